@@ -0,0 +1,225 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: transcription.proto
+
+package transcriptionpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TranscriptionService_Transcribe_FullMethodName        = "/ivritai.transcription.v1.TranscriptionService/Transcribe"
+	TranscriptionService_ListModels_FullMethodName        = "/ivritai.transcription.v1.TranscriptionService/ListModels"
+	TranscriptionService_TranslateSegments_FullMethodName = "/ivritai.transcription.v1.TranscriptionService/TranslateSegments"
+)
+
+// TranscriptionServiceClient is the client API for TranscriptionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TranscriptionService is the gRPC API of the ivrit.ai transcription server,
+// started with: ivrit_ai -grpc :50051
+//
+// Generate client code with e.g.:
+//
+//	protoc --go_out=. --go-grpc_out=. transcription.proto
+//	python -m grpc_tools.protoc -I. --python_out=. --grpc_python_out=. transcription.proto
+type TranscriptionServiceClient interface {
+	// Transcribe transcribes an audio or video file, streaming progress updates and
+	// segments as they are produced, followed by a final TranscriptionComplete event.
+	Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranscribeResponse], error)
+	// ListModels lists the available models and whether they are downloaded.
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
+	// TranslateSegments translates transcribed segments (requires Ollama on the server).
+	TranslateSegments(ctx context.Context, in *TranslateSegmentsRequest, opts ...grpc.CallOption) (*TranslateSegmentsResponse, error)
+}
+
+type transcriptionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTranscriptionServiceClient(cc grpc.ClientConnInterface) TranscriptionServiceClient {
+	return &transcriptionServiceClient{cc}
+}
+
+func (c *transcriptionServiceClient) Transcribe(ctx context.Context, in *TranscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TranscribeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TranscriptionService_ServiceDesc.Streams[0], TranscriptionService_Transcribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TranscribeRequest, TranscribeResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranscriptionService_TranscribeClient = grpc.ServerStreamingClient[TranscribeResponse]
+
+func (c *transcriptionServiceClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, TranscriptionService_ListModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transcriptionServiceClient) TranslateSegments(ctx context.Context, in *TranslateSegmentsRequest, opts ...grpc.CallOption) (*TranslateSegmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TranslateSegmentsResponse)
+	err := c.cc.Invoke(ctx, TranscriptionService_TranslateSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TranscriptionServiceServer is the server API for TranscriptionService service.
+// All implementations must embed UnimplementedTranscriptionServiceServer
+// for forward compatibility.
+//
+// TranscriptionService is the gRPC API of the ivrit.ai transcription server,
+// started with: ivrit_ai -grpc :50051
+//
+// Generate client code with e.g.:
+//
+//	protoc --go_out=. --go-grpc_out=. transcription.proto
+//	python -m grpc_tools.protoc -I. --python_out=. --grpc_python_out=. transcription.proto
+type TranscriptionServiceServer interface {
+	// Transcribe transcribes an audio or video file, streaming progress updates and
+	// segments as they are produced, followed by a final TranscriptionComplete event.
+	Transcribe(*TranscribeRequest, grpc.ServerStreamingServer[TranscribeResponse]) error
+	// ListModels lists the available models and whether they are downloaded.
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
+	// TranslateSegments translates transcribed segments (requires Ollama on the server).
+	TranslateSegments(context.Context, *TranslateSegmentsRequest) (*TranslateSegmentsResponse, error)
+	mustEmbedUnimplementedTranscriptionServiceServer()
+}
+
+// UnimplementedTranscriptionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTranscriptionServiceServer struct{}
+
+func (UnimplementedTranscriptionServiceServer) Transcribe(*TranscribeRequest, grpc.ServerStreamingServer[TranscribeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Transcribe not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) TranslateSegments(context.Context, *TranslateSegmentsRequest) (*TranslateSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TranslateSegments not implemented")
+}
+func (UnimplementedTranscriptionServiceServer) mustEmbedUnimplementedTranscriptionServiceServer() {}
+func (UnimplementedTranscriptionServiceServer) testEmbeddedByValue()                              {}
+
+// UnsafeTranscriptionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TranscriptionServiceServer will
+// result in compilation errors.
+type UnsafeTranscriptionServiceServer interface {
+	mustEmbedUnimplementedTranscriptionServiceServer()
+}
+
+func RegisterTranscriptionServiceServer(s grpc.ServiceRegistrar, srv TranscriptionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTranscriptionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TranscriptionService_ServiceDesc, srv)
+}
+
+func _TranscriptionService_Transcribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TranscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TranscriptionServiceServer).Transcribe(m, &grpc.GenericServerStream[TranscribeRequest, TranscribeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TranscriptionService_TranscribeServer = grpc.ServerStreamingServer[TranscribeResponse]
+
+func _TranscriptionService_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscriptionServiceServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscriptionService_ListModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscriptionServiceServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranscriptionService_TranslateSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TranslateSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscriptionServiceServer).TranslateSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscriptionService_TranslateSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscriptionServiceServer).TranslateSegments(ctx, req.(*TranslateSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TranscriptionService_ServiceDesc is the grpc.ServiceDesc for TranscriptionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TranscriptionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ivritai.transcription.v1.TranscriptionService",
+	HandlerType: (*TranscriptionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListModels",
+			Handler:    _TranscriptionService_ListModels_Handler,
+		},
+		{
+			MethodName: "TranslateSegments",
+			Handler:    _TranscriptionService_TranslateSegments_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Transcribe",
+			Handler:       _TranscriptionService_Transcribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "transcription.proto",
+}
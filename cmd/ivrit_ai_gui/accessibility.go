@@ -0,0 +1,76 @@
+//go:build !headless
+
+package main
+
+// Screen reader support. Gio publishes the semantic tree of each frame to VoiceOver,
+// NVDA/Narrator (UI Automation) and TalkBack. Material buttons, checkboxes and radio
+// buttons describe themselves by their label; these helpers describe the rest.
+//
+// Keyboard focus moves with Tab/Shift+Tab in layout order, so the window is laid out
+// top to bottom in the order a task is done: file, options, actions, transcript.
+
+import (
+	"image"
+
+	"gioui.org/io/semantic"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+// accessibleGroup describes a group of widgets, such as a set of radio buttons,
+// so screen readers announce what the choices are for
+func accessibleGroup(gtx layout.Context, description string, w layout.Widget) layout.Dimensions {
+	return semanticArea(gtx, w, func() {
+		semantic.DescriptionOp(description).Add(gtx.Ops)
+	})
+}
+
+// accessibleEditor describes an editor and exposes its contents, which Gio's
+// editor doesn't publish itself, so the transcript can be read aloud
+func accessibleEditor(gtx layout.Context, description, contents string, w layout.Widget) layout.Dimensions {
+	return semanticArea(gtx, w, func() {
+		semantic.DescriptionOp(description).Add(gtx.Ops)
+		semantic.LabelOp(contents).Add(gtx.Ops)
+	})
+}
+
+// semanticArea lays out w in its own semantic node with the properties added by describe
+func semanticArea(gtx layout.Context, w layout.Widget, describe func()) layout.Dimensions {
+	macro := op.Record(gtx.Ops)
+	dims := w(gtx)
+	call := macro.Stop()
+
+	defer clip.Rect(image.Rectangle{Max: dims.Size}).Push(gtx.Ops).Pop()
+	describe()
+	call.Add(gtx.Ops)
+	return dims
+}
+
+// describedButton lays out btn like ButtonStyle.Layout, with a description for
+// buttons whose label alone is cryptic (like "A+") when read aloud
+func describedButton(gtx layout.Context, th *material.Theme, btn material.ButtonStyle, description string) layout.Dimensions {
+	return material.ButtonLayoutStyle{
+		Background:   btn.Background,
+		CornerRadius: btn.CornerRadius,
+		Button:       btn.Button,
+	}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		semantic.DescriptionOp(description).Add(gtx.Ops)
+		return btn.Inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			colMacro := op.Record(gtx.Ops)
+			paint.ColorOp{Color: btn.Color}.Add(gtx.Ops)
+			return widget.Label{Alignment: text.Middle}.Layout(gtx, th.Shaper, btn.Font, btn.TextSize, btn.Text, colMacro.Stop())
+		})
+	})
+}
+
+// accessibleLink marks the contents of a clickable text link as a button
+func accessibleLink(gtx layout.Context, description string, w layout.Widget) layout.Dimensions {
+	semantic.Button.Add(gtx.Ops)
+	semantic.DescriptionOp(description).Add(gtx.Ops)
+	return w(gtx)
+}
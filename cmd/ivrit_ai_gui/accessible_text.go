@@ -0,0 +1,36 @@
+package main
+
+import "strings"
+
+// accessibleChars bounds the text of a long editor published to screen readers each
+// frame, so a transcript of hours isn't handed to the accessibility API 60 times a
+// second
+const accessibleChars = 2000
+
+// accessibleExcerpt returns the whole lines of text around caret (a rune offset, as
+// widget.Editor reports it), at most limit runes, for a screen reader to read from
+// where the user is. Text within the limit is returned whole.
+func accessibleExcerpt(text string, caret, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	caret = min(max(caret, 0), len(runes))
+	start := max(0, caret-limit/2)
+	end := min(len(runes), start+limit)
+	start = max(0, end-limit)
+
+	// Cut at line breaks, unless a single line is longer than the excerpt
+	excerpt := string(runes[start:end])
+	if start > 0 && runes[start-1] != '\n' {
+		if i := strings.IndexByte(excerpt, '\n'); i >= 0 && i < len(excerpt)-1 {
+			excerpt = excerpt[i+1:]
+		}
+	}
+	if end < len(runes) && runes[end] != '\n' {
+		if i := strings.LastIndexByte(excerpt, '\n'); i > 0 {
+			excerpt = excerpt[:i]
+		}
+	}
+	return strings.TrimSuffix(excerpt, "\n")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAccessibleExcerpt tests that a long transcript is published as the lines around
+// the caret
+func TestAccessibleExcerpt(t *testing.T) {
+	short := "שלום\nעולם"
+	if got := accessibleExcerpt(short, 3, 100); got != short {
+		t.Errorf("accessibleExcerpt(short) = %q, want the whole text", got)
+	}
+
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, strings.Repeat(string(rune('א'+i%22)), 9)) // 10 runes with the line break
+	}
+	text := strings.Join(lines, "\n")
+	tests := []struct {
+		caret      int
+		first, end string // The excerpt's first and last lines
+	}{
+		{0, lines[0], lines[4]},
+		{505, lines[48], lines[52]},
+		{len([]rune(text)), lines[95], lines[99]},
+	}
+	for _, tt := range tests {
+		got := strings.Split(accessibleExcerpt(text, tt.caret, 50), "\n")
+		if got[0] != tt.first || got[len(got)-1] != tt.end || len([]rune(strings.Join(got, "\n"))) > 50 {
+			t.Errorf("accessibleExcerpt(caret %d) = %q", tt.caret, got)
+		}
+	}
+
+	// A line longer than the excerpt is cut within it
+	long := strings.Repeat("א", 200)
+	if got := accessibleExcerpt(long, 100, 50); len([]rune(got)) != 50 {
+		t.Errorf("accessibleExcerpt(one long line) has %d runes, want 50", len([]rune(got)))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// alternativeDecode returns the decode tried for an alternative reading of a segment
+// transcribed with used. whisper.cpp only returns the best hypothesis of a decode, so
+// a different decode is how another reading surfaces: beam search finds what greedy
+// decoding missed, and a beam search transcript's runner-up shows up when sampling.
+func alternativeDecode(used DecodeOptions) DecodeOptions {
+	if used.BeamSize > 1 {
+		return DecodeOptions{Temperature: 0.4, InitialPrompt: used.InitialPrompt}
+	}
+	return DecodeOptions{BeamSize: 5, InitialPrompt: used.InitialPrompt}
+}
+
+// alternativesKey identifies a segment's reading in a recording, for the cache
+type alternativesKey struct {
+	audioPath string
+	modelID   string
+	start     float64
+	end       float64
+	current   string
+}
+
+// alternativesCache keeps the readings found per segment, so asking again is instant
+var alternativesCache = struct {
+	sync.Mutex
+	readings map[alternativesKey][]string
+}{readings: make(map[alternativesKey][]string)}
+
+// windowTranscriber transcribes part of an audio file with given decoding options
+type windowTranscriber interface {
+	SetTimeRange(timeRange TimeRange)
+	SetDecodeOptions(decode DecodeOptions)
+	Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error)
+}
+
+// SegmentAlternatives re-transcribes a segment's audio once, with a different decode
+// than the transcript's (used), and returns the reading if it differs from the
+// current one. Results are cached per segment and reading.
+func SegmentAlternatives(engine windowTranscriber, audioPath, modelID string, cpuThreads int, seg Segment, used DecodeOptions) ([]string, error) {
+	current := seg.Text
+	if seg.Original != "" {
+		current = seg.Original
+	}
+	key := alternativesKey{audioPath: audioPath, modelID: modelID, start: seg.Start, end: seg.End, current: current}
+	alternativesCache.Lock()
+	alternatives, ok := alternativesCache.readings[key]
+	alternativesCache.Unlock()
+	if ok {
+		return alternatives, nil
+	}
+
+	engine.SetTimeRange(RetranscribeWindow(seg))
+	engine.SetDecodeOptions(alternativeDecode(used))
+	result, err := engine.Transcribe(audioPath, modelID, cpuThreads, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	// A translation-only segment's Hebrew isn't known, so any reading is new
+	alternatives = []string{}
+	text := RetranscribedText(result)
+	if readingKey(text) != "" && (readingKey(text) != readingKey(current) || (seg.Translation != "" && seg.Original == "")) {
+		alternatives = append(alternatives, text)
+	}
+
+	alternativesCache.Lock()
+	alternativesCache.readings[key] = alternatives
+	alternativesCache.Unlock()
+	return alternatives, nil
+}
+
+// readingKey normalizes a reading for comparison, ignoring punctuation and spacing
+func readingKey(text string) string {
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool { return !isWordRune(r) }), " ")
+}
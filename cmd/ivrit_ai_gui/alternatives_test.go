@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeTranscriber returns a canned reading and records what it was asked
+type fakeTranscriber struct {
+	reading   string
+	timeRange TimeRange
+	decodes   []DecodeOptions
+}
+
+func (f *fakeTranscriber) SetTimeRange(timeRange TimeRange) { f.timeRange = timeRange }
+
+func (f *fakeTranscriber) SetDecodeOptions(decode DecodeOptions) {
+	f.decodes = append(f.decodes, decode)
+}
+
+func (f *fakeTranscriber) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	return []Segment{{Text: f.reading}}, nil
+}
+
+// TestSegmentAlternatives tests that one decode, different from the transcript's,
+// is run per segment and its reading offered when it differs from the current one
+func TestSegmentAlternatives(t *testing.T) {
+	engine := &fakeTranscriber{reading: "שלום לכולן"}
+	seg := Segment{Start: 1, End: 3, Text: "שלום לכולם."}
+
+	alternatives, err := SegmentAlternatives(engine, "alternatives.wav", "turbo", 4, seg, DecodeOptions{InitialPrompt: "ivrit.ai"})
+	if err != nil {
+		t.Fatalf("SegmentAlternatives() error: %v", err)
+	}
+	if expected := []string{"שלום לכולן"}; !reflect.DeepEqual(alternatives, expected) {
+		t.Errorf("SegmentAlternatives() = %q, expected %q", alternatives, expected)
+	}
+	if engine.timeRange != RetranscribeWindow(seg) {
+		t.Errorf("Expected the segment's window, got %+v", engine.timeRange)
+	}
+	if expected := []DecodeOptions{{BeamSize: 5, InitialPrompt: "ivrit.ai"}}; !reflect.DeepEqual(engine.decodes, expected) {
+		t.Errorf("Expected one beam search decode, got %+v", engine.decodes)
+	}
+
+	// Asking again is answered from the cache
+	if again, _ := SegmentAlternatives(engine, "alternatives.wav", "turbo", 4, seg, DecodeOptions{}); !reflect.DeepEqual(again, alternatives) || len(engine.decodes) != 1 {
+		t.Errorf("Expected the cached readings without decoding, got %q after %d decodes", again, len(engine.decodes))
+	}
+
+	// A beam search transcript is sampled instead; a reading only differing in
+	// punctuation isn't offered
+	engine = &fakeTranscriber{reading: "שלום, לכולם!"}
+	other := Segment{Start: 5, End: 7, Text: "שלום לכולם."}
+	if alternatives, _ := SegmentAlternatives(engine, "alternatives.wav", "turbo", 4, other, DecodeOptions{BeamSize: 5}); len(alternatives) != 0 {
+		t.Errorf("Expected no alternatives, got %q", alternatives)
+	}
+	if engine.decodes[0].BeamSize > 1 || engine.decodes[0].Temperature == 0 {
+		t.Errorf("Expected sampling for a beam search transcript, got %+v", engine.decodes[0])
+	}
+}
+
+// TestSegmentAlternativesTranslated tests which text translated segments are compared with
+func TestSegmentAlternativesTranslated(t *testing.T) {
+	kept := Segment{Start: 10, End: 11, Original: "שלום", Translation: "Hello", Text: "Hello"}
+	if alternatives, _ := SegmentAlternatives(&fakeTranscriber{reading: "שלום"}, "translated.wav", "turbo", 4, kept, DecodeOptions{}); len(alternatives) != 0 {
+		t.Errorf("With original: %q, expected none", alternatives)
+	}
+
+	// Without the original the current Hebrew is unknown, so the reading is offered
+	translationOnly := Segment{Start: 12, End: 13, Translation: "Hello", Text: "Hello"}
+	alternatives, _ := SegmentAlternatives(&fakeTranscriber{reading: "שלום"}, "translated.wav", "turbo", 4, translationOnly, DecodeOptions{})
+	if expected := []string{"שלום"}; !reflect.DeepEqual(alternatives, expected) {
+		t.Errorf("Translation only: %q, expected %q", alternatives, expected)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// appReleasesURL is the GitHub API address of the app's latest release
+var appReleasesURL = "https://api.github.com/repos/OriPekelman/ivrit_ai_gui/releases/latest"
+
+// AppRelease is a published release of the app
+type AppRelease struct {
+	Version string         `json:"tag_name"`
+	Name    string         `json:"name"`
+	Notes   string         `json:"body"` // Changelog, in markdown
+	URL     string         `json:"html_url"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release, e.g. an installer
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Installer files per platform: the OS names and architectures used in asset
+// names, and the extensions of installers and archives
+var (
+	releaseOSNames = map[string][]string{
+		"darwin":  {"macos", "darwin", "mac"},
+		"windows": {"windows"},
+		"linux":   {"linux"},
+	}
+	releaseArchNames = map[string][]string{
+		"amd64": {"amd64", "x86_64", "x64"},
+		"arm64": {"arm64", "aarch64"},
+	}
+	installerExtensions = []string{".dmg", ".pkg", ".msi", ".exe", ".zip", ".tar.gz", ".appimage", ".deb"}
+)
+
+// CheckAppUpdate fetches the latest release, returning it when it is newer than
+// this build, or nil when this build is up to date. Development builds, which
+// have no version, are never offered updates.
+func CheckAppUpdate() (*AppRelease, error) {
+	if !isReleaseVersion(appVersion) {
+		return nil, nil
+	}
+
+	req, err := http.NewRequest("GET", appReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "ivrit-ai/"+appVersion)
+
+	client, err := downloadClient()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to check for updates: status %d", resp.StatusCode)
+	}
+
+	var release AppRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to read the latest release: %v", err)
+	}
+	if compareVersions(release.Version, appVersion) <= 0 {
+		return nil, nil
+	}
+	return &release, nil
+}
+
+// isReleaseVersion reports whether a version is a release number such as "v1.2.0"
+func isReleaseVersion(version string) bool {
+	version = strings.TrimPrefix(version, "v")
+	number, _, _ := strings.Cut(version, "-")
+	_, err := strconv.Atoi(strings.Split(number, ".")[0])
+	return err == nil
+}
+
+// compareVersions compares two versions such as "v1.10.0" and "1.9.2-beta",
+// returning -1, 0 or 1. A pre-release is older than its release.
+func compareVersions(a, b string) int {
+	aNumber, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bNumber, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	aParts, bParts := strings.Split(aNumber, "."), strings.Split(bNumber, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// PlatformAsset returns the installer or archive of the release for this platform
+func (r AppRelease) PlatformAsset() (ReleaseAsset, bool) {
+	return platformAsset(r.Assets, runtime.GOOS, runtime.GOARCH)
+}
+
+// platformAsset picks the asset for goos and goarch: named after the OS, not
+// after another architecture, preferring installers and the exact architecture
+func platformAsset(assets []ReleaseAsset, goos, goarch string) (ReleaseAsset, bool) {
+	best, bestScore := ReleaseAsset{}, -1
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		if !containsAny(name, releaseOSNames[goos]) {
+			continue
+		}
+		otherArch := false
+		for arch, names := range releaseArchNames {
+			if arch != goarch && containsAny(name, names) {
+				otherArch = true
+			}
+		}
+		if otherArch {
+			continue
+		}
+
+		score := 0
+		for _, ext := range installerExtensions {
+			if strings.HasSuffix(name, ext) {
+				score += 2
+				break
+			}
+		}
+		if containsAny(name, releaseArchNames[goarch]) {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = asset, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadAppUpdate downloads a release asset to the Downloads folder, returning its path
+func DownloadAppUpdate(asset ReleaseAsset, progressCallback func(string, int)) (string, error) {
+	homeDir, _ := os.UserHomeDir()
+	dir := filepath.Join(homeDir, "Downloads")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	destPath := filepath.Join(dir, filepath.Base(asset.Name))
+
+	req, err := http.NewRequest("GET", asset.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	client, err := downloadClient()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+	if err := saveDownload(client, req, resp, destPath, progressCallback); err != nil {
+		return "", err
+	}
+	return destPath, nil
+}
+
+// releaseNotesPreview shortens release notes to their first lines for display
+func releaseNotesPreview(notes string, maxLines int) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(notes, "\r\n", "\n")), "\n")
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], "…")
+	}
+	return strings.Join(lines, "\n")
+}
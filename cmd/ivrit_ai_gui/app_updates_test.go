@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestCompareVersions tests ordering release versions
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.0", "v1.2.0", 0},
+		{"v1.2.0", "1.2.0", 0},
+		{"v1.10.0", "v1.9.2", 1},
+		{"v1.2", "v1.2.1", -1},
+		{"v2.0.0-beta", "v2.0.0", -1},
+		{"v2.0.0", "v1.9.0-rc1", 1},
+		{"v2.0.0-rc2", "v2.0.0-rc1", 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+
+	if isReleaseVersion("dev") || !isReleaseVersion("v1.2.0") || !isReleaseVersion("1.0.0-beta") {
+		t.Error("Unexpected release version detection")
+	}
+}
+
+// TestPlatformAsset tests picking the installer for a platform
+func TestPlatformAsset(t *testing.T) {
+	assets := []ReleaseAsset{
+		{Name: "ivrit_ai-macos-amd64"},
+		{Name: "ivrit_ai-macos.dmg"},
+		{Name: "ivrit_ai-linux-amd64.tar.gz"},
+		{Name: "ivrit_ai-linux-arm64.tar.gz"},
+		{Name: "ivrit_ai-windows-amd64.zip"},
+		{Name: "checksums.txt"},
+	}
+	tests := []struct {
+		goos, goarch string
+		expected     string
+	}{
+		{"darwin", "arm64", "ivrit_ai-macos.dmg"},
+		{"darwin", "amd64", "ivrit_ai-macos.dmg"},
+		{"linux", "amd64", "ivrit_ai-linux-amd64.tar.gz"},
+		{"linux", "arm64", "ivrit_ai-linux-arm64.tar.gz"},
+		{"windows", "amd64", "ivrit_ai-windows-amd64.zip"},
+	}
+
+	for _, tt := range tests {
+		asset, ok := platformAsset(assets, tt.goos, tt.goarch)
+		if !ok || asset.Name != tt.expected {
+			t.Errorf("platformAsset(%s/%s) = %q, expected %q", tt.goos, tt.goarch, asset.Name, tt.expected)
+		}
+	}
+	if asset, ok := platformAsset(assets, "windows", "arm64"); ok {
+		t.Errorf("Expected no asset for windows/arm64, got %q", asset.Name)
+	}
+}
+
+// TestCheckAppUpdate tests finding a newer release on GitHub
+func TestCheckAppUpdate(t *testing.T) {
+	latest := `{"tag_name":"v1.3.0","body":"## What's new\n- Faster","html_url":"https://github.com/OriPekelman/ivrit_ai_gui/releases/tag/v1.3.0",
+		"assets":[{"name":"ivrit_ai-linux-amd64.tar.gz","browser_download_url":"https://example.com/ivrit_ai-linux-amd64.tar.gz","size":1000}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, latest)
+	}))
+	defer server.Close()
+
+	oldURL, oldVersion := appReleasesURL, appVersion
+	defer func() { appReleasesURL, appVersion = oldURL, oldVersion }()
+	appReleasesURL = server.URL
+
+	appVersion = "v1.2.0"
+	release, err := CheckAppUpdate()
+	if err != nil {
+		t.Fatalf("CheckAppUpdate() error: %v", err)
+	}
+	if release == nil || release.Version != "v1.3.0" || len(release.Assets) != 1 {
+		t.Fatalf("Expected release v1.3.0, got %+v", release)
+	}
+	if notes := releaseNotesPreview(release.Notes, 1); notes != "## What's new\n…" {
+		t.Errorf("Unexpected notes preview: %q", notes)
+	}
+
+	appVersion = "v1.3.0"
+	if release, err := CheckAppUpdate(); err != nil || release != nil {
+		t.Errorf("Expected no update for the latest version, got %+v, %v", release, err)
+	}
+	appVersion = "dev"
+	if release, err := CheckAppUpdate(); err != nil || release != nil {
+		t.Errorf("Expected no update for a development build, got %+v, %v", release, err)
+	}
+}
+
+// TestAppUpdateCheckDue tests the opt-in daily check
+func TestAppUpdateCheckDue(t *testing.T) {
+	now := time.Now()
+	settings := defaultSettings()
+	if settings.AppUpdateCheckDue(now) {
+		t.Error("Expected no check unless opted in")
+	}
+	settings.CheckAppUpdates = true
+	if !settings.AppUpdateCheckDue(now) {
+		t.Error("Expected a first check when opted in")
+	}
+	settings.LastAppUpdateCheck = now.Add(-time.Hour)
+	if settings.AppUpdateCheckDue(now) {
+		t.Error("Expected no check within a day of the last one")
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// sampleReadSize is how many bytes of 16-bit PCM are converted to samples at a time
+const sampleReadSize = 64 * 1024
+
+// DecodeAudio decodes audio into whisper's 16kHz mono float32 samples. Files that are
+// already 16kHz mono 16-bit PCM WAV are read directly, and other uncompressed WAVs
+// (44.1kHz stereo, 24-bit, float...) are mixed down and resampled as they are read;
+// anything else is decoded by ffmpeg through a pipe straight into the sample buffer,
+// so no temporary WAV is written and a multi-GB recording doesn't need its size
+// again in free disk space. When a time range is set only that portion is decoded
+// by ffmpeg, and trimmed reports that the samples start at timeRange.Start.
+func DecodeAudio(audioPath string, timeRange TimeRange, progressCallback func(string)) ([]float32, bool, error) {
+	if isCompliantWAV(audioPath) {
+		if progressCallback != nil {
+			progressCallback("Audio is already 16kHz mono WAV, skipping conversion")
+		}
+		samples, err := readWAVSamples(audioPath)
+		return samples, false, err
+	}
+
+	if readableWAV(audioPath) {
+		if progressCallback != nil {
+			progressCallback("Resampling WAV audio to 16kHz mono...")
+		}
+		samples, err := readWAVSamples(audioPath)
+		return samples, false, err
+	}
+
+	if progressCallback != nil {
+		progressCallback("Decoding audio...")
+	}
+
+	cmd, stdout, err := startPCMDecode(audioPath, timeRange)
+	if err != nil {
+		return nil, false, err
+	}
+
+	samples, readErr := readPCM16Samples(stdout, expectedSamples(audioPath, timeRange))
+	if err := cmd.Wait(); err != nil {
+		return nil, false, fmt.Errorf("ffmpeg conversion failed: %v", err)
+	}
+	if readErr != nil {
+		return nil, false, fmt.Errorf("failed to read decoded audio: %v", readErr)
+	}
+	return samples, timeRange.IsSet(), nil
+}
+
+// prefetchKey identifies audio decoded ahead by the audio file and the time range
+type prefetchKey struct {
+	path      string
+	timeRange TimeRange
+}
+
+// prefetchedSamples is DecodeAudio's result for a file decoded ahead
+type prefetchedSamples struct {
+	samples []float32
+	trimmed bool
+}
+
+// Audio decoded ahead of inference (RunPipeline), until the engine takes it
+var (
+	prefetched      = make(map[prefetchKey]prefetchedSamples)
+	prefetchedMutex sync.Mutex
+)
+
+// prefetchAudio decodes a file's audio (the time range of it, when set) ahead of its
+// transcription, which then takes the samples instead of decoding it
+func prefetchAudio(audioPath string, timeRange TimeRange) error {
+	samples, trimmed, err := DecodeAudio(audioPath, timeRange, nil)
+	if err != nil {
+		return err
+	}
+	prefetchedMutex.Lock()
+	defer prefetchedMutex.Unlock()
+	prefetched[prefetchKey{audioPath, timeRange}] = prefetchedSamples{samples, trimmed}
+	return nil
+}
+
+// takePrefetchedAudio returns and forgets the samples decoded ahead for the file and
+// time range, if any
+func takePrefetchedAudio(audioPath string, timeRange TimeRange) ([]float32, bool, bool) {
+	prefetchedMutex.Lock()
+	defer prefetchedMutex.Unlock()
+	key := prefetchKey{audioPath, timeRange}
+	audio, ok := prefetched[key]
+	delete(prefetched, key)
+	return audio.samples, audio.trimmed, ok
+}
+
+// dropPrefetchedAudio forgets samples decoded ahead that weren't taken
+func dropPrefetchedAudio(audioPath string, timeRange TimeRange) {
+	takePrefetchedAudio(audioPath, timeRange)
+}
+
+// startPCMDecode starts ffmpeg decoding audio (only the time range, when set) to 16kHz
+// mono 16-bit PCM and returns the running command with a pipe of its output. The
+// caller reads the pipe to the end and then waits for the command.
+func startPCMDecode(audioPath string, timeRange TimeRange) (*exec.Cmd, io.ReadCloser, error) {
+	// Seek before the input for fast seeking; -t is then the duration to keep
+	args := []string{}
+	if timeRange.IsSet() {
+		args = append(args, "-ss", strconv.FormatFloat(timeRange.Start, 'f', 3, 64))
+		if timeRange.End > 0 {
+			args = append(args, "-t", strconv.FormatFloat(timeRange.End-timeRange.Start, 'f', 3, 64))
+		}
+	}
+	args = append(args,
+		"-i", audioPath,
+		"-vn",
+		"-ar", "16000", // 16kHz sample rate
+		"-ac", "1", // Mono
+		"-f", "s16le", // Raw 16-bit PCM, as a compliant WAV holds it
+		"-loglevel", "error",
+		"pipe:1",
+	)
+	cmd := exec.Command(ffmpegPath(), args...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("ffmpeg conversion failed: %v (is ffmpeg installed?)", err)
+	}
+	return cmd, stdout, nil
+}
+
+// expectedSamples estimates the number of samples ffmpeg decodes from the file's
+// duration, so the buffer is allocated once instead of growing (and briefly doubling)
+// while a long recording is read. 0 means unknown.
+func expectedSamples(audioPath string, timeRange TimeRange) int {
+	duration, err := getAudioDuration(audioPath)
+	if err != nil {
+		return 0
+	}
+	if timeRange.End > 0 && timeRange.End < duration {
+		duration = timeRange.End
+	}
+	duration -= timeRange.Start
+	if duration <= 0 {
+		return 0
+	}
+	return int(duration*whisperSampleRate) + whisperSampleRate // A second to spare
+}
+
+// wavFormat is the sample format of a WAV file, from its fmt chunk
+type wavFormat struct {
+	Float         bool // IEEE float samples rather than integer PCM
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+}
+
+// readable reports whether the samples are read without ffmpeg: integer PCM of 8,
+// 16, 24 or 32 bits, or 32 or 64-bit float
+func (f wavFormat) readable() bool {
+	if f.Channels < 1 || f.SampleRate < 1 {
+		return false
+	}
+	if f.Float {
+		return f.BitsPerSample == 32 || f.BitsPerSample == 64
+	}
+	return f.BitsPerSample == 8 || f.BitsPerSample == 16 || f.BitsPerSample == 24 || f.BitsPerSample == 32
+}
+
+// compliant reports whether the samples are whisper's 16kHz mono 16-bit PCM
+func (f wavFormat) compliant() bool {
+	return !f.Float && f.Channels == 1 && f.SampleRate == whisperSampleRate && f.BitsPerSample == 16
+}
+
+// readWAVHeader reads a WAV file's chunks up to its data, finding the fmt and data
+// chunks after any others (LIST, fact...) the file has, and returns the format and
+// the size of the data that follows
+func readWAVHeader(r *bufio.Reader) (wavFormat, int64, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return wavFormat{}, 0, fmt.Errorf("not a valid WAV file")
+	}
+	var format wavFormat
+	for {
+		chunk := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return format, 0, fmt.Errorf("WAV file has no audio data")
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		switch string(chunk[0:4]) {
+		case "data":
+			if format.Channels == 0 {
+				return format, 0, fmt.Errorf("WAV file has no format before its audio data")
+			}
+			return format, size, nil
+		case "fmt ":
+			if size < 16 {
+				return format, 0, fmt.Errorf("not a valid WAV file")
+			}
+			body := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return format, 0, fmt.Errorf("not a valid WAV file")
+			}
+			audioFormat := binary.LittleEndian.Uint16(body[0:2])
+			if audioFormat == 0xFFFE && size >= 26 { // WAVE_FORMAT_EXTENSIBLE: the format starts its subformat GUID
+				audioFormat = binary.LittleEndian.Uint16(body[24:26])
+			}
+			format = wavFormat{
+				Float:         audioFormat == 3,
+				Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+			}
+			if audioFormat != 1 && audioFormat != 3 {
+				format.BitsPerSample = 0 // Compressed (ADPCM, mu-law...): left to ffmpeg
+			}
+			continue
+		}
+		// Chunks are padded to an even size
+		if _, err := r.Discard(int(size + size%2)); err != nil {
+			return format, 0, fmt.Errorf("WAV file has no audio data")
+		}
+	}
+}
+
+// readableWAV reports whether a file is a WAV whose samples are read without ffmpeg
+func readableWAV(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	format, _, err := readWAVHeader(bufio.NewReader(f))
+	return err == nil && format.readable()
+}
+
+// readWAVSamples reads the samples of a WAV file as whisper's 16kHz mono samples.
+// 16kHz mono 16-bit PCM is read as is; other sample rates, channel counts and
+// sample formats are mixed down to mono and resampled as they are read.
+func readWAVSamples(wavPath string) ([]float32, error) {
+	f, err := os.Open(wavPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	format, size, err := readWAVHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	data := io.LimitReader(r, size)
+	if format.compliant() {
+		return readPCM16Samples(data, int(size/2))
+	}
+	if !format.readable() {
+		return nil, fmt.Errorf("unsupported WAV sample format (%d-bit, %d channels)", format.BitsPerSample, format.Channels)
+	}
+
+	// Whole frames (a sample of each channel) at a time, averaged to mono
+	frameSize := format.Channels * format.BitsPerSample / 8
+	buf := make([]byte, (sampleReadSize/frameSize)*frameSize)
+	mono := make([]float32, 0, len(buf)/frameSize)
+	resampler := newResampler(format.SampleRate, whisperSampleRate)
+	for {
+		n, err := io.ReadFull(data, buf)
+		mono = mono[:0]
+		for frame := 0; frame+frameSize <= n; frame += frameSize {
+			var sum float32
+			for ch := 0; ch < format.Channels; ch++ {
+				sum += format.sample(buf[frame+ch*format.BitsPerSample/8:])
+			}
+			mono = append(mono, sum/float32(format.Channels))
+		}
+		resampler.push(mono)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return resampler.finish(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sample converts the sample at the start of b to a float32 (-1.0 to 1.0)
+func (f wavFormat) sample(b []byte) float32 {
+	switch {
+	case f.Float && f.BitsPerSample == 64:
+		return float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case f.Float:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case f.BitsPerSample == 8: // Unsigned, centred on 128
+		return float32(int(b[0])-128) / 128.0
+	case f.BitsPerSample == 16:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768.0
+	case f.BitsPerSample == 24:
+		return float32(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / 8388608.0
+	}
+	return float32(int32(binary.LittleEndian.Uint32(b))) / 2147483648.0
+}
+
+// readPCM16Samples converts little-endian 16-bit PCM to float32 samples (-1.0 to 1.0)
+// as it is read. capacity is the expected number of samples (0 = unknown).
+func readPCM16Samples(r io.Reader, capacity int) ([]float32, error) {
+	samples := make([]float32, 0, capacity)
+	buf := make([]byte, sampleReadSize)
+	carry := 0 // A byte of a sample split across reads
+	for {
+		n, err := r.Read(buf[carry:])
+		n += carry
+		even := n - n%2
+		for i := 0; i < even; i += 2 {
+			samples = append(samples, float32(int16(binary.LittleEndian.Uint16(buf[i:i+2])))/32768.0)
+		}
+		carry = n - even
+		if carry > 0 {
+			buf[0] = buf[even]
+		}
+		if err == io.EOF {
+			return samples, nil
+		}
+		if err != nil {
+			return samples, err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+)
+
+// TestReadPCM16Samples tests converting PCM to samples, with samples split across reads
+func TestReadPCM16Samples(t *testing.T) {
+	pcm := make([]byte, 6)
+	for i, v := range []int16{0, 16384, -32768} {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(v))
+	}
+	samples, err := readPCM16Samples(iotest.OneByteReader(bytes.NewReader(pcm)), 0)
+	if err != nil {
+		t.Fatalf("readPCM16Samples() error: %v", err)
+	}
+	if len(samples) != 3 || samples[0] != 0 || samples[1] != 0.5 || samples[2] != -1 {
+		t.Errorf("Unexpected samples: %v", samples)
+	}
+}
+
+// TestDecodeAudioWAV tests reading a 16kHz mono WAV directly, with a chunk before its data
+func TestDecodeAudioWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "speech.wav")
+	pcm := make([]byte, 3200) // 0.1s
+	for i := 0; i < len(pcm)/2; i++ {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(i)))
+	}
+	writeTestWAV(t, path, 1, 1, 16000, 16, pcm)
+
+	samples, trimmed, err := DecodeAudio(path, TimeRange{}, nil)
+	if err != nil || trimmed {
+		t.Fatalf("DecodeAudio() = trimmed %v, error %v", trimmed, err)
+	}
+	if len(samples) != 1600 || samples[1] != 1.0/32768 {
+		t.Errorf("Expected 1600 samples, got %d (second: %v)", len(samples), samples[1])
+	}
+
+	// Recorders add LIST chunks (with odd sizes padded) between fmt and data
+	data, _ := os.ReadFile(path)
+	list := []byte("LIST\x03\x00\x00\x00abc\x00")
+	withList := append(append(append([]byte{}, data[:36]...), list...), data[36:]...)
+	os.WriteFile(path, withList, 0644)
+	if samples, err = readWAVSamples(path); err != nil || len(samples) != 1600 {
+		t.Errorf("Expected the data after the LIST chunk, got %d samples (error: %v)", len(samples), err)
+	}
+
+	os.WriteFile(path, data[:36], 0644)
+	if _, err := readWAVSamples(path); err == nil {
+		t.Error("Expected an error for a WAV without data")
+	}
+}
+
+// TestDecodeAudioResampledWAV tests reading WAVs at other rates, channel counts and
+// sample formats without ffmpeg
+func TestDecodeAudioResampledWAV(t *testing.T) {
+	// A second of a 440Hz tone at 44.1kHz: stereo 16-bit, with the right channel silent
+	const rate, tone = 44100, 440.0
+	pcm := make([]byte, rate*4)
+	for i := 0; i < rate; i++ {
+		binary.LittleEndian.PutUint16(pcm[i*4:], uint16(int16(16000*math.Sin(2*math.Pi*tone*float64(i)/rate))))
+	}
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	writeTestWAV(t, path, 1, 2, rate, 16, pcm)
+
+	samples, trimmed, err := DecodeAudio(path, TimeRange{}, nil)
+	if err != nil || trimmed {
+		t.Fatalf("DecodeAudio() = trimmed %v, error %v", trimmed, err)
+	}
+	if len(samples) != whisperSampleRate {
+		t.Fatalf("Expected a second at 16kHz, got %d samples", len(samples))
+	}
+	// The tone at half amplitude (mixed with the silent channel), away from the edges
+	for i := 1000; i < len(samples)-1000; i += 997 {
+		want := 0.5 * 16000 / 32768 * math.Sin(2*math.Pi*tone*float64(i)/whisperSampleRate)
+		if math.Abs(float64(samples[i])-want) > 0.01 {
+			t.Fatalf("Sample %d = %.4f, expected %.4f", i, samples[i], want)
+		}
+	}
+
+	// 8kHz mono 24-bit and 48kHz float
+	for _, tt := range []struct {
+		format, bits uint16
+		rate         uint32
+		sample       []byte
+	}{
+		{1, 24, 8000, []byte{0, 0, 0x40}}, // 0.5
+		{3, 32, 48000, binary.LittleEndian.AppendUint32(nil, math.Float32bits(0.5))},
+	} {
+		data := bytes.Repeat(tt.sample, int(tt.rate)/10)
+		writeTestWAV(t, path, tt.format, 1, tt.rate, tt.bits, data)
+		samples, err := readWAVSamples(path)
+		if err != nil || len(samples) != whisperSampleRate/10 || math.Abs(float64(samples[800])-0.5) > 0.01 {
+			t.Errorf("%d-bit %dHz: expected 0.1s of 0.5, got %d samples (error: %v)", tt.bits, tt.rate, len(samples), err)
+		}
+	}
+
+	// Compressed WAVs are left to ffmpeg
+	writeTestWAV(t, path, 2, 1, 8000, 4, make([]byte, 100)) // ADPCM
+	if readableWAV(path) {
+		t.Error("Expected an ADPCM WAV to need ffmpeg")
+	}
+}
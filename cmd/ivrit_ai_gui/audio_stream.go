@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Very long recordings are transcribed in windows read from the decoder one at a
+// time, so memory holds one window of samples instead of the whole recording
+const (
+	streamMinSecs    = 3 * 60 * 60 // Recordings at least this long are streamed
+	streamWindowSecs = 10 * 60     // Samples handed to whisper at a time
+	streamSearchSecs = 30          // A window ends at the last pause within this many seconds of its end
+)
+
+// shouldStreamAudio reports whether a recording (or its time range) is long enough
+// to be transcribed in streamed windows rather than decoded into memory whole
+func shouldStreamAudio(audioPath string, timeRange TimeRange) bool {
+	return expectedSamples(audioPath, timeRange) >= streamMinSecs*whisperSampleRate
+}
+
+// sampleWindows reads 16-bit PCM as consecutive windows of float32 samples, reusing
+// a single buffer. Each window is cut at a pause near its end where there is one,
+// and the audio after the cut is carried over to the start of the next window.
+type sampleWindows struct {
+	r      io.Reader
+	buf    []float32
+	raw    []byte
+	n      int  // Samples held in buf
+	cut    int  // Samples of buf returned by the last window
+	offset int  // Position of buf[0] in the stream, in samples
+	eof    bool // The reader is exhausted
+}
+
+// newSampleWindows returns windows of at most size samples read from r
+func newSampleWindows(r io.Reader, size int) *sampleWindows {
+	return &sampleWindows{
+		r:   r,
+		buf: make([]float32, size),
+		raw: make([]byte, sampleReadSize),
+	}
+}
+
+// Next returns the next window and the stream position (in samples) it starts at.
+// The window is only valid until the following call. It returns io.EOF once all
+// samples have been returned.
+func (w *sampleWindows) Next() ([]float32, int, error) {
+	// Carry the audio after the previous cut over to the start of the buffer
+	copy(w.buf, w.buf[w.cut:w.n])
+	w.n -= w.cut
+	w.offset += w.cut
+	w.cut = 0
+
+	if err := w.fill(); err != nil {
+		return nil, 0, err
+	}
+	if w.n == 0 {
+		return nil, 0, io.EOF
+	}
+
+	w.cut = w.n
+	if !w.eof {
+		w.cut = cutAtPause(w.buf[:w.n])
+	}
+	return w.buf[:w.cut], w.offset, nil
+}
+
+// fill reads samples until the buffer is full or the reader is exhausted
+func (w *sampleWindows) fill() error {
+	for w.n < len(w.buf) && !w.eof {
+		want := (len(w.buf) - w.n) * 2
+		if want > len(w.raw) {
+			want = len(w.raw)
+		}
+		got, err := io.ReadFull(w.r, w.raw[:want])
+		for i := 0; i+1 < got; i += 2 {
+			w.buf[w.n] = float32(int16(binary.LittleEndian.Uint16(w.raw[i:i+2]))) / 32768.0
+			w.n++
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			w.eof = true
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cutAtPause returns where to end a window: the middle of the last pause within
+// streamSearchSecs of its end, or its full length when there is none
+func cutAtPause(samples []float32) int {
+	from := len(samples) - streamSearchSecs*whisperSampleRate
+	if from < 0 {
+		from = 0
+	}
+	silences := findSilences(samples[from:])
+	if len(silences) == 0 {
+		return len(samples)
+	}
+	return from + silences[len(silences)-1]
+}
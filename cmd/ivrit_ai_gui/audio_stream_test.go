@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// pcm16 encodes samples as little-endian 16-bit PCM
+func pcm16(samples []float32) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(s*32767)))
+	}
+	return pcm
+}
+
+// TestSampleWindows tests that windows cover the stream contiguously and end at pauses
+func TestSampleWindows(t *testing.T) {
+	samples := toneWithPauses(200, 20)
+	size := 60 * whisperSampleRate
+	windows := newSampleWindows(iotest.HalfReader(bytes.NewReader(pcm16(samples))), size)
+
+	next := 0
+	count := 0
+	for {
+		window, start, err := windows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error: %v", err)
+		}
+		if start != next {
+			t.Fatalf("Window %d starts at %d, expected %d", count, start, next)
+		}
+		if len(window) == 0 || len(window) > size {
+			t.Fatalf("Window %d has %d samples", count, len(window))
+		}
+		next = start + len(window)
+		if next < len(samples) && samples[next] != 0 {
+			t.Errorf("Window %d ends at sample %d, which is not silent", count, next)
+		}
+		count++
+	}
+
+	if next != len(samples) {
+		t.Errorf("Windows cover %d samples, expected %d", next, len(samples))
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 windows, got %d", count)
+	}
+}
+
+// TestSampleWindowsWithoutPauses tests that audio without pauses is cut at the window size
+func TestSampleWindowsWithoutPauses(t *testing.T) {
+	samples := toneWithPauses(150, 0)
+	size := 60 * whisperSampleRate
+	windows := newSampleWindows(bytes.NewReader(pcm16(samples)), size)
+
+	lengths := []int{}
+	for {
+		window, _, err := windows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error: %v", err)
+		}
+		lengths = append(lengths, len(window))
+	}
+
+	if len(lengths) != 3 || lengths[0] != size || lengths[1] != size || lengths[2] != 30*whisperSampleRate {
+		t.Errorf("Unexpected window lengths: %v", lengths)
+	}
+}
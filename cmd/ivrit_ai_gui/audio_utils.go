@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strconv"
 )
@@ -10,7 +13,7 @@ import (
 // getAudioDuration gets the duration of an audio/video file using ffprobe
 func getAudioDuration(filePath string) (float64, error) {
 	// Use ffprobe to get duration
-	cmd := exec.Command("ffprobe",
+	cmd := exec.Command(ffprobePath(),
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
@@ -45,3 +48,62 @@ func getAudioDuration(filePath string) (float64, error) {
 	return duration, nil
 }
 
+// getAudioChannels gets the number of audio channels in the first audio stream using ffprobe
+func getAudioChannels(filePath string) (int, error) {
+	cmd := exec.Command(ffprobePath(),
+		"-v", "quiet",
+		"-print_format", "json",
+		"-show_streams",
+		"-select_streams", "a:0",
+		filePath,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+
+	var data struct {
+		Streams []struct {
+			Channels int `json:"channels"`
+		} `json:"streams"`
+	}
+
+	if err := json.Unmarshal(output, &data); err != nil {
+		return 0, err
+	}
+
+	if len(data.Streams) == 0 {
+		return 0, fmt.Errorf("no audio stream found")
+	}
+
+	return data.Streams[0].Channels, nil
+}
+
+// isCompliantWAV reports whether a file is already a 16kHz mono 16-bit PCM WAV
+// that whisper can read directly, so the ffmpeg conversion can be skipped.
+// The fmt chunk must come first.
+func isCompliantWAV(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	// RIFF header (12 bytes) followed by the fmt chunk header and body (24 bytes)
+	header := make([]byte, 36)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" || string(header[12:16]) != "fmt " {
+		return false
+	}
+
+	audioFormat := binary.LittleEndian.Uint16(header[20:22])
+	channels := binary.LittleEndian.Uint16(header[22:24])
+	sampleRate := binary.LittleEndian.Uint32(header[24:28])
+	bitsPerSample := binary.LittleEndian.Uint16(header[34:36])
+
+	return audioFormat == 1 && channels == 1 && sampleRate == 16000 && bitsPerSample == 16
+}
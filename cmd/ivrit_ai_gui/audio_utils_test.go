@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestWAV writes a minimal PCM WAV file with the given format and sample data
+func writeTestWAV(t *testing.T, path string, format uint16, channels uint16, sampleRate uint32, bitsPerSample uint16, pcm []byte) {
+	t.Helper()
+
+	blockAlign := channels * bitsPerSample / 8
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+len(pcm)))
+	copy(header[8:12], "WAVE")
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], format)
+	binary.LittleEndian.PutUint16(header[22:24], channels)
+	binary.LittleEndian.PutUint32(header[24:28], sampleRate)
+	binary.LittleEndian.PutUint32(header[28:32], sampleRate*uint32(blockAlign))
+	binary.LittleEndian.PutUint16(header[32:34], blockAlign)
+	binary.LittleEndian.PutUint16(header[34:36], bitsPerSample)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(len(pcm)))
+
+	if err := os.WriteFile(path, append(header, pcm...), 0644); err != nil {
+		t.Fatalf("Failed to write test WAV: %v", err)
+	}
+}
+
+// TestIsCompliantWAV tests detection of WAV files that can skip ffmpeg conversion
+func TestIsCompliantWAV(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-wav-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name          string
+		format        uint16
+		channels      uint16
+		sampleRate    uint32
+		bitsPerSample uint16
+		expected      bool
+	}{
+		{"16kHz mono PCM16", 1, 1, 16000, 16, true},
+		{"44.1kHz mono PCM16", 1, 1, 44100, 16, false},
+		{"16kHz stereo PCM16", 1, 2, 16000, 16, false},
+		{"16kHz mono PCM8", 1, 1, 16000, 8, false},
+		{"16kHz mono float", 3, 1, 16000, 32, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, string(rune('a'+i))+".wav")
+			writeTestWAV(t, path, tt.format, tt.channels, tt.sampleRate, tt.bitsPerSample, make([]byte, 64))
+			if result := isCompliantWAV(path); result != tt.expected {
+				t.Errorf("isCompliantWAV() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+
+	// Non-WAV and missing files are never compliant
+	notWAV := filepath.Join(tmpDir, "audio.mp3")
+	os.WriteFile(notWAV, []byte("ID3 not a wav file at all, just some bytes"), 0644)
+	if isCompliantWAV(notWAV) {
+		t.Error("Non-WAV file should not be compliant")
+	}
+	if isCompliantWAV(filepath.Join(tmpDir, "missing.wav")) {
+		t.Error("Missing file should not be compliant")
+	}
+}
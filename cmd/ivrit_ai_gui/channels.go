@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// Channel modes for multi-channel input
+const (
+	ChannelModeMix   = "mix"   // Downmix all channels to mono (default)
+	ChannelModeSplit = "split" // Transcribe each channel separately, one speaker per channel
+)
+
+// TranscribeByChannel transcribes each audio channel on its own and merges the
+// results, labelling every segment with its channel as the speaker. This suits
+// call recordings where each participant is on a separate channel.
+// Inputs with a single channel are transcribed normally. When the channels can't be
+// counted, e.g. without ffprobe, it fails rather than transcribe the mix unasked.
+func TranscribeByChannel(engine TranscriptionEngine, audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	channels, err := getAudioChannels(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot split the channels of %s: %v", filepath.Base(audioPath), err)
+	}
+	if channels <= 1 {
+		return engine.Transcribe(audioPath, modelID, cpuThreads, progressCallback, segmentCallback)
+	}
+
+	perChannel := make([][]Segment, 0, channels)
+	for ch := 0; ch < channels; ch++ {
+		channel := ch
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Extracting channel %d/%d...", channel+1, channels))
+		}
+
+		channelPath, err := extractAudioChannel(audioPath, channel)
+		if err != nil {
+			return nil, err
+		}
+
+		var channelProgress func(string)
+		if progressCallback != nil {
+			channelProgress = func(msg string) {
+				progressCallback(fmt.Sprintf("Channel %d/%d: %s", channel+1, channels, msg))
+			}
+		}
+		var channelSegment func(Segment)
+		if segmentCallback != nil {
+			channelSegment = func(seg Segment) {
+				seg.Speaker = channel
+				segmentCallback(seg)
+			}
+		}
+
+		segments, err := engine.Transcribe(channelPath, modelID, cpuThreads, channelProgress, channelSegment)
+		RemoveTempFile(channelPath)
+		if err != nil {
+			return nil, fmt.Errorf("channel %d: %v", channel+1, err)
+		}
+		perChannel = append(perChannel, segments)
+	}
+
+	return mergeChannelSegments(perChannel), nil
+}
+
+// mergeChannelSegments interleaves per-channel segments by start time and
+// assigns each segment's speaker from its channel index
+func mergeChannelSegments(perChannel [][]Segment) []Segment {
+	merged := []Segment{}
+	for channel, segments := range perChannel {
+		for _, seg := range segments {
+			seg.Speaker = channel
+			merged = append(merged, seg)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Start != merged[j].Start {
+			return merged[i].Start < merged[j].Start
+		}
+		return merged[i].Speaker < merged[j].Speaker
+	})
+
+	return merged
+}
+
+// extractAudioChannel extracts a single channel to a 16kHz mono WAV using ffmpeg
+func extractAudioChannel(audioPath string, channel int) (string, error) {
+	tempFile, err := CreateTempFile("channel_audio_*.wav")
+	if err != nil {
+		return "", err
+	}
+	tempPath := tempFile.Name()
+	tempFile.Close()
+
+	cmd := exec.Command(ffmpegPath(),
+		"-i", audioPath,
+		"-vn",                                          // No video
+		"-af", fmt.Sprintf("pan=mono|c0=c%d", channel), // Keep only this channel
+		"-acodec", "pcm_s16le", // PCM 16-bit
+		"-ar", "16000", // 16kHz sample rate
+		"-y", // Overwrite output file
+		tempPath,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		RemoveTempFile(tempPath)
+		return "", fmt.Errorf("ffmpeg channel extraction failed: %v", err)
+	}
+
+	return tempPath, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+// TestMergeChannelSegments tests interleaving of per-channel segments
+func TestMergeChannelSegments(t *testing.T) {
+	perChannel := [][]Segment{
+		{
+			{Start: 0.0, End: 2.0, Text: "שלום", Speaker: 3},
+			{Start: 5.0, End: 6.0, Text: "להתראות"},
+		},
+		{
+			{Start: 2.5, End: 4.0, Text: "היי"},
+			{Start: 5.0, End: 5.5, Text: "ביי"},
+		},
+	}
+
+	merged := mergeChannelSegments(perChannel)
+
+	expected := []struct {
+		text    string
+		speaker int
+	}{
+		{"שלום", 0},
+		{"היי", 1},
+		{"להתראות", 0},
+		{"ביי", 1},
+	}
+
+	if len(merged) != len(expected) {
+		t.Fatalf("Expected %d segments, got %d", len(expected), len(merged))
+	}
+	for i, exp := range expected {
+		if merged[i].Text != exp.text {
+			t.Errorf("Segment %d: expected text %q, got %q", i, exp.text, merged[i].Text)
+		}
+		if merged[i].Speaker != exp.speaker {
+			t.Errorf("Segment %d: expected speaker %d, got %d", i, exp.speaker, merged[i].Speaker)
+		}
+	}
+}
+
+// TestTranscribeByChannelNoProbe tests that splitting fails, rather than transcribing
+// the mix, when the channels can't be counted
+func TestTranscribeByChannelNoProbe(t *testing.T) {
+	SetFFmpegPaths("/nonexistent/ffmpeg", "/nonexistent/ffprobe")
+	defer SetFFmpegPaths("", "")
+
+	engine := &fakeEngine{segments: []Segment{{Start: 0, End: 1, Text: "שלום"}}}
+	if _, err := TranscribeByChannel(engine, "call.m4a", "turbo", 4, nil, nil); err == nil {
+		t.Error("Expected an error when the channels can't be counted")
+	}
+	if engine.calls != 0 {
+		t.Errorf("Expected nothing transcribed, got %d calls", engine.calls)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chat apps whose exports are imported
+const (
+	ChatAppWhatsApp = "WhatsApp"
+	ChatAppTelegram = "Telegram"
+)
+
+// voiceNoteExtensions are the audio attachments of a chat transcribed: voice notes
+// are Opus in .opus (WhatsApp) or .ogg (Telegram); the others are audio files shared
+var voiceNoteExtensions = []string{".opus", ".ogg", ".oga", ".m4a", ".aac", ".amr", ".mp3"}
+
+// ChatVoiceNote is a voice note of an exported chat
+type ChatVoiceNote struct {
+	Path   string
+	Sender string
+	Time   time.Time
+}
+
+// ChatExport is a chat exported from WhatsApp ("Export chat" with media) or Telegram
+// Desktop ("Export chat history" as machine-readable JSON) to a folder
+type ChatExport struct {
+	App     string
+	Name    string // The chat's name, e.g. the contact or group
+	Dir     string
+	Notes   []ChatVoiceNote // In the order they were sent
+	Missing int             // Voice notes the export names but left out
+}
+
+// Describe returns a line summarizing the export, e.g.
+// "WhatsApp chat \"Dana\": 12 voice notes"
+func (c *ChatExport) Describe() string {
+	desc := fmt.Sprintf("%s chat %q: %d voice notes", c.App, c.Name, len(c.Notes))
+	if c.Missing > 0 {
+		desc += fmt.Sprintf(" (%d not included in the export)", c.Missing)
+	}
+	return desc
+}
+
+// ReadChatExport reads the voice notes of an exported chat folder, with their
+// senders and times from the export's chat log
+func ReadChatExport(dir string) (*ChatExport, error) {
+	if data, err := os.ReadFile(filepath.Join(dir, "result.json")); err == nil {
+		return parseTelegramExport(dir, data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "_chat.txt" || (strings.HasPrefix(name, "WhatsApp") && strings.HasSuffix(name, ".txt")) {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			return parseWhatsAppExport(dir, name, string(data)), nil
+		}
+	}
+	return nil, fmt.Errorf("%s isn't an exported chat: expected WhatsApp's _chat.txt (or \"WhatsApp Chat with ….txt\") or Telegram's result.json", dir)
+}
+
+// whatsAppLine matches the first line of a message in both layouts of WhatsApp's
+// chat log, iOS ("[05/03/2024, 14:07:09] Dana: …") and Android
+// ("05/03/2024, 14:07 - Dana: …"), with a 12 or 24-hour clock
+var whatsAppLine = regexp.MustCompile(`^\[?(\d{1,2})[./-](\d{1,2})[./-](\d{2,4}),? (\d{1,2}):(\d{2})(?::(\d{2}))?\s?([AaPp]\.?[Mm]\.?)?(?:\]| -) ([^:]+): (.*)$`)
+
+// whatsAppAttachment matches the file name of an attachment in a message, e.g.
+// "<attached: 00000012-AUDIO-2024-03-05-14-07-09.opus>" on iOS or
+// "PTT-20240305-WA0003.opus (file attached)" on Android, in any language
+var whatsAppAttachment = regexp.MustCompile(`[^\s<>:]+\.[A-Za-z0-9]+`)
+
+// parseWhatsAppExport reads the voice notes of a WhatsApp chat log. The log's dates
+// are in the phone's format: day first unless a date shows otherwise.
+func parseWhatsAppExport(dir, logName, log string) *ChatExport {
+	chat := &ChatExport{App: ChatAppWhatsApp, Dir: dir, Name: whatsAppChatName(dir, logName)}
+
+	var matches [][]string
+	monthFirst, decided := false, false
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.Trim(line, "\r\u200e\u200f\ufeff ")
+		m := whatsAppLine.FindStringSubmatch(line)
+		if m == nil {
+			continue // A message's following lines, or a notice
+		}
+		if !decided && atoi(m[1]) > 12 {
+			decided = true
+		} else if !decided && atoi(m[2]) > 12 {
+			monthFirst, decided = true, true
+		}
+		matches = append(matches, m)
+	}
+
+	for _, m := range matches {
+		for _, name := range whatsAppAttachment.FindAllString(strings.ReplaceAll(m[9], "\u200e", ""), -1) {
+			if !containsString(voiceNoteExtensions, strings.ToLower(filepath.Ext(name))) {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err != nil {
+				chat.Missing++
+				continue
+			}
+			chat.Notes = append(chat.Notes, ChatVoiceNote{Path: path, Sender: strings.TrimSpace(m[8]), Time: whatsAppTime(m, monthFirst)})
+		}
+	}
+	return chat
+}
+
+// whatsAppTime returns when a message of the chat log was sent
+func whatsAppTime(m []string, monthFirst bool) time.Time {
+	day, month, year := atoi(m[1]), atoi(m[2]), atoi(m[3])
+	if monthFirst {
+		day, month = month, day
+	}
+	if year < 100 {
+		year += 2000
+	}
+	hour := atoi(m[4])
+	switch strings.ToLower(strings.ReplaceAll(m[7], ".", "")) {
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	}
+	return time.Date(year, time.Month(month), day, hour, atoi(m[5]), atoi(m[6]), 0, time.Local)
+}
+
+// atoi returns the number in s, or 0
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// whatsAppChatName returns the chat's name from the log's file name on Android
+// ("WhatsApp Chat with Dana.txt"), or the export folder's on iOS
+// ("WhatsApp Chat - Dana")
+func whatsAppChatName(dir, logName string) string {
+	name := strings.TrimSuffix(logName, ".txt")
+	if logName == "_chat.txt" {
+		name = filepath.Base(dir)
+	}
+	for _, prefix := range []string{"WhatsApp Chat with ", "WhatsApp Chat - ", "WhatsApp Chat "} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+	return name
+}
+
+// telegramExport is the part of Telegram Desktop's result.json that is read
+type telegramExport struct {
+	Name     string `json:"name"`
+	Messages []struct {
+		Type      string `json:"type"`
+		Date      string `json:"date"`
+		From      string `json:"from"`
+		File      string `json:"file"`
+		MediaType string `json:"media_type"`
+	} `json:"messages"`
+}
+
+// parseTelegramExport reads the voice messages of a Telegram chat export
+func parseTelegramExport(dir string, data []byte) (*ChatExport, error) {
+	var export telegramExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("cannot read the Telegram export: %v", err)
+	}
+	chat := &ChatExport{App: ChatAppTelegram, Dir: dir, Name: export.Name}
+	for _, msg := range export.Messages {
+		if msg.Type != "message" || msg.MediaType != "voice_message" {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(msg.File))
+		if _, err := os.Stat(path); msg.File == "" || err != nil {
+			chat.Missing++ // "(File not included. …)" when media wasn't exported
+			continue
+		}
+		sent, _ := time.ParseInLocation("2006-01-02T15:04:05", msg.Date, time.Local)
+		chat.Notes = append(chat.Notes, ChatVoiceNote{Path: path, Sender: msg.From, Time: sent})
+	}
+	sort.SliceStable(chat.Notes, func(i, j int) bool { return chat.Notes[i].Time.Before(chat.Notes[j].Time) })
+	return chat, nil
+}
+
+// ChatNoteTranscript is the transcript of one voice note
+type ChatNoteTranscript struct {
+	Note ChatVoiceNote
+	Text string
+	Err  error
+}
+
+// TranscribeChat transcribes the voice notes of a chat in order. A note that fails
+// doesn't stop the others; its error is kept with it.
+func TranscribeChat(engine TranscriptionEngine, chat *ChatExport, modelID string, cpuThreads int, progressCallback func(string)) ([]ChatNoteTranscript, error) {
+	if len(chat.Notes) == 0 {
+		return nil, errors.New("the chat has no voice notes to transcribe")
+	}
+	results := make([]ChatNoteTranscript, 0, len(chat.Notes))
+	for i, note := range chat.Notes {
+		var noteProgress func(string)
+		if progressCallback != nil {
+			n := i + 1
+			noteProgress = func(msg string) {
+				progressCallback(fmt.Sprintf("Voice note %d/%d: %s", n, len(chat.Notes), msg))
+			}
+		}
+		segments, err := engine.Transcribe(note.Path, modelID, cpuThreads, noteProgress, nil)
+		if errors.Is(err, ErrInterrupted) {
+			return results, err
+		}
+		texts := make([]string, 0, len(segments))
+		for _, seg := range segments {
+			if text := strings.TrimSpace(seg.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		results = append(results, ChatNoteTranscript{Note: note, Text: strings.Join(texts, " "), Err: err})
+	}
+	return results, nil
+}
+
+// FormatChatTranscript writes the transcripts of a chat's voice notes as a chat, a
+// line per note with when it was sent and by whom
+func FormatChatTranscript(chat *ChatExport, results []ChatNoteTranscript) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s chat: %s\n", chat.App, chat.Name)
+	fmt.Fprintf(&b, "%d voice notes transcribed\n", len(results))
+	lastDay := ""
+	for _, result := range results {
+		if day := result.Note.Time.Format("2006-01-02"); day != lastDay {
+			fmt.Fprintf(&b, "\n%s\n", day)
+			lastDay = day
+		}
+		text := result.Text
+		if result.Err != nil {
+			text = fmt.Sprintf("(%s could not be transcribed: %v)", filepath.Base(result.Note.Path), result.Err)
+		} else if text == "" {
+			text = "(no speech)"
+		}
+		fmt.Fprintf(&b, "[%s] %s: %s\n", result.Note.Time.Format("15:04"), result.Note.Sender, text)
+	}
+	return b.String()
+}
+
+// chatTranscriptFileName names a chat's transcript after the chat
+func chatTranscriptFileName(chat *ChatExport) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, chat.Name)
+	if name == "" {
+		name = "chat"
+	}
+	return name + "_voice_notes.txt"
+}
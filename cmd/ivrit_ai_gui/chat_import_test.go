@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeChatFiles creates the files of an exported chat in a temp folder
+func writeChatFiles(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	dir = filepath.Join(t.TempDir(), dir)
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestReadWhatsAppExportIOS tests reading the voice notes of an iPhone export, whose
+// log is _chat.txt in a folder named after the chat
+func TestReadWhatsAppExportIOS(t *testing.T) {
+	dir := writeChatFiles(t, "WhatsApp Chat - Dana", map[string]string{
+		"_chat.txt": "[05/03/2024, 14:07:09] Dana: שלום, מה נשמע?\n" +
+			"[05/03/2024, 14:07:30] Dana: ‎<attached: 00000012-AUDIO-2024-03-05-14-07-30.opus>\n" +
+			"[05/03/2024, 14:09:02] Yossi: ‎<attached: 00000013-PHOTO-2024-03-05-14-09-02.jpg>\n" +
+			"[06/03/2024, 09:15:00] Yossi: ‎<attached: 00000014-AUDIO-2024-03-06-09-15-00.opus>\n" +
+			"and a second line\n" +
+			"[06/03/2024, 09:16:00] Yossi: ‎<attached: 00000015-AUDIO-2024-03-06-09-16-00.opus>\n",
+		"00000012-AUDIO-2024-03-05-14-07-30.opus": "audio",
+		"00000013-PHOTO-2024-03-05-14-09-02.jpg":  "photo",
+		"00000014-AUDIO-2024-03-06-09-15-00.opus": "audio",
+	})
+
+	chat, err := ReadChatExport(dir)
+	if err != nil {
+		t.Fatalf("ReadChatExport() error: %v", err)
+	}
+	if chat.App != ChatAppWhatsApp || chat.Name != "Dana" || chat.Missing != 1 {
+		t.Errorf("Unexpected chat: %s", chat.Describe())
+	}
+	if len(chat.Notes) != 2 {
+		t.Fatalf("Expected 2 voice notes, got %+v", chat.Notes)
+	}
+	want := ChatVoiceNote{
+		Path:   filepath.Join(dir, "00000014-AUDIO-2024-03-06-09-15-00.opus"),
+		Sender: "Yossi",
+		Time:   time.Date(2024, 3, 6, 9, 15, 0, 0, time.Local),
+	}
+	if note := chat.Notes[1]; note.Path != want.Path || note.Sender != want.Sender || !note.Time.Equal(want.Time) {
+		t.Errorf("Unexpected voice note: %+v", note)
+	}
+}
+
+// TestReadWhatsAppExportAndroid tests an Android export's log, with dates month
+// first and a 12-hour clock
+func TestReadWhatsAppExportAndroid(t *testing.T) {
+	dir := writeChatFiles(t, "export", map[string]string{
+		"WhatsApp Chat with Family.txt": "3/5/24, 2:07 PM - Dana: PTT-20240305-WA0003.opus (file attached)\n" +
+			"3/15/24, 12:30 AM - Avi: PTT-20240315-WA0001.opus (file attached)\n",
+		"PTT-20240305-WA0003.opus": "audio",
+		"PTT-20240315-WA0001.opus": "audio",
+	})
+
+	chat, err := ReadChatExport(dir)
+	if err != nil {
+		t.Fatalf("ReadChatExport() error: %v", err)
+	}
+	if chat.Name != "Family" || len(chat.Notes) != 2 {
+		t.Fatalf("Unexpected chat: %s", chat.Describe())
+	}
+	if want := time.Date(2024, 3, 5, 14, 7, 0, 0, time.Local); !chat.Notes[0].Time.Equal(want) {
+		t.Errorf("First note sent %v, want %v", chat.Notes[0].Time, want)
+	}
+	if want := time.Date(2024, 3, 15, 0, 30, 0, 0, time.Local); !chat.Notes[1].Time.Equal(want) {
+		t.Errorf("Second note sent %v, want %v", chat.Notes[1].Time, want)
+	}
+}
+
+// TestReadTelegramExport tests reading the voice messages of Telegram's result.json
+func TestReadTelegramExport(t *testing.T) {
+	dir := writeChatFiles(t, "ChatExport_2024-03-06", map[string]string{
+		"result.json": `{"name": "Dana", "type": "personal_chat", "messages": [
+			{"id": 1, "type": "message", "date": "2024-03-05T14:07:09", "from": "Dana", "text": "שלום"},
+			{"id": 2, "type": "message", "date": "2024-03-05T14:08:00", "from": "Dana", "file": "voice_messages/audio_1@05-03-2024_14-08-00.ogg", "media_type": "voice_message"},
+			{"id": 3, "type": "service", "date": "2024-03-05T14:09:00", "action": "phone_call"},
+			{"id": 4, "type": "message", "date": "2024-03-05T14:10:00", "from": "Me", "file": "(File not included. Change data exporting settings to download.)", "media_type": "voice_message"}
+		]}`,
+		"voice_messages/audio_1@05-03-2024_14-08-00.ogg": "audio",
+	})
+
+	chat, err := ReadChatExport(dir)
+	if err != nil {
+		t.Fatalf("ReadChatExport() error: %v", err)
+	}
+	if chat.App != ChatAppTelegram || chat.Name != "Dana" || len(chat.Notes) != 1 || chat.Missing != 1 {
+		t.Fatalf("Unexpected chat: %s", chat.Describe())
+	}
+	if note := chat.Notes[0]; note.Sender != "Dana" || filepath.Base(note.Path) != "audio_1@05-03-2024_14-08-00.ogg" {
+		t.Errorf("Unexpected voice note: %+v", note)
+	}
+
+	if _, err := ReadChatExport(t.TempDir()); err == nil {
+		t.Error("Expected an error for a folder that isn't an exported chat")
+	}
+}
+
+// TestTranscribeChat tests transcribing the notes in order into a chat-style transcript
+func TestTranscribeChat(t *testing.T) {
+	chat := &ChatExport{App: ChatAppWhatsApp, Name: "Dana", Notes: []ChatVoiceNote{
+		{Path: "a.opus", Sender: "Dana", Time: time.Date(2024, 3, 5, 14, 7, 0, 0, time.Local)},
+		{Path: "b.opus", Sender: "Yossi", Time: time.Date(2024, 3, 5, 14, 9, 0, 0, time.Local)},
+		{Path: "c.opus", Sender: "Dana", Time: time.Date(2024, 3, 6, 9, 15, 0, 0, time.Local)},
+	}}
+	engine := &trackEngine{byPath: map[string][]Segment{
+		"a.opus": {{Text: " שלום,"}, {Text: " מה נשמע?"}},
+		"c.opus": {{Text: " נדבר מחר"}},
+	}}
+	results, err := TranscribeChat(engine, chat, "turbo", 4, nil)
+	if err != nil {
+		t.Fatalf("TranscribeChat() error: %v", err)
+	}
+	results[1].Err = errors.New("invalid data") // A note ffmpeg can't read
+
+	got := FormatChatTranscript(chat, results)
+	want := "WhatsApp chat: Dana\n3 voice notes transcribed\n" +
+		"\n2024-03-05\n" +
+		"[14:07] Dana: שלום, מה נשמע?\n" +
+		"[14:09] Yossi: (b.opus could not be transcribed: invalid data)\n" +
+		"\n2024-03-06\n" +
+		"[09:15] Dana: נדבר מחר\n"
+	if got != want {
+		t.Errorf("Unexpected transcript:\n%s\nwant:\n%s", got, want)
+	}
+	if name := chatTranscriptFileName(&ChatExport{Name: "Family: 2024/25"}); name != "Family_ 2024_25_voice_notes.txt" || strings.Contains(name, "/") {
+		t.Errorf("chatTranscriptFileName() = %q", name)
+	}
+}
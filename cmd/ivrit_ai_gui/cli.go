@@ -1,93 +1,577 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // CLIMode runs the application in command-line mode
 func CLIMode() {
+	// Options shared with the GUI come from the config file and environment;
+	// flags registered below override them
+	cfg, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// A preset sets options over those; flags still override it
+	if name := presetArg(os.Args[1:]); name != "" {
+		if err := cfg.ApplyPreset(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Ctrl+C stops transcribing and cleans up; files a crashed run left behind go now
+	ShutdownOnSignal()
+	CleanOrphanedTempFiles()
+
 	// Define command-line flags
-	audioFile := flag.String("input", "", "Input audio/video file path (required)")
-	outputFile := flag.String("output", "", "Output file path (default: transcription.txt)")
-	modelID := flag.String("model", "turbo", "Model to use: large-v3, turbo, or base")
-	format := flag.String("format", "text", "Output format: text, json, srt, or vtt")
-	translate := flag.Bool("translate", false, "Translate to English using Mistral 8B")
-	targetLang := flag.String("lang", "en", "Target language for translation: en, es, fr, de")
-	keepOriginal := flag.Bool("keep-original", true, "Keep original Hebrew text when translating")
-	cpuThreads := flag.Int("threads", 0, "Number of CPU threads (0 = auto)")
+	audioFile := flag.String("input", "", "Input audio/video file path (required; more files may follow as arguments)")
+	outputFile := flag.String("output", "", "Output file path, or output directory when transcribing several files (default: <input>_transcription.<ext>)")
+	fromTime := flag.String("from", "", "Start transcribing at this time (HH:MM:SS, MM:SS or seconds)")
+	toTime := flag.String("to", "", "Stop transcribing at this time (HH:MM:SS, MM:SS or seconds)")
+	grpcAddr := flag.String("grpc", "", "Serve the gRPC API on this address (e.g. :50051) instead of transcribing files")
+	exportTo := flag.String("export", "", "Also export each transcript to: google-docs, notion (comma-separated)")
+	login := flag.String("login", "", "Connect an export integration (google or notion) and exit")
+	mediaURL := flag.String("media-url", "", "Link markdown timestamps to this URL of the recording (default: the input file, relative to the output)")
+	minutes := flag.Bool("minutes", false, "Also write meeting minutes (attendees, decisions, action items) as <input>_minutes.md using the local LLM")
+	interactiveReview := flag.Bool("review", false, "After transcribing, go through the segments one at a time in the terminal to accept, edit or skip each before the output is translated and written")
+	splitSpeakers := flag.Bool("split-speakers", false, "Also write one file per speaker with only that speaker's segments and their timestamps, as <output>_speaker<N>.<ext> (all formats but html)")
+	splitEvery := flag.String("split-every", "", "Also write the transcript in stretches of this length (e.g. 10m), as <output>_01.<ext>, <output>_02.<ext>... timed from the start of each with renumbered cues; \"parts\" splits a -join recording at its files (all formats but html and markdown)")
+	vttTracks := flag.Bool("vtt-tracks", false, "With -translate, also write one VTT per language (<output>.he.vtt, <output>.en.vtt...) with an HLS playlist each, and an HLS manifest <output>.m3u8 offering them as selectable subtitles")
+	manifestPath := flag.String("manifest", "", "Also write a JSON manifest of the run to this file: each input's hash, the model, parameters, audio duration, processing time, realtime factor and the files written")
+	checkTranslation := flag.Bool("check-translation", false, "With -translate, translate each segment back to Hebrew and flag those whose back-translation differs much from the original, appended to text/markdown output (other formats: <input>_translation_review.md)")
+	speakerStats := flag.Bool("speaker-stats", false, "Append per-speaker talk time, word count, share and longest monologue to text/markdown output (other formats: <input>_speakers.md)")
+	coreML := flag.Bool("coreml", true, "Use the Core ML encoder on Apple Silicon when available (-coreml=false runs without; the GUI setting also applies)")
+	tuneThreads := flag.Bool("tune-threads", false, "Measure the fastest thread count for -model again (done automatically the first time a model is used with -threads 0)")
+	checkModels := flag.Bool("check-model-updates", false, "Check the downloaded models for updated versions published on HuggingFace, and exit")
+	updateModels := flag.Bool("update-models", false, "Download the updated versions of the downloaded models, replacing each model once the new file is verified, and exit")
+	redownloadModel := flag.Bool("redownload-model", false, "Download -model again, replacing a damaged model file once the new one is complete, and exit")
+	listModels := flag.Bool("list-models", false, "List the models, which are downloaded and where, and exit")
+	flag.String("preset", "", "Use the options of a named preset (built-in: \"Quick draft\", \"Podcast publish\", \"Legal verbatim\"; more are saved from the GUI or in presets.json); other flags override it")
+	listPresets := flag.Bool("list-presets", false, "List the presets and their options, and exit")
+	listVoiceMemos := flag.Bool("list-voice-memos", false, "List the recent recordings of the Voice Memos app (macOS), numbered for \"voice-memos <n>\", and exit")
+	moveModels := flag.String("move-models", "", "Move the models downloaded to this folder (e.g. ~/.cache/whisper) to -model-dir, and exit")
+	translateDir := flag.String("translate-dir", "", "Translate the saved transcripts (txt, srt, vtt, json) in this directory to -lang, writing <name>_<lang>.<ext> next to each (or under -output) instead of transcribing")
+	chatDir := flag.String("chat", "", "Transcribe the voice notes of a WhatsApp or Telegram chat exported to this folder, in order, into one chat-style transcript with each note's sender and time (default: <chat>_voice_notes.txt in the folder, or -output)")
+	checkUpdate := flag.Bool("check-update", false, "Check GitHub for a newer release of the app, show its changelog and installer link, and exit")
+	reuse := flag.Bool("reuse", false, "When an input has the same audio as a file transcribed before under another name, load that transcript instead of transcribing it again (without it, a note names the earlier transcript)")
+	participants := flag.Bool("participants", false, "When -input is a meeting recording with a track per participant (a Zoom recording folder with \"Audio Record\", or a multi-track recorder's folder), transcribe each track with the participant's name as the speaker")
+	startTime := flag.String("start-time", "", "When the recording started, e.g. \"2024-05-03 10:00\" (default: from <input>.start, or the date and time in the file name, as Zoom and phone recorders write them); recorded in the JSON manifest")
+	wallClock := flag.Bool("wall-clock", false, "Show the time of day instead of the time into the recording in SRT, VTT and markdown output, counting from -start-time")
+	join := flag.Bool("join", false, "Transcribe -input and the files after it as one recording, joined in the order given (or the audio files of an -input folder in name order, e.g. the tracks of an audio CD), noting where each file starts")
+	doctor := flag.Bool("doctor", false, "Check ffmpeg, the whisper.cpp library, the model folder and model, and ollama, saying what to fix, and exit")
+	demo := flag.Bool("demo", false, "Transcribe a short Hebrew sample clip bundled with the app, to check that everything works")
+	completion := flag.String("completion", "", "Print the completion script for bash, zsh or fish (e.g. source <(ivrit_ai -completion bash)), and exit")
+	version := flag.Bool("version", false, "Show the app version and the whisper.cpp library's version and features, then exit")
 	help := flag.Bool("help", false, "Show help message")
+	cfg.RegisterFlags(flag.CommandLine)
+
+	// A subcommand takes its own flags and sets the flat ones from its arguments
+	program := filepath.Base(os.Args[0])
+	var args []string
+	if len(os.Args) > 1 && os.Args[1] == "help" {
+		if subcommandHelp(program, flag.CommandLine, os.Args[2:]) {
+			return
+		}
+		*help = true
+	} else if len(os.Args) > 1 && FindSubcommand(os.Args[1]) != nil {
+		args, err = FindSubcommand(os.Args[1]).Parse(program, flag.CommandLine, os.Args[2:])
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	} else {
+		flag.Parse()
+		args = flag.Args()
+	}
+	SetCoreMLEnabled(*coreML && LoadSettings().CoreMLEncoder)
+	SetModelDir(cfg.ModelStorageDir(LoadSettings()))
+	if err := SetNetworkOptions(cfg.Network); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *version {
+		printVersion()
+		return
+	}
+
+	if *completion != "" {
+		script, err := CompletionScript(*completion, program, flag.CommandLine)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
+		return
+	}
+
+	if *doctor {
+		if !PrintDoctorReport(os.Stdout, RunDoctor(cfg)) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *listModels {
+		listModelsMode(cfg.Model)
+		return
+	}
+
+	if *listPresets {
+		presets, err := LoadPresets()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		printPresets(presets)
+		return
+	}
+
+	if *listVoiceMemos {
+		memos, err := ListVoiceMemos(0)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printVoiceMemos(os.Stdout, memos)
+		return
+	}
+
+	if *checkUpdate {
+		SetDownloadOptions(cfg.Download)
+		if err := appUpdateMode(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *login != "" {
+		if err := Login(*login, os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *moveModels != "" {
+		if cfg.ModelDir == "" {
+			fmt.Fprintln(os.Stderr, "Error: -move-models needs -model-dir, the folder to move the models to")
+			os.Exit(1)
+		}
+		moved, err := MoveModels(*moveModels, cfg.ModelDir, func(msg string) {
+			fmt.Println(msg)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Moved %d model files to %s\n", moved, cfg.ModelDir)
+		return
+	}
+
+	if *checkModels || *updateModels {
+		SetDownloadOptions(cfg.Download)
+		if err := modelUpdatesMode(*updateModels); err != nil {
+			fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *redownloadModel {
+		SetDownloadOptions(cfg.Download)
+		err := RedownloadModel(cfg.Model, func(msg string, pct int) {
+			fmt.Printf("\r%s  ", msg)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nDownloaded the %s model again\n", cfg.Model)
+		return
+	}
+
+	// Server mode: the shared options become the defaults for API requests
+	if *grpcAddr != "" {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		SetFFmpegPaths(cfg.FFmpegPath, cfg.FFprobePath)
+		SetDownloadOptions(cfg.Download)
+		SetSpeakerLabels(cfg.SpeakerLabels)
+		if err := SetExportScript(cfg.Script); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := CheckFFmpeg(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		warnMissingWhisperFeatures()
+		if notice := CloudUploadNotice(cfg); notice != "" {
+			fmt.Fprintf(os.Stderr, "Privacy: %s\n", notice)
+		}
+		if err := ServeGRPC(*grpcAddr, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Batch translation of transcripts saved by an earlier run
+	if *translateDir != "" {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !containsString(validTargetLangs, cfg.TargetLang) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid target language '%s'. Valid options: %s\n", cfg.TargetLang, strings.Join(validTargetLangs, ", "))
+			os.Exit(1)
+		}
+		if err := translateDirMode(*translateDir, *outputFile, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Voice notes of an exported chat
+	if *chatDir != "" {
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		SetFFmpegPaths(cfg.FFmpegPath, cfg.FFprobePath)
+		SetDownloadOptions(cfg.Download)
+		if err := CheckFFmpeg(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := chatMode(*chatDir, *outputFile, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "\nError: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	flag.Parse()
+	// Demo: the bundled sample clip is the input
+	if *demo && !*help {
+		samplePath, err := writeSampleClip()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		*audioFile = samplePath
+		fmt.Println("Demo: transcribing a short sample clip bundled with the app")
+	}
 
 	// Show help
 	if *help || *audioFile == "" {
 		fmt.Println("ivrit.ai Hebrew Transcription CLI")
 		fmt.Println("\nUsage:")
-		fmt.Printf("  %s -input <audio-file> [options]\n\n", os.Args[0])
+		fmt.Printf("  %s <command> [options] [arguments]\n", os.Args[0])
+		fmt.Printf("  %s -input <audio-file> [options] [more-audio-files...]\n\n", os.Args[0])
+		printSubcommands(os.Stdout, os.Args[0])
 		fmt.Println("Options:")
 		flag.PrintDefaults()
 		fmt.Println("\nExamples:")
 		fmt.Printf("  %s -input recording.m4a\n", os.Args[0])
+		fmt.Printf("  %s transcribe -format srt lecture.mp4\n", os.Args[0])
+		fmt.Printf("  %s batch -output transcripts/ recordings/\n", os.Args[0])
+		fmt.Printf("  %s models check\n", os.Args[0])
+		fmt.Printf("  %s doctor\n", os.Args[0])
+		fmt.Printf("  %s -demo\n", os.Args[0])
 		fmt.Printf("  %s -input video.mp4 -model large-v3 -format srt -output subtitles.srt\n", os.Args[0])
-		fmt.Printf("  %s -input audio.wav -translate -lang en -keep-original=false\n", os.Args[0])
+		fmt.Printf("  %s -input audio.wav -translate -lang en -display translation\n", os.Args[0])
+		fmt.Printf("  %s -input lecture.mp3 -from 00:10:00 -to 00:25:00\n", os.Args[0])
+		fmt.Printf("  %s -input 4-hour-meeting.m4a -parallel 4\n", os.Args[0])
+		fmt.Printf("  %s -output transcripts/ -input first.m4a second.m4a third.m4a\n", os.Args[0])
+		fmt.Printf("  %s -join -input part1.mp3 part2.mp3\n", os.Args[0])
+		fmt.Printf("  %s -reuse -input copy-of-interview.m4a\n", os.Args[0])
+		fmt.Printf("  %s -participants -input \"2024-05-02 10.00.00 Weekly Sync 81234567890\"\n", os.Args[0])
+		fmt.Printf("  %s -input hearing.m4a -format srt -wall-clock -start-time \"2024-05-03 10:00\"\n", os.Args[0])
+		fmt.Printf("  %s -grpc :50051\n", os.Args[0])
+		fmt.Printf("  %s -login notion && %s -input interview.m4a -export notion\n", os.Args[0], os.Args[0])
+		fmt.Printf("  %s -input meeting.m4a -minutes -translate -lang en\n", os.Args[0])
+		fmt.Printf("  %s -input sermon.m4a -format markdown -translate -lang en -check-translation\n", os.Args[0])
+		fmt.Printf("  %s -input lecture.m4a -translate -lang en -glossary names.txt\n", os.Args[0])
+		fmt.Printf("  %s -input interview.m4a -format markdown -speaker-stats\n", os.Args[0])
+		fmt.Printf("  %s -input meeting.m4a -channels split -split-speakers\n", os.Args[0])
+		fmt.Printf("  %s -input lecture.mp4 -format srt -split-every 10m\n", os.Args[0])
+		fmt.Printf("  %s -input call.m4a -redact -redact-words names.txt\n", os.Args[0])
+		fmt.Printf("  %s -input deposition.m4a -consensus models\n", os.Args[0])
+		fmt.Printf("  %s -input voicemail.m4a -review\n", os.Args[0])
+		fmt.Printf("  %s -translate-dir transcripts/ -lang fr\n", os.Args[0])
+		fmt.Printf("  %s -chat \"WhatsApp Chat - Dana\"\n", os.Args[0])
+		fmt.Printf("  %s -check-model-updates\n", os.Args[0])
+		fmt.Printf("  %s -check-update\n", os.Args[0])
+		fmt.Printf("  %s -model turbo -redownload-model\n", os.Args[0])
+		fmt.Printf("  %s -model-dir /Volumes/External/models -move-models ~/.cache/whisper\n", os.Args[0])
+		fmt.Printf("\nDefaults for the shared options can be set in %s or IVRIT_* environment variables.\n", configPath())
 		if *audioFile == "" {
 			os.Exit(1)
 		}
 		os.Exit(0)
 	}
 
-	// Validate input file
-	if _, err := os.Stat(*audioFile); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: Input file does not exist: %s\n", *audioFile)
+	// Collect input files: -input plus any trailing arguments (batch mode)
+	inputs := append([]string{*audioFile}, args...)
+
+	// Validate input files
+	for _, input := range inputs {
+		if _, err := os.Stat(input); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: Input file does not exist: %s\n", input)
+			os.Exit(1)
+		}
+	}
+
+	// Validate options
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	exportTargets := []string{}
+	for _, target := range strings.Split(*exportTo, ",") {
+		if target = strings.TrimSpace(target); target == "" {
+			continue
+		}
+		if target != ExportGoogleDocs && target != ExportNotion {
+			fmt.Fprintf(os.Stderr, "Error: Invalid export target '%s'. Valid options: %s, %s\n", target, ExportGoogleDocs, ExportNotion)
+			os.Exit(1)
+		}
+		exportTargets = append(exportTargets, target)
+	}
+	splitInterval := 0.0
+	if *splitEvery == SplitAtParts && !*join {
+		fmt.Fprintln(os.Stderr, "Error: -split-every parts splits recordings joined with -join")
+		os.Exit(1)
+	} else if *splitEvery != "" && *splitEvery != SplitAtParts {
+		if splitInterval, err = ParseSplitInterval(*splitEvery); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *checkTranslation && !cfg.Translate {
+		fmt.Fprintln(os.Stderr, "Error: -check-translation checks the translation, so it needs -translate")
 		os.Exit(1)
 	}
+	if cfg.Translate {
+		if _, err := cfg.Glossary.Load(cfg.TargetLang); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *startTime != "" {
+		if len(inputs) > 1 && !*join {
+			fmt.Fprintln(os.Stderr, "Error: -start-time is when one recording started, so it can't be used with several inputs")
+			os.Exit(1)
+		}
+		if _, err := ParseRecordingStart(*startTime); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Auto-detect output file name if not specified
-	if *outputFile == "" {
-		ext := "txt"
-		switch *format {
-		case "json":
-			ext = "json"
-		case "srt":
-			ext = "srt"
-		case "vtt":
-			ext = "vtt"
+	var redactor *Redactor
+	if cfg.Redact.Enabled {
+		words, err := cfg.Redact.WordList()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		base := filepath.Base(*audioFile)
-		*outputFile = base[:len(base)-len(filepath.Ext(base))] + "_transcription." + ext
+		redactor = NewRedactor(words)
 	}
 
-	// Validate model
-	validModels := map[string]bool{"large-v3": true, "turbo": true, "base": true}
-	if !validModels[*modelID] {
-		fmt.Fprintf(os.Stderr, "Error: Invalid model '%s'. Valid options: large-v3, turbo, base\n", *modelID)
+	// Locate ffmpeg/ffprobe, and set up model downloads, speaker labels and the export script
+	SetFFmpegPaths(cfg.FFmpegPath, cfg.FFprobePath)
+	SetDownloadOptions(cfg.Download)
+	SetSpeakerLabels(cfg.SpeakerLabels)
+	if err := SetExportScript(cfg.Script); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := CheckFFmpeg(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Validate format
-	validFormats := map[string]bool{"text": true, "json": true, "srt": true, "vtt": true}
-	if !validFormats[*format] {
-		fmt.Fprintf(os.Stderr, "Error: Invalid format '%s'. Valid options: text, json, srt, vtt\n", *format)
+	// Joined recordings: the parts become one input, named after the first part
+	var recordingParts []RecordingPart
+	firstPart := "" // The joined recording starts when its first part does
+	if *join {
+		partPaths := inputs
+		if info, err := os.Stat(inputs[0]); err == nil && info.IsDir() && len(inputs) == 1 {
+			if partPaths, err = RecordingPartsInDir(inputs[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if len(partPaths) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: -join needs at least two files, or a folder of them")
+			os.Exit(1)
+		}
+		if cfg.ChannelMode == ChannelModeSplit {
+			fmt.Fprintln(os.Stderr, "Error: -join mixes the parts down to mono, so -channels split can't be used with it")
+			os.Exit(1)
+		}
+		joined, parts, err := JoinRecording(partPaths, func(msg string) { fmt.Println(msg) })
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer RemoveTempFile(filepath.Dir(joined))
+		inputs = []string{joined}
+		recordingParts = parts
+		firstPart = partPaths[0]
+	}
+
+	// Meeting recordings: a folder's audio-only copy of everyone, or with -participants
+	// a track per participant, named after them
+	var meeting *MeetingRecording
+	var participantTracks []ParticipantTrack
+	if rec, ok := DetectMeetingRecording(inputs[0]); ok && len(inputs) == 1 && !*join {
+		meeting = rec
+		fmt.Println(rec.Describe())
+		info, _ := os.Stat(inputs[0])
+		switch {
+		case *participants && len(rec.Tracks) > 0:
+			participantTracks = rec.Tracks
+			inputs = []string{rec.Mixed}
+			if rec.Mixed == "" {
+				inputs = []string{rec.Tracks[0].Path}
+			}
+		case info.IsDir() && rec.Mixed == "":
+			fmt.Fprintf(os.Stderr, "Error: %s only has participant tracks: add -participants to transcribe them\n", inputs[0])
+			os.Exit(1)
+		case info.IsDir():
+			inputs = []string{rec.Mixed}
+		}
+		if len(rec.Tracks) > 0 && !*participants {
+			fmt.Println("Add -participants to transcribe each participant's track with their name as the speaker")
+		}
+	}
+	if *participants && len(participantTracks) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: -participants needs a meeting recording with a track per participant, e.g. a Zoom recording folder with \"Audio Record\"")
+		os.Exit(1)
+	}
+	if len(participantTracks) > 0 && cfg.ChannelMode == ChannelModeSplit {
+		fmt.Fprintln(os.Stderr, "Error: -participants already gives each speaker their own track, so -channels split can't be used with it")
+		os.Exit(1)
+	}
+	if len(participantTracks) > 0 && cfg.Consensus != "" {
+		fmt.Fprintln(os.Stderr, "Error: -consensus compares two transcripts of one recording, so it can't be used with -participants")
 		os.Exit(1)
 	}
 
-	// Determine CPU threads
-	threads := *cpuThreads
-	if threads == 0 {
-		threads = GetOptimalCPUThreads()
+	// Minutes need the attendees told apart; recordings with a channel each are split
+	if *minutes {
+		speakerTurns := !cfg.IsRemoteEngine() && WhisperCaps().TinyDiarize
+		for _, input := range inputs {
+			channels, _ := getAudioChannels(input)
+			mode, err := minutesChannelMode(cfg.ChannelMode, channels, len(participantTracks) > 0, speakerTurns)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", input, err)
+				os.Exit(1)
+			}
+			if mode != cfg.ChannelMode {
+				fmt.Printf("%s has %d channels: transcribing one speaker per channel for the minutes\n", filepath.Base(input), channels)
+				cfg.ChannelMode = mode
+			}
+		}
+	}
+
+	// Resolve output file names: -output is a file for one input, a directory for several
+	outputDir := cfg.OutputDir
+	if len(inputs) > 1 && *outputFile != "" {
+		outputDir = *outputFile
+	}
+	outputs := batchOutputPaths(inputs, outputDir, cfg.Format)
+	for _, input := range inputs {
+		if len(inputs) == 1 && *outputFile != "" {
+			outputs[input] = *outputFile
+		} else if meeting != nil {
+			outputs[input] = filepath.Join(outputDir, meeting.OutputFileName(cfg.Format))
+		}
+	}
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot create output directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Parse time range
+	timeRange, err := ParseTimeRange(*fromTime, *toTime)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid time range: %v\n", err)
+		os.Exit(1)
 	}
 
+	settings := LoadSettings()
+
+	// Determine CPU threads; with auto threads, a model's first use benchmarks it
+	threads := cfg.CPUThreads(cfg.Model, settings.TunedThreads)
+	tuning := cfg.Threads == 0 && !cfg.IsRemoteEngine() && len(cfg.EngineFallback) == 0 && (*tuneThreads || cfg.NeedsThreadTuning(cfg.Model, settings.TunedThreads))
+
 	fmt.Printf("Starting transcription...\n")
-	fmt.Printf("  Input:  %s\n", *audioFile)
-	fmt.Printf("  Output: %s\n", *outputFile)
-	fmt.Printf("  Model:  %s\n", *modelID)
-	fmt.Printf("  Format: %s\n", *format)
-	fmt.Printf("  Threads: %d\n", threads)
-	if *translate {
-		fmt.Printf("  Translation: Enabled (target: %s, keep original: %v)\n", *targetLang, *keepOriginal)
+	if len(recordingParts) > 0 {
+		fmt.Printf("  Input:  %d files joined (%s)\n", len(recordingParts), strings.Join(partFileNames(recordingParts), ", "))
+		fmt.Printf("  Output: %s\n", outputs[inputs[0]])
+	} else if len(inputs) == 1 {
+		fmt.Printf("  Input:  %s\n", inputs[0])
+		fmt.Printf("  Output: %s\n", outputs[inputs[0]])
+	} else {
+		fmt.Printf("  Inputs: %d files\n", len(inputs))
+	}
+	fmt.Printf("  Model:  %s\n", cfg.Model)
+	fmt.Printf("  Format: %s\n", cfg.Format)
+	if len(cfg.EngineFallback) > 0 {
+		fmt.Printf("  Engines: %s\n", strings.Join(cfg.EngineChain(), " → "))
+	}
+	if cfg.IsRemoteEngine() {
+		fmt.Printf("  Engine: %s (%s)\n", cfg.Engine, cfg.EngineAddress())
+	} else if tuning {
+		fmt.Printf("  Threads: auto (measuring the fastest count for %s)\n", cfg.Model)
+	} else {
+		fmt.Printf("  Threads: %d\n", threads)
+	}
+	if cfg.ChannelMode == ChannelModeSplit {
+		fmt.Printf("  Channels: split (one speaker per channel)\n")
+	}
+	if cfg.Parallel > 1 {
+		fmt.Printf("  Parallel chunks: up to %d\n", cfg.Parallel)
+	}
+	if timeRange.IsSet() {
+		end := "end"
+		if timeRange.End > 0 {
+			end = FormatTimestamp(timeRange.End, true)
+		}
+		fmt.Printf("  Range:  %s - %s\n", FormatTimestamp(timeRange.Start, true), end)
+	}
+	firstPass := ConsensusPass{Model: cfg.Model, Decode: cfg.Decode}
+	secondPass := ConsensusSecondPass(cfg.Consensus, firstPass)
+	if cfg.Consensus != "" {
+		fmt.Printf("  Consensus: %s and %s\n", firstPass.Label(cfg.Consensus), secondPass.Label(cfg.Consensus))
+	}
+	if cfg.Translate {
+		fmt.Printf("  Translation: Enabled (target: %s, display: %s)\n", cfg.TargetLang, cfg.DisplayMode)
+	}
+	if cfg.Transliteration.Method != "" {
+		fmt.Printf("  Transliteration: %s\n", cfg.Transliteration.Method)
 	}
 	fmt.Println()
 
@@ -100,73 +584,852 @@ func CLIMode() {
 		}
 	}
 
-	// Get model path (will auto-download if needed)
-	modelPath, err := GetModelPath(*modelID, progressCallback)
+	// Initialize the engine (the local engine downloads the model if needed)
+	engine, err := NewTranscriptionEngine(cfg, cfg.Model, progressCallback)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "\nError getting model: %v\n", err)
+		fmt.Fprintf(os.Stderr, "\nError initializing the transcription engine: %v\n", err)
+		var damaged *CorruptModelError
+		if errors.As(err, &damaged) && damaged.Redownloadable() {
+			fmt.Fprintf(os.Stderr, "Download it again with: %s -model %s -redownload-model\n", os.Args[0], damaged.ModelID)
+		}
 		os.Exit(1)
 	}
-
 	fmt.Println()
+	defer engine.Close()
+	if !cfg.IsRemoteEngine() {
+		warnMissingWhisperFeatures()
+	}
+	if notice := CloudUploadNotice(cfg); notice != "" {
+		fmt.Fprintf(os.Stderr, "Privacy: %s\n", notice)
+	}
+	engine.SetTokenDump(cfg.Format == "tokens")
+	engine.SetTimeRange(timeRange)
+	engine.SetParallelChunks(cfg.Parallel)
+	engine.SetDecodeOptions(cfg.Decode)
 
-	// Initialize whisper engine
-	engine, err := NewWhisperCGOEngine(modelPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing whisper engine: %v\n", err)
-		os.Exit(1)
+	if benchmarker, ok := engine.(threadBenchmarker); ok && tuning {
+		tuned, err := TuneThreads(benchmarker, cfg.Model, cpuTopology(), func(msg string) { fmt.Printf("\r%s  ", msg) })
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nWarning: Thread benchmark failed, using %d threads: %v\n", threads, err)
+		} else {
+			threads = tuned
+			settings.TunedThreads[cfg.Model] = tuned
+			if err := SaveSettings(settings); err != nil {
+				fmt.Fprintf(os.Stderr, "\nWarning: Failed to save settings: %v\n", err)
+			}
+			fmt.Printf("\nThreads: %d (fastest on this machine)\n", threads)
+		}
 	}
-	defer engine.Close()
 
-	// Transcribe
-	segments, err := engine.Transcribe(*audioFile, *modelID, threads, func(msg string) {
-		fmt.Printf("\r%s", msg)
-	}, nil)
+	// Consensus mode with two models needs a second engine; with two temperatures
+	// the first engine transcribes both passes
+	secondEngine := engine
+	if cfg.Consensus != "" && secondPass.Model != cfg.Model {
+		secondEngine, err = NewTranscriptionEngine(cfg, secondPass.Model, progressCallback)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nError initializing the transcription engine: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println()
+		defer secondEngine.Close()
+		secondEngine.SetTimeRange(timeRange)
+		secondEngine.SetParallelChunks(cfg.Parallel)
+	}
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "\nError during transcription: %v\n", err)
-		os.Exit(1)
+	// The job manifest (-manifest) records each job's provenance and the files it writes
+	var jobManifest *JobManifest
+	if *manifestPath != "" {
+		jobManifest = NewJobManifest()
+	}
+	writeOutput := func(inputPath, path string, data []byte) error {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		if jobManifest != nil {
+			jobManifest.Job(inputPath).AddOutput(path)
+		}
+		return nil
 	}
 
-	fmt.Printf("\nTranscription complete (%d segments)\n", len(segments))
+	// Review answers are read from the terminal, shared by all inputs
+	reviewInput := bufio.NewReader(os.Stdin)
+
+	// transcribeFile transcribes (and optionally translates) one input and writes its output.
+	// The pipeline has decoded its audio ahead where it could.
+	transcribeFile := func(inputPath string) ([]Segment, error) {
+		audioPath := inputPath
+		outputPath := outputs[inputPath]
+		if len(inputs) > 1 {
+			fmt.Printf("\n[%s]\n", inputPath)
+		}
 
-	// Translate if requested
-	if *translate {
-		fmt.Printf("Translating to %s...\n", *targetLang)
-		translator := NewMistralTranslator()
+		// When the recording started, for the manifest and -wall-clock
+		startPath := inputPath
+		if firstPart != "" {
+			startPath = firstPart
+		}
+		recordingStart, startSource, hasStart, startErr := ResolveRecordingStart(*startTime, startPath)
+		if startErr != nil {
+			return nil, startErr
+		}
+		if hasStart && startSource != StartFromFlag {
+			fmt.Printf("Recording started %s (from its %s)\n", FormatRecordingStart(recordingStart), startSource)
+		} else if !hasStart && *wallClock {
+			return nil, fmt.Errorf("-wall-clock needs the time %s started: add -start-time, or write it in %s", filepath.Base(startPath), filepath.Base(recordingStartFile(startPath)))
+		}
 
-		translatedSegments, err := translator.TranslateSegments(segments, *targetLang, func(msg string) {
+		// ETA from this machine's historical realtime factor for the model
+		realtimeFactor := settings.RealtimeFactors[cfg.Model]
+		audioDuration := 0.0
+		if duration, err := getAudioDuration(inputPath); err == nil {
+			audioDuration = effectiveAudioDuration(duration, timeRange)
+		}
+		if remaining, ok := EstimateRemaining(realtimeFactor, audioDuration, 0, 0); ok {
+			fmt.Printf("Estimated transcription time: %s\n", FormatETA(remaining))
+		}
+
+		// Transcribe
+		inferenceStart := time.Now()
+		transcribeProgress := func(msg string) {
+			var percent int
+			if _, err := fmt.Sscanf(msg, "Transcribing... %d%%", &percent); err == nil {
+				if remaining, ok := EstimateRemaining(realtimeFactor, audioDuration, time.Since(inferenceStart), percent); ok && remaining > 0 {
+					msg = fmt.Sprintf("Transcribing... %d%% (ETA: %s)  ", percent, FormatETA(remaining))
+				}
+			}
 			fmt.Printf("\r%s", msg)
-		}, nil)
+		}
+		// A copy of a recording transcribed before, found by its audio rather than its name
+		var reused *TranscriptRecord
+		if len(participantTracks) == 0 && len(recordingParts) == 0 && !timeRange.IsSet() {
+			if earlier, ok := FindTranscribedDuplicate(inputPath); ok {
+				if *reuse {
+					reused = &earlier
+					fmt.Printf("Reusing the earlier transcript: %s\n", earlier.Describe())
+				} else {
+					fmt.Printf("Note: %s has the %s (add -reuse to load that transcript instead)\n", filepath.Base(inputPath), earlier.Describe())
+				}
+			}
+		}
+
+		var segments []Segment
+		var err error
+		if reused != nil {
+			segments, _, err = reused.LoadTranscript()
+		} else if cfg.ChannelMode == ChannelModeSplit {
+			segments, err = TranscribeByChannel(engine, audioPath, cfg.Model, threads, transcribeProgress, nil)
+		} else if len(participantTracks) > 0 {
+			segments, err = TranscribeTracks(engine, participantTracks, cfg.Model, threads, transcribeProgress, nil)
+		} else {
+			segments, err = engine.Transcribe(audioPath, cfg.Model, threads, transcribeProgress, nil)
+		}
 
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "\nError during translation: %v\n", err)
-			os.Exit(1)
+			AwaitShutdown() // Ctrl+C: the shutdown saves what was transcribed and exits
+			return nil, fmt.Errorf("error during transcription: %v", err)
+		}
+
+		fmt.Printf("\nTranscription complete (%d segments)\n", len(segments))
+
+		// Learn this machine's speed for future ETAs (cached results, split-channel,
+		// per-participant and parallel-chunk runs don't reflect single-pass inference time)
+		if reused == nil && !engine.LastResultCached() && ranLocally(engine) && cfg.ChannelMode != ChannelModeSplit && len(participantTracks) == 0 && cfg.Parallel <= 1 && audioDuration > 0 {
+			UpdateRealtimeFactor(settings.RealtimeFactors, cfg.Model, time.Since(inferenceStart), audioDuration)
+			if err := SaveSettings(settings); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to save settings: %v\n", err)
+			}
+		}
+
+		// Record how this transcript was produced (JSON output only)
+		params := ManifestParameters{
+			Language:    "he",
+			Threads:     threads,
+			ChannelMode: cfg.ChannelMode,
+			Parallel:    cfg.Parallel,
+			From:        timeRange.Start,
+			To:          timeRange.End,
+			Decode:      cfg.Decode,
+			Engine:      engineDescription(engine),
+			Parts:       recordingParts,
+		}
+		if meeting != nil && len(participantTracks) > 0 {
+			params.Participants = meeting.TrackNames()
+		}
+		if hasStart {
+			params.RecordingStart = recordingStart.Format(time.RFC3339)
 		}
 
-		// Handle keep original setting
-		if !*keepOriginal {
-			for i := range translatedSegments {
-				translatedSegments[i].Text = translatedSegments[i].Translation
-				translatedSegments[i].Original = ""
+		// High accuracy: transcribe again and merge, flagging where the passes disagree
+		var review *ConsensusReview
+		if cfg.Consensus != "" {
+			fmt.Printf("Transcribing again with %s...\n", secondPass.Label(cfg.Consensus))
+			second, err := transcribeSecondPass(secondEngine, secondPass, cfg.Decode, audioPath, threads, cfg.ChannelMode == ChannelModeSplit, func(msg string) {
+				fmt.Printf("\r%s", msg)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error during second transcription: %v", err)
 			}
-		} else {
-			for i := range translatedSegments {
-				translatedSegments[i].Text = translatedSegments[i].Translation
+			merged, disagreements := MergeConsensus(segments, second)
+			segments = merged
+			review = &ConsensusReview{First: firstPass.Label(cfg.Consensus), Second: secondPass.Label(cfg.Consensus), Segments: len(merged), Disagreements: disagreements}
+			params.Consensus = cfg.Consensus
+			params.ConsensusWith = review.Second
+			fmt.Printf("\nConsensus: %d of %d segments differ\n", len(disagreements), len(merged))
+		}
+
+		// Corrections in the terminal, before anything is translated or written
+		if *interactiveReview {
+			fmt.Printf("\nReview %d segments: %s\n", len(segments), reviewHelp)
+			reviewed := ReviewSegments(segments, reviewInput, os.Stdout)
+			segments = reviewed.Segments
+			fmt.Printf("\nReview complete: %d edited, %d skipped\n", reviewed.Edited, reviewed.Skipped)
+		}
+
+		// Translate if requested
+		if cfg.Translate {
+			fmt.Printf("Translating to %s...\n", cfg.TargetLang)
+			translator, err := cfg.NewTranslator(cfg.TargetLang)
+			if err != nil {
+				return nil, err
+			}
+			params.TranslateTo = cfg.TargetLang
+			params.TranslationModel = translator.model
+
+			translatedSegments, err := translator.TranslateSegments(segments, cfg.TargetLang, func(msg string) {
+				fmt.Printf("\r%s", msg)
+			}, nil)
+
+			if err != nil {
+				return nil, fmt.Errorf("error during translation: %v", err)
+			}
+
+			segments = translatedSegments
+			fmt.Println("\nTranslation complete")
+			for _, miss := range translator.GlossaryMisses() {
+				fmt.Fprintf(os.Stderr, "Warning: The translation doesn't use the glossary's %s\n", miss.Describe())
+			}
+		}
+
+		// Back-translate to flag translations that may be wrong
+		var translationReview *TranslationReview
+		if cfg.Translate && *checkTranslation {
+			fmt.Println("Checking the translation...")
+			checked, err := NewMistralTranslator().CheckTranslation(segments, cfg.TargetLang, func(msg string) {
+				fmt.Printf("\r%s", msg)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error checking the translation: %v", err)
+			}
+			translationReview = &checked
+			fmt.Printf("\nTranslation check: %d of %d segments flagged\n", len(checked.Flags), checked.Checked)
+		}
+
+		// Transliterate the Hebrew (the originals of a translation) if requested
+		if cfg.Transliteration.Method != "" {
+			fmt.Println("Transliterating...")
+			params.Transliteration = cfg.Transliteration.Method
+			segments, err = TransliterateSegments(segments, transliterator(cfg.Transliteration.Method), cfg.Transliteration.Only, func(msg string) {
+				fmt.Printf("\r%s", msg)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error during transliteration: %v", err)
+			}
+			fmt.Println("\nTransliteration complete")
+		}
+
+		// Numbers, dates and times in the style selected (the words as transcribed by default)
+		segments = NormalizeNumbers(segments, cfg.Numbers)
+
+		if jobManifest != nil {
+			manifest := NewManifest(cfg.Model, engineModelPath(engine), inputPath, params)
+			job := jobManifest.Job(inputPath)
+			job.Manifest = &manifest
+			job.AudioDuration = audioDuration
+		}
+
+		// Transcripts of the whole recording that keep the Hebrew are remembered, so a
+		// copy of the recording under another name can reuse them
+		remember := reused == nil && len(recordingParts) == 0 && !timeRange.IsSet() && !cfg.Transliteration.Only && !*wallClock &&
+			(!cfg.Translate || cfg.DisplayMode != DisplayTranslation)
+
+		// Format and write the output (each format in turn with -format all), with the
+		// texts the display mode selects
+		shown := ApplyDisplayMode(segments, cfg.DisplayMode)
+		statsSaved, reviewSaved, translationReviewSaved := false, false, false
+		var speakers []SpeakerTranscript
+		if *splitSpeakers {
+			if speakers = SplitBySpeaker(segments); len(speakers) < 2 {
+				fmt.Println("Only one speaker found, so no per-speaker files are written")
+				speakers = nil
+			}
+		}
+		var pieces []TranscriptPiece
+		if *splitEvery == SplitAtParts {
+			pieces = SplitByParts(segments, recordingParts)
+		} else if splitInterval > 0 {
+			pieces = SplitByInterval(segments, splitInterval)
+		}
+		for _, format := range outputFormats(cfg.Format) {
+			formatPath := outputPath
+			if cfg.Format == FormatAll {
+				formatPath = formatFileName(outputPath, format)
+			}
+			// Non-speech events like [music] only in the formats that show them
+			formatSegments, formatShown := segments, shown
+			if !ShowsEvents(cfg.Events, format) {
+				formatSegments, formatShown = WithoutEvents(segments), WithoutEvents(shown)
+			}
+			outputText := FormatOutput(formatSegments, format, cfg.DisplayMode)
+			if format == "json" {
+				outputText = AttachManifest(outputText, NewManifest(cfg.Model, engineModelPath(engine), inputPath, params))
+			}
+			if format == "markdown" {
+				outputText = FormatMarkdown(formatShown, markdownMediaURL(*mediaURL, inputPath, formatPath))
 			}
+			if format == FormatCustom {
+				if outputText, err = FormatScript(formatShown); err != nil {
+					return nil, err
+				}
+			}
+			if format == "html" {
+				audio, mimeType, err := EncodePlayerAudio(inputPath)
+				if err != nil {
+					return nil, err
+				}
+				if outputText, err = FormatHTML(transcriptTitle(inputPath), formatShown, audio, mimeType); err != nil {
+					return nil, fmt.Errorf("error formatting HTML: %v", err)
+				}
+			}
+			if *speakerStats {
+				var appended bool
+				if outputText, appended = AppendSpeakerStats(outputText, segments, format); !appended && !statsSaved {
+					statsSaved = true
+					statsText := FormatSpeakerStats(ComputeSpeakerStats(segments), "markdown")
+					statsPath := filepath.Join(filepath.Dir(formatPath), speakerStatsFileName(inputPath))
+					if err := writeOutput(inputPath, statsPath, []byte(statsText)); err != nil {
+						return nil, fmt.Errorf("error writing speaker statistics file: %v", err)
+					}
+					fmt.Printf("Speaker statistics saved to: %s\n", statsPath)
+
+					uploadOrWarn(cfg.Destinations, filepath.Base(statsPath), []byte(statsText))
+				}
+			}
+			if review != nil {
+				var appended bool
+				if outputText, appended = AppendConsensusReview(outputText, *review, format); !appended && !reviewSaved {
+					reviewSaved = true
+					reviewText := FormatConsensusReview(*review, "markdown")
+					reviewPath := filepath.Join(filepath.Dir(formatPath), consensusReviewFileName(inputPath))
+					if err := writeOutput(inputPath, reviewPath, []byte(reviewText)); err != nil {
+						return nil, fmt.Errorf("error writing review file: %v", err)
+					}
+					fmt.Printf("Disagreements to review saved to: %s\n", reviewPath)
+
+					uploadOrWarn(cfg.Destinations, filepath.Base(reviewPath), []byte(reviewText))
+				}
+			}
+			if translationReview != nil {
+				var appended bool
+				if outputText, appended = AppendTranslationReview(outputText, *translationReview, format); !appended && !translationReviewSaved {
+					translationReviewSaved = true
+					reviewText := FormatTranslationReview(*translationReview, "markdown")
+					reviewPath := filepath.Join(filepath.Dir(formatPath), translationReviewFileName(inputPath))
+					if err := writeOutput(inputPath, reviewPath, []byte(reviewText)); err != nil {
+						return nil, fmt.Errorf("error writing translation review file: %v", err)
+					}
+					fmt.Printf("Translations to review saved to: %s\n", reviewPath)
+
+					uploadOrWarn(cfg.Destinations, filepath.Base(reviewPath), []byte(reviewText))
+				}
+			}
+			if len(recordingParts) > 0 {
+				outputText, _ = MarkRecordingParts(outputText, recordingParts, format)
+			}
+			if *wallClock {
+				outputText, _ = ApplyWallClock(outputText, recordingStart, format)
+			}
+
+			// Write to file
+			outputData := cfg.OutputData(outputText, format)
+			if err := writeOutput(inputPath, formatPath, outputData); err != nil {
+				return nil, fmt.Errorf("error writing output file: %v", err)
+			}
+
+			fmt.Printf("Saved to: %s\n", formatPath)
+			if remember {
+				if err := RecordTranscript(inputPath, formatPath, cfg.Model); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Cannot remember the transcript for reuse: %v\n", err)
+				}
+			}
+
+			uploadOrWarn(cfg.Destinations, filepath.Base(formatPath), outputData)
+
+			// Redacted copy alongside the original. HTML pages get no audio and markdown
+			// no links to it, since the recording still contains what was masked.
+			if redactor != nil {
+				redactedSegments, count := redactor.RedactSegments(formatSegments)
+				redactedText := FormatOutput(redactedSegments, format, cfg.DisplayMode)
+				if format == "json" {
+					redactedText = AttachManifest(redactedText, NewManifest(cfg.Model, engineModelPath(engine), inputPath, params))
+				}
+				if *speakerStats {
+					redactedText, _ = AppendSpeakerStats(redactedText, redactedSegments, format)
+				}
+				if review != nil {
+					redactedText, _ = AppendConsensusReview(redactedText, redactor.RedactReview(*review), format)
+				}
+				if len(recordingParts) > 0 {
+					redactedText, _ = MarkRecordingParts(redactedText, recordingParts, format)
+				}
+				if *wallClock {
+					redactedText, _ = ApplyWallClock(redactedText, recordingStart, format)
+				}
+				redactedPath := redactedFileName(formatPath)
+				redactedData := cfg.OutputData(redactedText, format)
+				if err := writeOutput(inputPath, redactedPath, redactedData); err != nil {
+					return nil, fmt.Errorf("error writing redacted file: %v", err)
+				}
+				fmt.Printf("Redacted copy (%d items masked) saved to: %s\n", count, redactedPath)
+
+				uploadOrWarn(cfg.Destinations, filepath.Base(redactedPath), redactedData)
+			}
+
+			// One file per speaker with only their segments
+			if format == "html" {
+				continue
+			}
+			for _, speaker := range speakers {
+				speakerPath := speakerFileName(formatPath, speaker.Speaker)
+				if !ShowsEvents(cfg.Events, format) {
+					speaker.Segments = WithoutEvents(speaker.Segments)
+				}
+				speakerText := FormatSpeakerTranscript(speaker, format, cfg.DisplayMode, markdownMediaURL(*mediaURL, inputPath, speakerPath))
+				if *wallClock {
+					speakerText, _ = ApplyWallClock(speakerText, recordingStart, format)
+				}
+				speakerData := cfg.OutputData(speakerText, format)
+				if err := writeOutput(inputPath, speakerPath, speakerData); err != nil {
+					return nil, fmt.Errorf("error writing speaker file: %v", err)
+				}
+				fmt.Printf("%s saved to: %s\n", speaker.Label(), speakerPath)
+
+				uploadOrWarn(cfg.Destinations, filepath.Base(speakerPath), speakerData)
+			}
+
+			// Stretches of the transcript; markdown links to the full recording, so isn't split
+			if format == "markdown" {
+				continue
+			}
+			for _, piece := range pieces {
+				piecePath := pieceFileName(formatPath, piece.Number, pieces[len(pieces)-1].Number)
+				pieceSegments := piece.Segments
+				if !ShowsEvents(cfg.Events, format) {
+					pieceSegments = WithoutEvents(pieceSegments)
+				}
+				pieceData := cfg.OutputData(FormatOutput(pieceSegments, format, cfg.DisplayMode), format)
+				if err := writeOutput(inputPath, piecePath, pieceData); err != nil {
+					return nil, fmt.Errorf("error writing split file: %v", err)
+				}
+				fmt.Printf("From %s saved to: %s\n", FormatTimestamp(piece.Start, true)[:8], piecePath)
+
+				uploadOrWarn(cfg.Destinations, filepath.Base(piecePath), pieceData)
+			}
+		}
+
+		// One VTT per language with HLS playlists, for players with selectable subtitles
+		if *vttTracks {
+			trackSegments := segments
+			if !ShowsEvents(cfg.Events, "vtt") {
+				trackSegments = WithoutEvents(trackSegments)
+			}
+			trackFiles := SubtitleTrackFiles(trackSegments, cfg.TargetLang, outputPath)
+			if trackFiles == nil {
+				fmt.Println("Nothing is translated, so no subtitle tracks are written")
+			}
+			for _, file := range trackFiles {
+				data := []byte(file.Text)
+				if file.Format == "vtt" {
+					data = cfg.OutputData(file.Text, file.Format)
+				}
+				if err := writeOutput(inputPath, file.Path, data); err != nil {
+					return nil, fmt.Errorf("error writing subtitle track: %v", err)
+				}
+				fmt.Printf("Subtitle track saved to: %s\n", file.Path)
+
+				uploadOrWarn(cfg.Destinations, filepath.Base(file.Path), data)
+			}
+		}
+
+		for _, target := range exportTargets {
+			url, err := Export(target, transcriptTitle(inputPath), shown)
+			if err != nil {
+				return nil, fmt.Errorf("export to %s failed: %v", target, err)
+			}
+			fmt.Printf("Exported to %s: %s\n", target, url)
 		}
 
-		segments = translatedSegments
-		fmt.Println("\nTranslation complete")
+		// Meeting minutes, in the translation language when translating
+		if *minutes {
+			if err := minutesSpeakersError(segments); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Minutes not written: %v\n", err)
+				return segments, nil
+			}
+			minutesLang := "he"
+			if cfg.Translate {
+				minutesLang = cfg.TargetLang
+			}
+			fmt.Println("Writing meeting minutes...")
+			meetingMinutes, err := NewMistralTranslator().GenerateMinutes(segments, minutesLang, func(msg string) {
+				fmt.Printf("\r%s", msg)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error generating minutes: %v", err)
+			}
+			minutesText := FormatMinutesMarkdown(minutesTitle(inputPath), meetingMinutes, segments)
+			minutesPath := filepath.Join(filepath.Dir(outputPath), minutesFileName(inputPath))
+			if err := writeOutput(inputPath, minutesPath, []byte(minutesText)); err != nil {
+				return nil, fmt.Errorf("error writing minutes file: %v", err)
+			}
+			fmt.Printf("\nMinutes saved to: %s\n", minutesPath)
+
+			uploadOrWarn(cfg.Destinations, filepath.Base(minutesPath), []byte(minutesText))
+		}
+		return segments, nil
 	}
 
-	// Format output
-	outputText := FormatOutput(segments, *format, *keepOriginal)
+	// transcribeOne runs one job and reports its outcome to the webhook, if configured
+	notified := make(map[string]bool, len(inputs))
+	transcribeOne := func(inputPath string) error {
+		job := NewWebhookPayload(inputPath, cfg.Model)
+		jobStart := time.Now()
+		segments, err := transcribeFile(inputPath)
+		if jobManifest != nil {
+			jobManifest.Job(inputPath).Finish(time.Since(jobStart), err)
+		}
+		if err == nil {
+			duration, _ := getAudioDuration(inputPath)
+			entry := NewHistoryEntry(inputPath, cfg.Model, duration, time.Since(jobStart))
+			entry.OutputPath = outputs[inputPath]
+			if cfg.Format == FormatAll {
+				entry.OutputPath = formatFileName(entry.OutputPath, "json") // Reopens with its timestamps
+			}
+			if err := AddHistory(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Cannot add the transcription to the history: %v\n", err)
+			}
+		}
+		if cfg.WebhookURL != "" {
+			if err == nil {
+				job.OutputPath = outputs[inputPath]
+			}
+			job.Finish(segments, err)
+			notifyWebhook(cfg.WebhookURL, job)
+			notified[inputPath] = true
+		}
+		return err
+	}
 
-	// Write to file
-	if err := os.WriteFile(*outputFile, []byte(outputText), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+	// Decode the next file while the current one is transcribed. Split-channel mode
+	// needs the original (multi-channel) input, participant tracks are transcribed
+	// from their own files, and remote engines upload the file, so they skip it.
+	prefetch := cfg.ChannelMode != ChannelModeSplit && len(participantTracks) == 0 && !cfg.IsRemoteEngine()
+	errs := RunPipeline(inputs, prefetch, timeRange, transcribeOne)
+
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "\nError: %s: %v\n", inputs[i], err)
+
+			// Jobs that failed before transcription (e.g. audio conversion) haven't been reported yet
+			if cfg.WebhookURL != "" && !notified[inputs[i]] {
+				job := NewWebhookPayload(inputs[i], cfg.Model)
+				job.Finish(nil, err)
+				notifyWebhook(cfg.WebhookURL, job)
+			}
+			if jobManifest != nil && jobManifest.Job(inputs[i]).Error == "" {
+				jobManifest.Job(inputs[i]).Finish(0, err)
+			}
+		}
+	}
+	if len(inputs) > 1 {
+		fmt.Printf("\nBatch complete: %d/%d files transcribed\n", len(inputs)-failed, len(inputs))
+	}
+	if jobManifest != nil {
+		if err := jobManifest.Write(*manifestPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Cannot write the manifest: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Manifest saved to: %s\n", *manifestPath)
+	}
+	if failed > 0 {
 		os.Exit(1)
 	}
+}
+
+// printVersion prints the build and the whisper.cpp library the app runs with
+func printVersion() {
+	caps := WhisperCaps()
+	fmt.Printf("ivrit.ai %s\n", appVersion)
+	fmt.Printf("%s\n", caps)
+	if missing := caps.Missing(); len(missing) > 0 {
+		fmt.Printf("Not supported: %s\n", strings.Join(missing, ", "))
+	}
+}
+
+// listModelsMode lists the models, marking the current one and where each downloaded one is
+func listModelsMode(current string) {
+	fmt.Printf("Models in %s:\n", ModelDir())
+	for _, modelID := range ModelIDs() {
+		marker := " "
+		if modelID == current {
+			marker = "*"
+		}
+		if path, err := FindLocalModel(modelID); err == nil {
+			fmt.Printf("%s %-20s %s\n", marker, modelID, path)
+		} else {
+			fmt.Printf("%s %-20s not downloaded\n", marker, modelID)
+		}
+	}
+}
+
+// translateDirMode translates the saved transcripts in dir and reports each one
+func translateDirMode(dir, outputDir string, cfg AppConfig) error {
+	fmt.Printf("Translating transcripts in %s to %s...\n", dir, cfg.TargetLang)
+	translator, err := cfg.NewTranslator(cfg.TargetLang)
+	if err != nil {
+		return err
+	}
+	results, err := TranslateDir(dir, outputDir, cfg, translator, func(msg string) {
+		fmt.Printf("\r%s  ", msg)
+	})
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no transcripts (txt, srt, vtt, json) found in %s", dir)
+	}
+
+	translated, failed := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			fmt.Printf("\rSkipped %s (%s exists)\n", result.Input, result.Output)
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "\rError: %s: %v\n", result.Input, result.Err)
+		default:
+			fmt.Printf("\rSaved to: %s\n", result.Output)
+			translated++
+			if len(cfg.Destinations) == 0 {
+				continue
+			}
+			if data, err := os.ReadFile(result.Output); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s not uploaded: %v\n", result.Output, err)
+			} else {
+				uploadOrWarn(cfg.Destinations, filepath.Base(result.Output), data)
+			}
+		}
+	}
+	fmt.Printf("\nTranslation complete: %d translated, %d skipped, %d failed\n", translated, len(results)-translated-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transcripts failed", failed, len(results))
+	}
+	return nil
+}
+
+// chatMode transcribes the voice notes of an exported chat into one transcript
+func chatMode(dir, outputPath string, cfg AppConfig) error {
+	chat, err := ReadChatExport(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Println(chat.Describe())
+	if outputPath == "" {
+		outputDir := cfg.OutputDir
+		if outputDir == "" {
+			outputDir = dir
+		}
+		outputPath = filepath.Join(outputDir, chatTranscriptFileName(chat))
+	}
+
+	engine, err := NewTranscriptionEngine(cfg, cfg.Model, func(msg string, pct int) {
+		fmt.Printf("\r%s  ", msg)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot initialize the transcription engine: %v", err)
+	}
+	defer engine.Close()
+	engine.SetDecodeOptions(cfg.Decode)
+	threads := cfg.CPUThreads(cfg.Model, LoadSettings().TunedThreads)
+
+	results, err := TranscribeChat(engine, chat, cfg.Model, threads, func(msg string) {
+		fmt.Printf("\r%s  ", msg)
+	})
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "\rError: %s: %v\n", result.Note.Path, result.Err)
+		}
+	}
+
+	data := []byte(FormatChatTranscript(chat, results))
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return err
+	}
+	uploadOrWarn(cfg.Destinations, filepath.Base(outputPath), data)
+	fmt.Printf("\rTranscribed %d of %d voice notes\n", len(results)-failed, len(results))
+	fmt.Printf("Saved to: %s\n", outputPath)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d voice notes failed", failed, len(results))
+	}
+	return nil
+}
+
+// modelUpdatesMode checks the downloaded models for updates and, with update set, downloads them
+func modelUpdatesMode(update bool) error {
+	updates := CheckModelUpdates(func(msg string) {
+		fmt.Println(msg)
+	})
+	if len(updates) == 0 {
+		fmt.Println("The downloaded models are up to date")
+		return nil
+	}
+
+	failed := 0
+	for _, u := range updates {
+		size := ""
+		if u.RemoteSize > 0 {
+			size = fmt.Sprintf(" (%.1fMB)", float64(u.RemoteSize)/(1024*1024))
+		}
+		fmt.Printf("An updated %s model is available%s\n", u.ModelID, size)
+		if !update {
+			continue
+		}
+		err := UpdateModel(u.ModelID, func(msg string, percent int) {
+			fmt.Printf("\r%s  ", msg)
+		})
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "\rError: %s: %v\n", u.ModelID, err)
+			continue
+		}
+		fmt.Println()
+	}
+	if !update {
+		fmt.Printf("Run %s -update-models to download them\n", os.Args[0])
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d models failed to update", failed, len(updates))
+	}
+	return nil
+}
+
+// appUpdateMode checks for a newer release of the app and describes it
+func appUpdateMode() error {
+	if !isReleaseVersion(appVersion) {
+		fmt.Printf("This is a development build (%s): build from source again to update\n", appVersion)
+		return nil
+	}
+	release, err := CheckAppUpdate()
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		fmt.Printf("ivrit.ai %s is up to date\n", appVersion)
+		return nil
+	}
+
+	fmt.Printf("ivrit.ai %s is available (you have %s)\n", release.Version, appVersion)
+	if notes := releaseNotesPreview(release.Notes, 30); notes != "" {
+		fmt.Printf("\n%s\n\n", notes)
+	}
+	if asset, ok := release.PlatformAsset(); ok {
+		fmt.Printf("Download: %s\n", asset.URL)
+	} else {
+		fmt.Printf("Download: %s\n", release.URL)
+	}
+	return nil
+}
+
+// autoOutputFileName derives the default output file name for an input file and format
+func autoOutputFileName(inputPath string, format string) string {
+	base := filepath.Base(inputPath)
+	return base[:len(base)-len(filepath.Ext(base))] + "_transcription." + formatExtension(format)
+}
+
+// batchOutputPaths names the outputs of inputs in outputDir. Inputs of the same name
+// in different folders (a/x.mp3 and b/x.mp3) get their folder's name added, and a
+// number when that isn't enough, instead of overwriting each other's transcript.
+func batchOutputPaths(inputs []string, outputDir, format string) map[string]string {
+	// Compared ignoring case, as macOS and Windows file names are
+	sameName := make(map[string]int, len(inputs))
+	for _, input := range inputs {
+		sameName[strings.ToLower(autoOutputFileName(input, format))]++
+	}
+
+	outputs := make(map[string]string, len(inputs))
+	used := make(map[string]bool, len(inputs))
+	for _, input := range inputs {
+		name := autoOutputFileName(input, format)
+		if sameName[strings.ToLower(name)] > 1 {
+			if folder := filepath.Base(filepath.Dir(absolutePath(input))); folder != "." && folder != string(filepath.Separator) {
+				name = autoOutputFileName(folder+"_"+filepath.Base(input), format)
+			}
+		}
+		base := filepath.Base(input)
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			name = autoOutputFileName(fmt.Sprintf("%s_%d%s", stem, n, filepath.Ext(base)), format)
+		}
+		used[strings.ToLower(name)] = true
+		outputs[input] = filepath.Join(outputDir, name)
+	}
+	return outputs
+}
+
+// markdownMediaURL returns where markdown timestamps link to: the -media-url value, or
+// the input file relative to the output so the links work when both are published together
+func markdownMediaURL(mediaURL, inputPath, outputPath string) string {
+	if mediaURL != "" {
+		return mediaURL
+	}
+	absInput, err1 := filepath.Abs(inputPath)
+	absOutputDir, err2 := filepath.Abs(filepath.Dir(outputPath))
+	if err1 != nil || err2 != nil {
+		return filepath.Base(inputPath)
+	}
+	rel, err := filepath.Rel(absOutputDir, absInput)
+	if err != nil {
+		return filepath.Base(inputPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// transcriptTitle names an exported document after its input file
+func transcriptTitle(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + " - Transcript"
+}
+
+// minutesTitle names the meeting minutes after their input file
+func minutesTitle(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + " - Minutes"
+}
+
+// minutesFileName derives the meeting minutes file name for an input file
+func minutesFileName(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_minutes.md"
+}
 
-	fmt.Printf("Saved to: %s\n", *outputFile)
+// speakerStatsFileName derives the speaker statistics file name for an input file,
+// written for output formats the report can't be appended to
+func speakerStatsFileName(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_speakers.md"
 }
@@ -161,22 +161,15 @@ func TestAutoOutputFilename(t *testing.T) {
 		{"JSON format", "test.wav", "json", ".json"},
 		{"SRT format", "test.m4a", "srt", ".srt"},
 		{"VTT format", "test.mp4", "vtt", ".vtt"},
+		{"Tokens format", "test.mp4", "tokens", ".tokens.txt"},
+		{"Markdown format", "test.mp4", "markdown", ".md"},
+		{"All formats", "test.mp4", "all", ".txt"},
+		{"Path input", "/recordings/test.mp3", "text", ".txt"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Simulate the auto-filename logic from cli.go
-			ext := "txt"
-			switch tt.format {
-			case "json":
-				ext = "json"
-			case "srt":
-				ext = "srt"
-			case "vtt":
-				ext = "vtt"
-			}
-			base := filepath.Base(tt.inputFile)
-			outputFile := base[:len(base)-len(filepath.Ext(base))] + "_transcription." + ext
+			outputFile := autoOutputFileName(tt.inputFile, tt.format)
 
 			if !strings.HasSuffix(outputFile, tt.expectedExt) {
 				t.Errorf("Expected output file to have extension %s, got %s", tt.expectedExt, outputFile)
@@ -185,10 +178,33 @@ func TestAutoOutputFilename(t *testing.T) {
 			if !strings.Contains(outputFile, "_transcription") {
 				t.Error("Output filename should contain '_transcription'")
 			}
+
+			// Output goes to the current directory, not next to the input
+			if filepath.Dir(outputFile) != "." {
+				t.Errorf("Output filename should not contain a directory, got %s", outputFile)
+			}
 		})
 	}
 }
 
+// TestBatchOutputPaths tests that inputs of the same name in different folders don't
+// write the same transcript
+func TestBatchOutputPaths(t *testing.T) {
+	inputs := []string{"a/x.mp3", "b/x.mp3", "b/X.m4a", "c/y.mp3"}
+	outputs := batchOutputPaths(inputs, "out", "text")
+	expected := map[string]string{
+		"a/x.mp3": filepath.Join("out", "a_x_transcription.txt"),
+		"b/x.mp3": filepath.Join("out", "b_x_transcription.txt"),
+		"b/X.m4a": filepath.Join("out", "X_2_transcription.txt"),
+		"c/y.mp3": filepath.Join("out", "y_transcription.txt"),
+	}
+	for input, want := range expected {
+		if outputs[input] != want {
+			t.Errorf("Output of %s = %s, want %s", input, outputs[input], want)
+		}
+	}
+}
+
 // TestModelValidation tests model name validation
 func TestModelValidation(t *testing.T) {
 	validModels := map[string]bool{"large-v3": true, "turbo": true, "base": true}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// mediaExtensions are the audio and video file types offered in the file dialog
+// and recognized on the clipboard
+var mediaExtensions = []string{"mp3", "wav", "m4a", "aac", "flac", "ogg", "wma", "aiff", "aif", "mp4", "avi", "mov", "mkv", "webm", "flv", "wmv", "m4v", "3gp", "qta"}
+
+// Clipboard watching
+const (
+	clipboardPollInterval = 1500 * time.Millisecond
+	maxClipboardText      = 4096 // Longer clipboard contents are never a single path or URL
+)
+
+// windowsFileURLPath matches the path of a Windows file URL (file:///C:/...)
+var windowsFileURLPath = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// isMediaFile reports whether name has an audio or video extension
+func isMediaFile(name string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	return ext != "" && containsString(mediaExtensions, ext)
+}
+
+// ClipboardMedia returns the media file or URL in copied text: an existing local
+// audio/video file (absolute path, ~/path or file:// URL) or an http(s) URL of one
+func ClipboardMedia(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || len(text) > maxClipboardText || strings.ContainsAny(text, "\r\n") {
+		return "", false
+	}
+	text = strings.Trim(text, `"'`) // Windows "Copy as path" adds quotes
+
+	if u, err := url.Parse(text); err == nil {
+		switch u.Scheme {
+		case "http", "https":
+			if u.Host != "" && isMediaFile(path.Base(u.Path)) {
+				return text, true
+			}
+			return "", false
+		case "file":
+			text = u.Path
+			if windowsFileURLPath.MatchString(text) {
+				text = text[1:]
+			}
+			text = filepath.FromSlash(text)
+		}
+	}
+
+	if strings.HasPrefix(text, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		text = filepath.Join(homeDir, text[2:])
+	}
+	if !filepath.IsAbs(text) || !isMediaFile(text) {
+		return "", false
+	}
+	if info, err := os.Stat(text); err != nil || info.IsDir() {
+		return "", false
+	}
+	return text, true
+}
+
+// ClipboardWatcher spots media newly copied to the clipboard
+type ClipboardWatcher struct {
+	last    string
+	started bool
+
+	// copiedFile, when set, returns a media file copied as a file reference, whose
+	// clipboard text is only its name (e.g. copiedFinderFile on macOS)
+	copiedFile func() (string, bool)
+}
+
+// Check is called with the clipboard contents on every poll. It returns a media file
+// or URL only when the contents changed since the previous poll, so what was on the
+// clipboard when watching started, or was already offered, isn't offered again.
+func (w *ClipboardWatcher) Check(text string) (string, bool) {
+	changed := w.started && text != w.last
+	w.started, w.last = true, text
+	if !changed {
+		return "", false
+	}
+	if media, ok := ClipboardMedia(text); ok || w.copiedFile == nil {
+		return media, ok
+	}
+	return w.copiedFile()
+}
+
+// Reset forgets the clipboard contents, for when watching is turned off
+func (w *ClipboardWatcher) Reset() {
+	w.started, w.last = false, ""
+}
+
+// DownloadMedia downloads a media URL into a new temporary directory, keeping the
+// URL's file name, and returns the file's path. The caller removes the directory.
+func DownloadMedia(mediaURL string, progressCallback func(string, int)) (string, error) {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient(0).Get(mediaURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	}
+
+	dir, err := os.MkdirTemp("", "ivrit_download_*")
+	if err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(dir, path.Base(u.Path))
+	out, err := os.Create(filePath)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	defer out.Close()
+
+	progress := &downloadProgress{total: resp.ContentLength, callback: progressCallback}
+	if _, err := io.Copy(out, io.TeeReader(resp.Body, progress)); err != nil {
+		out.Close()
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return filePath, nil
+}
+
+// downloadProgress reports download progress as data passes through it
+type downloadProgress struct {
+	total      int64
+	downloaded int64
+	callback   func(string, int)
+}
+
+func (p *downloadProgress) Write(data []byte) (int, error) {
+	p.downloaded += int64(len(data))
+	if p.callback != nil && p.total > 0 {
+		p.callback(fmt.Sprintf("Downloading: %.1fMB / %.1fMB", float64(p.downloaded)/(1024*1024), float64(p.total)/(1024*1024)), int(p.downloaded*100/p.total))
+	}
+	return len(data), nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestClipboardMedia tests recognizing copied media paths and URLs
+func TestClipboardMedia(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "my interview.m4a")
+	document := filepath.Join(dir, "notes.txt")
+	os.WriteFile(audio, []byte("audio"), 0644)
+	os.WriteFile(document, []byte("notes"), 0644)
+
+	tests := []struct {
+		text     string
+		expected string
+		ok       bool
+	}{
+		{audio, audio, true},
+		{"  " + audio + "\n", audio, true},
+		{`"` + audio + `"`, audio, true},
+		{"file://" + filepath.ToSlash(strings.ReplaceAll(audio, " ", "%20")), audio, true},
+		{"https://example.com/podcast/ep1.MP3?download=1", "https://example.com/podcast/ep1.MP3?download=1", true},
+		{"https://example.com/podcast/", "", false},
+		{document, "", false},
+		{filepath.Join(dir, "missing.wav"), "", false},
+		{"interview.m4a", "", false},
+		{audio + "\n" + audio, "", false},
+		{"שלום, מה שלומך?", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		media, ok := ClipboardMedia(tt.text)
+		if media != tt.expected || ok != tt.ok {
+			t.Errorf("ClipboardMedia(%q) = %q, %v, expected %q, %v", tt.text, media, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// TestClipboardWatcher tests that only newly copied media is offered, once
+func TestClipboardWatcher(t *testing.T) {
+	url := "https://example.com/ep1.mp3"
+	watcher := &ClipboardWatcher{}
+
+	if _, ok := watcher.Check(url); ok {
+		t.Error("Contents present when watching started should not be offered")
+	}
+	if _, ok := watcher.Check("other text"); ok {
+		t.Error("Text that isn't media should not be offered")
+	}
+	if media, ok := watcher.Check(url); !ok || media != url {
+		t.Errorf("Expected newly copied URL to be offered, got %q, %v", media, ok)
+	}
+	if _, ok := watcher.Check(url); ok {
+		t.Error("Unchanged clipboard should not be offered again")
+	}
+
+	watcher.Reset()
+	if _, ok := watcher.Check(url); ok {
+		t.Error("Contents present when watching restarted should not be offered")
+	}
+}
+
+// TestClipboardWatcherCopiedFile tests offering a file copied as a file reference,
+// whose clipboard text is only its name
+func TestClipboardWatcherCopiedFile(t *testing.T) {
+	memo := "/Users/me/Recordings/20240305 140709-1A2B3C4D.m4a"
+	watcher := &ClipboardWatcher{copiedFile: func() (string, bool) { return memo, true }}
+	watcher.Check("")
+	if media, ok := watcher.Check("20240305 140709-1A2B3C4D.m4a"); !ok || media != memo {
+		t.Errorf("Expected the copied file to be offered, got %q, %v", media, ok)
+	}
+	url := "https://example.com/ep1.mp3"
+	if media, ok := watcher.Check(url); !ok || media != url {
+		t.Errorf("Expected copied media text to be offered first, got %q, %v", media, ok)
+	}
+}
+
+// TestDownloadMedia tests downloading a media URL under its own file name
+func TestDownloadMedia(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/episodes/ep1.mp3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("audio data"))
+	}))
+	defer server.Close()
+
+	var lastPercent int
+	filePath, err := DownloadMedia(server.URL+"/episodes/ep1.mp3", func(msg string, percent int) { lastPercent = percent })
+	if err != nil {
+		t.Fatalf("DownloadMedia() error: %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(filePath))
+
+	if data, _ := os.ReadFile(filePath); filepath.Base(filePath) != "ep1.mp3" || string(data) != "audio data" {
+		t.Errorf("Downloaded %q with %q", filePath, data)
+	}
+	if lastPercent != 100 {
+		t.Errorf("Expected progress to reach 100%%, got %d", lastPercent)
+	}
+
+	if _, err := DownloadMedia(server.URL+"/missing.mp3", nil); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
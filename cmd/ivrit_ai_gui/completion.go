@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// completionShells are the shells -completion writes scripts for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// Flags taking a path, completed with file or folder names
+var (
+	fileFlags   = []string{"input", "output", "manifest", "ffmpeg", "ffprobe", "redact-words", "tls-cert", "tls-key"}
+	folderFlags = []string{"model-dir", "move-models", "translate-dir", "chat"}
+)
+
+// completionValues lists the values offered after the flags that take one of a fixed set
+func completionValues() map[string][]string {
+	return map[string][]string{
+		"model":           ModelIDs(),
+		"format":          validFormats,
+		"lang":            validTargetLangs,
+		"display":         validDisplayModes,
+		"channels":        {ChannelModeMix, ChannelModeSplit},
+		"engine":          validEngines,
+		"engine-fallback": validEngines,
+		"consensus":       {ConsensusModels, ConsensusTemperature},
+		"transliterate":   validTransliterations,
+		"encoding":        validEncodings,
+		"line-endings":    validLineEndings,
+		"numbers":         validNumberStyles,
+		"events":          {EventsAll, EventsNone},
+		"export":          {ExportGoogleDocs, ExportNotion},
+		"login":           {"google", "notion"},
+		"split-every":     {SplitAtParts, "5m", "10m", "15m"},
+		"completion":      completionShells,
+	}
+}
+
+// completionFlag is a command-line flag as the completion scripts describe it
+type completionFlag struct {
+	Name        string
+	Description string
+	Bool        bool     // Takes no value
+	Values      []string // Values offered, if a fixed set
+	Files       bool     // Takes a file path
+	Folders     bool     // Takes a folder path
+}
+
+// completionFlags describes the flags of fs in name order
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	values := completionValues()
+	var flags []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			Name:        f.Name,
+			Description: completionDescription(f.Usage),
+			Bool:        ok && boolFlag.IsBoolFlag(),
+			Values:      values[f.Name],
+			Files:       containsString(fileFlags, f.Name),
+			Folders:     containsString(folderFlags, f.Name),
+		})
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
+
+// completionDescription shortens a flag's usage to its first clause for the shell's menu
+func completionDescription(usage string) string {
+	for _, sep := range []string{" (", ": ", "; ", ", e.g."} {
+		if i := strings.Index(usage, sep); i > 0 {
+			usage = usage[:i]
+		}
+	}
+	if runes := []rune(usage); len(runes) > 70 {
+		usage = string(runes[:69]) + "…"
+	}
+	return usage
+}
+
+// CompletionScript returns the completion script for a shell, covering the
+// subcommands, the flags of fs, the model names and format values, for the program
+// installed as program
+func CompletionScript(shell, program string, fs *flag.FlagSet) (string, error) {
+	flags := completionFlags(fs)
+	switch shell {
+	case "bash":
+		return bashCompletion(program, flags), nil
+	case "zsh":
+		return zshCompletion(program, flags), nil
+	case "fish":
+		return fishCompletion(program, flags), nil
+	}
+	return "", fmt.Errorf("unknown shell %q (valid: %s)", shell, strings.Join(completionShells, ", "))
+}
+
+// completionFunction names the shell function completing program
+func completionFunction(program string) string {
+	return "_" + strings.NewReplacer("-", "_", ".", "_").Replace(program)
+}
+
+// bashCompletion completes values after the flags taking them, flag names after a
+// dash, and file names otherwise
+func bashCompletion(program string, flags []completionFlag) string {
+	var b strings.Builder
+	fn := completionFunction(program)
+	fmt.Fprintf(&b, "# bash completion for %s; load with: source <(%s -completion bash)\n", program, program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(&b, "    if [[ $COMP_CWORD -eq 1 && \"$cur\" != -* ]]; then\n        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\") $(compgen -f -- \"$cur\"))\n        return\n    fi\n", strings.Join(subcommandNames(), " "))
+	b.WriteString("    case \"$prev\" in\n")
+	var names, free []string
+	for _, f := range flags {
+		names = append(names, "-"+f.Name)
+		switch {
+		case f.Bool:
+		case len(f.Values) > 0:
+			fmt.Fprintf(&b, "        -%s|--%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.Name, f.Name, strings.Join(f.Values, " "))
+		case f.Files:
+			fmt.Fprintf(&b, "        -%s|--%s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", f.Name, f.Name)
+		case f.Folders:
+			fmt.Fprintf(&b, "        -%s|--%s) COMPREPLY=($(compgen -d -- \"$cur\")); return ;;\n", f.Name, f.Name)
+		default:
+			free = append(free, "-"+f.Name, "--"+f.Name)
+		}
+	}
+	if len(free) > 0 {
+		fmt.Fprintf(&b, "        %s) return ;;\n", strings.Join(free, "|"))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(names, " "))
+	b.WriteString("    else\n")
+	b.WriteString("        COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	b.WriteString("    fi\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -o filenames -F %s %s\n", fn, program)
+	return b.String()
+}
+
+// zshCompletion describes the flags to _arguments, with their values, and files
+// for the inputs after them
+func zshCompletion(program string, flags []completionFlag) string {
+	escape := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`)
+	var b strings.Builder
+	fn := completionFunction(program)
+	fmt.Fprintf(&b, "#compdef %s\n", program)
+	fmt.Fprintf(&b, "# zsh completion for %s; load with: source <(%s -completion zsh)\n", program, program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    _arguments \\\n")
+	for _, f := range flags {
+		spec := fmt.Sprintf("-%s[%s]", f.Name, escape.Replace(f.Description))
+		switch {
+		case f.Bool:
+		case len(f.Values) > 0:
+			spec += fmt.Sprintf(":%s:(%s)", f.Name, strings.Join(f.Values, " "))
+		case f.Files:
+			spec += ":file:_files"
+		case f.Folders:
+			spec += ":folder:_files -/"
+		default:
+			spec += ":" + f.Name + ":"
+		}
+		fmt.Fprintf(&b, "        '%s' \\\n", spec)
+	}
+	fmt.Fprintf(&b, "        '1:command or file:{_alternative \"commands:command:(%s)\" \"files:file:_files\"}' \\\n", strings.Join(subcommandNames(), " "))
+	b.WriteString("        '*:file:_files'\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "compdef %s %s\n", fn, program)
+	return b.String()
+}
+
+// fishCompletion declares each flag with its description and values, in fish's
+// old-style (single dash) options
+func fishCompletion(program string, flags []completionFlag) string {
+	escape := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s; load with: %s -completion fish | source\n", program, program)
+	fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a '%s'\n", program, strings.Join(subcommandNames(), " "))
+	for _, f := range flags {
+		fmt.Fprintf(&b, "complete -c %s -o %s -d '%s'", program, f.Name, escape.Replace(f.Description))
+		switch {
+		case f.Bool:
+		case len(f.Values) > 0:
+			fmt.Fprintf(&b, " -x -a '%s'", strings.Join(f.Values, " "))
+		case f.Files:
+			b.WriteString(" -r -F")
+		case f.Folders:
+			b.WriteString(" -x -a '(__fish_complete_directories)'")
+		default:
+			b.WriteString(" -x")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// testCompletionFlags registers the shared options and a few of the CLI's own flags
+func testCompletionFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("ivrit_ai", flag.ContinueOnError)
+	cfg := DefaultConfig()
+	cfg.RegisterFlags(fs)
+	fs.String("input", "", "Input audio/video file path (required; more files may follow as arguments)")
+	fs.String("translate-dir", "", "Translate the saved transcripts (txt, srt, vtt, json) in this directory")
+	fs.Bool("join", false, "Transcribe -input and the files after it as one recording")
+	return fs
+}
+
+// TestCompletionScript tests the completion scripts of each shell
+func TestCompletionScript(t *testing.T) {
+	fs := testCompletionFlags()
+	tests := []struct {
+		shell    string
+		expected []string
+	}{
+		{"bash", []string{
+			"complete -o filenames -F _ivrit_ai ivrit_ai",
+			`-model|--model) COMPREPLY=($(compgen -W "large-v3 turbo base" -- "$cur")); return ;;`,
+			`-format|--format) COMPREPLY=($(compgen -W "text json srt vtt markdown html ttml stl tokens custom all" -- "$cur"))`,
+			`-input|--input) COMPREPLY=($(compgen -f -- "$cur"))`,
+			`-translate-dir|--translate-dir) COMPREPLY=($(compgen -d -- "$cur"))`,
+			"-threads|--threads|",
+			`COMPREPLY=($(compgen -W "transcribe batch translate chat models voice-memos serve doctor" -- "$cur") $(compgen -f -- "$cur"))`,
+		}},
+		{"zsh", []string{
+			"#compdef ivrit_ai",
+			"'-model[Model to use]:model:(large-v3 turbo base)' \\",
+			"'-input[Input audio/video file path]:file:_files' \\",
+			"'-join[Transcribe -input and the files after it as one recording]' \\",
+			"compdef _ivrit_ai ivrit_ai",
+			`"commands:command:(transcribe batch translate chat models voice-memos serve doctor)"`,
+		}},
+		{"fish", []string{
+			"complete -c ivrit_ai -n __fish_use_subcommand -a 'transcribe batch translate chat models voice-memos serve doctor'",
+			"complete -c ivrit_ai -o model -d 'Model to use' -x -a 'large-v3 turbo base'",
+			"complete -c ivrit_ai -o input -d 'Input audio/video file path' -r -F",
+			"complete -c ivrit_ai -o join -d 'Transcribe -input and the files after it as one recording'\n",
+			"complete -c ivrit_ai -o threads -d 'Number of CPU threads' -x",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			script, err := CompletionScript(tt.shell, "ivrit_ai", fs)
+			if err != nil {
+				t.Fatalf("CompletionScript() error: %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(script, expected) {
+					t.Errorf("Expected %q in the script:\n%s", expected, script)
+				}
+			}
+		})
+	}
+
+	if _, err := CompletionScript("powershell", "ivrit_ai", fs); err == nil {
+		t.Error("Expected an error for an unsupported shell")
+	}
+}
+
+// TestCompletionDescription tests shortening flag usages for the shell menus
+func TestCompletionDescription(t *testing.T) {
+	tests := []struct {
+		usage, expected string
+	}{
+		{"Model to use: large-v3, turbo, base", "Model to use"},
+		{"Number of CPU threads (0 = auto)", "Number of CPU threads"},
+		{"Show help message", "Show help message"},
+		{strings.Repeat("a", 80), strings.Repeat("a", 69) + "…"},
+	}
+	for _, tt := range tests {
+		if got := completionDescription(tt.usage); got != tt.expected {
+			t.Errorf("completionDescription(%q) = %q, expected %q", tt.usage, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Valid option values, shared by flag validation and the GUI
+var (
+	validModels      = []string{"large-v3", "turbo", "base"} // Built-in; custom models are added in models.json
+	validFormats     = []string{"text", "json", "srt", "vtt", "markdown", "html", "ttml", "stl", "tokens", FormatCustom, FormatAll}
+	validTargetLangs = []string{"en", "es", "fr", "de", "ar", "ru", "yi", "am"}
+)
+
+// DecodeOptions holds advanced whisper decoding parameters
+type DecodeOptions struct {
+	BeamSize      int     `json:"beamSize,omitempty"`      // Beam search width (0 or 1 = greedy decoding)
+	Temperature   float64 `json:"temperature,omitempty"`   // Initial sampling temperature (0 = deterministic)
+	InitialPrompt string  `json:"initialPrompt,omitempty"` // Text that primes vocabulary and style (names, jargon)
+
+	// Segment length at inference, e.g. for subtitle-sized cues (0 = whisper's own segments)
+	MaxSegmentChars  int `json:"maxSegmentChars,omitempty"`  // Split at word boundaries so segments have at most about this many characters
+	MaxSegmentTokens int `json:"maxSegmentTokens,omitempty"` // End segments after this many tokens (local engine only)
+}
+
+// LimitsSegments reports whether the segment length is limited at inference
+func (d DecodeOptions) LimitsSegments() bool {
+	return d.MaxSegmentChars > 0 || d.MaxSegmentTokens > 0
+}
+
+// ValidateSegmentLimits checks that the engine can limit the segment length
+func (c AppConfig) ValidateSegmentLimits() error {
+	if c.Decode.MaxSegmentChars > 0 && (c.Engine == EngineFasterWhisper || c.Engine == EngineRunPod) {
+		return fmt.Errorf("a maximum segment length needs the local engine or whisper-server")
+	}
+	if c.Decode.MaxSegmentTokens > 0 && c.IsRemoteEngine() {
+		return fmt.Errorf("a maximum of tokens per segment needs the local engine")
+	}
+	return nil
+}
+
+// whisperMaxLen converts MaxSegmentChars to whisper's max_len, which counts bytes.
+// Hebrew letters take two bytes in UTF-8 and spaces and punctuation one, about 1.8
+// bytes per character of Hebrew text on average.
+func whisperMaxLen(chars int) int {
+	return chars * 9 / 5
+}
+
+// AppConfig holds the transcription options shared by the CLI and GUI.
+// Values are resolved from defaults, then the config file, then IVRIT_*
+// environment variables, and finally command-line flags.
+type AppConfig struct {
+	Model       string `json:"model"`
+	Format      string `json:"format"`
+	Translate   bool   `json:"translate"`
+	TargetLang  string `json:"targetLang"`
+	DisplayMode string `json:"displayMode"`           // Texts output when translating: DisplayBilingual, DisplayOriginal or DisplayTranslation
+	Threads     int    `json:"threads"`               // 0 = auto
+	ChannelMode string `json:"channels"`              // ChannelModeMix or ChannelModeSplit
+	Parallel    int    `json:"parallel"`              // Split-by-silence chunks (1 = off)
+	TrimSilence bool   `json:"trimSilence"`           // Skip long silence at the start and end of the audio (local engine)
+	OutputDir   string `json:"outputDir,omitempty"`   // Where outputs go when no -output is given (default: current directory)
+	FFmpegPath  string `json:"ffmpegPath,omitempty"`  // Explicit ffmpeg executable (default: search PATH and common locations)
+	FFprobePath string `json:"ffprobePath,omitempty"` // Explicit ffprobe executable
+	ModelDir    string `json:"modelDir,omitempty"`    // Where models are downloaded to (default: the folder chosen in the GUI, or ~/.cache/whisper)
+	WebhookURL  string `json:"webhookURL,omitempty"`  // Receives a POST when each CLI or server job completes or fails
+
+	// Transcription engine: EngineLocal, or a server to offload work to
+	Engine         string   `json:"engine,omitempty"`
+	EngineFallback []string `json:"engineFallback,omitempty"` // Engines tried in order when the previous one fails
+	EngineURL      string   `json:"engineUrl,omitempty"`      // Server address (default: the engine's usual local port)
+	EngineKey      string   `json:"engineKey,omitempty"`      // Bearer token for servers that require one
+	EngineMaxWait  int      `json:"engineMaxWait,omitempty"`  // Minutes a cloud (RunPod) job may take before it's cancelled (default: 60)
+
+	// Server mode security: clients must send one of the API keys; TLS is enabled when a certificate is set
+	APIKeys []string `json:"apiKeys,omitempty"`
+	TLSCert string   `json:"tlsCert,omitempty"` // PEM certificate file
+	TLSKey  string   `json:"tlsKey,omitempty"`  // PEM private key file
+
+	// Finished CLI and server transcripts are also uploaded to each of these
+	Destinations []DestinationConfig `json:"destinations,omitempty"`
+
+	Decode DecodeOptions `json:"decode"`
+
+	// Transcribe twice and flag disagreements for review: ConsensusModels or ConsensusTemperature ("" = off)
+	Consensus string `json:"consensus,omitempty"`
+
+	// Masking of phone numbers, ID numbers, emails and listed words in a redacted copy
+	Redact RedactOptions `json:"redact"`
+
+	// Latin-script transliteration of the Hebrew, for readers who don't read Hebrew letters
+	Transliteration TransliterationOptions `json:"transliteration"`
+
+	// Names and terms translations render as given, the same throughout a transcript
+	Glossary GlossaryOptions `json:"glossary"`
+
+	// Formats that show non-speech events such as [music]: EventsAll (default), EventsNone or a comma-separated list
+	Events string `json:"events,omitempty"`
+
+	// Character encoding and line endings of text, SRT and VTT files
+	Encoding OutputEncoding `json:"encoding"`
+
+	// Direction marks and punctuation fixes for players that show Hebrew punctuation on the wrong side
+	RTL RTLOptions `json:"rtl"`
+
+	// Where SRT and VTT cues show on screen, e.g. bottom-right for Hebrew
+	Cues CueOptions `json:"cues"`
+
+	// Numbers in digits or words, and consistent dates and times, in what is shown and saved
+	Numbers NumberOptions `json:"numbers"`
+
+	// How speakers are labeled in each format, e.g. "דובר 1:" or "[SPK1]", or not at all
+	SpeakerLabels SpeakerLabelOptions `json:"speakerLabels"`
+
+	// Lua script writing the custom format, e.g. a CSV for a subtitling tool
+	Script ExportScriptOptions `json:"script"`
+
+	// Model downloads through a HuggingFace mirror or proxy, with a token for gated models
+	Download DownloadOptions `json:"download"`
+
+	// Proxy, trusted certificate authorities and timeout of all network connections
+	Network NetworkOptions `json:"network"`
+}
+
+// DefaultConfig returns the built-in option defaults
+func DefaultConfig() AppConfig {
+	return AppConfig{
+		Model:       "turbo",
+		Format:      "text",
+		Translate:   false,
+		TargetLang:  "en",
+		DisplayMode: DisplayBilingual,
+		Threads:     0,
+		ChannelMode: ChannelModeMix,
+		Parallel:    1,
+		TrimSilence: true,
+		Engine:      EngineLocal,
+		Numbers:     NumberOptions{Style: NumbersTranscribed},
+	}
+}
+
+// configPath returns the location of the config file
+func configPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "ivrit-ai", "config.json")
+}
+
+// LoadConfig resolves the configuration from defaults, the config file and the environment.
+// A missing config file is not an error; an unreadable or invalid one is.
+func LoadConfig() (AppConfig, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(configPath())
+	if err == nil {
+		if err := cfg.parseConfigFile(data); err != nil {
+			return DefaultConfig(), fmt.Errorf("invalid config file %s: %v", configPath(), err)
+		}
+	} else if !os.IsNotExist(err) {
+		return DefaultConfig(), err
+	}
+
+	if err := cfg.applyEnv(os.Getenv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// parseConfigFile reads config.json over the current values. Files from before
+// display modes may set keepOriginal instead of displayMode.
+func (c *AppConfig) parseConfigFile(data []byte) error {
+	if err := json.Unmarshal(data, c); err != nil {
+		return err
+	}
+	var legacy struct {
+		KeepOriginal *bool  `json:"keepOriginal"`
+		DisplayMode  string `json:"displayMode"`
+	}
+	json.Unmarshal(data, &legacy)
+	if legacy.KeepOriginal != nil && legacy.DisplayMode == "" {
+		c.DisplayMode = displayModeForKeepOriginal(*legacy.KeepOriginal)
+	}
+	return nil
+}
+
+// applyEnv overrides options from IVRIT_* environment variables
+func (c *AppConfig) applyEnv(getenv func(string) string) error {
+	// The former keep-original switch, overridden by IVRIT_DISPLAY
+	if value := getenv("IVRIT_KEEP_ORIGINAL"); value != "" {
+		keep, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid IVRIT_KEEP_ORIGINAL: %q", value)
+		}
+		c.DisplayMode = displayModeForKeepOriginal(keep)
+	}
+
+	strVars := map[string]*string{
+		"IVRIT_MODEL":         &c.Model,
+		"IVRIT_FORMAT":        &c.Format,
+		"IVRIT_LANG":          &c.TargetLang,
+		"IVRIT_DISPLAY":       &c.DisplayMode,
+		"IVRIT_CHANNELS":      &c.ChannelMode,
+		"IVRIT_OUTPUT_DIR":    &c.OutputDir,
+		"IVRIT_FFMPEG":        &c.FFmpegPath,
+		"IVRIT_FFPROBE":       &c.FFprobePath,
+		"IVRIT_MODEL_DIR":     &c.ModelDir,
+		"IVRIT_PROMPT":        &c.Decode.InitialPrompt,
+		"IVRIT_WEBHOOK":       &c.WebhookURL,
+		"IVRIT_TLS_CERT":      &c.TLSCert,
+		"IVRIT_TLS_KEY":       &c.TLSKey,
+		"IVRIT_REDACT_WORDS":  &c.Redact.WordsFile,
+		"IVRIT_CONSENSUS":     &c.Consensus,
+		"IVRIT_ENGINE":        &c.Engine,
+		"IVRIT_ENGINE_URL":    &c.EngineURL,
+		"IVRIT_ENGINE_KEY":    &c.EngineKey,
+		"IVRIT_TRANSLITERATE": &c.Transliteration.Method,
+		"IVRIT_GLOSSARY":      &c.Glossary.File,
+		"IVRIT_EVENTS":        &c.Events,
+		"IVRIT_ENCODING":      &c.Encoding.Charset,
+		"IVRIT_LINE_ENDINGS":  &c.Encoding.LineEndings,
+		"IVRIT_NUMBERS":       &c.Numbers.Style,
+		"IVRIT_SPEAKER_LABEL": &c.SpeakerLabels.Name,
+		"IVRIT_SCRIPT":        &c.Script.Path,
+		"IVRIT_PROXY":         &c.Network.Proxy,
+		"IVRIT_CA_CERTS":      &c.Network.CACerts,
+		"HF_ENDPOINT":         &c.Download.Endpoint, // The names huggingface_hub uses
+		"HF_TOKEN":            &c.Download.Token,
+		"IVRIT_RATE_LIMIT":    &c.Download.RateLimit,
+	}
+	for name, field := range strVars {
+		if value := getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"IVRIT_TRANSLATE":          &c.Translate,
+		"IVRIT_REDACT":             &c.Redact.Enabled,
+		"IVRIT_TRANSLITERATE_ONLY": &c.Transliteration.Only,
+		"IVRIT_FIX_RTL":            &c.RTL.FixPunctuation,
+		"IVRIT_STRIP_RTL_MARKS":    &c.RTL.StripEmbedding,
+		"IVRIT_NORMALIZE_DATES":    &c.Numbers.Dates,
+		"IVRIT_TRIM_SILENCE":       &c.TrimSilence,
+	}
+	for name, field := range boolVars {
+		if value := getenv(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %q", name, value)
+			}
+			*field = parsed
+		}
+	}
+
+	intVars := map[string]*int{
+		"IVRIT_THREADS":            &c.Threads,
+		"IVRIT_PARALLEL":           &c.Parallel,
+		"IVRIT_BEAM_SIZE":          &c.Decode.BeamSize,
+		"IVRIT_MAX_SEGMENT_CHARS":  &c.Decode.MaxSegmentChars,
+		"IVRIT_MAX_SEGMENT_TOKENS": &c.Decode.MaxSegmentTokens,
+		"IVRIT_CONNECT_TIMEOUT":    &c.Network.ConnectTimeout,
+		"IVRIT_ENGINE_MAX_WAIT":    &c.EngineMaxWait,
+	}
+	for name, field := range intVars {
+		if value := getenv(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %q", name, value)
+			}
+			*field = parsed
+		}
+	}
+
+	// Comma-separated, so keys can be kept out of the config file
+	if value := getenv("IVRIT_API_KEYS"); value != "" {
+		c.APIKeys = splitList(value)
+	}
+	if value := getenv("IVRIT_ENGINE_FALLBACK"); value != "" {
+		c.EngineFallback = splitList(value)
+	}
+
+	if value := getenv("IVRIT_TEMPERATURE"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid IVRIT_TEMPERATURE: %q", value)
+		}
+		c.Decode.Temperature = parsed
+	}
+
+	return nil
+}
+
+// RegisterFlags binds command-line flags to the options. The current values
+// (from file and environment) become the flag defaults, so flags take precedence.
+func (c *AppConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Model, "model", c.Model, "Model to use: "+strings.Join(ModelIDs(), ", "))
+	fs.StringVar(&c.Format, "format", c.Format, "Output format: text, json, srt, vtt, markdown, html (with audio player), ttml (IMSC1), stl (EBU-STL), tokens (debug dump), custom (the -script export), or all (text, srt, vtt and json at once)")
+	fs.BoolVar(&c.Translate, "translate", c.Translate, "Translate to English using Mistral 8B")
+	fs.StringVar(&c.TargetLang, "lang", c.TargetLang, "Target language for translation: "+strings.Join(validTargetLangs, ", "))
+	fs.StringVar(&c.DisplayMode, "display", c.DisplayMode, "Texts to output when translating: bilingual (Hebrew and translation), original (Hebrew only) or translation")
+	fs.BoolFunc("keep-original", "Deprecated: same as -display bilingual, or with =false -display translation", func(value string) error {
+		keep, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.DisplayMode = displayModeForKeepOriginal(keep)
+		return nil
+	})
+	fs.IntVar(&c.Threads, "threads", c.Threads, "Number of CPU threads (0 = auto: measured fastest for each model on its first use)")
+	fs.StringVar(&c.ChannelMode, "channels", c.ChannelMode, "Multi-channel handling: mix (downmix to mono) or split (one speaker per channel)")
+	fs.IntVar(&c.Parallel, "parallel", c.Parallel, "Split long audio at silences into up to N chunks transcribed in parallel (1 = off)")
+	fs.BoolVar(&c.TrimSilence, "trim-silence", c.TrimSilence, "Skip long silence at the start and end of the audio, keeping the timestamps of the original (-trim-silence=false transcribes all of it)")
+	fs.StringVar(&c.Engine, "engine", c.Engine, "Transcription engine: local (built-in whisper.cpp), whisper-server (whisper.cpp server), faster-whisper (OpenAI-style faster-whisper server) or runpod (ivrit.ai's hosted transcription or a RunPod endpoint; uploads the audio)")
+	fs.Func("engine-fallback", "Comma-separated engines to try in order when -engine fails, e.g. local-cpu,runpod", func(value string) error {
+		c.EngineFallback = splitList(value)
+		return nil
+	})
+	fs.StringVar(&c.EngineURL, "engine-url", c.EngineURL, "Address of the whisper-server or faster-whisper engine (default: http://127.0.0.1:8080 and http://127.0.0.1:8000), or the RunPod endpoint ID")
+	fs.IntVar(&c.EngineMaxWait, "engine-max-wait", c.EngineMaxWait, "Minutes a runpod job may take, queueing included, before it's cancelled (default: 60)")
+	fs.StringVar(&c.FFmpegPath, "ffmpeg", c.FFmpegPath, "Path to the ffmpeg executable (default: search PATH and common install locations)")
+	fs.StringVar(&c.FFprobePath, "ffprobe", c.FFprobePath, "Path to the ffprobe executable (default: search PATH and common install locations)")
+	fs.StringVar(&c.WebhookURL, "webhook", c.WebhookURL, "POST job results (JSON) to this URL when each job completes or fails")
+	fs.StringVar(&c.TLSCert, "tls-cert", c.TLSCert, "TLS certificate (PEM) for server mode")
+	fs.StringVar(&c.TLSKey, "tls-key", c.TLSKey, "TLS private key (PEM) for server mode")
+	fs.IntVar(&c.Decode.BeamSize, "beam-size", c.Decode.BeamSize, "Beam search width (0 or 1 = greedy decoding)")
+	fs.Float64Var(&c.Decode.Temperature, "temperature", c.Decode.Temperature, "Initial sampling temperature (0 = deterministic)")
+	fs.StringVar(&c.Decode.InitialPrompt, "prompt", c.Decode.InitialPrompt, "Initial prompt with names or terms to prime the model")
+	fs.IntVar(&c.Decode.MaxSegmentChars, "max-segment-chars", c.Decode.MaxSegmentChars, "Split segments at word boundaries while transcribing so each has at most about this many characters, e.g. 42 for subtitles (0 = whisper's own segments)")
+	fs.IntVar(&c.Decode.MaxSegmentTokens, "max-segment-tokens", c.Decode.MaxSegmentTokens, "End segments after this many tokens while transcribing (0 = no limit; local engine only)")
+	fs.StringVar(&c.Consensus, "consensus", c.Consensus, "High accuracy: transcribe twice and flag disagreements for review, with models (turbo and large-v3) or temperature (two sampling temperatures)")
+	fs.BoolVar(&c.Redact.Enabled, "redact", c.Redact.Enabled, "Also write a redacted copy (<output>_redacted.<ext>) masking phone numbers, ID numbers, emails and listed words")
+	fs.StringVar(&c.Redact.WordsFile, "redact-words", c.Redact.WordsFile, "File of words and phrases to mask when redacting, one per line")
+	fs.StringVar(&c.Glossary.File, "glossary", c.Glossary.File, "File of names and terms to translate as given, \"hebrew = translation\" per line (under [en], [fr]... headings for one language)")
+	fs.StringVar(&c.Events, "events", c.Events, "Formats that show non-speech events such as [music], [laughter] and [silence]: all (default), none, or a comma-separated list such as text,markdown")
+	fs.StringVar(&c.Transliteration.Method, "transliterate", c.Transliteration.Method, "Add a Latin-script transliteration of the Hebrew: rules (built-in, approximate) or llm (local LLM via Ollama)")
+	fs.BoolVar(&c.Transliteration.Only, "transliterate-only", c.Transliteration.Only, "Output the transliteration in place of the Hebrew")
+	fs.StringVar(&c.Encoding.Charset, "encoding", c.Encoding.Charset, "Encoding of text, SRT and VTT files: utf-8 (default), utf-8-bom or utf-16le, for players that need a BOM to show Hebrew")
+	fs.StringVar(&c.Encoding.LineEndings, "line-endings", c.Encoding.LineEndings, "Line endings of text, SRT and VTT files: lf (default) or crlf (Windows)")
+	fs.BoolVar(&c.RTL.FixPunctuation, "fix-rtl", c.RTL.FixPunctuation, "Fix Hebrew punctuation shown on the wrong side in text, SRT and VTT files, adding direction marks (RLM/LRM) where needed")
+	fs.BoolVar(&c.RTL.StripEmbedding, "strip-rtl-marks", c.RTL.StripEmbedding, "Save plain text without the Unicode embedding marks around Hebrew lines")
+	fs.StringVar(&c.Cues.Line, "vtt-line", c.Cues.Line, "VTT cue line: a percentage from the top, e.g. 90%, or a line number, e.g. -1 for the bottom line")
+	fs.StringVar(&c.Cues.Align, "vtt-align", c.Cues.Align, "VTT cue text alignment: "+strings.Join(validVTTAligns, ", "))
+	fs.StringVar(&c.Cues.Position, "vtt-position", c.Cues.Position, "VTT cue position across the video, a percentage, e.g. 95%")
+	fs.StringVar(&c.Cues.SRTAlign, "srt-align", c.Cues.SRTAlign, "SRT cue position for players that read {\\an} tags (VLC, mpv, MPC-HC): bottom-right, bottom-center, top-right...")
+	fs.StringVar(&c.Numbers.Style, "numbers", c.Numbers.Style, "Write numbers in the Hebrew as transcribed (default), digits (spelled-out numbers above ten) or words (numbers in digits)")
+	fs.BoolVar(&c.Numbers.Dates, "normalize-dates", c.Numbers.Dates, "Write dates as DD/MM/YYYY and times as H:MM")
+	fs.StringVar(&c.SpeakerLabels.Name, "speaker-label", c.SpeakerLabels.Name, "Label of speakers without a name, {n} standing for their number, e.g. \"דובר {n}\" or SPK{n} (default \"Speaker {n}\"), or none to leave speaker labels out")
+	fs.StringVar(&c.Script.Path, "script", c.Script.Path, "Lua script writing the custom format (-format custom) from the transcript's segments")
+	fs.StringVar(&c.Script.Extension, "script-ext", c.Script.Extension, "File extension of the custom format, e.g. csv (default: txt)")
+	fs.StringVar(&c.Download.Endpoint, "hf-endpoint", c.Download.Endpoint, "HuggingFace mirror to download models from, e.g. https://hf-mirror.com (default: https://huggingface.co)")
+	fs.StringVar(&c.ModelDir, "model-dir", c.ModelDir, "Folder models are downloaded to, e.g. on an external drive (default: ~/.cache/whisper)")
+	fs.StringVar(&c.Download.RateLimit, "download-rate-limit", c.Download.RateLimit, "Bytes per second model and update downloads may take, e.g. 500K or 2M (default: no limit)")
+	fs.StringVar(&c.Network.Proxy, "proxy", c.Network.Proxy, "Proxy for all network connections (model downloads, Ollama, cloud engines, uploads), e.g. http://proxy:3128 (default: HTTPS_PROXY/HTTP_PROXY)")
+	fs.StringVar(&c.Network.CACerts, "ca-certs", c.Network.CACerts, "PEM file of certificate authorities to trust besides the system's, e.g. a corporate firewall's")
+	fs.IntVar(&c.Network.ConnectTimeout, "connect-timeout", c.Network.ConnectTimeout, "Seconds to wait for a connection and TLS handshake (default: 30)")
+}
+
+// Validate checks that all options have supported values
+func (c AppConfig) Validate() error {
+	if !containsString(ModelIDs(), c.Model) {
+		return fmt.Errorf("Invalid model '%s'. Valid options: %s", c.Model, strings.Join(ModelIDs(), ", "))
+	}
+	if !containsString(validFormats, c.Format) {
+		return fmt.Errorf("Invalid format '%s'. Valid options: %s", c.Format, strings.Join(validFormats, ", "))
+	}
+	if c.Translate && !containsString(validTargetLangs, c.TargetLang) {
+		return fmt.Errorf("Invalid target language '%s'. Valid options: %s", c.TargetLang, strings.Join(validTargetLangs, ", "))
+	}
+	if !containsString(validDisplayModes, c.DisplayMode) {
+		return fmt.Errorf("Invalid display mode '%s'. Valid options: %s", c.DisplayMode, strings.Join(validDisplayModes, ", "))
+	}
+	if c.Transliteration.Method != "" && !containsString(validTransliterations, c.Transliteration.Method) {
+		return fmt.Errorf("Invalid transliteration '%s'. Valid options: %s", c.Transliteration.Method, strings.Join(validTransliterations, ", "))
+	}
+	if err := validateEvents(c.Events); err != nil {
+		return err
+	}
+	if c.Transliteration.Only && c.Transliteration.Method == "" {
+		return fmt.Errorf("-transliterate-only needs a transliteration method (-transliterate)")
+	}
+	if c.Encoding.Charset != "" && !containsString(validEncodings, c.Encoding.Charset) {
+		return fmt.Errorf("Invalid encoding '%s'. Valid options: %s", c.Encoding.Charset, strings.Join(validEncodings, ", "))
+	}
+	if c.Encoding.LineEndings != "" && !containsString(validLineEndings, c.Encoding.LineEndings) {
+		return fmt.Errorf("Invalid line endings '%s'. Valid options: %s", c.Encoding.LineEndings, strings.Join(validLineEndings, ", "))
+	}
+	if c.Numbers.Style != "" && !containsString(validNumberStyles, c.Numbers.Style) {
+		return fmt.Errorf("Invalid number style '%s'. Valid options: %s", c.Numbers.Style, strings.Join(validNumberStyles, ", "))
+	}
+	if err := c.Cues.Validate(); err != nil {
+		return err
+	}
+	if err := c.SpeakerLabels.Validate(); err != nil {
+		return err
+	}
+	if c.Format == FormatCustom && c.Script.Path == "" {
+		return fmt.Errorf("the custom format needs an export script (-script)")
+	}
+	if c.ChannelMode != ChannelModeMix && c.ChannelMode != ChannelModeSplit {
+		return fmt.Errorf("Invalid channel mode '%s'. Valid options: mix, split", c.ChannelMode)
+	}
+	if c.Consensus != "" && c.Consensus != ConsensusModels && c.Consensus != ConsensusTemperature {
+		return fmt.Errorf("Invalid consensus mode '%s'. Valid options: %s, %s", c.Consensus, ConsensusModels, ConsensusTemperature)
+	}
+	remotes := 0
+	for i, engine := range c.EngineChain() {
+		if !containsString(validEngines, engine) {
+			return fmt.Errorf("Invalid engine '%s'. Valid options: %s", engine, strings.Join(validEngines, ", "))
+		}
+		if containsString(c.EngineChain()[:i], engine) {
+			return fmt.Errorf("engine %s is listed twice", engine)
+		}
+		if !isLocalEngine(engine) {
+			remotes++
+		}
+	}
+	// The engine URL and key belong to one server
+	if remotes > 1 {
+		return fmt.Errorf("the engine and its fallbacks can include only one remote engine")
+	}
+	if c.EngineURL != "" && !(c.remoteEngine() == EngineRunPod && isRunPodEndpointID(c.EngineURL)) {
+		if u, err := url.Parse(c.EngineURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("engine URL must be an http(s) URL, got %q", c.EngineURL)
+		}
+	}
+	if c.IsRemoteEngine() && c.Format == "tokens" {
+		return fmt.Errorf("the tokens format needs the local engine")
+	}
+	if c.remoteEngine() == EngineRunPod && c.EngineKey == "" {
+		return fmt.Errorf("the runpod engine needs an API key (IVRIT_ENGINE_KEY)")
+	}
+	if c.remoteEngine() == EngineWhisperServer && c.Consensus == ConsensusModels {
+		return fmt.Errorf("consensus with two models needs an engine that can switch models (local or faster-whisper)")
+	}
+	if c.EngineMaxWait < 0 {
+		return fmt.Errorf("the engine's maximum wait must not be negative")
+	}
+	if c.Threads < 0 || c.Parallel < 0 || c.Decode.BeamSize < 0 || c.Decode.MaxSegmentChars < 0 || c.Decode.MaxSegmentTokens < 0 {
+		return fmt.Errorf("threads, parallel, beam size and segment limits must not be negative")
+	}
+	if err := c.ValidateSegmentLimits(); err != nil {
+		return err
+	}
+	if c.Decode.Temperature < 0 || c.Decode.Temperature > 1 {
+		return fmt.Errorf("temperature must be between 0 and 1, got %v", c.Decode.Temperature)
+	}
+	if c.Download.Endpoint != "" {
+		if u, err := url.Parse(c.Download.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("HuggingFace endpoint must be an http(s) URL, got %q", c.Download.Endpoint)
+		}
+	}
+	if c.Download.Proxy != "" {
+		if u, err := url.Parse(c.Download.Proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+			return fmt.Errorf("proxy must be an http(s) or socks5 URL, got %q", c.Download.Proxy)
+		}
+	}
+	if _, err := ParseRateLimit(c.Download.RateLimit); err != nil {
+		return err
+	}
+	if err := c.Network.Validate(); err != nil {
+		return err
+	}
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook must be an http(s) URL, got %q", c.WebhookURL)
+		}
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("TLS needs both a certificate and a key")
+	}
+	for _, dest := range c.Destinations {
+		if _, err := NewDestination(dest); err != nil {
+			return err
+		}
+	}
+	for _, key := range c.APIKeys {
+		if len(key) < minAPIKeyLength {
+			return fmt.Errorf("API keys must be at least %d characters", minAPIKeyLength)
+		}
+	}
+	return nil
+}
+
+// IsRemoteEngine reports whether transcription runs on a server rather than in the app
+func (c AppConfig) IsRemoteEngine() bool {
+	return !isLocalEngine(c.Engine)
+}
+
+// EngineChain returns the engines to try in order: the engine, then its fallbacks
+func (c AppConfig) EngineChain() []string {
+	engine := c.Engine
+	if engine == "" {
+		engine = EngineLocal
+	}
+	return append([]string{engine}, c.EngineFallback...)
+}
+
+// remoteEngine returns the remote engine in the chain, which the engine URL and key are for
+func (c AppConfig) remoteEngine() string {
+	for _, engine := range c.EngineChain() {
+		if !isLocalEngine(engine) {
+			return engine
+		}
+	}
+	return ""
+}
+
+// EngineAddress returns the remote engine's address, or its default one
+func (c AppConfig) EngineAddress() string {
+	engine := c.remoteEngine()
+	if engine == EngineRunPod {
+		return runPodEndpointURL(c.EngineURL)
+	}
+	if c.EngineURL != "" {
+		return c.EngineURL
+	}
+	return defaultEngineURLs[engine]
+}
+
+// CPUThreads returns the configured thread count, or for auto the count measured
+// fastest for the model (tuned, from the settings), else the heuristic pick
+func (c AppConfig) CPUThreads(modelID string, tuned map[string]int) int {
+	if c.Threads > 0 {
+		return c.Threads
+	}
+	if tuned[modelID] > 0 {
+		return tuned[modelID]
+	}
+	return GetOptimalCPUThreads(modelID)
+}
+
+// NeedsThreadTuning reports whether the thread count for a model should be measured:
+// threads are automatic, the model runs locally and hasn't been benchmarked on this
+// machine yet. With fallback engines it isn't known in advance where the model runs.
+func (c AppConfig) NeedsThreadTuning(modelID string, tuned map[string]int) bool {
+	return c.Threads == 0 && tuned[modelID] == 0 && !c.IsRemoteEngine() && len(c.EngineFallback) == 0
+}
+
+// splitList splits a comma-separated list, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDefaultConfigValid tests that the built-in defaults pass validation
+func TestDefaultConfigValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Default config should be valid: %v", err)
+	}
+}
+
+// TestConfigPrecedence tests that file < environment < flags
+func TestConfigPrecedence(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-config-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("HOME", tmpDir)
+
+	path := configPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	config := `{"model": "large-v3", "format": "srt", "threads": 2, "decode": {"beamSize": 5}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("IVRIT_FORMAT", "vtt")
+	t.Setenv("IVRIT_THREADS", "6")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-threads", "3"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	if cfg.Model != "large-v3" {
+		t.Errorf("Model from file = %q, expected large-v3", cfg.Model)
+	}
+	if cfg.Format != "vtt" {
+		t.Errorf("Format from environment = %q, expected vtt", cfg.Format)
+	}
+	if cfg.Threads != 3 {
+		t.Errorf("Threads from flag = %d, expected 3", cfg.Threads)
+	}
+	if cfg.Decode.BeamSize != 5 {
+		t.Errorf("Beam size from file = %d, expected 5", cfg.Decode.BeamSize)
+	}
+	// Options not set anywhere keep their defaults
+	if cfg.TargetLang != "en" || cfg.DisplayMode != DisplayBilingual || cfg.Parallel != 1 {
+		t.Errorf("Unset options should keep defaults, got %+v", cfg)
+	}
+}
+
+// TestLoadConfigInvalidFile tests that a corrupt config file is reported
+func TestLoadConfigInvalidFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-config-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("HOME", tmpDir)
+
+	path := configPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err == nil {
+		t.Error("Expected error for invalid config file")
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("Expected default config for invalid file, got %+v", cfg)
+	}
+}
+
+// TestApplyEnvInvalid tests that malformed environment values are rejected
+func TestApplyEnvInvalid(t *testing.T) {
+	tests := map[string]string{
+		"IVRIT_THREADS":     "many",
+		"IVRIT_TRANSLATE":   "maybe",
+		"IVRIT_TEMPERATURE": "hot",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			err := cfg.applyEnv(func(key string) string {
+				if key == name {
+					return value
+				}
+				return ""
+			})
+			if err == nil {
+				t.Errorf("Expected error for %s=%q", name, value)
+			}
+		})
+	}
+}
+
+// TestApplyEnvAPIKeys tests that API keys are read as a comma-separated list
+func TestApplyEnvAPIKeys(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKeys = []string{"from-config-file-key"}
+	cfg.applyEnv(func(key string) string {
+		if key == "IVRIT_API_KEYS" {
+			return "first-key-0123456789, second-key-0123456789,"
+		}
+		return ""
+	})
+
+	expected := []string{"first-key-0123456789", "second-key-0123456789"}
+	if !reflect.DeepEqual(cfg.APIKeys, expected) {
+		t.Errorf("APIKeys = %q, expected %q", cfg.APIKeys, expected)
+	}
+}
+
+// TestConfigValidate tests option validation
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *AppConfig)
+		valid  bool
+	}{
+		{"Base model", func(c *AppConfig) { c.Model = "base" }, true},
+		{"Invalid model", func(c *AppConfig) { c.Model = "large" }, false},
+		{"Tokens format", func(c *AppConfig) { c.Format = "tokens" }, true},
+		{"Invalid format", func(c *AppConfig) { c.Format = "xml" }, false},
+		{"Invalid language when translating", func(c *AppConfig) { c.Translate = true; c.TargetLang = "xx" }, false},
+		{"Invalid language ignored without translation", func(c *AppConfig) { c.TargetLang = "xx" }, true},
+		{"Split channels", func(c *AppConfig) { c.ChannelMode = ChannelModeSplit }, true},
+		{"Invalid channel mode", func(c *AppConfig) { c.ChannelMode = "stereo" }, false},
+		{"Negative threads", func(c *AppConfig) { c.Threads = -1 }, false},
+		{"Beam search", func(c *AppConfig) { c.Decode.BeamSize = 5 }, true},
+		{"Temperature too high", func(c *AppConfig) { c.Decode.Temperature = 1.5 }, false},
+		{"Consensus", func(c *AppConfig) { c.Consensus = ConsensusTemperature }, true},
+		{"Invalid consensus mode", func(c *AppConfig) { c.Consensus = "vote" }, false},
+		{"UTF-16 with CRLF", func(c *AppConfig) { c.Encoding = OutputEncoding{EncodingUTF16LE, LineEndingsCRLF} }, true},
+		{"Invalid encoding", func(c *AppConfig) { c.Encoding.Charset = "windows-1255" }, false},
+		{"Invalid line endings", func(c *AppConfig) { c.Encoding.LineEndings = "cr" }, false},
+		{"Numbers in words", func(c *AppConfig) { c.Numbers.Style = NumbersWords }, true},
+		{"Invalid number style", func(c *AppConfig) { c.Numbers.Style = "roman" }, false},
+		{"Mirror and proxy", func(c *AppConfig) {
+			c.Download = DownloadOptions{Endpoint: "https://hf-mirror.com", Proxy: "socks5://127.0.0.1:1080"}
+		}, true},
+		{"Mirror without scheme", func(c *AppConfig) { c.Download.Endpoint = "hf-mirror.com" }, false},
+		{"Invalid proxy", func(c *AppConfig) { c.Download.Proxy = "ftp://proxy" }, false},
+		{"Network proxy", func(c *AppConfig) { c.Network = NetworkOptions{Proxy: "http://proxy:3128", ConnectTimeout: 10} }, true},
+		{"Network proxy without scheme", func(c *AppConfig) { c.Network.Proxy = "proxy:3128" }, false},
+		{"Negative connect timeout", func(c *AppConfig) { c.Network.ConnectTimeout = -1 }, false},
+		{"Webhook", func(c *AppConfig) { c.WebhookURL = "https://example.com/hooks/ivrit" }, true},
+		{"Webhook without scheme", func(c *AppConfig) { c.WebhookURL = "example.com/hook" }, false},
+		{"TLS", func(c *AppConfig) { c.TLSCert = "cert.pem"; c.TLSKey = "key.pem" }, true},
+		{"TLS without key", func(c *AppConfig) { c.TLSCert = "cert.pem" }, false},
+		{"API key", func(c *AppConfig) { c.APIKeys = []string{"0123456789abcdef"} }, true},
+		{"Short API key", func(c *AppConfig) { c.APIKeys = []string{"secret"} }, false},
+		{"Destination", func(c *AppConfig) {
+			c.Destinations = []DestinationConfig{{Name: "dav", Type: DestinationWebDAV, URL: "https://dav.example.com/files"}}
+		}, true},
+		{"Invalid destination", func(c *AppConfig) { c.Destinations = []DestinationConfig{{Name: "dav", Type: DestinationWebDAV}} }, false},
+		{"Remote engine", func(c *AppConfig) { c.Engine = EngineWhisperServer; c.EngineURL = "http://gpu-box:8080" }, true},
+		{"Invalid engine", func(c *AppConfig) { c.Engine = "openai" }, false},
+		{"Engine URL without scheme", func(c *AppConfig) { c.Engine = EngineFasterWhisper; c.EngineURL = "gpu-box:8000" }, false},
+		{"Tokens format with a remote engine", func(c *AppConfig) { c.Engine = EngineWhisperServer; c.Format = "tokens" }, false},
+		{"Model consensus with whisper-server", func(c *AppConfig) { c.Engine = EngineWhisperServer; c.Consensus = ConsensusModels }, false},
+		{"RunPod endpoint ID", func(c *AppConfig) { c.Engine = EngineRunPod; c.EngineURL = "abc123xyz"; c.EngineKey = "rp-key" }, true},
+		{"RunPod without API key", func(c *AppConfig) { c.Engine = EngineRunPod; c.EngineURL = "abc123xyz" }, false},
+		{"Subtitle-sized segments", func(c *AppConfig) { c.Decode.MaxSegmentChars = 42; c.Decode.MaxSegmentTokens = 20 }, true},
+		{"Negative segment length", func(c *AppConfig) { c.Decode.MaxSegmentChars = -1 }, false},
+		{"Segment length with whisper-server", func(c *AppConfig) { c.Engine = EngineWhisperServer; c.Decode.MaxSegmentChars = 42 }, true},
+		{"Segment length with faster-whisper", func(c *AppConfig) { c.Engine = EngineFasterWhisper; c.Decode.MaxSegmentChars = 42 }, false},
+		{"Segment tokens with whisper-server", func(c *AppConfig) { c.Engine = EngineWhisperServer; c.Decode.MaxSegmentTokens = 20 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.change(&cfg)
+			err := cfg.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, expected valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
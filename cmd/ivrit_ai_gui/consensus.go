@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Consensus modes: transcribe twice and flag where the two passes disagree
+const (
+	ConsensusModels      = "models"      // turbo and large-v3
+	ConsensusTemperature = "temperature" // The same model at two sampling temperatures
+)
+
+// consensusTemperature is the sampling temperature of the second pass in temperature mode
+const consensusTemperature = 0.4
+
+// ConsensusPass is one of the two transcriptions compared in consensus mode
+type ConsensusPass struct {
+	Model  string
+	Decode DecodeOptions
+}
+
+// Label names the pass in the review report
+func (p ConsensusPass) Label(mode string) string {
+	if mode == ConsensusTemperature {
+		return fmt.Sprintf("%s (temperature %.1f)", p.Model, p.Decode.Temperature)
+	}
+	return p.Model
+}
+
+// ConsensusSecondPass returns the pass whose transcript is compared with the first:
+// the other of turbo and large-v3, or the same model sampled at another temperature
+func ConsensusSecondPass(mode string, first ConsensusPass) ConsensusPass {
+	second := first
+	switch mode {
+	case ConsensusModels:
+		second.Model = "large-v3"
+		if first.Model == "large-v3" {
+			second.Model = "turbo"
+		}
+	case ConsensusTemperature:
+		second.Decode.Temperature = consensusTemperature
+		if first.Decode.Temperature == consensusTemperature {
+			second.Decode.Temperature = 0
+		}
+	}
+	return second
+}
+
+// Disagreement is a stretch of audio the two passes transcribed differently.
+// An empty reading means that pass heard no speech there.
+type Disagreement struct {
+	Start   float64
+	End     float64
+	Speaker int
+	First   string
+	Second  string
+}
+
+// ConsensusReview lists the disagreements between two passes for review
+type ConsensusReview struct {
+	First         string // Labels of the two passes
+	Second        string
+	Segments      int // Segments in the merged transcript
+	Disagreements []Disagreement
+}
+
+// MergeConsensus merges two transcripts of the same audio. The first pass's segments
+// are kept, and speech only the second pass heard is added, so nothing either pass
+// heard is lost. Each second-pass segment is compared with the first-pass segment it
+// overlaps most; segments whose readings differ (ignoring punctuation) are flagged.
+func MergeConsensus(first, second []Segment) ([]Segment, []Disagreement) {
+	matched := make([][]string, len(first))
+	var merged []Segment
+	var disagreements []Disagreement
+	for _, seg := range second {
+		best, bestOverlap := -1, 0.0
+		for i, f := range first {
+			if overlap := min(f.End, seg.End) - max(f.Start, seg.Start); overlap > bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+		}
+		text := strings.TrimSpace(seg.Text)
+		if best >= 0 {
+			matched[best] = append(matched[best], text)
+		} else if readingKey(text) != "" {
+			merged = append(merged, seg)
+			disagreements = append(disagreements, Disagreement{Start: seg.Start, End: seg.End, Speaker: seg.Speaker, Second: text})
+		}
+	}
+
+	for i, seg := range first {
+		merged = append(merged, seg)
+		text := strings.TrimSpace(seg.Text)
+		other := strings.TrimSpace(strings.Join(matched[i], " "))
+		if readingKey(text) != readingKey(other) {
+			disagreements = append(disagreements, Disagreement{Start: seg.Start, End: seg.End, Speaker: seg.Speaker, First: text, Second: other})
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Start < merged[j].Start })
+	sort.SliceStable(disagreements, func(i, j int) bool { return disagreements[i].Start < disagreements[j].Start })
+	return merged, disagreements
+}
+
+// FormatConsensusReview renders the review report: a Markdown table for the markdown
+// format and a plain list otherwise
+func FormatConsensusReview(review ConsensusReview, format string) string {
+	reading := func(text string) string {
+		if text == "" {
+			return "(no speech)"
+		}
+		return text
+	}
+	summary := fmt.Sprintf("Transcribed with %s and %s: %d of %d segments differ.", review.First, review.Second, len(review.Disagreements), review.Segments)
+
+	var b strings.Builder
+	if format == "markdown" {
+		b.WriteString("## Disagreements to Review\n\n")
+		b.WriteString(summary + "\n\n")
+		if len(review.Disagreements) == 0 {
+			return b.String()
+		}
+		b.WriteString("| Time | Speaker | " + review.First + " | " + review.Second + " |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, d := range review.Disagreements {
+			fmt.Fprintf(&b, "| %s | Speaker %d | %s | %s |\n", FormatTimestamp(d.Start, true)[:8], d.Speaker+1,
+				strings.ReplaceAll(reading(d.First), "|", `\|`), strings.ReplaceAll(reading(d.Second), "|", `\|`))
+		}
+		return b.String()
+	}
+
+	b.WriteString("Disagreements to review\n\n")
+	b.WriteString(summary + "\n")
+	for _, d := range review.Disagreements {
+		fmt.Fprintf(&b, "\n[%s] Speaker %d\n", FormatTimestamp(d.Start, true)[:8], d.Speaker+1)
+		fmt.Fprintf(&b, "  %s: %s\n", review.First, reading(d.First))
+		fmt.Fprintf(&b, "  %s: %s\n", review.Second, reading(d.Second))
+	}
+	return b.String()
+}
+
+// AppendConsensusReview adds the review report to the end of a text or markdown
+// transcript. Other formats can't hold it, so they are returned unchanged with false.
+func AppendConsensusReview(output string, review ConsensusReview, format string) (string, bool) {
+	if format != "text" && format != "markdown" {
+		return output, false
+	}
+	return strings.TrimRight(output, "\n") + "\n\n" + FormatConsensusReview(review, format), true
+}
+
+// transcribeSecondPass transcribes the audio again with the second pass's decoding
+// options, restoring the engine's options afterwards so it can be reused
+func transcribeSecondPass(engine TranscriptionEngine, pass ConsensusPass, restore DecodeOptions, audioPath string, cpuThreads int, splitChannels bool, progressCallback func(string)) ([]Segment, error) {
+	engine.SetDecodeOptions(pass.Decode)
+	defer engine.SetDecodeOptions(restore)
+	if splitChannels {
+		return TranscribeByChannel(engine, audioPath, pass.Model, cpuThreads, progressCallback, nil)
+	}
+	return engine.Transcribe(audioPath, pass.Model, cpuThreads, progressCallback, nil)
+}
+
+// consensusReviewFileName derives the review report file name for an input file,
+// written for output formats the report can't be appended to
+func consensusReviewFileName(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_review.md"
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMergeConsensus tests keeping the first pass, adding speech only the second heard
+// and flagging segments the passes read differently
+func TestMergeConsensus(t *testing.T) {
+	first := []Segment{
+		{Start: 0, End: 2, Text: "שלום לכולם.", Speaker: 0},
+		{Start: 2, End: 5, Text: "היום נדבר על החוזה", Speaker: 0},
+		{Start: 9, End: 10, Text: "תודה", Speaker: 1},
+	}
+	second := []Segment{
+		{Start: 0, End: 2.1, Text: "שלום לכולם"}, // Punctuation only
+		{Start: 2.1, End: 3.5, Text: "היום נדבר"},
+		{Start: 3.5, End: 5, Text: "על החוזים"},
+		{Start: 6, End: 8, Text: "כן, בדיוק", Speaker: 1}, // Missed by the first pass
+	}
+
+	merged, disagreements := MergeConsensus(first, second)
+	if len(merged) != 4 || merged[2].Text != "כן, בדיוק" || merged[3].Text != "תודה" {
+		t.Fatalf("Unexpected merged transcript: %+v", merged)
+	}
+
+	expected := []Disagreement{
+		{Start: 2, End: 5, First: "היום נדבר על החוזה", Second: "היום נדבר על החוזים"},
+		{Start: 6, End: 8, Speaker: 1, Second: "כן, בדיוק"},
+		{Start: 9, End: 10, Speaker: 1, First: "תודה"}, // Not heard by the second pass
+	}
+	if !reflect.DeepEqual(disagreements, expected) {
+		t.Errorf("MergeConsensus() disagreements = %+v, expected %+v", disagreements, expected)
+	}
+}
+
+// TestConsensusSecondPass tests choosing the other model or temperature
+func TestConsensusSecondPass(t *testing.T) {
+	tests := []struct {
+		mode  string
+		first ConsensusPass
+		model string
+		temp  float64
+		label string
+	}{
+		{ConsensusModels, ConsensusPass{Model: "turbo"}, "large-v3", 0, "large-v3"},
+		{ConsensusModels, ConsensusPass{Model: "large-v3"}, "turbo", 0, "turbo"},
+		{ConsensusModels, ConsensusPass{Model: "base"}, "large-v3", 0, "large-v3"},
+		{ConsensusTemperature, ConsensusPass{Model: "turbo"}, "turbo", 0.4, "turbo (temperature 0.4)"},
+		{ConsensusTemperature, ConsensusPass{Model: "turbo", Decode: DecodeOptions{Temperature: 0.4}}, "turbo", 0, "turbo (temperature 0.0)"},
+	}
+	for _, tt := range tests {
+		second := ConsensusSecondPass(tt.mode, tt.first)
+		if second.Model != tt.model || second.Decode.Temperature != tt.temp || second.Label(tt.mode) != tt.label {
+			t.Errorf("ConsensusSecondPass(%s, %+v) = %+v (%s)", tt.mode, tt.first, second, second.Label(tt.mode))
+		}
+	}
+}
+
+// TestFormatConsensusReview tests the review report in markdown and plain text
+func TestFormatConsensusReview(t *testing.T) {
+	review := ConsensusReview{First: "turbo", Second: "large-v3", Segments: 3, Disagreements: []Disagreement{
+		{Start: 62, End: 65, Speaker: 1, First: "a | b", Second: ""},
+	}}
+
+	markdown := FormatConsensusReview(review, "markdown")
+	for _, want := range []string{"## Disagreements to Review", "1 of 3 segments differ", "| Time | Speaker | turbo | large-v3 |", `| 00:01:02 | Speaker 2 | a \| b | (no speech) |`} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown review missing %q:\n%s", want, markdown)
+		}
+	}
+
+	text, appended := AppendConsensusReview("שלום\n", review, "text")
+	if !appended || !strings.HasPrefix(text, "שלום\n\nDisagreements to review") || !strings.Contains(text, "  large-v3: (no speech)") {
+		t.Errorf("Unexpected text review:\n%s", text)
+	}
+	if _, appended := AppendConsensusReview("{}", review, "json"); appended {
+		t.Error("The review can't be appended to JSON")
+	}
+	if name := consensusReviewFileName("/recordings/deposition.m4a"); name != "deposition_review.md" {
+		t.Errorf("consensusReviewFileName() = %q", name)
+	}
+}
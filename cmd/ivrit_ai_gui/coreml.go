@@ -0,0 +1,227 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Core ML runs whisper's encoder on the Apple Neural Engine, 2-3x faster than the
+// CPU on M-series Macs. whisper.cpp built with Core ML support loads the compiled
+// encoder <model>-encoder.mlmodelc found next to the model file; encoders are kept
+// in a separate folder and linked next to the model while Core ML is turned on.
+var (
+	coreMLEnabled = true
+	coreMLMutex   sync.RWMutex
+)
+
+// quantizationSuffix matches the quantization whisper.cpp drops from a model's name
+// when looking for its Core ML encoder (ggml-large-v3-q5_0.bin uses ggml-large-v3's)
+var quantizationSuffix = regexp.MustCompile(`-q\d+_\d+$`)
+
+// SetCoreMLEnabled turns use of Core ML encoders on or off for models loaded from now on
+func SetCoreMLEnabled(enabled bool) {
+	coreMLMutex.Lock()
+	defer coreMLMutex.Unlock()
+	coreMLEnabled = enabled
+}
+
+// CoreMLAvailable reports whether this is an Apple Silicon Mac with a whisper.cpp
+// built with Core ML support
+func CoreMLAvailable() bool {
+	return runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" && hasCoreML(WhisperSystemInfo())
+}
+
+// hasCoreML reports whether whisper.cpp's system info lists Core ML support
+func hasCoreML(systemInfo string) bool {
+	return strings.Contains(systemInfo, "COREML = 1")
+}
+
+// coreMLEncoderPath is where whisper.cpp looks for a model's Core ML encoder
+func coreMLEncoderPath(modelPath string) string {
+	stem := strings.TrimSuffix(modelPath, filepath.Ext(modelPath))
+	return quantizationSuffix.ReplaceAllString(stem, "") + "-encoder.mlmodelc"
+}
+
+// coreMLStorePath is where a model's Core ML encoder is kept while not linked
+func coreMLStorePath(modelPath string) string {
+	return filepath.Join(ModelDir(), "coreml", filepath.Base(coreMLEncoderPath(modelPath)))
+}
+
+// prepareCoreML gets a model's Core ML encoder ready to be loaded with it, or out of
+// the way when Core ML is off. A missing encoder is downloaded when the model's
+// configuration names one. Problems are reported as progress and never stop the
+// model from loading on the CPU/GPU instead.
+func prepareCoreML(modelID string, info ModelInfo, modelPath string, progressCallback func(string, int)) {
+	if !CoreMLAvailable() {
+		return
+	}
+	coreMLMutex.RLock()
+	enabled := coreMLEnabled
+	coreMLMutex.RUnlock()
+
+	report := func(msg string) {
+		if progressCallback != nil {
+			progressCallback(msg, -1)
+		}
+	}
+
+	storePath := coreMLStorePath(modelPath)
+	if enabled && !exists(storePath) && !exists(coreMLEncoderPath(modelPath)) {
+		if info.CoreMLID == "" || info.CoreMLFile == "" {
+			report(fmt.Sprintf("No Core ML encoder available for %s; using Metal/CPU", modelID))
+			return
+		}
+		report(fmt.Sprintf("Downloading the Core ML encoder for %s...", modelID))
+		if err := downloadCoreMLEncoder(info, storePath, progressCallback); err != nil {
+			report(fmt.Sprintf("Core ML encoder download failed (%v); using Metal/CPU", err))
+			return
+		}
+	}
+	if err := applyCoreMLSetting(modelPath); err != nil {
+		report(fmt.Sprintf("Could not switch the Core ML encoder: %v", err))
+	}
+}
+
+// applyCoreMLSetting links or unlinks a model's stored Core ML encoder according to
+// the current setting, without downloading anything
+func applyCoreMLSetting(modelPath string) error {
+	if !CoreMLAvailable() {
+		return nil
+	}
+	coreMLMutex.RLock()
+	enabled := coreMLEnabled
+	coreMLMutex.RUnlock()
+	return linkCoreMLEncoder(modelPath, coreMLStorePath(modelPath), enabled)
+}
+
+// linkCoreMLEncoder links the stored encoder next to the model, or removes it from
+// there. An encoder placed next to the model by hand is moved to the store first,
+// so it can be turned off too.
+func linkCoreMLEncoder(modelPath, storePath string, enabled bool) error {
+	encoderPath := coreMLEncoderPath(modelPath)
+	info, err := os.Lstat(encoderPath)
+	if err == nil && info.Mode()&os.ModeSymlink == 0 {
+		if enabled {
+			return nil
+		}
+		if exists(storePath) {
+			return fmt.Errorf("%s is already stored", filepath.Base(storePath))
+		}
+		if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+			return err
+		}
+		return os.Rename(encoderPath, storePath)
+	}
+
+	if !enabled {
+		if err == nil {
+			return os.Remove(encoderPath)
+		}
+		return nil
+	}
+	if err == nil {
+		if target, _ := os.Readlink(encoderPath); target == storePath {
+			return nil
+		}
+		os.Remove(encoderPath) // Stale link
+	}
+	if !exists(storePath) {
+		return nil
+	}
+	return os.Symlink(storePath, encoderPath)
+}
+
+// downloadCoreMLEncoder downloads a model's zipped Core ML encoder and unpacks it to storePath
+func downloadCoreMLEncoder(info ModelInfo, storePath string, progressCallback func(string, int)) error {
+	storeDir := filepath.Dir(storePath)
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		return err
+	}
+	zipFile, err := os.CreateTemp(storeDir, "download_*.zip")
+	if err != nil {
+		return err
+	}
+	zipPath := zipFile.Name()
+	zipFile.Close()
+	defer os.Remove(zipPath)
+
+	if err := downloadModelFromHuggingFace(info.CoreMLID, info.CoreMLFile, zipPath, progressCallback); err != nil {
+		return err
+	}
+
+	unpackDir, err := os.MkdirTemp(storeDir, "unpack_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(unpackDir)
+	if err := unzip(zipPath, unpackDir); err != nil {
+		return err
+	}
+
+	// The archive holds one .mlmodelc folder, named after the original model
+	matches, _ := filepath.Glob(filepath.Join(unpackDir, "*.mlmodelc"))
+	if len(matches) != 1 {
+		return fmt.Errorf("%s does not contain a Core ML encoder", info.CoreMLFile)
+	}
+	return os.Rename(matches[0], storePath)
+}
+
+// unzip extracts a zip archive into dir, refusing entries that would land outside it
+func unzip(zipPath, dir string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		path := filepath.Join(dir, file.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := extractZipFile(file, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes one archive entry to path
+func extractZipFile(file *zip.File, path string) error {
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// exists reports whether a file or folder exists at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
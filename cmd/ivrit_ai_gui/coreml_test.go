@@ -0,0 +1,116 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasCoreML(t *testing.T) {
+	if !hasCoreML("WHISPER : COREML = 1 | OPENVINO = 0 | Metal : EMBED_LIBRARY = 1 |") {
+		t.Error("Core ML build not detected")
+	}
+	if hasCoreML("WHISPER : COREML = 0 | OPENVINO = 0 |") {
+		t.Error("build without Core ML detected as Core ML")
+	}
+}
+
+func TestCoreMLEncoderPath(t *testing.T) {
+	tests := map[string]string{
+		"/m/ggml-base.bin":                 "/m/ggml-base-encoder.mlmodelc",
+		"/m/ggml-large-v3-turbo-ivrit.bin": "/m/ggml-large-v3-turbo-ivrit-encoder.mlmodelc",
+		"/m/ggml-large-v3-q5_0.bin":        "/m/ggml-large-v3-encoder.mlmodelc",
+	}
+	for modelPath, want := range tests {
+		if got := coreMLEncoderPath(modelPath); got != want {
+			t.Errorf("coreMLEncoderPath(%q) = %q, want %q", modelPath, got, want)
+		}
+	}
+}
+
+func TestLinkCoreMLEncoder(t *testing.T) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "ggml-base.bin")
+	encoderPath := filepath.Join(dir, "ggml-base-encoder.mlmodelc")
+	storePath := filepath.Join(dir, "coreml", "ggml-base-encoder.mlmodelc")
+
+	// Nothing stored: nothing to link
+	if err := linkCoreMLEncoder(modelPath, storePath, true); err != nil || exists(encoderPath) {
+		t.Fatalf("link without a stored encoder: %v, exists %v", err, exists(encoderPath))
+	}
+
+	if err := os.MkdirAll(storePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := linkCoreMLEncoder(modelPath, storePath, true); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(encoderPath); err != nil || target != storePath {
+		t.Fatalf("encoder link = %q, %v, want %q", target, err, storePath)
+	}
+	// Enabling again keeps the link
+	if err := linkCoreMLEncoder(modelPath, storePath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := linkCoreMLEncoder(modelPath, storePath, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(encoderPath); !os.IsNotExist(err) || !exists(storePath) {
+		t.Fatal("disabling should remove the link and keep the stored encoder")
+	}
+
+	// An encoder placed next to the model by hand is moved to the store when disabled
+	if err := os.RemoveAll(storePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(encoderPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := linkCoreMLEncoder(modelPath, storePath, false); err != nil {
+		t.Fatal(err)
+	}
+	if exists(encoderPath) || !exists(storePath) {
+		t.Error("hand-placed encoder should be moved to the store")
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	dir := t.TempDir()
+	writeZip := func(name string, entries ...string) string {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := zip.NewWriter(f)
+		for _, entry := range entries {
+			fw, err := w.Create(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte("data"))
+		}
+		w.Close()
+		f.Close()
+		return path
+	}
+
+	out := filepath.Join(dir, "out")
+	good := writeZip("good.zip", "ggml-base-encoder.mlmodelc/model.mil", "ggml-base-encoder.mlmodelc/weights/weight.bin")
+	if err := unzip(good, out); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(filepath.Join(out, "ggml-base-encoder.mlmodelc", "weights", "weight.bin")) {
+		t.Error("archive not extracted")
+	}
+
+	bad := writeZip("bad.zip", "../escape.txt")
+	if err := unzip(bad, filepath.Join(dir, "out2")); err == nil {
+		t.Error("entries outside the folder should be refused")
+	}
+	if exists(filepath.Join(dir, "escape.txt")) {
+		t.Error("entry written outside the folder")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// customModelNamePattern is what custom model names may contain: they become
+// -model values and local file names
+var customModelNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// huggingFaceRepoPattern matches a HuggingFace repository ID ("owner/name")
+var huggingFaceRepoPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*/[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// modelsConfigPaths returns the locations models.json is looked for in, in order
+func modelsConfigPaths() []string {
+	return []string{
+		"models.json",                      // Current directory
+		filepath.Join(".", "models.json"),  // Explicit current dir
+		filepath.Join("..", "models.json"), // Parent directory
+		userModelsConfigPath(),             // User config
+	}
+}
+
+// userModelsConfigPath returns the models.json in the user config directory
+func userModelsConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "ivrit-ai", "models.json")
+}
+
+// modelsConfigPath returns the models.json the models are loaded from, or the user
+// config one when there is none, which custom models are added to
+func modelsConfigPath() string {
+	for _, path := range modelsConfigPaths() {
+		if data, err := os.ReadFile(path); err == nil {
+			var config ModelsConfig
+			if err := json.Unmarshal(data, &config); err == nil && len(config.Models) > 0 {
+				return path
+			}
+		}
+	}
+	return userModelsConfigPath()
+}
+
+// ModelIDs returns the names of the configured models: the built-in ones, then
+// custom models in alphabetical order
+func ModelIDs() []string {
+	models := loadModelsConfig()
+	var ids []string
+	for _, id := range validModels {
+		if _, ok := models[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return append(ids, CustomModelIDs()...)
+}
+
+// CustomModelIDs returns the names of the models added to models.json besides the
+// built-in ones, in alphabetical order
+func CustomModelIDs() []string {
+	var ids []string
+	for id := range loadModelsConfig() {
+		if !containsString(validModels, id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// AddCustomModel adds a GGML model to models.json under a name: either a local
+// model file (info.Path), used where it is, or a file in a HuggingFace
+// repository (info.ID and info.File), downloaded on first use like the built-in
+// models. The model is written with the models already configured, so adding
+// one to a new user models.json doesn't hide the built-in ones.
+func AddCustomModel(name string, info ModelInfo) error {
+	name = strings.TrimSpace(name)
+	if !customModelNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid model name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	models := loadModelsConfig()
+	if _, exists := models[name]; exists {
+		return fmt.Errorf("a model named %q already exists", name)
+	}
+
+	switch {
+	case info.Path != "":
+		path, err := filepath.Abs(info.Path)
+		if err != nil {
+			return err
+		}
+		if stat, err := os.Stat(path); err != nil {
+			return fmt.Errorf("model file not found: %s", info.Path)
+		} else if stat.IsDir() {
+			return fmt.Errorf("%s is a directory, not a model file", info.Path)
+		}
+		info = ModelInfo{Path: path, Description: info.Description}
+	case info.ID != "":
+		if !huggingFaceRepoPattern.MatchString(info.ID) {
+			return fmt.Errorf("invalid HuggingFace repository %q: expected owner/name", info.ID)
+		}
+		if info.File == "" {
+			info.File = "ggml-model.bin"
+		}
+		// Models of different repositories are often all named ggml-model.bin
+		info.LocalFileName = "ggml-" + name + ".bin"
+	default:
+		return fmt.Errorf("choose a model file or a HuggingFace repository")
+	}
+	if info.Description == "" {
+		info.Description = "Custom model"
+	}
+	models[name] = info
+
+	path := modelsConfigPath()
+	data, err := json.MarshalIndent(ModelsConfig{Models: models}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTempModelsConfig runs a test from an empty directory with an empty home
+// directory, so custom models go to a new user models.json
+func useTempModelsConfig(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(t.TempDir(), "work")
+	os.MkdirAll(dir, 0755)
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	os.Chdir(dir)
+	return home
+}
+
+// TestAddCustomModelFromFile tests adding a model file on this computer
+func TestAddCustomModelFromFile(t *testing.T) {
+	home := useTempModelsConfig(t)
+	modelPath := filepath.Join(home, "models", "ggml-tiny-he.bin")
+	os.MkdirAll(filepath.Dir(modelPath), 0755)
+	os.WriteFile(modelPath, []byte("lmgg"), 0644)
+
+	if err := AddCustomModel("tiny-he", ModelInfo{Path: modelPath}); err != nil {
+		t.Fatalf("AddCustomModel() error: %v", err)
+	}
+	if modelsConfigPath() != userModelsConfigPath() {
+		t.Errorf("Expected the model added to %s, got %s", userModelsConfigPath(), modelsConfigPath())
+	}
+
+	ids := ModelIDs()
+	expected := []string{"large-v3", "turbo", "base", "tiny-he"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected models %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected models %v, got %v", expected, ids)
+			break
+		}
+	}
+	if path, err := FindLocalModel("tiny-he"); err != nil || path != modelPath {
+		t.Errorf("Expected the model file %s, got %q, %v", modelPath, path, err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Model = "tiny-he"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the custom model valid for -model, got %v", err)
+	}
+
+	os.Remove(modelPath)
+	if _, err := GetModelPath("tiny-he", nil); err == nil {
+		t.Error("Expected an error for a missing model file, not a download")
+	}
+}
+
+// TestAddCustomModelFromHub tests adding a model in a HuggingFace repository
+func TestAddCustomModelFromHub(t *testing.T) {
+	useTempModelsConfig(t)
+	if err := AddCustomModel("medium-he", ModelInfo{ID: "someone/whisper-medium-he-ggml"}); err != nil {
+		t.Fatalf("AddCustomModel() error: %v", err)
+	}
+	info := loadModelsConfig()["medium-he"]
+	if info.File != "ggml-model.bin" || info.LocalFileName != "ggml-medium-he.bin" || info.Path != "" {
+		t.Errorf("Unexpected model entry: %+v", info)
+	}
+	if ids := CustomModelIDs(); len(ids) != 1 || ids[0] != "medium-he" {
+		t.Errorf("Expected the custom model listed, got %v", ids)
+	}
+}
+
+// TestAddCustomModelErrors tests that invalid custom models are rejected
+func TestAddCustomModelErrors(t *testing.T) {
+	useTempModelsConfig(t)
+	tests := []struct {
+		name      string
+		modelName string
+		info      ModelInfo
+	}{
+		{"Empty name", "", ModelInfo{ID: "org/repo"}},
+		{"Name with spaces", "my model", ModelInfo{ID: "org/repo"}},
+		{"Existing name", "turbo", ModelInfo{ID: "org/repo"}},
+		{"Missing file", "missing", ModelInfo{Path: "/nonexistent/ggml-model.bin"}},
+		{"Directory", "dir", ModelInfo{Path: os.TempDir()}},
+		{"Invalid repository", "repo", ModelInfo{ID: "https://huggingface.co/org/repo"}},
+		{"No source", "nothing", ModelInfo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := AddCustomModel(tt.modelName, tt.info); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+	if ids := CustomModelIDs(); len(ids) != 0 {
+		t.Errorf("Expected no models added, got %v", ids)
+	}
+}
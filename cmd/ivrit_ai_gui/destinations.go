@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Destination types
+const (
+	DestinationS3        = "s3"
+	DestinationWebDAV    = "webdav"
+	DestinationNextcloud = "nextcloud"
+)
+
+// destinationTimeout bounds a single upload request
+const destinationTimeout = 5 * time.Minute
+
+// DestinationConfig describes where finished transcripts are uploaded.
+// Secret fields may reference environment variables ("$AWS_SECRET_ACCESS_KEY")
+// so credentials can be kept out of the config file.
+type DestinationConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // DestinationS3, DestinationWebDAV or DestinationNextcloud
+
+	// S3-compatible storage (AWS, MinIO, Cloudflare R2, ...)
+	Endpoint  string `json:"endpoint,omitempty"` // Default: https://s3.<region>.amazonaws.com
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket,omitempty"`
+	Prefix    string `json:"prefix,omitempty"` // Key prefix, e.g. "transcripts/"
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+
+	// WebDAV and Nextcloud
+	URL      string `json:"url,omitempty"` // WebDAV folder URL, or the Nextcloud server URL
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"` // For Nextcloud, an app password
+	Path     string `json:"path,omitempty"`     // Folder inside the WebDAV root or Nextcloud files
+}
+
+// Destination uploads finished transcripts
+type Destination interface {
+	// Upload stores data under name and returns where it ended up
+	Upload(name string, data []byte) (string, error)
+}
+
+// NewDestination creates the uploader for a configured destination
+func NewDestination(cfg DestinationConfig) (Destination, error) {
+	switch cfg.Type {
+	case DestinationS3:
+		if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+			return nil, fmt.Errorf("destination %q: s3 needs bucket, accessKey and secretKey", cfg.Name)
+		}
+		region := cfg.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		endpoint := cfg.Endpoint
+		if endpoint == "" {
+			endpoint = "https://s3." + region + ".amazonaws.com"
+		}
+		if _, err := parseHTTPURL(endpoint); err != nil {
+			return nil, fmt.Errorf("destination %q: invalid endpoint: %v", cfg.Name, err)
+		}
+		return &s3Destination{
+			endpoint:  strings.TrimSuffix(endpoint, "/"),
+			region:    region,
+			bucket:    cfg.Bucket,
+			prefix:    cfg.Prefix,
+			accessKey: os.ExpandEnv(cfg.AccessKey),
+			secretKey: os.ExpandEnv(cfg.SecretKey),
+		}, nil
+
+	case DestinationWebDAV, DestinationNextcloud:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("destination %q: %s needs url", cfg.Name, cfg.Type)
+		}
+		base, err := parseHTTPURL(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("destination %q: invalid url: %v", cfg.Name, err)
+		}
+		// Nextcloud exposes each user's files over WebDAV at a fixed location
+		if cfg.Type == DestinationNextcloud {
+			if cfg.Username == "" {
+				return nil, fmt.Errorf("destination %q: nextcloud needs username", cfg.Name)
+			}
+			base = base.JoinPath("remote.php", "dav", "files", cfg.Username)
+		}
+		return &webDAVDestination{
+			base:     base,
+			folder:   splitPath(cfg.Path),
+			username: cfg.Username,
+			password: os.ExpandEnv(cfg.Password),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("destination %q: unknown type %q (valid: s3, webdav, nextcloud)", cfg.Name, cfg.Type)
+}
+
+// UploadToDestinations uploads a transcript to every configured destination.
+// All destinations are attempted; the first failure is returned.
+func UploadToDestinations(configs []DestinationConfig, name string, data []byte) error {
+	var firstErr error
+	for _, cfg := range configs {
+		dest, err := NewDestination(cfg)
+		if err == nil {
+			var location string
+			if location, err = dest.Upload(name, data); err == nil {
+				fmt.Fprintf(os.Stderr, "Uploaded to %s: %s\n", cfg.Name, location)
+				continue
+			}
+			err = fmt.Errorf("upload to %s failed: %v", cfg.Name, err)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// uploadOrWarn uploads a copy of a saved transcript like UploadToDestinations,
+// warning about a failure rather than failing the transcription. Progress goes to
+// stderr, away from any transcript written to stdout.
+func uploadOrWarn(configs []DestinationConfig, name string, data []byte) {
+	if err := UploadToDestinations(configs, name, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s not uploaded: %v\n", name, err)
+	}
+}
+
+// s3Destination uploads objects to S3-compatible storage using path-style URLs
+type s3Destination struct {
+	endpoint  string
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+}
+
+// Upload PUTs the object, signed with AWS Signature Version 4
+func (d *s3Destination) Upload(name string, data []byte) (string, error) {
+	objectURL := d.endpoint + "/" + awsURIEncode(d.bucket, false) + "/" + awsURIEncode(d.prefix+name, false)
+
+	req, err := http.NewRequest("PUT", objectURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentTypeForTranscript(name))
+	signS3Request(req, sha256Hex(data), d.accessKey, d.secretKey, d.region, time.Now())
+
+	if err := doUpload(req); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s%s", d.bucket, d.prefix, name), nil
+}
+
+// signS3Request adds AWS Signature Version 4 headers, signing the host and
+// all headers already set on the request
+func signS3Request(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Canonical headers: lowercase names, sorted, including host
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// awsURIEncode percent-encodes everything but unreserved characters (and '/' unless encodeSlash)
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// webDAVDestination uploads files to a WebDAV folder (also used for Nextcloud)
+type webDAVDestination struct {
+	base     *url.URL
+	folder   []string
+	username string
+	password string
+}
+
+// Upload PUTs the file, creating the destination folder if it doesn't exist yet
+func (d *webDAVDestination) Upload(name string, data []byte) (string, error) {
+	fileURL := d.base.JoinPath(append(d.folder, name)...).String()
+
+	err := d.put(fileURL, name, data)
+	if err == errWebDAVMissingFolder {
+		if err := d.makeFolders(); err != nil {
+			return "", err
+		}
+		err = d.put(fileURL, name, data)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fileURL, nil
+}
+
+// errWebDAVMissingFolder reports that the folder to upload into doesn't exist
+var errWebDAVMissingFolder = fmt.Errorf("folder does not exist")
+
+// put uploads one file
+func (d *webDAVDestination) put(fileURL, name string, data []byte) error {
+	req, err := http.NewRequest("PUT", fileURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentTypeForTranscript(name))
+
+	err = d.do(req)
+	if httpErr, ok := err.(uploadHTTPError); ok && (httpErr.status == http.StatusConflict || httpErr.status == http.StatusNotFound) {
+		return errWebDAVMissingFolder
+	}
+	return err
+}
+
+// makeFolders creates each level of the destination folder (MKCOL), skipping existing ones
+func (d *webDAVDestination) makeFolders() error {
+	for i := range d.folder {
+		req, err := http.NewRequest("MKCOL", d.base.JoinPath(d.folder[:i+1]...).String(), nil)
+		if err != nil {
+			return err
+		}
+		err = d.do(req)
+		// 405 Method Not Allowed means the folder already exists
+		if httpErr, ok := err.(uploadHTTPError); ok && httpErr.status == http.StatusMethodNotAllowed {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create folder %s: %v", strings.Join(d.folder[:i+1], "/"), err)
+		}
+	}
+	return nil
+}
+
+// do sends a request with the destination's credentials
+func (d *webDAVDestination) do(req *http.Request) error {
+	if d.username != "" {
+		req.SetBasicAuth(d.username, d.password)
+	}
+	return doUpload(req)
+}
+
+// uploadHTTPError is an unsuccessful HTTP response from a destination
+type uploadHTTPError struct {
+	status int
+	body   string
+}
+
+func (e uploadHTTPError) Error() string {
+	if e.body != "" {
+		return fmt.Sprintf("HTTP %d: %s", e.status, e.body)
+	}
+	return fmt.Sprintf("HTTP %d", e.status)
+}
+
+// doUpload sends an upload request and turns non-2xx responses into errors
+func doUpload(req *http.Request) error {
+	client := httpClient(destinationTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return uploadHTTPError{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// contentTypeForTranscript returns the MIME type for a transcript file name
+func contentTypeForTranscript(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return "application/json"
+	case strings.HasSuffix(name, ".vtt"):
+		return "text/vtt; charset=utf-8"
+	case strings.HasSuffix(name, ".srt"):
+		return "application/x-subrip; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// parseHTTPURL parses an http(s) URL
+func parseHTTPURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+	return u, nil
+}
+
+// splitPath splits a slash-separated folder path into its non-empty parts
+func splitPath(path string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// sha256Hex returns the hex SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 computes HMAC-SHA256(key, data)
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
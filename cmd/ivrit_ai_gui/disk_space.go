@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diskSpaceMargin is kept free besides what a download or a conversion needs, so
+// the disk isn't left completely full for the system and other apps
+const diskSpaceMargin = 200 << 20
+
+// wavBytesPerSecond is the size of 16kHz mono 16-bit audio, as whisper takes it
+const wavBytesPerSecond = 16000 * 2
+
+// DiskSpaceError reports that a download or a conversion doesn't fit on the disk,
+// with what to do about it
+type DiskSpaceError struct {
+	What   string // e.g. "the model download"
+	Dir    string
+	Needed uint64
+	Free   uint64
+	Hint   string
+}
+
+func (e *DiskSpaceError) Error() string {
+	return fmt.Sprintf("not enough disk space for %s: it needs %s in %s, which has %s free. %s",
+		e.What, formatSize(e.Needed), e.Dir, formatSize(e.Free), e.Hint)
+}
+
+// CheckDiskSpace returns a DiskSpaceError when dir's disk has less than needed bytes
+// free (plus a margin). Where the free space can't be told, it doesn't stop anything.
+func CheckDiskSpace(dir string, needed int64, what, hint string) error {
+	if needed <= 0 {
+		return nil
+	}
+	// The folder may not exist yet: check the disk of its nearest existing parent
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+	free, err := freeDiskSpace(dir)
+	if err != nil || free >= uint64(needed)+diskSpaceMargin {
+		return nil
+	}
+	return &DiskSpaceError{What: what, Dir: dir, Needed: uint64(needed), Free: free, Hint: hint}
+}
+
+// Hints on making room, by what needs it
+const (
+	downloadSpaceHint = "Free up space on that disk."
+	modelSpaceHint    = "Free up space on that disk, or choose a models folder on a larger one (Models Folder… in the app, or -model-dir)."
+	tempSpaceHint     = "Free up space on that disk, or set TMPDIR (TEMP on Windows) to a folder on a larger one."
+)
+
+// expectedWAVSize returns the size of the 16kHz WAV converted from duration seconds
+// of audio, or of the time range of it when set
+func expectedWAVSize(duration float64, timeRange TimeRange) int64 {
+	if timeRange.IsSet() {
+		if timeRange.End > 0 && timeRange.End < duration {
+			duration = timeRange.End
+		}
+		duration -= timeRange.Start
+	}
+	if duration <= 0 {
+		return 0
+	}
+	return int64(duration*wavBytesPerSecond) + 44 // WAV header
+}
+
+// formatSize returns a size in bytes for display, e.g. "1.5 GB"
+func formatSize(size uint64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.0f MB", float64(size)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", size>>10)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckDiskSpace tests stopping only what doesn't fit on the disk
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckDiskSpace(dir, 1<<20, "a test", "Free up space."); err != nil {
+		t.Errorf("Expected 1 MB to fit, got %v", err)
+	}
+	if err := CheckDiskSpace(filepath.Join(dir, "not", "yet"), 1<<20, "a test", ""); err != nil {
+		t.Errorf("Expected a folder to be created to be checked on its parent's disk, got %v", err)
+	}
+
+	err := CheckDiskSpace(dir, 1<<60, "the model download", "Free up space.")
+	var spaceErr *DiskSpaceError
+	if !errors.As(err, &spaceErr) || spaceErr.Dir != dir {
+		t.Fatalf("Expected a DiskSpaceError for %s, got %v", dir, err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "the model download") || !strings.HasSuffix(msg, "Free up space.") {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+}
+
+// TestExpectedWAVSize tests estimating the converted audio of a recording or a part of it
+func TestExpectedWAVSize(t *testing.T) {
+	tests := []struct {
+		duration  float64
+		timeRange TimeRange
+		expected  int64
+	}{
+		{3600, TimeRange{}, 3600*32000 + 44},
+		{3600, TimeRange{Start: 600}, 3000*32000 + 44},
+		{3600, TimeRange{Start: 600, End: 900}, 300*32000 + 44},
+		{60, TimeRange{Start: 30, End: 900}, 30*32000 + 44},
+		{60, TimeRange{Start: 90}, 0},
+	}
+	for _, tt := range tests {
+		if got := expectedWAVSize(tt.duration, tt.timeRange); got != tt.expected {
+			t.Errorf("expectedWAVSize(%v, %+v) = %d, expected %d", tt.duration, tt.timeRange, got, tt.expected)
+		}
+	}
+	if got := formatSize(3 << 29); got != "1.5 GB" {
+		t.Errorf("formatSize() = %q", got)
+	}
+}
+
+// TestSaveDownloadNoSpace tests that a download too large for the disk stops before
+// writing anything
+func TestSaveDownloadNoSpace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1152921504606846976") // 1 EB
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "ggml-large.bin")
+	defer SetDownloadOptions(DownloadOptions{})
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+	err := downloadModelFromHuggingFace("org/repo", "ggml-large.bin", dest, nil)
+	var spaceErr *DiskSpaceError
+	if !errors.As(err, &spaceErr) {
+		t.Fatalf("Expected a DiskSpaceError, got %v", err)
+	}
+	if _, err := os.Stat(dest + ".download"); err == nil {
+		t.Error("Expected no partial file")
+	}
+}
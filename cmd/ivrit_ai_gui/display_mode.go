@@ -0,0 +1,45 @@
+package main
+
+// Display modes: which texts of a translated transcript are shown and exported.
+// Translated segments always keep both texts (Original and Translation); the mode is
+// only applied when formatting, so it can be changed without translating again.
+const (
+	DisplayBilingual   = "bilingual"   // Hebrew with the translation under it
+	DisplayOriginal    = "original"    // Hebrew only
+	DisplayTranslation = "translation" // The translation only
+)
+
+var validDisplayModes = []string{DisplayBilingual, DisplayOriginal, DisplayTranslation}
+
+// ApplyDisplayMode returns the segments as a display mode shows them: the single-text
+// modes put the chosen text in Text and drop the other one. Untranslated segments,
+// and the segments passed in, are left unchanged.
+func ApplyDisplayMode(segments []Segment, mode string) []Segment {
+	if mode == "" || mode == DisplayBilingual {
+		return segments
+	}
+	shown := make([]Segment, len(segments))
+	for i, seg := range segments {
+		if seg.Original != "" && seg.Translation != "" {
+			if mode == DisplayOriginal {
+				seg.Text = seg.Original
+			} else {
+				seg.Text = seg.Translation
+			}
+			seg.Original, seg.Translation = "", ""
+			if mode == DisplayTranslation {
+				seg.Transliteration = "" // Only shown with the Hebrew
+			}
+		}
+		shown[i] = seg
+	}
+	return shown
+}
+
+// displayModeForKeepOriginal maps the former keepOriginal option to its display mode
+func displayModeForKeepOriginal(keepOriginal bool) string {
+	if keepOriginal {
+		return DisplayBilingual
+	}
+	return DisplayTranslation
+}
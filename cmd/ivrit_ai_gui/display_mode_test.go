@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+// TestApplyDisplayMode tests the texts each display mode shows
+func TestApplyDisplayMode(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "Hello", Original: "שלום", Translation: "Hello"},
+		{Start: 2, End: 4, Text: "עולם"},
+	}
+
+	tests := []struct {
+		mode      string
+		text      string
+		bilingual bool
+	}{
+		{DisplayBilingual, "Hello", true},
+		{"", "Hello", true},
+		{DisplayOriginal, "שלום", false},
+		{DisplayTranslation, "Hello", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			shown := ApplyDisplayMode(segments, tt.mode)
+			if shown[0].Text != tt.text {
+				t.Errorf("Expected text %q, got %q", tt.text, shown[0].Text)
+			}
+			if (shown[0].Original != "") != tt.bilingual {
+				t.Errorf("Expected bilingual = %v, got %+v", tt.bilingual, shown[0])
+			}
+			if shown[1].Text != "עולם" {
+				t.Errorf("Untranslated segments should be unchanged, got %+v", shown[1])
+			}
+		})
+	}
+
+	// The segments keep both texts, so the mode can be changed afterwards
+	if segments[0].Original != "שלום" || segments[0].Translation != "Hello" {
+		t.Errorf("ApplyDisplayMode() modified its input: %+v", segments[0])
+	}
+}
+
+// TestFormatOutputDisplayMode tests that formatted output follows the display mode
+func TestFormatOutputDisplayMode(t *testing.T) {
+	segments := []Segment{{Start: 0, End: 2, Text: "Hello", Original: "שלום", Translation: "Hello"}}
+
+	if output := FormatOutput(segments, "srt", DisplayBilingual); !strings.Contains(output, "שלום") || !strings.Contains(output, "Hello") {
+		t.Errorf("Expected both texts, got %q", output)
+	}
+	if output := FormatOutput(segments, "srt", DisplayOriginal); !strings.Contains(output, "שלום") || strings.Contains(output, "Hello") {
+		t.Errorf("Expected only the Hebrew, got %q", output)
+	}
+	if output := FormatOutput(segments, "srt", DisplayTranslation); strings.Contains(output, "שלום") || !strings.Contains(output, "Hello") {
+		t.Errorf("Expected only the translation, got %q", output)
+	}
+}
+
+// TestDisplayModeKeepOriginal tests that the former keep-original settings still work
+func TestDisplayModeKeepOriginal(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.parseConfigFile([]byte(`{"keepOriginal": false}`)); err != nil {
+		t.Fatalf("parseConfigFile() error: %v", err)
+	}
+	if cfg.DisplayMode != DisplayTranslation {
+		t.Errorf("keepOriginal false in config.json = %q, expected translation", cfg.DisplayMode)
+	}
+
+	cfg = DefaultConfig()
+	if err := cfg.parseConfigFile([]byte(`{"keepOriginal": false, "displayMode": "original"}`)); err != nil {
+		t.Fatalf("parseConfigFile() error: %v", err)
+	}
+	if cfg.DisplayMode != DisplayOriginal {
+		t.Errorf("displayMode should win over keepOriginal, got %q", cfg.DisplayMode)
+	}
+
+	cfg = DefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-keep-original=false"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if cfg.DisplayMode != DisplayTranslation {
+		t.Errorf("-keep-original=false = %q, expected translation", cfg.DisplayMode)
+	}
+
+	cfg.DisplayMode = "hebrew"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for an unknown display mode")
+	}
+}
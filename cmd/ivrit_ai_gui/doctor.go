@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Results of a setup check
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn" // Works, with a feature missing or a step still to come
+	doctorFail = "fail" // Transcription won't work until it is fixed
+)
+
+// DoctorCheck is one item the doctor command checks, with how to fix it
+type DoctorCheck struct {
+	Name   string
+	Status string // doctorOK, doctorWarn or doctorFail
+	Detail string
+}
+
+// RunDoctor checks what transcription depends on: the configuration, ffmpeg, the
+// whisper.cpp library, the model folder and model, and ollama for translation
+func RunDoctor(cfg AppConfig) []DoctorCheck {
+	checks := []DoctorCheck{doctorConfigCheck(cfg)}
+
+	SetFFmpegPaths(cfg.FFmpegPath, cfg.FFprobePath)
+	if err := CheckFFmpeg(); err != nil {
+		checks = append(checks, DoctorCheck{"ffmpeg", doctorFail, err.Error()})
+	} else {
+		checks = append(checks, DoctorCheck{"ffmpeg", doctorOK, ffmpegPath() + ", " + ffprobePath()})
+	}
+
+	if cfg.IsRemoteEngine() {
+		checks = append(checks, DoctorCheck{"Engine", doctorOK, fmt.Sprintf("%s at %s (models aren't needed locally)", cfg.Engine, cfg.EngineAddress())})
+	} else {
+		caps := WhisperCaps()
+		if missing := caps.Missing(); len(missing) > 0 {
+			checks = append(checks, DoctorCheck{"whisper.cpp", doctorWarn, fmt.Sprintf("%s doesn't support %s; update the library to use them", caps, strings.Join(missing, ", "))})
+		} else {
+			checks = append(checks, DoctorCheck{"whisper.cpp", doctorOK, caps.String()})
+		}
+		checks = append(checks, doctorModelDirCheck(ModelDir()), doctorModelCheck(cfg.Model))
+	}
+
+	translator := NewMistralTranslator()
+	status := translator.Status()
+	check := DoctorCheck{"Translation (ollama)", doctorOK, ollamaInstallHint(status, translator.model)}
+	if !status.Running || !status.HasModel {
+		check.Status = doctorWarn
+	}
+	return append(checks, check)
+}
+
+// doctorConfigCheck validates the options from the config file, environment and flags
+func doctorConfigCheck(cfg AppConfig) DoctorCheck {
+	if err := cfg.Validate(); err != nil {
+		return DoctorCheck{"Configuration", doctorFail, fmt.Sprintf("%v (check %s and the IVRIT_* environment variables)", err, configPath())}
+	}
+	if _, err := os.Stat(configPath()); err != nil {
+		return DoctorCheck{"Configuration", doctorOK, "defaults (no " + configPath() + ")"}
+	}
+	return DoctorCheck{"Configuration", doctorOK, configPath()}
+}
+
+// doctorModelDirCheck checks that models can be downloaded to dir
+func doctorModelDirCheck(dir string) DoctorCheck {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return DoctorCheck{"Model folder", doctorOK, dir + " (created on the first download)"}
+	}
+	if err != nil || !info.IsDir() {
+		return DoctorCheck{"Model folder", doctorFail, fmt.Sprintf("%s isn't a usable folder; choose another with -model-dir", dir)}
+	}
+	probe, err := os.CreateTemp(dir, ".ivrit-ai-doctor-*")
+	if err != nil {
+		return DoctorCheck{"Model folder", doctorFail, fmt.Sprintf("%s isn't writable (%v); choose another with -model-dir", dir, err)}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return DoctorCheck{"Model folder", doctorOK, dir}
+}
+
+// doctorModelCheck checks that the model is downloaded, naming the others that are
+func doctorModelCheck(modelID string) DoctorCheck {
+	var downloaded []string
+	for _, id := range ModelIDs() {
+		if _, err := FindLocalModel(id); err == nil && id != modelID {
+			downloaded = append(downloaded, id)
+		}
+	}
+	others := ""
+	if len(downloaded) > 0 {
+		others = "; also downloaded: " + strings.Join(downloaded, ", ")
+	}
+
+	path, err := FindLocalModel(modelID)
+	if err != nil {
+		return DoctorCheck{"Model " + modelID, doctorWarn, "not downloaded yet: it is downloaded on first use" + others}
+	}
+	if err := ValidateModelFile(path); err != nil {
+		return DoctorCheck{"Model " + modelID, doctorFail, fmt.Sprintf("%v; download it again with: models redownload %s", err, modelID)}
+	}
+	return DoctorCheck{"Model " + modelID, doctorOK, path + others}
+}
+
+// PrintDoctorReport lists the checks, returning whether none failed
+func PrintDoctorReport(w io.Writer, checks []DoctorCheck) bool {
+	failed, warned := 0, 0
+	for _, check := range checks {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", check.Status, check.Name, strings.ReplaceAll(check.Detail, "\n", "\n       "))
+		switch check.Status {
+		case doctorFail:
+			failed++
+		case doctorWarn:
+			warned++
+		}
+	}
+	switch {
+	case failed > 0:
+		fmt.Fprintf(w, "\n%d of %d checks failed: fix them before transcribing\n", failed, len(checks))
+	case warned > 0:
+		fmt.Fprintln(w, "\nReady to transcribe; the warnings above are about optional features")
+	default:
+		fmt.Fprintln(w, "\nEverything is ready")
+	}
+	return failed == 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDoctorModelDirCheck tests checking the folder models are downloaded to
+func TestDoctorModelDirCheck(t *testing.T) {
+	dir := t.TempDir()
+	if check := doctorModelDirCheck(dir); check.Status != doctorOK {
+		t.Errorf("Expected a writable folder to pass, got %+v", check)
+	}
+	if check := doctorModelDirCheck(filepath.Join(dir, "new")); check.Status != doctorOK || !strings.Contains(check.Detail, "created") {
+		t.Errorf("Expected a missing folder to be created later, got %+v", check)
+	}
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, nil, 0644)
+	if check := doctorModelDirCheck(file); check.Status != doctorFail {
+		t.Errorf("Expected a file to fail, got %+v", check)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected the check to leave nothing behind, got %d entries", len(entries))
+	}
+}
+
+// TestPrintDoctorReport tests the report and its verdict
+func TestPrintDoctorReport(t *testing.T) {
+	var out bytes.Buffer
+	ok := PrintDoctorReport(&out, []DoctorCheck{
+		{"ffmpeg", doctorOK, "/usr/bin/ffmpeg"},
+		{"Translation (ollama)", doctorWarn, "Ollama isn't running."},
+	})
+	if !ok || !strings.Contains(out.String(), "[ok  ] ffmpeg: /usr/bin/ffmpeg") || !strings.Contains(out.String(), "optional features") {
+		t.Errorf("Unexpected report:\n%s", out.String())
+	}
+
+	out.Reset()
+	ok = PrintDoctorReport(&out, []DoctorCheck{{"ffmpeg", doctorFail, "ffmpeg not found\nInstall it"}})
+	if ok || !strings.Contains(out.String(), "not found\n       Install it") || !strings.Contains(out.String(), "1 of 1 checks failed") {
+		t.Errorf("Unexpected report:\n%s", out.String())
+	}
+}
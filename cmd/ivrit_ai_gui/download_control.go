@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseRateLimit parses a download rate limit in bytes per second, with an optional
+// K, M or G suffix as curl's --limit-rate takes, e.g. "500K" or "2M" (also "2MB/s").
+// An empty limit or 0 means no limit.
+func ParseRateLimit(s string) (int64, error) {
+	value := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "/S"), "B")
+	if value == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	switch value[len(value)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("invalid download rate limit %q: use bytes per second, e.g. 500K or 2M", s)
+	}
+	return int64(rate * float64(multiplier)), nil
+}
+
+// FormatRate returns a rate in bytes per second for display, e.g. "2.0 MB/s"
+func FormatRate(rate int64) string {
+	switch {
+	case rate >= 1<<20:
+		return fmt.Sprintf("%.1f MB/s", float64(rate)/(1<<20))
+	case rate >= 1<<10:
+		return fmt.Sprintf("%.0f KB/s", float64(rate)/(1<<10))
+	}
+	return fmt.Sprintf("%d B/s", rate)
+}
+
+// Downloads in progress are paused and resumed together, from the GUI
+var downloads = struct {
+	sync.Mutex
+	active  int
+	paused  bool
+	resumed chan struct{} // Closed on resume
+}{resumed: make(chan struct{})}
+
+// PauseDownloads holds the downloads in progress, and any started, until
+// ResumeDownloads
+func PauseDownloads() {
+	downloads.Lock()
+	defer downloads.Unlock()
+	if !downloads.paused {
+		downloads.paused = true
+		downloads.resumed = make(chan struct{})
+	}
+}
+
+// ResumeDownloads continues the paused downloads
+func ResumeDownloads() {
+	downloads.Lock()
+	defer downloads.Unlock()
+	if downloads.paused {
+		downloads.paused = false
+		close(downloads.resumed)
+	}
+}
+
+// DownloadsPaused reports whether downloads are paused
+func DownloadsPaused() bool {
+	downloads.Lock()
+	defer downloads.Unlock()
+	return downloads.paused
+}
+
+// DownloadInProgress reports whether a model or update is being downloaded
+func DownloadInProgress() bool {
+	downloads.Lock()
+	defer downloads.Unlock()
+	return downloads.active > 0
+}
+
+// downloadThrottle paces one download to the rate limit and holds it while
+// downloads are paused
+type downloadThrottle struct {
+	rate  int64 // Bytes per second (0 = no limit)
+	start time.Time
+	sent  int64 // Bytes since start
+}
+
+// newDownloadThrottle starts pacing a download, counted as in progress until done
+func newDownloadThrottle(rate int64) *downloadThrottle {
+	downloads.Lock()
+	downloads.active++
+	downloads.Unlock()
+	return &downloadThrottle{rate: rate, start: time.Now()}
+}
+
+// done counts the download as finished. Once none is left, a pause ends, so the
+// next download doesn't start paused.
+func (t *downloadThrottle) done() {
+	downloads.Lock()
+	defer downloads.Unlock()
+	downloads.active--
+	if downloads.active == 0 && downloads.paused {
+		downloads.paused = false
+		close(downloads.resumed)
+	}
+}
+
+// wait blocks while downloads are paused, reporting whether it did, so the
+// connection can be checked
+func (t *downloadThrottle) wait() bool {
+	downloads.Lock()
+	paused, resumed := downloads.paused, downloads.resumed
+	downloads.Unlock()
+	if !paused {
+		return false
+	}
+	<-resumed
+	t.start, t.sent = time.Now(), 0 // No burst to catch up on the pause
+	return true
+}
+
+// received sleeps as long as n more bytes take at the rate limit
+func (t *downloadThrottle) received(n int) {
+	if t.rate <= 0 {
+		return
+	}
+	t.sent += int64(n)
+	due := time.Duration(float64(t.sent) / float64(t.rate) * float64(time.Second))
+	if ahead := due - time.Since(t.start); ahead > 0 {
+		time.Sleep(ahead)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestParseRateLimit tests reading rate limits as curl's --limit-rate takes them
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		limit    string
+		expected int64
+		ok       bool
+	}{
+		{"", 0, true},
+		{"0", 0, true},
+		{"50000", 50000, true},
+		{"500K", 500 << 10, true},
+		{"2M", 2 << 20, true},
+		{"1.5m", 3 << 19, true},
+		{"2MB/s", 2 << 20, true},
+		{"1G", 1 << 30, true},
+		{"fast", 0, false},
+		{"-2M", 0, false},
+	}
+	for _, tt := range tests {
+		rate, err := ParseRateLimit(tt.limit)
+		if rate != tt.expected || (err == nil) != tt.ok {
+			t.Errorf("ParseRateLimit(%q) = %d, %v, expected %d", tt.limit, rate, err, tt.expected)
+		}
+	}
+	if got := FormatRate(2 << 20); got != "2.0 MB/s" {
+		t.Errorf("FormatRate() = %q", got)
+	}
+}
+
+// TestDownloadThrottle tests pacing a download to the rate limit
+func TestDownloadThrottle(t *testing.T) {
+	throttle := newDownloadThrottle(100 << 10)
+	defer throttle.done()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		throttle.received(5 << 10)
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond || elapsed > time.Second {
+		t.Errorf("20KB at 100KB/s took %v, want about 200ms", elapsed)
+	}
+}
+
+// TestPausedDownload tests holding a download while paused, and continuing it from
+// where it stopped when the server closed the connection meanwhile
+func TestPausedDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("weights "), 16<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			http.ServeContent(w, r, "model.bin", time.Time{}, bytes.NewReader(content))
+			return
+		}
+		// Half of it, then the connection drops
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+	defer SetDownloadOptions(DownloadOptions{})
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+
+	PauseDownloads()
+	dest := filepath.Join(t.TempDir(), "model.bin")
+	done := make(chan error)
+	go func() { done <- downloadModelFromHuggingFace("org/model", "model.bin", dest, nil) }()
+
+	time.Sleep(100 * time.Millisecond)
+	if !DownloadInProgress() || !DownloadsPaused() {
+		t.Fatal("Expected a paused download in progress")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Download finished while paused: %v", err)
+	default:
+	}
+
+	ResumeDownloads()
+	if err := <-done; err != nil {
+		t.Fatalf("Download error after resuming: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); !bytes.Equal(data, content) {
+		t.Errorf("Downloaded %d bytes, want %d", len(data), len(content))
+	}
+	if DownloadInProgress() || DownloadsPaused() {
+		t.Error("Expected no download in progress or paused once done")
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// EBU-STL (EBU Tech 3264) files are a 1024-byte General Subtitle Information (GSI)
+// block followed by 128-byte Text and Timing Information (TTI) blocks, each holding
+// up to 112 bytes of a subtitle's text
+const (
+	stlGSISize     = 1024
+	stlTTISize     = 128
+	stlTextSize    = 112
+	stlFrameRate   = 25   // STL25.01, the PAL frame rate of European broadcasters
+	stlLineBreak   = 0x8A // CR/LF between the rows of a subtitle
+	stlUnusedSpace = 0x8F // Fills the rest of a text field
+	stlRows        = 23   // Teletext rows
+	stlMinRowChars = 40   // Teletext row width, the least declared as the longest row
+)
+
+// stlCharset is a character code table of EBU-STL with the language codes of its
+// script: Hebrew text is written in the Latin/Hebrew table (ISO 8859-8)
+type stlCharset struct {
+	table    string // Character code table (CCT)
+	language string // Language code (LC) of text in its script, "00" = unknown
+	encode   func(r rune) (byte, bool)
+}
+
+var (
+	stlLatin    = stlCharset{"00", "00", nil} // ISO 6937, see encodeISO6937
+	stlCyrillic = stlCharset{"01", "56", charmap.ISO8859_5.EncodeRune}
+	stlArabic   = stlCharset{"02", "7E", charmap.ISO8859_6.EncodeRune}
+	stlHebrew   = stlCharset{"04", "6C", charmap.ISO8859_8.EncodeRune}
+)
+
+// stlCharsetFor returns the character table for the text of the segments: that of
+// their right-to-left or Cyrillic script, or Latin. Letters of other scripts in
+// them (e.g. Amharic) can't be written and become "?".
+func stlCharsetFor(segments []Segment) stlCharset {
+	for _, seg := range segments {
+		for _, text := range []string{seg.Text, seg.Original} {
+			switch {
+			case strings.ContainsFunc(text, isHebrewLetter):
+				return stlHebrew
+			case strings.ContainsFunc(text, func(r rune) bool { return unicode.Is(unicode.Arabic, r) }):
+				return stlArabic
+			case strings.ContainsFunc(text, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }):
+				return stlCyrillic
+			}
+		}
+	}
+	return stlLatin
+}
+
+// FormatSTL writes segments as an EBU-STL file for teletext subtitling, as a string
+// of its binary data. Hebrew is stored in logical order, as typed; rows are centred.
+func FormatSTL(segments []Segment) string {
+	charset := stlCharsetFor(segments)
+	var blocks []byte
+	subtitles, blockCount, longestRow := 0, 0, 0
+	lastSpeaker := -1
+	for _, seg := range segments {
+		speakerLabel := ""
+		if seg.Speaker != lastSpeaker && seg.Event == "" {
+			speakerLabel = speakerLinePrefix(seg, "stl")
+			lastSpeaker = seg.Speaker
+		}
+		rows := []string{speakerLabel + strings.TrimSpace(seg.Text)}
+		if seg.Original != "" && seg.Translation != "" {
+			rows = []string{speakerLabel + strings.TrimSpace(seg.Original), strings.TrimSpace(seg.Translation)}
+		}
+		if seg.Transliteration != "" {
+			rows = append(rows[:1], append([]string{seg.Transliteration}, rows[1:]...)...)
+		}
+
+		var text []byte
+		for i, row := range rows {
+			encoded := charset.encodeText(row)
+			longestRow = max(longestRow, len(encoded))
+			if i > 0 {
+				text = append(text, stlLineBreak)
+			}
+			text = append(text, encoded...)
+		}
+		verticalPosition := stlRows - 1 - 2*(len(rows)-1) // Bottom rows, double-height spacing
+
+		// Text beyond one block continues in extension blocks of the same subtitle
+		for start := 0; start == 0 || start < len(text); start += stlTextSize {
+			extension := byte(0xFF) // The subtitle's last block
+			if start+stlTextSize < len(text) {
+				extension = byte(start / stlTextSize)
+			}
+			block := make([]byte, stlTTISize)
+			block[0] = 0 // Subtitle group
+			binary.LittleEndian.PutUint16(block[1:3], uint16(subtitles))
+			block[3] = extension
+			block[4] = 0 // Not cumulative
+			copy(block[5:9], stlTimecode(seg.Start))
+			copy(block[9:13], stlTimecode(seg.End))
+			block[13] = byte(verticalPosition)
+			block[14] = 2 // Centred
+			block[15] = 0 // Subtitle data, not a comment
+			field := block[16:]
+			for i := range field {
+				field[i] = stlUnusedSpace
+			}
+			copy(field, text[start:min(len(text), start+stlTextSize)])
+			blocks = append(blocks, block...)
+			blockCount++
+		}
+		subtitles++
+	}
+
+	firstCue := "00000000"
+	if len(segments) > 0 {
+		firstCue = stlTimecodeText(segments[0].Start)
+	}
+	return string(stlGSI(charset, blockCount, subtitles, max(longestRow, stlMinRowChars), firstCue)) + string(blocks)
+}
+
+// stlGSI returns the General Subtitle Information block of an STL file
+func stlGSI(charset stlCharset, blocks, subtitles, rowChars int, firstCue string) []byte {
+	gsi := []byte(strings.Repeat(" ", stlGSISize))
+	today := time.Now().Format("060102")
+	for _, field := range []struct {
+		offset int
+		value  string
+	}{
+		{0, "850"}, // Code page of the GSI block
+		{3, fmt.Sprintf("STL%d.01", stlFrameRate)}, // Disk format
+		{11, "1"},                             // Display standard: teletext level 1
+		{12, charset.table},                   // Character code table of the subtitles
+		{14, charset.language},                // Language code
+		{224, today},                          // Creation date
+		{230, today},                          // Revision date
+		{236, "00"},                           // Revision number
+		{238, fmt.Sprintf("%05d", blocks)},    // Total number of TTI blocks
+		{243, fmt.Sprintf("%05d", subtitles)}, // Total number of subtitles
+		{248, "001"},                          // Total number of subtitle groups
+		{251, fmt.Sprintf("%02d", min(rowChars, 99))}, // Maximum number of displayable characters in a row
+		{253, fmt.Sprintf("%02d", stlRows)},           // Maximum number of displayable rows
+		{255, "1"},                                    // Time code status: intended for use
+		{256, "00000000"},                             // Time code: start of programme
+		{264, firstCue},                               // Time code: first in-cue
+		{272, "1"},                                    // Total number of disks
+		{273, "1"},                                    // Disk sequence number
+	} {
+		copy(gsi[field.offset:], field.value)
+	}
+	return gsi
+}
+
+// stlTimecode returns a time as the hours, minutes, seconds and frames bytes of a TTI block
+func stlTimecode(seconds float64) []byte {
+	frames := int(math.Round(seconds * stlFrameRate))
+	return []byte{
+		byte(frames / (3600 * stlFrameRate)),
+		byte(frames / (60 * stlFrameRate) % 60),
+		byte(frames / stlFrameRate % 60),
+		byte(frames % stlFrameRate),
+	}
+}
+
+// stlTimecodeText returns a time as the HHMMSSFF time code of the GSI block
+func stlTimecodeText(seconds float64) string {
+	tc := stlTimecode(seconds)
+	return fmt.Sprintf("%02d%02d%02d%02d", tc[0], tc[1], tc[2], tc[3])
+}
+
+// encodeText returns a row of text in the character table. Direction marks and
+// Hebrew points, which teletext can't show, are left out; characters missing from
+// the table become "?".
+func (c stlCharset) encodeText(text string) []byte {
+	if c.encode == nil {
+		return encodeISO6937(text)
+	}
+	var encoded []byte
+	for _, r := range text {
+		if isBidiControl(r) || unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if b, ok := c.encode(r); ok {
+			encoded = append(encoded, b)
+		} else {
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// iso6937Accents maps combining accents to the ISO 6937 bytes written before the letter
+var iso6937Accents = map[rune]byte{
+	'\u0300': 0xC1, '\u0301': 0xC2, '\u0302': 0xC3, '\u0303': 0xC4, '\u0304': 0xC5, // Grave, acute, circumflex, tilde, macron
+	'\u0306': 0xC6, '\u0307': 0xC7, '\u0308': 0xC8, '\u030A': 0xCA, '\u0327': 0xCB, // Breve, dot, diaeresis, ring, cedilla
+	'\u030B': 0xCD, '\u0328': 0xCE, '\u030C': 0xCF, // Double acute, ogonek, caron
+}
+
+// iso6937Letters maps the letters and signs of Latin languages ISO 6937 has as such
+var iso6937Letters = map[rune]byte{
+	'¡': 0xA1, '¢': 0xA2, '£': 0xA3, '§': 0xA7, '«': 0xAB, '°': 0xB0, '»': 0xBB, '¿': 0xBF,
+	'Æ': 0xE1, 'Ø': 0xE9, 'Œ': 0xEA, 'æ': 0xF1, 'ø': 0xF9, 'œ': 0xFA, 'ß': 0xFB,
+	'‘': '\'', '’': '\'', '“': '"', '”': '"', '–': '-', '—': '-',
+}
+
+// encodeISO6937 returns text in the Latin table of EBU-STL (ISO 6937), where an
+// accented letter is its accent followed by the letter
+func encodeISO6937(text string) []byte {
+	var encoded []byte
+	for _, r := range norm.NFD.String(text) {
+		if accent := iso6937Accents[r]; accent != 0 {
+			// Decomposed after its letter, but written before it
+			if n := len(encoded); n > 0 {
+				encoded = append(encoded[:n-1], accent, encoded[n-1])
+			}
+			continue
+		}
+		if b, ok := iso6937Letters[r]; ok {
+			encoded = append(encoded, b)
+		} else if r >= 0x20 && r < 0x7F {
+			encoded = append(encoded, byte(r))
+		} else if !isBidiControl(r) && !unicode.Is(unicode.Mn, r) {
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// isBidiControl reports whether r is an invisible direction mark, embedding or isolate
+func isBidiControl(r rune) bool {
+	return r == '\u200E' || r == '\u200F' || (r >= '\u202A' && r <= '\u202E') || (r >= '\u2066' && r <= '\u2069')
+}
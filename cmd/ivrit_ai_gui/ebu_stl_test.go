@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestFormatSTL tests the GSI and TTI blocks of EBU-STL files with Hebrew text
+func TestFormatSTL(t *testing.T) {
+	segments := []Segment{
+		{Start: 1.48, End: 3.04, Text: "שלום"},
+		{Start: 3601, End: 3602, Text: "Hello", Original: "שלום", Translation: "Hello"},
+		{Start: 3602, End: 3610, Text: strings.Repeat("א", 150)},
+	}
+	data := []byte(FormatOutput(segments, "stl", DisplayBilingual))
+	if len(data) != stlGSISize+4*stlTTISize {
+		t.Fatalf("Expected a GSI block and 4 TTI blocks, got %d bytes", len(data))
+	}
+	gsi := string(data[:stlGSISize])
+	for offset, want := range map[int]string{0: "850STL25.011", 12: "046C", 238: "0000400003001", 251: "99231", 264: "00000112"} {
+		if got := gsi[offset : offset+len(want)]; got != want {
+			t.Errorf("GSI at %d: expected %q, got %q", offset, want, got)
+		}
+	}
+
+	// Hebrew in ISO 8859-8, after a speaker label
+	first := data[stlGSISize : stlGSISize+stlTTISize]
+	if !bytes.Equal(first[5:13], []byte{0, 0, 1, 12, 0, 0, 3, 1}) || first[3] != 0xFF {
+		t.Errorf("Unexpected first block timing: % x", first[:16])
+	}
+	if text := first[16:]; !bytes.HasPrefix(text, []byte("[Speaker 1] \xF9\xEC\xE5\xED\x8F")) {
+		t.Errorf("Expected the Hebrew in ISO 8859-8: % x", text[:20])
+	}
+
+	// The translation on its own row
+	second := data[stlGSISize+stlTTISize : stlGSISize+2*stlTTISize]
+	if !bytes.HasPrefix(second[16:], []byte("\xF9\xEC\xE5\xED\x8AHello\x8F")) || second[5] != 1 || second[13] != 20 {
+		t.Errorf("Unexpected bilingual block: % x", second[:32])
+	}
+
+	// Long text continues in an extension block of the same subtitle
+	third, fourth := data[stlGSISize+2*stlTTISize:], data[stlGSISize+3*stlTTISize:]
+	if third[1] != 2 || fourth[1] != 2 || third[3] != 0 || fourth[3] != 0xFF {
+		t.Errorf("Expected subtitle 2 in blocks 0 and 0xFF, got %d/%x and %d/%x", third[1], third[3], fourth[1], fourth[3])
+	}
+}
+
+// TestEncodeISO6937 tests the Latin character table of EBU-STL
+func TestEncodeISO6937(t *testing.T) {
+	if got := encodeISO6937("Café ¿señor? ß"); !bytes.Equal(got, []byte("Caf\xC2e \xBFse\xC4nor? \xFB")) {
+		t.Errorf("Unexpected encoding: % x", got)
+	}
+	if got := stlCharsetFor([]Segment{{Text: "Hello"}}); got.table != "00" {
+		t.Errorf("Expected the Latin table for English, got %s", got.table)
+	}
+	if got := stlCharsetFor([]Segment{{Text: "Привет"}}); got.table != "01" || got.language != "56" {
+		t.Errorf("Expected the Cyrillic table for Russian, got %+v", got)
+	}
+}
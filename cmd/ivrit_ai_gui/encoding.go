@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// Character encodings and line endings of saved transcripts. Some older Windows
+// subtitle tools and car media players only show Hebrew correctly from files with
+// a byte order mark (BOM) or in UTF-16, or need Windows (CRLF) line endings.
+const (
+	EncodingUTF8    = "utf-8"     // No BOM (the default)
+	EncodingUTF8BOM = "utf-8-bom" // UTF-8 starting with a BOM
+	EncodingUTF16LE = "utf-16le"  // UTF-16 little-endian with a BOM, as Windows Notepad's "Unicode"
+
+	LineEndingsLF   = "lf"   // Unix and macOS (the default)
+	LineEndingsCRLF = "crlf" // Windows
+)
+
+var (
+	validEncodings   = []string{EncodingUTF8, EncodingUTF8BOM, EncodingUTF16LE}
+	validLineEndings = []string{LineEndingsLF, LineEndingsCRLF}
+)
+
+// OutputEncoding selects how text, SRT and VTT transcripts are written. JSON, HTML
+// and Markdown are always UTF-8 with LF line endings, as their readers expect.
+type OutputEncoding struct {
+	Charset     string `json:"charset,omitempty"`     // EncodingUTF8, EncodingUTF8BOM or EncodingUTF16LE ("" = UTF-8)
+	LineEndings string `json:"lineEndings,omitempty"` // LineEndingsLF or LineEndingsCRLF ("" = LF)
+}
+
+// encodesFormat reports whether the encoding options apply to an output format
+func encodesFormat(format string) bool {
+	return format == "text" || format == "srt" || format == "vtt"
+}
+
+// Encode returns the bytes of a transcript in the given format to write to a file
+func (e OutputEncoding) Encode(text, format string) []byte {
+	if !encodesFormat(format) {
+		return []byte(text)
+	}
+	if e.LineEndings == LineEndingsCRLF {
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	}
+
+	switch e.Charset {
+	case EncodingUTF8BOM:
+		return append([]byte("\uFEFF"), text...)
+	case EncodingUTF16LE:
+		units := utf16.Encode([]rune("\uFEFF" + text))
+		data := make([]byte, 2*len(units))
+		for i, unit := range units {
+			binary.LittleEndian.PutUint16(data[2*i:], unit)
+		}
+		return data
+	}
+	return []byte(text)
+}
+
+// OutputData returns the bytes of a transcript in the given format to save or
+// upload, with the right-to-left fixes, cue settings and encoding of the
+// configuration applied
+func (c AppConfig) OutputData(text, format string) []byte {
+	return c.Encoding.Encode(c.Cues.Apply(c.RTL.Apply(text, format), format), format)
+}
+
+// decodeTranscriptText returns the text of a saved transcript in any of the output
+// encodings (the BOM and CR of CRLF line endings are left for the caller to drop)
+func decodeTranscriptText(data []byte) string {
+	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[2*i:])
+		}
+		return string(utf16.Decode(units))
+	}
+	return string(data)
+}
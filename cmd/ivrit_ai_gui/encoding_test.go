@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestOutputEncodingEncode tests the BOMs, UTF-16 and line endings of encoded transcripts
+func TestOutputEncodingEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding OutputEncoding
+		format   string
+		text     string
+		expected []byte
+	}{
+		{"Default", OutputEncoding{}, "srt", "שלום\n", []byte("שלום\n")},
+		{"UTF-8 BOM", OutputEncoding{Charset: EncodingUTF8BOM}, "vtt", "שלום\n", []byte("\xEF\xBB\xBFשלום\n")},
+		{"CRLF", OutputEncoding{LineEndings: LineEndingsCRLF}, "text", "שלום\n", []byte("שלום\r\n")},
+		{"UTF-16LE with CRLF", OutputEncoding{EncodingUTF16LE, LineEndingsCRLF}, "srt", "ש\n", []byte{0xFF, 0xFE, 0xE9, 0x05, '\r', 0, '\n', 0}},
+		{"JSON stays UTF-8", OutputEncoding{EncodingUTF16LE, LineEndingsCRLF}, "json", "שלום\n", []byte("שלום\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.encoding.Encode(tt.text, tt.format); !bytes.Equal(got, tt.expected) {
+				t.Errorf("Encode() = % x, expected % x", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseEncodedTranscript tests that transcripts saved in every encoding are read back
+func TestParseEncodedTranscript(t *testing.T) {
+	srt := FormatOutput([]Segment{{Start: 0, End: 1.5, Text: "שלום עולם"}, {Start: 1.5, End: 3, Text: "מה שלומך?"}}, "srt", DisplayBilingual)
+	for _, charset := range validEncodings {
+		t.Run(charset, func(t *testing.T) {
+			data := OutputEncoding{charset, LineEndingsCRLF}.Encode(srt, "srt")
+			segments, _, err := ParseTranscript(data, "srt")
+			if err != nil {
+				t.Fatalf("ParseTranscript() error: %v", err)
+			}
+			if len(segments) != 2 || segments[0].Text != "שלום עולם" || segments[1].End != 3 {
+				t.Errorf("Unexpected segments: %+v", segments)
+			}
+		})
+	}
+}
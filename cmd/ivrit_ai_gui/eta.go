@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// realtimeFactorWeight is how much the latest run moves the stored realtime factor
+// (exponential moving average, so one unusual run doesn't skew estimates)
+const realtimeFactorWeight = 0.3
+
+// UpdateRealtimeFactor records how long a transcription took relative to the audio
+// duration for a model. The factor is processing seconds per second of audio.
+func UpdateRealtimeFactor(history map[string]float64, modelID string, elapsed time.Duration, audioDuration float64) {
+	if audioDuration <= 0 || elapsed <= 0 {
+		return
+	}
+
+	factor := elapsed.Seconds() / audioDuration
+	if previous, ok := history[modelID]; ok && previous > 0 {
+		factor = previous*(1-realtimeFactorWeight) + factor*realtimeFactorWeight
+	}
+	history[modelID] = factor
+}
+
+// EstimateRemaining estimates the remaining transcription time. Before any progress is
+// reported it relies on the model's historical realtime factor; as progress comes in the
+// progress-based estimate takes over. ok is false when there is nothing to go on.
+func EstimateRemaining(realtimeFactor float64, audioDuration float64, elapsed time.Duration, percent int) (remaining time.Duration, ok bool) {
+	historyTotal := 0.0
+	if realtimeFactor > 0 && audioDuration > 0 {
+		historyTotal = realtimeFactor * audioDuration
+	}
+
+	var total float64
+	switch {
+	case percent > 0 && percent <= 100 && historyTotal > 0:
+		// Blend: early percentages are noisy, so trust history until progress accumulates
+		progressTotal := elapsed.Seconds() / (float64(percent) / 100.0)
+		weight := float64(percent) / 100.0
+		total = weight*progressTotal + (1-weight)*historyTotal
+	case percent > 0 && percent <= 100:
+		total = elapsed.Seconds() / (float64(percent) / 100.0)
+	case historyTotal > 0:
+		total = historyTotal
+	default:
+		return 0, false
+	}
+
+	remaining = time.Duration(total*float64(time.Second)) - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// FormatETA formats a remaining duration as "2m 30s" or "45s"
+func FormatETA(remaining time.Duration) string {
+	mins := int(remaining.Minutes())
+	secs := int(remaining.Seconds()) % 60
+	if mins > 0 {
+		return fmt.Sprintf("%dm %ds", mins, secs)
+	}
+	return fmt.Sprintf("%ds", secs)
+}
+
+// effectiveAudioDuration returns how much audio a time range actually covers
+func effectiveAudioDuration(duration float64, timeRange TimeRange) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	end := duration
+	if timeRange.End > 0 && timeRange.End < duration {
+		end = timeRange.End
+	}
+	if timeRange.Start >= end {
+		return 0
+	}
+	return end - timeRange.Start
+}
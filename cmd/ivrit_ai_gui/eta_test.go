@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestUpdateRealtimeFactor tests the moving average of realtime factors
+func TestUpdateRealtimeFactor(t *testing.T) {
+	history := map[string]float64{}
+
+	UpdateRealtimeFactor(history, "turbo", 30*time.Second, 60)
+	if history["turbo"] != 0.5 {
+		t.Errorf("First run should set factor to 0.5, got %v", history["turbo"])
+	}
+
+	UpdateRealtimeFactor(history, "turbo", 60*time.Second, 60)
+	expected := 0.5*(1-realtimeFactorWeight) + 1.0*realtimeFactorWeight
+	if math.Abs(history["turbo"]-expected) > 1e-9 {
+		t.Errorf("Second run should average to %v, got %v", expected, history["turbo"])
+	}
+
+	// Unknown duration is ignored
+	UpdateRealtimeFactor(history, "base", 10*time.Second, 0)
+	if _, ok := history["base"]; ok {
+		t.Error("Zero audio duration should not record a factor")
+	}
+}
+
+// TestEstimateRemaining tests history-based and progress-based ETAs
+func TestEstimateRemaining(t *testing.T) {
+	tests := []struct {
+		name     string
+		factor   float64
+		duration float64
+		elapsed  time.Duration
+		percent  int
+		expected time.Duration
+		expectOK bool
+	}{
+		{"No history, no progress", 0, 600, 0, 0, 0, false},
+		{"History only at start", 0.5, 600, 0, 0, 300 * time.Second, true},
+		{"History only, partway", 0.5, 600, 100 * time.Second, 0, 200 * time.Second, true},
+		{"Progress only", 0, 600, 50 * time.Second, 50, 50 * time.Second, true},
+		{"Blend at 50%", 0.5, 600, 100 * time.Second, 50, 150 * time.Second, true},
+		{"Overrun clamps to zero", 0.1, 600, 100 * time.Second, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, ok := EstimateRemaining(tt.factor, tt.duration, tt.elapsed, tt.percent)
+			if ok != tt.expectOK {
+				t.Fatalf("EstimateRemaining() ok = %v, expected %v", ok, tt.expectOK)
+			}
+			if remaining.Round(time.Second) != tt.expected {
+				t.Errorf("EstimateRemaining() = %v, expected %v", remaining, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFormatETA tests ETA formatting
+func TestFormatETA(t *testing.T) {
+	if got := FormatETA(150 * time.Second); got != "2m 30s" {
+		t.Errorf("FormatETA(150s) = %q, expected \"2m 30s\"", got)
+	}
+	if got := FormatETA(45 * time.Second); got != "45s" {
+		t.Errorf("FormatETA(45s) = %q, expected \"45s\"", got)
+	}
+}
+
+// TestEffectiveAudioDuration tests duration of a selected time range
+func TestEffectiveAudioDuration(t *testing.T) {
+	if d := effectiveAudioDuration(600, TimeRange{}); d != 600 {
+		t.Errorf("Whole file should be 600, got %v", d)
+	}
+	if d := effectiveAudioDuration(600, TimeRange{Start: 100, End: 250}); d != 150 {
+		t.Errorf("Range 100-250 should be 150, got %v", d)
+	}
+	if d := effectiveAudioDuration(600, TimeRange{Start: 500, End: 900}); d != 100 {
+		t.Errorf("Range past end should be clamped to 100, got %v", d)
+	}
+	if d := effectiveAudioDuration(600, TimeRange{Start: 700}); d != 0 {
+		t.Errorf("Range starting past end should be 0, got %v", d)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Non-speech events: stretches of a recording whisper transcribes as a sound rather
+// than as words, shown in transcripts as their label, e.g. [music]
+const (
+	EventMusic    = "music"
+	EventLaughter = "laughter"
+	EventApplause = "applause"
+	EventSilence  = "silence"
+	EventNoise    = "noise" // Any other sound whisper annotates in square brackets
+)
+
+// Which output formats show non-speech events (the events option)
+const (
+	EventsAll  = "all"  // Every format (default)
+	EventsNone = "none" // Events are left out of every format
+)
+
+// eventWords are the words of whisper's annotations, in English and Hebrew, that name
+// each event; an annotation is the event whose word it contains ("laughs" is laughter)
+var eventWords = []struct {
+	event string
+	words []string
+}{
+	{EventMusic, []string{"music", "singing", "מוזיקה", "מוסיקה", "נגינה", "שירה", "♪", "♫"}},
+	{EventLaughter, []string{"laugh", "chuckl", "צחוק", "צוחק", "צחקוק"}},
+	{EventApplause, []string{"applause", "clapping", "מחיאות כפיים", "כפיים"}},
+	{EventSilence, []string{"blank_audio", "silence", "שקט"}},
+}
+
+// annotationPattern matches a segment made only of annotations: [Music], (צחוק),
+// *laughs* or musical notes
+var annotationPattern = regexp.MustCompile(`^(?:\s*(?:\[[^\]]*\]|\([^)]*\)|\*[^*]+\*|[♪♫]+)\s*)+$`)
+
+// noSpeechThreshold is whisper's probability that a segment has no speech above which
+// a short segment is taken for silence whisper filled in with words, as it does with
+// "תודה רבה" at the end of many recordings. Whisper's own threshold for skipping
+// audio is 0.6; a higher one keeps quiet speech.
+const noSpeechThreshold = 0.8
+
+// maxSilenceWords is the most words a segment can have and still be taken for silence
+const maxSilenceWords = 4
+
+// ClassifyEvent returns the non-speech event a segment's text is, or "" for speech.
+// noSpeechProb is whisper's probability that the segment has no speech (0 when the
+// engine doesn't give one). Annotations within speech, such as "כן (צוחק) בדיוק",
+// leave the segment speech.
+func ClassifyEvent(text string, noSpeechProb float64) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	if annotationPattern.MatchString(text) || strings.HasPrefix(text, "♪") || strings.HasPrefix(text, "♫") {
+		lower := strings.ToLower(text)
+		for _, e := range eventWords {
+			for _, word := range e.words {
+				if strings.Contains(lower, word) {
+					return e.event
+				}
+			}
+		}
+		// Parentheses are also used for asides; only brackets surely mean a sound
+		if strings.HasPrefix(text, "[") {
+			return EventNoise
+		}
+	}
+	if noSpeechProb >= noSpeechThreshold && len(strings.Fields(text)) <= maxSilenceWords {
+		return EventSilence
+	}
+	return ""
+}
+
+// LabelEvent marks a segment whisper transcribed as a sound with its event, replacing
+// its text with the event's label
+func LabelEvent(seg Segment, noSpeechProb float64) Segment {
+	if event := ClassifyEvent(seg.Text, noSpeechProb); event != "" {
+		seg.Event, seg.Text = event, EventLabel(event)
+	}
+	return seg
+}
+
+// EventLabel is how an event is shown in transcripts, e.g. [music]
+func EventLabel(event string) string {
+	return "[" + event + "]"
+}
+
+// WithoutEvents returns the segments that are speech, leaving out the events
+func WithoutEvents(segments []Segment) []Segment {
+	speech := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		if seg.Event == "" {
+			speech = append(speech, seg)
+		}
+	}
+	return speech
+}
+
+// ShowsEvents reports whether the events option shows non-speech events in a format:
+// EventsAll (or "") for every format, EventsNone for none, or a comma-separated list
+// of the formats that show them, e.g. "text,markdown"
+func ShowsEvents(events, format string) bool {
+	switch events {
+	case "", EventsAll:
+		return true
+	case EventsNone:
+		return false
+	}
+	return containsString(splitList(events), format)
+}
+
+// validateEvents checks the events option names all, none or output formats
+func validateEvents(events string) error {
+	if events == "" || events == EventsAll || events == EventsNone {
+		return nil
+	}
+	for _, format := range splitList(events) {
+		if format == FormatAll || !containsString(validFormats, format) {
+			return fmt.Errorf("Invalid events '%s'. Valid options: all, none or formats such as text,markdown", events)
+		}
+	}
+	return nil
+}
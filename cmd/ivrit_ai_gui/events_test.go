@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestClassifyEvent tests telling whisper's annotations of sounds from speech
+func TestClassifyEvent(t *testing.T) {
+	tests := []struct {
+		text         string
+		noSpeechProb float64
+		want         string
+	}{
+		{" [Music]", 0, EventMusic},
+		{"♪ לה לה לה ♪", 0, EventMusic},
+		{"(מוזיקה)", 0, EventMusic},
+		{"[צחוק]", 0, EventLaughter},
+		{"*laughs*", 0, EventLaughter},
+		{"(מחיאות כפיים)", 0, EventApplause},
+		{"[BLANK_AUDIO]", 0, EventSilence},
+		{"[door slams]", 0, EventNoise},
+		{"(בערך)", 0, ""},                             // An aside, not a sound
+		{"כן (צוחק) בדיוק", 0, ""},                    // A sound within speech
+		{"תודה רבה", 0.93, EventSilence},              // Words whisper filled silence with
+		{"תודה רבה", 0.5, ""},                         // Quiet speech
+		{"אז בואו נתחיל את הישיבה של היום", 0.93, ""}, // Too much said to be silence
+	}
+	for _, tt := range tests {
+		if got := ClassifyEvent(tt.text, tt.noSpeechProb); got != tt.want {
+			t.Errorf("ClassifyEvent(%q, %.2f) = %q, want %q", tt.text, tt.noSpeechProb, got, tt.want)
+		}
+	}
+
+	if seg := LabelEvent(Segment{Text: "[Music]", Speaker: 1}, 0); seg.Event != EventMusic || seg.Text != "[music]" {
+		t.Errorf("Unexpected labeled segment: %+v", seg)
+	}
+}
+
+// TestShowsEvents tests the formats that show events
+func TestShowsEvents(t *testing.T) {
+	if !ShowsEvents("", "srt") || !ShowsEvents(EventsAll, "srt") || ShowsEvents(EventsNone, "text") {
+		t.Error("Expected events in every format by default and in none with none")
+	}
+	if !ShowsEvents("text, markdown", "markdown") || ShowsEvents("text,markdown", "srt") {
+		t.Error("Expected events only in the formats listed")
+	}
+	for _, events := range []string{"", EventsAll, EventsNone, "text,json"} {
+		if err := validateEvents(events); err != nil {
+			t.Errorf("validateEvents(%q) error: %v", events, err)
+		}
+	}
+	for _, events := range []string{"docx", "text,all", "some"} {
+		if err := validateEvents(events); err == nil {
+			t.Errorf("Expected validateEvents(%q) to fail", events)
+		}
+	}
+}
+
+// TestEventsInOutput tests that events aren't attributed to speakers and can be left out
+func TestEventsInOutput(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום לכולם"},
+		{Start: 2, End: 9, Text: "[music]", Event: EventMusic, Speaker: 1},
+		{Start: 9, End: 11, Text: "נתחיל"},
+	}
+
+	text := FormatOutput(segments, "text", DisplayBilingual)
+	if strings.Contains(text, "Speaker 2") || !strings.Contains(text, "\n[music]\n") {
+		t.Errorf("Expected the event on its own line, without a speaker:\n%s", text)
+	}
+	json := FormatOutput(segments, "json", DisplayBilingual)
+	if !strings.Contains(json, `"text": "[music]", "event": "music"`) {
+		t.Errorf("Expected the event in JSON:\n%s", json)
+	}
+	if srt := FormatOutput(WithoutEvents(segments), "srt", DisplayBilingual); strings.Contains(srt, "[music]") {
+		t.Errorf("Expected the event left out:\n%s", srt)
+	}
+
+	stats := ComputeSpeakerStats(segments)
+	if len(stats) != 1 || stats[0].TalkTime != 4 {
+		t.Errorf("Expected the music not to count as talk time: %+v", stats)
+	}
+}
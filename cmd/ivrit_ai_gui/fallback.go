@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FallbackEngine implements TranscriptionEngine over an ordered list of engines
+// (e.g. local → local-cpu → runpod). Each transcription starts with the first
+// engine; when an engine can't be created or its transcription fails, the next one
+// takes over, and the switch is reported through the progress callback.
+type FallbackEngine struct {
+	kinds   []string
+	engines map[string]TranscriptionEngine // Created so far, by kind
+	failed  map[string]error               // Engines that could not be created, not tried again
+	create  func(kind string) (TranscriptionEngine, error)
+	active  string // The engine that produced the last result
+
+	abortMutex sync.Mutex
+	aborted    bool
+	running    TranscriptionEngine // The engine transcribing now, for Abort
+
+	dumpTokens bool
+	timeRange  TimeRange
+	parallel   int
+	decode     DecodeOptions
+}
+
+// NewFallbackEngine creates an engine trying cfg's engine chain in order. Engines are
+// created when first needed, so a model is only downloaded if its engine is reached.
+func NewFallbackEngine(cfg AppConfig, modelID string, progressCallback func(string, int)) *FallbackEngine {
+	return &FallbackEngine{
+		kinds:   cfg.EngineChain(),
+		engines: make(map[string]TranscriptionEngine),
+		failed:  make(map[string]error),
+		create: func(kind string) (TranscriptionEngine, error) {
+			return newEngine(cfg, kind, modelID, progressCallback)
+		},
+	}
+}
+
+// SupportsModel reports whether the engine can run a model
+func (f *FallbackEngine) SupportsModel(modelID string) bool {
+	return true
+}
+
+// SetTokenDump enables per-token data on the engines that support it
+func (f *FallbackEngine) SetTokenDump(enabled bool) {
+	f.dumpTokens = enabled
+}
+
+// SetTimeRange restricts transcription to part of the audio (zero value = whole file)
+func (f *FallbackEngine) SetTimeRange(timeRange TimeRange) {
+	f.timeRange = timeRange
+}
+
+// SetParallelChunks sets the parallel chunks of the local engines
+func (f *FallbackEngine) SetParallelChunks(n int) {
+	f.parallel = n
+}
+
+// SetDecodeOptions sets the decoding parameters of every engine
+func (f *FallbackEngine) SetDecodeOptions(decode DecodeOptions) {
+	f.decode = decode
+}
+
+// LastResultCached reports whether the engine that produced the last result served it from its cache
+func (f *FallbackEngine) LastResultCached() bool {
+	if engine := f.activeEngine(); engine != nil {
+		return engine.LastResultCached()
+	}
+	return false
+}
+
+// Abort stops the engine transcribing now; no further engines are tried
+func (f *FallbackEngine) Abort() {
+	f.abortMutex.Lock()
+	defer f.abortMutex.Unlock()
+	f.aborted = true
+	if f.running != nil {
+		f.running.Abort()
+	}
+}
+
+// setRunning records the engine about to transcribe, or reports false after Abort
+func (f *FallbackEngine) setRunning(engine TranscriptionEngine) bool {
+	f.abortMutex.Lock()
+	defer f.abortMutex.Unlock()
+	f.running = engine
+	return !f.aborted
+}
+
+// Close closes every engine created
+func (f *FallbackEngine) Close() {
+	for _, engine := range f.engines {
+		engine.Close()
+	}
+}
+
+// Transcribe transcribes with the first engine that succeeds. Segments already
+// streamed by a failed engine aren't streamed again by the next one.
+func (f *FallbackEngine) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	report := func(msg string) {
+		if progressCallback != nil {
+			progressCallback(msg)
+		}
+	}
+	streamedUntil := 0.0
+	stream := func(segment Segment) {
+		if segment.End > streamedUntil {
+			streamedUntil = segment.End
+			if segmentCallback != nil {
+				segmentCallback(segment)
+			}
+		}
+	}
+
+	var failures []string
+	for i, kind := range f.kinds {
+		engine, err := f.engine(kind)
+		if err == nil {
+			if !f.setRunning(engine) {
+				return nil, ErrInterrupted
+			}
+			var segments []Segment
+			if segments, err = engine.Transcribe(audioPath, modelID, cpuThreads, progressCallback, stream); err == nil {
+				f.active = kind
+				if i > 0 {
+					report(fmt.Sprintf("Transcribed with the %s engine (fallback)", kind))
+				}
+				return segments, nil
+			}
+			if !f.setRunning(nil) {
+				return nil, ErrInterrupted
+			}
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", kind, err))
+		if i+1 < len(f.kinds) {
+			report(fmt.Sprintf("The %s engine failed (%v); falling back to %s", kind, err, f.kinds[i+1]))
+		}
+	}
+	return nil, fmt.Errorf("all transcription engines failed: %s", strings.Join(failures, "; "))
+}
+
+// engine returns the engine of a kind with the current settings, creating it on first use
+func (f *FallbackEngine) engine(kind string) (TranscriptionEngine, error) {
+	if err, ok := f.failed[kind]; ok {
+		return nil, err
+	}
+	engine, ok := f.engines[kind]
+	if !ok {
+		var err error
+		if engine, err = f.create(kind); err != nil {
+			f.failed[kind] = err
+			return nil, err
+		}
+		f.engines[kind] = engine
+	}
+	engine.SetTokenDump(f.dumpTokens)
+	engine.SetTimeRange(f.timeRange)
+	engine.SetParallelChunks(f.parallel)
+	engine.SetDecodeOptions(f.decode)
+	return engine, nil
+}
+
+// activeEngine returns the engine that produced the last result, if any
+func (f *FallbackEngine) activeEngine() TranscriptionEngine {
+	return f.engines[f.active]
+}
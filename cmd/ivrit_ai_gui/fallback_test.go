@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeEngine streams and returns canned segments, or fails with err
+type fakeEngine struct {
+	segments []Segment
+	err      error
+	decode   DecodeOptions
+	calls    int
+	closed   bool
+}
+
+func (f *fakeEngine) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	f.calls++
+	for _, segment := range f.segments {
+		if segmentCallback != nil {
+			segmentCallback(segment)
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.segments, nil
+}
+
+func (f *fakeEngine) SupportsModel(modelID string) bool     { return true }
+func (f *fakeEngine) SetTokenDump(enabled bool)             {}
+func (f *fakeEngine) SetTimeRange(timeRange TimeRange)      {}
+func (f *fakeEngine) SetParallelChunks(n int)               {}
+func (f *fakeEngine) SetDecodeOptions(decode DecodeOptions) { f.decode = decode }
+func (f *fakeEngine) LastResultCached() bool                { return false }
+func (f *fakeEngine) Abort()                                {}
+func (f *fakeEngine) Close()                                { f.closed = true }
+
+// newTestFallbackEngine creates a FallbackEngine over fake engines; kinds missing
+// from engines can't be created
+func newTestFallbackEngine(kinds []string, engines map[string]*fakeEngine) (*FallbackEngine, map[string]int) {
+	created := map[string]int{}
+	f := &FallbackEngine{
+		kinds:   kinds,
+		engines: make(map[string]TranscriptionEngine),
+		failed:  make(map[string]error),
+		create: func(kind string) (TranscriptionEngine, error) {
+			created[kind]++
+			if engine, ok := engines[kind]; ok {
+				return engine, nil
+			}
+			return nil, errors.New("model not downloaded")
+		},
+	}
+	return f, created
+}
+
+// TestFallbackEngineFailover tests that failing and unavailable engines hand over to the next one
+func TestFallbackEngineFailover(t *testing.T) {
+	local := &fakeEngine{segments: []Segment{{Start: 0, End: 2, Text: "שלום"}}, err: errors.New("out of GPU memory")}
+	remote := &fakeEngine{segments: []Segment{{Start: 0, End: 2, Text: "שלום"}, {Start: 2, End: 4, Text: "עולם"}}}
+	f, created := newTestFallbackEngine([]string{EngineLocal, EngineLocalCPU, EngineRunPod},
+		map[string]*fakeEngine{EngineLocal: local, EngineRunPod: remote})
+	f.SetDecodeOptions(DecodeOptions{BeamSize: 5})
+
+	var messages []string
+	var streamed []Segment
+	segments, err := f.Transcribe("talk.wav", "turbo", 4, func(msg string) { messages = append(messages, msg) }, func(s Segment) { streamed = append(streamed, s) })
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if !reflect.DeepEqual(segments, remote.segments) || f.active != EngineRunPod {
+		t.Errorf("Expected the runpod engine's result, got %+v from %s", segments, f.active)
+	}
+	// The segment the local engine streamed before failing isn't repeated
+	if len(streamed) != 2 || streamed[1].Text != "עולם" {
+		t.Errorf("Expected each segment streamed once, got %+v", streamed)
+	}
+	if remote.decode.BeamSize != 5 {
+		t.Errorf("Expected the decode options passed on, got %+v", remote.decode)
+	}
+	report := strings.Join(messages, "\n")
+	for _, expected := range []string{"local engine failed (out of GPU memory); falling back to local-cpu", "local-cpu engine failed (model not downloaded)", "with the runpod engine (fallback)"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %q reported, got:\n%s", expected, report)
+		}
+	}
+
+	// The next file starts over with the first engine; unavailable ones aren't created again
+	if _, err := f.Transcribe("talk.wav", "turbo", 4, nil, nil); err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if local.calls != 2 || created[EngineLocalCPU] != 1 {
+		t.Errorf("Expected the local engine retried and local-cpu not recreated, got %d calls and %d creations", local.calls, created[EngineLocalCPU])
+	}
+
+	f.Close()
+	if !local.closed || !remote.closed {
+		t.Error("Expected every created engine closed")
+	}
+}
+
+// TestFallbackEngineAllFail tests that every engine's error is reported when none succeeds
+func TestFallbackEngineAllFail(t *testing.T) {
+	f, _ := newTestFallbackEngine([]string{EngineLocal, EngineWhisperServer},
+		map[string]*fakeEngine{EngineWhisperServer: {err: errors.New("connection refused")}})
+
+	_, err := f.Transcribe("talk.wav", "turbo", 4, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "local: model not downloaded") || !strings.Contains(err.Error(), "whisper-server: connection refused") {
+		t.Errorf("Expected both failures reported, got %v", err)
+	}
+}
+
+// TestEngineChainConfig tests the engine chain and its validation
+func TestEngineChainConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.applyEnv(func(name string) string {
+		return map[string]string{"IVRIT_ENGINE_FALLBACK": "local-cpu, whisper-server"}[name]
+	}); err != nil {
+		t.Fatalf("applyEnv() error: %v", err)
+	}
+	if chain := cfg.EngineChain(); !reflect.DeepEqual(chain, []string{EngineLocal, EngineLocalCPU, EngineWhisperServer}) {
+		t.Errorf("Unexpected chain %v", chain)
+	}
+	if cfg.EngineAddress() != defaultEngineURLs[EngineWhisperServer] {
+		t.Errorf("Expected the fallback server's address, got %s", cfg.EngineAddress())
+	}
+	if cfg.NeedsThreadTuning("turbo", nil) {
+		t.Error("Expected no thread tuning with fallback engines")
+	}
+	if engine, err := NewTranscriptionEngine(cfg, "turbo", nil); err != nil {
+		t.Fatalf("NewTranscriptionEngine() error: %v", err)
+	} else if _, ok := engine.(*FallbackEngine); !ok {
+		t.Errorf("Expected a FallbackEngine, got %T", engine)
+	}
+
+	tests := []struct {
+		name     string
+		fallback []string
+		valid    bool
+	}{
+		{"Local then remote", []string{EngineLocalCPU, EngineFasterWhisper}, true},
+		{"Unknown engine", []string{"openai"}, false},
+		{"Listed twice", []string{EngineLocal}, false},
+		{"Two remote engines", []string{EngineWhisperServer, EngineFasterWhisper}, false},
+		{"RunPod without key", []string{EngineRunPod}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.EngineFallback = tt.fallback
+			if err := cfg.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() error = %v, expected valid = %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// Explicit tool paths from the config (empty = search)
+var (
+	ffmpegOverride  string
+	ffprobeOverride string
+	ffmpegMutex     sync.RWMutex
+)
+
+// SetFFmpegPaths sets explicit ffmpeg/ffprobe executables; empty paths fall back to searching
+func SetFFmpegPaths(ffmpeg, ffprobe string) {
+	ffmpegMutex.Lock()
+	defer ffmpegMutex.Unlock()
+	ffmpegOverride = ffmpeg
+	ffprobeOverride = ffprobe
+}
+
+// ffmpegPath returns the ffmpeg executable to run
+func ffmpegPath() string {
+	ffmpegMutex.RLock()
+	override := ffmpegOverride
+	ffmpegMutex.RUnlock()
+
+	if path, err := findTool("ffmpeg", override); err == nil {
+		return path
+	}
+	return "ffmpeg" // Let exec report the failure
+}
+
+// ffprobePath returns the ffprobe executable to run
+func ffprobePath() string {
+	ffmpegMutex.RLock()
+	override := ffprobeOverride
+	ffmpegMutex.RUnlock()
+
+	if path, err := findTool("ffprobe", override); err == nil {
+		return path
+	}
+	return "ffprobe"
+}
+
+// toolSearchDirs returns common install locations that are often missing from
+// PATH (notably for GUI apps launched from Finder or the Start menu)
+func toolSearchDirs() []string {
+	dirs := []string{}
+
+	// Next to our own executable (bundled builds)
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		dirs = append(dirs, "/opt/homebrew/bin", "/usr/local/bin", "/opt/local/bin")
+	case "windows":
+		dirs = append(dirs,
+			`C:\ffmpeg\bin`,
+			filepath.Join(os.Getenv("ProgramFiles"), "ffmpeg", "bin"),
+			filepath.Join(os.Getenv("ProgramData"), "chocolatey", "bin"),
+			filepath.Join(os.Getenv("USERPROFILE"), "scoop", "shims"),
+			filepath.Join(os.Getenv("LOCALAPPDATA"), "Microsoft", "WinGet", "Links"),
+		)
+	default:
+		dirs = append(dirs, "/usr/local/bin", "/usr/bin", "/snap/bin", "/var/lib/flatpak/exports/bin")
+	}
+
+	return dirs
+}
+
+// findTool locates an executable: the explicit path if given, else PATH, else common install locations
+func findTool(name, override string) (string, error) {
+	if override != "" {
+		if info, err := os.Stat(override); err != nil || info.IsDir() {
+			return "", fmt.Errorf("%s not found at configured path %s", name, override)
+		}
+		return override, nil
+	}
+
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	fileName := name
+	if runtime.GOOS == "windows" {
+		fileName += ".exe"
+	}
+	for _, dir := range toolSearchDirs() {
+		path := filepath.Join(dir, fileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not found", name)
+}
+
+// CheckFFmpeg verifies that ffmpeg and ffprobe can be found, with install instructions if not
+func CheckFFmpeg() error {
+	ffmpegMutex.RLock()
+	overrides := map[string]string{"ffmpeg": ffmpegOverride, "ffprobe": ffprobeOverride}
+	ffmpegMutex.RUnlock()
+
+	for _, name := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := findTool(name, overrides[name]); err != nil {
+			return fmt.Errorf("%v\n%s", err, ffmpegInstallHint())
+		}
+	}
+	return nil
+}
+
+// ffmpegInstallHint explains how to install ffmpeg on this platform
+func ffmpegInstallHint() string {
+	var install string
+	switch runtime.GOOS {
+	case "darwin":
+		install = "Install it with: brew install ffmpeg"
+	case "windows":
+		install = "Install it with: winget install ffmpeg (or choco install ffmpeg), or unzip a build to C:\\ffmpeg"
+	default:
+		install = "Install it with: sudo apt install ffmpeg (Debian/Ubuntu) or sudo dnf install ffmpeg (Fedora)"
+	}
+	return install + "\nIf it is installed elsewhere, set its location with -ffmpeg/-ffprobe or \"ffmpegPath\"/\"ffprobePath\" in " + configPath()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestFindTool tests locating executables by explicit path and PATH search
+func TestFindTool(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-ffmpeg-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name := "ivrit-fake-tool"
+	fileName := name
+	if runtime.GOOS == "windows" {
+		fileName += ".exe"
+	}
+	toolPath := filepath.Join(tmpDir, fileName)
+	if err := os.WriteFile(toolPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write tool: %v", err)
+	}
+
+	// Explicit path wins
+	if path, err := findTool(name, toolPath); err != nil || path != toolPath {
+		t.Errorf("findTool() with override = %q, %v; expected %q", path, err, toolPath)
+	}
+
+	// A configured path that doesn't exist is an error, not a silent fallback
+	if _, err := findTool(name, filepath.Join(tmpDir, "missing")); err == nil {
+		t.Error("Expected error for missing configured path")
+	}
+	if _, err := findTool(name, tmpDir); err == nil {
+		t.Error("Expected error for configured path that is a directory")
+	}
+
+	// Found on PATH
+	t.Setenv("PATH", tmpDir)
+	if path, err := findTool(name, ""); err != nil || path != toolPath {
+		t.Errorf("findTool() via PATH = %q, %v; expected %q", path, err, toolPath)
+	}
+
+	// Not found anywhere
+	if _, err := findTool("ivrit-no-such-tool", ""); err == nil {
+		t.Error("Expected error for missing tool")
+	}
+}
+
+// TestCheckFFmpegHint tests that a missing ffmpeg explains how to fix it
+func TestCheckFFmpegHint(t *testing.T) {
+	SetFFmpegPaths("/nonexistent/ffmpeg", "/nonexistent/ffprobe")
+	defer SetFFmpegPaths("", "")
+
+	err := CheckFFmpeg()
+	if err == nil {
+		t.Fatal("Expected error for missing ffmpeg")
+	}
+	if !strings.Contains(err.Error(), "Install it with") || !strings.Contains(err.Error(), "-ffmpeg") {
+		t.Errorf("Error should include install instructions, got: %v", err)
+	}
+}
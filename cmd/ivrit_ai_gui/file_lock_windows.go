@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const lockfileExclusiveLock = 0x2
+
+var procLockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+
+// lockFile takes an exclusive lock on path+".lock", waiting while another process
+// holds it; unlock releases it
+func lockFile(path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	var overlapped syscall.Overlapped
+	if ok, _, err := procLockFileEx.Call(file.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped))); ok == 0 {
+		file.Close()
+		return nil, err
+	}
+	return func() { file.Close() }, nil // Closing the file releases the lock
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GlossaryTerm is the fixed translation of a Hebrew name or term, e.g. an
+// organization's official English name or the customary rendering of a religious term
+type GlossaryTerm struct {
+	Hebrew      string `json:"hebrew"`
+	Translation string `json:"translation"`
+	Lang        string `json:"lang,omitempty"` // Language of the translation ("" = any)
+}
+
+// GlossaryOptions are the terms translations must render consistently, listed in
+// config.json or in a glossary file
+type GlossaryOptions struct {
+	Terms []GlossaryTerm `json:"terms,omitempty"`
+	File  string         `json:"file,omitempty"` // "hebrew = translation" per line, under optional [lang] headings (# starts a comment)
+}
+
+// glossaryPrefixes are the one-letter Hebrew prefixes (and, the, in, to, from, that,
+// as) a term may be written with: "בירושלים" is "ירושלים" too
+const glossaryPrefixes = "והבלמשכ"
+
+// Glossary is the glossary's terms for one target language, ready to match
+type Glossary struct {
+	terms    []GlossaryTerm
+	patterns []*regexp.Regexp // Finds each term in Hebrew text, with up to two prefixes
+}
+
+// Load returns the terms for translating to lang: those of config.json and the
+// glossary file without a language, or in lang. Terms from the file come last, so a
+// term listed twice keeps the file's translation.
+func (o GlossaryOptions) Load(lang string) (*Glossary, error) {
+	terms := append([]GlossaryTerm{}, o.Terms...)
+	if o.File != "" {
+		fileTerms, err := readGlossaryFile(o.File)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fileTerms...)
+	}
+
+	g := &Glossary{}
+	index := make(map[string]int)
+	for _, term := range terms {
+		term.Hebrew, term.Translation = strings.TrimSpace(term.Hebrew), strings.TrimSpace(term.Translation)
+		if term.Hebrew == "" || term.Translation == "" || (term.Lang != "" && term.Lang != lang) {
+			continue
+		}
+		if i, ok := index[term.Hebrew]; ok {
+			g.terms[i] = term
+			continue
+		}
+		index[term.Hebrew] = len(g.terms)
+		g.terms = append(g.terms, term)
+		g.patterns = append(g.patterns, regexp.MustCompile(`(?:^|[^\p{L}\p{M}])[`+glossaryPrefixes+`]{0,2}`+regexp.QuoteMeta(term.Hebrew)+`(?:$|[^\p{L}\p{M}])`))
+	}
+	return g, nil
+}
+
+// NewTranslator returns a translator to lang that renders the glossary's terms as given
+func (c AppConfig) NewTranslator(lang string) (*MistralTranslator, error) {
+	glossary, err := c.Glossary.Load(lang)
+	if err != nil {
+		return nil, err
+	}
+	translator := NewMistralTranslator()
+	translator.SetGlossary(glossary)
+	return translator, nil
+}
+
+// readGlossaryFile reads a glossary file: "hebrew = translation" per line, for every
+// language until a [lang] heading, e.g. [en], makes the terms after it that language's
+func readGlossaryFile(path string) ([]GlossaryTerm, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read glossary: %v", err)
+	}
+	defer file.Close()
+
+	var terms []GlossaryTerm
+	lang := ""
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			lang = strings.TrimSpace(line[1 : len(line)-1])
+		default:
+			hebrew, translation, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(hebrew) == "" || strings.TrimSpace(translation) == "" {
+				return nil, fmt.Errorf("glossary %s line %d: expected \"hebrew = translation\"", path, number)
+			}
+			terms = append(terms, GlossaryTerm{Hebrew: hebrew, Translation: translation, Lang: lang})
+		}
+	}
+	return terms, scanner.Err()
+}
+
+// Len returns the number of terms
+func (g *Glossary) Len() int {
+	if g == nil {
+		return 0
+	}
+	return len(g.terms)
+}
+
+// Matches returns the terms that appear in a Hebrew text
+func (g *Glossary) Matches(hebrew string) []GlossaryTerm {
+	var matches []GlossaryTerm
+	for i := 0; i < g.Len(); i++ {
+		if g.patterns[i].MatchString(hebrew) {
+			matches = append(matches, g.terms[i])
+		}
+	}
+	return matches
+}
+
+// Missing returns the terms that appear in a Hebrew text whose translation, ignoring
+// case, doesn't appear in its translation
+func (g *Glossary) Missing(hebrew, translation string) []GlossaryTerm {
+	var missing []GlossaryTerm
+	lower := strings.ToLower(translation)
+	for _, term := range g.Matches(hebrew) {
+		if !strings.Contains(lower, strings.ToLower(term.Translation)) {
+			missing = append(missing, term)
+		}
+	}
+	return missing
+}
+
+// glossaryInstructions tells the model how to translate the glossary terms in the text
+func glossaryInstructions(terms []GlossaryTerm) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\nAlways translate these names and terms exactly as given:\n")
+	for _, term := range terms {
+		fmt.Fprintf(&b, "- %s: %s\n", term.Hebrew, term.Translation)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// glossaryList lists terms for the model as "hebrew → translation", comma-separated
+func glossaryList(terms []GlossaryTerm) string {
+	items := make([]string, len(terms))
+	for i, term := range terms {
+		items[i] = term.Hebrew + " → " + term.Translation
+	}
+	return strings.Join(items, ", ")
+}
+
+// GlossaryMiss is a segment whose translation still doesn't use a glossary term's
+// translation after being translated again
+type GlossaryMiss struct {
+	Start float64
+	Term  GlossaryTerm
+}
+
+// Describe says what is missing where, e.g. `"Rabbi Kook" for הרב קוק at 00:01:05`
+func (m GlossaryMiss) Describe() string {
+	return fmt.Sprintf("%q for %s at %s", m.Term.Translation, m.Term.Hebrew, FormatTimestamp(m.Start, true)[:8])
+}
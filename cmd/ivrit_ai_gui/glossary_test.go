@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGlossaryLoad tests combining config.json's terms with a glossary file's for one language
+func TestGlossaryLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.txt")
+	os.WriteFile(path, []byte(`# Names
+הרב קוק = Rabbi Kook
+מכון ויצמן = Weizmann Institute of Science
+
+[fr]
+מכון ויצמן = Institut Weizmann
+`), 0644)
+
+	options := GlossaryOptions{
+		Terms: []GlossaryTerm{
+			{Hebrew: "מכון ויצמן", Translation: "Weizmann Institute"},
+			{Hebrew: "בית המשפט העליון", Translation: "Supreme Court", Lang: "en"},
+		},
+		File: path,
+	}
+	en, err := options.Load("en")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if en.Len() != 3 {
+		t.Errorf("Expected 3 English terms, got %d", en.Len())
+	}
+	if m := en.Matches("ביקרנו במכון ויצמן"); len(m) != 1 || m[0].Translation != "Weizmann Institute of Science" {
+		t.Errorf("Expected the file's translation to win, got %+v", m)
+	}
+
+	fr, _ := options.Load("fr")
+	if m := fr.Matches("מכון ויצמן"); fr.Len() != 2 || len(m) != 1 || m[0].Translation != "Institut Weizmann" {
+		t.Errorf("Expected the French section's translation, got %d terms, %+v", fr.Len(), m)
+	}
+
+	os.WriteFile(path, []byte("הרב קוק = Rabbi Kook\nמכון ויצמן\n"), 0644)
+	if _, err := options.Load("en"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error for line 2, got %v", err)
+	}
+}
+
+// TestGlossaryMatches tests finding terms with Hebrew prefixes but not inside other words
+func TestGlossaryMatches(t *testing.T) {
+	glossary, _ := GlossaryOptions{Terms: []GlossaryTerm{{Hebrew: "ירושלים", Translation: "Jerusalem"}}}.Load("en")
+
+	for _, text := range []string{"ירושלים יפה", "גרנו בירושלים", "ובירושלים ירד שלג"} {
+		if len(glossary.Matches(text)) != 1 {
+			t.Errorf("Expected a match in %q", text)
+		}
+	}
+	if len(glossary.Matches("הירושלמים")) != 0 {
+		t.Error("Expected no match inside another word")
+	}
+
+	if missing := glossary.Missing("גרנו בירושלים", "We lived in JERUSALEM"); len(missing) != 0 {
+		t.Errorf("Expected the translation to be found ignoring case, missing %+v", missing)
+	}
+	if missing := glossary.Missing("גרנו בירושלים", "We lived in Yerushalayim"); len(missing) != 1 {
+		t.Errorf("Expected the term to be missing, got %+v", missing)
+	}
+
+	var none *Glossary
+	if none.Len() != 0 || none.Matches("ירושלים") != nil {
+		t.Error("Expected a nil glossary to have no terms")
+	}
+}
+
+// TestTranslateWithGlossary tests that the prompt lists the glossary's terms and that
+// a translation leaving one out is translated again
+func TestTranslateWithGlossary(t *testing.T) {
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OllamaRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		prompts = append(prompts, request.Prompt)
+		reply := "Rav Kuk wrote about it"
+		if strings.Contains(request.Prompt, "A previous translation got these wrong") {
+			reply = "Rabbi Kook wrote about it"
+		}
+		json.NewEncoder(w).Encode(OllamaResponse{Response: reply, Done: true})
+	}))
+	defer server.Close()
+
+	glossary, _ := GlossaryOptions{Terms: []GlossaryTerm{
+		{Hebrew: "הרב קוק", Translation: "Rabbi Kook"},
+		{Hebrew: "מכון ויצמן", Translation: "Weizmann Institute"},
+	}}.Load("en")
+	translator := NewMistralTranslator()
+	translator.ollamaURL = server.URL
+	translator.SetGlossary(glossary)
+
+	segments, err := translator.TranslateSegments([]Segment{{Start: 65, End: 68, Text: "הרב קוק כתב על זה"}}, "en", nil, nil)
+	if err != nil {
+		t.Fatalf("TranslateSegments() error: %v", err)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "- הרב קוק: Rabbi Kook") || strings.Contains(prompts[0], "מכון ויצמן:") {
+		t.Errorf("Expected the prompt to list only the terms in the text, then a retry: %q", prompts)
+	}
+	if segments[0].Translation != "Rabbi Kook wrote about it" || len(translator.GlossaryMisses()) != 0 {
+		t.Errorf("Expected the retry to be kept, got %q with misses %+v", segments[0].Translation, translator.GlossaryMisses())
+	}
+}
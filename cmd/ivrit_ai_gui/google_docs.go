@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Google endpoints (variables so tests can point them at a local server)
+var (
+	googleAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	googleDocsAPI  = "https://docs.googleapis.com/v1"
+)
+
+// googleDocsScope only allows access to files created by the app, which is all the
+// Docs API needs to create a document and write the transcript into it
+const googleDocsScope = "https://www.googleapis.com/auth/drive.file"
+
+// googleLoginTimeout is how long to wait for the user to approve access in the browser
+const googleLoginTimeout = 5 * time.Minute
+
+// googleTokenResponse is the OAuth token endpoint's reply
+type googleTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// GoogleLogin runs the OAuth flow for installed apps: the user approves access in
+// the browser, which redirects back to a temporary local server with the code
+func GoogleLogin(clientID, clientSecret string) (*GoogleToken, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("client ID and secret are required")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	redirectURI := "http://" + listener.Addr().String()
+	state := newJobID()
+
+	// Buffered and sent without blocking: only the first redirect counts
+	received := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			fmt.Fprintln(w, "Access was not granted. You can close this window.")
+			select {
+			case errs <- fmt.Errorf("authorization failed: %s", query.Get("error")):
+			default:
+			}
+		default:
+			fmt.Fprintln(w, "ivrit.ai is now connected to Google Docs. You can close this window.")
+			select {
+			case received <- query.Get("code"):
+			default:
+			}
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL := googleAuthURL + "?" + url.Values{
+		"client_id":     {clientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {googleDocsScope},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"},
+		"state":         {state},
+	}.Encode()
+	fmt.Printf("\nOpen this page in your browser to allow access:\n\n  %s\n\n", authURL)
+
+	var code string
+	select {
+	case code = <-received:
+	case err := <-errs:
+		return nil, err
+	case <-time.After(googleLoginTimeout):
+		return nil, fmt.Errorf("timed out waiting for authorization")
+	}
+
+	token := &GoogleToken{ClientID: clientID, ClientSecret: clientSecret}
+	err = token.requestToken(url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		return nil, fmt.Errorf("google did not return a refresh token")
+	}
+	return token, nil
+}
+
+// accessToken returns a valid access token, refreshing it (and saving the new one) when expired
+func (t *GoogleToken) accessToken() (string, error) {
+	if t.AccessToken != "" && time.Until(t.Expiry) > time.Minute {
+		return t.AccessToken, nil
+	}
+
+	err := t.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.RefreshToken},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh Google token (run with -login google again): %v", err)
+	}
+
+	// Keep the refreshed token for next time
+	if integrations, err := LoadIntegrations(); err == nil {
+		integrations.Google = t
+		SaveIntegrations(integrations)
+	}
+	return t.AccessToken, nil
+}
+
+// requestToken calls the token endpoint and stores the result in t
+func (t *GoogleToken) requestToken(params url.Values) error {
+	params.Set("client_id", t.ClientID)
+	params.Set("client_secret", t.ClientSecret)
+
+	client := httpClient(integrationTimeout)
+	resp, err := client.PostForm(googleTokenURL, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result googleTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid token response (HTTP %d): %v", resp.StatusCode, err)
+	}
+	if result.Error != "" || result.AccessToken == "" {
+		return fmt.Errorf("%s %s", result.Error, result.Description)
+	}
+
+	t.AccessToken = result.AccessToken
+	t.Expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	if result.RefreshToken != "" {
+		t.RefreshToken = result.RefreshToken
+	}
+	return nil
+}
+
+// ExportToGoogleDocs creates a new Google Doc with a heading per speaker turn and returns its URL
+func ExportToGoogleDocs(token *GoogleToken, title string, segments []Segment) (string, error) {
+	accessToken, err := token.accessToken()
+	if err != nil {
+		return "", err
+	}
+
+	var doc struct {
+		DocumentID string `json:"documentId"`
+	}
+	if err := doJSON("POST", googleDocsAPI+"/documents", accessToken, nil, map[string]string{"title": title}, &doc); err != nil {
+		return "", fmt.Errorf("failed to create document: %v", err)
+	}
+
+	// An empty transcript leaves the document empty (batchUpdate needs at least one request)
+	if requests := googleDocRequests(segments); len(requests) > 0 {
+		update := map[string]any{"requests": requests}
+		if err := doJSON("POST", googleDocsAPI+"/documents/"+doc.DocumentID+":batchUpdate", accessToken, nil, update, nil); err != nil {
+			return "", fmt.Errorf("failed to write document: %v", err)
+		}
+	}
+
+	return "https://docs.google.com/document/d/" + doc.DocumentID + "/edit", nil
+}
+
+// googleDocRequests builds the batchUpdate requests that insert the transcript into an
+// empty document: one insertText, then paragraph styles for headings and Hebrew text.
+// Document indices count UTF-16 code units, and a new document's body starts at 1.
+func googleDocRequests(segments []Segment) []map[string]any {
+	type paragraph struct {
+		text    string
+		heading bool
+	}
+	paragraphs := []paragraph{}
+	for _, turn := range SpeakerTurns(segments) {
+		text, translation := turn.Text()
+		paragraphs = append(paragraphs, paragraph{text: turn.Heading(), heading: true}, paragraph{text: text})
+		if translation != "" {
+			paragraphs = append(paragraphs, paragraph{text: translation})
+		}
+	}
+	if len(paragraphs) == 0 {
+		return []map[string]any{}
+	}
+
+	var body strings.Builder
+	styles := []map[string]any{}
+	index := 1
+	for _, p := range paragraphs {
+		line := strings.ReplaceAll(p.text, "\n", " ") + "\n"
+		body.WriteString(line)
+		end := index + len(utf16.Encode([]rune(line)))
+
+		style := map[string]any{}
+		fields := []string{}
+		if p.heading {
+			style["namedStyleType"] = "HEADING_2"
+			fields = append(fields, "namedStyleType")
+		}
+		if containsRTL(p.text) {
+			style["direction"] = "RIGHT_TO_LEFT"
+			style["alignment"] = "START"
+			fields = append(fields, "direction", "alignment")
+		}
+		if len(fields) > 0 {
+			styles = append(styles, map[string]any{"updateParagraphStyle": map[string]any{
+				"range":          map[string]int{"startIndex": index, "endIndex": end},
+				"paragraphStyle": style,
+				"fields":         strings.Join(fields, ","),
+			}})
+		}
+		index = end
+	}
+
+	requests := []map[string]any{{"insertText": map[string]any{
+		"location": map[string]int{"index": 1},
+		"text":     body.String(),
+	}}}
+	return append(requests, styles...)
+}
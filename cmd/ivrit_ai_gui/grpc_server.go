@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	pb "github.com/ivrit-ai/hebrew-transcription-native/api/transcriptionpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// maxGRPCMessageSize allows a whole recording to be sent in one Transcribe request
+const maxGRPCMessageSize = 1 << 30
+
+// grpcServer implements the TranscriptionService defined in api/transcriptionpb/transcription.proto
+type grpcServer struct {
+	pb.UnimplementedTranscriptionServiceServer
+	config AppConfig
+}
+
+// ServeGRPC serves the gRPC API on addr until the listener fails
+func ServeGRPC(addr string, config AppConfig) error {
+	server, err := newGRPCServer(config)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	if len(config.APIKeys) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: No API keys configured; anyone who can reach %s can use this server\n", listener.Addr())
+	}
+	scheme := "plaintext"
+	if config.TLSCert != "" {
+		scheme = "TLS"
+	}
+	fmt.Printf("gRPC server listening on %s (%s)\n", listener.Addr(), scheme)
+	return server.Serve(listener)
+}
+
+// newGRPCServer creates the gRPC server with TLS and API key checks as configured
+func newGRPCServer(config AppConfig) (*grpc.Server, error) {
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(maxGRPCMessageSize)}
+
+	if config.TLSCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	if len(config.APIKeys) > 0 {
+		auth := apiKeyAuth{keys: config.APIKeys}
+		opts = append(opts, grpc.UnaryInterceptor(auth.unary), grpc.StreamInterceptor(auth.stream))
+	}
+
+	server := grpc.NewServer(opts...)
+	pb.RegisterTranscriptionServiceServer(server, &grpcServer{config: config})
+	return server, nil
+}
+
+// Transcribe transcribes the uploaded file, streaming progress and segments as they are produced
+func (s *grpcServer) Transcribe(req *pb.TranscribeRequest, stream pb.TranscriptionService_TranscribeServer) (err error) {
+	if len(req.Audio) == 0 {
+		return status.Error(codes.InvalidArgument, "audio is required")
+	}
+
+	modelID := req.Model
+	if modelID == "" {
+		modelID = s.config.Model
+	}
+	if !containsString(ModelIDs(), modelID) {
+		return status.Errorf(codes.InvalidArgument, "invalid model %q (valid: %v)", modelID, ModelIDs())
+	}
+
+	timeRange := TimeRange{Start: req.From, End: req.To}
+	if err := timeRange.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	decode := s.config.Decode
+	if req.Decode != nil {
+		decode = DecodeOptions{
+			BeamSize:      int(req.Decode.BeamSize),
+			Temperature:   req.Decode.Temperature,
+			InitialPrompt: req.Decode.InitialPrompt,
+			// Segment limits aren't part of the request; the server's configuration applies
+			MaxSegmentChars:  s.config.Decode.MaxSegmentChars,
+			MaxSegmentTokens: s.config.Decode.MaxSegmentTokens,
+		}
+	}
+
+	// Requests that get this far are jobs; report how they end to the webhook
+	var segments []Segment
+	if s.config.WebhookURL != "" {
+		job := NewWebhookPayload(req.Filename, modelID)
+		defer func() {
+			job.Finish(segments, err)
+			go notifyWebhook(s.config.WebhookURL, job)
+		}()
+	}
+
+	// ffmpeg needs a file; keep the extension so it can recognise the container
+	audioFile, err := CreateTempFile("grpc_upload_*" + filepath.Ext(req.Filename))
+	if err != nil {
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+	audioPath := audioFile.Name()
+	defer RemoveTempFile(audioPath)
+	_, err = audioFile.Write(req.Audio)
+	audioFile.Close()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to write temp file: %v", err)
+	}
+
+	engine, err := NewTranscriptionEngine(s.config, modelID, nil)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "failed to load the transcription engine: %v", err)
+	}
+	defer engine.Close()
+	engine.SetTimeRange(timeRange)
+	engine.SetParallelChunks(s.config.Parallel)
+	engine.SetDecodeOptions(decode)
+
+	// A stream must not be written from several goroutines, so the callbacks
+	// hand their events to this goroutine, which does all the sending
+	events := make(chan *pb.TranscribeResponse, 100)
+	type transcriptionResult struct {
+		segments []Segment
+		err      error
+	}
+	done := make(chan transcriptionResult, 1)
+
+	go func() {
+		progressCallback := func(msg string) {
+			percent := -1
+			fmt.Sscanf(msg, "Transcribing... %d%%", &percent)
+			event := &pb.TranscribeResponse{Event: &pb.TranscribeResponse_Progress{
+				Progress: &pb.Progress{Message: msg, Percent: int32(percent)},
+			}}
+			// Progress is best effort; drop updates rather than stall inference
+			select {
+			case events <- event:
+			default:
+			}
+		}
+		segmentCallback := func(seg Segment) {
+			events <- &pb.TranscribeResponse{Event: &pb.TranscribeResponse_Segment{Segment: segmentToProto(seg)}}
+		}
+
+		// Thread counts tuned by the CLI or GUI on this machine are reused; requests
+		// aren't held up by benchmarking
+		threads := s.config.CPUThreads(modelID, LoadSettings().TunedThreads)
+		var segments []Segment
+		var err error
+		if req.SplitChannels {
+			segments, err = TranscribeByChannel(engine, audioPath, modelID, threads, progressCallback, segmentCallback)
+		} else {
+			segments, err = engine.Transcribe(audioPath, modelID, threads, progressCallback, segmentCallback)
+		}
+		done <- transcriptionResult{segments: segments, err: err}
+	}()
+
+	// A client that goes away (or cancels) aborts the transcription. Events are still
+	// drained until it stops, since the engine and temp file must outlive it.
+	var sendErr error
+	send := func(event *pb.TranscribeResponse) {
+		if sendErr == nil {
+			sendErr = stream.Send(event)
+		}
+	}
+	clientGone := stream.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			engine.Abort()
+			clientGone = nil
+		case event := <-events:
+			send(event)
+		case result := <-done:
+			for len(events) > 0 {
+				send(<-events)
+			}
+			if result.err == ErrInterrupted && stream.Context().Err() != nil {
+				return status.FromContextError(stream.Context().Err()).Err()
+			}
+			if result.err != nil {
+				return status.Errorf(codes.Internal, "transcription failed: %v", result.err)
+			}
+			// Keep a copy of the transcript in the configured destinations. A failed upload
+			// doesn't cost the client its transcript; it is reported alongside it.
+			var uploadErr error
+			if len(s.config.Destinations) > 0 {
+				name := autoOutputFileName(uploadName(req.Filename), s.config.Format)
+				for _, format := range outputFormats(s.config.Format) {
+					saved := NormalizeNumbers(result.segments, s.config.Numbers)
+					if !ShowsEvents(s.config.Events, format) {
+						saved = WithoutEvents(saved)
+					}
+					output := FormatOutput(saved, format, s.config.DisplayMode)
+					if err := UploadToDestinations(s.config.Destinations, formatFileName(name, format), s.config.OutputData(output, format)); err != nil && uploadErr == nil {
+						uploadErr = err
+					}
+				}
+			}
+
+			segments = result.segments
+			complete := &pb.TranscriptionComplete{
+				Segments: segmentsToProto(result.segments),
+				Cached:   engine.LastResultCached(),
+			}
+			if uploadErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Transcript of %q not uploaded: %v\n", req.Filename, uploadErr)
+				complete.UploadError = uploadErr.Error()
+			}
+			send(&pb.TranscribeResponse{Event: &pb.TranscribeResponse_Complete{Complete: complete}})
+			return sendErr
+		}
+	}
+}
+
+// uploadName names a transcript after the uploaded file, or the time of the upload if it had no name
+func uploadName(filename string) string {
+	if name := filepath.Base(filename); filename != "" && name != "." && name != "/" {
+		return name
+	}
+	return "upload_" + time.Now().Format("20060102_150405")
+}
+
+// ListModels lists the configured models and whether each is already downloaded
+func (s *grpcServer) ListModels(ctx context.Context, req *pb.ListModelsRequest) (*pb.ListModelsResponse, error) {
+	models := loadModelsConfig()
+
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	resp := &pb.ListModelsResponse{}
+	for _, id := range ids {
+		_, err := FindLocalModel(id)
+		resp.Models = append(resp.Models, &pb.Model{
+			Id:          id,
+			Description: models[id].Description,
+			Downloaded:  err == nil,
+		})
+	}
+	return resp, nil
+}
+
+// TranslateSegments translates segments with the local Mistral model
+func (s *grpcServer) TranslateSegments(ctx context.Context, req *pb.TranslateSegmentsRequest) (*pb.TranslateSegmentsResponse, error) {
+	if !containsString(validTargetLangs, req.TargetLanguage) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid target language %q (valid: %v)", req.TargetLanguage, validTargetLangs)
+	}
+
+	segments := make([]Segment, len(req.Segments))
+	for i, seg := range req.Segments {
+		segments[i] = segmentFromProto(seg)
+	}
+
+	translator, err := s.config.NewTranslator(req.TargetLanguage)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "glossary: %v", err)
+	}
+	translated, err := translator.TranslateSegments(segments, req.TargetLanguage, nil, nil)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "translation failed: %v", err)
+	}
+
+	return &pb.TranslateSegmentsResponse{Segments: segmentsToProto(translated)}, nil
+}
+
+// segmentToProto converts a segment to its API form (speakers are 1-based in the API)
+func segmentToProto(seg Segment) *pb.Segment {
+	return &pb.Segment{
+		Start:       seg.Start,
+		End:         seg.End,
+		Text:        seg.Text,
+		Speaker:     int32(seg.Speaker + 1),
+		Original:    seg.Original,
+		Translation: seg.Translation,
+	}
+}
+
+// segmentsToProto converts a list of segments to their API form
+func segmentsToProto(segments []Segment) []*pb.Segment {
+	result := make([]*pb.Segment, len(segments))
+	for i, seg := range segments {
+		result[i] = segmentToProto(seg)
+	}
+	return result
+}
+
+// segmentFromProto converts an API segment back; a missing speaker means the first speaker
+func segmentFromProto(seg *pb.Segment) Segment {
+	speaker := int(seg.Speaker) - 1
+	if speaker < 0 {
+		speaker = 0
+	}
+	return Segment{
+		Start:       seg.Start,
+		End:         seg.End,
+		Text:        seg.Text,
+		Speaker:     speaker,
+		Original:    seg.Original,
+		Translation: seg.Translation,
+	}
+}
@@ -1,22 +1,39 @@
+//go:build !headless
+
 package main
 
 // Gio-based GUI implementation with proper Unicode/RTL support
 // Gio is actively maintained and has better text rendering than Fyne
 
 import (
+	"errors"
 	"fmt"
+	"image"
 	"image/color"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"gioui.org/app"
+	"gioui.org/font"
 	"gioui.org/font/gofont"
+	"gioui.org/io/clipboard"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/pointer"
+	"gioui.org/io/system"
+	"gioui.org/io/transfer"
 	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
 	"gioui.org/text"
 	"gioui.org/unit"
 	"gioui.org/widget"
@@ -32,14 +49,126 @@ type GioApp struct {
 	// Widgets
 	fileLabel         *widget.Label
 	browseBtn         *widget.Clickable
+	newWindowBtn      *widget.Clickable // Opens another session window
+	demoBtn           *widget.Clickable // Transcribes the bundled sample clip
+	addPartBtn        *widget.Clickable // Adds the next file of a recording in parts
+	joinFolderBtn     *widget.Clickable // Joins the audio files of a folder, e.g. an audio CD
+	recordBtn         *widget.Clickable // Opens the panel for recording from a microphone
+	recordDevice      *widget.Enum      // Input recorded from, by its index in recordingDevices
+	recordLive        *widget.Bool      // Transcribes the recording while it is made
+	recordStartBtn    *widget.Clickable
+	recordPauseBtn    *widget.Clickable // Pauses or resumes the recording
+	recordStopBtn     *widget.Clickable // Stops the recording and transcribes it
+	recordCloseBtn    *widget.Clickable
 	transcribeBtn     *widget.Clickable
 	stopBtn           *widget.Clickable
 	saveBtn           *widget.Clickable
+	exportAllBtn      *widget.Clickable // Saves the transcript as txt, srt, vtt and json at once
+	perSpeakerBtn     *widget.Clickable // Saves one file per speaker
+	vttTracksBtn      *widget.Clickable // Saves a VTT per language with an HLS manifest
+	minutesBtn        *widget.Clickable // Generates meeting minutes with the local LLM
+	presentBtn        *widget.Clickable // Opens the live captions window
+	revealBtn         *widget.Clickable // Shows the last saved file in the file manager
+	openFileBtn       *widget.Clickable // Opens the last saved file with its default app
+	shareBtn          *widget.Clickable // Emails the last saved file
+	downloadPauseBtn  *widget.Clickable // Pauses or resumes the model download in progress
+	googleDocsBtn     *widget.Clickable // Exports to a new Google Doc (shown once connected)
+	notionBtn         *widget.Clickable // Exports to a new Notion page (shown once connected)
 	modelList         *widget.Enum
+	addModelBtn       *widget.Clickable // Opens the panel for adding a custom model
+	customModelName   *widget.Editor    // Settings of the custom model being added
+	customModelSource *widget.Enum      // customModelFromFile or customModelFromHub
+	customModelBrowseBtn *widget.Clickable
+	customModelRepo   *widget.Editor
+	customModelFile   *widget.Editor
+	saveCustomModelBtn   *widget.Clickable
+	cancelCustomModelBtn *widget.Clickable
 	formatList        *widget.Enum
+	presetList        *widget.Enum      // Preset whose options were applied last
+	presetNameEditor  *widget.Editor    // Name to save the current options under
+	savePresetBtn     *widget.Clickable // Saves the current options as a preset
 	enableTranslation *widget.Bool // Enable translation checkbox
 	translateLangList *widget.Enum // Target language for translation
-	keepOriginal      *widget.Bool // Keep original Hebrew text checkbox
+	checkTranslation  *widget.Bool // Back-translate the translation to flag segments that may be wrong
+	transliterate     *widget.Bool // Add a Latin-script transliteration of the Hebrew
+	displayMode       *widget.Enum // Which texts of a translation are shown: bilingual, original or translation
+	numberStyle       *widget.Enum // Numbers as transcribed, in digits or in words (see NumberOptions)
+	normalizeDates    *widget.Bool // Dates as DD/MM/YYYY and times as H:MM
+	splitChannels     *widget.Bool // Transcribe each audio channel as a separate speaker
+	participantTracks *widget.Bool // Transcribe a meeting recording's track per participant, named after them
+	redact            *widget.Bool // Also save a copy with phone numbers, IDs, emails and listed words masked
+	consensus         *widget.Bool // Transcribe twice and flag disagreements for review
+	preloadModel      *widget.Bool // Preload the default model at launch
+	compactLayout     *widget.Bool // Tighter spacing for small screens
+	watchClipboard    *widget.Bool // Offer to transcribe media files and URLs copied to the clipboard
+	coreMLEncoder     *widget.Bool // Run the encoder with Core ML (shown on Apple Silicon with a Core ML build)
+	coreMLAvailable   bool
+	checkModelUpdates *widget.Bool // Check the downloaded models for updates weekly
+	checkAppUpdates   *widget.Bool // Check GitHub for a newer release of the app daily (opt-in)
+	appUpdateBtn      *widget.Clickable // Downloads the new release's installer
+	appNotesBtn       *widget.Clickable // Shows or hides the new release's changelog
+	dismissAppUpdateBtn *widget.Clickable
+	modelDirBtn       *widget.Clickable // Chooses the folder models are stored in
+	updateModelsBtn   *widget.Clickable
+	dismissUpdatesBtn *widget.Clickable
+	redownloadBtn     *widget.Clickable
+	dismissDamagedBtn *widget.Clickable
+	onboardingModelList *widget.Enum      // Model chosen in the first-run setup
+	onboardingActionBtn *widget.Clickable // Does what the setup step needs: download, check again...
+	onboardingBackBtn   *widget.Clickable
+	onboardingNextBtn   *widget.Clickable
+	onboardingSkipBtn   *widget.Clickable
+	fontSmallerBtn    *widget.Clickable // Transcript font size
+	fontLargerBtn     *widget.Clickable
+	lineSpacingList   *widget.Enum // Transcript line spacing (one of lineSpacingOptions)
+	monospace         *widget.Bool // Show the transcript in a monospace font
+	showTimestamps    *widget.Bool // Prefix transcript lines with their start time
+	wallClock         *widget.Bool   // Show times of day, counting from startTimeEditor
+	startTimeEditor   *widget.Editor // When the recording started, from its .start file or name, or typed
+	speakerStats      *widget.Bool // Show (and save) per-speaker statistics after the transcript
+	textStats         *widget.Bool // Show word and character counts of the transcript
+	showEvents        *widget.Bool // Show (and save) non-speech events such as [music]
+	acceptOfferBtn    *widget.Clickable
+	dismissOfferBtn   *widget.Clickable
+	reuseTranscriptBtn  *widget.Clickable // Loads the earlier transcript of the selected recording's audio
+	dismissDuplicateBtn *widget.Clickable
+	historyBtn          *widget.Clickable                 // Opens the history of completed transcriptions
+	closeHistoryBtn     *widget.Clickable
+	historyOpenBtns     [historyShown]widget.Clickable    // Reopen the transcript of each entry shown
+	historyExportBtns   [historyShown]widget.Clickable    // Reopen it and save it again, e.g. in another format
+	voiceMemosBtn       *widget.Clickable                 // Lists the recent Voice Memos recordings (macOS)
+	closeVoiceMemosBtn  *widget.Clickable
+	voiceMemoBtns       [recentVoiceMemos]widget.Clickable // Transcribe each memo listed
+	fixSegmentBtn     *widget.Clickable // Re-transcribes the segment at the cursor
+	retranscribeModel       *widget.Enum       // Settings for re-transcribing a segment
+	retranscribeBeam        *widget.Editor
+	retranscribePrompt      *widget.Editor
+	retranscribeRunBtn      *widget.Clickable
+	retranscribeCancelBtn   *widget.Clickable
+	alternativeBtns         []widget.Clickable // One per alternative reading offered
+	dismissAlternativesBtn  *widget.Clickable
+	transcriptPointer       *int               // Tag for right-clicks on the transcript
+	spellCheckBtn           *widget.Clickable  // Proposes LLM corrections of recognition errors
+	playSegmentBtn          *widget.Clickable  // Plays the segment at the cursor
+	retranscribePlayBtn     *widget.Clickable  // Play buttons of the segment panels
+	alternativesPlayBtn     *widget.Clickable
+	correctionPlayBtn       *widget.Clickable
+	translationPlayBtn      *widget.Clickable
+	acceptCorrectionBtn     *widget.Clickable
+	skipCorrectionBtn       *widget.Clickable
+	acceptAllCorrectionsBtn *widget.Clickable
+	cancelCorrectionsBtn    *widget.Clickable
+	editTranslationBtn      *widget.Clickable  // Edits the translation of the segment at the cursor
+	translationEditor       *widget.Editor
+	saveTranslationBtn      *widget.Clickable
+	cancelTranslationBtn    *widget.Clickable
+	flagPlayBtn             *widget.Clickable // Buttons of the flagged translations panel
+	editFlaggedBtn          *widget.Clickable
+	nextFlagBtn             *widget.Clickable
+	closeFlagsBtn           *widget.Clickable
+	fromEditor        *widget.Editor // Optional start time of the range to transcribe
+	toEditor          *widget.Editor // Optional end time of the range to transcribe
+	maxSegmentEditor  *widget.Editor // Longest segment in characters at inference (empty = whisper's own segments)
 
 	// Credit links
 	ivritLink    *widget.Clickable
@@ -58,75 +187,736 @@ type GioApp struct {
 	stopRequested     bool // Flag to stop transcription
 	transcriptionStartTime int64
 	audioDuration     float64
+	lastManifest      *Manifest // Provenance of the current transcript (embedded in JSON exports)
+	consensusReview   *ConsensusReview    // Disagreements of a high accuracy run (protected by uiMutex)
+	presentation      *PresentationWindow // Live captions window (nil until opened, protected by uiMutex)
+	clipboardWatcher  *ClipboardWatcher   // Also the tag clipboard contents are delivered to
+	lastClipboardRead time.Time
+	clipboardOffer    string    // Copied media file or URL awaiting confirmation (protected by uiMutex)
+	duplicateOf       *TranscriptRecord // Earlier transcript of the selected file's audio under another name (protected by uiMutex)
+	history           []HistoryEntry    // Completed transcriptions listed while the history is open (nil = closed, protected by uiMutex)
+	presets           []Preset          // Built-in and saved presets (protected by uiMutex)
+	historyEntry      *HistoryEntry     // History entry of the transcript shown, updated when it is saved (protected by uiMutex)
+	modelUpdates      []ModelUpdate // Updated models published since they were downloaded (protected by uiMutex)
+	appUpdate         *AppRelease   // Newer release of the app found by the last check (protected by uiMutex)
+	showAppNotes      bool          // The new release's changelog is shown (protected by uiMutex)
+	damagedModel      *CorruptModelError // Model file that failed validation, offered for download again (protected by uiMutex)
+	onboardingStep    int       // Step of the first-run setup shown (-1 = none, protected by uiMutex)
+	onboardingInfo    string    // What the step found, e.g. where ffmpeg is missing (protected by uiMutex)
+	onboardingReady   bool      // The step's requirement is met (protected by uiMutex)
+	onboardingModel   string    // Model the model step was checked for (protected by uiMutex)
+	ollamaStatus      OllamaStatus // As of the Ollama step's last check (protected by uiMutex)
+	customModels      []string  // Custom models offered besides the built-in ones (protected by uiMutex)
+	addingModel       bool      // The panel for adding a custom model is open (protected by uiMutex)
+	customModelPath   string    // Local model file picked for the custom model (protected by uiMutex)
+	downloadDir       string    // Temporary directory of the last downloaded media URL
+	recordingParts    []RecordingPart // Files the selected recording was joined from (protected by uiMutex)
+	partPaths         []string  // Their paths, in order
+	joinDir           string    // Temporary directory of the joined recording
+	recordingOpen     bool      // The panel for recording is open (protected by uiMutex)
+	recordingDevices  []RecordingDevice // Inputs offered for recording (protected by uiMutex)
+	recorder          *Recorder // Recording in progress (protected by uiMutex)
+	liveTranscriber   *LiveTranscriber // Transcribing the recording in progress, in live mode (protected by uiMutex)
+	livePartial       string    // Provisional text of the live transcript, not yet settled (protected by uiMutex)
+	voiceMemos        []VoiceMemo // Recent Voice Memos recordings listed (protected by uiMutex)
+	meeting           *MeetingRecording // Meeting recording the selected file belongs to, when it has participant tracks
+	savedFilePath     string    // Last file saved, offered to reveal or open (protected by uiMutex)
+	retranscribeIndex int       // Segment being fixed (-1 = none, protected by uiMutex)
+	alternativesIndex int       // Segment the alternative readings are for (-1 = none, protected by uiMutex)
+	alternatives      []string  // Alternative readings offered (protected by uiMutex)
+	translationIndex  int       // Segment whose translation is being edited (-1 = none, protected by uiMutex)
+	corrections       []SegmentCorrection // Proposed spelling corrections under review (protected by uiMutex)
+	correctionIndex   int                 // Correction being reviewed (protected by uiMutex)
+	acceptedCorrections []SegmentCorrection // Approved so far, applied when the review ends (protected by uiMutex)
+	translationReview *TranslationReview  // Translations flagged by the back-translation check (protected by uiMutex)
+	translationFlagIndex int              // Flagged translation being reviewed (protected by uiMutex)
+	glossaryMisses       []GlossaryMiss   // Glossary terms the translation didn't use (protected by uiMutex)
+	player            *SegmentPlayer // Plays single segments for checking
+	playingIndex      int       // Segment being played (-1 = none, protected by uiMutex)
+	transcriptFontSize float32  // In Sp
+	initialFocusSet   bool      // Keyboard focus starts on the file button
+	windowWidth       unit.Dp   // Current window size (saved on exit)
+	windowHeight      unit.Dp
+
+	// Options shared with the CLI (config file and environment)
+	config AppConfig
+
+	// Connected export integrations (from -login)
+	integrations Integrations
+
+	// Persisted user preferences, shared by all windows
+	settings *SharedSettings
 
 	// Status (protected by uiMutex)
 	statusText      string
 	timingText      string
+	telemetryText   string // System load while transcribing, shown in place of the timing
 	progressVisible bool
 	uiMutex         sync.RWMutex // Protects statusText, timingText, outputEditor text
 }
 
-// NewGioApp creates a new Gio application
-func NewGioApp(w *app.Window) *GioApp {
+// SharedSettings are the user settings shared by all open session windows
+type SharedSettings struct {
+	Settings
+	sync.Mutex
+}
+
+// NewGioApp creates the session shown in window w. warmStart preloads the
+// default model (when enabled), which only the first window needs to do.
+func NewGioApp(w *app.Window, sharedSettings *SharedSettings, warmStart bool) *GioApp {
 	th := material.NewTheme()
 	// Use system fonts to get Hebrew support on macOS (SF Pro, Arial Hebrew, etc)
 	th.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
 
+	// A config the CLI would refuse is shown in the status line, and the defaults used
+	status := "Ready"
+	config, err := LoadConfig()
+	if err == nil {
+		if err = config.Validate(); err != nil {
+			config = DefaultConfig()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		status = fmt.Sprintf("Error in the configuration, using the defaults: %v", err)
+	}
+	SetFFmpegPaths(config.FFmpegPath, config.FFprobePath)
+	SetDownloadOptions(config.Download)
+	if err := SetNetworkOptions(config.Network); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	SetSpeakerLabels(config.SpeakerLabels)
+	if err := SetExportScript(config.Script); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	sharedSettings.Lock()
+	settings := sharedSettings.Settings
+	sharedSettings.Unlock()
+	SetModelDir(config.ModelStorageDir(settings))
+	integrations, err := LoadIntegrations()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	presets, err := LoadPresets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
 	gioApp := &GioApp{
 		window:            w,
 		theme:             th,
 		fileLabel:         &widget.Label{},
 		browseBtn:         &widget.Clickable{},
+		newWindowBtn:      &widget.Clickable{},
+		demoBtn:           &widget.Clickable{},
+		addPartBtn:        &widget.Clickable{},
+		joinFolderBtn:     &widget.Clickable{},
+		recordBtn:         &widget.Clickable{},
+		recordDevice:      &widget.Enum{},
+		recordLive:        &widget.Bool{},
+		recordStartBtn:    &widget.Clickable{},
+		recordPauseBtn:    &widget.Clickable{},
+		recordStopBtn:     &widget.Clickable{},
+		recordCloseBtn:    &widget.Clickable{},
 		transcribeBtn:     &widget.Clickable{},
 		stopBtn:           &widget.Clickable{},
 		saveBtn:           &widget.Clickable{},
+		exportAllBtn:      &widget.Clickable{},
+		perSpeakerBtn:     &widget.Clickable{},
+		vttTracksBtn:      &widget.Clickable{},
+		minutesBtn:        &widget.Clickable{},
+		presentBtn:        &widget.Clickable{},
+		revealBtn:         &widget.Clickable{},
+		openFileBtn:       &widget.Clickable{},
+		shareBtn:          &widget.Clickable{},
+		downloadPauseBtn:  &widget.Clickable{},
+		googleDocsBtn:     &widget.Clickable{},
+		notionBtn:         &widget.Clickable{},
 		modelList:         &widget.Enum{},
+		addModelBtn:       &widget.Clickable{},
+		customModelName:   &widget.Editor{SingleLine: true},
+		customModelSource: &widget.Enum{Value: customModelFromFile},
+		customModelBrowseBtn: &widget.Clickable{},
+		customModelRepo:   &widget.Editor{SingleLine: true},
+		customModelFile:   &widget.Editor{SingleLine: true},
+		saveCustomModelBtn:   &widget.Clickable{},
+		cancelCustomModelBtn: &widget.Clickable{},
+		customModels:      CustomModelIDs(),
 		formatList:        &widget.Enum{},
-		enableTranslation: &widget.Bool{},
+		presetList:        &widget.Enum{},
+		presetNameEditor:  &widget.Editor{SingleLine: true},
+		savePresetBtn:     &widget.Clickable{},
+		enableTranslation: &widget.Bool{Value: config.Translate},
 		translateLangList: &widget.Enum{},
-		keepOriginal:      &widget.Bool{Value: true}, // Default to keeping original
+		checkTranslation:  &widget.Bool{Value: settings.CheckTranslation},
+		transliterate:     &widget.Bool{Value: config.Transliteration.Method != ""},
+		displayMode:       &widget.Enum{Value: config.DisplayMode},
+		numberStyle:       &widget.Enum{Value: config.Numbers.Style},
+		normalizeDates:    &widget.Bool{Value: config.Numbers.Dates},
+		splitChannels:     &widget.Bool{Value: config.ChannelMode == ChannelModeSplit},
+		participantTracks: &widget.Bool{Value: true},
+		redact:            &widget.Bool{Value: config.Redact.Enabled},
+		consensus:         &widget.Bool{Value: config.Consensus != ""},
+		preloadModel:      &widget.Bool{Value: settings.PreloadModel},
+		compactLayout:     &widget.Bool{Value: settings.UIDensity == UIDensityCompact},
+		watchClipboard:    &widget.Bool{Value: settings.WatchClipboard},
+		coreMLEncoder:     &widget.Bool{Value: settings.CoreMLEncoder},
+		coreMLAvailable:   CoreMLAvailable(),
+		checkModelUpdates: &widget.Bool{Value: settings.CheckModelUpdates},
+		checkAppUpdates:   &widget.Bool{Value: settings.CheckAppUpdates},
+		appUpdateBtn:      &widget.Clickable{},
+		appNotesBtn:       &widget.Clickable{},
+		dismissAppUpdateBtn: &widget.Clickable{},
+		modelDirBtn:       &widget.Clickable{},
+		updateModelsBtn:   &widget.Clickable{},
+		dismissUpdatesBtn: &widget.Clickable{},
+		redownloadBtn:     &widget.Clickable{},
+		dismissDamagedBtn: &widget.Clickable{},
+		onboardingModelList: &widget.Enum{},
+		onboardingActionBtn: &widget.Clickable{},
+		onboardingBackBtn:   &widget.Clickable{},
+		onboardingNextBtn:   &widget.Clickable{},
+		onboardingSkipBtn:   &widget.Clickable{},
+		onboardingStep:    -1,
+		acceptOfferBtn:    &widget.Clickable{},
+		dismissOfferBtn:   &widget.Clickable{},
+		reuseTranscriptBtn:  &widget.Clickable{},
+		dismissDuplicateBtn: &widget.Clickable{},
+		historyBtn:          &widget.Clickable{},
+		voiceMemosBtn:       &widget.Clickable{},
+		closeVoiceMemosBtn:  &widget.Clickable{},
+		closeHistoryBtn:     &widget.Clickable{},
+		clipboardWatcher:  &ClipboardWatcher{copiedFile: copiedFinderFile},
+		fontSmallerBtn:    &widget.Clickable{},
+		fontLargerBtn:     &widget.Clickable{},
+		lineSpacingList:   &widget.Enum{Value: lineSpacingValue(settings.TranscriptLineSpacing())},
+		monospace:         &widget.Bool{Value: settings.MonospaceTranscript},
+		showTimestamps:    &widget.Bool{Value: settings.ShowTimestamps},
+		wallClock:         &widget.Bool{},
+		startTimeEditor:   &widget.Editor{SingleLine: true},
+		speakerStats:      &widget.Bool{Value: settings.ShowSpeakerStats},
+		textStats:         &widget.Bool{Value: settings.ShowTextStats},
+		showEvents:        &widget.Bool{Value: !settings.HideEvents},
+		transcriptFontSize: settings.TranscriptTextSize(),
+		fixSegmentBtn:     &widget.Clickable{},
+		retranscribeModel:       &widget.Enum{},
+		retranscribeBeam:        &widget.Editor{SingleLine: true, Filter: "0123456789"},
+		retranscribePrompt:      &widget.Editor{SingleLine: true},
+		retranscribeRunBtn:      &widget.Clickable{},
+		retranscribeCancelBtn:   &widget.Clickable{},
+		retranscribeIndex:       -1,
+		alternativeBtns:         make([]widget.Clickable, 1),
+		dismissAlternativesBtn:  &widget.Clickable{},
+		transcriptPointer:       new(int),
+		alternativesIndex:       -1,
+		spellCheckBtn:           &widget.Clickable{},
+		playSegmentBtn:          &widget.Clickable{},
+		retranscribePlayBtn:     &widget.Clickable{},
+		alternativesPlayBtn:     &widget.Clickable{},
+		correctionPlayBtn:       &widget.Clickable{},
+		translationPlayBtn:      &widget.Clickable{},
+		player:                  &SegmentPlayer{},
+		playingIndex:            -1,
+		acceptCorrectionBtn:     &widget.Clickable{},
+		skipCorrectionBtn:       &widget.Clickable{},
+		acceptAllCorrectionsBtn: &widget.Clickable{},
+		cancelCorrectionsBtn:    &widget.Clickable{},
+		editTranslationBtn:      &widget.Clickable{},
+		translationEditor:       &widget.Editor{SingleLine: true, Submit: true},
+		saveTranslationBtn:      &widget.Clickable{},
+		cancelTranslationBtn:    &widget.Clickable{},
+		translationIndex:        -1,
+		flagPlayBtn:             &widget.Clickable{},
+		editFlaggedBtn:          &widget.Clickable{},
+		nextFlagBtn:             &widget.Clickable{},
+		closeFlagsBtn:           &widget.Clickable{},
+		fromEditor:        &widget.Editor{SingleLine: true, Submit: true},
+		toEditor:          &widget.Editor{SingleLine: true, Submit: true},
+		maxSegmentEditor:  &widget.Editor{SingleLine: true, Filter: "0123456789"},
 		ivritLink:         &widget.Clickable{},
 		patreonLink:       &widget.Clickable{},
 		creditsLink:       &widget.Clickable{},
 		outputEditor:      &widget.Editor{ReadOnly: true, SingleLine: false},
-		statusText:        "Ready",
+		statusText:        status,
+		config:            config,
+		integrations:      integrations,
+		presets:           presets,
+		settings:          sharedSettings,
+	}
+
+	// Set defaults: the last used model wins over the configured one
+	gioApp.modelList.Value = config.Model
+	if settings.DefaultModel != "" {
+		gioApp.modelList.Value = settings.DefaultModel
+	}
+	gioApp.formatList.Value = config.Format
+	if config.Format == FormatAll {
+		gioApp.formatList.Value = "text" // Export All writes the others
+	}
+	gioApp.translateLangList.Value = config.TargetLang
+	maxSegmentChars := config.Decode.MaxSegmentChars // The configured length wins over the last one used
+	if maxSegmentChars == 0 {
+		maxSegmentChars = settings.MaxSegmentChars
+	}
+	if maxSegmentChars > 0 {
+		gioApp.maxSegmentEditor.SetText(strconv.Itoa(maxSegmentChars))
+	}
+
+	// First launch: walk through the setup
+	if warmStart && NeedsOnboarding(settings) {
+		gioApp.onboardingStep = onboardingModel
+		gioApp.onboardingModelList.Value = gioApp.modelList.Value
 	}
 
-	// Set defaults
-	gioApp.modelList.Value = "turbo"
-	gioApp.formatList.Value = "text"
-	gioApp.translateLangList.Value = "en" // Default to English
+	// Warm start: load the default model in the background
+	if warmStart && settings.PreloadModel {
+		go gioApp.preloadDefaultModel(gioApp.modelList.Value)
+	}
+	if warmStart && settings.ModelUpdateCheckDue(time.Now()) {
+		go gioApp.checkForModelUpdates()
+	}
+	if warmStart && settings.AppUpdateCheckDue(time.Now()) {
+		go gioApp.checkForAppUpdate()
+	}
 
 	return gioApp
 }
 
+// updateSettings applies a change to the user settings and persists it
+func (a *GioApp) updateSettings(change func(s *Settings)) {
+	a.settings.Lock()
+	defer a.settings.Unlock()
+
+	change(&a.settings.Settings)
+	if err := SaveSettings(a.settings.Settings); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to save settings: %v\n", err)
+	}
+}
+
+// space scales a margin or gap for the chosen UI density
+func (a *GioApp) space(dp unit.Dp) unit.Dp {
+	if a.compactLayout.Value {
+		return dp * compactSpacingScale
+	}
+	return dp
+}
+
+// buttonInset returns the padding inside buttons for the chosen UI density
+func (a *GioApp) buttonInset() layout.Inset {
+	return layout.Inset{Top: a.space(10), Bottom: a.space(10), Left: a.space(12), Right: a.space(12)}
+}
+
+// rememberWindowSize records the current window size (in Dp) so it can be restored next launch
+func (a *GioApp) rememberWindowSize(size image.Point, metric unit.Metric) {
+	a.windowWidth = metric.PxToDp(size.X)
+	a.windowHeight = metric.PxToDp(size.Y)
+}
+
+// saveWindowSize persists the last window size
+func (a *GioApp) saveWindowSize() {
+	if a.windowWidth <= 0 || a.windowHeight <= 0 {
+		return
+	}
+	width, height := float32(a.windowWidth), float32(a.windowHeight)
+	a.updateSettings(func(s *Settings) {
+		s.WindowWidth = width
+		s.WindowHeight = height
+	})
+}
+
+// checkForModelUpdates checks the downloaded models for updates in the background,
+// offering the ones found
+func (a *GioApp) checkForModelUpdates() {
+	updates := CheckModelUpdates(func(msg string) {
+		fmt.Fprintln(os.Stderr, msg)
+	})
+	a.updateSettings(func(s *Settings) { s.LastModelUpdateCheck = time.Now() })
+
+	a.uiMutex.Lock()
+	a.modelUpdates = updates
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// checkForAppUpdate checks GitHub for a newer release of the app in the background,
+// offering it when found
+func (a *GioApp) checkForAppUpdate() {
+	release, err := CheckAppUpdate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	a.updateSettings(func(s *Settings) { s.LastAppUpdateCheck = time.Now() })
+
+	a.uiMutex.Lock()
+	a.appUpdate = release
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// downloadAppUpdate downloads the installer of the new release for this platform
+// and shows it in the file manager, or opens the release page when there is none
+func (a *GioApp) downloadAppUpdate() {
+	a.uiMutex.RLock()
+	release := a.appUpdate
+	a.uiMutex.RUnlock()
+	if release == nil {
+		return
+	}
+	asset, ok := release.PlatformAsset()
+	if !ok {
+		openURL(release.URL)
+		return
+	}
+	if !a.claimWorker() {
+		a.setStatus("Wait for the current task to finish before downloading the update")
+		return
+	}
+	defer a.releaseWorker()
+
+	a.uiMutex.Lock()
+	a.progressVisible = true
+	a.uiMutex.Unlock()
+	path, err := DownloadAppUpdate(asset, func(msg string, percent int) {
+		a.setStatus(msg)
+	})
+	a.uiMutex.Lock()
+	a.progressVisible = false
+	if err == nil {
+		a.appUpdate = nil
+	}
+	a.uiMutex.Unlock()
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Failed to download the update: %v", err))
+		return
+	}
+	a.setStatus(fmt.Sprintf("Downloaded %s to %s; quit the app and install it", release.Version, path))
+	if err := RevealFile(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// updateModels downloads the updated models offered. Each model is replaced only
+// once its new file is verified; loaded models are used until the app restarts.
+func (a *GioApp) updateModels() {
+	if !a.claimWorker() {
+		a.setStatus("Wait for the current task to finish before updating models")
+		return
+	}
+	defer a.releaseWorker()
+
+	a.uiMutex.Lock()
+	updates := a.modelUpdates
+	a.modelUpdates = nil
+	a.progressVisible = true
+	a.uiMutex.Unlock()
+
+	var failed []ModelUpdate
+	for _, u := range updates {
+		err := UpdateModel(u.ModelID, func(msg string, percent int) {
+			a.setStatus(msg)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to update the %s model: %v\n", u.ModelID, err)
+			a.setStatus(fmt.Sprintf("Failed to update the %s model: %v", u.ModelID, err))
+			failed = append(failed, u)
+		}
+	}
+
+	a.uiMutex.Lock()
+	a.modelUpdates = failed // Offered again
+	a.progressVisible = false
+	a.uiMutex.Unlock()
+	if len(failed) == 0 {
+		a.setStatus("Models updated; a loaded model is replaced after restarting the app")
+	}
+}
+
+// redownloadModel downloads the damaged model file again
+func (a *GioApp) redownloadModel() {
+	if !a.claimWorker() {
+		a.setStatus("Wait for the current task to finish before downloading the model")
+		return
+	}
+	defer a.releaseWorker()
+
+	a.uiMutex.Lock()
+	damaged := a.damagedModel
+	a.damagedModel = nil
+	a.progressVisible = true
+	a.uiMutex.Unlock()
+	if damaged == nil {
+		return
+	}
+
+	err := RedownloadModel(damaged.ModelID, func(msg string, percent int) {
+		a.setStatus(msg)
+	})
+	a.uiMutex.Lock()
+	a.progressVisible = false
+	a.uiMutex.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to download the %s model again: %v\n", damaged.ModelID, err)
+		a.setStatus(fmt.Sprintf("Failed to download the %s model: %v", damaged.ModelID, err))
+		return
+	}
+	a.setStatus(fmt.Sprintf("Downloaded the %s model again; transcribe to use it", damaged.ModelID))
+}
+
+// refreshOnboarding checks what the current setup step needs
+func (a *GioApp) refreshOnboarding() {
+	a.uiMutex.RLock()
+	step := a.onboardingStep
+	modelID := a.onboardingModel
+	a.uiMutex.RUnlock()
+
+	var info string
+	var ollama OllamaStatus
+	ready := false
+	switch step {
+	case onboardingModel:
+		if path, err := FindLocalModel(modelID); err == nil {
+			info = fmt.Sprintf("The %s model is downloaded (%s).", modelID, path)
+			ready = true
+		} else {
+			info = "turbo is fast and accurate enough for most recordings; large-v3 is the most accurate, but slower and needs more memory. The model is downloaded once, which takes a while: it is several gigabytes."
+		}
+	case onboardingFFmpeg:
+		if err := CheckFFmpeg(); err != nil {
+			info = err.Error()
+		} else {
+			info = "ffmpeg is installed: audio and video files of all common formats can be transcribed."
+			ready = true
+		}
+	case onboardingOllama:
+		translator := NewMistralTranslator()
+		ollama = translator.Status()
+		info = ollamaInstallHint(ollama, translator.model)
+		ready = ollama.HasModel
+	case onboardingSample:
+		info = "Transcribe a short sample clip to check that everything works. Then choose your own recording above."
+		ready = true
+	default:
+		return
+	}
+
+	a.uiMutex.Lock()
+	if a.onboardingStep == step { // Not moved on meanwhile
+		a.onboardingInfo = info
+		a.onboardingReady = ready
+		a.ollamaStatus = ollama
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// setOnboardingStep moves the first-run setup to a step, or closes it for good
+// past the last one
+func (a *GioApp) setOnboardingStep(step int) {
+	a.uiMutex.Lock()
+	if step >= onboardingSteps {
+		step = -1
+	}
+	a.onboardingStep = step
+	a.onboardingInfo = ""
+	a.onboardingReady = false
+	a.onboardingModel = "" // Checked again on the model step
+	a.uiMutex.Unlock()
+
+	if step < 0 {
+		go a.updateSettings(func(s *Settings) { s.OnboardingDone = true })
+		return
+	}
+	go a.refreshOnboarding()
+}
+
+// onboardingAction does what the current setup step needs
+func (a *GioApp) onboardingAction() {
+	a.uiMutex.RLock()
+	step := a.onboardingStep
+	ready := a.onboardingReady
+	ollama := a.ollamaStatus
+	a.uiMutex.RUnlock()
+
+	switch {
+	case step == onboardingModel && !ready:
+		if !a.claimWorker() {
+			a.setStatus("Wait for the current task to finish before downloading a model")
+			return
+		}
+		modelID := a.onboardingModelList.Value
+		a.uiMutex.Lock()
+		a.progressVisible = true
+		a.uiMutex.Unlock()
+		_, err := GetModelPath(modelID, func(msg string, percent int) {
+			a.setStatus(msg)
+		})
+		a.uiMutex.Lock()
+		a.progressVisible = false
+		a.uiMutex.Unlock()
+		a.releaseWorker()
+		if err != nil {
+			a.setStatus(fmt.Sprintf("Failed to download the %s model: %v", modelID, err))
+		} else {
+			a.modelList.Value = modelID
+			a.updateSettings(func(s *Settings) { s.DefaultModel = modelID })
+			a.setStatus(fmt.Sprintf("Downloaded the %s model", modelID))
+		}
+	case step == onboardingOllama && ollama.Running && !ollama.HasModel:
+		if !a.claimWorker() {
+			a.setStatus("Wait for the current task to finish before downloading the translation model")
+			return
+		}
+		err := NewMistralTranslator().PullModel(func(msg string, percent int) {
+			if percent >= 0 {
+				msg = fmt.Sprintf("%s (%d%%)", msg, percent)
+			}
+			a.setStatus(msg)
+		})
+		a.releaseWorker()
+		if err != nil {
+			a.setStatus(fmt.Sprintf("Failed to download the translation model: %v", err))
+		} else {
+			a.setStatus("Downloaded the translation model")
+		}
+	case step == onboardingSample:
+		a.transcribeSample()
+		return
+	}
+	a.refreshOnboarding()
+}
+
+// transcribeSample transcribes the bundled sample clip with the chosen model
+func (a *GioApp) transcribeSample() {
+	if a.workerBusy() {
+		a.setStatus("Wait for the current task to finish before transcribing the sample")
+		return
+	}
+	path, err := writeSampleClip()
+	if err != nil {
+		a.setStatus(err.Error())
+		return
+	}
+	a.setAudioFile(path)
+	a.startTranscription()
+}
+
+// preloadDefaultModel loads the model selected at startup into the model cache in
+// the background. The caller reads modelID on the UI goroutine, which owns modelList.
+func (a *GioApp) preloadDefaultModel(modelID string) {
+	preloading := fmt.Sprintf("Preloading %s model...", modelID)
+	a.uiMutex.Lock()
+	if a.statusText == "Ready" { // Keep a configuration error shown
+		a.statusText = preloading
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+
+	err := PreloadModel(modelID)
+
+	// Don't clobber the status of a transcription that started meanwhile
+	if a.workerBusy() {
+		return
+	}
+
+	a.uiMutex.Lock()
+	var damaged *CorruptModelError
+	status := "Ready"
+	if errors.As(err, &damaged) {
+		a.damagedModel = damaged
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Model preload skipped: %v\n", err)
+	} else {
+		status = fmt.Sprintf("Ready (%s model loaded)", modelID)
+	}
+	if a.statusText == preloading {
+		a.statusText = status
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
 // Layout lays out the UI
 func (a *GioApp) Layout(gtx layout.Context) layout.Dimensions {
-	return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+	a.pollClipboard(gtx)
+	a.handleShortcuts(gtx)
+
+	return layout.UniformInset(a.space(16)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{
 			Axis:    layout.Vertical,
 			Spacing: layout.SpaceSides,
 		}.Layout(gtx,
+			// First-run setup
+			layout.Rigid(a.layoutOnboarding),
+
 			// File selection
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, a.layoutFileSelection)
+				return layout.Inset{Bottom: a.space(12)}.Layout(gtx, a.layoutFileSelection)
 			}),
 
+			// Recording from a microphone
+			layout.Rigid(a.layoutRecording),
+
 			// Options
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, a.layoutOptions)
+				return layout.Inset{Bottom: a.space(12)}.Layout(gtx, a.layoutOptions)
 			}),
 
+			// Privacy notice when recordings are transcribed in the cloud
+			layout.Rigid(a.layoutCloudNotice),
+
+			// Offer to transcribe media copied to the clipboard
+			layout.Rigid(a.layoutClipboardOffer),
+
+			// Offer to reuse the transcript of the same recording under another name
+			layout.Rigid(a.layoutDuplicateOffer),
+
+			// Completed transcriptions, to reopen or export again
+			layout.Rigid(a.layoutHistory),
+
+			// Recent Voice Memos recordings, to transcribe in one click
+			layout.Rigid(a.layoutVoiceMemos),
+
+			// A newer release of the app
+			layout.Rigid(a.layoutAppUpdate),
+
+			// Updated models published by ivrit.ai
+			layout.Rigid(a.layoutModelUpdates),
+
+			// A damaged model file, e.g. from an interrupted download
+			layout.Rigid(a.layoutDamagedModel),
+
+			// Adding a custom model
+			layout.Rigid(a.layoutAddModel),
+
+			// Settings for re-transcribing one segment
+			layout.Rigid(a.layoutRetranscribe),
+
+			// Alternative readings of one segment
+			layout.Rigid(a.layoutAlternatives),
+
+			// Proposed spelling corrections awaiting approval
+			layout.Rigid(a.layoutCorrections),
+
+			// Translations flagged by the back-translation check
+			layout.Rigid(a.layoutTranslationReview),
+
+			// Editing the translation of one segment
+			layout.Rigid(a.layoutTranslationEdit),
+
 			// Controls
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Bottom: unit.Dp(12)}.Layout(gtx, a.layoutControls)
+				return layout.Inset{Bottom: a.space(12)}.Layout(gtx, a.layoutControls)
 			}),
 
 			// Status
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, a.layoutStatus)
+				return layout.Inset{Bottom: a.space(8)}.Layout(gtx, a.layoutStatus)
 			}),
 
+			// Word and character counts
+			layout.Rigid(a.layoutTextStats),
+
 			// Output (expands)
 			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 				return a.layoutOutput(gtx)
@@ -134,7 +924,7 @@ func (a *GioApp) Layout(gtx layout.Context) layout.Dimensions {
 
 			// Credits
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, a.layoutCredits)
+				return layout.Inset{Top: a.space(8)}.Layout(gtx, a.layoutCredits)
 			}),
 		)
 	})
@@ -145,6 +935,24 @@ func (a *GioApp) layoutFileSelection(gtx layout.Context) layout.Dimensions {
 	for a.browseBtn.Clicked(gtx) {
 		go a.selectFile()
 	}
+	for a.newWindowBtn.Clicked(gtx) {
+		openSessionWindow(a.settings, false)
+	}
+	for a.demoBtn.Clicked(gtx) {
+		go a.transcribeSample()
+	}
+	for a.addPartBtn.Clicked(gtx) {
+		go a.addRecordingPart()
+	}
+	for a.joinFolderBtn.Clicked(gtx) {
+		go a.joinFolder()
+	}
+	for a.recordBtn.Clicked(gtx) {
+		go a.openRecording()
+	}
+	for a.voiceMemosBtn.Clicked(gtx) {
+		go a.openVoiceMemos()
+	}
 	
 	return layout.Flex{
 		Axis:      layout.Horizontal,
@@ -157,96 +965,328 @@ func (a *GioApp) layoutFileSelection(gtx layout.Context) layout.Dimensions {
 				fileName = "No file selected"
 			} else {
 				fileName = filepath.Base(fileName)
+				if len(a.partPaths) > 1 {
+					fileName = filepath.Base(a.partPaths[0])
+				}
 				if len(fileName) > 50 {
 					fileName = fileName[:50] + "..."
 				}
+				if len(a.partPaths) > 1 {
+					fileName += fmt.Sprintf(" + %d more parts", len(a.partPaths)-1)
+				}
 			}
 			label := material.Label(a.theme, unit.Sp(14), fileName)
 			return label.Layout(gtx)
 		}),
-		layout.Rigid(layout.Spacer{Width: unit.Dp(12)}.Layout),
+		layout.Rigid(layout.Spacer{Width: a.space(12)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			btn := material.Button(a.theme, a.browseBtn, "Choose audio file to transcribe")
 			return btn.Layout(gtx)
 		}),
-	)
-}
-
-func (a *GioApp) layoutOptions(gtx layout.Context) layout.Dimensions {
-	return layout.Flex{
-		Axis:    layout.Vertical,
-		Spacing: layout.SpaceStart,
-	}.Layout(gtx,
-		// Row 1: Model and Format
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return layout.Flex{
-				Axis:    layout.Horizontal,
-				Spacing: layout.SpaceStart,
-			}.Layout(gtx,
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.Label(a.theme, unit.Sp(14), "Model:").Layout(gtx)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.modelList, "large-v3", "large-v3").Layout(gtx)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.modelList, "turbo", "turbo").Layout(gtx)
-				}),
-				layout.Rigid(layout.Spacer{Width: unit.Dp(24)}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.Label(a.theme, unit.Sp(14), "Format:").Layout(gtx)
-				}),
-				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.formatList, "text", "text").Layout(gtx)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.formatList, "json", "json").Layout(gtx)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.formatList, "srt", "srt").Layout(gtx)
-				}),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.RadioButton(a.theme, a.formatList, "vtt", "vtt").Layout(gtx)
-				}),
-			)
+			if a.audioFilePath != "" {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.demoBtn, "Try Demo")
+				return describedButton(gtx, a.theme, btn, "Transcribe a short sample clip bundled with the app")
+			})
 		}),
-		// Row 2: Translation (via Mistral 8B)
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			return layout.Flex{
-				Axis:    layout.Horizontal,
-				Spacing: layout.SpaceStart,
-				Alignment: layout.Middle,
-			}.Layout(gtx,
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return material.CheckBox(a.theme, a.enableTranslation, "Enable Translation").Layout(gtx)
-				}),
-				layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					if a.enableTranslation.Value {
-						return layout.Flex{
-							Axis:    layout.Horizontal,
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				if a.audioFilePath == "" {
+					btn := material.Button(a.theme, a.joinFolderBtn, "Join Folder...")
+					return describedButton(gtx, a.theme, btn, "Transcribe the audio files of a folder, e.g. the tracks of an audio CD, as one recording")
+				}
+				btn := material.Button(a.theme, a.addPartBtn, "Add Part...")
+				return describedButton(gtx, a.theme, btn, "Add the next file of this recording, transcribed as one with continuous timestamps")
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.recordBtn, "Record...")
+				return describedButton(gtx, a.theme, btn, "Record from a microphone and transcribe the recording")
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if runtime.GOOS != "darwin" {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.voiceMemosBtn, "Voice Memos...")
+				return describedButton(gtx, a.theme, btn, "List the recent recordings of the Voice Memos app to transcribe one")
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.meeting == nil {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := fmt.Sprintf("Per participant (%d)", len(a.meeting.Tracks))
+				return material.CheckBox(a.theme, a.participantTracks, label).Layout(gtx)
+			})
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.newWindowBtn, "New Window")
+			return btn.Layout(gtx)
+		}),
+	)
+}
+
+// recordMeterInterval is how often the level meter of a recording is redrawn
+const recordMeterInterval = 50 * time.Millisecond
+
+// layoutRecording shows the panel for recording from a microphone, when open: the
+// inputs to choose from, then the level and length of the recording in progress
+func (a *GioApp) layoutRecording(gtx layout.Context) layout.Dimensions {
+	for a.recordStartBtn.Clicked(gtx) {
+		go a.startRecording()
+	}
+	for a.recordPauseBtn.Clicked(gtx) {
+		a.uiMutex.RLock()
+		rec := a.recorder
+		a.uiMutex.RUnlock()
+		if rec != nil && rec.Paused() {
+			rec.Resume()
+		} else if rec != nil {
+			rec.Pause()
+		}
+	}
+	for a.recordStopBtn.Clicked(gtx) {
+		go a.stopRecording(true)
+	}
+	for a.recordCloseBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.recordingOpen = false
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	open := a.recordingOpen
+	devices := a.recordingDevices
+	rec := a.recorder
+	live := a.liveTranscriber != nil
+	partial := a.livePartial
+	a.uiMutex.RUnlock()
+	if !open {
+		return layout.Dimensions{}
+	}
+
+	button := func(clickable *widget.Clickable, label string, primary bool) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, clickable, label)
+			btn.Inset = a.buttonInset()
+			if primary {
+				btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+			}
+			return btn.Layout(gtx)
+		})
+	}
+
+	var row []layout.FlexChild
+	if rec == nil {
+		choices := make([]layout.FlexChild, len(devices))
+		for i, device := range devices {
+			name := device.Name
+			if device.Loopback && !strings.Contains(strings.ToLower(name), "system audio") {
+				name += " (system audio)"
+			}
+			choices[i] = layout.Rigid(material.RadioButton(a.theme, a.recordDevice, strconv.Itoa(i), name).Layout)
+		}
+		row = append(row,
+			layout.Rigid(material.Label(a.theme, unit.Sp(14), "Input:").Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return accessibleGroup(gtx, "Input to record from", func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, choices...)
+				})
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			layout.Rigid(material.CheckBox(a.theme, a.recordLive, "Transcribe live").Layout),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			button(a.recordStartBtn, "Start Recording", true),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			button(a.recordCloseBtn, "Close", false),
+		)
+	} else {
+		// Keep the meter moving while recording
+		gtx.Execute(op.InvalidateCmd{At: gtx.Now.Add(recordMeterInterval)})
+		state, pauseLabel, stopLabel := "Recording", "Pause", "Stop & Transcribe"
+		if rec.Paused() {
+			state, pauseLabel = "Paused", "Resume"
+		}
+		if live {
+			state, stopLabel = "Transcribing live", "Stop"
+		}
+		row = append(row,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return a.layoutLevelMeter(gtx, rec.Level())
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				label := fmt.Sprintf("%s %s to %s", state, formatClockDuration(rec.Duration().Seconds()), filepath.Base(rec.Path))
+				return material.Label(a.theme, unit.Sp(14), label).Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			button(a.recordPauseBtn, pauseLabel, false),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			button(a.recordStopBtn, stopLabel, true),
+		)
+	}
+
+	// Where no input carries the system's output, how to add one
+	hint := ""
+	if rec == nil && !slices.ContainsFunc(devices, func(d RecordingDevice) bool { return d.Loopback }) {
+		hint = LoopbackHint()
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Record from a microphone or the system audio", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, row...)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if hint == "" {
+						return layout.Dimensions{}
+					}
+					return material.Label(a.theme, unit.Sp(12), hint).Layout(gtx)
+				}),
+				// Live text not yet settled, which may still change
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !live || partial == "" {
+						return layout.Dimensions{}
+					}
+					label := material.Label(a.theme, unit.Sp(14), partial+" …")
+					label.Color = color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+					return label.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+// layoutLevelMeter draws the input level of a recording as a bar, turning yellow and
+// then red as it nears full scale
+func (a *GioApp) layoutLevelMeter(gtx layout.Context, level float32) layout.Dimensions {
+	size := image.Pt(gtx.Dp(120), gtx.Dp(10))
+	paint.FillShape(gtx.Ops, color.NRGBA{R: 220, G: 220, B: 220, A: 255}, clip.Rect{Max: size}.Op())
+	fill := color.NRGBA{R: 52, G: 199, B: 89, A: 255}
+	if level > 0.9 { // Above -6 dBFS
+		fill = color.NRGBA{R: 255, G: 59, B: 48, A: 255}
+	} else if level > 0.75 { // Above -15 dBFS
+		fill = color.NRGBA{R: 255, G: 204, B: 0, A: 255}
+	}
+	paint.FillShape(gtx.Ops, fill, clip.Rect{Max: image.Pt(int(float32(size.X)*level), size.Y)}.Op())
+	return layout.Dimensions{Size: size}
+}
+
+func (a *GioApp) layoutOptions(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{
+		Axis:    layout.Vertical,
+		Spacing: layout.SpaceStart,
+	}.Layout(gtx,
+		// Row 0: Presets
+		layout.Rigid(a.layoutPresets),
+		// Row 1: Model and Format
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:    layout.Horizontal,
+				Spacing: layout.SpaceStart,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "Model:").Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return accessibleGroup(gtx, "Transcription model", func(gtx layout.Context) layout.Dimensions {
+						return a.layoutModelChoices(gtx, a.modelList)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					for a.addModelBtn.Clicked(gtx) {
+						a.uiMutex.Lock()
+						a.addingModel = true
+						a.uiMutex.Unlock()
+					}
+					btn := material.Button(a.theme, a.addModelBtn, "Add…")
+					btn.Inset = layout.UniformInset(a.space(6))
+					return describedButton(gtx, a.theme, btn, "Add a custom model")
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.consensus, "High accuracy (transcribe twice)").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(24)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "Format:").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return accessibleGroup(gtx, "Output format", func(gtx layout.Context) layout.Dimensions {
+						formats := []layout.FlexChild{
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "text", "text").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "json", "json").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "srt", "srt").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "vtt", "vtt").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "markdown", "md").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "html", "html").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "ttml", "ttml").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.formatList, "stl", "stl").Layout),
+						}
+						// The export script's format, when one is configured
+						if HasExportScript() {
+							formats = append(formats, layout.Rigid(material.RadioButton(a.theme, a.formatList, FormatCustom, "custom").Layout))
+						}
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, formats...)
+					})
+				}),
+			)
+		}),
+		// Row 2: Translation (via Mistral 8B)
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:    layout.Horizontal,
+				Spacing: layout.SpaceStart,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.transliterate, "Transliterate").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.enableTranslation, "Enable Translation").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if a.enableTranslation.Value {
+						return layout.Flex{
+							Axis:    layout.Horizontal,
 							Spacing: layout.SpaceStart,
 						}.Layout(gtx,
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 								return material.Label(a.theme, unit.Sp(14), "To:").Layout(gtx)
 							}),
-							layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
-							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								return material.RadioButton(a.theme, a.translateLangList, "en", "English").Layout(gtx)
-							}),
-							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								return material.RadioButton(a.theme, a.translateLangList, "es", "Spanish").Layout(gtx)
-							}),
+							layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								return material.RadioButton(a.theme, a.translateLangList, "fr", "French").Layout(gtx)
+								return accessibleGroup(gtx, "Translation language", a.layoutTranslationLanguages)
 							}),
+							layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								return material.RadioButton(a.theme, a.translateLangList, "de", "German").Layout(gtx)
+								return accessibleGroup(gtx, "Translation display", func(gtx layout.Context) layout.Dimensions {
+									return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+										layout.Rigid(material.RadioButton(a.theme, a.displayMode, DisplayBilingual, "Both").Layout),
+										layout.Rigid(material.RadioButton(a.theme, a.displayMode, DisplayOriginal, "Hebrew").Layout),
+										layout.Rigid(material.RadioButton(a.theme, a.displayMode, DisplayTranslation, "Translation").Layout),
+									)
+								})
 							}),
-							layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+							layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
 							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-								return material.CheckBox(a.theme, a.keepOriginal, "Keep Hebrew").Layout(gtx)
+								if a.checkTranslation.Update(gtx) {
+									check := a.checkTranslation.Value
+									go a.updateSettings(func(s *Settings) { s.CheckTranslation = check })
+								}
+								return material.CheckBox(a.theme, a.checkTranslation, "Check by back-translation").Layout(gtx)
 							}),
 						)
 					}
@@ -254,282 +1294,3551 @@ func (a *GioApp) layoutOptions(gtx layout.Context) layout.Dimensions {
 				}),
 			)
 		}),
+		// Row 3: Audio input handling and startup options
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.preloadModel.Update(gtx) {
+				preload := a.preloadModel.Value
+				go a.updateSettings(func(s *Settings) { s.PreloadModel = preload })
+			}
+			if a.checkModelUpdates.Update(gtx) {
+				check := a.checkModelUpdates.Value
+				go a.updateSettings(func(s *Settings) { s.CheckModelUpdates = check })
+				if check {
+					go a.checkForModelUpdates()
+				}
+			}
+			if a.checkAppUpdates.Update(gtx) {
+				check := a.checkAppUpdates.Value
+				go a.updateSettings(func(s *Settings) { s.CheckAppUpdates = check })
+				if check {
+					go a.checkForAppUpdate()
+				}
+			}
+			if a.compactLayout.Update(gtx) {
+				density := UIDensityComfortable
+				if a.compactLayout.Value {
+					density = UIDensityCompact
+				}
+				go a.updateSettings(func(s *Settings) { s.UIDensity = density })
+			}
+			if a.watchClipboard.Update(gtx) {
+				watch := a.watchClipboard.Value
+				if !watch {
+					a.clipboardWatcher.Reset()
+					a.uiMutex.Lock()
+					a.clipboardOffer = ""
+					a.uiMutex.Unlock()
+				}
+				go a.updateSettings(func(s *Settings) { s.WatchClipboard = watch })
+			}
+			if a.coreMLEncoder.Update(gtx) {
+				enabled := a.coreMLEncoder.Value
+				SetCoreMLEnabled(enabled)
+				go a.updateSettings(func(s *Settings) { s.CoreMLEncoder = enabled })
+				a.setStatus("Core ML setting saved; it applies to models loaded from now on (restart to reload a loaded model)")
+			}
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Spacing:   layout.SpaceStart,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.splitChannels, "Split channels (one speaker per channel)").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.redact, "Save redacted copy").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "From:").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.layoutTimeEditor(gtx, a.fromEditor, "start", "Start time (HH:MM:SS)")
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "To:").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.layoutTimeEditor(gtx, a.toEditor, "end", "End time (HH:MM:SS)")
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "Max segment:").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.layoutTimeEditor(gtx, a.maxSegmentEditor, "chars", "Longest segment in characters, e.g. 42 for subtitles (empty = whisper's own segments)")
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.preloadModel, "Preload model at launch").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.checkModelUpdates, "Check for model updates").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.checkAppUpdates, "Check for app updates").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					for a.modelDirBtn.Clicked(gtx) {
+						go a.selectModelDir()
+					}
+					btn := material.Button(a.theme, a.modelDirBtn, "Models Folder…")
+					btn.Inset = layout.UniformInset(a.space(6))
+					return describedButton(gtx, a.theme, btn, "Choose where models are stored: "+ModelDir())
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.compactLayout, "Compact layout").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.CheckBox(a.theme, a.watchClipboard, "Watch clipboard").Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !a.coreMLAvailable {
+						return layout.Dimensions{}
+					}
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+						layout.Rigid(material.CheckBox(a.theme, a.coreMLEncoder, "Core ML encoder").Layout),
+					)
+				}),
+			)
+		}),
+		// Row 4: Transcript display
+		layout.Rigid(a.layoutDisplayOptions),
 	)
 }
 
-func (a *GioApp) layoutControls(gtx layout.Context) layout.Dimensions {
-	// Handle button clicks
-	for a.transcribeBtn.Clicked(gtx) {
-		go a.startTranscription()
-	}
-	for a.stopBtn.Clicked(gtx) {
-		go a.stopTranscription()
+// layoutPresets lays out the presets, choosing one applying its options, and
+// saving the current options as a preset
+func (a *GioApp) layoutPresets(gtx layout.Context) layout.Dimensions {
+	if a.presetList.Update(gtx) {
+		a.applyPreset(a.presetList.Value)
 	}
-	for a.saveBtn.Clicked(gtx) {
-		go a.saveTranscription()
+	for a.savePresetBtn.Clicked(gtx) {
+		go a.saveCurrentPreset(a.presetNameEditor.Text(), a.selectedOptions())
 	}
-	
-	return layout.Flex{
-		Axis:    layout.Horizontal,
-		Spacing: layout.SpaceStart,
-	}.Layout(gtx,
-		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Button(a.theme, a.transcribeBtn, "Transcribe")
-			btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
-			return btn.Layout(gtx)
-		}),
-		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+	a.uiMutex.RLock()
+	presets := a.presets
+	a.uiMutex.RUnlock()
+
+	children := []layout.FlexChild{
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Button(a.theme, a.stopBtn, "Stop")
-			btn.Background = color.NRGBA{R: 220, G: 53, B: 69, A: 255}
-			return btn.Layout(gtx)
+			return material.Label(a.theme, unit.Sp(14), "Preset:").Layout(gtx)
 		}),
-		layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Button(a.theme, a.saveBtn, "Save As...")
-			return btn.Layout(gtx)
+			return accessibleGroup(gtx, "Preset", func(gtx layout.Context) layout.Dimensions {
+				choices := make([]layout.FlexChild, len(presets))
+				for i, preset := range presets {
+					choices[i] = layout.Rigid(material.RadioButton(a.theme, a.presetList, preset.Name, preset.Name).Layout)
+				}
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, choices...)
+			})
 		}),
-	)
-}
-
-func (a *GioApp) layoutStatus(gtx layout.Context) layout.Dimensions {
-	// Read UI state with lock to prevent data races
-	a.uiMutex.RLock()
-	statusText := a.statusText
-	timingText := a.timingText
-	a.uiMutex.RUnlock()
-
-	return layout.Flex{
-		Axis:    layout.Horizontal,
-		Spacing: layout.SpaceBetween,
-	}.Layout(gtx,
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Label(a.theme, unit.Sp(12), statusText)
-			return label.Layout(gtx)
+			gtx.Constraints.Min.X = gtx.Dp(unit.Dp(140))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			ed := material.Editor(a.theme, a.presetNameEditor, "Preset name")
+			ed.TextSize = unit.Sp(14)
+			return accessibleEditor(gtx, "Name to save the current options under as a preset", a.presetNameEditor.Text(), ed.Layout)
 		}),
-		layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Label(a.theme, unit.Sp(12), timingText)
-			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
-			return label.Layout(gtx)
+			btn := material.Button(a.theme, a.savePresetBtn, "Save Preset")
+			btn.Inset = layout.UniformInset(a.space(6))
+			return describedButton(gtx, a.theme, btn, "Save the model, format, translation, decoding and post-processing options as a preset")
 		}),
-	)
-}
-
-func (a *GioApp) layoutOutput(gtx layout.Context) layout.Dimensions {
-	// Output text area with RTL support
-	// Gio's text shaper handles RTL automatically for Hebrew text
-	ed := material.Editor(a.theme, a.outputEditor, "Transcription will appear here...")
-
-	// Right-align Hebrew text
-	a.uiMutex.RLock()
-	currentText := a.outputEditor.Text()
-	a.uiMutex.RUnlock()
-
-	if containsHebrew(currentText) {
-		ed.Editor.Alignment = text.End // Right-align for RTL Hebrew
-	} else {
-		ed.Editor.Alignment = text.Start // Left-align for LTR
 	}
+	return layout.Inset{Bottom: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
 
-	// Wrap Layout call with panic recovery to prevent crashes with large text buffers
-	var dims layout.Dimensions
-	func() {
-		defer func() {
-			if r := recover(); r != nil {
-				// If Layout panics (e.g., text buffer too large), show error in status
-				fmt.Fprintf(os.Stderr, "Warning: Text layout error: %v\n", r)
-				a.uiMutex.Lock()
-				a.statusText = "Warning: Text display limited due to size"
-				a.uiMutex.Unlock()
-				// Return empty dimensions to prevent further crashes
-				dims = layout.Dimensions{}
+// layoutTranslationLanguages lays out the translation target languages, four to a row
+func (a *GioApp) layoutTranslationLanguages(gtx layout.Context) layout.Dimensions {
+	const perRow = 4
+	var rows []layout.FlexChild
+	for start := 0; start < len(validTargetLangs); start += perRow {
+		langs := validTargetLangs[start:min(start+perRow, len(validTargetLangs))]
+		rows = append(rows, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			buttons := make([]layout.FlexChild, len(langs))
+			for i, lang := range langs {
+				buttons[i] = layout.Rigid(material.RadioButton(a.theme, a.translateLangList, lang, languageName(lang)).Layout)
 			}
-		}()
-		dims = ed.Layout(gtx)
-	}()
-
-	return dims
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, buttons...)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
 }
 
-func (a *GioApp) layoutCredits(gtx layout.Context) layout.Dimensions {
-	// Handle link clicks
-	for a.ivritLink.Clicked(gtx) {
-		go openURL("https://ivrit.ai")
+// layoutDisplayOptions lays out the transcript font size, line spacing, font and timestamp options
+func (a *GioApp) layoutDisplayOptions(gtx layout.Context) layout.Dimensions {
+	for a.fontSmallerBtn.Clicked(gtx) {
+		a.changeFontSize(-transcriptFontSizeStep)
 	}
-	for a.patreonLink.Clicked(gtx) {
-		go openURL("https://www.patreon.com/ivrit_ai")
+	for a.fontLargerBtn.Clicked(gtx) {
+		a.changeFontSize(transcriptFontSizeStep)
 	}
-	for a.creditsLink.Clicked(gtx) {
-		go openURL("https://www.ivrit.ai/he/%d7%9e%d7%99-%d7%90%d7%a0%d7%97%d7%a0%d7%95/")
+	if a.lineSpacingList.Update(gtx) {
+		spacing, _ := strconv.ParseFloat(a.lineSpacingList.Value, 32)
+		go a.updateSettings(func(s *Settings) { s.LineSpacing = float32(spacing) })
+	}
+	if a.monospace.Update(gtx) {
+		monospace := a.monospace.Value
+		go a.updateSettings(func(s *Settings) { s.MonospaceTranscript = monospace })
+	}
+	if a.showTimestamps.Update(gtx) {
+		show := a.showTimestamps.Value
+		go a.updateSettings(func(s *Settings) { s.ShowTimestamps = show })
+		a.refreshOutput()
+	}
+	if a.wallClock.Update(gtx) {
+		a.refreshOutput()
+	}
+	for {
+		ev, ok := a.startTimeEditor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.ChangeEvent); ok && a.wallClock.Value {
+			a.refreshOutput()
+		}
+	}
+	if a.showEvents.Update(gtx) {
+		hide := !a.showEvents.Value
+		go a.updateSettings(func(s *Settings) { s.HideEvents = hide })
+		a.refreshOutput()
+	}
+	if a.speakerStats.Update(gtx) {
+		show := a.speakerStats.Value
+		go a.updateSettings(func(s *Settings) { s.ShowSpeakerStats = show })
+		a.refreshOutput()
+	}
+	if a.textStats.Update(gtx) {
+		show := a.textStats.Value
+		go a.updateSettings(func(s *Settings) { s.ShowTextStats = show })
+	}
+	if a.displayMode.Update(gtx) {
+		a.refreshOutput()
+	}
+	if a.numberStyle.Update(gtx) || a.normalizeDates.Update(gtx) {
+		a.refreshOutput()
 	}
 
-	return layout.Flex{
-		Axis:      layout.Horizontal,
-		Spacing:   layout.SpaceStart,
-		Alignment: layout.Middle,
-	}.Layout(gtx,
+	children := []layout.FlexChild{
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Label(a.theme, unit.Sp(10), "Powered by ")
-			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
-			return label.Layout(gtx)
+			return material.Label(a.theme, unit.Sp(14), "Text size:").Layout(gtx)
 		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Clickable(gtx, a.ivritLink, func(gtx layout.Context) layout.Dimensions {
-				label := material.Label(a.theme, unit.Sp(10), "ivrit.ai")
-				label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255} // Blue link color
-				return label.Layout(gtx)
-			})
-			return btn
+			btn := material.Button(a.theme, a.fontSmallerBtn, "A-")
+			btn.Inset = layout.UniformInset(a.space(6))
+			return describedButton(gtx, a.theme, btn, "Smaller transcript text")
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Label(a.theme, unit.Sp(10), " | ")
-			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
-			return label.Layout(gtx)
+			return layout.Inset{Left: a.space(6), Right: a.space(6)}.Layout(gtx,
+				material.Label(a.theme, unit.Sp(14), fmt.Sprintf("%.0f", a.transcriptFontSize)).Layout)
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Clickable(gtx, a.patreonLink, func(gtx layout.Context) layout.Dimensions {
-				label := material.Label(a.theme, unit.Sp(10), "Support on Patreon")
-				label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
-				return label.Layout(gtx)
+			btn := material.Button(a.theme, a.fontLargerBtn, "A+")
+			btn.Inset = layout.UniformInset(a.space(6))
+			return describedButton(gtx, a.theme, btn, "Larger transcript text")
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(24)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), "Line spacing:").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return accessibleGroup(gtx, "Line spacing", func(gtx layout.Context) layout.Dimensions {
+				var options []layout.FlexChild
+				for _, spacing := range lineSpacingOptions {
+					value := lineSpacingValue(spacing)
+					options = append(options, layout.Rigid(material.RadioButton(a.theme, a.lineSpacingList, value, value).Layout))
+				}
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, options...)
 			})
-			return btn
 		}),
+		layout.Rigid(layout.Spacer{Width: a.space(24)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			label := material.Label(a.theme, unit.Sp(10), " | ")
-			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
-			return label.Layout(gtx)
+			return material.CheckBox(a.theme, a.monospace, "Monospace").Layout(gtx)
 		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-			btn := material.Clickable(gtx, a.creditsLink, func(gtx layout.Context) layout.Dimensions {
-				label := material.Label(a.theme, unit.Sp(10), "Credits")
-				label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
-				return label.Layout(gtx)
+			return material.CheckBox(a.theme, a.showTimestamps, "Timestamps").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.CheckBox(a.theme, a.wallClock, "Clock times from:").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(unit.Dp(140))
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			ed := material.Editor(a.theme, a.startTimeEditor, "2024-05-03 10:00")
+			ed.TextSize = unit.Sp(14)
+			return accessibleEditor(gtx, "When the recording started, for showing times of day", a.startTimeEditor.Text(), ed.Layout)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.CheckBox(a.theme, a.showEvents, "Sounds").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.CheckBox(a.theme, a.speakerStats, "Speaker stats").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.CheckBox(a.theme, a.textStats, "Text stats").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(24)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), "Numbers:").Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return accessibleGroup(gtx, "Numbers", func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+					layout.Rigid(material.RadioButton(a.theme, a.numberStyle, NumbersTranscribed, "As spoken").Layout),
+					layout.Rigid(material.RadioButton(a.theme, a.numberStyle, NumbersDigits, "123").Layout),
+					layout.Rigid(material.RadioButton(a.theme, a.numberStyle, NumbersWords, "Words").Layout),
+				)
 			})
-			return btn
 		}),
-	)
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.CheckBox(a.theme, a.normalizeDates, "Dates").Layout(gtx)
+		}),
+	}
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Spacing:   layout.SpaceStart,
+		Alignment: layout.Middle,
+	}.Layout(gtx, children...)
 }
 
-// openURL opens a URL in the default browser
-func openURL(url string) {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return
+// layoutTimeEditor lays out a small timecode input (HH:MM:SS) for the time range
+func (a *GioApp) layoutTimeEditor(gtx layout.Context, editor *widget.Editor, hint, description string) layout.Dimensions {
+	gtx.Constraints.Min.X = gtx.Dp(unit.Dp(72))
+	gtx.Constraints.Max.X = gtx.Constraints.Min.X
+	ed := material.Editor(a.theme, editor, hint)
+	ed.TextSize = unit.Sp(14)
+	return accessibleEditor(gtx, description, editor.Text(), ed.Layout)
+}
+
+// handleShortcuts handles the keyboard shortcuts for the main actions (Cmd instead
+// of Ctrl on macOS): Ctrl+O chooses a file, Ctrl+Enter transcribes, Escape stops,
+// Ctrl+S saves, Ctrl+N opens a new session window, Ctrl+P plays the segment at the
+// cursor and Ctrl+plus/minus change the transcript font size. The first frame
+// focuses the file button, so keyboard and screen reader users start where the
+// task starts.
+func (a *GioApp) handleShortcuts(gtx layout.Context) {
+	if !a.initialFocusSet {
+		a.initialFocusSet = true
+		gtx.Execute(key.FocusCmd{Tag: a.browseBtn})
+	}
+
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Name: "O", Required: key.ModShortcut},
+			key.Filter{Name: key.NameReturn, Required: key.ModShortcut},
+			key.Filter{Name: key.NameEnter, Required: key.ModShortcut},
+			key.Filter{Name: key.NameEscape},
+			key.Filter{Name: "S", Required: key.ModShortcut},
+			key.Filter{Name: "N", Required: key.ModShortcut},
+			key.Filter{Name: "P", Required: key.ModShortcut},
+			key.Filter{Name: "+", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Name: "=", Required: key.ModShortcut, Optional: key.ModShift},
+			key.Filter{Name: "-", Required: key.ModShortcut},
+		)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+		switch e.Name {
+		case "O":
+			go a.selectFile()
+		case key.NameReturn, key.NameEnter:
+			if !a.workerBusy() {
+				go a.startTranscription()
+			}
+		case key.NameEscape:
+			if a.workerBusy() {
+				go a.stopTranscription()
+			}
+		case "S":
+			go a.saveTranscription()
+		case "N":
+			openSessionWindow(a.settings, false)
+		case "P":
+			a.playSegmentAtCursor()
+		case "+", "=":
+			a.changeFontSize(transcriptFontSizeStep)
+		case "-":
+			a.changeFontSize(-transcriptFontSizeStep)
+		}
 	}
-	cmd.Run()
 }
 
-// selectFile opens native file dialog using cross-platform dialog library
-func (a *GioApp) selectFile() {
-	// Open file dialog with audio/video file filters
-	filePath, err := dialog.File().
-		Title("Choose audio or video file").
-		Filter("Audio/Video Files", "mp3", "wav", "m4a", "aac", "flac", "ogg", "wma", "mp4", "avi", "mov", "mkv", "webm", "flv", "wmv", "m4v", "3gp").
-		Filter("All Files", "*").
-		Load()
+// changeFontSize grows or shrinks the transcript text and remembers the size
+func (a *GioApp) changeFontSize(delta float32) {
+	a.transcriptFontSize = Settings{TranscriptFontSize: a.transcriptFontSize + delta}.TranscriptTextSize()
+	size := a.transcriptFontSize
+	go a.updateSettings(func(s *Settings) { s.TranscriptFontSize = size })
+}
 
-	if err != nil {
-		// User cancelled or error occurred
-		if err.Error() != "Cancelled" {
+// lineSpacingValue is the enum value of a line spacing option
+func lineSpacingValue(spacing float32) string {
+	return strconv.FormatFloat(float64(spacing), 'f', 1, 32)
+}
+
+// pollClipboard reads the clipboard every clipboardPollInterval while watching is on,
+// and offers to transcribe newly copied media files and URLs
+func (a *GioApp) pollClipboard(gtx layout.Context) {
+	if !a.watchClipboard.Value {
+		return
+	}
+
+	event.Op(gtx.Ops, a.clipboardWatcher)
+	for {
+		ev, ok := gtx.Event(transfer.TargetFilter{Target: a.clipboardWatcher, Type: "application/text"})
+		if !ok {
+			break
+		}
+		data, ok := ev.(transfer.DataEvent)
+		if !ok {
+			continue
+		}
+		content := data.Open()
+		text, _ := io.ReadAll(io.LimitReader(content, maxClipboardText+1))
+		content.Close()
+		if media, ok := a.clipboardWatcher.Check(string(text)); ok {
 			a.uiMutex.Lock()
-			a.statusText = fmt.Sprintf("Error opening file dialog: %v", err)
+			a.clipboardOffer = media
 			a.uiMutex.Unlock()
 		}
-		return
 	}
 
-	a.audioFilePath = filePath
+	if gtx.Now.Sub(a.lastClipboardRead) >= clipboardPollInterval {
+		a.lastClipboardRead = gtx.Now
+		gtx.Execute(clipboard.ReadCmd{Tag: a.clipboardWatcher})
+	}
+	gtx.Execute(op.InvalidateCmd{At: a.lastClipboardRead.Add(clipboardPollInterval)})
+}
+
+// layoutClipboardOffer shows the "Transcribe?" prompt for copied media, if any
+func (a *GioApp) layoutClipboardOffer(gtx layout.Context) layout.Dimensions {
+	for a.acceptOfferBtn.Clicked(gtx) {
+		go a.transcribeClipboardOffer()
+	}
+	for a.dismissOfferBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.clipboardOffer = ""
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	offer := a.clipboardOffer
+	a.uiMutex.RUnlock()
+	if offer == "" {
+		return layout.Dimensions{}
+	}
+
+	name := offer
+	if !strings.Contains(offer, "://") {
+		name = filepath.Base(offer)
+	}
+	if len(name) > 60 {
+		name = name[:60] + "..."
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.Label(a.theme, unit.Sp(14), "Copied: "+name+" — Transcribe?").Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.acceptOfferBtn, "Transcribe")
+				btn.Inset = a.buttonInset()
+				btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+				return btn.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.dismissOfferBtn, "Dismiss")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			}),
+		)
+	})
+}
+
+// layoutDuplicateOffer offers the earlier transcript of a copy of the selected recording
+func (a *GioApp) layoutDuplicateOffer(gtx layout.Context) layout.Dimensions {
+	for a.reuseTranscriptBtn.Clicked(gtx) {
+		go a.reuseTranscript()
+	}
+	for a.dismissDuplicateBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.duplicateOf = nil
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	earlier := a.duplicateOf
+	a.uiMutex.RUnlock()
+	if earlier == nil {
+		return layout.Dimensions{}
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				msg := fmt.Sprintf("Transcribed before: this file has the same audio as %s (saved as %s)", filepath.Base(earlier.InputPath), filepath.Base(earlier.OutputPath))
+				return material.Label(a.theme, unit.Sp(14), msg).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.reuseTranscriptBtn, "Use That Transcript")
+				btn.Inset = a.buttonInset()
+				btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+				return btn.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.dismissDuplicateBtn, "Dismiss")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			}),
+		)
+	})
+}
+
+// historyShown is how many completed transcriptions the history panel lists
+const historyShown = 10
+
+// layoutHistory lists the latest completed transcriptions, when the history is open,
+// each with buttons to reopen its saved transcript or export it again
+func (a *GioApp) layoutHistory(gtx layout.Context) layout.Dimensions {
+	a.uiMutex.RLock()
+	entries := a.history
+	a.uiMutex.RUnlock()
+
+	for i := range entries {
+		for a.historyOpenBtns[i].Clicked(gtx) {
+			go a.reopenFromHistory(entries[i], false)
+		}
+		for a.historyExportBtns[i].Clicked(gtx) {
+			go a.reopenFromHistory(entries[i], true)
+		}
+	}
+	for a.closeHistoryBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.history = nil
+		a.uiMutex.Unlock()
+	}
+	if len(entries) == 0 {
+		return layout.Dimensions{}
+	}
+
+	button := func(btn *widget.Clickable, text string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				b := material.Button(a.theme, btn, text)
+				b.Inset = a.buttonInset()
+				return b.Layout(gtx)
+			})
+		})
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), "Completed transcriptions (newest first)").Layout(gtx)
+		}),
+	}
+	for i, entry := range entries {
+		row := []layout.FlexChild{
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.Label(a.theme, unit.Sp(14), entry.Describe()).Layout(gtx)
+			}),
+		}
+		if entry.CanReopen() {
+			row = append(row, button(&a.historyOpenBtns[i], "Reopen"), button(&a.historyExportBtns[i], "Export..."))
+		} else {
+			row = append(row, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return material.Label(a.theme, unit.Sp(12), "no transcript saved to reopen").Layout(gtx)
+			}))
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx, row...)
+			})
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			b := material.Button(a.theme, a.closeHistoryBtn, "Close")
+			b.Inset = a.buttonInset()
+			return b.Layout(gtx)
+		})
+	}))
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "History", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+	})
+}
+
+// layoutVoiceMemos lists the recent Voice Memos recordings, when open, each with a
+// button transcribing it
+func (a *GioApp) layoutVoiceMemos(gtx layout.Context) layout.Dimensions {
+	a.uiMutex.RLock()
+	memos := a.voiceMemos
+	a.uiMutex.RUnlock()
+
+	for i := range memos {
+		for a.voiceMemoBtns[i].Clicked(gtx) {
+			go a.transcribeVoiceMemo(memos[i])
+		}
+	}
+	for a.closeVoiceMemosBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.voiceMemos = nil
+		a.uiMutex.Unlock()
+	}
+	if len(memos) == 0 {
+		return layout.Dimensions{}
+	}
+
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), "Voice Memos (newest first)").Layout(gtx)
+		}),
+	}
+	for i, memo := range memos {
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return material.Label(a.theme, unit.Sp(14), memo.Describe()).Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						b := material.Button(a.theme, &a.voiceMemoBtns[i], "Transcribe")
+						b.Inset = a.buttonInset()
+						return b.Layout(gtx)
+					}),
+				)
+			})
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			b := material.Button(a.theme, a.closeVoiceMemosBtn, "Close")
+			b.Inset = a.buttonInset()
+			return b.Layout(gtx)
+		})
+	}))
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Voice Memos", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+	})
+}
+
+// layoutModelChoices lays out a radio button for each model offered: the built-in
+// large-v3 and turbo, and the custom models
+func (a *GioApp) layoutModelChoices(gtx layout.Context, models *widget.Enum) layout.Dimensions {
+	a.uiMutex.RLock()
+	choices := append([]string{"large-v3", "turbo"}, a.customModels...)
+	a.uiMutex.RUnlock()
+
+	children := make([]layout.FlexChild, len(choices))
+	for i, modelID := range choices {
+		children[i] = layout.Rigid(material.RadioButton(a.theme, models, modelID, modelID).Layout)
+	}
+	return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+}
+
+// Sources of a custom model
+const (
+	customModelFromFile = "file" // A GGML file on this computer
+	customModelFromHub  = "hub"  // A file in a HuggingFace repository
+)
+
+// layoutAddModel shows the panel for adding a custom model, when open
+func (a *GioApp) layoutAddModel(gtx layout.Context) layout.Dimensions {
+	for a.customModelBrowseBtn.Clicked(gtx) {
+		go a.selectCustomModelFile()
+	}
+	for a.saveCustomModelBtn.Clicked(gtx) {
+		a.addCustomModel()
+	}
+	for a.cancelCustomModelBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.addingModel = false
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	adding := a.addingModel
+	picked := a.customModelPath
+	a.uiMutex.RUnlock()
+	if !adding {
+		return layout.Dimensions{}
+	}
+	if picked == "" {
+		picked = "No file chosen"
+	} else {
+		picked = filepath.Base(picked)
+	}
+
+	editor := func(ed *widget.Editor, hint, description string, width unit.Dp) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			gtx.Constraints.Min.X = gtx.Dp(width)
+			gtx.Constraints.Max.X = gtx.Constraints.Min.X
+			e := material.Editor(a.theme, ed, hint)
+			e.TextSize = unit.Sp(14)
+			return accessibleEditor(gtx, description, ed.Text(), e.Layout)
+		})
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Add a custom model", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return material.Label(a.theme, unit.Sp(14), "Name:").Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+				editor(a.customModelName, "my-model", "Name of the custom model", 100),
+				layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return accessibleGroup(gtx, "Custom model source", func(gtx layout.Context) layout.Dimensions {
+						return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+							layout.Rigid(material.RadioButton(a.theme, a.customModelSource, customModelFromFile, "File").Layout),
+							layout.Rigid(material.RadioButton(a.theme, a.customModelSource, customModelFromHub, "HuggingFace").Layout),
+						)
+					})
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					if a.customModelSource.Value == customModelFromHub {
+						return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+							editor(a.customModelRepo, "owner/repository", "HuggingFace repository", 200),
+							layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+							editor(a.customModelFile, "ggml-model.bin", "File in the repository", 140),
+						)
+					}
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.customModelBrowseBtn, "Choose .bin…")
+							btn.Inset = a.buttonInset()
+							return btn.Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(material.Label(a.theme, unit.Sp(14), picked).Layout),
+					)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(a.theme, a.saveCustomModelBtn, "Add Model")
+					btn.Inset = a.buttonInset()
+					btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+					return btn.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(a.theme, a.cancelCustomModelBtn, "Cancel")
+					btn.Inset = a.buttonInset()
+					return btn.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+// layoutAppUpdate offers the newer release of the app found by the last check,
+// with its changelog on request
+func (a *GioApp) layoutAppUpdate(gtx layout.Context) layout.Dimensions {
+	for a.appUpdateBtn.Clicked(gtx) {
+		go a.downloadAppUpdate()
+	}
+	for a.appNotesBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.showAppNotes = !a.showAppNotes
+		a.uiMutex.Unlock()
+	}
+	for a.dismissAppUpdateBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.appUpdate = nil
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	release := a.appUpdate
+	showNotes := a.showAppNotes
+	a.uiMutex.RUnlock()
+	if release == nil {
+		return layout.Dimensions{}
+	}
+
+	message := fmt.Sprintf("ivrit.ai %s is available (you have %s)", release.Version, appVersion)
+	download := "Download"
+	if _, ok := release.PlatformAsset(); !ok {
+		download = "Open Release Page"
+	}
+	notes := "What's New"
+	if showNotes {
+		notes = "Hide Changes"
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return material.Label(a.theme, unit.Sp(14), message).Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(a.theme, a.appUpdateBtn, download)
+						btn.Inset = a.buttonInset()
+						btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(a.theme, a.appNotesBtn, notes)
+						btn.Inset = a.buttonInset()
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						btn := material.Button(a.theme, a.dismissAppUpdateBtn, "Later")
+						btn.Inset = a.buttonInset()
+						return btn.Layout(gtx)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !showNotes {
+					return layout.Dimensions{}
+				}
+				text := releaseNotesPreview(release.Notes, 15)
+				if text == "" {
+					text = "No changelog was published with this release."
+				}
+				return layout.Inset{Top: a.space(8)}.Layout(gtx, material.Label(a.theme, unit.Sp(13), text).Layout)
+			}),
+		)
+	})
+}
+
+// layoutModelUpdates offers to download the updated models found by the last check
+func (a *GioApp) layoutModelUpdates(gtx layout.Context) layout.Dimensions {
+	for a.updateModelsBtn.Clicked(gtx) {
+		go a.updateModels()
+	}
+	for a.dismissUpdatesBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.modelUpdates = nil
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	updates := a.modelUpdates
+	a.uiMutex.RUnlock()
+	if len(updates) == 0 {
+		return layout.Dimensions{}
+	}
+
+	names := make([]string, len(updates))
+	for i, u := range updates {
+		names[i] = u.ModelID
+	}
+	message := fmt.Sprintf("ivrit.ai published an updated %s model", names[0])
+	if len(names) > 1 {
+		message = fmt.Sprintf("ivrit.ai published updated models: %s", strings.Join(names, ", "))
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.Label(a.theme, unit.Sp(14), message).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.updateModelsBtn, "Update")
+				btn.Inset = a.buttonInset()
+				btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+				return btn.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.dismissUpdatesBtn, "Later")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			}),
+		)
+	})
+}
+
+// layoutOnboarding shows the step of the first-run setup, when one is open
+func (a *GioApp) layoutOnboarding(gtx layout.Context) layout.Dimensions {
+	for a.onboardingActionBtn.Clicked(gtx) {
+		go a.onboardingAction()
+	}
+	a.uiMutex.RLock()
+	step := a.onboardingStep
+	a.uiMutex.RUnlock()
+	for a.onboardingBackBtn.Clicked(gtx) {
+		a.setOnboardingStep(step - 1)
+	}
+	for a.onboardingNextBtn.Clicked(gtx) {
+		a.setOnboardingStep(step + 1)
+	}
+	for a.onboardingSkipBtn.Clicked(gtx) {
+		a.setOnboardingStep(-1)
+	}
+
+	a.uiMutex.Lock()
+	step = a.onboardingStep
+	if step == onboardingModel && a.onboardingModel != a.onboardingModelList.Value {
+		a.onboardingModel = a.onboardingModelList.Value // Chosen another model: check it
+		go a.refreshOnboarding()
+	}
+	info := a.onboardingInfo
+	ready := a.onboardingReady
+	ollama := a.ollamaStatus
+	a.uiMutex.Unlock()
+	if step < 0 {
+		return layout.Dimensions{}
+	}
+
+	action := ""
+	switch step {
+	case onboardingModel:
+		if !ready {
+			action = "Download"
+		}
+	case onboardingFFmpeg:
+		if !ready {
+			action = "Check Again"
+		}
+	case onboardingOllama:
+		if !ollama.Running {
+			action = "Check Again"
+		} else if !ready {
+			action = "Download Translation Model"
+		}
+	case onboardingSample:
+		action = "Transcribe Sample"
+	}
+	next := "Next"
+	if step == onboardingSteps-1 {
+		next = "Finish"
+	}
+
+	title := fmt.Sprintf("Setup, step %d of %d: %s", step+1, onboardingSteps, onboardingTitles[step])
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "First-run setup", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(a.theme, unit.Sp(16), title)
+					label.Font.Weight = font.Bold
+					return label.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Height: a.space(4)}.Layout),
+				layout.Rigid(material.Label(a.theme, unit.Sp(14), info).Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if step != onboardingModel {
+						return layout.Dimensions{}
+					}
+					return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return accessibleGroup(gtx, "Model", func(gtx layout.Context) layout.Dimensions {
+							return a.layoutModelChoices(gtx, a.onboardingModelList)
+						})
+					})
+				}),
+				layout.Rigid(layout.Spacer{Height: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal, Alignment: layout.Middle}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if step == 0 {
+								return layout.Dimensions{}
+							}
+							btn := material.Button(a.theme, a.onboardingBackBtn, "Back")
+							btn.Inset = a.buttonInset()
+							return layout.Inset{Right: a.space(8)}.Layout(gtx, btn.Layout)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if action == "" {
+								return layout.Dimensions{}
+							}
+							btn := material.Button(a.theme, a.onboardingActionBtn, action)
+							btn.Inset = a.buttonInset()
+							btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+							return btn.Layout(gtx)
+						}),
+						layout.Flexed(1, layout.Spacer{}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.onboardingSkipBtn, "Skip Setup")
+							btn.Inset = a.buttonInset()
+							return btn.Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.onboardingNextBtn, next)
+							btn.Inset = a.buttonInset()
+							return btn.Layout(gtx)
+						}),
+					)
+				}),
+			)
+		})
+	})
+}
+
+// layoutDamagedModel offers to download a model again when its file failed validation
+func (a *GioApp) layoutDamagedModel(gtx layout.Context) layout.Dimensions {
+	for a.redownloadBtn.Clicked(gtx) {
+		go a.redownloadModel()
+	}
+	for a.dismissDamagedBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.damagedModel = nil
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	damaged := a.damagedModel
+	a.uiMutex.RUnlock()
+	if damaged == nil {
+		return layout.Dimensions{}
+	}
+
+	redownloadable := damaged.Redownloadable()
+	message := fmt.Sprintf("The %s model file is damaged, probably by an interrupted download", damaged.ModelID)
+	if !redownloadable {
+		message = fmt.Sprintf("The %s model file is damaged: replace %s", damaged.ModelID, damaged.Path)
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return material.Label(a.theme, unit.Sp(14), message).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !redownloadable {
+					return layout.Dimensions{}
+				}
+				btn := material.Button(a.theme, a.redownloadBtn, "Download Again")
+				btn.Inset = a.buttonInset()
+				btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+				return layout.Inset{Right: a.space(8)}.Layout(gtx, btn.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.dismissDamagedBtn, "Dismiss")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			}),
+		)
+	})
+}
+
+// layoutCloudNotice warns that recordings leave this computer, when the runpod engine is configured
+func (a *GioApp) layoutCloudNotice(gtx layout.Context) layout.Dimensions {
+	notice := CloudUploadNotice(a.config)
+	if notice == "" {
+		return layout.Dimensions{}
+	}
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		label := material.Label(a.theme, unit.Sp(13), "⚠ Privacy: "+notice)
+		label.Color = color.NRGBA{R: 180, G: 100, B: 0, A: 255}
+		return label.Layout(gtx)
+	})
+}
+
+// layoutRetranscribe shows the settings for re-transcribing the selected segment, if any
+func (a *GioApp) layoutRetranscribe(gtx layout.Context) layout.Dimensions {
+	for a.retranscribeRunBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		index := a.retranscribeIndex
+		a.retranscribeIndex = -1
+		a.uiMutex.Unlock()
+		beamSize, _ := strconv.Atoi(a.retranscribeBeam.Text())
+		decode := DecodeOptions{BeamSize: beamSize, Temperature: a.config.Decode.Temperature, InitialPrompt: strings.TrimSpace(a.retranscribePrompt.Text())}
+		go a.retranscribeSegment(index, a.retranscribeModel.Value, decode)
+	}
+	for a.retranscribeCancelBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.retranscribeIndex = -1
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	index := a.retranscribeIndex
+	a.uiMutex.RUnlock()
+	if index < 0 || index >= len(a.transcriptionSegments) {
+		return layout.Dimensions{}
+	}
+	seg := a.transcriptionSegments[index]
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Re-transcribe segment", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := fmt.Sprintf("Fix %s: %s", FormatTimestamp(seg.Start, true)[:8], segmentPreview(seg, 60))
+					return material.Label(a.theme, unit.Sp(14), label).Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis:      layout.Horizontal,
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Label(a.theme, unit.Sp(14), "Model:").Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return accessibleGroup(gtx, "Model for re-transcribing", func(gtx layout.Context) layout.Dimensions {
+								return a.layoutModelChoices(gtx, a.retranscribeModel)
+							})
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Label(a.theme, unit.Sp(14), "Beam:").Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							gtx.Constraints.Min.X = gtx.Dp(unit.Dp(32))
+							gtx.Constraints.Max.X = gtx.Constraints.Min.X
+							ed := material.Editor(a.theme, a.retranscribeBeam, "1")
+							ed.TextSize = unit.Sp(14)
+							return accessibleEditor(gtx, "Beam size", a.retranscribeBeam.Text(), ed.Layout)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return material.Label(a.theme, unit.Sp(14), "Prompt:").Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(4)}.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							ed := material.Editor(a.theme, a.retranscribePrompt, "names or terms")
+							ed.TextSize = unit.Sp(14)
+							return accessibleEditor(gtx, "Prompt with names or terms", a.retranscribePrompt.Text(), ed.Layout)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return a.layoutPlayButton(gtx, a.retranscribePlayBtn, index)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.retranscribeRunBtn, "Re-transcribe")
+							btn.Inset = a.buttonInset()
+							btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+							return btn.Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.retranscribeCancelBtn, "Cancel")
+							btn.Inset = a.buttonInset()
+							return btn.Layout(gtx)
+						}),
+					)
+				}),
+			)
+		})
+	})
+}
+
+// layoutAlternatives offers the alternative readings found for a segment, one button each
+func (a *GioApp) layoutAlternatives(gtx layout.Context) layout.Dimensions {
+	a.uiMutex.RLock()
+	index := a.alternativesIndex
+	alternatives := a.alternatives
+	a.uiMutex.RUnlock()
+
+	for i := range alternatives {
+		for a.alternativeBtns[i].Clicked(gtx) {
+			a.uiMutex.Lock()
+			a.alternativesIndex = -1
+			a.alternatives = nil
+			a.uiMutex.Unlock()
+			go a.pickAlternative(index, alternatives[i])
+		}
+	}
+	for a.dismissAlternativesBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.alternativesIndex = -1
+		a.alternatives = nil
+		a.uiMutex.Unlock()
+	}
+	if index < 0 || len(alternatives) == 0 || index >= len(a.transcriptionSegments) {
+		return layout.Dimensions{}
+	}
+
+	seg := a.transcriptionSegments[index]
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := fmt.Sprintf("Alternatives for %s: %s", FormatTimestamp(seg.Start, true)[:8], segmentPreview(seg, 60))
+			return material.Label(a.theme, unit.Sp(14), label).Layout(gtx)
+		}),
+	}
+	for i, reading := range alternatives {
+		btn := &a.alternativeBtns[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				b := material.Button(a.theme, btn, reading)
+				b.Inset = a.buttonInset()
+				return describedButton(gtx, a.theme, b, "Use this reading")
+			})
+		}))
+	}
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return layout.Inset{Top: a.space(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return a.layoutPlayButton(gtx, a.alternativesPlayBtn, index)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					b := material.Button(a.theme, a.dismissAlternativesBtn, "Keep current text")
+					b.Inset = a.buttonInset()
+					return b.Layout(gtx)
+				}),
+			)
+		})
+	}))
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Alternative readings", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+		})
+	})
+}
+
+// layoutCorrections shows the proposed spelling correction under review, one at a time
+func (a *GioApp) layoutCorrections(gtx layout.Context) layout.Dimensions {
+	for a.acceptCorrectionBtn.Clicked(gtx) {
+		a.reviewCorrection(true, false)
+	}
+	for a.skipCorrectionBtn.Clicked(gtx) {
+		a.reviewCorrection(false, false)
+	}
+	for a.acceptAllCorrectionsBtn.Clicked(gtx) {
+		a.reviewCorrection(true, true)
+	}
+	for a.cancelCorrectionsBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.corrections = nil
+		a.acceptedCorrections = nil
+		a.statusText = "Corrections discarded"
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	corrections := a.corrections
+	index := a.correctionIndex
+	a.uiMutex.RUnlock()
+	if index >= len(corrections) || corrections[index].Index >= len(a.transcriptionSegments) {
+		return layout.Dimensions{}
+	}
+	c := corrections[index]
+	seg := a.transcriptionSegments[c.Index]
+
+	line := func(text string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), text).Layout(gtx)
+		})
+	}
+	button := func(btn *widget.Clickable, text string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				b := material.Button(a.theme, btn, text)
+				b.Inset = a.buttonInset()
+				return b.Layout(gtx)
+			})
+		})
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Proposed correction", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				line(fmt.Sprintf("Correction %d of %d at %s", index+1, len(corrections), FormatTimestamp(seg.Start, true)[:8])),
+				line("Before: "+c.Before),
+				line("After: "+c.After),
+				line("Changes: "+strings.Join(DescribeChanges(c.Before, c.After), "; ")),
+				layout.Rigid(layout.Spacer{Height: a.space(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return layout.Inset{Right: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return a.layoutPlayButton(gtx, a.correctionPlayBtn, c.Index)
+							})
+						}),
+						button(a.acceptCorrectionBtn, "Accept"),
+						button(a.skipCorrectionBtn, "Skip"),
+						button(a.acceptAllCorrectionsBtn, "Accept All Remaining"),
+						button(a.cancelCorrectionsBtn, "Cancel"),
+					)
+				}),
+			)
+		})
+	})
+}
+
+// layoutTranslationReview shows the translations flagged by the back-translation check
+// one at a time, with the Hebrew and the back-translation, to edit or move past
+func (a *GioApp) layoutTranslationReview(gtx layout.Context) layout.Dimensions {
+	for a.editFlaggedBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		if review := a.translationReview; review != nil && a.translationFlagIndex < len(review.Flags) {
+			if index := review.Flags[a.translationFlagIndex].Index; index < len(a.transcriptionSegments) {
+				a.translationIndex = index
+				a.translationEditor.SetText(a.transcriptionSegments[index].Translation)
+			}
+		}
+		a.uiMutex.Unlock()
+	}
+	for a.nextFlagBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.translationFlagIndex++
+		if a.translationReview != nil && a.translationFlagIndex >= len(a.translationReview.Flags) {
+			a.translationReview = nil
+			a.statusText = "Translation review done"
+		}
+		a.uiMutex.Unlock()
+	}
+	for a.closeFlagsBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.translationReview = nil
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	review := a.translationReview
+	index := a.translationFlagIndex
+	a.uiMutex.RUnlock()
+	if review == nil || index >= len(review.Flags) || review.Flags[index].Index >= len(a.transcriptionSegments) {
+		return layout.Dimensions{}
+	}
+	f := review.Flags[index]
+	seg := a.transcriptionSegments[f.Index]
+
+	line := func(text string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return material.Label(a.theme, unit.Sp(14), text).Layout(gtx)
+		})
+	}
+	button := func(btn *widget.Clickable, text string) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				b := material.Button(a.theme, btn, text)
+				b.Inset = a.buttonInset()
+				return b.Layout(gtx)
+			})
+		})
+	}
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Flagged translation", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				line(fmt.Sprintf("Flagged translation %d of %d at %s (%.0f%% match)", index+1, len(review.Flags), FormatTimestamp(seg.Start, true)[:8], f.Similarity*100)),
+				line("Hebrew: "+f.Original),
+				line("Translation: "+seg.Translation),
+				line("Back-translation: "+f.BackTranslation),
+				layout.Rigid(layout.Spacer{Height: a.space(4)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return layout.Inset{Right: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return a.layoutPlayButton(gtx, a.flagPlayBtn, f.Index)
+							})
+						}),
+						button(a.editFlaggedBtn, "Edit Translation"),
+						button(a.nextFlagBtn, "Next"),
+						button(a.closeFlagsBtn, "Done Reviewing"),
+					)
+				}),
+			)
+		})
+	})
+}
+
+// layoutTranslationEdit shows the editor for the translation of the selected segment, if any
+func (a *GioApp) layoutTranslationEdit(gtx layout.Context) layout.Dimensions {
+	save := false
+	for a.saveTranslationBtn.Clicked(gtx) {
+		save = true
+	}
+	for {
+		ev, ok := a.translationEditor.Update(gtx)
+		if !ok {
+			break
+		}
+		if _, ok := ev.(widget.SubmitEvent); ok {
+			save = true
+		}
+	}
+	if save {
+		a.uiMutex.Lock()
+		index := a.translationIndex
+		a.translationIndex = -1
+		a.uiMutex.Unlock()
+		go a.editTranslation(index, a.translationEditor.Text())
+	}
+	for a.cancelTranslationBtn.Clicked(gtx) {
+		a.uiMutex.Lock()
+		a.translationIndex = -1
+		a.uiMutex.Unlock()
+	}
+
+	a.uiMutex.RLock()
+	index := a.translationIndex
+	a.uiMutex.RUnlock()
+	if index < 0 || index >= len(a.transcriptionSegments) {
+		return layout.Dimensions{}
+	}
+	seg := a.transcriptionSegments[index]
+
+	return layout.Inset{Bottom: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return accessibleGroup(gtx, "Edit translation", func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					label := fmt.Sprintf("Translation of %s: %s", FormatTimestamp(seg.Start, true)[:8], segmentPreview(seg, 60))
+					return material.Label(a.theme, unit.Sp(14), label).Layout(gtx)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis:      layout.Horizontal,
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							ed := material.Editor(a.theme, a.translationEditor, "translation")
+							ed.TextSize = unit.Sp(14)
+							return accessibleEditor(gtx, "Translation", a.translationEditor.Text(), ed.Layout)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return a.layoutPlayButton(gtx, a.translationPlayBtn, index)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.saveTranslationBtn, "Save")
+							btn.Inset = a.buttonInset()
+							btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+							return btn.Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							btn := material.Button(a.theme, a.cancelTranslationBtn, "Cancel")
+							btn.Inset = a.buttonInset()
+							return btn.Layout(gtx)
+						}),
+					)
+				}),
+			)
+		})
+	})
+}
+
+// layoutPlayButton lays out a button playing (or stopping) a segment's audio
+func (a *GioApp) layoutPlayButton(gtx layout.Context, btn *widget.Clickable, index int) layout.Dimensions {
+	for btn.Clicked(gtx) {
+		a.toggleSegmentPlayback(index)
+	}
+	a.uiMutex.RLock()
+	label := "Play"
+	if a.playingIndex == index {
+		label = "Stop"
+	}
+	a.uiMutex.RUnlock()
+	b := material.Button(a.theme, btn, label)
+	b.Inset = a.buttonInset()
+	return describedButton(gtx, a.theme, b, "Play this segment's audio")
+}
+
+func (a *GioApp) layoutControls(gtx layout.Context) layout.Dimensions {
+	// Handle button clicks
+	for a.transcribeBtn.Clicked(gtx) {
+		go a.startTranscription()
+	}
+	for a.stopBtn.Clicked(gtx) {
+		go a.stopTranscription()
+	}
+	for a.saveBtn.Clicked(gtx) {
+		go a.saveTranscription()
+	}
+	for a.exportAllBtn.Clicked(gtx) {
+		go a.saveAllFormats()
+	}
+	for a.perSpeakerBtn.Clicked(gtx) {
+		go a.saveBySpeaker()
+	}
+	for a.vttTracksBtn.Clicked(gtx) {
+		go a.saveSubtitleTracks()
+	}
+	for a.minutesBtn.Clicked(gtx) {
+		go a.saveMinutes()
+	}
+	for a.fixSegmentBtn.Clicked(gtx) {
+		a.selectSegmentToFix()
+	}
+	for a.spellCheckBtn.Clicked(gtx) {
+		go a.checkSpelling()
+	}
+	for a.editTranslationBtn.Clicked(gtx) {
+		a.selectTranslationToEdit()
+	}
+	for a.playSegmentBtn.Clicked(gtx) {
+		a.playSegmentAtCursor()
+	}
+	for a.presentBtn.Clicked(gtx) {
+		go a.openPresentation()
+	}
+	for a.googleDocsBtn.Clicked(gtx) {
+		go a.exportTranscription(ExportGoogleDocs, "Google Docs")
+	}
+	for a.notionBtn.Clicked(gtx) {
+		go a.exportTranscription(ExportNotion, "Notion")
+	}
+	for a.historyBtn.Clicked(gtx) {
+		go a.openHistory()
+	}
+	
+	return layout.Flex{
+		Axis:    layout.Horizontal,
+		Spacing: layout.SpaceStart,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.transcribeBtn, "Transcribe")
+			btn.Inset = a.buttonInset()
+			btn.Background = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+			return btn.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.stopBtn, "Stop")
+			btn.Inset = a.buttonInset()
+			btn.Background = color.NRGBA{R: 220, G: 53, B: 69, A: 255}
+			return btn.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.saveBtn, "Save As...")
+			btn.Inset = a.buttonInset()
+			return btn.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.exportAllBtn, "Export All...")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Save the transcript as text, SRT, VTT and JSON at once")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if len(SplitBySpeaker(a.transcriptionSegments)) < 2 {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.perSpeakerBtn, "Per Speaker...")
+				btn.Inset = a.buttonInset()
+				return describedButton(gtx, a.theme, btn, "Save one file per speaker with only that speaker's segments")
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if SubtitleTracks(a.transcriptionSegments, a.config.TargetLang) == nil {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.vttTracksBtn, "VTT Tracks...")
+				btn.Inset = a.buttonInset()
+				return describedButton(gtx, a.theme, btn, "Save a VTT file per language with an HLS manifest offering them as selectable subtitles")
+			})
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.minutesBtn, "Minutes...")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Generate meeting minutes")
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.fixSegmentBtn, "Fix Segment...")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Re-transcribe the segment at the cursor with other settings")
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			a.uiMutex.RLock()
+			label := "Play Segment"
+			if a.playingIndex >= 0 {
+				label = "Stop Playing"
+			}
+			a.uiMutex.RUnlock()
+			btn := material.Button(a.theme, a.playSegmentBtn, label)
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Play the audio of the segment at the cursor")
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.spellCheckBtn, "Spell Check...")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Propose fixes for obvious recognition errors with the local LLM")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			// Translations are edited next to the Hebrew, so only while both are shown
+			if a.displayMode.Value != DisplayBilingual || !IsTranslated(a.transcriptionSegments) {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.editTranslationBtn, "Edit Translation...")
+				btn.Inset = a.buttonInset()
+				return describedButton(gtx, a.theme, btn, "Correct the translation of the segment at the cursor")
+			})
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.historyBtn, "History")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Completed transcriptions, to reopen or export again")
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Button(a.theme, a.presentBtn, "Present")
+			btn.Inset = a.buttonInset()
+			return describedButton(gtx, a.theme, btn, "Open live captions window")
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.integrations.Google == nil {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.googleDocsBtn, "Google Docs")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.integrations.Notion == nil {
+				return layout.Dimensions{}
+			}
+			return layout.Inset{Left: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.notionBtn, "Notion")
+				btn.Inset = a.buttonInset()
+				return btn.Layout(gtx)
+			})
+		}),
+	)
+}
+
+func (a *GioApp) layoutStatus(gtx layout.Context) layout.Dimensions {
+	// Read UI state with lock to prevent data races
+	a.uiMutex.RLock()
+	statusText := a.statusText
+	timingText := a.timingText
+	if a.telemetryText != "" {
+		timingText = a.telemetryText
+	}
+	savedFilePath := a.savedFilePath
+	a.uiMutex.RUnlock()
+
+	for a.revealBtn.Clicked(gtx) {
+		go a.runFileAction(RevealFile, savedFilePath)
+	}
+	for a.openFileBtn.Clicked(gtx) {
+		go a.runFileAction(OpenFile, savedFilePath)
+	}
+	for a.shareBtn.Clicked(gtx) {
+		go a.runFileAction(ShareFile, savedFilePath)
+	}
+	for a.downloadPauseBtn.Clicked(gtx) {
+		if DownloadsPaused() {
+			ResumeDownloads()
+			a.setStatus("Resuming the download...")
+		} else {
+			PauseDownloads()
+			a.setStatus("Download paused")
+		}
+	}
+	downloading, paused := DownloadInProgress(), DownloadsPaused()
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Spacing:   layout.SpaceBetween,
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(a.theme, unit.Sp(12), statusText)
+			return label.Layout(gtx)
+		}),
+		// Actions on the file just saved
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if savedFilePath == "" {
+				return layout.Dimensions{}
+			}
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+				layout.Rigid(layout.Spacer{Width: a.space(12)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(a.theme, a.revealBtn, revealLabel(runtime.GOOS))
+					btn.TextSize = unit.Sp(12)
+					btn.Inset = layout.UniformInset(a.space(4))
+					return btn.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(a.theme, a.openFileBtn, "Open")
+					btn.TextSize = unit.Sp(12)
+					btn.Inset = layout.UniformInset(a.space(4))
+					return describedButton(gtx, a.theme, btn, "Open "+filepath.Base(savedFilePath)+" with its default app")
+				}),
+				layout.Rigid(layout.Spacer{Width: a.space(8)}.Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					btn := material.Button(a.theme, a.shareBtn, "Email")
+					btn.TextSize = unit.Sp(12)
+					btn.Inset = layout.UniformInset(a.space(4))
+					return describedButton(gtx, a.theme, btn, "Email "+filepath.Base(savedFilePath)+" as an attachment from the default mail app")
+				}),
+			)
+		}),
+		// Pausing the download in progress, e.g. to free the connection for a call
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if !downloading {
+				return layout.Dimensions{}
+			}
+			label := "Pause Download"
+			if paused {
+				label = "Resume Download"
+			}
+			return layout.Inset{Left: a.space(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				btn := material.Button(a.theme, a.downloadPauseBtn, label)
+				btn.TextSize = unit.Sp(12)
+				btn.Inset = layout.UniformInset(a.space(4))
+				return btn.Layout(gtx)
+			})
+		}),
+		layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Dimensions{Size: gtx.Constraints.Min}
+		}),
+		layout.Rigid(layout.Spacer{Width: a.space(16)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(a.theme, unit.Sp(12), timingText)
+			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+			return label.Layout(gtx)
+		}),
+	)
+}
+
+// layoutTextStats shows the word and character counts of the transcript shown,
+// updated as segments arrive
+func (a *GioApp) layoutTextStats(gtx layout.Context) layout.Dimensions {
+	if !a.textStats.Value || len(a.transcriptionSegments) == 0 {
+		return layout.Dimensions{}
+	}
+	stats := ComputeTextStats(a.shownSegments(a.outputSegments()))
+	return layout.Inset{Bottom: a.space(8)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		label := material.Label(a.theme, unit.Sp(12), stats.Summary())
+		label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+		return label.Layout(gtx)
+	})
+}
+
+func (a *GioApp) layoutOutput(gtx layout.Context) layout.Dimensions {
+	// Output text area with RTL support
+	// Gio's text shaper handles RTL automatically for Hebrew text
+	ed := material.Editor(a.theme, a.outputEditor, "Transcription will appear here...")
+	ed.TextSize = unit.Sp(a.transcriptFontSize)
+	spacing, _ := strconv.ParseFloat(a.lineSpacingList.Value, 32)
+	ed.LineHeightScale = float32(spacing)
+	if a.monospace.Value {
+		ed.Font.Typeface = font.Typeface("Go Mono")
+	}
+
+	// Right-align Hebrew text
+	a.uiMutex.RLock()
+	currentText := a.outputEditor.Text()
+	a.uiMutex.RUnlock()
+
+	if containsRTL(currentText) {
+		ed.Editor.Alignment = text.End // Right-align for RTL Hebrew and Arabic
+	} else {
+		ed.Editor.Alignment = text.Start // Left-align for LTR
+	}
+
+	// Wrap Layout call with panic recovery to prevent crashes with large text buffers
+	var dims layout.Dimensions
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// If Layout panics (e.g., text buffer too large), show error in status
+				fmt.Fprintf(os.Stderr, "Warning: Text layout error: %v\n", r)
+				a.uiMutex.Lock()
+				a.statusText = "Warning: Text display limited due to size"
+				a.uiMutex.Unlock()
+				// Return empty dimensions to prevent further crashes
+				dims = layout.Dimensions{}
+			}
+		}()
+		// Screen readers get the lines around the cursor rather than the whole transcript
+		caret, _ := a.outputEditor.Selection()
+		dims = accessibleEditor(gtx, "Transcript", accessibleExcerpt(currentText, caret, accessibleChars), ed.Layout)
+	}()
+
+	// Right-clicking offers alternative readings of the segment at the cursor
+	area := clip.Rect{Max: dims.Size}.Push(gtx.Ops)
+	pass := pointer.PassOp{}.Push(gtx.Ops)
+	event.Op(gtx.Ops, a.transcriptPointer)
+	pass.Pop()
+	area.Pop()
+	for {
+		ev, ok := gtx.Event(pointer.Filter{Target: a.transcriptPointer, Kinds: pointer.Press})
+		if !ok {
+			break
+		}
+		if e, ok := ev.(pointer.Event); ok && e.Buttons == pointer.ButtonSecondary {
+			a.showAlternatives()
+		}
+	}
+
+	return dims
+}
+
+func (a *GioApp) layoutCredits(gtx layout.Context) layout.Dimensions {
+	// Handle link clicks
+	for a.ivritLink.Clicked(gtx) {
+		go openURL("https://ivrit.ai")
+	}
+	for a.patreonLink.Clicked(gtx) {
+		go openURL("https://www.patreon.com/ivrit_ai")
+	}
+	for a.creditsLink.Clicked(gtx) {
+		go openURL("https://www.ivrit.ai/he/%d7%9e%d7%99-%d7%90%d7%a0%d7%97%d7%a0%d7%95/")
+	}
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Spacing:   layout.SpaceStart,
+		Alignment: layout.Middle,
+	}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(a.theme, unit.Sp(10), "Powered by ")
+			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Clickable(gtx, a.ivritLink, func(gtx layout.Context) layout.Dimensions {
+				return accessibleLink(gtx, "Open ivrit.ai in the browser", func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(a.theme, unit.Sp(10), "ivrit.ai")
+					label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255} // Blue link color
+					return label.Layout(gtx)
+				})
+			})
+			return btn
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(a.theme, unit.Sp(10), " | ")
+			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Clickable(gtx, a.patreonLink, func(gtx layout.Context) layout.Dimensions {
+				return accessibleLink(gtx, "Open the ivrit.ai Patreon page in the browser", func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(a.theme, unit.Sp(10), "Support on Patreon")
+					label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+					return label.Layout(gtx)
+				})
+			})
+			return btn
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(a.theme, unit.Sp(10), " | ")
+			label.Color = color.NRGBA{R: 100, G: 100, B: 100, A: 255}
+			return label.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			btn := material.Clickable(gtx, a.creditsLink, func(gtx layout.Context) layout.Dimensions {
+				return accessibleLink(gtx, "Open the credits page in the browser", func(gtx layout.Context) layout.Dimensions {
+					label := material.Label(a.theme, unit.Sp(10), "Credits")
+					label.Color = color.NRGBA{R: 0, G: 122, B: 255, A: 255}
+					return label.Layout(gtx)
+				})
+			})
+			return btn
+		}),
+	)
+}
+
+// openURL opens a URL in the default browser
+func openURL(url string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", url)
+	default:
+		return
+	}
+	cmd.Run()
+}
+
+// selectFile opens native file dialog using cross-platform dialog library
+func (a *GioApp) selectFile() {
+	// Open file dialog with audio/video file filters
+	filePath, err := dialog.File().
+		Title("Choose audio or video file").
+		Filter("Audio/Video Files", mediaExtensions...).
+		Filter("All Files", "*").
+		Load()
+
+	if err != nil {
+		// User cancelled or error occurred
+		if err.Error() != "Cancelled" {
+			a.uiMutex.Lock()
+			a.statusText = fmt.Sprintf("Error opening file dialog: %v", err)
+			a.uiMutex.Unlock()
+		}
+		return
+	}
+
+	a.setAudioFile(filePath)
+}
+
+// selectModelDir chooses the folder models are stored in and moves the downloaded
+// models there
+func (a *GioApp) selectModelDir() {
+	if a.config.ModelDir != "" {
+		a.setStatus(fmt.Sprintf("Models are stored in %s, set as modelDir in config.json or IVRIT_MODEL_DIR", a.config.ModelDir))
+		return
+	}
+	dir, err := dialog.Directory().Title("Choose where models are stored").Browse()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening folder dialog: %v", err))
+		}
+		return
+	}
+	if !a.claimWorker() {
+		a.setStatus("Wait for the current task to finish before moving the models")
+		return
+	}
+	defer a.releaseWorker()
+
+	moved, err := MoveModels(ModelDir(), dir, a.setStatus)
+	if err != nil && moved == 0 {
+		a.setStatus(fmt.Sprintf("Could not move the models: %v", err))
+		return
+	}
+	// Models moved so far are only found in the new folder, so switch to it even after an error
+	SetModelDir(dir)
+	a.updateSettings(func(s *Settings) { s.ModelDir = dir })
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Models are now stored in %s, but some could not be moved: %v", dir, err))
+		return
+	}
+	a.setStatus(fmt.Sprintf("Models are now stored in %s", dir))
+}
+
+// selectCustomModelFile picks the GGML file of a custom model, naming the model after it
+func (a *GioApp) selectCustomModelFile() {
+	filePath, err := dialog.File().
+		Title("Choose a GGML model file").
+		Filter("GGML Models", "bin").
+		Filter("All Files", "*").
+		Load()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening file dialog: %v", err))
+		}
+		return
+	}
+
+	a.uiMutex.Lock()
+	a.customModelPath = filePath
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// addCustomModel adds the model set up in the panel to models.json and selects it
+func (a *GioApp) addCustomModel() {
+	name := strings.TrimSpace(a.customModelName.Text())
+	info := ModelInfo{}
+	if a.customModelSource.Value == customModelFromHub {
+		info.ID = strings.TrimSpace(a.customModelRepo.Text())
+		info.File = strings.TrimSpace(a.customModelFile.Text())
+	} else {
+		a.uiMutex.RLock()
+		info.Path = a.customModelPath
+		a.uiMutex.RUnlock()
+		if name == "" && info.Path != "" {
+			name = strings.TrimPrefix(strings.TrimSuffix(filepath.Base(info.Path), filepath.Ext(info.Path)), "ggml-")
+		}
+	}
+
+	if err := AddCustomModel(name, info); err != nil {
+		a.setStatus(fmt.Sprintf("Could not add the model: %v", err))
+		return
+	}
+	a.uiMutex.Lock()
+	a.customModels = CustomModelIDs()
+	a.addingModel = false
+	a.customModelPath = ""
+	a.uiMutex.Unlock()
+	a.customModelName.SetText("")
+	a.customModelRepo.SetText("")
+	a.customModelFile.SetText("")
+	a.modelList.Value = name
+	a.setStatus(fmt.Sprintf("Added the %s model to %s", name, modelsConfigPath()))
+}
+
+// raise brings the window to the front
+func (a *GioApp) raise() {
+	a.window.Perform(system.ActionRaise)
+}
+
+// openLaunchedFile selects a file the app was launched with, e.g. from "Open With"
+// while it was running, unless a job is running: its input isn't replaced then
+func (a *GioApp) openLaunchedFile(path string) bool {
+	if !a.claimWorker() {
+		return false
+	}
+	defer a.releaseWorker()
+	if _, err := os.Stat(path); err != nil {
+		a.setStatus(fmt.Sprintf("Cannot open %s: %v", filepath.Base(path), err))
+		return true
+	}
+	a.setAudioFile(path)
+	a.window.Invalidate()
+	return true
+}
+
+// setAudioFile selects the file to transcribe
+func (a *GioApp) setAudioFile(filePath string) {
+	a.removeJoinedRecording()
+	a.audioFilePath = filePath
+	a.window.Option(app.Title(windowTitle + " - " + filepath.Base(filePath))) // Tells session windows apart
+	a.uiMutex.Lock()
+	a.statusText = "File selected: " + filepath.Base(filePath)
+	a.uiMutex.Unlock()
+
+	// A meeting recording with a track per participant offers to transcribe those
+	a.meeting = nil
+	if rec, ok := DetectMeetingRecording(filePath); ok && len(rec.Tracks) > 0 {
+		a.meeting = rec
+		a.setStatus(rec.Describe())
+	}
+
+	// A start time in the recording's .start file or name, for clock times
+	a.startTimeEditor.SetText("")
+	if start, _, ok, err := ResolveRecordingStart("", filePath); ok && err == nil {
+		a.startTimeEditor.SetText(FormatRecordingStart(start))
+	}
+
+	// Get audio duration in background
+	go func() {
+		duration, err := getAudioDuration(filePath)
+		if err == nil {
+			a.audioDuration = duration
+		}
+	}()
+
+	// Look for an earlier transcript of the same audio under another name (hashing
+	// a long recording takes a moment)
+	a.uiMutex.Lock()
+	a.duplicateOf = nil
+	a.uiMutex.Unlock()
+	go func() {
+		earlier, ok := FindTranscribedDuplicate(filePath)
+		if !ok {
+			return
+		}
+		a.uiMutex.Lock()
+		if a.audioFilePath == filePath {
+			a.duplicateOf = &earlier
+		}
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+	}()
+}
+
+// reuseTranscript loads the earlier transcript of the selected recording's audio in
+// place of transcribing it again
+func (a *GioApp) reuseTranscript() {
+	a.uiMutex.Lock()
+	earlier := a.duplicateOf
+	a.duplicateOf = nil
+	a.uiMutex.Unlock()
+	if earlier == nil {
+		return
+	}
+	if !a.claimWorker() {
+		a.setStatus("A transcription is already running")
+		return
+	}
+	defer a.releaseWorker()
+
+	segments, manifest, err := earlier.LoadTranscript()
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Error reading %s: %v", earlier.OutputPath, err))
+		return
+	}
+	a.showLoadedTranscript(segments, manifest, nil, fmt.Sprintf("Loaded the transcript of %s (%d segments)", filepath.Base(earlier.InputPath), len(segments)))
+}
+
+// showLoadedTranscript shows a transcript read back from a file in place of the
+// current one, with the history entry it was reopened from (nil if none)
+func (a *GioApp) showLoadedTranscript(segments []Segment, manifest *Manifest, entry *HistoryEntry, status string) {
+	a.uiMutex.Lock()
+	a.transcriptionSegments = segments
+	a.originalSegments = nil
+	a.savedFilePath = ""
+	a.lastManifest = manifest
+	a.consensusReview = nil
+	a.translationReview = nil
+	a.glossaryMisses = nil
+	a.corrections = nil
+	a.acceptedCorrections = nil
+	a.timingText = ""
+	a.historyEntry = entry
+	a.statusText = status
+	a.outputEditor.SetText(a.displayText(segments))
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// recordHistoryOutput notes in the history where the transcript shown was saved
+func (a *GioApp) recordHistoryOutput(filePath string) {
+	a.uiMutex.RLock()
+	entry := a.historyEntry
+	a.uiMutex.RUnlock()
+	if entry == nil {
+		return
+	}
+	go func() {
+		if err := SetHistoryOutput(*entry, filePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Cannot update the history: %v\n", err)
+		}
+	}()
+}
+
+// selectedOptions returns the configuration with the options chosen in the window
+func (a *GioApp) selectedOptions() AppConfig {
+	cfg := a.config
+	cfg.Model = a.modelList.Value
+	cfg.Format = a.formatList.Value
+	cfg.Translate = a.enableTranslation.Value
+	cfg.TargetLang = a.translateLangList.Value
+	cfg.DisplayMode = a.displayMode.Value
+	cfg.Numbers = NumberOptions{Style: a.numberStyle.Value, Dates: a.normalizeDates.Value}
+	cfg.Redact.Enabled = a.redact.Value
+	cfg.ChannelMode = ChannelModeMix
+	if a.splitChannels.Value {
+		cfg.ChannelMode = ChannelModeSplit
+	}
+	if !a.consensus.Value {
+		cfg.Consensus = ""
+	} else if cfg.Consensus == "" {
+		cfg.Consensus = ConsensusModels
+	}
+	if !a.transliterate.Value {
+		cfg.Transliteration.Method = ""
+	} else if cfg.Transliteration.Method == "" {
+		cfg.Transliteration.Method = TransliterateRules
+	}
+	cfg.Decode.MaxSegmentChars, _ = strconv.Atoi(a.maxSegmentEditor.Text())
+	return cfg
+}
+
+// applyPreset sets the options of a preset, in the window and for saving
+func (a *GioApp) applyPreset(name string) {
+	if !a.claimWorker() {
+		a.setStatus("Presets can't be changed while a transcription is running")
+		return
+	}
+	cfg := a.selectedOptions()
+	err := cfg.ApplyPreset(name)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		a.releaseWorker()
+		a.setStatus(fmt.Sprintf("Error applying preset %s: %v", name, err))
+		return
+	}
+
+	a.config = cfg
+	SetSpeakerLabels(cfg.SpeakerLabels)
+	a.modelList.Value = cfg.Model
+	a.formatList.Value = cfg.Format
+	if cfg.Format == FormatAll {
+		a.formatList.Value = "text" // Export All writes the others
+	}
+	a.enableTranslation.Value = cfg.Translate
+	a.translateLangList.Value = cfg.TargetLang
+	a.displayMode.Value = cfg.DisplayMode
+	a.numberStyle.Value = cfg.Numbers.Style
+	a.normalizeDates.Value = cfg.Numbers.Dates
+	a.redact.Value = cfg.Redact.Enabled
+	a.splitChannels.Value = cfg.ChannelMode == ChannelModeSplit
+	a.consensus.Value = cfg.Consensus != ""
+	a.transliterate.Value = cfg.Transliteration.Method != ""
+	a.maxSegmentEditor.SetText("")
+	if cfg.Decode.MaxSegmentChars > 0 {
+		a.maxSegmentEditor.SetText(strconv.Itoa(cfg.Decode.MaxSegmentChars))
+	}
+	a.releaseWorker()
+	a.refreshOutput()
+	a.setStatus(fmt.Sprintf("Preset %s applied", name))
+}
+
+// saveCurrentPreset saves the options chosen in the window as a preset
+func (a *GioApp) saveCurrentPreset(name string, cfg AppConfig) {
+	preset, err := NewPreset(name, cfg)
+	if err == nil {
+		err = SavePreset(preset)
+	}
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Error saving the preset: %v", err))
+		return
+	}
+	presets, err := LoadPresets()
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Error reading the presets: %v", err))
+		return
+	}
+	a.uiMutex.Lock()
+	a.presets = presets
+	a.uiMutex.Unlock()
+	a.presetList.Value = preset.Name
+	a.setStatus(fmt.Sprintf("Preset %s saved", preset.Name))
+}
+
+// openHistory lists the latest completed transcriptions in the history panel
+func (a *GioApp) openHistory() {
+	entries, err := LoadHistory()
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Cannot read the history: %v", err))
+		return
+	}
+	if len(entries) > historyShown {
+		entries = entries[:historyShown]
+	}
+	a.uiMutex.Lock()
+	if len(entries) == 0 {
+		a.history = nil
+		a.statusText = "No completed transcriptions yet"
+	} else {
+		a.history = entries
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// openVoiceMemos lists the recent recordings of the Voice Memos app
+func (a *GioApp) openVoiceMemos() {
+	memos, err := ListVoiceMemos(recentVoiceMemos)
+	if err != nil {
+		a.setStatus(err.Error())
+		return
+	}
+	a.uiMutex.Lock()
+	if len(memos) == 0 {
+		a.statusText = "No Voice Memos recordings"
+	}
+	a.voiceMemos = memos
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// transcribeVoiceMemo selects a Voice Memos recording and transcribes it
+func (a *GioApp) transcribeVoiceMemo(memo VoiceMemo) {
+	if a.workerBusy() {
+		a.setStatus("Wait for the current task to finish before transcribing the memo")
+		return
+	}
+	a.uiMutex.Lock()
+	a.voiceMemos = nil
+	a.uiMutex.Unlock()
+	a.setAudioFile(memo.Path)
+	a.startTranscription()
+}
+
+// reopenFromHistory loads the saved transcript of a history entry, with its
+// recording selected for playback when it is still there, and with export set
+// offers to save it again
+func (a *GioApp) reopenFromHistory(entry HistoryEntry, export bool) {
+	if !a.claimWorker() {
+		a.setStatus("A transcription is already running")
+		return
+	}
+	segments, manifest, err := entry.LoadTranscript()
+	if err != nil {
+		a.releaseWorker()
+		a.setStatus(fmt.Sprintf("Error reopening: %v", err))
+		return
+	}
+	if _, err := os.Stat(entry.InputPath); err == nil {
+		a.setAudioFile(entry.InputPath)
+		a.audioDuration = entry.Duration
+	}
+	a.uiMutex.Lock()
+	a.history = nil
+	a.uiMutex.Unlock()
+	a.showLoadedTranscript(segments, manifest, &entry, fmt.Sprintf("Reopened the transcript of %s (%d segments)", filepath.Base(entry.InputPath), len(segments)))
+	a.releaseWorker()
+
+	if export {
+		a.saveTranscription()
+	}
+}
+
+// recordTranscript remembers a transcript of the whole recording saved to filePath,
+// so a copy of the recording under another name can reuse it. Transcripts without
+// the Hebrew, or of part of the recording, aren't remembered.
+func (a *GioApp) recordTranscript(filePath string) {
+	a.uiMutex.RLock()
+	manifest := a.lastManifest
+	a.uiMutex.RUnlock()
+	inputPath := a.audioFilePath
+	if manifest == nil || manifest.Input != filepath.Base(inputPath) {
+		return // Not transcribed here, or another file was selected since
+	}
+	params := manifest.Parameters
+	if len(params.Parts) > 0 || params.From > 0 || params.To > 0 || a.wallClock.Value ||
+		(params.TranslateTo != "" && a.displayMode.Value == DisplayTranslation) ||
+		(params.Transliteration != "" && a.config.Transliteration.Only) {
+		return
+	}
+	go func() {
+		if err := RecordTranscript(inputPath, filePath, manifest.Model); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Cannot remember the transcript for reuse: %v\n", err)
+		}
+	}()
+}
+
+// transcribeClipboardOffer transcribes the media offered from the clipboard, downloading URLs first
+func (a *GioApp) transcribeClipboardOffer() {
+	a.uiMutex.Lock()
+	offer := a.clipboardOffer
+	a.clipboardOffer = ""
+	a.uiMutex.Unlock()
+	if offer == "" {
+		return
+	}
+
+	if a.workerBusy() {
+		a.setStatus("A transcription is already running")
+		return
+	}
+
+	filePath := offer
+	if strings.Contains(offer, "://") {
+		downloaded, err := DownloadMedia(offer, func(msg string, pct int) {
+			a.uiMutex.Lock()
+			a.statusText = fmt.Sprintf("%s (%d%%)", msg, pct)
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+		})
+		if err != nil {
+			a.uiMutex.Lock()
+			a.statusText = fmt.Sprintf("Error downloading %s: %v", offer, err)
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+			return
+		}
+		a.removeDownloadedMedia()
+		a.downloadDir = filepath.Dir(downloaded)
+		filePath = downloaded
+	}
+
+	// One may have started while the media downloaded: don't replace its input
+	if a.workerBusy() {
+		a.setStatus("A transcription is already running; " + filepath.Base(filePath) + " was not opened")
+		return
+	}
+	a.setAudioFile(filePath)
+	a.startTranscription()
+}
+
+// addRecordingPart asks for the next file of the selected recording and joins it on
+func (a *GioApp) addRecordingPart() {
+	filePath, err := dialog.File().
+		Title("Choose the next part of the recording").
+		Filter("Audio/Video Files", mediaExtensions...).
+		Filter("All Files", "*").
+		SetStartDir(filepath.Dir(a.audioFilePath)).
+		Load()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening file dialog: %v", err))
+		}
+		return
+	}
+
+	paths := a.partPaths
+	if len(paths) == 0 {
+		paths = []string{a.audioFilePath}
+	}
+	a.joinRecording(append(append([]string{}, paths...), filePath))
+}
+
+// joinFolder asks for a folder of audio files, e.g. an audio CD, and joins them in name order
+func (a *GioApp) joinFolder() {
+	dir, err := dialog.Directory().Title("Choose a folder of audio files, e.g. an audio CD").Browse()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening folder dialog: %v", err))
+		}
+		return
+	}
+	paths, err := RecordingPartsInDir(dir)
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	if len(paths) == 1 {
+		a.setAudioFile(paths[0])
+		return
+	}
+	a.joinRecording(paths)
+}
+
+// joinRecording joins the files of one recording and selects the result, so they are
+// transcribed as one with continuous timestamps
+func (a *GioApp) joinRecording(paths []string) {
+	if !a.claimWorker() {
+		a.setStatus("A transcription is already running")
+		return
+	}
+	defer a.releaseWorker()
+
+	joined, parts, err := JoinRecording(paths, a.setStatus)
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	a.setAudioFile(joined)
+	a.joinDir = filepath.Dir(joined)
+	a.partPaths = paths
+	last := parts[len(parts)-1]
+	a.uiMutex.Lock()
+	a.recordingParts = parts
+	a.statusText = fmt.Sprintf("Joined %d files (%s)", len(parts), FormatTimestamp(last.Start+last.Duration, true)[:8])
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// removeJoinedRecording deletes the last joined recording and forgets its parts
+func (a *GioApp) removeJoinedRecording() {
+	if a.joinDir != "" {
+		RemoveTempFile(a.joinDir)
+		a.joinDir = ""
+	}
+	a.partPaths = nil
+	a.uiMutex.Lock()
+	a.recordingParts = nil
+	a.uiMutex.Unlock()
+}
+
+// removeDownloadedMedia deletes the last media file downloaded from a URL
+func (a *GioApp) removeDownloadedMedia() {
+	if a.downloadDir != "" {
+		os.RemoveAll(a.downloadDir)
+		a.downloadDir = ""
+	}
+}
+
+// startTranscription starts transcription
+// openRecording opens the recording panel with the inputs of the system, the one
+// recorded from last chosen
+func (a *GioApp) openRecording() {
+	devices, err := ListRecordingDevices()
+	if err != nil {
+		a.setStatus(fmt.Sprintf("Cannot list microphones: %v", err))
+		return
+	}
+	a.settings.Lock()
+	last := a.settings.RecordingDevice
+	a.settings.Unlock()
+
+	a.uiMutex.Lock()
+	a.recordingDevices = devices
+	a.recordDevice.Value = "0"
+	for i, device := range devices {
+		if device.Name == last {
+			a.recordDevice.Value = strconv.Itoa(i)
+		}
+	}
+	a.recordingOpen = true
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// startRecording records the chosen input to a WAV file in the output folder, and
+// in live mode transcribes it as it is recorded
+func (a *GioApp) startRecording() {
+	a.uiMutex.RLock()
+	index, _ := strconv.Atoi(a.recordDevice.Value)
+	devices := a.recordingDevices
+	a.uiMutex.RUnlock()
+	if index < 0 || index >= len(devices) {
+		return
+	}
+	device := devices[index]
+	a.updateSettings(func(s *Settings) { s.RecordingDevice = device.Name })
+
+	// Live mode loads the model before recording starts, and holds the worker
+	// until the recording's transcript is finished
+	var engine TranscriptionEngine
+	modelID := a.modelList.Value
+	if a.recordLive.Value {
+		a.workerMutex.Lock()
+		running := a.workerRunning
+		if !running {
+			a.workerRunning = true
+			a.stopRequested = false
+		}
+		a.workerMutex.Unlock()
+		if running {
+			a.setStatus("Wait for the current task to finish before transcribing live")
+			return
+		}
+		a.setStatus("Loading the model for live transcription...")
+		var err error
+		engine, err = NewTranscriptionEngine(a.config, modelID, func(msg string, pct int) { a.setStatus(msg) })
+		if err != nil {
+			a.workerMutex.Lock()
+			a.workerRunning = false
+			a.workerMutex.Unlock()
+			a.setStatus("Error: " + err.Error())
+			return
+		}
+		engine.SetDecodeOptions(a.config.Decode)
+	}
+
+	a.settings.Lock()
+	cpuThreads := a.config.CPUThreads(modelID, a.settings.TunedThreads)
+	a.settings.Unlock()
+
+	rec, err := StartRecording(device, recordingFileName(a.config.OutputDir, time.Now()))
+	if err != nil {
+		if engine != nil {
+			engine.Close()
+			a.workerMutex.Lock()
+			a.workerRunning = false
+			a.workerMutex.Unlock()
+		}
+		a.setStatus(err.Error())
+		return
+	}
+	a.uiMutex.Lock()
+	a.recorder = rec
+	if engine != nil {
+		a.transcriptionSegments = nil
+		a.originalSegments = nil
+		a.savedFilePath = ""
+		a.lastManifest = nil
+		a.outputEditor.SetText("")
+		a.livePartial = ""
+		a.liveTranscriber = StartLiveTranscription(rec, engine, modelID, cpuThreads, func(seg Segment) {
+			a.appendSegment(seg)
+			a.window.Invalidate()
+		}, func(partial string) {
+			a.uiMutex.Lock()
+			a.livePartial = partial
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+		})
+	}
+	a.uiMutex.Unlock()
+	a.setStatus("Recording from " + device.Name)
+
+	// A device unplugged or ffmpeg failing ends the recording early; what was
+	// recorded is kept and selected
+	go func() {
+		<-rec.Done()
+		a.uiMutex.RLock()
+		current := a.recorder == rec
+		a.uiMutex.RUnlock()
+		if current {
+			a.stopRecording(false)
+		}
+	}()
+}
+
+// stopRecording ends the recording in progress and selects the file, transcribing
+// it if asked to (in live mode, finishing its transcript). The file stays where it
+// was recorded, so the audio isn't lost if the transcription fails.
+func (a *GioApp) stopRecording(transcribe bool) {
+	a.uiMutex.Lock()
+	rec, live := a.recorder, a.liveTranscriber
+	a.recorder, a.liveTranscriber = nil, nil
+	a.uiMutex.Unlock()
+	if rec == nil {
+		return
+	}
+
+	err := rec.Stop()
+	var segments []Segment
+	var liveErr error
+	if live != nil {
+		a.setStatus("Transcribing the end of the recording...")
+		segments, liveErr = live.Finish()
+		a.workerMutex.Lock()
+		a.workerRunning = false
+		a.workerMutex.Unlock()
+	}
+	if _, statErr := os.Stat(rec.Path); statErr != nil {
+		a.setStatus(fmt.Sprintf("Recording failed: %v", err))
+		return
+	}
+	a.uiMutex.Lock()
+	a.recordingOpen = false
+	a.uiMutex.Unlock()
+	a.setAudioFile(rec.Path)
+
+	a.workerMutex.Lock()
+	running := a.workerRunning
+	a.workerMutex.Unlock()
+	switch {
+	case live != nil && liveErr != nil:
+		a.setStatus(fmt.Sprintf("Live transcription failed: %v. The recording is saved to %s", liveErr, rec.Path))
+	case live != nil:
+		a.showLoadedTranscript(segments, nil, nil, "Live transcription complete; the recording is saved to "+rec.Path)
+	case err != nil:
+		a.setStatus(fmt.Sprintf("Recording stopped: %v. Saved to %s", err, rec.Path))
+	case !transcribe || running:
+		a.setStatus("Recording saved to " + rec.Path)
+	default:
+		a.startTranscription()
+	}
+}
+
+func (a *GioApp) startTranscription() {
+	if a.audioFilePath == "" {
+		return
+	}
+
+	a.workerMutex.Lock()
+	if a.workerRunning {
+		a.workerMutex.Unlock()
+		return // Already transcribing, e.g. Enter pressed twice
+	}
+	a.workerRunning = true
+	a.stopRequested = false // Reset stop flag
+	a.workerMutex.Unlock()
+
+	// Remember the model so it is selected (and preloaded) next launch
+	modelID := a.modelList.Value
+	a.updateSettings(func(s *Settings) { s.DefaultModel = modelID })
+
+	a.uiMutex.Lock()
+	a.statusText = "Transcribing..."
+	a.transcriptionStartTime = time.Now().Unix()
+	a.transcriptionSegments = nil // Clear previous transcription
+	a.savedFilePath = ""
+	a.originalSegments = nil      // Clear previous original segments
+	a.lastManifest = nil
+	a.consensusReview = nil
+	a.translationReview = nil
+	a.glossaryMisses = nil
+	a.corrections = nil
+	a.acceptedCorrections = nil
+	a.player.Stop()
+	a.playingIndex = -1
+	if a.presentation != nil {
+		a.presentation.Clear()
+	}
+	a.uiMutex.Unlock()
+
+	go a.runTranscription()
+}
+
+// openPresentation opens the live captions window, or brings it to the front if already open
+func (a *GioApp) openPresentation() {
+	a.uiMutex.Lock()
+	defer a.uiMutex.Unlock()
+
+	if a.presentation != nil && !a.presentation.Closed() {
+		a.presentation.Raise()
+		return
+	}
+	a.presentation = OpenPresentationWindow()
+}
+
+// stopTranscription stops transcription
+func (a *GioApp) stopTranscription() {
+	a.workerMutex.Lock()
+	a.stopRequested = true // Request stop
+	a.workerMutex.Unlock()
+
+	a.uiMutex.Lock()
+	a.statusText = "Stopping..."
+	a.uiMutex.Unlock()
+}
+
+// saveTranscription saves transcription using cross-platform dialog library
+func (a *GioApp) saveTranscription() {
+	if len(a.transcriptionSegments) == 0 {
+		a.uiMutex.Lock()
+		a.statusText = "No transcription to save"
+		a.uiMutex.Unlock()
+		return
+	}
+
+	format := a.formatList.Value
+	ext := format
+	filterName := "Text Files"
+	if format == "json" {
+		ext = "json"
+		filterName = "JSON Files"
+	} else if format == "srt" {
+		ext = "srt"
+		filterName = "SRT Subtitle Files"
+	} else if format == "vtt" {
+		ext = "vtt"
+		filterName = "WebVTT Subtitle Files"
+	} else if format == "markdown" {
+		ext = "md"
+		filterName = "Markdown Files"
+	} else if format == "html" {
+		ext = "html"
+		filterName = "HTML Files"
+	} else if format == "ttml" {
+		ext = "ttml"
+		filterName = "TTML Subtitle Files"
+	} else if format == "stl" {
+		ext = "stl"
+		filterName = "EBU-STL Subtitle Files"
+	} else if format == FormatCustom {
+		ext = customExtension()
+		filterName = "Custom Export Files"
+	} else {
+		ext = "txt"
+		filterName = "Text Files"
+	}
+
+	defaultName := "transcription." + ext
+
+	// Open save dialog with appropriate file filter
+	filePath, err := dialog.File().
+		Title("Save transcription").
+		Filter(filterName, ext).
+		Filter("All Files", "*").
+		SetStartFile(defaultName).
+		SetStartDir(a.config.OutputDir).
+		Save()
+
+	if err != nil {
+		// User cancelled or error occurred
+		if err.Error() != "Cancelled" {
+			a.uiMutex.Lock()
+			a.statusText = fmt.Sprintf("Error opening save dialog: %v", err)
+			a.uiMutex.Unlock()
+		}
+		return
+	}
+
+	// Ensure file has the correct extension
+	if !strings.HasSuffix(filePath, "."+ext) {
+		filePath = filePath + "." + ext
+	}
+
+	outputText := a.transcriptText(format, filePath)
+	if format == FormatCustom {
+		// FormatOutput leaves script errors out, so run it again to report them
+		if _, err := FormatScript(ApplyDisplayMode(a.shownSegments(a.outputSegments()), a.displayMode.Value)); err != nil {
+			a.setStatus(fmt.Sprintf("Error running the export script: %v", err))
+			return
+		}
+	}
+	if format == "html" {
+		a.uiMutex.Lock()
+		a.statusText = "Embedding audio..."
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+
+		audio, mimeType, err := EncodePlayerAudio(a.audioFilePath)
+		if err == nil {
+			outputText, err = FormatHTML(transcriptTitle(a.audioFilePath), ApplyDisplayMode(a.shownSegments(a.outputSegments()), a.displayMode.Value), audio, mimeType)
+		}
+		if err != nil {
+			a.uiMutex.Lock()
+			a.statusText = fmt.Sprintf("Error creating HTML player: %v", err)
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+			return
+		}
+	}
+	if err := os.WriteFile(filePath, a.config.OutputData(outputText, format), 0644); err != nil {
+		a.uiMutex.Lock()
+		a.statusText = fmt.Sprintf("Error saving file: %v", err)
+		a.uiMutex.Unlock()
+		return
+	}
+
+	a.recordTranscript(filePath)
+	a.recordHistoryOutput(filePath)
+
+	status := "Transcription saved to " + filepath.Base(filePath)
+	if a.redact.Value {
+		redactedPath, count, err := a.saveRedactedCopy(filePath, format)
+		if err != nil {
+			status = fmt.Sprintf("Transcription saved, but the redacted copy failed: %v", err)
+		} else {
+			status = fmt.Sprintf("Saved %s and %s (%d items masked)", filepath.Base(filePath), filepath.Base(redactedPath), count)
+		}
+	}
+
+	a.uiMutex.Lock()
+	a.statusText = status
+	a.savedFilePath = filePath
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// transcriptText formats the transcript for saving to filePath, with the manifest,
+// speaker statistics and consensus review where the format has room for them
+func (a *GioApp) transcriptText(format, filePath string) string {
+	segments := a.shownSegments(a.outputSegments())
+	outputText := FormatOutput(segments, format, a.displayMode.Value)
+	a.uiMutex.RLock()
+	manifest := a.lastManifest
+	review := a.consensusReview
+	parts := a.recordingParts
+	a.uiMutex.RUnlock()
+	if format == "json" && manifest != nil {
+		outputText = AttachManifest(outputText, *manifest)
+	}
+	if format == "markdown" {
+		outputText = FormatMarkdown(ApplyDisplayMode(segments, a.displayMode.Value), markdownMediaURL("", a.audioFilePath, filePath))
+	}
+	if a.speakerStats.Value {
+		outputText, _ = AppendSpeakerStats(outputText, a.transcriptionSegments, format)
+	}
+	if review != nil {
+		outputText, _ = AppendConsensusReview(outputText, *review, format)
+	}
+	if len(parts) > 0 {
+		outputText, _ = MarkRecordingParts(outputText, parts, format)
+	}
+	outputText = a.withWallClock(outputText, format)
+	return outputText
+}
+
+// saveAllFormats saves the transcript as text, SRT, VTT and JSON files named after
+// the file chosen (see the CLI's -format all)
+func (a *GioApp) saveAllFormats() {
+	if len(a.transcriptionSegments) == 0 {
+		a.setStatus("No transcription to save")
+		return
+	}
+
+	filePath, err := dialog.File().
+		Title("Export all formats").
+		Filter("Text Files", "txt").
+		SetStartFile("transcription.txt").
+		SetStartDir(a.config.OutputDir).
+		Save()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening save dialog: %v", err))
+		}
+		return
+	}
+
+	var names []string
+	for _, format := range allFormats {
+		formatPath := formatFileName(filePath, format)
+		if err := os.WriteFile(formatPath, a.config.OutputData(a.transcriptText(format, formatPath), format), 0644); err != nil {
+			a.setStatus(fmt.Sprintf("Error saving file: %v", err))
+			return
+		}
+		if a.redact.Value {
+			if _, _, err := a.saveRedactedCopy(formatPath, format); err != nil {
+				a.setStatus(fmt.Sprintf("Saved %s, but the redacted copy failed: %v", filepath.Base(formatPath), err))
+				return
+			}
+		}
+		a.recordTranscript(formatPath)
+		names = append(names, filepath.Base(formatPath))
+	}
+	a.recordHistoryOutput(formatFileName(filePath, "json"))
+
+	status := "Saved " + strings.Join(names, ", ")
+	if a.redact.Value {
+		status += " with redacted copies"
+	}
+	a.uiMutex.Lock()
+	a.statusText = status
+	a.savedFilePath = formatFileName(filePath, "text")
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// saveBySpeaker saves one file per speaker in the selected format, each with only
+// that speaker's segments (see the CLI's -split-speakers)
+func (a *GioApp) saveBySpeaker() {
+	speakers := SplitBySpeaker(a.shownSegments(a.outputSegments()))
+	if len(speakers) < 2 {
+		a.setStatus("The transcript has only one speaker")
+		return
+	}
+	format := a.formatList.Value
+	if format == "html" {
+		a.setStatus("Per-speaker files can't be saved as HTML, choose another format")
+		return
+	}
+
+	ext := formatExtension(format)
+	filePath, err := dialog.File().
+		Title("Save one file per speaker").
+		Filter("Transcript Files", ext).
+		SetStartFile("transcription." + ext).
+		SetStartDir(a.config.OutputDir).
+		Save()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening save dialog: %v", err))
+		}
+		return
+	}
+	if !strings.HasSuffix(filePath, "."+ext) {
+		filePath += "." + ext
+	}
+
+	var names []string
+	for _, speaker := range speakers {
+		speakerPath := speakerFileName(filePath, speaker.Speaker)
+		speakerText := FormatSpeakerTranscript(speaker, format, a.displayMode.Value, markdownMediaURL("", a.audioFilePath, speakerPath))
+		if err := os.WriteFile(speakerPath, a.config.OutputData(speakerText, format), 0644); err != nil {
+			a.setStatus(fmt.Sprintf("Error saving file: %v", err))
+			return
+		}
+		names = append(names, filepath.Base(speakerPath))
+	}
+
+	a.uiMutex.Lock()
+	a.statusText = "Saved " + strings.Join(names, ", ")
+	a.savedFilePath = speakerFileName(filePath, speakers[0].Speaker)
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// saveSubtitleTracks saves a VTT file per language of a translated transcript, with
+// an HLS manifest listing them (see the CLI's -vtt-tracks)
+func (a *GioApp) saveSubtitleTracks() {
+	segments := a.shownSegments(a.outputSegments())
+	if SubtitleTracks(segments, a.config.TargetLang) == nil {
+		a.setStatus("The transcript isn't translated")
+		return
+	}
+
+	filePath, err := dialog.File().
+		Title("Save subtitle tracks").
+		Filter("HLS Manifests", "m3u8").
+		SetStartFile("transcription.m3u8").
+		SetStartDir(a.config.OutputDir).
+		Save()
+	if err != nil {
+		if err.Error() != "Cancelled" {
+			a.setStatus(fmt.Sprintf("Error opening save dialog: %v", err))
+		}
+		return
+	}
+
+	var names []string
+	files := SubtitleTrackFiles(segments, a.config.TargetLang, filePath)
+	for _, file := range files {
+		data := []byte(file.Text)
+		if file.Format == "vtt" {
+			data = a.config.OutputData(file.Text, file.Format)
+		}
+		if err := os.WriteFile(file.Path, data, 0644); err != nil {
+			a.setStatus(fmt.Sprintf("Error saving file: %v", err))
+			return
+		}
+		names = append(names, filepath.Base(file.Path))
+	}
+
+	a.uiMutex.Lock()
+	a.statusText = "Saved " + strings.Join(names, ", ")
+	a.savedFilePath = files[len(files)-1].Path // The manifest
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// saveRedactedCopy writes the transcript with sensitive details masked next to filePath,
+// without audio or links to it (see the CLI's -redact)
+func (a *GioApp) saveRedactedCopy(filePath, format string) (string, int, error) {
+	words, err := a.config.Redact.WordList()
+	if err != nil {
+		return "", 0, err
+	}
+	redactor := NewRedactor(words)
+	segments, count := redactor.RedactSegments(a.shownSegments(a.outputSegments()))
+	outputText := FormatOutput(segments, format, a.displayMode.Value)
+	a.uiMutex.RLock()
+	manifest := a.lastManifest
+	review := a.consensusReview
+	parts := a.recordingParts
+	a.uiMutex.RUnlock()
+	if format == "json" && manifest != nil {
+		outputText = AttachManifest(outputText, *manifest)
+	}
+	if a.speakerStats.Value {
+		outputText, _ = AppendSpeakerStats(outputText, segments, format)
+	}
+	if review != nil {
+		outputText, _ = AppendConsensusReview(outputText, redactor.RedactReview(*review), format)
+	}
+	if len(parts) > 0 {
+		outputText, _ = MarkRecordingParts(outputText, parts, format)
+	}
+	outputText = a.withWallClock(outputText, format)
+
+	redactedPath := redactedFileName(filePath)
+	if err := os.WriteFile(redactedPath, a.config.OutputData(outputText, format), 0644); err != nil {
+		return "", 0, err
+	}
+	return redactedPath, count, nil
+}
+
+// runFileAction reveals or opens a saved file, reporting failures in the status line
+func (a *GioApp) runFileAction(action func(string) error, filePath string) {
+	if err := action(filePath); err != nil {
+		a.uiMutex.Lock()
+		a.statusText = fmt.Sprintf("Error: %v", err)
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+	}
+}
+
+// exportTranscription pushes the transcript to a new Google Doc or Notion page and opens it
+func (a *GioApp) exportTranscription(target, name string) {
+	// Copy the transcript first: it runs on its own goroutine while the transcript
+	// may be edited or replaced
+	a.uiMutex.Lock()
+	if len(a.transcriptionSegments) == 0 {
+		a.statusText = "No transcription to export"
+		a.uiMutex.Unlock()
+		return
+	}
+	title := transcriptTitle(a.audioFilePath)
+	segments := append([]Segment(nil), ApplyDisplayMode(a.outputSegments(), a.displayMode.Value)...)
+	a.statusText = fmt.Sprintf("Exporting to %s...", name)
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+
+	url, err := Export(target, title, segments)
+
+	a.uiMutex.Lock()
+	if err != nil {
+		a.statusText = fmt.Sprintf("Export to %s failed: %v", name, err)
+	} else {
+		a.statusText = fmt.Sprintf("Exported to %s", name)
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+
+	if err == nil {
+		openURL(url)
+	}
+}
+
+// selectSegmentToFix opens the re-transcription settings for the segment at the
+// transcript cursor, starting from a bigger model and beam search
+func (a *GioApp) selectSegmentToFix() {
+	a.workerMutex.Lock()
+	running := a.workerRunning
+	a.workerMutex.Unlock()
+
+	a.uiMutex.Lock()
+	defer a.uiMutex.Unlock()
+	if running || len(a.transcriptionSegments) == 0 {
+		a.statusText = "Transcribe a file first, then click on the segment to fix"
+		return
+	}
+	caret, _ := a.outputEditor.Selection()
+	index := SegmentAt(a.outputEditor.Text(), a.outputSegments(), caret)
+	if index < 0 {
+		a.statusText = "Click on the segment to fix in the transcript first"
+		return
+	}
+
+	a.retranscribeIndex = index
+	a.retranscribeModel.Value = "large-v3"
+	beamSize := a.config.Decode.BeamSize
+	if beamSize <= 1 {
+		beamSize = 5
+	}
+	a.retranscribeBeam.SetText(strconv.Itoa(beamSize))
+	a.retranscribePrompt.SetText(a.config.Decode.InitialPrompt)
+}
+
+// retranscribeSegment re-runs whisper on one segment's audio with other settings and
+// replaces its text in place
+func (a *GioApp) retranscribeSegment(index int, modelID string, decode DecodeOptions) {
+	if !a.claimWorker() {
+		return
+	}
+	defer a.releaseWorker()
+	if index < 0 || index >= len(a.transcriptionSegments) {
+		return
+	}
+	seg := a.transcriptionSegments[index]
+
+	engine, err := a.segmentEngine(modelID)
+	if err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	defer engine.Close()
+	engine.SetTimeRange(RetranscribeWindow(seg))
+	engine.SetDecodeOptions(decode)
+
+	a.setStatus(fmt.Sprintf("Re-transcribing segment at %s with %s...", FormatTimestamp(seg.Start, true)[:8], modelID))
+	result, err := engine.Transcribe(a.audioFilePath, modelID, a.cpuThreads(modelID), nil, nil)
+	if err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	hebrew := RetranscribedText(result)
+	if hebrew == "" {
+		a.setStatus("Re-transcription found no speech; segment unchanged")
+		return
+	}
+
+	if err := a.replaceSegment(index, hebrew); err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	a.setStatus(fmt.Sprintf("Segment at %s re-transcribed with %s", FormatTimestamp(seg.Start, true)[:8], modelID))
+}
+
+// showAlternatives looks for alternative readings of the segment at the transcript cursor
+func (a *GioApp) showAlternatives() {
+	a.workerMutex.Lock()
+	running := a.workerRunning
+	a.workerMutex.Unlock()
+
+	a.uiMutex.Lock()
+	defer a.uiMutex.Unlock()
+	if running || len(a.transcriptionSegments) == 0 {
+		return
+	}
+	caret, _ := a.outputEditor.Selection()
+	index := SegmentAt(a.outputEditor.Text(), a.outputSegments(), caret)
+	if index < 0 {
+		a.statusText = "Click on a segment, then right-click for alternative readings"
+		return
+	}
+	a.alternativesIndex = index
+	a.alternatives = nil
+	go a.findAlternatives(index)
+}
+
+// findAlternatives decodes a segment's audio in other ways and offers the readings found
+func (a *GioApp) findAlternatives(index int) {
+	if !a.claimWorker() {
+		return
+	}
+	defer a.releaseWorker()
+	if index < 0 || index >= len(a.transcriptionSegments) {
+		return
+	}
+	seg := a.transcriptionSegments[index]
+	modelID := a.modelList.Value
+
+	engine, err := a.segmentEngine(modelID)
+	if err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	defer engine.Close()
+
+	a.setStatus(fmt.Sprintf("Finding alternative readings of the segment at %s...", FormatTimestamp(seg.Start, true)[:8]))
+	alternatives, err := SegmentAlternatives(engine, a.audioFilePath, modelID, a.cpuThreads(modelID), seg, a.config.Decode)
+
+	a.uiMutex.Lock()
+	switch {
+	case a.alternativesIndex != index:
+		// Dismissed meanwhile
+	case err != nil && len(alternatives) == 0:
+		a.alternativesIndex = -1
+		a.statusText = "Error: " + err.Error()
+	case len(alternatives) == 0:
+		a.alternativesIndex = -1
+		a.statusText = "No other readings found for this segment"
+	default:
+		a.alternatives = alternatives
+		a.statusText = fmt.Sprintf("%d alternative readings found", len(alternatives))
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// pickAlternative replaces a segment with the chosen alternative reading
+func (a *GioApp) pickAlternative(index int, reading string) {
+	if !a.claimWorker() {
+		return
+	}
+	defer a.releaseWorker()
+	if err := a.replaceSegment(index, reading); err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	a.setStatus("Segment replaced with the alternative reading")
+}
+
+// replaceSegment replaces a segment's Hebrew text in place, translating it again if
+// the transcript was translated, and redisplays the transcript
+func (a *GioApp) replaceSegment(index int, hebrew string) error {
+	seg := a.transcriptionSegments[index]
+	translation := ""
+	if seg.Translation != "" {
+		a.setStatus("Translating the new text...")
+		translator, err := a.config.NewTranslator(a.translateLangList.Value)
+		if err != nil {
+			return fmt.Errorf("translation failed: %v", err)
+		}
+		if translation, err = translator.Translate(hebrew, a.translateLangList.Value, nil); err != nil {
+			return fmt.Errorf("translation failed: %v", err)
+		}
+	}
+
+	a.transcriptionSegments[index] = ReplaceSegmentText(seg, hebrew, translation)
+	a.uiMutex.Lock()
+	a.outputEditor.SetText(a.displayText(a.transcriptionSegments))
+	a.uiMutex.Unlock()
+	return nil
+}
+
+// selectTranslationToEdit opens the translation of the segment at the transcript cursor for editing
+func (a *GioApp) selectTranslationToEdit() {
+	a.workerMutex.Lock()
+	running := a.workerRunning
+	a.workerMutex.Unlock()
+
+	a.uiMutex.Lock()
+	defer a.uiMutex.Unlock()
+	if running || !IsTranslated(a.transcriptionSegments) {
+		a.statusText = "Translate a transcript first, then click on the segment to edit"
+		return
+	}
+	caret, _ := a.outputEditor.Selection()
+	index := SegmentAt(a.outputEditor.Text(), a.outputSegments(), caret)
+	if index < 0 || a.transcriptionSegments[index].Translation == "" {
+		a.statusText = "Click on the translated segment to edit in the transcript first"
+		return
+	}
+
+	a.translationIndex = index
+	a.translationEditor.SetText(a.transcriptionSegments[index].Translation)
+}
+
+// editTranslation replaces the translation of a segment with the user's edit, keeping
+// its Hebrew, and redisplays the transcript
+func (a *GioApp) editTranslation(index int, translation string) {
+	translation = strings.TrimSpace(translation)
+	if !a.claimWorker() {
+		return
+	}
+	defer a.releaseWorker()
+	if index < 0 || index >= len(a.transcriptionSegments) || translation == "" {
+		return
+	}
+
+	a.transcriptionSegments[index] = ReplaceSegmentTranslation(a.transcriptionSegments[index], translation)
+	a.uiMutex.Lock()
+	a.outputEditor.SetText(a.displayText(a.transcriptionSegments))
+	a.uiMutex.Unlock()
+	a.setStatus(fmt.Sprintf("Translation at %s updated", FormatTimestamp(a.transcriptionSegments[index].Start, true)[:8]))
+}
+
+// checkSpelling asks the local LLM for corrections of obvious recognition errors,
+// which are then reviewed one at a time before any text is replaced
+func (a *GioApp) checkSpelling() {
+	if len(a.transcriptionSegments) == 0 || !a.claimWorker() {
+		return
+	}
+	defer a.releaseWorker()
+
+	a.setStatus("Checking the transcript for recognition errors...")
+	corrections, err := NewMistralTranslator().CorrectSegments(a.transcriptionSegments, a.setStatus)
+
+	a.uiMutex.Lock()
+	a.corrections = corrections
+	a.correctionIndex = 0
+	a.acceptedCorrections = nil
+	switch {
+	case err != nil && len(corrections) == 0:
+		a.statusText = "Error: " + err.Error()
+	case err != nil:
+		a.statusText = fmt.Sprintf("Spell check stopped early (%v); review the %d corrections found", err, len(corrections))
+	case len(corrections) == 0:
+		a.statusText = "No corrections proposed"
+	default:
+		a.statusText = fmt.Sprintf("%d corrections proposed; review them below", len(corrections))
+	}
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// reviewCorrection accepts or skips the correction under review (or all remaining
+// ones), and applies the accepted corrections once the last one is reviewed
+func (a *GioApp) reviewCorrection(accept, remaining bool) {
+	a.uiMutex.Lock()
+	defer a.uiMutex.Unlock()
+	if a.correctionIndex >= len(a.corrections) {
+		return
+	}
+	end := a.correctionIndex + 1
+	if remaining {
+		end = len(a.corrections)
+	}
+	if accept {
+		a.acceptedCorrections = append(a.acceptedCorrections, a.corrections[a.correctionIndex:end]...)
+	}
+	a.correctionIndex = end
+	if a.correctionIndex < len(a.corrections) {
+		return
+	}
+
+	accepted := a.acceptedCorrections
+	a.corrections = nil
+	a.acceptedCorrections = nil
+	if len(accepted) == 0 {
+		a.statusText = "No corrections accepted"
+		return
+	}
+	go a.applyCorrections(accepted)
+}
+
+// applyCorrections replaces the text of approved corrections. Segments changed since
+// the spell check (e.g. re-transcribed) are left alone.
+func (a *GioApp) applyCorrections(corrections []SegmentCorrection) {
+	if !a.claimWorker() {
+		a.setStatus("Busy; corrections not applied")
+		return
+	}
+	defer a.releaseWorker()
+
+	applied := 0
+	for _, c := range corrections {
+		if c.Index >= len(a.transcriptionSegments) {
+			continue
+		}
+		if text, _ := segmentHebrew(a.transcriptionSegments[c.Index]); text != c.Before {
+			continue
+		}
+		if err := a.replaceSegment(c.Index, c.After); err != nil {
+			a.setStatus("Error: " + err.Error())
+			return
+		}
+		applied++
+	}
+	a.setStatus(fmt.Sprintf("Applied %d corrections", applied))
+}
+
+// playSegmentAtCursor plays the segment at the transcript cursor, or stops playback
+func (a *GioApp) playSegmentAtCursor() {
+	a.uiMutex.Lock()
+	if a.playingIndex >= 0 {
+		a.uiMutex.Unlock()
+		a.stopPlayback()
+		return
+	}
+	if len(a.transcriptionSegments) == 0 {
+		a.statusText = "Transcribe a file first, then click on the segment to play"
+		a.uiMutex.Unlock()
+		return
+	}
+	caret, _ := a.outputEditor.Selection()
+	index := SegmentAt(a.outputEditor.Text(), a.outputSegments(), caret)
+	if index < 0 {
+		a.statusText = "Click on the segment to play in the transcript first"
+		a.uiMutex.Unlock()
+		return
+	}
+	a.uiMutex.Unlock()
+	a.toggleSegmentPlayback(index)
+}
+
+// toggleSegmentPlayback plays a segment's audio, or stops it if it is playing
+func (a *GioApp) toggleSegmentPlayback(index int) {
 	a.uiMutex.Lock()
-	a.statusText = "File selected: " + filepath.Base(filePath)
+	if a.playingIndex == index {
+		a.uiMutex.Unlock()
+		a.stopPlayback()
+		return
+	}
+	if index < 0 || index >= len(a.transcriptionSegments) {
+		a.uiMutex.Unlock()
+		return
+	}
+	seg := a.transcriptionSegments[index]
+	a.playingIndex = index
+	a.statusText = fmt.Sprintf("Playing segment at %s", FormatTimestamp(seg.Start, true)[:8])
 	a.uiMutex.Unlock()
 
-	// Get audio duration in background
+	finished := func(err error) {
+		a.uiMutex.Lock()
+		if a.playingIndex == index {
+			a.playingIndex = -1
+		}
+		if err != nil {
+			a.statusText = "Error: playback failed: " + err.Error()
+		}
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+	}
+	// Cutting the segment with ffmpeg takes a moment without ffplay
 	go func() {
-		duration, err := getAudioDuration(filePath)
-		if err == nil {
-			a.audioDuration = duration
+		if err := a.player.Play(a.audioFilePath, seg, finished); err != nil {
+			finished(err)
 		}
 	}()
 }
 
-// startTranscription starts transcription
-func (a *GioApp) startTranscription() {
-	if a.audioFilePath == "" {
-		return
-	}
+// stopPlayback stops the segment playing, if any
+func (a *GioApp) stopPlayback() {
+	a.player.Stop()
+	a.uiMutex.Lock()
+	a.playingIndex = -1
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
+}
+
+// cpuThreads returns the thread count to run a model with
+func (a *GioApp) cpuThreads(modelID string) int {
+	a.settings.Lock()
+	defer a.settings.Unlock()
+	return a.config.CPUThreads(modelID, a.settings.TunedThreads)
+}
 
+// segmentEngine loads a model for working on single segments
+func (a *GioApp) segmentEngine(modelID string) (TranscriptionEngine, error) {
+	return NewTranscriptionEngine(a.config, modelID, func(msg string, pct int) { a.setStatus(msg) })
+}
+
+// claimWorker marks the session busy for a background job, unless one is running
+func (a *GioApp) claimWorker() bool {
 	a.workerMutex.Lock()
+	defer a.workerMutex.Unlock()
+	if a.workerRunning {
+		return false
+	}
 	a.workerRunning = true
-	a.stopRequested = false // Reset stop flag
-	a.workerMutex.Unlock()
-
-	a.uiMutex.Lock()
-	a.statusText = "Transcribing..."
-	a.transcriptionStartTime = time.Now().Unix()
-	a.transcriptionSegments = nil // Clear previous transcription
-	a.originalSegments = nil      // Clear previous original segments
-	a.uiMutex.Unlock()
+	return true
+}
 
-	go a.runTranscription()
+// workerBusy reports whether a transcription or another background job is running
+func (a *GioApp) workerBusy() bool {
+	a.workerMutex.Lock()
+	defer a.workerMutex.Unlock()
+	return a.workerRunning
 }
 
-// stopTranscription stops transcription
-func (a *GioApp) stopTranscription() {
+// releaseWorker marks the background job claimed with claimWorker as done
+func (a *GioApp) releaseWorker() {
 	a.workerMutex.Lock()
-	a.stopRequested = true // Request stop
+	a.workerRunning = false
 	a.workerMutex.Unlock()
+}
 
+// setStatus shows a message in the status line
+func (a *GioApp) setStatus(msg string) {
 	a.uiMutex.Lock()
-	a.statusText = "Stopping..."
+	a.statusText = msg
 	a.uiMutex.Unlock()
+	a.window.Invalidate()
 }
 
-// saveTranscription saves transcription using cross-platform dialog library
-func (a *GioApp) saveTranscription() {
+// saveMinutes generates meeting minutes from the current transcription and saves them as Markdown
+func (a *GioApp) saveMinutes() {
 	if len(a.transcriptionSegments) == 0 {
 		a.uiMutex.Lock()
-		a.statusText = "No transcription to save"
+		a.statusText = "No transcription to summarize"
 		a.uiMutex.Unlock()
 		return
 	}
 
-	format := a.formatList.Value
-	ext := format
-	filterName := "Text Files"
-	if format == "json" {
-		ext = "json"
-		filterName = "JSON Files"
-	} else if format == "srt" {
-		ext = "srt"
-		filterName = "SRT Subtitle Files"
-	} else if format == "vtt" {
-		ext = "vtt"
-		filterName = "WebVTT Subtitle Files"
-	} else {
-		ext = "txt"
-		filterName = "Text Files"
+	// Minutes need the attendees told apart, which a recording with a channel per
+	// speaker can be when transcribed again with split channels
+	if err := minutesSpeakersError(a.transcriptionSegments); err != nil {
+		message := "Error: " + err.Error()
+		if channels, _ := getAudioChannels(a.audioFilePath); channels > 1 && !a.splitChannels.Value {
+			message = fmt.Sprintf("Minutes need the speakers told apart: the recording has %d channels, so check Split channels and transcribe it again", channels)
+		}
+		a.setStatus(message)
+		return
 	}
 
-	defaultName := "transcription." + ext
+	// Minutes are written in the translation language when translating
+	lang := "he"
+	if a.enableTranslation.Value {
+		lang = a.translateLangList.Value
+	}
+
+	minutes, err := NewMistralTranslator().GenerateMinutes(a.transcriptionSegments, lang, func(msg string) {
+		a.uiMutex.Lock()
+		a.statusText = msg
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+	})
+	if err != nil {
+		a.uiMutex.Lock()
+		a.statusText = fmt.Sprintf("Error generating minutes: %v", err)
+		a.uiMutex.Unlock()
+		a.window.Invalidate()
+		return
+	}
+
+	a.uiMutex.Lock()
+	a.statusText = "Minutes ready"
+	a.uiMutex.Unlock()
+	a.window.Invalidate()
 
-	// Open save dialog with appropriate file filter
 	filePath, err := dialog.File().
-		Title("Save transcription").
-		Filter(filterName, ext).
+		Title("Save meeting minutes").
+		Filter("Markdown Files", "md").
 		Filter("All Files", "*").
-		SetStartFile(defaultName).
+		SetStartFile(minutesFileName(a.audioFilePath)).
+		SetStartDir(a.config.OutputDir).
 		Save()
-
 	if err != nil {
-		// User cancelled or error occurred
 		if err.Error() != "Cancelled" {
 			a.uiMutex.Lock()
 			a.statusText = fmt.Sprintf("Error opening save dialog: %v", err)
@@ -537,23 +4846,20 @@ func (a *GioApp) saveTranscription() {
 		}
 		return
 	}
-
-	// Ensure file has the correct extension
-	if !strings.HasSuffix(filePath, "."+ext) {
-		filePath = filePath + "." + ext
+	if !strings.HasSuffix(filePath, ".md") {
+		filePath = filePath + ".md"
 	}
 
-	outputText := FormatOutput(a.transcriptionSegments, format, false)
-	if err := os.WriteFile(filePath, []byte(outputText), 0644); err != nil {
-		a.uiMutex.Lock()
+	markdown := FormatMinutesMarkdown(minutesTitle(a.audioFilePath), minutes, a.transcriptionSegments)
+	a.uiMutex.Lock()
+	if err := os.WriteFile(filePath, []byte(markdown), 0644); err != nil {
 		a.statusText = fmt.Sprintf("Error saving file: %v", err)
-		a.uiMutex.Unlock()
-		return
+	} else {
+		a.statusText = fmt.Sprintf("Saved minutes to: %s", filePath)
+		a.savedFilePath = filePath
 	}
-
-	a.uiMutex.Lock()
-	a.statusText = "Transcription saved to " + filepath.Base(filePath)
 	a.uiMutex.Unlock()
+	a.window.Invalidate()
 }
 
 // appendSegment appends a segment (Gio handles RTL automatically)
@@ -571,6 +4877,10 @@ func (a *GioApp) appendSegment(seg Segment) {
 	a.uiMutex.Lock()
 	defer a.uiMutex.Unlock()
 
+	if a.presentation != nil {
+		a.presentation.AddCaption(seg.Text)
+	}
+
 	// Gio's text shaper automatically handles RTL for Hebrew text!
 	currentText := a.outputEditor.Text()
 	format := a.formatList.Value
@@ -589,10 +4899,15 @@ func (a *GioApp) appendSegment(seg Segment) {
 		currentText = "[...earlier text truncated for display...]\n\n" + currentText
 	}
 
+	line := seg.Text
+	if a.showTimestamps.Value {
+		line = "[" + FormatTimestamp(seg.Start, true)[:8] + "] " + line
+	}
+
 	var newText string
 	switch format {
 	case "text":
-		newText = currentText + seg.Text + "\n"
+		newText = currentText + line + "\n"
 	case "json":
 		newText = currentText + fmt.Sprintf(`{"start": %.2f, "end": %.2f, "text": "%s"}`+"\n", seg.Start, seg.End, seg.Text)
 	case "srt":
@@ -604,6 +4919,8 @@ func (a *GioApp) appendSegment(seg Segment) {
 		start := FormatTimestamp(seg.Start, true)
 		end := FormatTimestamp(seg.End, true)
 		newText = currentText + fmt.Sprintf("%s --> %s\n%s\n\n", start, end, seg.Text)
+	case "markdown", "html", "ttml", "stl", FormatCustom:
+		newText = currentText + line + "\n\n"
 	}
 
 	// Safe text update with error recovery
@@ -628,6 +4945,106 @@ func (a *GioApp) appendSegment(seg Segment) {
 	}
 }
 
+// displayText renders a finished transcript for the output area, followed by the
+// speaker statistics when enabled
+func (a *GioApp) displayText(segments []Segment) string {
+	output := a.transcriptDisplayText(segments)
+	reportFormat := "text"
+	if a.formatList.Value == "markdown" {
+		reportFormat = "markdown"
+	}
+	if a.speakerStats.Value {
+		output, _ = AppendSpeakerStats(output, segments, reportFormat)
+	}
+	if a.consensusReview != nil {
+		output, _ = AppendConsensusReview(output, *a.consensusReview, reportFormat)
+	}
+	return output
+}
+
+// outputSegments returns the transcript with the numbers, dates and times written as
+// selected, as it is shown and saved. The transcript itself keeps the words as
+// transcribed, so the selection can be changed back.
+func (a *GioApp) outputSegments() []Segment {
+	return NormalizeNumbers(a.transcriptionSegments, NumberOptions{Style: a.numberStyle.Value, Dates: a.normalizeDates.Value})
+}
+
+// shownSegments leaves out non-speech events unless they are shown. Only what is
+// displayed and saved is filtered: segment indexes stay those of the transcript.
+func (a *GioApp) shownSegments(segments []Segment) []Segment {
+	if a.showEvents.Value {
+		return segments
+	}
+	return WithoutEvents(segments)
+}
+
+// transcriptDisplayText renders a finished transcript in the selected format. Plain
+// text is shown with timestamps when enabled; HTML is shown as plain text, and
+// binary EBU-STL as SRT.
+func (a *GioApp) transcriptDisplayText(segments []Segment) string {
+	segments = a.shownSegments(NormalizeNumbers(segments, NumberOptions{Style: a.numberStyle.Value, Dates: a.normalizeDates.Value}))
+	format := a.formatList.Value
+	if format == "html" {
+		format = "text"
+	}
+	if format == "stl" {
+		format = "srt"
+	}
+	if format != "text" || !a.showTimestamps.Value {
+		return a.withWallClock(FormatOutput(segments, format, a.displayMode.Value), format)
+	}
+
+	output := ""
+	lastSpeaker := -1
+	for _, seg := range ApplyDisplayMode(segments, a.displayMode.Value) {
+		prefix := "[" + FormatTimestamp(seg.Start, true)[:8] + "] "
+		if seg.Speaker != lastSpeaker && seg.Event == "" {
+			prefix += speakerLinePrefix(seg, "text")
+			lastSpeaker = seg.Speaker
+		}
+		if seg.Original != "" && seg.Translation != "" {
+			output += prefix + seg.Original + "\n" + transliterationLine(seg) + seg.Translation + "\n\n"
+		} else if seg.Transliteration != "" {
+			output += prefix + seg.Text + "\n" + seg.Transliteration + "\n\n"
+		} else {
+			output += prefix + seg.Text + "\n"
+		}
+	}
+	return a.withWallClock(output, format)
+}
+
+// recordingStart parses the time the recording started, when one is set
+func (a *GioApp) recordingStart() (time.Time, bool, error) {
+	if strings.TrimSpace(a.startTimeEditor.Text()) == "" {
+		return time.Time{}, false, nil
+	}
+	start, err := ParseRecordingStart(a.startTimeEditor.Text())
+	return start, err == nil, err
+}
+
+// withWallClock shows the transcript's times as times of day when clock times are
+// selected and the recording's start is set
+func (a *GioApp) withWallClock(output, format string) string {
+	if start, ok, _ := a.recordingStart(); ok && a.wallClock.Value {
+		output, _ = ApplyWallClock(output, start, format)
+	}
+	return output
+}
+
+// refreshOutput redisplays a finished transcript, e.g. after a display option changed
+func (a *GioApp) refreshOutput() {
+	a.workerMutex.Lock()
+	running := a.workerRunning
+	a.workerMutex.Unlock()
+	if running || len(a.transcriptionSegments) == 0 {
+		return
+	}
+
+	a.uiMutex.Lock()
+	a.outputEditor.SetText(a.displayText(a.transcriptionSegments))
+	a.uiMutex.Unlock()
+}
+
 // transcriptionComplete handles completion
 func (a *GioApp) transcriptionComplete(segments []Segment) {
 	a.uiMutex.Lock()
@@ -635,10 +5052,18 @@ func (a *GioApp) transcriptionComplete(segments []Segment) {
 
 	a.progressVisible = false
 	a.statusText = "Transcription complete"
+	if a.consensusReview != nil {
+		a.statusText = fmt.Sprintf("Transcription complete (%d segments to review)", len(a.consensusReview.Disagreements))
+	}
+	if a.translationReview != nil && len(a.translationReview.Flags) > 0 {
+		a.statusText += fmt.Sprintf("; %d translations flagged for review", len(a.translationReview.Flags))
+	}
+	if len(a.glossaryMisses) > 0 {
+		a.statusText += fmt.Sprintf("; %d glossary terms not used (see the log)", len(a.glossaryMisses))
+	}
 	a.transcriptionSegments = segments
 
-	format := a.formatList.Value
-	finalOutput := FormatOutput(segments, format, false)
+	finalOutput := a.displayText(segments)
 
 	// Gio handles RTL automatically - no manual markers needed!
 	a.outputEditor.SetText(finalOutput)
@@ -651,25 +5076,99 @@ func (a *GioApp) transcriptionComplete(segments []Segment) {
 		speed := elapsedSeconds / a.audioDuration
 		a.timingText += fmt.Sprintf(" | Speed: %.2fx", speed)
 	}
+
+	// Add it to the history; where it is saved is filled in on saving
+	modelID := ""
+	if a.lastManifest != nil {
+		modelID = a.lastManifest.Model
+	}
+	entry := NewHistoryEntry(a.audioFilePath, modelID, a.audioDuration, time.Duration(elapsed)*time.Second)
+	a.historyEntry = &entry
+	go func() {
+		if err := AddHistory(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Cannot add the transcription to the history: %v\n", err)
+		}
+	}()
 }
 
 // runTranscription runs the transcription (ported from Qt/Fyne version)
 func (a *GioApp) runTranscription() {
+	// The session stays busy until the results are published, below, unless it
+	// doesn't get that far
+	started := false
 	defer func() {
-		a.workerMutex.Lock()
-		a.workerRunning = false
-		a.workerMutex.Unlock()
+		if !started {
+			a.releaseWorker()
+		}
 	}()
 	
 	// Get options
 	modelID := a.modelList.Value
 	enableTranslation := a.enableTranslation.Value
+	checkTranslation := a.checkTranslation.Value
 	targetLang := a.translateLangList.Value
-	keepOriginal := a.keepOriginal.Value
+	transliteration := a.config.Transliteration
+	if !a.transliterate.Value {
+		transliteration.Method = ""
+	} else if transliteration.Method == "" {
+		transliteration.Method = TransliterateRules
+	}
+	splitChannels := a.splitChannels.Value
 	audioPath := a.audioFilePath
+	var tracks []ParticipantTrack
+	var participants []string
+	if a.meeting != nil && a.participantTracks.Value {
+		tracks, participants = a.meeting.Tracks, a.meeting.TrackNames()
+		splitChannels = false // Each participant already has their own track
+	}
+	a.uiMutex.RLock()
+	recordingParts := a.recordingParts
+	a.uiMutex.RUnlock()
+	consensusMode := ""
+	if a.consensus.Value {
+		consensusMode = a.config.Consensus
+		if consensusMode == "" {
+			consensusMode = ConsensusModels
+		}
+	}
+	if consensusMode != "" && len(tracks) > 0 {
+		a.uiMutex.Lock()
+		a.statusText = "Error: High accuracy can't be combined with per-participant tracks"
+		a.uiMutex.Unlock()
+		return
+	}
+
+	// Subtitle-sized segments from whisper itself, when a maximum length is set
+	decode := a.config.Decode
+	decode.MaxSegmentChars, _ = strconv.Atoi(a.maxSegmentEditor.Text())
+	if err := (AppConfig{Engine: a.config.Engine, Decode: decode}).ValidateSegmentLimits(); err != nil {
+		a.setStatus("Error: " + err.Error())
+		return
+	}
+	a.updateSettings(func(s *Settings) { s.MaxSegmentChars = decode.MaxSegmentChars })
+
+	recordingStart, hasStart, startErr := a.recordingStart()
+	if startErr != nil {
+		a.setStatus("Error: " + startErr.Error())
+		return
+	}
+	if a.wallClock.Value && !hasStart {
+		a.setStatus("Error: Clock times need the time the recording started")
+		return
+	}
+
+	timeRange, rangeErr := ParseTimeRange(a.fromEditor.Text(), a.toEditor.Text())
+	if rangeErr != nil {
+		a.uiMutex.Lock()
+		a.statusText = "Error: Invalid time range: " + rangeErr.Error()
+		a.uiMutex.Unlock()
+		return
+	}
 
-	// Use optimal CPU threads
-	cpuThreads := GetOptimalCPUThreads()
+	a.settings.Lock()
+	cpuThreads := a.config.CPUThreads(modelID, a.settings.TunedThreads)
+	tuneThreads := a.config.NeedsThreadTuning(modelID, a.settings.TunedThreads)
+	a.settings.Unlock()
 	
 	// Channels for communication
 	progressChan := make(chan string, 10)
@@ -677,41 +5176,23 @@ func (a *GioApp) runTranscription() {
 	doneChan := make(chan []Segment, 1)
 	errorChan := make(chan string, 1)
 	
+	// ETA inputs: this machine's historical realtime factor for the model and the audio length
+	a.settings.Lock()
+	realtimeFactor := a.settings.RealtimeFactors[modelID]
+	a.settings.Unlock()
+	audioDuration := effectiveAudioDuration(a.audioDuration, timeRange)
+	var inferenceStart time.Time // Set just before inference starts
+
 	// Progress callback with ETA calculation
 	progressCallback := func(msg string) {
 		// Extract percentage from message if present (e.g., "Transcribing... 45%")
-		var enhancedMsg string
-		if strings.Contains(msg, "%") {
-			// Try to parse percentage
-			var percent int
-			if _, err := fmt.Sscanf(msg, "Transcribing... %d%%", &percent); err == nil && percent > 0 && percent <= 100 {
-				// Calculate ETA
-				elapsed := time.Since(time.Unix(a.transcriptionStartTime, 0))
-				if percent > 0 {
-					// Estimate total time = elapsed / (percent/100)
-					estimatedTotal := time.Duration(float64(elapsed) / (float64(percent) / 100.0))
-					remaining := estimatedTotal - elapsed
-
-					// Format remaining time
-					if remaining > 0 {
-						mins := int(remaining.Minutes())
-						secs := int(remaining.Seconds()) % 60
-						if mins > 0 {
-							enhancedMsg = fmt.Sprintf("Transcribing... %d%% (ETA: %dm %ds)", percent, mins, secs)
-						} else {
-							enhancedMsg = fmt.Sprintf("Transcribing... %d%% (ETA: %ds)", percent, secs)
-						}
-					} else {
-						enhancedMsg = msg
-					}
-				} else {
-					enhancedMsg = msg
-				}
-			} else {
-				enhancedMsg = msg
+		enhancedMsg := msg
+		var percent int
+		if _, err := fmt.Sscanf(msg, "Transcribing... %d%%", &percent); err == nil && !inferenceStart.IsZero() {
+			remaining, ok := EstimateRemaining(realtimeFactor, audioDuration, time.Since(inferenceStart), percent)
+			if ok && remaining > 0 {
+				enhancedMsg = fmt.Sprintf("Transcribing... %d%% (ETA: %s)", percent, FormatETA(remaining))
 			}
-		} else {
-			enhancedMsg = msg
 		}
 
 		select {
@@ -729,7 +5210,9 @@ func (a *GioApp) runTranscription() {
 	}
 	
 	// Handle UI updates
+	started = true
 	go func() {
+		defer a.releaseWorker() // Once the results or the error are shown
 		for {
 			select {
 			case msg := <-progressChan:
@@ -755,36 +5238,88 @@ func (a *GioApp) runTranscription() {
 	
 	// Transcribe using native whisper.cpp
 	go func() {
-		// Get model path
-		modelPath, modelErr := GetModelPath(modelID, func(msg string, pct int) {
+		// Show the machine's load while it works, so heavy CPU use or an idle GPU can be seen
+		stopTelemetry := MonitorSystemLoad(telemetryInterval, func(load SystemLoad) {
+			a.uiMutex.Lock()
+			a.telemetryText = FormatSystemLoad(load)
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+		})
+		defer func() {
+			stopTelemetry()
+			a.uiMutex.Lock()
+			a.telemetryText = ""
+			a.uiMutex.Unlock()
+			a.window.Invalidate()
+		}()
+
+		if err := CheckFFmpeg(); err != nil {
+			errorChan <- err.Error()
+			return
+		}
+
+		// Native whisper.cpp (downloading the model if needed) or a remote engine
+		engine, engineErr := NewTranscriptionEngine(a.config, modelID, func(msg string, pct int) {
 			select {
 			case progressChan <- msg:
 			default:
 			}
 		})
-		
-		if modelErr != nil {
-			errorChan <- modelErr.Error()
-			return
-		}
-		
-		// Use native whisper.cpp
-		engine, engineErr := NewWhisperCGOEngine(modelPath)
 		if engineErr != nil {
-			errorChan <- fmt.Sprintf("Failed to initialize whisper engine: %v", engineErr)
+			var damaged *CorruptModelError
+			if errors.As(engineErr, &damaged) {
+				a.uiMutex.Lock()
+				a.damagedModel = damaged
+				a.uiMutex.Unlock()
+			}
+			errorChan <- engineErr.Error()
 			return
 		}
 		defer engine.Close()
-		
+		engine.SetTimeRange(timeRange)
+		engine.SetParallelChunks(a.config.Parallel)
+		engine.SetDecodeOptions(decode)
+
+		// With auto threads, a model's first use measures the fastest thread count
+		if benchmarker, ok := engine.(threadBenchmarker); ok && tuneThreads {
+			if tuned, err := TuneThreads(benchmarker, modelID, cpuTopology(), progressCallback); err == nil {
+				cpuThreads = tuned
+				a.updateSettings(func(s *Settings) { s.TunedThreads[modelID] = tuned })
+			}
+		}
+
 		// Step 1: Transcribe in Hebrew (no whisper translation)
-		progressCallback("Transcribing in Hebrew...")
-		segments, err := engine.Transcribe(audioPath, modelID, cpuThreads, progressCallback, segmentCallback)
+		// With a known realtime factor the ETA is available before any progress arrives
+		if remaining, ok := EstimateRemaining(realtimeFactor, audioDuration, 0, 0); ok {
+			progressCallback(fmt.Sprintf("Transcribing in Hebrew... (ETA: %s)", FormatETA(remaining)))
+		} else {
+			progressCallback("Transcribing in Hebrew...")
+		}
+		inferenceStart = time.Now()
+		var segments []Segment
+		var err error
+		if splitChannels {
+			segments, err = TranscribeByChannel(engine, audioPath, modelID, cpuThreads, progressCallback, segmentCallback)
+		} else if len(tracks) > 0 {
+			segments, err = TranscribeTracks(engine, tracks, modelID, cpuThreads, progressCallback, segmentCallback)
+		} else {
+			segments, err = engine.Transcribe(audioPath, modelID, cpuThreads, progressCallback, segmentCallback)
+		}
 		if err != nil {
 			errorChan <- err.Error()
 			return
 		}
 		a.originalSegments = segments
 
+		// Learn this machine's speed for future ETAs (cached results, split-channel,
+		// per-participant and parallel-chunk runs don't reflect single-pass inference time)
+		if !engine.LastResultCached() && ranLocally(engine) && !splitChannels && len(tracks) == 0 && a.config.Parallel <= 1 && audioDuration > 0 {
+			inferenceTime := time.Since(inferenceStart)
+			a.updateSettings(func(s *Settings) {
+				UpdateRealtimeFactor(s.RealtimeFactors, modelID, inferenceTime, audioDuration)
+			})
+		}
+
 		// Check if stop was requested after transcription
 		a.workerMutex.Lock()
 		stopped := a.stopRequested
@@ -797,10 +5332,82 @@ func (a *GioApp) runTranscription() {
 			return
 		}
 
+		// Record how this transcript was produced
+		params := ManifestParameters{
+			Language:    "he",
+			Threads:     cpuThreads,
+			ChannelMode: ChannelModeMix,
+			Parallel:    a.config.Parallel,
+			From:        timeRange.Start,
+			To:          timeRange.End,
+			Decode:      decode,
+			Engine:      engineDescription(engine),
+			Parts:       recordingParts,
+		}
+		if len(tracks) > 0 {
+			params.Participants = participants
+		}
+		if hasStart {
+			params.RecordingStart = recordingStart.Format(time.RFC3339)
+		}
+		if splitChannels {
+			params.ChannelMode = ChannelModeSplit
+		}
+
+		// High accuracy: transcribe again and merge, flagging where the passes disagree
+		if consensusMode != "" {
+			firstPass := ConsensusPass{Model: modelID, Decode: decode}
+			secondPass := ConsensusSecondPass(consensusMode, firstPass)
+			secondEngine := engine
+			if secondPass.Model != modelID {
+				var err error
+				if secondEngine, err = NewTranscriptionEngine(a.config, secondPass.Model, func(msg string, pct int) { progressCallback(msg) }); err != nil {
+					errorChan <- err.Error()
+					return
+				}
+				defer secondEngine.Close()
+				secondEngine.SetTimeRange(timeRange)
+				secondEngine.SetParallelChunks(a.config.Parallel)
+			}
+
+			progressCallback(fmt.Sprintf("Transcribing again with %s...", secondPass.Label(consensusMode)))
+			second, err := transcribeSecondPass(secondEngine, secondPass, decode, audioPath, cpuThreads, splitChannels, progressCallback)
+			if err != nil {
+				errorChan <- fmt.Sprintf("Second transcription failed: %v", err)
+				return
+			}
+
+			a.workerMutex.Lock()
+			stopped := a.stopRequested
+			a.workerMutex.Unlock()
+			if stopped {
+				a.uiMutex.Lock()
+				a.statusText = "Stopped"
+				a.uiMutex.Unlock()
+				doneChan <- segments
+				return
+			}
+
+			merged, disagreements := MergeConsensus(segments, second)
+			segments = merged
+			params.Consensus = consensusMode
+			params.ConsensusWith = secondPass.Label(consensusMode)
+			a.uiMutex.Lock()
+			a.consensusReview = &ConsensusReview{First: firstPass.Label(consensusMode), Second: secondPass.Label(consensusMode), Segments: len(merged), Disagreements: disagreements}
+			a.uiMutex.Unlock()
+			a.originalSegments = merged
+		}
+
 		// Step 2: Translate using Mistral if requested
 		if enableTranslation {
 			progressCallback(fmt.Sprintf("Translating to %s using Mistral 8B...", targetLang))
-			translator := NewMistralTranslator()
+			translator, err := a.config.NewTranslator(targetLang)
+			if err != nil {
+				errorChan <- fmt.Sprintf("Translation failed: %v", err)
+				return
+			}
+			params.TranslateTo = targetLang
+			params.TranslationModel = translator.model
 
 			translatedSegments, transErr := translator.TranslateSegments(segments, targetLang, progressCallback, nil)
 			if transErr != nil {
@@ -820,22 +5427,56 @@ func (a *GioApp) runTranscription() {
 				return
 			}
 
-			// If keep original is disabled, only show translation
-			if !keepOriginal {
-				for i := range translatedSegments {
-					translatedSegments[i].Text = translatedSegments[i].Translation
-					translatedSegments[i].Original = ""
-				}
-			} else {
-				// Keep both original and translation
-				for i := range translatedSegments {
+			// Both texts are kept; the display mode picks what is shown when formatting
+			for i := range translatedSegments {
+				if translatedSegments[i].Event == "" {
 					translatedSegments[i].Text = translatedSegments[i].Translation
 				}
 			}
 
 			segments = translatedSegments
+
+			misses := translator.GlossaryMisses()
+			for _, miss := range misses {
+				fmt.Fprintf(os.Stderr, "Warning: The translation doesn't use the glossary's %s\n", miss.Describe())
+			}
+			a.uiMutex.Lock()
+			a.glossaryMisses = misses
+			a.uiMutex.Unlock()
+
+			// Back-translate to flag translations that may be wrong; if the check
+			// fails, the translation is kept unchecked
+			if checkTranslation {
+				progressCallback("Checking the translation...")
+				review, err := translator.CheckTranslation(segments, targetLang, progressCallback)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Translation check failed: %v\n", err)
+				} else {
+					a.uiMutex.Lock()
+					a.translationReview = &review
+					a.translationFlagIndex = 0
+					a.uiMutex.Unlock()
+				}
+			}
+		}
+
+		// Step 3: Transliterate the Hebrew if requested
+		if transliteration.Method != "" {
+			progressCallback("Transliterating...")
+			params.Transliteration = transliteration.Method
+			transliterated, err := TransliterateSegments(segments, transliterator(transliteration.Method), transliteration.Only, progressCallback)
+			if err != nil {
+				errorChan <- fmt.Sprintf("Transliteration failed: %v", err)
+				return
+			}
+			segments = transliterated
 		}
 
+		manifest := NewManifest(modelID, engineModelPath(engine), audioPath, params)
+		a.uiMutex.Lock()
+		a.lastManifest = &manifest
+		a.uiMutex.Unlock()
+
 		doneChan <- segments
 	}()
 }
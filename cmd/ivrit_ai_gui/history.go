@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHistoryEntries bounds the history of completed transcriptions; the oldest are dropped
+const maxHistoryEntries = 500
+
+// HistoryEntry is a completed transcription, from the GUI or the CLI
+type HistoryEntry struct {
+	InputPath      string    `json:"inputPath"`
+	CompletedAt    time.Time `json:"completedAt"`
+	Duration       float64   `json:"duration,omitempty"` // Seconds of audio
+	Model          string    `json:"model,omitempty"`
+	OutputPath     string    `json:"outputPath,omitempty"`     // Where the transcript was last saved ("" = not saved)
+	RealtimeFactor float64   `json:"realtimeFactor,omitempty"` // Processing time over audio duration
+}
+
+var historyMutex sync.Mutex // Serializes reading and writing the history file in this process
+
+// historyPath returns the location of the history of completed transcriptions
+func historyPath() string {
+	return filepath.Join(filepath.Dir(settingsPath()), "history.json")
+}
+
+// lockHistory takes historyMutex and a lock file next to the history, so the CLI and
+// the GUI updating the history at the same time don't lose each other's entries
+func lockHistory() (unlock func(), err error) {
+	historyMutex.Lock()
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		historyMutex.Unlock()
+		return nil, err
+	}
+	unlockFile, err := lockFile(path)
+	if err != nil {
+		historyMutex.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		historyMutex.Unlock()
+	}, nil
+}
+
+// LoadHistory reads the history of completed transcriptions, newest first; a
+// missing file is an empty history
+func LoadHistory() ([]HistoryEntry, error) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+	return loadHistory()
+}
+
+// loadHistory reads the history file; the caller holds the history lock. A file that
+// can't be read or parsed is an error rather than an empty history, so it isn't
+// overwritten with the new entry alone.
+func loadHistory() ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	data, err := os.ReadFile(historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("the history in %s is damaged: %v", historyPath(), err)
+	}
+	return entries, nil
+}
+
+// saveHistory writes the history file; the caller holds the history lock. It is written
+// to a temporary file that then replaces it, so the CLI and the GUI reading it
+// meanwhile see the old history or the new one, never a partly written file.
+func saveHistory(entries []HistoryEntry) error {
+	if len(entries) > maxHistoryEntries {
+		entries = entries[:maxHistoryEntries]
+	}
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path that then replaces
+// it, so a reader sees the old contents or the new ones, never a partly written file
+func writeFileAtomic(path string, data []byte) error {
+	ext := filepath.Ext(path)
+	temp, err := os.CreateTemp(filepath.Dir(path), strings.TrimSuffix(filepath.Base(path), ext)+"-*"+ext)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name()) // Left over only on failure
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// NewHistoryEntry describes a transcription of inputPath that just completed
+// after taking elapsed, with paths made absolute so it can be reopened from anywhere
+func NewHistoryEntry(inputPath, modelID string, duration float64, elapsed time.Duration) HistoryEntry {
+	entry := HistoryEntry{
+		InputPath:   absolutePath(inputPath),
+		CompletedAt: time.Now().UTC().Truncate(time.Second),
+		Duration:    duration,
+		Model:       modelID,
+	}
+	if duration > 0 {
+		entry.RealtimeFactor = elapsed.Seconds() / duration
+	}
+	return entry
+}
+
+// AddHistory adds a completed transcription to the top of the history
+func AddHistory(entry HistoryEntry) error {
+	if entry.OutputPath != "" {
+		entry.OutputPath = absolutePath(entry.OutputPath)
+	}
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	return saveHistory(append([]HistoryEntry{entry}, entries...))
+}
+
+// SetHistoryOutput records where the transcript of a history entry was saved
+func SetHistoryOutput(entry HistoryEntry, outputPath string) error {
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].InputPath == entry.InputPath && entries[i].CompletedAt.Equal(entry.CompletedAt) {
+			entries[i].OutputPath = absolutePath(outputPath)
+			return saveHistory(entries)
+		}
+	}
+	return nil
+}
+
+// CanReopen reports whether the entry's saved transcript can be read back into the
+// app (text, SRT, VTT and JSON transcripts that still exist)
+func (h HistoryEntry) CanReopen() bool {
+	if h.OutputPath == "" || transcriptFileFormat(h.OutputPath) == "" {
+		return false
+	}
+	_, err := os.Stat(h.OutputPath)
+	return err == nil
+}
+
+// LoadTranscript reads the entry's saved transcript back, with its manifest if it
+// was saved as JSON
+func (h HistoryEntry) LoadTranscript() ([]Segment, *Manifest, error) {
+	if !h.CanReopen() {
+		return nil, nil, fmt.Errorf("no readable transcript of %s was saved", filepath.Base(h.InputPath))
+	}
+	return TranscriptRecord{OutputPath: h.OutputPath}.LoadTranscript()
+}
+
+// Describe summarizes the entry on one line, e.g.
+// "2026-10-16 14:05  interview.m4a  1:02:10  turbo  0.31x  → interview.srt"
+func (h HistoryEntry) Describe() string {
+	line := fmt.Sprintf("%s  %s", h.CompletedAt.Local().Format("2006-01-02 15:04"), filepath.Base(h.InputPath))
+	if h.Duration > 0 {
+		line += "  " + formatClockDuration(h.Duration)
+	}
+	if h.Model != "" {
+		line += "  " + h.Model
+	}
+	if h.RealtimeFactor > 0 {
+		line += fmt.Sprintf("  %.2fx", h.RealtimeFactor)
+	}
+	if h.OutputPath != "" {
+		line += "  → " + filepath.Base(h.OutputPath)
+	}
+	return line
+}
+
+// formatClockDuration formats seconds as H:MM:SS, or M:SS under an hour
+func formatClockDuration(seconds float64) string {
+	total := int(seconds + 0.5)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
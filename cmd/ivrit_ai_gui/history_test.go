@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHistory tests adding completed transcriptions and recording where they were saved
+func TestHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if entries, err := LoadHistory(); len(entries) != 0 || err != nil {
+		t.Fatalf("Expected an empty history, got %v, %v", entries, err)
+	}
+
+	first := NewHistoryEntry("/recordings/interview.m4a", "turbo", 3730, 20*time.Minute)
+	if err := AddHistory(first); err != nil {
+		t.Fatalf("AddHistory() error: %v", err)
+	}
+	second := NewHistoryEntry("/recordings/lecture.m4a", "large-v3", 600, 5*time.Minute)
+	second.OutputPath = "/transcripts/lecture.srt"
+	if err := AddHistory(second); err != nil {
+		t.Fatalf("AddHistory() error: %v", err)
+	}
+
+	entries, _ := LoadHistory()
+	if len(entries) != 2 || entries[0].InputPath != second.InputPath {
+		t.Fatalf("Expected the newest entry first, got %+v", entries)
+	}
+	if entries[1].RealtimeFactor < 0.32 || entries[1].RealtimeFactor > 0.33 {
+		t.Errorf("Expected a realtime factor of about 0.32, got %v", entries[1].RealtimeFactor)
+	}
+
+	if err := SetHistoryOutput(first, "/transcripts/interview.json"); err != nil {
+		t.Fatalf("SetHistoryOutput() error: %v", err)
+	}
+	if entries, _ = LoadHistory(); entries[1].OutputPath != "/transcripts/interview.json" || entries[0].OutputPath != "/transcripts/lecture.srt" {
+		t.Errorf("Expected only the first entry's output to change, got %+v", entries)
+	}
+}
+
+// TestHistoryDamaged tests that a history file that can't be parsed is reported
+// rather than replaced by one holding the new entry alone
+func TestHistoryDamaged(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := AddHistory(NewHistoryEntry("/recordings/interview.m4a", "turbo", 60, time.Minute)); err != nil {
+		t.Fatalf("AddHistory() error: %v", err)
+	}
+	damaged := []byte(`[{"inputPath": "/recordings/interview.m4a", "compl`)
+	if err := os.WriteFile(historyPath(), damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadHistory(); err == nil {
+		t.Error("Expected an error for a damaged history")
+	}
+	if err := AddHistory(NewHistoryEntry("/recordings/lecture.m4a", "turbo", 60, time.Minute)); err == nil {
+		t.Error("Expected AddHistory() to fail on a damaged history")
+	}
+	if data, _ := os.ReadFile(historyPath()); string(data) != string(damaged) {
+		t.Errorf("Expected the damaged history left as it was, got %s", data)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(historyPath()), "history-*")); len(leftovers) != 0 {
+		t.Errorf("Temporary files left behind: %v", leftovers)
+	}
+}
+
+// TestLockFile tests that a second holder of the history lock, e.g. the CLI while the
+// GUI updates the history, waits for the first to release it
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile() failed: %v", err)
+	}
+	locked := make(chan func())
+	go func() {
+		second, err := lockFile(path)
+		if err != nil {
+			t.Errorf("lockFile() failed for the second holder: %v", err)
+			second = func() {}
+		}
+		locked <- second
+	}()
+
+	select {
+	case second := <-locked:
+		second()
+		t.Fatal("Expected the second holder to wait while the lock is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case second := <-locked:
+		second()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second holder to get the lock once released")
+	}
+}
+
+// TestHistoryEntryDescribe tests the one-line summary of an entry
+func TestHistoryEntryDescribe(t *testing.T) {
+	entry := HistoryEntry{
+		InputPath:      "/recordings/interview.m4a",
+		CompletedAt:    time.Date(2026, 10, 16, 14, 5, 0, 0, time.Local),
+		Duration:       3730,
+		Model:          "turbo",
+		OutputPath:     "/transcripts/interview.srt",
+		RealtimeFactor: 0.3125,
+	}
+	want := "2026-10-16 14:05  interview.m4a  1:02:10  turbo  0.31x  → interview.srt"
+	if got := entry.Describe(); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+
+	entry.Duration = 95
+	if got := entry.Describe(); !strings.Contains(got, "  1:35  ") {
+		t.Errorf("Expected M:SS under an hour, got %q", got)
+	}
+	if entry.CanReopen() {
+		t.Error("Expected a transcript that doesn't exist not to be reopenable")
+	}
+}
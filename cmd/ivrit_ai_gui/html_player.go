@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// playerAudioBitrate keeps embedded audio small: speech stays clear at 48 kbps mono AAC
+// (about 22 MB per hour, a third more once base64-encoded into the page)
+const playerAudioBitrate = "48k"
+
+// htmlPlayerData is what the player template renders
+type htmlPlayerData struct {
+	Title string
+	Dir   string       // Page direction: rtl when the transcript contains Hebrew
+	Audio template.URL // data: URI of the embedded audio (empty for a transcript-only page)
+	Turns []htmlPlayerTurn
+}
+
+// htmlPlayerTurn is a speaker turn on the page
+type htmlPlayerTurn struct {
+	Heading  string
+	Segments []htmlPlayerSegment
+}
+
+// htmlPlayerSegment is a clickable segment; Translation is set for translated segments
+type htmlPlayerSegment struct {
+	Start       float64
+	End         float64
+	Time        string
+	Text        string
+	Translation string
+}
+
+// FormatHTML renders a self-contained HTML page with the transcript and, when audio is
+// given, an embedded player: clicking a segment seeks to it, the segment being played
+// is highlighted, and the transcript can be searched.
+func FormatHTML(title string, segments []Segment, audio []byte, mimeType string) (string, error) {
+	data := htmlPlayerData{Title: title, Dir: "ltr", Turns: []htmlPlayerTurn{}}
+	if len(audio) > 0 {
+		data.Audio = template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(audio))
+	}
+
+	for _, turn := range SpeakerTurns(segments) {
+		htmlTurn := htmlPlayerTurn{Heading: currentSpeakerLabels().Write(turn.Label(), "html")}
+		for _, seg := range turn.Segments {
+			htmlSeg := htmlPlayerSegment{Start: seg.Start, End: seg.End, Time: FormatTimestamp(seg.Start, true)[:8], Text: seg.Text}
+			if seg.Original != "" && seg.Translation != "" {
+				htmlSeg.Text, htmlSeg.Translation = seg.Original, seg.Translation
+			}
+			if containsRTL(htmlSeg.Text) {
+				data.Dir = "rtl"
+			}
+			htmlTurn.Segments = append(htmlTurn.Segments, htmlSeg)
+		}
+		data.Turns = append(data.Turns, htmlTurn)
+	}
+
+	var b strings.Builder
+	if err := htmlPlayerTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// EncodePlayerAudio compresses the input's audio for embedding in an HTML page
+func EncodePlayerAudio(inputPath string) ([]byte, string, error) {
+	tempFile, err := CreateTempFile("player_audio_*.m4a")
+	if err != nil {
+		return nil, "", err
+	}
+	tempPath := tempFile.Name()
+	tempFile.Close()
+	defer RemoveTempFile(tempPath)
+
+	cmd := exec.Command(ffmpegPath(),
+		"-i", inputPath,
+		"-vn",      // No video
+		"-ac", "1", // Mono
+		"-c:a", "aac", // Built into every ffmpeg and playable in all browsers
+		"-b:a", playerAudioBitrate,
+		"-movflags", "+faststart", // Index first, so seeking works before the whole file is parsed
+		"-y", // Overwrite output file
+		tempPath,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, "", fmt.Errorf("ffmpeg audio encoding failed: %v", err)
+	}
+
+	audio, err := os.ReadFile(tempPath)
+	if err != nil {
+		return nil, "", err
+	}
+	return audio, "audio/mp4", nil
+}
+
+// htmlPlayerTemplate is the player page; styles and script are inline so the file works offline
+var htmlPlayerTemplate = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html lang="he" dir="{{.Dir}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="ivrit.ai">
+<title>{{.Title}}</title>
+<style>
+  body { margin: 0; font-family: -apple-system, "Segoe UI", Arial, sans-serif; line-height: 1.6; color: #222; background: #fafafa; }
+  header { position: sticky; top: 0; background: #fff; border-bottom: 1px solid #ddd; padding: 12px 16px; z-index: 1; }
+  header h1 { font-size: 1.2em; margin: 0 0 8px; }
+  .controls { display: flex; flex-wrap: wrap; gap: 12px; align-items: center; }
+  audio { flex: 1 1 320px; min-width: 0; }
+  #search { flex: 0 1 240px; padding: 6px 8px; font-size: 1em; border: 1px solid #bbb; border-radius: 4px; }
+  #count { color: #666; font-size: 0.9em; }
+  main { max-width: 860px; margin: 0 auto; padding: 16px; }
+  .turn h2 { font-size: 1em; color: #007aff; margin: 24px 0 4px; }
+  .seg { padding: 4px 8px; border-radius: 4px; cursor: pointer; }
+  .seg:hover { background: #eef4ff; }
+  .seg.active { background: #fff3c4; }
+  .time { color: #999; font-size: 0.8em; font-variant-numeric: tabular-nums; }
+  .translation { color: #555; }
+  .hidden { display: none; }
+  .no-audio .seg { cursor: default; }
+</style>
+</head>
+<body{{if not .Audio}} class="no-audio"{{end}}>
+<header>
+  <h1>{{.Title}}</h1>
+  <div class="controls">
+    {{if .Audio}}<audio id="audio" controls preload="metadata" src="{{.Audio}}"></audio>
+    <label><input type="checkbox" id="follow" checked> Follow playback</label>{{end}}
+    <input type="search" id="search" placeholder="Search / חיפוש" dir="auto">
+    <span id="count"></span>
+  </div>
+</header>
+<main>
+{{range .Turns}}<section class="turn">
+{{if .Heading}}  <h2>{{.Heading}}</h2>
+{{end}}{{range .Segments}}  <div class="seg" data-start="{{.Start}}" data-end="{{.End}}">
+    <span class="time">{{.Time}}</span>
+    <div class="text" dir="auto">{{.Text}}</div>{{if .Translation}}
+    <div class="translation" dir="auto">{{.Translation}}</div>{{end}}
+  </div>
+{{end}}</section>
+{{end}}</main>
+<script>
+(function () {
+  var audio = document.getElementById("audio");
+  var follow = document.getElementById("follow");
+  var search = document.getElementById("search");
+  var count = document.getElementById("count");
+  var segments = Array.prototype.slice.call(document.querySelectorAll(".seg"));
+  var active = null;
+
+  if (audio) {
+    segments.forEach(function (seg) {
+      seg.addEventListener("click", function () {
+        audio.currentTime = parseFloat(seg.dataset.start);
+        audio.play();
+      });
+    });
+
+    audio.addEventListener("timeupdate", function () {
+      var t = audio.currentTime;
+      var current = null;
+      for (var i = 0; i < segments.length; i++) {
+        if (t >= parseFloat(segments[i].dataset.start) && t < parseFloat(segments[i].dataset.end)) {
+          current = segments[i];
+          break;
+        }
+      }
+      if (current === active) {
+        return;
+      }
+      if (active) {
+        active.classList.remove("active");
+      }
+      active = current;
+      if (active) {
+        active.classList.add("active");
+        if (follow.checked) {
+          active.scrollIntoView({ block: "center", behavior: "smooth" });
+        }
+      }
+    });
+  }
+
+  search.addEventListener("input", function () {
+    var query = search.value.trim().toLowerCase();
+    var matches = 0;
+    segments.forEach(function (seg) {
+      var text = seg.querySelector(".text").textContent;
+      var translation = seg.querySelector(".translation");
+      if (translation) {
+        text += " " + translation.textContent;
+      }
+      var match = !query || text.toLowerCase().indexOf(query) >= 0;
+      seg.classList.toggle("hidden", !match);
+      if (match && query) {
+        matches++;
+      }
+    });
+    document.querySelectorAll(".turn").forEach(function (turn) {
+      turn.classList.toggle("hidden", !turn.querySelector(".seg:not(.hidden)"));
+    });
+    count.textContent = query ? matches + (matches === 1 ? " match" : " matches") : "";
+  });
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFormatHTML tests the embedded audio, clickable segments and escaping
+func TestFormatHTML(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2.5, Text: "שלום <script>alert(1)</script>", Speaker: 0},
+		{Start: 2.5, End: 4, Original: "מה שלומך?", Translation: "How are you?", Text: "How are you?", Speaker: 1},
+	}
+
+	page, err := FormatHTML("Interview - Transcript", segments, []byte("audio"), "audio/mp4")
+	if err != nil {
+		t.Fatalf("FormatHTML() error: %v", err)
+	}
+
+	expected := []string{
+		`<html lang="he" dir="rtl">`,
+		`<title>Interview - Transcript</title>`,
+		`src="data:audio/mp4;base64,YXVkaW8="`,
+		`<div class="seg" data-start="2.5" data-end="4">`,
+		`<div class="text" dir="auto">מה שלומך?</div>`,
+		`<div class="translation" dir="auto">How are you?</div>`,
+		`<h2>Speaker 2</h2>`,
+		`&lt;script&gt;alert(1)&lt;/script&gt;`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(page, want) {
+			t.Errorf("Page missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>alert(1)") {
+		t.Error("Segment text was not escaped")
+	}
+}
+
+// TestFormatHTMLWithoutAudio tests the transcript-only page produced by FormatOutput
+func TestFormatHTMLWithoutAudio(t *testing.T) {
+	page := FormatOutput([]Segment{{Start: 0, End: 1, Text: "Hello"}}, "html", DisplayBilingual)
+
+	if strings.Contains(page, "<audio") || !strings.Contains(page, `class="no-audio"`) {
+		t.Error("Expected a page without a player")
+	}
+	if !strings.Contains(page, `dir="ltr"`) || !strings.Contains(page, `id="search"`) {
+		t.Error("Expected a left-to-right page with search")
+	}
+}
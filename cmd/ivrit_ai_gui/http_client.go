@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkOptions configures every connection the app makes (model downloads,
+// Ollama, translation and cloud engines, uploads, webhooks, update checks) for
+// networks behind a corporate proxy or a firewall inspecting TLS
+type NetworkOptions struct {
+	Proxy          string `json:"proxy,omitempty"`          // HTTP(S) or SOCKS5 proxy (default: HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment)
+	CACerts        string `json:"caCerts,omitempty"`        // PEM file of certificate authorities to trust besides the system's, e.g. the firewall's
+	ConnectTimeout int    `json:"connectTimeout,omitempty"` // Seconds to connect and complete the TLS handshake (default: 30)
+}
+
+const defaultConnectTimeout = 30 * time.Second
+
+// The transport shared by all HTTP clients, built from the network options
+var (
+	networkTransport = newNetworkTransport(NetworkOptions{}, nil)
+	networkMutex     sync.RWMutex
+)
+
+// SetNetworkOptions sets the proxy, trusted certificate authorities and connect
+// timeout of all HTTP connections
+func SetNetworkOptions(options NetworkOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	var roots *x509.CertPool
+	if options.CACerts != "" {
+		pem, err := os.ReadFile(options.CACerts)
+		if err != nil {
+			return fmt.Errorf("cannot read the CA certificates: %v", err)
+		}
+		if roots, err = x509.SystemCertPool(); err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in %s", options.CACerts)
+		}
+	}
+	transport := newNetworkTransport(options, roots)
+	networkMutex.Lock()
+	defer networkMutex.Unlock()
+	networkTransport.CloseIdleConnections()
+	networkTransport = transport
+	return nil
+}
+
+// Validate checks the proxy URL and the timeout
+func (o NetworkOptions) Validate() error {
+	if o.Proxy != "" {
+		if u, err := url.Parse(o.Proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+			return fmt.Errorf("proxy must be an http(s) or socks5 URL, got %q", o.Proxy)
+		}
+	}
+	if o.ConnectTimeout < 0 {
+		return fmt.Errorf("connect timeout must not be negative, got %d", o.ConnectTimeout)
+	}
+	return nil
+}
+
+// newNetworkTransport returns a transport with the options, trusting roots besides
+// the system's certificate authorities when set
+func newNetworkTransport(options NetworkOptions, roots *x509.CertPool) *http.Transport {
+	timeout := defaultConnectTimeout
+	if options.ConnectTimeout > 0 {
+		timeout = time.Duration(options.ConnectTimeout) * time.Second
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	if options.Proxy != "" {
+		// Like the environment's proxy, it isn't used for NO_PROXY hosts or this
+		// computer's (e.g. Ollama on localhost)
+		proxy := httpproxy.FromEnvironment()
+		proxy.HTTPProxy, proxy.HTTPSProxy = options.Proxy, options.Proxy
+		proxyFunc := proxy.ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) { return proxyFunc(req.URL) }
+	}
+	if roots != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	}
+	return transport
+}
+
+// currentTransport returns the transport of the network options in effect
+func currentTransport() *http.Transport {
+	networkMutex.RLock()
+	defer networkMutex.RUnlock()
+	return networkTransport
+}
+
+// httpClient returns a client using the network options, giving up on a request
+// after timeout (0 = never, e.g. for downloads and long transcriptions)
+func httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: currentTransport(), Timeout: timeout}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestNetworkCACerts tests trusting a certificate authority of the CA file, as
+// behind a firewall re-signing TLS connections
+func TestNetworkCACerts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	defer SetNetworkOptions(NetworkOptions{})
+
+	if _, err := httpClient(5 * time.Second).Get(server.URL); err == nil {
+		t.Fatal("Expected an unknown authority error without the CA file")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "firewall.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+	if err := SetNetworkOptions(NetworkOptions{CACerts: caFile}); err != nil {
+		t.Fatalf("SetNetworkOptions() error: %v", err)
+	}
+	resp, err := httpClient(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request with the CA file failed: %v", err)
+	}
+	resp.Body.Close()
+
+	notPEM := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+	for _, options := range []NetworkOptions{{CACerts: notPEM}, {CACerts: filepath.Join(t.TempDir(), "missing.pem")}, {Proxy: "ftp://proxy"}} {
+		if err := SetNetworkOptions(options); err == nil {
+			t.Errorf("Expected an error for %+v", options)
+		}
+	}
+}
+
+// TestNetworkProxy tests that all clients, not only downloads, go through the proxy
+func TestNetworkProxy(t *testing.T) {
+	var requested string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		w.Write([]byte("{}"))
+	}))
+	defer proxy.Close()
+	defer SetNetworkOptions(NetworkOptions{})
+
+	if err := SetNetworkOptions(NetworkOptions{Proxy: proxy.URL, ConnectTimeout: 5}); err != nil {
+		t.Fatalf("SetNetworkOptions() error: %v", err)
+	}
+	resp, err := httpClient(5*time.Second).Post("http://ollama.example:11434/api/generate", "application/json", nil)
+	if err != nil {
+		t.Fatalf("Request through the proxy failed: %v", err)
+	}
+	resp.Body.Close()
+	if requested != "http://ollama.example:11434/api/generate" {
+		t.Errorf("Expected the Ollama URL requested from the proxy, got %q", requested)
+	}
+	resp, err = httpClient(5 * time.Second).Get(proxy.URL + "/local")
+	if err != nil {
+		t.Fatalf("Request to this computer failed: %v", err)
+	}
+	resp.Body.Close()
+	if requested != "/local" {
+		t.Errorf("Expected this computer reached without the proxy, got %q requested", requested)
+	}
+	if client, _ := downloadClient(); client.Transport != currentTransport() {
+		t.Error("Downloads don't share the network transport")
+	}
+}
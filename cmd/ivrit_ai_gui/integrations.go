@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Export targets
+const (
+	ExportGoogleDocs = "google-docs"
+	ExportNotion     = "notion"
+)
+
+// integrationTimeout bounds a single API request to Google or Notion
+const integrationTimeout = 60 * time.Second
+
+// GoogleToken is a stored OAuth token for the Google Docs API
+type GoogleToken struct {
+	ClientID     string    `json:"clientId"`
+	ClientSecret string    `json:"clientSecret"`
+	AccessToken  string    `json:"accessToken,omitempty"`
+	RefreshToken string    `json:"refreshToken"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// NotionToken is a stored Notion integration token and the page new transcripts go under
+type NotionToken struct {
+	Token        string `json:"token"`
+	ParentPageID string `json:"parentPageId"`
+}
+
+// Integrations holds the credentials for the export integrations.
+// They live apart from config.json because they are secrets written by the app.
+type Integrations struct {
+	Google *GoogleToken `json:"google,omitempty"`
+	Notion *NotionToken `json:"notion,omitempty"`
+}
+
+// integrationsPath returns the location of the stored integration tokens
+func integrationsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "ivrit-ai", "integrations.json")
+}
+
+// LoadIntegrations reads the stored tokens (none stored is not an error)
+func LoadIntegrations() (Integrations, error) {
+	var integrations Integrations
+	data, err := os.ReadFile(integrationsPath())
+	if os.IsNotExist(err) {
+		return integrations, nil
+	}
+	if err != nil {
+		return integrations, err
+	}
+	if err := json.Unmarshal(data, &integrations); err != nil {
+		return Integrations{}, fmt.Errorf("invalid integrations file %s: %v", integrationsPath(), err)
+	}
+	return integrations, nil
+}
+
+// SaveIntegrations stores the tokens, readable only by the current user
+func SaveIntegrations(integrations Integrations) error {
+	path := integrationsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(integrations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Export pushes a transcript to a Google Doc or Notion page titled title and returns its URL
+func Export(target, title string, segments []Segment) (string, error) {
+	integrations, err := LoadIntegrations()
+	if err != nil {
+		return "", err
+	}
+
+	switch target {
+	case ExportGoogleDocs:
+		if integrations.Google == nil {
+			return "", fmt.Errorf("not connected to Google; run with -login google first")
+		}
+		return ExportToGoogleDocs(integrations.Google, title, segments)
+	case ExportNotion:
+		if integrations.Notion == nil {
+			return "", fmt.Errorf("not connected to Notion; run with -login notion first")
+		}
+		return ExportToNotion(integrations.Notion, title, segments)
+	}
+	return "", fmt.Errorf("unknown export target %q (valid: %s, %s)", target, ExportGoogleDocs, ExportNotion)
+}
+
+// Login connects an integration interactively and stores its token
+func Login(service string, in io.Reader) error {
+	integrations, err := LoadIntegrations()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(in)
+
+	switch service {
+	case "google":
+		fmt.Println("Create an OAuth client of type \"Desktop app\" with the Google Docs API enabled")
+		fmt.Println("at https://console.cloud.google.com/apis/credentials, then enter its details.")
+		clientID := prompt(reader, "Client ID: ")
+		clientSecret := prompt(reader, "Client secret: ")
+		token, err := GoogleLogin(clientID, clientSecret)
+		if err != nil {
+			return err
+		}
+		integrations.Google = token
+
+	case "notion":
+		fmt.Println("Create an internal integration at https://www.notion.so/my-integrations and")
+		fmt.Println("share the page that should hold transcripts with it (... > Connections).")
+		token := prompt(reader, "Integration secret: ")
+		pageID, err := notionPageID(prompt(reader, "Parent page URL or ID: "))
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return fmt.Errorf("integration secret is required")
+		}
+		integrations.Notion = &NotionToken{Token: token, ParentPageID: pageID}
+
+	default:
+		return fmt.Errorf("unknown service %q (valid: google, notion)", service)
+	}
+
+	if err := SaveIntegrations(integrations); err != nil {
+		return err
+	}
+	fmt.Printf("Connected to %s. Credentials saved to %s\n", service, integrationsPath())
+	return nil
+}
+
+// prompt asks for one line of input
+func prompt(reader *bufio.Reader, question string) string {
+	fmt.Print(question)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// doJSON sends a JSON API request and decodes the JSON response into result (if not nil)
+func doJSON(method, url, bearer string, headers map[string]string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = strings.NewReader(string(data))
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearer)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	client := httpClient(integrationTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSpeakerTurns tests grouping consecutive segments by speaker
+func TestSpeakerTurns(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום", Speaker: 0},
+		{Start: 2, End: 4, Text: "מה שלומך?", Speaker: 0},
+		{Start: 4, End: 6, Text: "טוב", Speaker: 1},
+		{Start: 65, End: 70, Original: "תודה", Translation: "Thanks", Text: "Thanks", Speaker: 0},
+	}
+
+	turns := SpeakerTurns(segments)
+	if len(turns) != 3 {
+		t.Fatalf("Expected 3 turns, got %d", len(turns))
+	}
+	if text, _ := turns[0].Text(); text != "שלום מה שלומך?" || turns[0].End != 4 {
+		t.Errorf("First turn = %q ending %v", text, turns[0].End)
+	}
+	if text, translation := turns[2].Text(); text != "תודה" || translation != "Thanks" {
+		t.Errorf("Translated turn = %q / %q", text, translation)
+	}
+	if heading := turns[2].Heading(); heading != "Speaker 1 (00:01:05)" {
+		t.Errorf("Heading() = %q", heading)
+	}
+}
+
+// TestGoogleDocRequests tests heading and right-to-left ranges in UTF-16 document indices
+func TestGoogleDocRequests(t *testing.T) {
+	requests := googleDocRequests([]Segment{{Start: 0, End: 2, Text: "שלום"}})
+
+	text := requests[0]["insertText"].(map[string]any)["text"].(string)
+	if text != "Speaker 1 (00:00:00)\nשלום\n" {
+		t.Errorf("Inserted text = %q", text)
+	}
+
+	// Heading paragraph: index 1 up to and including its newline (21 units)
+	heading := requests[1]["updateParagraphStyle"].(map[string]any)
+	if r := heading["range"].(map[string]int); r["startIndex"] != 1 || r["endIndex"] != 22 {
+		t.Errorf("Heading range = %v", r)
+	}
+	// Hebrew paragraph: 4 letters and a newline, right to left
+	body := requests[2]["updateParagraphStyle"].(map[string]any)
+	if r := body["range"].(map[string]int); r["startIndex"] != 22 || r["endIndex"] != 27 {
+		t.Errorf("Body range = %v", r)
+	}
+	if body["paragraphStyle"].(map[string]any)["direction"] != "RIGHT_TO_LEFT" {
+		t.Errorf("Hebrew paragraph should be right to left, got %v", body)
+	}
+
+	if len(googleDocRequests(nil)) != 0 {
+		t.Error("Expected no requests for an empty transcript")
+	}
+}
+
+// TestExportToGoogleDocs tests refreshing an expired token and creating the document
+func TestExportToGoogleDocs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var createdTitle string
+	var updated bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh" {
+				t.Errorf("Unexpected token request: %v", r.Form)
+			}
+			fmt.Fprint(w, `{"access_token": "fresh", "expires_in": 3600}`)
+		case r.Header.Get("Authorization") != "Bearer fresh":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/documents":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			createdTitle = body["title"]
+			fmt.Fprint(w, `{"documentId": "doc123"}`)
+		case r.URL.Path == "/documents/doc123:batchUpdate":
+			updated = true
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(tokenURL, api string) { googleTokenURL, googleDocsAPI = tokenURL, api }(googleTokenURL, googleDocsAPI)
+	googleTokenURL, googleDocsAPI = server.URL+"/token", server.URL
+
+	token := &GoogleToken{ClientID: "id", ClientSecret: "secret", AccessToken: "stale", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+	url, err := ExportToGoogleDocs(token, "Interview - Transcript", []Segment{{Text: "שלום"}})
+	if err != nil {
+		t.Fatalf("ExportToGoogleDocs() error: %v", err)
+	}
+	if url != "https://docs.google.com/document/d/doc123/edit" || createdTitle != "Interview - Transcript" || !updated {
+		t.Errorf("Unexpected export: url %q, title %q, updated %v", url, createdTitle, updated)
+	}
+	if token.AccessToken != "fresh" || time.Until(token.Expiry) < 50*time.Minute {
+		t.Errorf("Token not refreshed: %+v", token)
+	}
+}
+
+// TestExportToNotion tests that long transcripts are sent in batches of Notion's block limit
+func TestExportToNotion(t *testing.T) {
+	var created, appended int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret_token" || r.Header.Get("Notion-Version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Children []json.RawMessage `json:"children"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Children) > notionMaxBlocks {
+			t.Errorf("Request with %d blocks exceeds the limit", len(body.Children))
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/pages":
+			created += len(body.Children)
+			fmt.Fprint(w, `{"id": "page1", "url": "https://www.notion.so/page1"}`)
+		case r.Method == "PATCH" && r.URL.Path == "/blocks/page1/children":
+			appended += len(body.Children)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(api string) { notionAPI = api }(notionAPI)
+	notionAPI = server.URL
+
+	// Alternating speakers: a heading and a paragraph per segment
+	segments := make([]Segment, 80)
+	for i := range segments {
+		segments[i] = Segment{Start: float64(i), End: float64(i + 1), Text: "שלום", Speaker: i % 2}
+	}
+
+	url, err := ExportToNotion(&NotionToken{Token: "secret_token", ParentPageID: "parent"}, "Interview", segments)
+	if err != nil {
+		t.Fatalf("ExportToNotion() error: %v", err)
+	}
+	if url != "https://www.notion.so/page1" || created != 100 || appended != 60 {
+		t.Errorf("Unexpected export: url %q, %d blocks created, %d appended", url, created, appended)
+	}
+}
+
+// TestNotionRichTextLimit tests splitting long text into Notion-sized pieces
+func TestNotionRichTextLimit(t *testing.T) {
+	parts := notionRichText(strings.Repeat("א", notionMaxTextLength+10))
+	if len(parts) != 2 {
+		t.Errorf("Expected 2 rich text parts, got %d", len(parts))
+	}
+	if parts := notionRichText(""); len(parts) != 1 {
+		t.Errorf("Expected one empty part for empty text, got %d", len(parts))
+	}
+}
+
+// TestNotionPageID tests extracting page IDs from Notion URLs
+func TestNotionPageID(t *testing.T) {
+	expected := "1429989f-e8ac-4eff-bc8f-57f56486db54"
+	inputs := []string{
+		"https://www.notion.so/myworkspace/Meeting-Notes-1429989fe8ac4effbc8f57f56486db54",
+		"https://www.notion.so/1429989fe8ac4effbc8f57f56486db54?pvs=4",
+		"1429989f-e8ac-4eff-bc8f-57f56486db54",
+	}
+	for _, input := range inputs {
+		if id, err := notionPageID(input); err != nil || id != expected {
+			t.Errorf("notionPageID(%q) = %q, %v", input, id, err)
+		}
+	}
+	if _, err := notionPageID("https://www.notion.so/not-a-page"); err == nil {
+		t.Error("Expected error for URL without page ID")
+	}
+}
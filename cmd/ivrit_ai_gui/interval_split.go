@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SplitAtParts is the -split-every value splitting a joined recording's transcript
+// at the files it was joined from
+const SplitAtParts = "parts"
+
+// TranscriptPiece is a stretch of a transcript written to a file of its own
+type TranscriptPiece struct {
+	Number   int       // 1-based position among all the stretches, including empty ones
+	Start    float64   // Where the stretch starts in the recording, in seconds
+	Segments []Segment // Timed from the start of the stretch
+}
+
+// ParseSplitInterval parses a -split-every interval: a duration such as "10m" or
+// "1h30m", a timecode such as "00:10:00", or seconds
+func ParseSplitInterval(value string) (float64, error) {
+	seconds := 0.0
+	if d, err := time.ParseDuration(value); err == nil {
+		seconds = d.Seconds()
+	} else if seconds, err = ParseTimecode(value); err != nil {
+		return 0, fmt.Errorf("invalid interval %q (expected e.g. 10m, 00:10:00 or 600)", value)
+	}
+	if seconds < 1 {
+		return 0, fmt.Errorf("invalid interval %q (must be at least a second)", value)
+	}
+	return seconds, nil
+}
+
+// SplitByInterval chops a transcript into stretches of interval seconds
+func SplitByInterval(segments []Segment, interval float64) []TranscriptPiece {
+	end := 0.0
+	for _, seg := range segments {
+		end = math.Max(end, seg.Start)
+	}
+	var starts []float64
+	for start := 0.0; start <= end; start += interval {
+		starts = append(starts, start)
+	}
+	return splitAtTimes(segments, starts)
+}
+
+// SplitByParts chops a joined recording's transcript at the files it was joined from
+func SplitByParts(segments []Segment, parts []RecordingPart) []TranscriptPiece {
+	starts := make([]float64, len(parts))
+	for i, part := range parts {
+		starts[i] = part.Start
+	}
+	return splitAtTimes(segments, starts)
+}
+
+// splitAtTimes chops a transcript at the start times given in order. A segment belongs
+// to the stretch it starts in, and is timed from that stretch's start so each file
+// lines up with the matching clip of a video cut at the same times. Stretches without
+// speech are left out.
+func splitAtTimes(segments []Segment, starts []float64) []TranscriptPiece {
+	var pieces []TranscriptPiece
+	for i, start := range starts {
+		end := math.Inf(1)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		piece := TranscriptPiece{Number: i + 1, Start: start}
+		for _, seg := range segments {
+			if seg.Start < start || seg.Start >= end {
+				continue
+			}
+			seg.Start -= start
+			seg.End -= start
+			if len(seg.Tokens) > 0 {
+				tokens := make([]Token, len(seg.Tokens))
+				for j, token := range seg.Tokens {
+					token.Start -= start
+					token.End -= start
+					tokens[j] = token
+				}
+				seg.Tokens = tokens
+			}
+			piece.Segments = append(piece.Segments, seg)
+		}
+		if len(piece.Segments) > 0 {
+			pieces = append(pieces, piece)
+		}
+	}
+	return pieces
+}
+
+// pieceFileName numbers a stretch's file after the transcript's, padding the number
+// so the files sort in order (e.g. talk.srt → talk_03.srt)
+func pieceFileName(path string, number, total int) string {
+	ext := filepath.Ext(path)
+	width := max(2, len(fmt.Sprint(total)))
+	return fmt.Sprintf("%s_%0*d%s", strings.TrimSuffix(path, ext), width, number, ext)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseSplitInterval tests the -split-every values
+func TestParseSplitInterval(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+	}{
+		{"10m", 600},
+		{"1h30m", 5400},
+		{"00:10:00", 600},
+		{"90", 90},
+	}
+	for _, tt := range tests {
+		if got, err := ParseSplitInterval(tt.value); err != nil || got != tt.expected {
+			t.Errorf("ParseSplitInterval(%q) = %v, %v, expected %v", tt.value, got, err, tt.expected)
+		}
+	}
+	for _, value := range []string{"", "0", "-5m", "ten minutes"} {
+		if _, err := ParseSplitInterval(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+// TestSplitByInterval tests chopping a transcript into stretches
+func TestSplitByInterval(t *testing.T) {
+	segments := []Segment{
+		{Start: 5, End: 10, Text: "אחת"},
+		{Start: 598, End: 603, Text: "שתיים"},
+		{Start: 610, End: 615, Text: "שלוש"},
+		{Start: 1850, End: 1855, Text: "ארבע"},
+	}
+
+	pieces := SplitByInterval(segments, 600)
+	if len(pieces) != 3 {
+		t.Fatalf("Expected 3 stretches with speech, got %d", len(pieces))
+	}
+	if pieces[0].Number != 1 || len(pieces[0].Segments) != 2 || pieces[0].Segments[1].End != 603 {
+		t.Errorf("Expected a segment crossing the boundary kept whole in its first stretch, got %+v", pieces[0])
+	}
+	if pieces[1].Number != 2 || pieces[1].Segments[0].Start != 10 {
+		t.Errorf("Expected the second stretch timed from 10:00, got %+v", pieces[1])
+	}
+	if pieces[2].Number != 4 || pieces[2].Start != 1800 {
+		t.Errorf("Expected the silent third stretch left out, got %+v", pieces[2])
+	}
+	if segments[1].Start != 598 || segments[2].Start != 610 {
+		t.Error("Expected the transcript unchanged")
+	}
+
+	srt := FormatOutput(pieces[1].Segments, "srt", "original")
+	if !strings.HasPrefix(srt, "1\n00:00:10,000 --> 00:00:15,000\n") {
+		t.Errorf("Expected renumbered cues, got:\n%s", srt)
+	}
+}
+
+// TestSplitByParts tests splitting a joined recording at its files
+func TestSplitByParts(t *testing.T) {
+	parts := []RecordingPart{{File: "a.mp3", Start: 0, Duration: 100}, {File: "b.mp3", Start: 100, Duration: 50}}
+	segments := []Segment{{Start: 10, End: 20}, {Start: 99.5, End: 104}, {Start: 120, End: 130}}
+
+	pieces := SplitByParts(segments, parts)
+	if len(pieces) != 2 || len(pieces[0].Segments) != 2 || pieces[1].Segments[0].Start != 20 {
+		t.Errorf("Unexpected stretches: %+v", pieces)
+	}
+}
+
+// TestPieceFileName tests numbering the files of the stretches
+func TestPieceFileName(t *testing.T) {
+	if name := pieceFileName("talk_transcription.srt", 3, 12); name != "talk_transcription_03.srt" {
+		t.Errorf("Unexpected file name: %s", name)
+	}
+	if name := pieceFileName("talk.vtt", 7, 120); name != "talk_007.vtt" {
+		t.Errorf("Unexpected file name: %s", name)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JobManifest is the machine-readable record of a CLI run (-manifest): for each
+// input, the provenance the JSON output's manifest carries, how long it took and
+// the files written, for pipelines that archive it with the transcripts
+type JobManifest struct {
+	AppVersion     string       `json:"appVersion"`
+	WhisperVersion string       `json:"whisperVersion"`
+	StartedAt      time.Time    `json:"startedAt"`
+	FinishedAt     time.Time    `json:"finishedAt"`
+	Jobs           []*JobRecord `json:"jobs"`
+}
+
+// JobRecord describes the transcription of one input
+type JobRecord struct {
+	InputPath      string    `json:"inputPath"`
+	Manifest       *Manifest `json:"manifest,omitempty"`       // Missing when the job failed before transcribing
+	AudioDuration  float64   `json:"audioDuration,omitempty"`  // Seconds of audio transcribed
+	ProcessingTime float64   `json:"processingTime"`           // Seconds, from transcription to the last file written
+	RealtimeFactor float64   `json:"realtimeFactor,omitempty"` // Processing seconds per second of audio
+	Outputs        []string  `json:"outputs"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// NewJobManifest starts the manifest of a run
+func NewJobManifest() *JobManifest {
+	return &JobManifest{
+		AppVersion:     appVersion,
+		WhisperVersion: whisperVersion,
+		StartedAt:      time.Now().UTC().Truncate(time.Second),
+		Jobs:           []*JobRecord{},
+	}
+}
+
+// Job returns the record of an input, adding it on first use
+func (m *JobManifest) Job(inputPath string) *JobRecord {
+	path := absolutePath(inputPath)
+	for _, job := range m.Jobs {
+		if job.InputPath == path {
+			return job
+		}
+	}
+	job := &JobRecord{InputPath: path, Outputs: []string{}}
+	m.Jobs = append(m.Jobs, job)
+	return job
+}
+
+// AddOutput records a file the job wrote
+func (r *JobRecord) AddOutput(path string) {
+	r.Outputs = append(r.Outputs, absolutePath(path))
+}
+
+// Finish records how long the job took and how it ended
+func (r *JobRecord) Finish(elapsed time.Duration, err error) {
+	r.ProcessingTime = elapsed.Seconds()
+	if r.AudioDuration > 0 {
+		r.RealtimeFactor = elapsed.Seconds() / r.AudioDuration
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Write saves the manifest as JSON
+func (m *JobManifest) Write(path string) error {
+	m.FinishedAt = time.Now().UTC().Truncate(time.Second)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// absolutePath makes a path absolute, so the manifest locates files wherever it is read
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestJobManifest tests recording the jobs of a run and writing them as JSON
+func TestJobManifest(t *testing.T) {
+	dir := t.TempDir()
+	m := NewJobManifest()
+
+	job := m.Job(filepath.Join(dir, "a.m4a"))
+	job.Manifest = &Manifest{Model: "turbo", Input: "a.m4a", InputSHA256: "abc"}
+	job.AudioDuration = 120
+	job.AddOutput(filepath.Join(dir, "a_transcription.srt"))
+	m.Job(filepath.Join(dir, "a.m4a")).AddOutput(filepath.Join(dir, "a_transcription_redacted.srt"))
+	job.Finish(30*time.Second, nil)
+	m.Job(filepath.Join(dir, "b.m4a")).Finish(0, errors.New("conversion failed"))
+
+	path := filepath.Join(dir, "run.json")
+	if err := m.Write(path); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed JobManifest
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Manifest is not valid JSON: %v\n%s", err, data)
+	}
+
+	if len(parsed.Jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(parsed.Jobs))
+	}
+	first := parsed.Jobs[0]
+	if first.Manifest == nil || first.Manifest.InputSHA256 != "abc" || first.RealtimeFactor != 0.25 || first.ProcessingTime != 30 {
+		t.Errorf("Unexpected first job: %+v", first)
+	}
+	if len(first.Outputs) != 2 || !filepath.IsAbs(first.Outputs[0]) {
+		t.Errorf("Expected both outputs with absolute paths, got %v", first.Outputs)
+	}
+	if second := parsed.Jobs[1]; second.Error != "conversion failed" || second.Manifest != nil {
+		t.Errorf("Unexpected failed job: %+v", second)
+	}
+	if parsed.FinishedAt.IsZero() || parsed.AppVersion != appVersion {
+		t.Errorf("Unexpected run details: %+v", parsed)
+	}
+}
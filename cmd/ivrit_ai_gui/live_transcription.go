@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// A recording in progress, e.g. of the system audio during a video call, is
+// transcribed live with a sliding window, as whisper.cpp's stream example does: what
+// was recorded is read back from its file as it grows, and every liveStepSecs the
+// audio not yet settled is transcribed again. Segments two passes in a row agree on
+// are settled and their audio dropped; the rest is shown as provisional text, which
+// firms up as more audio arrives.
+const (
+	liveStepSecs      = 2  // New audio between passes
+	liveWindowSecs    = 15 // Beyond this much unsettled audio, all but the last segment is settled
+	liveMaxWindowSecs = 30 // Beyond this (whisper's context), all of it is settled
+	livePollInterval  = 500 * time.Millisecond
+)
+
+// liveStream holds the unsettled audio of a recording and settles its segments by
+// local agreement between consecutive passes
+type liveStream struct {
+	buf      []float32
+	start    int      // Position of buf[0] in the recording, in samples
+	fresh    int      // Samples added since the last pass
+	previous []string // Texts of the last pass's unsettled segments
+}
+
+// add appends samples read from the recording
+func (s *liveStream) add(samples []float32) {
+	s.buf = append(s.buf, samples...)
+	s.fresh += len(samples)
+}
+
+// ready reports whether enough audio arrived since the last pass for another
+func (s *liveStream) ready() bool {
+	return s.fresh >= liveStepSecs*whisperSampleRate
+}
+
+// window returns the unsettled audio to transcribe and the position it starts at
+func (s *liveStream) window() ([]float32, int) {
+	s.fresh = 0
+	return append([]float32(nil), s.buf...), s.start
+}
+
+// settle takes a pass's segments (at their time in the recording) and returns those
+// now settled and those still provisional. A segment is settled once the previous
+// pass had it too, along with all segments before it; the last segment stays
+// provisional, as its audio may end mid-word, unless the window has grown too long
+// or the recording has ended (final).
+func (s *liveStream) settle(segments []Segment, final bool) (settled, provisional []Segment) {
+	n := 0
+	for n < len(segments)-1 && n < len(s.previous) && strings.TrimSpace(segments[n].Text) == s.previous[n] {
+		n++
+	}
+	switch {
+	case final || len(s.buf) >= liveMaxWindowSecs*whisperSampleRate:
+		n = len(segments)
+	case len(s.buf) >= liveWindowSecs*whisperSampleRate:
+		n = max(n, len(segments)-1)
+	}
+	settled, provisional = segments[:n], segments[n:]
+
+	// Drop the settled audio; the next pass starts where the last settled segment ends
+	cut := len(s.buf)
+	if len(provisional) > 0 {
+		cut = 0
+		if n > 0 {
+			cut = min(len(s.buf), max(0, int(settled[n-1].End*whisperSampleRate)-s.start))
+		}
+	}
+	s.buf = append(s.buf[:0], s.buf[cut:]...)
+	s.start += cut
+	s.previous = s.previous[:0]
+	for _, seg := range provisional {
+		s.previous = append(s.previous, strings.TrimSpace(seg.Text))
+	}
+	return settled, provisional
+}
+
+// LiveTranscriber transcribes a recording while it is being made
+type LiveTranscriber struct {
+	recorder   *Recorder
+	engine     TranscriptionEngine
+	modelID    string
+	cpuThreads int
+	onSegment  func(Segment)
+	onPartial  func(string)
+
+	stop     chan struct{}
+	done     chan struct{}
+	segments []Segment // Written by run only, read once done
+	err      error
+}
+
+// StartLiveTranscription transcribes the recording in progress with the engine,
+// calling onSegment with each segment as it is settled and onPartial with the
+// provisional text after it (empty once all is settled). The engine is closed by
+// Finish.
+func StartLiveTranscription(rec *Recorder, engine TranscriptionEngine, modelID string, cpuThreads int, onSegment func(Segment), onPartial func(string)) *LiveTranscriber {
+	l := &LiveTranscriber{
+		recorder:   rec,
+		engine:     engine,
+		modelID:    modelID,
+		cpuThreads: cpuThreads,
+		onSegment:  onSegment,
+		onPartial:  onPartial,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Finish transcribes the rest of the recording, which must have been stopped, closes
+// the engine and returns the whole transcript
+func (l *LiveTranscriber) Finish() ([]Segment, error) {
+	close(l.stop)
+	<-l.done
+	l.engine.Close()
+	return l.segments, l.err
+}
+
+// run reads the recording as it grows and transcribes the unsettled audio every
+// step, until Finish, then settles what is left
+func (l *LiveTranscriber) run() {
+	defer close(l.done)
+	f, err := os.Open(l.recorder.Path)
+	if err != nil {
+		l.err = err
+		return
+	}
+	defer f.Close()
+
+	var stream liveStream
+	var read int64 // PCM bytes read from the recording
+	speakerOffset := 0
+	ticker := time.NewTicker(livePollInterval)
+	defer ticker.Stop()
+	for final := false; !final; {
+		select {
+		case <-l.stop:
+			final = true
+		case <-ticker.C:
+		}
+
+		if recorded := l.recorder.recorded(); recorded > read {
+			pcm := make([]byte, recorded-read)
+			n, err := f.ReadAt(pcm, wavHeaderSize+read)
+			if n < len(pcm) {
+				l.err = fmt.Errorf("cannot read the recording: %v", err)
+				return
+			}
+			read += int64(n)
+			samples, _ := readPCM16Samples(bytes.NewReader(pcm), len(pcm)/2)
+			stream.add(samples)
+		}
+		if len(stream.buf) == 0 || (!final && !stream.ready()) {
+			continue
+		}
+
+		samples, start := stream.window()
+		segments, err := l.transcribeWindow(samples, start)
+		if err != nil {
+			l.err = err
+			return
+		}
+		settled, provisional := stream.settle(segments, final)
+
+		// Speaker numbering continues across windows
+		for _, seg := range settled {
+			seg.Speaker += speakerOffset
+			l.segments = append(l.segments, seg)
+			if l.onSegment != nil {
+				l.onSegment(seg)
+			}
+		}
+		if len(settled) > 0 {
+			speakerOffset = l.segments[len(l.segments)-1].Speaker
+		}
+		if l.onPartial != nil {
+			texts := make([]string, len(provisional))
+			for i, seg := range provisional {
+				texts[i] = strings.TrimSpace(seg.Text)
+			}
+			l.onPartial(strings.Join(texts, " "))
+		}
+	}
+}
+
+// transcribeWindow transcribes a window of the recording starting at sample start,
+// returning its segments at their time in the recording
+func (l *LiveTranscriber) transcribeWindow(samples []float32, start int) ([]Segment, error) {
+	pcm := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(math.Max(-32768, math.Min(32767, float64(s)*32768)))))
+	}
+	f, err := CreateTempFile("live_window_*.wav")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	defer RemoveTempFile(path)
+	_, err = f.Write(append(wavHeader(int64(len(pcm))), pcm...))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := l.engine.Transcribe(path, l.modelID, l.cpuThreads, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	segments = append([]Segment(nil), segments...) // Not the engine's cached copy
+	offset := float64(start) / whisperSampleRate
+	for i := range segments {
+		segments[i].Start += offset
+		segments[i].End += offset
+	}
+	return segments, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLiveStreamSettle tests settling the segments two passes agree on, and
+// dropping their audio from the window
+func TestLiveStreamSettle(t *testing.T) {
+	var s liveStream
+	s.add(make([]float32, 3*whisperSampleRate))
+	if !s.ready() {
+		t.Fatal("Not ready after a step of audio")
+	}
+	s.window()
+	if s.ready() {
+		t.Fatal("Ready again without new audio")
+	}
+
+	// The first pass settles nothing
+	first := []Segment{{Start: 0, End: 1.5, Text: " שלום לכולם"}, {Start: 1.5, End: 3, Text: " ברוכים הבא"}}
+	settled, provisional := s.settle(first, false)
+	if len(settled) != 0 || len(provisional) != 2 || s.start != 0 {
+		t.Fatalf("First pass settled %d, %d provisional, start %d", len(settled), len(provisional), s.start)
+	}
+
+	// The second agrees on the first segment; the last stays provisional
+	s.add(make([]float32, 2*whisperSampleRate))
+	second := []Segment{{Start: 0, End: 1.5, Text: " שלום לכולם"}, {Start: 1.5, End: 4, Text: " ברוכים הבאים"}, {Start: 4, End: 5, Text: " לתוכנית"}}
+	settled, provisional = s.settle(second, false)
+	if len(settled) != 1 || settled[0].Text != " שלום לכולם" || len(provisional) != 2 {
+		t.Fatalf("Second pass settled %v, provisional %v", settled, provisional)
+	}
+	if s.start != 24000 || len(s.buf) != 5*whisperSampleRate-24000 {
+		t.Errorf("Window starts at %d with %d samples, want the settled audio dropped", s.start, len(s.buf))
+	}
+
+	// A changed segment isn't settled; the recording's end settles everything
+	settled, _ = s.settle([]Segment{{Start: 1.5, End: 4, Text: " ברוכים הבאים"}, {Start: 4, End: 5, Text: " לתכנית"}}, false)
+	if len(settled) != 1 {
+		t.Errorf("Settled %v, want the agreed segment only", settled)
+	}
+	settled, provisional = s.settle([]Segment{{Start: 4, End: 5, Text: " לתכנית"}}, true)
+	if len(settled) != 1 || len(provisional) != 0 || len(s.buf) != 0 {
+		t.Errorf("Final pass settled %d, %d provisional, %d samples left", len(settled), len(provisional), len(s.buf))
+	}
+}
+
+// TestLiveStreamLongWindow tests settling all but the last segment once the window is long
+func TestLiveStreamLongWindow(t *testing.T) {
+	var s liveStream
+	s.add(make([]float32, liveWindowSecs*whisperSampleRate))
+	s.window()
+	settled, provisional := s.settle([]Segment{{Start: 0, End: 7, Text: "אחת"}, {Start: 7, End: 12, Text: "שתיים"}, {Start: 12, End: 15, Text: "שלוש"}}, false)
+	if len(settled) != 2 || len(provisional) != 1 || s.start != 12*whisperSampleRate {
+		t.Errorf("Settled %d, %d provisional, window at %d", len(settled), len(provisional), s.start)
+	}
+}
+
+// TestLiveTranscriber tests transcribing a recording as it grows, with provisional
+// text shown until the recording ends and segments at their time in the recording
+func TestLiveTranscriber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	pcm := make([]byte, 2*20*whisperSampleRate)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(i%200-100)))
+	}
+	if err := os.WriteFile(path, append(wavHeader(int64(len(pcm))), pcm...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rec := &Recorder{Path: path, written: int64(len(pcm))}
+
+	engine := &fakeEngine{segments: []Segment{{Start: 0.5, End: 1.5, Text: "שלום"}, {Start: 2, End: 3, Text: "עולם"}}}
+	var streamed []Segment
+	var partials []string
+	live := StartLiveTranscription(rec, engine, "turbo", 4,
+		func(seg Segment) { streamed = append(streamed, seg) },
+		func(partial string) { partials = append(partials, partial) })
+	time.Sleep(livePollInterval + 200*time.Millisecond) // A pass while recording
+	segments, err := live.Finish()
+	if err != nil {
+		t.Fatalf("Finish() error: %v", err)
+	}
+	if engine.calls != 2 || !engine.closed {
+		t.Errorf("Engine called %d times, closed %v; want 2 passes and closed", engine.calls, engine.closed)
+	}
+
+	// The 20s window settles its first segment at once; the end settles the rest
+	if len(segments) != 3 || len(streamed) != 3 {
+		t.Fatalf("Expected 3 segments, got %d (%d streamed)", len(segments), len(streamed))
+	}
+	if segments[0].Start != 0.5 || segments[1].Start != 2 || segments[2].Start != 3.5 {
+		t.Errorf("Unexpected segment times: %.2f, %.2f, %.2f", segments[0].Start, segments[1].Start, segments[2].Start)
+	}
+	if len(partials) != 2 || partials[0] != "עולם" || partials[1] != "" {
+		t.Errorf("Unexpected provisional text: %q", partials)
+	}
+}
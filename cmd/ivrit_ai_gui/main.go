@@ -1,44 +1,96 @@
+//go:build !headless
+
 package main
 
 import (
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"gioui.org/app"
 	"gioui.org/op"
+	"gioui.org/unit"
 )
 
 func main() {
 	// Check if running in CLI mode (any command-line arguments provided)
 	if len(os.Args) > 1 {
-		// Check if the first arg is a flag (starts with -)
-		if os.Args[1][0] == '-' {
+		// Check if the first arg is a flag (starts with -) or a subcommand
+		if os.Args[1][0] == '-' || IsSubcommand(os.Args[1]) {
 			CLIMode()
 			return
 		}
 	}
 
-	// Run GUI mode
+	// Run GUI mode, unless the app is running already: this launch's files (e.g. from
+	// "Open With") are then handed to it, which comes to the front
+	files := os.Args[1:]
+	lock, forwarded, err := AcquireInstanceLock(instanceSocketPath(), files, openLaunchedFiles)
+	if forwarded {
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: other launches of the app will open a second app: %v", err)
+	}
+
+	// The app exits when the last session window closes
+	ShutdownOnSignal()
+	go CleanOrphanedTempFiles()
+	settings := LoadSettings()
+	SetCoreMLEnabled(settings.CoreMLEncoder)
+	warnMissingWhisperFeatures()
+	shared := &SharedSettings{Settings: settings}
+	setLaunchWindowOpener(func() { openSessionWindow(shared, false) })
+	openSessionWindow(shared, true)
+	if len(files) > 0 {
+		openLaunchedFiles(files)
+	}
 	go func() {
-		w := new(app.Window)
-		w.Option(app.Title("ivrit.ai - Hebrew Audio Transcription"))
-		w.Option(app.Size(900, 700))
-		if err := run(w); err != nil {
-			log.Fatal(err)
+		sessionWindows.Wait()
+		if lock != nil {
+			lock.Release()
 		}
+		Shutdown() // Stops a transcription still running, saving what it has
 		os.Exit(0)
 	}()
 	app.Main()
 }
 
-func run(w *app.Window) error {
+// windowTitle is the title of session windows
+const windowTitle = "ivrit.ai - Hebrew Audio Transcription"
+
+// sessionWindows counts the open session windows
+var sessionWindows sync.WaitGroup
+
+// openSessionWindow opens a window with its own transcription session. Sessions
+// share the user settings and loaded models, so one transcript can be reviewed
+// while another file transcribes.
+func openSessionWindow(settings *SharedSettings, warmStart bool) {
+	sessionWindows.Add(1)
+	go func() {
+		defer sessionWindows.Done()
+		w := new(app.Window)
+		w.Option(app.Title(windowTitle))
+		settings.Lock()
+		width, height := settings.WindowSize()
+		settings.Unlock()
+		w.Option(app.Size(unit.Dp(width), unit.Dp(height)))
+		w.Option(app.MinSize(minWindowWidth, minWindowHeight))
+		if err := run(w, settings, warmStart); err != nil {
+			log.Fatal(err)
+		}
+	}()
+}
+
+func run(w *app.Window, settings *SharedSettings, warmStart bool) error {
 	var ops op.Ops
-	gioApp := NewGioApp(w)
+	gioApp := NewGioApp(w, settings, warmStart)
 
 	// Ticker for UI refresh during transcription (avoids CGO thread safety issues)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
+	shown := false // Files from later launches are handed over once the window is up
 
 	for {
 		// Check for timer tick
@@ -53,11 +105,21 @@ func run(w *app.Window) error {
 		// Handle window events
 		switch e := w.Event().(type) {
 		case app.DestroyEvent:
+			removeLaunchedSession(gioApp)
+			gioApp.saveWindowSize()
+			gioApp.removeDownloadedMedia()
+			gioApp.removeJoinedRecording()
+			gioApp.player.Stop()
 			return e.Err
 		case app.FrameEvent:
+			gioApp.rememberWindowSize(e.Size, e.Metric)
 			gtx := app.NewContext(&ops, e)
 			gioApp.Layout(gtx)
 			e.Frame(gtx.Ops)
+			if !shown {
+				shown = true
+				addLaunchedSession(gioApp)
+			}
 		}
 	}
 }
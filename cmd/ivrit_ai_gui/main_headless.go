@@ -0,0 +1,11 @@
+//go:build headless
+
+package main
+
+// Headless entry point: built with -tags headless, the binary has no Gio/dialog
+// dependencies (and needs no X11/Wayland libraries), so it runs on servers and
+// in containers. All arguments go to the CLI.
+
+func main() {
+	CLIMode()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Build information, set at link time by scripts/build.sh:
+//
+//	go build -ldflags "-X main.appVersion=1.2.0 -X main.whisperVersion=1.7.4"
+var (
+	appVersion     = "dev"
+	whisperVersion = "unknown"
+)
+
+// Manifest records everything needed to reproduce a transcript or audit its provenance
+type Manifest struct {
+	AppVersion     string             `json:"appVersion"`
+	WhisperVersion string             `json:"whisperVersion"`
+	Model          string             `json:"model"`
+	ModelFile      string             `json:"modelFile"`
+	ModelSHA256    string             `json:"modelSha256,omitempty"`
+	Input          string             `json:"input"`
+	InputSHA256    string             `json:"inputSha256,omitempty"`
+	Parameters     ManifestParameters `json:"parameters"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+// ManifestParameters are the options that affect the transcript content
+type ManifestParameters struct {
+	Language         string          `json:"language"`
+	Threads          int             `json:"threads"`
+	ChannelMode      string          `json:"channels"`
+	Parallel         int             `json:"parallel"`
+	From             float64         `json:"from,omitempty"` // Time range start in seconds
+	To               float64         `json:"to,omitempty"`   // Time range end in seconds (0 = end of file)
+	Decode           DecodeOptions   `json:"decode"`
+	TranslateTo      string          `json:"translateTo,omitempty"`
+	TranslationModel string          `json:"translationModel,omitempty"`
+	Consensus        string          `json:"consensus,omitempty"`       // Consensus mode, when transcribed twice
+	ConsensusWith    string          `json:"consensusWith,omitempty"`   // The second pass compared with
+	Engine           string          `json:"engine,omitempty"`          // Engine that ran, with a remote one's address (empty = whisper.cpp in the app)
+	Transliteration  string          `json:"transliteration,omitempty"` // Transliteration method, when transliterated
+	Parts            []RecordingPart `json:"parts,omitempty"`           // Files joined into the recording, with where each starts
+	Participants     []string        `json:"participants,omitempty"`    // Participants whose tracks were transcribed, in speaker order
+	RecordingStart   string          `json:"recordingStart,omitempty"`  // When the recording began (RFC 3339), when known
+}
+
+// NewManifest builds the manifest for a transcription run. Hashing failures are
+// not fatal: the manifest is still useful without them, so the hash is left empty.
+func NewManifest(modelID, modelPath, inputPath string, params ManifestParameters) Manifest {
+	manifest := Manifest{
+		AppVersion:     appVersion,
+		WhisperVersion: whisperVersion,
+		Model:          modelID,
+		Input:          filepath.Base(inputPath),
+		Parameters:     params,
+		CreatedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+
+	// Remote engines run their own copy of the model, which can't be hashed here
+	if modelPath != "" {
+		manifest.ModelFile = filepath.Base(modelPath)
+		if hash, err := modelHash(modelPath); err == nil {
+			manifest.ModelSHA256 = hash
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Cannot hash model: %v\n", err)
+		}
+	}
+	if hash, err := fileSHA256(inputPath); err == nil {
+		manifest.InputSHA256 = hash
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: Cannot hash input: %v\n", err)
+	}
+
+	return manifest
+}
+
+// fileSHA256 returns the hex SHA-256 of a file's contents
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// modelHash returns the SHA-256 of a model file. Models are several GB, so the
+// hash is cached in a "<model>.sha256" file next to it and reused while that
+// file is newer than the model.
+func modelHash(modelPath string) (string, error) {
+	modelInfo, err := os.Stat(modelPath)
+	if err != nil {
+		return "", err
+	}
+
+	cachePath := modelPath + ".sha256"
+	if cacheInfo, err := os.Stat(cachePath); err == nil && !cacheInfo.ModTime().Before(modelInfo.ModTime()) {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			if hash := strings.TrimSpace(string(data)); len(hash) == sha256.Size*2 {
+				return hash, nil
+			}
+		}
+	}
+
+	hash, err := fileSHA256(modelPath)
+	if err != nil {
+		return "", err
+	}
+	// Caching is best effort (the model directory may be read-only)
+	os.WriteFile(cachePath, []byte(hash+"\n"), 0644)
+
+	return hash, nil
+}
+
+// jsonTranscriptVersion is the version of the JSON transcript layout written by
+// AttachManifest. Version 1, a bare segment array without a version key, is what
+// earlier releases wrote; readers accept both.
+const jsonTranscriptVersion = 2
+
+// AttachManifest wraps JSON segment output together with its manifest:
+// {"version": 2, "manifest": {...}, "segments": [...]}
+func AttachManifest(jsonOutput string, manifest Manifest) string {
+	manifestJSON, err := json.MarshalIndent(manifest, "  ", "  ")
+	if err != nil {
+		return jsonOutput
+	}
+
+	// Indent the segment array to sit inside the wrapping object
+	segments := strings.ReplaceAll(jsonOutput, "\n", "\n  ")
+
+	return fmt.Sprintf("{\n  \"version\": %d,\n  \"manifest\": ", jsonTranscriptVersion) + string(manifestJSON) + ",\n  \"segments\": " + segments + "\n}"
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAttachManifest tests that JSON output with a manifest is valid JSON
+func TestAttachManifest(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2.5, Text: "שלום"},
+		{Start: 2.5, End: 5, Text: "עולם", Speaker: 1},
+	}
+	manifest := Manifest{
+		AppVersion: "1.0.0",
+		Model:      "turbo",
+		Input:      "test.m4a",
+		Parameters: ManifestParameters{Language: "he", Threads: 4, Decode: DecodeOptions{BeamSize: 5}},
+	}
+
+	output := AttachManifest(FormatOutput(segments, "json", DisplayBilingual), manifest)
+
+	var parsed struct {
+		Version  int      `json:"version"`
+		Manifest Manifest `json:"manifest"`
+		Segments []struct {
+			Text string `json:"text"`
+		} `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, output)
+	}
+
+	if parsed.Version != jsonTranscriptVersion {
+		t.Errorf("Expected version %d, got %d", jsonTranscriptVersion, parsed.Version)
+	}
+	if parsed.Manifest.Model != "turbo" || parsed.Manifest.Parameters.Decode.BeamSize != 5 {
+		t.Errorf("Manifest not preserved, got %+v", parsed.Manifest)
+	}
+	if len(parsed.Segments) != 2 || parsed.Segments[1].Text != "עולם" {
+		t.Errorf("Segments not preserved, got %+v", parsed.Segments)
+	}
+}
+
+// TestModelHashCache tests that the model hash is cached next to the model and refreshed when stale
+func TestModelHashCache(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-manifest-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	modelPath := filepath.Join(tmpDir, "model.bin")
+	if err := os.WriteFile(modelPath, []byte("model weights"), 0644); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	hash, err := modelHash(modelPath)
+	if err != nil {
+		t.Fatalf("modelHash() error: %v", err)
+	}
+	expected, _ := fileSHA256(modelPath)
+	if hash != expected {
+		t.Errorf("modelHash() = %s, expected %s", hash, expected)
+	}
+	if _, err := os.Stat(modelPath + ".sha256"); err != nil {
+		t.Errorf("Hash cache file should exist: %v", err)
+	}
+
+	// A cached hash is reused while it is newer than the model
+	fake := "0000000000000000000000000000000000000000000000000000000000000000"
+	os.WriteFile(modelPath+".sha256", []byte(fake+"\n"), 0644)
+	if hash, _ := modelHash(modelPath); hash != fake {
+		t.Errorf("Expected cached hash to be used, got %s", hash)
+	}
+
+	// Replacing the model invalidates the cache
+	future := mustModTime(t, modelPath+".sha256").Add(time.Second)
+	os.Chtimes(modelPath, future, future)
+	if hash, _ := modelHash(modelPath); hash != expected {
+		t.Errorf("Expected stale cache to be recomputed, got %s", hash)
+	}
+}
+
+// mustModTime returns a file's modification time or fails the test
+func mustModTime(t *testing.T, path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	return info.ModTime()
+}
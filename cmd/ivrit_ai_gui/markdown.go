@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownChapterLength starts a new heading within a long turn (e.g. a single-speaker
+// lecture) at the first segment boundary after this many seconds
+const markdownChapterLength = 5 * 60.0
+
+// FormatMarkdown renders the transcript for publishing on blogs and wikis: a heading
+// per speaker turn (or chapter of a long turn) with its start time, and translated
+// Hebrew blockquoted above its translation. When mediaURL is set, the timestamps
+// link to that moment in the recording.
+func FormatMarkdown(segments []Segment, mediaURL string) string {
+	var b strings.Builder
+	writeMarkdownTurns(&b, segments, "##", mediaURL)
+	return b.String()
+}
+
+// writeMarkdownTurns writes a heading of the given level and the text of each turn
+func writeMarkdownTurns(b *strings.Builder, segments []Segment, heading, mediaURL string) {
+	for _, turn := range markdownChapters(SpeakerTurns(segments)) {
+		text, translation := turn.Text()
+		if label := currentSpeakerLabels().Write(turn.Label(), "markdown"); label != "" {
+			fmt.Fprintf(b, "%s %s (%s)\n\n", heading, label, markdownTimestamp(turn.Start, mediaURL))
+		} else {
+			fmt.Fprintf(b, "%s %s\n\n", heading, markdownTimestamp(turn.Start, mediaURL))
+		}
+		if translation == "" {
+			fmt.Fprintf(b, "%s\n\n", strings.Join(Paragraphs(text), "\n\n"))
+			continue
+		}
+		fmt.Fprintf(b, "> %s\n\n%s\n\n", text, translation)
+	}
+}
+
+// markdownChapters splits turns longer than markdownChapterLength into chapters
+func markdownChapters(turns []SpeakerTurn) []SpeakerTurn {
+	chapters := []SpeakerTurn{}
+	for _, turn := range turns {
+		chapter := SpeakerTurn{Speaker: turn.Speaker, Name: turn.Name, Start: turn.Start}
+		for _, seg := range turn.Segments {
+			if len(chapter.Segments) > 0 && seg.Start-chapter.Start >= markdownChapterLength {
+				chapters = append(chapters, chapter)
+				chapter = SpeakerTurn{Speaker: turn.Speaker, Name: turn.Name, Start: seg.Start}
+			}
+			chapter.End = seg.End
+			chapter.Segments = append(chapter.Segments, seg)
+		}
+		chapters = append(chapters, chapter)
+	}
+	return chapters
+}
+
+// markdownTimestamp formats a start time as HH:MM:SS, linked to that moment in the
+// recording when mediaURL is set. YouTube links use the t query parameter; other
+// URLs a media fragment (#t=seconds), which browsers' audio and video players honor.
+func markdownTimestamp(seconds float64, mediaURL string) string {
+	timestamp := FormatTimestamp(seconds, true)[:8]
+	if mediaURL == "" {
+		return timestamp
+	}
+
+	link := fmt.Sprintf("%s#t=%d", strings.SplitN(mediaURL, "#", 2)[0], int(seconds))
+	if strings.Contains(mediaURL, "youtube.com/") || strings.Contains(mediaURL, "youtu.be/") {
+		separator := "?"
+		if strings.Contains(mediaURL, "?") {
+			separator = "&"
+		}
+		link = fmt.Sprintf("%s%st=%d", mediaURL, separator, int(seconds))
+	}
+	// Spaces in local file names would end the link
+	return fmt.Sprintf("[%s](%s)", timestamp, strings.ReplaceAll(link, " ", "%20"))
+}
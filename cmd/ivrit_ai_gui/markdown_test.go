@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFormatMarkdown tests headings per turn, blockquoted Hebrew and timestamp links
+func TestFormatMarkdown(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום", Speaker: 0},
+		{Start: 2, End: 4, Text: "מה שלומך?", Speaker: 0},
+		{Start: 65, End: 70, Original: "טוב", Translation: "Good", Text: "Good", Speaker: 1},
+	}
+
+	expected := "## Speaker 1 ([00:00:00](talk.m4a#t=0))\n\nשלום מה שלומך?\n\n" +
+		"## Speaker 2 ([00:01:05](talk.m4a#t=65))\n\n> טוב\n\nGood\n\n"
+	if output := FormatMarkdown(segments, "talk.m4a"); output != expected {
+		t.Errorf("FormatMarkdown() =\n%s\nexpected\n%s", output, expected)
+	}
+
+	if output := FormatOutput(segments, "markdown", DisplayBilingual); !strings.HasPrefix(output, "## Speaker 1 (00:00:00)\n") {
+		t.Errorf("Expected plain timestamps without a media URL, got:\n%s", output)
+	}
+}
+
+// TestMarkdownChapters tests that long single-speaker turns get a heading every few minutes
+func TestMarkdownChapters(t *testing.T) {
+	segments := make([]Segment, 20)
+	for i := range segments {
+		segments[i] = Segment{Start: float64(i * 60), End: float64(i*60 + 60), Text: "שיעור"}
+	}
+
+	chapters := markdownChapters(SpeakerTurns(segments))
+	if len(chapters) != 4 {
+		t.Fatalf("Expected 4 chapters, got %d", len(chapters))
+	}
+	if chapters[1].Start != 300 || chapters[1].End != 600 || len(chapters[1].Segments) != 5 {
+		t.Errorf("Unexpected second chapter: start %v, end %v, %d segments", chapters[1].Start, chapters[1].End, len(chapters[1].Segments))
+	}
+}
+
+// TestMarkdownTimestamp tests link formats for media files and YouTube
+func TestMarkdownTimestamp(t *testing.T) {
+	tests := []struct {
+		mediaURL string
+		expected string
+	}{
+		{"", "00:01:30"},
+		{"https://example.com/ep1.mp3", "[00:01:30](https://example.com/ep1.mp3#t=90)"},
+		{"https://example.com/ep1.mp3#t=10", "[00:01:30](https://example.com/ep1.mp3#t=90)"},
+		{"https://www.youtube.com/watch?v=abc", "[00:01:30](https://www.youtube.com/watch?v=abc&t=90)"},
+		{"https://youtu.be/abc", "[00:01:30](https://youtu.be/abc?t=90)"},
+		{"../audio/my talk.m4a", "[00:01:30](../audio/my%20talk.m4a#t=90)"},
+	}
+	for _, tt := range tests {
+		if result := markdownTimestamp(90.5, tt.mediaURL); result != tt.expected {
+			t.Errorf("markdownTimestamp(90.5, %q) = %q, expected %q", tt.mediaURL, result, tt.expected)
+		}
+	}
+}
+
+// TestMarkdownMediaURL tests linking to the input file relative to the output
+func TestMarkdownMediaURL(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "audio", "talk.m4a")
+	output := filepath.Join(dir, "site", "talk_transcription.md")
+
+	if url := markdownMediaURL("", input, output); url != "../audio/talk.m4a" {
+		t.Errorf("markdownMediaURL() = %q", url)
+	}
+	if url := markdownMediaURL("https://example.com/talk.mp3", input, output); url != "https://example.com/talk.mp3" {
+		t.Errorf("Expected -media-url to take precedence, got %q", url)
+	}
+}
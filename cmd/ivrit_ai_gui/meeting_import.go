@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Meeting apps whose recording layouts are recognized
+const (
+	MeetingAppZoom       = "Zoom"
+	MeetingAppGoogleMeet = "Google Meet"
+	MeetingAppMultiTrack = "Multi-track recorder" // A numbered file per participant, e.g. Craig for Discord
+)
+
+// zoomParticipantDir is the folder Zoom writes a track per participant to, when
+// "Record a separate audio file of each participant" is on
+const zoomParticipantDir = "Audio Record"
+
+var (
+	// Zoom's local recording: audio_only.m4a and zoom_0.mp4, or audio<id>.m4a and
+	// video<id>.mp4 in newer versions, both holding everyone's audio
+	zoomAudioPattern = regexp.MustCompile(`(?i)^(audio_only|audio\d+)\.m4a$`)
+	zoomVideoPattern = regexp.MustCompile(`(?i)^(zoom_\d+|video\d+)\.mp4$`)
+	// A participant's track: audio<Name><id>.m4a, e.g. audioDanaLevi21695381234.m4a
+	zoomTrackPattern = regexp.MustCompile(`(?i)^audio(.+?)\d+\.m4a$`)
+	// Zoom names the folder "<date> <time> <topic> <meeting ID>"
+	zoomFolderPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}\.\d{2}\.\d{2} (.+?)(?: \d{9,11})?$`)
+	// Google Meet saves one video, "<title> (<date> <time> <zone>)"
+	meetFilePattern = regexp.MustCompile(`^(.+) \(\d{4}-\d{2}-\d{2} \d{2}[:.\-]\d{2}[^)]*\)\.(mp4|webm)$`)
+	// Multi-track recorders number a file per participant: 1-dana.flac, 2-yossi_0.flac
+	numberedTrackPattern = regexp.MustCompile(`^\d+-(.+?)(?:_\d+)?\.(flac|ogg|m4a|aac|wav|mp3|opus)$`)
+)
+
+// ParticipantTrack is one participant's audio in a meeting recording
+type ParticipantTrack struct {
+	Path string
+	Name string // From the file name
+}
+
+// MeetingRecording is a meeting app's recording, found from its folder or one of its files
+type MeetingRecording struct {
+	App    string
+	Dir    string
+	Title  string             // Meeting topic, when the app records it in the names
+	Mixed  string             // Everyone's audio: the audio-only file when there is also a video
+	Video  string             // The video, with the same audio as Mixed, so not transcribed again
+	Tracks []ParticipantTrack // One per participant, when recorded separately
+}
+
+// DetectMeetingRecording recognizes the layout of Zoom's local recordings (the
+// audio-only file next to the video, and the "Audio Record" folder of participant
+// tracks) and Google Meet's recordings, given the folder or a file in it, and the
+// folders of multi-track recorders. ok is false for other files and folders.
+func DetectMeetingRecording(path string) (rec *MeetingRecording, ok bool) {
+	dir, file := path, ""
+	if info, err := os.Stat(path); err != nil {
+		return nil, false
+	} else if !info.IsDir() {
+		dir, file = filepath.Dir(path), filepath.Base(path)
+	}
+	if filepath.Base(dir) == zoomParticipantDir {
+		dir = filepath.Dir(dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false
+	}
+
+	rec = &MeetingRecording{Dir: dir}
+	var numbered []ParticipantTrack
+	for _, entry := range entries {
+		name := entry.Name()
+		entryPath := filepath.Join(dir, name)
+		switch {
+		case entry.IsDir() && name == zoomParticipantDir:
+			rec.Tracks = zoomParticipantTracks(entryPath)
+		case entry.IsDir():
+		case zoomAudioPattern.MatchString(name):
+			rec.Mixed = entryPath
+		case zoomVideoPattern.MatchString(name):
+			rec.Video = entryPath
+		case numberedTrackPattern.MatchString(name):
+			numbered = append(numbered, ParticipantTrack{Path: entryPath, Name: participantName(numberedTrackPattern.FindStringSubmatch(name)[1])})
+		}
+	}
+
+	switch {
+	case rec.Mixed != "" || rec.Video != "" || len(rec.Tracks) > 0:
+		rec.App = MeetingAppZoom
+		if m := zoomFolderPattern.FindStringSubmatch(filepath.Base(dir)); m != nil {
+			rec.Title = m[1]
+		}
+		if rec.Mixed == "" {
+			rec.Mixed, rec.Video = rec.Video, ""
+		}
+	case len(numbered) > 1 && file == "":
+		rec.App = MeetingAppMultiTrack
+		rec.Tracks = numbered
+	case file != "" && meetFilePattern.MatchString(file):
+		rec.App = MeetingAppGoogleMeet
+		rec.Title = meetFilePattern.FindStringSubmatch(file)[1]
+		rec.Mixed = path
+	default:
+		return nil, false
+	}
+	sort.Slice(rec.Tracks, func(i, j int) bool {
+		return naturalLess(filepath.Base(rec.Tracks[i].Path), filepath.Base(rec.Tracks[j].Path))
+	})
+	return rec, true
+}
+
+// zoomParticipantTracks lists the participant tracks in Zoom's "Audio Record" folder
+func zoomParticipantTracks(dir string) []ParticipantTrack {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var tracks []ParticipantTrack
+	for _, entry := range entries {
+		if m := zoomTrackPattern.FindStringSubmatch(entry.Name()); m != nil && !entry.IsDir() {
+			tracks = append(tracks, ParticipantTrack{Path: filepath.Join(dir, entry.Name()), Name: participantName(m[1])})
+		}
+	}
+	return tracks
+}
+
+// participantName turns the name in a track's file name into a speaker name:
+// "DanaLevi" and "dana_levi" become "Dana Levi"
+func participantName(name string) string {
+	var b strings.Builder
+	runes := []rune(strings.NewReplacer("_", " ", "-", " ", ".", " ").Replace(name))
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	words := strings.Fields(b.String())
+	for i, word := range words {
+		first := []rune(word)
+		first[0] = unicode.ToUpper(first[0])
+		words[i] = string(first)
+	}
+	return strings.Join(words, " ")
+}
+
+// TrackNames lists the participants' names
+func (m *MeetingRecording) TrackNames() []string {
+	names := make([]string, len(m.Tracks))
+	for i, track := range m.Tracks {
+		names[i] = track.Name
+	}
+	return names
+}
+
+// Describe summarizes what was found, e.g. "Zoom recording with 3 participant tracks (Dana, Yossi, Noa)"
+func (m *MeetingRecording) Describe() string {
+	description := m.App + " recording"
+	if m.Title != "" {
+		description += fmt.Sprintf(" %q", m.Title)
+	}
+	if len(m.Tracks) > 0 {
+		description += fmt.Sprintf(" with %d participant tracks (%s)", len(m.Tracks), strings.Join(m.TrackNames(), ", "))
+	}
+	return description
+}
+
+// OutputFileName names the transcript after the meeting's title, or else after its
+// mixed audio or first track
+func (m *MeetingRecording) OutputFileName(format string) string {
+	if m.Title != "" {
+		return m.Title + "_transcription." + formatExtension(format)
+	}
+	if m.Mixed != "" {
+		return autoOutputFileName(m.Mixed, format)
+	}
+	return autoOutputFileName(m.Tracks[0].Path, format)
+}
+
+// TranscribeTracks transcribes each participant's track on its own and merges the
+// results by time, with the participant's name as the speaker. The tracks of one
+// meeting all start when the recording does, so their timestamps line up.
+func TranscribeTracks(engine TranscriptionEngine, tracks []ParticipantTrack, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	perTrack := make([][]Segment, 0, len(tracks))
+	for i, track := range tracks {
+		speaker, name := i, track.Name
+		var trackProgress func(string)
+		if progressCallback != nil {
+			trackProgress = func(msg string) {
+				progressCallback(fmt.Sprintf("%s (%d/%d): %s", name, speaker+1, len(tracks), msg))
+			}
+		}
+		var trackSegment func(Segment)
+		if segmentCallback != nil {
+			trackSegment = func(seg Segment) {
+				seg.Speaker, seg.SpeakerName = speaker, name
+				segmentCallback(seg)
+			}
+		}
+
+		segments, err := engine.Transcribe(track.Path, modelID, cpuThreads, trackProgress, trackSegment)
+		if err != nil {
+			return nil, fmt.Errorf("%s's track: %v", name, err)
+		}
+		perTrack = append(perTrack, segments)
+	}
+
+	merged := mergeChannelSegments(perTrack)
+	for i := range merged {
+		merged[i].SpeakerName = tracks[merged[i].Speaker].Name
+	}
+	return merged, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestFiles creates empty files under dir
+func writeTestFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestDetectZoomRecording tests recognizing a Zoom recording folder from the folder and its files
+func TestDetectZoomRecording(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "2024-05-02 10.00.00 Weekly Sync 81234567890")
+	writeTestFiles(t, dir, "video1714640400.mp4", "audio1714640400.m4a", "playback.m3u",
+		"Audio Record/audioYossiCohen31714640400.m4a", "Audio Record/audioDanaLevi21714640400.m4a")
+
+	for _, path := range []string{dir, filepath.Join(dir, "video1714640400.mp4"), filepath.Join(dir, "Audio Record", "audioDanaLevi21714640400.m4a")} {
+		rec, ok := DetectMeetingRecording(path)
+		if !ok {
+			t.Fatalf("Expected a Zoom recording from %s", path)
+		}
+		if rec.App != MeetingAppZoom || rec.Title != "Weekly Sync" || filepath.Base(rec.Mixed) != "audio1714640400.m4a" || filepath.Base(rec.Video) != "video1714640400.mp4" {
+			t.Errorf("Unexpected recording: %+v", rec)
+		}
+		if names := rec.TrackNames(); !reflect.DeepEqual(names, []string{"Dana Levi", "Yossi Cohen"}) {
+			t.Errorf("Unexpected participants: %v", names)
+		}
+	}
+
+	rec, _ := DetectMeetingRecording(dir)
+	if rec.OutputFileName("srt") != "Weekly Sync_transcription.srt" {
+		t.Errorf("Unexpected output name: %s", rec.OutputFileName("srt"))
+	}
+	if !strings.Contains(rec.Describe(), `"Weekly Sync" with 2 participant tracks (Dana Levi, Yossi Cohen)`) {
+		t.Errorf("Unexpected description: %s", rec.Describe())
+	}
+}
+
+// TestDetectMeetingRecordingLayouts tests the other layouts, and files that aren't meetings
+func TestDetectMeetingRecordingLayouts(t *testing.T) {
+	root := t.TempDir()
+
+	meet := filepath.Join(root, "meet")
+	writeTestFiles(t, meet, "Design review (2024-05-02 10:00 GMT+3).mp4")
+	rec, ok := DetectMeetingRecording(filepath.Join(meet, "Design review (2024-05-02 10:00 GMT+3).mp4"))
+	if !ok || rec.App != MeetingAppGoogleMeet || rec.Title != "Design review" || len(rec.Tracks) != 0 {
+		t.Errorf("Unexpected Google Meet recording: %+v", rec)
+	}
+
+	craig := filepath.Join(root, "craig")
+	writeTestFiles(t, craig, "10-noa_0.flac", "2-yossi_cohen_0.flac", "info.txt")
+	rec, ok = DetectMeetingRecording(craig)
+	if !ok || rec.App != MeetingAppMultiTrack || !reflect.DeepEqual(rec.TrackNames(), []string{"Yossi Cohen", "Noa"}) {
+		t.Errorf("Unexpected multi-track recording: %+v", rec)
+	}
+	if _, ok := DetectMeetingRecording(filepath.Join(craig, "10-noa_0.flac")); ok {
+		t.Error("Expected a single numbered file to be left alone")
+	}
+
+	plain := filepath.Join(root, "plain")
+	writeTestFiles(t, plain, "interview.m4a")
+	if _, ok := DetectMeetingRecording(filepath.Join(plain, "interview.m4a")); ok {
+		t.Error("Expected an ordinary recording not to be a meeting")
+	}
+}
+
+// trackEngine returns canned segments for each track
+type trackEngine struct {
+	fakeEngine
+	byPath map[string][]Segment
+}
+
+func (e *trackEngine) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	for _, seg := range e.byPath[audioPath] {
+		if segmentCallback != nil {
+			segmentCallback(seg)
+		}
+	}
+	return e.byPath[audioPath], nil
+}
+
+// TestTranscribeTracks tests merging the participants' tracks with their names as speakers
+func TestTranscribeTracks(t *testing.T) {
+	tracks := []ParticipantTrack{{Path: "dana.m4a", Name: "Dana"}, {Path: "yossi.m4a", Name: "Yossi"}}
+	engine := &trackEngine{byPath: map[string][]Segment{
+		"dana.m4a":  {{Start: 0, End: 2, Text: "שלום"}, {Start: 6, End: 8, Text: "תודה"}},
+		"yossi.m4a": {{Start: 3, End: 5, Text: "היי"}},
+	}}
+	var streamed []Segment
+	segments, err := TranscribeTracks(engine, tracks, "turbo", 4, nil, func(seg Segment) { streamed = append(streamed, seg) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var labels []string
+	for _, seg := range segments {
+		labels = append(labels, seg.SpeakerLabel()+": "+seg.Text)
+	}
+	if !reflect.DeepEqual(labels, []string{"Dana: שלום", "Yossi: היי", "Dana: תודה"}) {
+		t.Errorf("Unexpected transcript: %v", labels)
+	}
+	if len(streamed) != 3 || streamed[2].SpeakerName != "Yossi" || streamed[2].Speaker != 1 {
+		t.Errorf("Expected streamed segments with their speakers, got %+v", streamed)
+	}
+	if text := FormatOutput(segments, "srt", DisplayBilingual); !strings.Contains(text, "[Yossi] היי") {
+		t.Errorf("Expected the names in the output:\n%s", text)
+	}
+}
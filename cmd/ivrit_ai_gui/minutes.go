@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// maxMinutesChunkChars is the most transcript text sent to the model at once;
+// longer meetings are summarized in parts and the parts merged
+const maxMinutesChunkChars = 6000
+
+// MeetingMinutes is the structured summary of a meeting
+type MeetingMinutes struct {
+	Attendees   []string     `json:"attendees"` // Guessed from the conversation; may be just speaker labels
+	Summary     string       `json:"summary"`
+	Decisions   []string     `json:"decisions"`
+	ActionItems []ActionItem `json:"actionItems"`
+}
+
+// ActionItem is a task agreed on in the meeting
+type ActionItem struct {
+	Task  string `json:"task"`
+	Owner string `json:"owner,omitempty"`
+	Due   string `json:"due,omitempty"`
+}
+
+// minutesChannelMode returns the channel mode a recording with the given channel
+// count is transcribed with for minutes, which need the attendees told apart: their
+// own tracks (participants), a channel each (split mode, turned on for recordings with
+// several channels), or speaker turns detected by whisper (speakerTurns). It returns
+// an error when the recording has none of these.
+func minutesChannelMode(channelMode string, channels int, participants, speakerTurns bool) (string, error) {
+	switch {
+	case participants || channelMode == ChannelModeSplit || speakerTurns:
+		return channelMode, nil
+	case channels > 1:
+		return ChannelModeSplit, nil
+	}
+	return "", fmt.Errorf("meeting minutes need the speakers told apart, but the recording has a single channel and speaker turns aren't detected with this engine or whisper.cpp library; use a recording with a channel per speaker, or a meeting recording with -participants")
+}
+
+// minutesSpeakersError reports a transcript the attendees can't be told apart in,
+// with everything said by one speaker
+func minutesSpeakersError(segments []Segment) error {
+	type speaker struct {
+		id   int
+		name string
+	}
+	speakers := map[speaker]bool{}
+	for _, seg := range segments {
+		if seg.Event == "" {
+			speakers[speaker{seg.Speaker, seg.SpeakerName}] = true
+		}
+	}
+	if len(speakers) > 1 {
+		return nil
+	}
+	return fmt.Errorf("meeting minutes need the speakers told apart, but the whole transcript is one speaker's; transcribe a recording with a channel per speaker with split channels, or a meeting's participant tracks")
+}
+
+// GenerateMinutes asks the model for attendees, a summary, decisions and action
+// items, written in the language with code lang
+func (t *MistralTranslator) GenerateMinutes(segments []Segment, lang string, progressCallback func(string)) (MeetingMinutes, error) {
+	chunks := minutesTranscriptChunks(segments)
+	if len(chunks) == 0 {
+		return MeetingMinutes{}, fmt.Errorf("transcript is empty")
+	}
+
+	parts := make([]MeetingMinutes, 0, len(chunks))
+	for i, chunk := range chunks {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Writing minutes (part %d/%d)...", i+1, len(chunks)))
+		}
+
+		reply, err := t.generate(minutesPrompt(chunk, lang, i, len(chunks)), true)
+		if err != nil {
+			return MeetingMinutes{}, err
+		}
+		part, err := parseMinutes(reply)
+		if err != nil {
+			return MeetingMinutes{}, fmt.Errorf("part %d: %v", i+1, err)
+		}
+		parts = append(parts, part)
+	}
+
+	minutes := mergeMinutes(parts)
+
+	// Turn the per-part summaries into one
+	if len(parts) > 1 {
+		if progressCallback != nil {
+			progressCallback("Writing summary...")
+		}
+		prompt := fmt.Sprintf(`These are summaries of consecutive parts of one meeting. Combine them into a single summary of at most one paragraph, written in %s. Only output the summary.
+
+%s`, languageName(lang), minutes.Summary)
+		summary, err := t.generate(prompt, false)
+		if err != nil {
+			return MeetingMinutes{}, err
+		}
+		minutes.Summary = summary
+	}
+
+	return minutes, nil
+}
+
+// minutesPrompt builds the prompt for one part of the transcript
+func minutesPrompt(transcript, lang string, part, parts int) string {
+	scope := "the meeting transcript below"
+	if parts > 1 {
+		scope = fmt.Sprintf("part %d of %d of the meeting transcript below", part+1, parts)
+	}
+	return fmt.Sprintf(`You are writing meeting minutes from %s. Speakers are labelled "Speaker N"; if the conversation reveals a speaker's name or role, use it as "Speaker N (name)".
+
+Reply with JSON only, in this shape, with all text written in %s:
+{"attendees": ["Speaker 1 (Dana)"], "summary": "one paragraph", "decisions": ["..."], "actionItems": [{"task": "...", "owner": "...", "due": "..."}]}
+
+Use empty lists when there are no decisions or action items. Leave owner or due empty when unknown. Do not invent anything that is not in the transcript.
+
+Transcript:
+%s`, scope, languageName(lang), transcript)
+}
+
+// minutesTranscriptChunks renders the transcript as "[HH:MM:SS] Speaker N: text" lines,
+// grouped into chunks of at most maxMinutesChunkChars (a longer turn is its own chunk)
+func minutesTranscriptChunks(segments []Segment) []string {
+	chunks := []string{}
+	current := ""
+	for _, turn := range SpeakerTurns(segments) {
+		text, _ := turn.Text()
+		if text == "" {
+			continue
+		}
+		line := fmt.Sprintf("[%s] %s: %s\n", FormatTimestamp(turn.Start, true)[:8], turn.Label(), text)
+		if current != "" && len([]rune(current))+len([]rune(line)) > maxMinutesChunkChars {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		current += line
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// parseMinutes decodes the model's JSON reply, ignoring any text around the object
+func parseMinutes(reply string) (MeetingMinutes, error) {
+	var minutes MeetingMinutes
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return minutes, fmt.Errorf("model reply is not JSON: %q", reply)
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &minutes); err != nil {
+		return minutes, fmt.Errorf("model reply is not valid minutes JSON: %v", err)
+	}
+	return minutes, nil
+}
+
+// mergeMinutes combines minutes of consecutive parts: lists are concatenated
+// (attendees without duplicates) and summaries joined in order
+func mergeMinutes(parts []MeetingMinutes) MeetingMinutes {
+	merged := MeetingMinutes{Attendees: []string{}, Decisions: []string{}, ActionItems: []ActionItem{}}
+	summaries := []string{}
+	for _, part := range parts {
+		for _, attendee := range part.Attendees {
+			if attendee = strings.TrimSpace(attendee); attendee != "" && !containsString(merged.Attendees, attendee) {
+				merged.Attendees = append(merged.Attendees, attendee)
+			}
+		}
+		merged.Decisions = append(merged.Decisions, part.Decisions...)
+		merged.ActionItems = append(merged.ActionItems, part.ActionItems...)
+		if summary := strings.TrimSpace(part.Summary); summary != "" {
+			summaries = append(summaries, summary)
+		}
+	}
+	merged.Summary = strings.Join(summaries, "\n\n")
+	return merged
+}
+
+// FormatMinutesMarkdown renders the minutes, followed by the transcript by speaker turn
+func FormatMinutesMarkdown(title string, minutes MeetingMinutes, segments []Segment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if len(segments) > 0 {
+		fmt.Fprintf(&b, "*Duration: %s*\n\n", FormatTimestamp(segments[len(segments)-1].End, true)[:8])
+	}
+
+	b.WriteString("## Attendees\n\n")
+	writeMarkdownList(&b, minutes.Attendees, "None identified")
+
+	b.WriteString("## Summary\n\n")
+	if minutes.Summary != "" {
+		b.WriteString(minutes.Summary + "\n\n")
+	}
+
+	b.WriteString("## Decisions\n\n")
+	writeMarkdownList(&b, minutes.Decisions, "None recorded")
+
+	b.WriteString("## Action Items\n\n")
+	if len(minutes.ActionItems) == 0 {
+		b.WriteString("None recorded\n\n")
+	}
+	for _, item := range minutes.ActionItems {
+		line := "- [ ] " + item.Task
+		if item.Owner != "" {
+			line += " — **" + item.Owner + "**"
+		}
+		if item.Due != "" {
+			line += " (due: " + item.Due + ")"
+		}
+		b.WriteString(line + "\n")
+	}
+	if len(minutes.ActionItems) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Transcript\n\n")
+	writeMarkdownTurns(&b, segments, "###", "")
+
+	return b.String()
+}
+
+// writeMarkdownList writes items as a bulleted list, or a placeholder when there are none
+func writeMarkdownList(b *strings.Builder, items []string, none string) {
+	if len(items) == 0 {
+		b.WriteString(none + "\n\n")
+		return
+	}
+	for _, item := range items {
+		b.WriteString("- " + item + "\n")
+	}
+	b.WriteString("\n")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGenerateMinutes tests requesting JSON minutes from the model
+func TestGenerateMinutes(t *testing.T) {
+	var request OllamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		reply := `{"attendees": ["Speaker 1 (Dana)", "Speaker 2"], "summary": "Budget review.", "decisions": ["Approve the budget"], "actionItems": [{"task": "Send the report", "owner": "Dana", "due": "Sunday"}]}`
+		json.NewEncoder(w).Encode(OllamaResponse{Response: reply, Done: true})
+	}))
+	defer server.Close()
+
+	translator := NewMistralTranslator()
+	translator.ollamaURL = server.URL
+
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום, אני דנה", Speaker: 0},
+		{Start: 2, End: 4, Text: "בואו נאשר את התקציב", Speaker: 1},
+	}
+	minutes, err := translator.GenerateMinutes(segments, "en", nil)
+	if err != nil {
+		t.Fatalf("GenerateMinutes() error: %v", err)
+	}
+	if request.Format != "json" || !strings.Contains(request.Prompt, "[00:00:02] Speaker 2: בואו נאשר את התקציב") {
+		t.Errorf("Unexpected request: format %q, prompt %q", request.Format, request.Prompt)
+	}
+	if len(minutes.Attendees) != 2 || minutes.Summary != "Budget review." || minutes.ActionItems[0].Owner != "Dana" {
+		t.Errorf("Unexpected minutes: %+v", minutes)
+	}
+
+	if _, err := translator.GenerateMinutes(nil, "en", nil); err == nil {
+		t.Error("Expected error for empty transcript")
+	}
+}
+
+// TestMinutesChunks tests that long meetings are split between speaker turns
+func TestMinutesChunks(t *testing.T) {
+	segments := make([]Segment, 40)
+	for i := range segments {
+		segments[i] = Segment{Start: float64(i), End: float64(i + 1), Text: strings.Repeat("א", 300), Speaker: i % 2}
+	}
+
+	chunks := minutesTranscriptChunks(segments)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+	lines := 0
+	for _, chunk := range chunks {
+		if len([]rune(chunk)) > maxMinutesChunkChars {
+			t.Errorf("Chunk of %d characters exceeds the limit", len([]rune(chunk)))
+		}
+		lines += strings.Count(chunk, "\n")
+	}
+	if lines != len(segments) {
+		t.Errorf("Expected %d turns across chunks, got %d", len(segments), lines)
+	}
+}
+
+// TestParseMinutes tests decoding replies with surrounding text and invalid replies
+func TestParseMinutes(t *testing.T) {
+	minutes, err := parseMinutes("Here are the minutes:\n```json\n{\"summary\": \"Short\", \"decisions\": [\"Yes\"]}\n```")
+	if err != nil || minutes.Summary != "Short" || len(minutes.Decisions) != 1 {
+		t.Errorf("parseMinutes() = %+v, %v", minutes, err)
+	}
+
+	for _, reply := range []string{"", "no minutes", "{not json}"} {
+		if _, err := parseMinutes(reply); err == nil {
+			t.Errorf("Expected error for reply %q", reply)
+		}
+	}
+}
+
+// TestMergeMinutes tests combining the minutes of several parts
+func TestMergeMinutes(t *testing.T) {
+	merged := mergeMinutes([]MeetingMinutes{
+		{Attendees: []string{"Speaker 1", "Speaker 2"}, Summary: "First.", Decisions: []string{"A"}},
+		{Attendees: []string{"Speaker 2 ", "Speaker 3"}, Summary: "Second.", ActionItems: []ActionItem{{Task: "B"}}},
+	})
+
+	if fmt.Sprint(merged.Attendees) != "[Speaker 1 Speaker 2 Speaker 3]" {
+		t.Errorf("Attendees = %v", merged.Attendees)
+	}
+	if merged.Summary != "First.\n\nSecond." || len(merged.Decisions) != 1 || len(merged.ActionItems) != 1 {
+		t.Errorf("Unexpected merge: %+v", merged)
+	}
+}
+
+// TestFormatMinutesMarkdown tests the Markdown sections
+func TestFormatMinutesMarkdown(t *testing.T) {
+	minutes := MeetingMinutes{
+		Attendees:   []string{"Speaker 1 (Dana)"},
+		Summary:     "Budget review.",
+		ActionItems: []ActionItem{{Task: "Send the report", Owner: "Dana", Due: "Sunday"}, {Task: "Book a room"}},
+	}
+	segments := []Segment{{Start: 0, End: 65, Original: "שלום", Translation: "Hello", Text: "Hello"}}
+
+	markdown := FormatMinutesMarkdown("Meeting - Minutes", minutes, segments)
+	expected := []string{
+		"# Meeting - Minutes\n\n*Duration: 00:01:05*",
+		"## Attendees\n\n- Speaker 1 (Dana)\n",
+		"## Decisions\n\nNone recorded\n",
+		"- [ ] Send the report — **Dana** (due: Sunday)\n- [ ] Book a room\n",
+		"### Speaker 1 (00:00:00)\n\n> שלום\n\nHello\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, markdown)
+		}
+	}
+}
+
+// TestMinutesSpeakers tests when minutes can tell the attendees apart
+func TestMinutesSpeakers(t *testing.T) {
+	tests := []struct {
+		name         string
+		channelMode  string
+		channels     int
+		participants bool
+		speakerTurns bool
+		expected     string // "" = refused
+	}{
+		{"Participant tracks", ChannelModeMix, 1, true, false, ChannelModeMix},
+		{"Split channels", ChannelModeSplit, 1, false, false, ChannelModeSplit},
+		{"Speaker turns", ChannelModeMix, 1, false, true, ChannelModeMix},
+		{"Channel per speaker", ChannelModeMix, 2, false, false, ChannelModeSplit},
+		{"No way to tell", ChannelModeMix, 1, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := minutesChannelMode(tt.channelMode, tt.channels, tt.participants, tt.speakerTurns)
+			if mode != tt.expected || (err != nil) != (tt.expected == "") {
+				t.Errorf("minutesChannelMode() = %q, %v, expected %q", mode, err, tt.expected)
+			}
+		})
+	}
+
+	oneSpeaker := []Segment{{Text: "שלום"}, {Text: "מוזיקה", Event: EventMusic, Speaker: 3}, {Text: "להתראות"}}
+	if err := minutesSpeakersError(oneSpeaker); err == nil {
+		t.Error("Expected a one-speaker transcript refused")
+	}
+	if err := minutesSpeakersError(append(oneSpeaker, Segment{Text: "היי", Speaker: 1})); err != nil {
+		t.Errorf("Expected two speakers accepted, got %v", err)
+	}
+}
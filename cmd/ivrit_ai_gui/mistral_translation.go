@@ -9,10 +9,16 @@ import (
 	"strings"
 )
 
+// maxTranslationChunkChars is the longest text sent to the model in one request;
+// longer text is translated a few sentences at a time
+const maxTranslationChunkChars = 1500
+
 // MistralTranslator handles translation using Mistral 8B via ollama
 type MistralTranslator struct {
 	ollamaURL string
 	model     string
+	glossary  *Glossary      // Terms translated as given (nil = none)
+	misses    []GlossaryMiss // Glossary terms the last TranslateSegments couldn't get used
 }
 
 // NewMistralTranslator creates a new Mistral translator
@@ -23,11 +29,23 @@ func NewMistralTranslator() *MistralTranslator {
 	}
 }
 
+// SetGlossary makes translations render the glossary's terms as given
+func (t *MistralTranslator) SetGlossary(glossary *Glossary) {
+	t.glossary = glossary
+}
+
+// GlossaryMisses lists the segments of the last TranslateSegments whose translation
+// still doesn't use a glossary term's translation
+func (t *MistralTranslator) GlossaryMisses() []GlossaryMiss {
+	return t.misses
+}
+
 // OllamaRequest represents the request to ollama API
 type OllamaRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
+	Format string `json:"format,omitempty"` // "json" constrains the reply to valid JSON
 }
 
 // OllamaResponse represents the response from ollama API
@@ -42,45 +60,76 @@ func (t *MistralTranslator) Translate(text string, targetLang string, progressCa
 		return "", nil
 	}
 
-	// Build prompt
-	langNames := map[string]string{
-		"en": "English",
-		"es": "Spanish",
-		"fr": "French",
-		"de": "German",
-		"ar": "Arabic",
-		"ru": "Russian",
-		"zh": "Chinese",
+	// Split long text at sentence boundaries so each request stays within the model's context
+	if chunks := ChunkSentences(text, maxTranslationChunkChars); len(chunks) > 1 {
+		translations := make([]string, 0, len(chunks))
+		for _, chunk := range chunks {
+			translation, err := t.Translate(chunk, targetLang, progressCallback)
+			if err != nil {
+				return "", err
+			}
+			translations = append(translations, translation)
+		}
+		return strings.Join(translations, " "), nil
 	}
 
-	langName := langNames[targetLang]
-	if langName == "" {
-		langName = targetLang
-	}
+	// Build prompt, with the glossary's translations of the terms in the text
+	langName := languageName(targetLang)
+	terms := t.glossary.Matches(text)
+	prompt := translationPrompt(text, langName, terms, nil)
 
-	prompt := fmt.Sprintf(`Translate the following Hebrew text to %s. Only output the translation, nothing else. Keep the formatting the same including timecodes.
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Translating to %s...", langName))
+	}
 
-Hebrew text: %s
+	translation, err := t.generate(prompt, false)
+	if err != nil {
+		return "", err
+	}
 
-%s translation:`, langName, text, langName)
+	// Models sometimes ignore the glossary; translate once more, insisting on the
+	// terms left out, and keep whichever translation uses more of them
+	if missing := t.glossary.Missing(text, translation); len(missing) > 0 {
+		retry, err := t.generate(translationPrompt(text, langName, terms, missing), false)
+		if err == nil && len(t.glossary.Missing(text, retry)) < len(missing) {
+			translation = retry
+		}
+	}
+	return translation, nil
+}
 
-	if progressCallback != nil {
-		progressCallback(fmt.Sprintf("Translating to %s...", langName))
+// translationPrompt builds the prompt translating Hebrew text to langName, with the
+// glossary terms that appear in it, insisting on those an earlier translation left out
+func translationPrompt(text, langName string, terms, insist []GlossaryTerm) string {
+	instructions := glossaryInstructions(terms)
+	if len(insist) > 0 {
+		instructions += "\nA previous translation got these wrong; use them word for word: " + glossaryList(insist)
 	}
+	return fmt.Sprintf(`Translate the following Hebrew text to %s. Only output the translation, nothing else. Keep the formatting the same including timecodes.%s
+
+Hebrew text: %s
 
+%s translation:`, langName, instructions, text, langName)
+}
+
+// generate sends a prompt to the model and returns its reply
+func (t *MistralTranslator) generate(prompt string, jsonOutput bool) (string, error) {
 	// Make request to ollama
 	reqBody := OllamaRequest{
 		Model:  t.model,
 		Prompt: prompt,
 		Stream: false,
 	}
+	if jsonOutput {
+		reqBody.Format = "json"
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	resp, err := http.Post(t.ollamaURL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := httpClient(0).Post(t.ollamaURL, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to ollama: %v (is ollama running?)", err)
 	}
@@ -101,28 +150,64 @@ Hebrew text: %s
 		return "", fmt.Errorf("failed to parse response: %v", err)
 	}
 
-	translation := strings.TrimSpace(ollamaResp.Response)
-	return translation, nil
+	return strings.TrimSpace(ollamaResp.Response), nil
+}
+
+// languageNames maps language codes to the names used in prompts
+var languageNames = map[string]string{
+	"he": "Hebrew",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"ar": "Arabic",
+	"ru": "Russian",
+	"zh": "Chinese",
+	"yi": "Yiddish",
+	"am": "Amharic",
+}
+
+// languageName returns the English name of a language code (or the code if unknown)
+func languageName(code string) string {
+	if name := languageNames[code]; name != "" {
+		return name
+	}
+	return code
 }
 
 // TranslateSegments translates multiple segments
 func (t *MistralTranslator) TranslateSegments(segments []Segment, targetLang string, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
 	translatedSegments := make([]Segment, len(segments))
+	t.misses = nil
 
 	for i, seg := range segments {
 		if progressCallback != nil {
 			progressCallback(fmt.Sprintf("Translating segment %d/%d...", i+1, len(segments)))
 		}
 
+		// Events such as [music] have no words to translate
+		if seg.Event != "" {
+			translatedSegments[i] = seg
+			if segmentCallback != nil {
+				segmentCallback(seg)
+			}
+			continue
+		}
+
 		translation, err := t.Translate(seg.Text, targetLang, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to translate segment %d: %v", i+1, err)
 		}
+		for _, term := range t.glossary.Missing(seg.Text, translation) {
+			t.misses = append(t.misses, GlossaryMiss{Start: seg.Start, Term: term})
+		}
 
 		translatedSeg := Segment{
 			Start:       seg.Start,
 			End:         seg.End,
 			Text:        translation,
+			Speaker:     seg.Speaker,
+			SpeakerName: seg.SpeakerName,
 			Original:    seg.Text, // Keep original Hebrew
 			Translation: translation,
 		}
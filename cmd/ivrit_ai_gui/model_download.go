@@ -5,17 +5,24 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // ModelInfo represents a HuggingFace model
 type ModelInfo struct {
-	ID            string `json:"id"`
-	File          string `json:"file"`
-	LocalFileName string `json:"localFileName,omitempty"`
-	Description   string `json:"description,omitempty"`
-	URL           string `json:"url,omitempty"`
+	ID              string `json:"id"`
+	File            string `json:"file"`
+	LocalFileName   string `json:"localFileName,omitempty"`
+	Description     string `json:"description,omitempty"`
+	URL             string `json:"url,omitempty"`
+	CoreMLID        string `json:"coreMLId,omitempty"`        // Repository of the zipped Core ML encoder (Apple Silicon)
+	CoreMLFile      string `json:"coreMLFile,omitempty"`      // e.g. ggml-base-encoder.mlmodelc.zip
+	FasterWhisperID string `json:"fasterWhisperId,omitempty"` // CTranslate2 model a faster-whisper engine loads instead
+	Path            string `json:"path,omitempty"`            // Local model file used where it is, instead of a download (custom models)
 }
 
 // ModelsConfig represents the models configuration file
@@ -23,17 +30,84 @@ type ModelsConfig struct {
 	Models map[string]ModelInfo `json:"models"`
 }
 
+// DownloadOptions configures model downloads for networks where huggingface.co is
+// slow or blocked
+type DownloadOptions struct {
+	Endpoint string `json:"endpoint,omitempty"` // HuggingFace mirror, e.g. https://hf-mirror.com (default: https://huggingface.co)
+	Token    string `json:"token,omitempty"`    // HuggingFace access token, for gated and private models
+	Proxy    string `json:"proxy,omitempty"`    // HTTP(S) or SOCKS5 proxy of downloads only (default: the network proxy)
+
+	// Bytes per second downloads may take, e.g. "2M", so a multi-GB model doesn't
+	// saturate the connection (default: no limit)
+	RateLimit string `json:"rateLimit,omitempty"`
+}
+
+const defaultHuggingFaceEndpoint = "https://huggingface.co"
+
+// Model download options from the config
+var (
+	downloadOptions DownloadOptions
+	downloadMutex   sync.RWMutex
+)
+
+// SetDownloadOptions sets the HuggingFace mirror, token and proxy of model downloads
+func SetDownloadOptions(options DownloadOptions) {
+	downloadMutex.Lock()
+	defer downloadMutex.Unlock()
+	downloadOptions = options
+}
+
+// currentDownloadOptions returns the model download options in effect
+func currentDownloadOptions() DownloadOptions {
+	downloadMutex.RLock()
+	defer downloadMutex.RUnlock()
+	return downloadOptions
+}
+
+// huggingFaceEndpoint returns the HuggingFace address models are downloaded from
+func huggingFaceEndpoint() string {
+	if endpoint := currentDownloadOptions().Endpoint; endpoint != "" {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return defaultHuggingFaceEndpoint
+}
+
+// downloadClient returns the HTTP client of model downloads, using the network
+// options, or the proxy set for downloads only (download.proxy in config.json)
+func downloadClient() (*http.Client, error) {
+	client := httpClient(0)
+	if proxy := currentDownloadOptions().Proxy; proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %v", proxy, err)
+		}
+		transport := currentTransport().Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		client.Transport = transport
+	}
+	return client, nil
+}
+
+// requestRest requests a download again from offset on, when its connection was
+// lost during a pause
+func requestRest(client *http.Client, req *http.Request, offset int64) (io.ReadCloser, error) {
+	rest := req.Clone(req.Context())
+	rest.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	resp, err := client.Do(rest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot continue the paused download: %v", err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cannot continue the paused download: the server answered with status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
 // loadModelsConfig loads model configuration from JSON file if it exists
 func loadModelsConfig() map[string]ModelInfo {
 	// Try to load from multiple locations
-	configPaths := []string{
-		"models.json",                           // Current directory
-		filepath.Join(".", "models.json"),       // Explicit current dir
-		filepath.Join("..", "models.json"),      // Parent directory
-		filepath.Join(os.Getenv("HOME"), ".config", "ivrit-ai", "models.json"), // User config
-	}
-
-	for _, configPath := range configPaths {
+	for _, configPath := range modelsConfigPaths() {
 		if data, err := os.ReadFile(configPath); err == nil {
 			var config ModelsConfig
 			if err := json.Unmarshal(data, &config); err == nil && len(config.Models) > 0 {
@@ -45,45 +119,62 @@ func loadModelsConfig() map[string]ModelInfo {
 	// Return default hardcoded models if config file not found
 	return map[string]ModelInfo{
 		"large-v3": {
-			ID:            "ivrit-ai/whisper-large-v3-ggml",
-			File:          "ggml-model.bin",
-			LocalFileName: "ggml-large-v3-ivrit.bin",
-			Description:   "Ivrit.ai Large v3 - Best quality for Hebrew",
+			ID:              "ivrit-ai/whisper-large-v3-ggml",
+			File:            "ggml-model.bin",
+			LocalFileName:   "ggml-large-v3-ivrit.bin",
+			Description:     "Ivrit.ai Large v3 - Best quality for Hebrew",
+			FasterWhisperID: "ivrit-ai/whisper-large-v3-ct2",
 		},
 		"turbo": {
-			ID:            "ivrit-ai/whisper-large-v3-turbo-ggml",
-			File:          "ggml-model.bin",
-			LocalFileName: "ggml-large-v3-turbo-ivrit.bin",
-			Description:   "Ivrit.ai Turbo - Faster with good quality",
+			ID:              "ivrit-ai/whisper-large-v3-turbo-ggml",
+			File:            "ggml-model.bin",
+			LocalFileName:   "ggml-large-v3-turbo-ivrit.bin",
+			Description:     "Ivrit.ai Turbo - Faster with good quality",
+			FasterWhisperID: "ivrit-ai/whisper-large-v3-turbo-ct2",
 		},
 		"base": {
-			ID:            "ggerganov/whisper.cpp",
-			File:          "ggml-base.bin",
-			LocalFileName: "ggml-base.bin",
-			Description:   "Base model - Fast but lower quality",
+			ID:              "ggerganov/whisper.cpp",
+			File:            "ggml-base.bin",
+			LocalFileName:   "ggml-base.bin",
+			Description:     "Base model - Fast but lower quality",
+			CoreMLID:        "ggerganov/whisper.cpp",
+			CoreMLFile:      "ggml-base-encoder.mlmodelc.zip",
+			FasterWhisperID: "Systran/faster-whisper-base",
 		},
 	}
 }
 
-// GetModelPath returns the path to a whisper model file, downloading if needed
-func GetModelPath(modelID string, progressCallback func(string, int)) (string, error) {
-	// Load model configuration from JSON file or use defaults
+// modelLocalFileName returns the file name a model is stored under locally
+func modelLocalFileName(modelInfo ModelInfo) string {
+	// Use configured local filename or fall back to original file name
+	if modelInfo.LocalFileName != "" {
+		return modelInfo.LocalFileName
+	}
+	return modelInfo.File
+}
+
+// FindLocalModel returns the path to an already-downloaded model without downloading it
+func FindLocalModel(modelID string) (string, error) {
 	modelMap := loadModelsConfig()
 
 	modelInfo, exists := modelMap[modelID]
-	if !exists || modelInfo.ID == "" {
+	if !exists || (modelInfo.ID == "" && modelInfo.Path == "") {
 		return "", fmt.Errorf("unsupported model: %s", modelID)
 	}
+	if modelInfo.Path != "" {
+		if _, err := os.Stat(modelInfo.Path); err != nil {
+			return "", fmt.Errorf("model file of %s not found: %s", modelID, modelInfo.Path)
+		}
+		return modelInfo.Path, nil
+	}
 
-	// Check common model locations
+	// Check the model folder, then common model locations
 	homeDir, _ := os.UserHomeDir()
-	// Use configured local filename or fall back to original file name
-	localFileName := modelInfo.LocalFileName
-	if localFileName == "" {
-		localFileName = modelInfo.File
-	}
+	localFileName := modelLocalFileName(modelInfo)
 
 	possiblePaths := []string{
+		filepath.Join(ModelDir(), localFileName),
+		filepath.Join(ModelDir(), modelID+".bin"),
 		filepath.Join(homeDir, ".cache", "whisper", localFileName),
 		filepath.Join(homeDir, ".cache", "whisper", modelID+".bin"),
 		filepath.Join(homeDir, ".local", "share", "whisper", localFileName),
@@ -92,23 +183,50 @@ func GetModelPath(modelID string, progressCallback func(string, int)) (string, e
 		filepath.Join(".", localFileName),
 	}
 
-	// Check for existing model
 	for _, path := range possiblePaths {
 		if _, err := os.Stat(path); err == nil {
-			if progressCallback != nil {
-				progressCallback(fmt.Sprintf("Found model at: %s", path), -1)
-			}
 			return path, nil
 		}
 	}
 
-	// Model not found - try to download
+	return "", fmt.Errorf("model %s not found locally", modelID)
+}
+
+// GetModelPath returns the path to a whisper model file, downloading if needed
+func GetModelPath(modelID string, progressCallback func(string, int)) (string, error) {
+	// Load model configuration from JSON file or use defaults
+	modelMap := loadModelsConfig()
+
+	modelInfo, exists := modelMap[modelID]
+	if !exists || (modelInfo.ID == "" && modelInfo.Path == "") {
+		return "", fmt.Errorf("unsupported model: %s", modelID)
+	}
+
+	localFileName := modelLocalFileName(modelInfo)
+
+	// Check for existing model
+	if path, err := FindLocalModel(modelID); err == nil {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Found model at: %s", path), -1)
+		}
+		// A damaged file would only fail in whisper.cpp, without saying why
+		if err := checkModelFile(modelID, path); err != nil {
+			return "", err
+		}
+		prepareCoreML(modelID, modelInfo, path, progressCallback)
+		return path, nil
+	}
+
+	// Model not found - try to download, unless it is a local file
+	if modelInfo.Path != "" {
+		return "", fmt.Errorf("model file of %s not found: %s", modelID, modelInfo.Path)
+	}
 	if progressCallback != nil {
 		progressCallback(fmt.Sprintf("Downloading %s from ivrit.ai...", modelID), 0)
 	}
 
 	// Download from HuggingFace
-	cacheDir := filepath.Join(homeDir, ".cache", "whisper")
+	cacheDir := ModelDir()
 	os.MkdirAll(cacheDir, 0755)
 	modelPath := filepath.Join(cacheDir, localFileName)
 
@@ -126,85 +244,59 @@ func GetModelPath(modelID string, progressCallback func(string, int)) (string, e
 			return "", fmt.Errorf(
 				"failed to download model: %v\n"+
 					"Please manually download from:\n"+
-					"  https://huggingface.co/%s\n"+
+					"  %s/%s\n"+
 					"Then place %s in: %s",
-				err, modelInfo.ID, localFileName, cacheDir)
+				err, huggingFaceEndpoint(), modelInfo.ID, localFileName, cacheDir)
 		}
 	}
 
+	if err := checkModelFile(modelID, modelPath); err != nil {
+		return "", err
+	}
 	if progressCallback != nil {
 		progressCallback("Model downloaded successfully", 100)
 	}
+	prepareCoreML(modelID, modelInfo, modelPath, progressCallback)
 
 	return modelPath, nil
 }
 
-// downloadModelFromHuggingFace downloads a model from HuggingFace
+// downloadModelFromHuggingFace downloads a model from HuggingFace, or the configured
+// mirror, with the HuggingFace token when one is set
 func downloadModelFromHuggingFace(repoID, fileName, destPath string, progressCallback func(string, int)) error {
 	// HuggingFace API endpoint
-	url := fmt.Sprintf("https://huggingface.co/%s/resolve/main/%s", repoID, fileName)
+	url := fmt.Sprintf("%s/%s/resolve/main/%s", huggingFaceEndpoint(), repoID, fileName)
 
 	// Create request
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return err
 	}
+	token := currentDownloadOptions().Token
+	if token != "" {
+		// Go only forwards it on redirects within the endpoint's domain
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
 	// Make request
-	client := &http.Client{}
+	client, err := downloadClient()
+	if err != nil {
+		return err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && token == "" {
+		return fmt.Errorf("download failed with status: %d (the model may need a HuggingFace token: set HF_TOKEN)", resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
 	}
 
-	// Get content length
-	contentLength := resp.ContentLength
-	if contentLength == 0 {
-		contentLength = -1 // Unknown size
-	}
-
-	// Create destination file
-	out, err := os.Create(destPath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	// Download with progress
-	buffer := make([]byte, 32*1024) // 32KB chunks
-	var downloaded int64
-
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			written, writeErr := out.Write(buffer[:n])
-			if writeErr != nil {
-				return writeErr
-			}
-			downloaded += int64(written)
-
-			// Update progress
-			if progressCallback != nil && contentLength > 0 {
-				percent := int((downloaded * 100) / contentLength)
-				mbDownloaded := float64(downloaded) / (1024 * 1024)
-				mbTotal := float64(contentLength) / (1024 * 1024)
-				progressCallback(fmt.Sprintf("Downloading: %.1fMB / %.1fMB", mbDownloaded, mbTotal), percent)
-			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return saveDownload(client, req, resp, destPath, progressCallback)
 }
 
 // downloadModelDirect downloads from ggml.ggerganov.com (direct download)
@@ -226,7 +318,10 @@ func downloadModelDirect(fileName, destPath string, progressCallback func(string
 		return err
 	}
 
-	client := &http.Client{}
+	client, err := downloadClient()
+	if err != nil {
+		return err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -237,44 +332,94 @@ func downloadModelDirect(fileName, destPath string, progressCallback func(string
 		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
 	}
 
+	return saveDownload(client, req, resp, destPath, progressCallback)
+}
+
+// saveDownload writes a download to destPath with progress, no faster than the
+// download rate limit and holding while downloads are paused. It is written to a
+// temporary file first and only renamed into place once all of it has arrived, so
+// an interrupted download never leaves a truncated model where one is looked for.
+func saveDownload(client *http.Client, req *http.Request, resp *http.Response, destPath string, progressCallback func(string, int)) error {
+	// Get content length
 	contentLength := resp.ContentLength
 	if contentLength == 0 {
-		contentLength = -1
+		contentLength = -1 // Unknown size
 	}
 
-	out, err := os.Create(destPath)
+	// Stop before starting rather than when the disk fills up
+	dir, hint := filepath.Dir(destPath), downloadSpaceHint
+	if dir == ModelDir() {
+		hint = modelSpaceHint
+	}
+	if err := CheckDiskSpace(dir, contentLength, "the download of "+filepath.Base(destPath), hint); err != nil {
+		return err
+	}
+
+	// Create the temporary file
+	partialPath := destPath + ".download"
+	out, err := os.Create(partialPath)
 	if err != nil {
 		return err
 	}
+	defer os.Remove(partialPath) // Left over only on failure
 	defer out.Close()
 
-	buffer := make([]byte, 32*1024)
+	rate, _ := ParseRateLimit(currentDownloadOptions().RateLimit) // Checked by Validate
+	limit := ""
+	if rate > 0 {
+		limit = " (limited to " + FormatRate(rate) + ")"
+	}
+	throttle := newDownloadThrottle(rate)
+	defer throttle.done()
+
+	// Download with progress
+	buffer := make([]byte, 32*1024) // 32KB chunks
 	var downloaded int64
+	body := resp.Body
+	reconnect := false // After a pause, the server may have closed the connection
 
 	for {
-		n, err := resp.Body.Read(buffer)
+		if throttle.wait() {
+			reconnect = true
+		}
+		n, err := body.Read(buffer)
 		if n > 0 {
 			written, writeErr := out.Write(buffer[:n])
 			if writeErr != nil {
 				return writeErr
 			}
 			downloaded += int64(written)
+			throttle.received(n)
 
+			// Update progress
 			if progressCallback != nil && contentLength > 0 {
 				percent := int((downloaded * 100) / contentLength)
 				mbDownloaded := float64(downloaded) / (1024 * 1024)
 				mbTotal := float64(contentLength) / (1024 * 1024)
-				progressCallback(fmt.Sprintf("Downloading: %.1fMB / %.1fMB", mbDownloaded, mbTotal), percent)
+				progressCallback(fmt.Sprintf("Downloading: %.1fMB / %.1fMB%s", mbDownloaded, mbTotal, limit), percent)
 			}
 		}
 		if err == io.EOF {
 			break
 		}
+		if err != nil && reconnect {
+			reconnect = false
+			if body, err = requestRest(client, req, downloaded); err != nil {
+				return err
+			}
+			defer body.Close()
+			continue
+		}
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if contentLength > 0 && downloaded != contentLength {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", downloaded, contentLength)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, destPath)
 }
-
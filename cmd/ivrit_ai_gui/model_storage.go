@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Folder models are downloaded to, from -model-dir or the GUI setting
+var (
+	modelDir      string
+	modelDirMutex sync.RWMutex
+)
+
+// SetModelDir sets the folder models are downloaded to ("" = the default)
+func SetModelDir(dir string) {
+	modelDirMutex.Lock()
+	defer modelDirMutex.Unlock()
+	modelDir = dir
+}
+
+// ModelDir returns the folder models are downloaded to
+func ModelDir() string {
+	modelDirMutex.RLock()
+	defer modelDirMutex.RUnlock()
+	if modelDir != "" {
+		return modelDir
+	}
+	return defaultModelDir()
+}
+
+// defaultModelDir returns whisper.cpp's usual model cache, ~/.cache/whisper
+func defaultModelDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cache", "whisper")
+}
+
+// ModelStorageDir returns the folder models are stored in: the configured one
+// (config.json, IVRIT_MODEL_DIR or -model-dir), or else the one chosen in the GUI
+func (c AppConfig) ModelStorageDir(settings Settings) string {
+	if c.ModelDir != "" {
+		return c.ModelDir
+	}
+	return settings.ModelDir
+}
+
+// isModelStorageEntry reports whether a file or folder in the model folder belongs
+// to the models: model files, their checksums and Core ML encoders
+func isModelStorageEntry(name string, isDir bool) bool {
+	if isDir {
+		return name == "coreml" || strings.HasSuffix(name, ".mlmodelc")
+	}
+	return strings.HasSuffix(name, ".bin") || strings.HasSuffix(name, ".bin.sha256")
+}
+
+// MoveModels moves the downloaded models, with their checksums and Core ML
+// encoders, from one folder to another, returning the number of entries moved.
+// Models already in the destination are left where they are. Links to Core ML
+// encoders are removed rather than moved; they are linked again when the model
+// is next loaded.
+func MoveModels(from, to string, progressCallback func(string)) (int, error) {
+	absFrom, err1 := filepath.Abs(from)
+	absTo, err2 := filepath.Abs(to)
+	if err1 != nil || err2 != nil || absFrom == absTo {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(from)
+	if os.IsNotExist(err) {
+		return 0, nil // Nothing downloaded yet
+	} else if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return 0, fmt.Errorf("cannot create the model folder: %v", err)
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		source := filepath.Join(from, entry.Name())
+		if entry.Type()&os.ModeSymlink != 0 {
+			if strings.HasSuffix(entry.Name(), ".mlmodelc") {
+				os.Remove(source)
+			}
+			continue
+		}
+		if !isModelStorageEntry(entry.Name(), entry.IsDir()) {
+			continue
+		}
+
+		dest := filepath.Join(to, entry.Name())
+		if _, err := os.Lstat(dest); err == nil {
+			if progressCallback != nil {
+				progressCallback(fmt.Sprintf("Skipped %s: already in %s", entry.Name(), to))
+			}
+			continue
+		}
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Moving %s...", entry.Name()))
+		}
+		if err := moveFile(source, dest); err != nil {
+			return moved, fmt.Errorf("failed to move %s: %v", entry.Name(), err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// moveFile moves a file or folder, copying it when it goes to another drive
+func moveFile(source, dest string) error {
+	if err := os.Rename(source, dest); err == nil {
+		return nil
+	}
+	// Renaming fails across drives: copy to a temporary name, then remove the original
+	partial := dest + ".partial"
+	if err := copyTree(source, partial); err != nil {
+		os.RemoveAll(partial)
+		return err
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		os.RemoveAll(partial)
+		return err
+	}
+	return os.RemoveAll(source)
+}
+
+// copyTree copies a file, or a folder with its contents
+func copyTree(source, dest string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if err := os.MkdirAll(dest, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyTree(filepath.Join(source, entry.Name()), filepath.Join(dest, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
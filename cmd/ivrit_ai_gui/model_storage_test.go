@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMoveModels tests moving the downloaded models to another folder
+func TestMoveModels(t *testing.T) {
+	from := t.TempDir()
+	to := filepath.Join(t.TempDir(), "models")
+	os.WriteFile(filepath.Join(from, "ggml-base.bin"), []byte("weights"), 0644)
+	os.WriteFile(filepath.Join(from, "ggml-base.bin.sha256"), []byte("0123\n"), 0644)
+	os.WriteFile(filepath.Join(from, "notes.txt"), []byte("not a model"), 0644)
+	os.MkdirAll(filepath.Join(from, "coreml", "ggml-base-encoder.mlmodelc"), 0755)
+	os.WriteFile(filepath.Join(from, "coreml", "ggml-base-encoder.mlmodelc", "model.mil"), []byte("encoder"), 0644)
+	os.Symlink(filepath.Join(from, "coreml", "ggml-base-encoder.mlmodelc"), filepath.Join(from, "ggml-base-encoder.mlmodelc"))
+
+	// A model already in the destination stays where it is
+	os.MkdirAll(to, 0755)
+	os.WriteFile(filepath.Join(from, "ggml-turbo.bin"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(to, "ggml-turbo.bin"), []byte("new"), 0644)
+
+	var messages []string
+	moved, err := MoveModels(from, to, func(msg string) { messages = append(messages, msg) })
+	if err != nil {
+		t.Fatalf("MoveModels() error: %v", err)
+	}
+	if moved != 3 {
+		t.Errorf("Expected the model, its checksum and the Core ML folder moved, got %d (%v)", moved, messages)
+	}
+	if data, _ := os.ReadFile(filepath.Join(to, "coreml", "ggml-base-encoder.mlmodelc", "model.mil")); string(data) != "encoder" {
+		t.Errorf("Expected the Core ML encoder moved, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(to, "ggml-turbo.bin")); string(data) != "new" {
+		t.Errorf("Expected the model in the destination kept, got %q", data)
+	}
+	for _, name := range []string{"ggml-base.bin", "ggml-base.bin.sha256", "coreml", "ggml-base-encoder.mlmodelc"} {
+		if _, err := os.Lstat(filepath.Join(from, name)); err == nil {
+			t.Errorf("Expected %s gone from the old folder", name)
+		}
+	}
+	for _, name := range []string{"notes.txt", "ggml-turbo.bin"} {
+		if _, err := os.Stat(filepath.Join(from, name)); err != nil {
+			t.Errorf("Expected %s left in the old folder", name)
+		}
+	}
+
+	if moved, err := MoveModels(filepath.Join(from, "missing"), to, nil); err != nil || moved != 0 {
+		t.Errorf("Expected nothing to move from a missing folder, got %d, %v", moved, err)
+	}
+}
+
+// TestCopyTree tests the copy used when models move to another drive
+func TestCopyTree(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "encoder.mlmodelc")
+	os.MkdirAll(filepath.Join(source, "weights"), 0755)
+	os.WriteFile(filepath.Join(source, "weights", "weight.bin"), []byte("w"), 0644)
+
+	dest := filepath.Join(t.TempDir(), "encoder.mlmodelc")
+	if err := copyTree(source, dest); err != nil {
+		t.Fatalf("copyTree() error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dest, "weights", "weight.bin")); string(data) != "w" {
+		t.Errorf("Unexpected copy: %q", data)
+	}
+}
+
+// TestModelDir tests where models are stored and found
+func TestModelDir(t *testing.T) {
+	defer SetModelDir("")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg := DefaultConfig()
+	settings := defaultSettings()
+	settings.ModelDir = filepath.Join(home, "external")
+	if dir := cfg.ModelStorageDir(settings); dir != settings.ModelDir {
+		t.Errorf("Expected the GUI folder, got %q", dir)
+	}
+	cfg.ModelDir = filepath.Join(home, "configured")
+	if dir := cfg.ModelStorageDir(settings); dir != cfg.ModelDir {
+		t.Errorf("Expected the configured folder to win, got %q", dir)
+	}
+
+	SetModelDir("")
+	if ModelDir() != filepath.Join(home, ".cache", "whisper") {
+		t.Errorf("Expected the default folder, got %q", ModelDir())
+	}
+
+	SetModelDir(cfg.ModelDir)
+	modelPath := filepath.Join(cfg.ModelDir, "ggml-base.bin")
+	os.MkdirAll(cfg.ModelDir, 0755)
+	os.WriteFile(modelPath, []byte("weights"), 0644)
+	if path, err := FindLocalModel("base"); err != nil || path != modelPath {
+		t.Errorf("Expected the model found in the model folder, got %q, %v", path, err)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ModelUpdate is the result of comparing a downloaded model with the one published on HuggingFace
+type ModelUpdate struct {
+	ModelID      string
+	Path         string // The downloaded model
+	LocalSHA256  string
+	RemoteSHA256 string // SHA-256 of the published file ("" if HuggingFace didn't report one)
+	RemoteSize   int64  // Size of the published file (-1 if unknown)
+}
+
+// Available reports whether the published model differs from the downloaded one
+func (u ModelUpdate) Available() bool {
+	return u.RemoteSHA256 != "" && u.RemoteSHA256 != u.LocalSHA256
+}
+
+// remoteModelSHA256 asks HuggingFace (or the configured mirror) for the SHA-256 and
+// size of a published model file. Model files are stored with Git LFS, whose ETag is
+// the SHA-256 of the file; redirects aren't followed, since the CDN they lead to
+// doesn't report it.
+func remoteModelSHA256(repoID, fileName string) (string, int64, error) {
+	url := fmt.Sprintf("%s/%s/resolve/main/%s", huggingFaceEndpoint(), repoID, fileName)
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return "", -1, err
+	}
+	if token := currentDownloadOptions().Token; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client, err := downloadClient()
+	if err != nil {
+		return "", -1, err
+	}
+	client.Timeout = 30 * time.Second
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", -1, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", -1, fmt.Errorf("update check failed with status: %d", resp.StatusCode)
+	}
+
+	etag := resp.Header.Get("X-Linked-Etag")
+	if etag == "" {
+		etag = resp.Header.Get("ETag")
+	}
+	etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+	if len(etag) != sha256.Size*2 {
+		etag = "" // Not an LFS file: the ETag isn't a checksum of the contents
+	}
+
+	size := int64(-1)
+	if linked := resp.Header.Get("X-Linked-Size"); linked != "" {
+		size, _ = strconv.ParseInt(linked, 10, 64)
+	}
+	return strings.ToLower(etag), size, nil
+}
+
+// CheckModelUpdate compares a downloaded model with the one published on HuggingFace.
+// The first check of a model hashes it, which takes a few seconds per gigabyte.
+func CheckModelUpdate(modelID string) (ModelUpdate, error) {
+	modelInfo, exists := loadModelsConfig()[modelID]
+	if !exists || modelInfo.ID == "" {
+		return ModelUpdate{}, fmt.Errorf("unsupported model: %s", modelID)
+	}
+	path, err := FindLocalModel(modelID)
+	if err != nil {
+		return ModelUpdate{}, err
+	}
+
+	update := ModelUpdate{ModelID: modelID, Path: path}
+	if update.RemoteSHA256, update.RemoteSize, err = remoteModelSHA256(modelInfo.ID, modelInfo.File); err != nil {
+		return update, err
+	}
+	if update.RemoteSHA256 == "" {
+		return update, nil
+	}
+	update.LocalSHA256, err = modelHash(path)
+	return update, err
+}
+
+// CheckModelUpdates checks every downloaded model for updates, returning the models
+// with one. Models that can't be checked are reported through the callback.
+func CheckModelUpdates(progressCallback func(string)) []ModelUpdate {
+	models := loadModelsConfig()
+	modelIDs := make([]string, 0, len(models))
+	for modelID := range models {
+		modelIDs = append(modelIDs, modelID)
+	}
+	sort.Strings(modelIDs)
+
+	var updates []ModelUpdate
+	for _, modelID := range modelIDs {
+		if _, err := FindLocalModel(modelID); err != nil || models[modelID].ID == "" {
+			continue // Not downloaded, or a local model file
+		}
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Checking the %s model for updates...", modelID))
+		}
+		update, err := CheckModelUpdate(modelID)
+		if err != nil {
+			if progressCallback != nil {
+				progressCallback(fmt.Sprintf("Could not check the %s model for updates: %v", modelID, err))
+			}
+			continue
+		}
+		if update.Available() {
+			updates = append(updates, update)
+		}
+	}
+	return updates
+}
+
+// UpdateModel downloads the published version of a downloaded model. The new file is
+// downloaded next to the old one and verified against the published checksum before
+// it replaces it, so a failed or corrupted download leaves the old model in place.
+func UpdateModel(modelID string, progressCallback func(string, int)) error {
+	update, err := CheckModelUpdate(modelID)
+	if err != nil {
+		return err
+	}
+	if !update.Available() {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("The %s model is up to date", modelID), 100)
+		}
+		return nil
+	}
+	modelInfo := loadModelsConfig()[modelID]
+
+	newPath := update.Path + ".update"
+	defer os.Remove(newPath)
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Downloading the updated %s model...", modelID), 0)
+	}
+	if err := downloadModelFromHuggingFace(modelInfo.ID, modelInfo.File, newPath, progressCallback); err != nil {
+		return fmt.Errorf("failed to download the updated model: %v", err)
+	}
+
+	if progressCallback != nil {
+		progressCallback("Verifying the downloaded model...", -1)
+	}
+	sum, err := fileSHA256(newPath)
+	if err != nil {
+		return err
+	}
+	if sum != update.RemoteSHA256 {
+		return fmt.Errorf("the downloaded model doesn't match the published checksum; the current model was kept")
+	}
+
+	// Swap the files, keeping the old one until the new one is in place
+	oldPath := update.Path + ".previous"
+	if err := os.Rename(update.Path, oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, update.Path); err != nil {
+		os.Rename(oldPath, update.Path)
+		return err
+	}
+	os.Remove(oldPath)
+	os.WriteFile(update.Path+".sha256", []byte(sum+"\n"), 0644) // The hash cache of modelHash
+
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Updated the %s model", modelID), 100)
+	}
+	return nil
+}
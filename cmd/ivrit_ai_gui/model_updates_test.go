@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// modelUpdateServer publishes the base model with the given contents, reporting the
+// checksum HuggingFace reports for LFS files (or a wrong one, to test verification)
+func modelUpdateServer(t *testing.T, published string, reportedSHA256 string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ggerganov/whisper.cpp/resolve/main/ggml-base.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"0123abcd"`)
+		w.Header().Set("X-Linked-Etag", `"`+reportedSHA256+`"`)
+		if r.Method == "HEAD" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		io.WriteString(w, published)
+	}))
+}
+
+// testDownloadedModel places a downloaded base model in a temporary home directory
+func testDownloadedModel(t *testing.T, contents string) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".cache", "whisper", "ggml-base.bin")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestCheckModelUpdate tests comparing a downloaded model with the published one
+func TestCheckModelUpdate(t *testing.T) {
+	path := testDownloadedModel(t, "old weights")
+	server := modelUpdateServer(t, "new weights", testSHA256("new weights"))
+	defer server.Close()
+	defer SetDownloadOptions(DownloadOptions{})
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+
+	update, err := CheckModelUpdate("base")
+	if err != nil {
+		t.Fatalf("CheckModelUpdate() error: %v", err)
+	}
+	if !update.Available() || update.Path != path || update.LocalSHA256 != testSHA256("old weights") {
+		t.Errorf("Expected an update of %s, got %+v", path, update)
+	}
+	if _, err := os.Stat(path + ".sha256"); err != nil {
+		t.Errorf("Expected the checksum cached: %v", err)
+	}
+	if updates := CheckModelUpdates(nil); len(updates) != 1 || updates[0].ModelID != "base" {
+		t.Errorf("Expected the base model listed, got %+v", updates)
+	}
+
+	if _, err := CheckModelUpdate("turbo"); err == nil {
+		t.Error("Expected an error for a model that isn't downloaded")
+	}
+}
+
+// TestUpdateModel tests that a model is replaced only by a verified download
+func TestUpdateModel(t *testing.T) {
+	path := testDownloadedModel(t, "old weights")
+	defer SetDownloadOptions(DownloadOptions{})
+
+	corrupt := modelUpdateServer(t, "truncated", testSHA256("new weights"))
+	defer corrupt.Close()
+	SetDownloadOptions(DownloadOptions{Endpoint: corrupt.URL})
+	if err := UpdateModel("base", nil); err == nil {
+		t.Error("Expected an error for a download that doesn't match the checksum")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old weights" {
+		t.Errorf("Expected the old model kept, got %q", data)
+	}
+
+	server := modelUpdateServer(t, "new weights", testSHA256("new weights"))
+	defer server.Close()
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+	if err := UpdateModel("base", nil); err != nil {
+		t.Fatalf("UpdateModel() error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new weights" {
+		t.Errorf("Expected the new model, got %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 2 {
+		t.Errorf("Expected only the model and its checksum left, got %v", entries)
+	}
+	if update, err := CheckModelUpdate("base"); err != nil || update.Available() {
+		t.Errorf("Expected the model up to date, got %+v, %v", update, err)
+	}
+}
+
+// TestModelUpdateCheckDue tests the weekly update check schedule
+func TestModelUpdateCheckDue(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	settings := defaultSettings()
+	if !settings.ModelUpdateCheckDue(now) {
+		t.Error("Expected a check due when none was made")
+	}
+	settings.LastModelUpdateCheck = now.Add(-24 * time.Hour)
+	if settings.ModelUpdateCheckDue(now) {
+		t.Error("Expected no check due a day after the last one")
+	}
+	settings.LastModelUpdateCheck = now.Add(-8 * 24 * time.Hour)
+	if !settings.ModelUpdateCheckDue(now) {
+		t.Error("Expected a check due a week after the last one")
+	}
+	settings.CheckModelUpdates = false
+	if settings.ModelUpdateCheckDue(now) {
+		t.Error("Expected no check when checks are off")
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ggmlMagic starts whisper.cpp's GGML model files ("lmgg" on disk)
+const ggmlMagic = 0x67676d6c
+
+// ggmlTypeSizes holds the bytes per block and elements per block of the ggml tensor
+// types whisper models are stored in
+var ggmlTypeSizes = map[int32][2]int64{
+	0:  {4, 1},     // F32
+	1:  {2, 1},     // F16
+	2:  {18, 32},   // Q4_0
+	3:  {20, 32},   // Q4_1
+	6:  {22, 32},   // Q5_0
+	7:  {24, 32},   // Q5_1
+	8:  {34, 32},   // Q8_0
+	10: {84, 256},  // Q2_K
+	11: {110, 256}, // Q3_K
+	12: {144, 256}, // Q4_K
+	13: {176, 256}, // Q5_K
+	14: {210, 256}, // Q6_K
+	30: {2, 1},     // BF16
+}
+
+// CorruptModelError reports a model file whisper.cpp can't load, typically the
+// remains of an interrupted download
+type CorruptModelError struct {
+	ModelID string
+	Path    string
+	Reason  string
+}
+
+func (e *CorruptModelError) Error() string {
+	return fmt.Sprintf("the %s model file %s is damaged (%s)", e.ModelID, e.Path, e.Reason)
+}
+
+// Redownloadable reports whether the model can be downloaded again, rather than
+// being a custom model file on this computer
+func (e *CorruptModelError) Redownloadable() bool {
+	return loadModelsConfig()[e.ModelID].ID != ""
+}
+
+// ggmlReader reads the little-endian values of a model file's headers, keeping the
+// first error
+type ggmlReader struct {
+	file   *os.File
+	offset int64
+	size   int64
+	err    error
+}
+
+func (r *ggmlReader) int32() int32 {
+	var buf [4]byte
+	if r.err == nil {
+		_, r.err = r.file.ReadAt(buf[:], r.offset)
+	}
+	r.offset += 4
+	return int32(binary.LittleEndian.Uint32(buf[:]))
+}
+
+// skip moves past n bytes, failing if the file ends before them
+func (r *ggmlReader) skip(n int64) {
+	r.offset += n
+	if r.err == nil && r.offset > r.size {
+		r.err = io.ErrUnexpectedEOF
+	}
+}
+
+// ValidateModelFile checks that a file is a complete whisper.cpp GGML model: it
+// must start with the GGML magic and sensible hyperparameters, and every tensor
+// the model's layers need must be there in full. Only the headers are read, so
+// it takes a fraction of a second even for the large models.
+func ValidateModelFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := &ggmlReader{file: file, size: info.Size()}
+	if magic := uint32(r.int32()); r.err != nil || magic != ggmlMagic {
+		if r.err == nil && string(binary.LittleEndian.AppendUint32(nil, magic)) == "GGUF" {
+			return fmt.Errorf("a GGUF file, not a whisper.cpp GGML model")
+		}
+		return fmt.Errorf("not a GGML model file")
+	}
+
+	// n_vocab, n_audio_ctx, n_audio_state, n_audio_head, n_audio_layer, n_text_ctx,
+	// n_text_state, n_text_head, n_text_layer, n_mels, ftype
+	var hparams [11]int32
+	for i := range hparams {
+		hparams[i] = r.int32()
+	}
+	nVocab, audioLayers, textLayers, nMels := hparams[0], hparams[4], hparams[8], hparams[9]
+	if r.err != nil {
+		return fmt.Errorf("the file ends in its header")
+	}
+	if nVocab <= 0 || nVocab > 1000000 || audioLayers <= 0 || audioLayers > 128 || textLayers <= 0 || textLayers > 128 || (nMels != 80 && nMels != 128) {
+		return fmt.Errorf("invalid model header")
+	}
+
+	// Mel filters, then the vocabulary
+	filterMels, filterFFT := r.int32(), r.int32()
+	if filterMels < 0 || filterFFT < 0 {
+		return fmt.Errorf("invalid mel filters")
+	}
+	r.skip(int64(filterMels) * int64(filterFFT) * 4)
+	words := r.int32()
+	for i := int32(0); i < words && r.err == nil; i++ {
+		length := r.int32()
+		if length < 0 || length > 1<<16 {
+			return fmt.Errorf("invalid vocabulary")
+		}
+		r.skip(int64(length))
+	}
+	if r.err != nil {
+		return fmt.Errorf("the file ends in its vocabulary")
+	}
+
+	// The tensors, each a header followed by its data, up to the end of the file
+	expected := 7 + 15*int(audioLayers) + 4 + 24*int(textLayers)
+	tensors := 0
+	for r.offset < r.size {
+		dims, nameLength, tensorType := r.int32(), r.int32(), r.int32()
+		if r.err != nil {
+			return fmt.Errorf("the file ends in tensor %d of %d", tensors+1, expected)
+		}
+		if dims < 1 || dims > 4 || nameLength < 1 || nameLength > 1024 {
+			return fmt.Errorf("invalid header of tensor %d", tensors+1)
+		}
+		elements := int64(1)
+		for i := int32(0); i < dims; i++ {
+			elements *= int64(r.int32())
+		}
+		r.skip(int64(nameLength))
+		size, ok := ggmlTypeSizes[tensorType]
+		if !ok {
+			return nil // A tensor type this check doesn't know: leave the rest to whisper.cpp
+		}
+		if elements <= 0 || elements%size[1] != 0 {
+			return fmt.Errorf("invalid header of tensor %d", tensors+1)
+		}
+		r.skip(elements / size[1] * size[0])
+		if r.err != nil {
+			return fmt.Errorf("incomplete file: it ends in tensor %d of %d (%d bytes missing)", tensors+1, expected, r.offset-r.size)
+		}
+		tensors++
+	}
+	if tensors < expected {
+		return fmt.Errorf("incomplete file: %d of %d tensors", tensors, expected)
+	}
+	return nil
+}
+
+// checkModelFile validates a model before it is loaded, returning a CorruptModelError for a damaged one
+func checkModelFile(modelID, path string) error {
+	if err := ValidateModelFile(path); err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return err
+		}
+		return &CorruptModelError{ModelID: modelID, Path: path, Reason: err.Error()}
+	}
+	return nil
+}
+
+// RedownloadModel downloads a model again in place of a damaged file. The damaged
+// file is only replaced once the new download is complete.
+func RedownloadModel(modelID string, progressCallback func(string, int)) error {
+	modelInfo, exists := loadModelsConfig()[modelID]
+	if !exists || modelInfo.ID == "" {
+		return fmt.Errorf("the %s model can't be downloaded: replace its file by hand", modelID)
+	}
+	path, err := FindLocalModel(modelID)
+	if err != nil {
+		_, err = GetModelPath(modelID, progressCallback) // Not there any more: a normal download
+		return err
+	}
+
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Downloading %s again...", modelID), 0)
+	}
+	if err := downloadModelFromHuggingFace(modelInfo.ID, modelInfo.File, path, progressCallback); err != nil {
+		return fmt.Errorf("failed to download the model again: %v", err)
+	}
+	os.Remove(path + ".sha256") // The hash of the damaged file
+	return checkModelFile(modelID, path)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testGGMLModel builds a small whisper.cpp model file with one encoder and one
+// decoder layer: the header, mel filters, a vocabulary and the 50 tensors of
+// such a model, each a single F32 value
+func testGGMLModel(tensorType int32) []byte {
+	var buf bytes.Buffer
+	write := func(values ...int32) {
+		for _, v := range values {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+	}
+	write(ggmlMagic)
+	write(3, 1500, 8, 1, 1, 448, 8, 1, 1, 80, 0) // hparams
+	write(2, 2, 0, 0, 0, 0)                      // 2x2 mel filters
+	write(3)                                     // vocabulary
+	for _, word := range []string{"a", "bc", "def"} {
+		write(int32(len(word)))
+		buf.WriteString(word)
+	}
+	for i := 0; i < 50; i++ {
+		write(1, 1, tensorType, 1) // dims, name length, type, shape
+		buf.WriteString("t")
+		write(0) // data
+	}
+	return buf.Bytes()
+}
+
+func writeTestModel(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "ggml-test.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestValidateModelFile tests detecting damaged model files
+func TestValidateModelFile(t *testing.T) {
+	model := testGGMLModel(0)
+	if err := ValidateModelFile(writeTestModel(t, model)); err != nil {
+		t.Fatalf("Expected a complete model valid, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		contains string
+	}{
+		{"Empty", nil, "not a GGML model"},
+		{"HTML error page", []byte("<!DOCTYPE html><html>Not found</html>"), "not a GGML model"},
+		{"GGUF", append([]byte("GGUF"), model[4:]...), "GGUF"},
+		{"Truncated header", model[:20], "header"},
+		{"Truncated vocabulary", model[:80], "vocabulary"},
+		{"Truncated tensor data", model[:len(model)-2], "incomplete"},
+		{"Missing tensors", model[:len(model)-21], "49 of 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateModelFile(writeTestModel(t, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Expected an error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+
+	// Tensor types the check doesn't know are left to whisper.cpp
+	if err := ValidateModelFile(writeTestModel(t, testGGMLModel(99))); err != nil {
+		t.Errorf("Expected an unknown tensor type accepted, got %v", err)
+	}
+}
+
+// TestCheckModelFile tests the error returned for damaged and missing model files
+func TestCheckModelFile(t *testing.T) {
+	err := checkModelFile("base", writeTestModel(t, []byte("lmgg")))
+	var damaged *CorruptModelError
+	if !errors.As(err, &damaged) || damaged.ModelID != "base" {
+		t.Errorf("Expected a CorruptModelError, got %v", err)
+	}
+
+	err = checkModelFile("base", filepath.Join(t.TempDir(), "missing.bin"))
+	if err == nil || errors.As(err, &damaged) {
+		t.Errorf("Expected a missing file not reported as damaged, got %v", err)
+	}
+}
+
+// TestRedownloadModel tests replacing a damaged model with a new download
+func TestRedownloadModel(t *testing.T) {
+	model := string(testGGMLModel(0))
+	path := testDownloadedModel(t, model[:len(model)/2])
+	os.WriteFile(path+".sha256", []byte("0123\n"), 0644)
+	server := modelUpdateServer(t, model, testSHA256(model))
+	defer server.Close()
+	defer SetDownloadOptions(DownloadOptions{})
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+
+	var damaged *CorruptModelError
+	if _, err := GetModelPath("base", nil); !errors.As(err, &damaged) {
+		t.Fatalf("Expected the half-downloaded model reported as damaged, got %v", err)
+	}
+	if !damaged.Redownloadable() {
+		t.Error("Expected the base model downloadable again")
+	}
+
+	if err := RedownloadModel("base", nil); err != nil {
+		t.Fatalf("RedownloadModel() error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != model {
+		t.Error("Expected the model replaced with the new download")
+	}
+	if _, err := os.Stat(path + ".sha256"); err == nil {
+		t.Error("Expected the checksum of the damaged file removed")
+	}
+}
+
+// TestSaveDownloadIncomplete tests that an interrupted download leaves no file behind
+func TestSaveDownloadIncomplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1000")
+		w.Write([]byte("only part of it"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "ggml-base.bin")
+	defer SetDownloadOptions(DownloadOptions{})
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL})
+	if err := downloadModelFromHuggingFace("org/repo", "ggml-base.bin", dest, nil); err == nil {
+		t.Fatal("Expected an error for an interrupted download")
+	}
+	for _, path := range []string{dest, dest + ".download"} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("Expected no %s after an interrupted download", filepath.Base(path))
+		}
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -239,3 +243,53 @@ func TestJSONMarshal(t *testing.T) {
 		t.Errorf("Expected 1 model after round-trip, got %d", len(decoded.Models))
 	}
 }
+
+// TestDownloadFromMirror tests downloading from a HuggingFace mirror with a token
+func TestDownloadFromMirror(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/model/resolve/main/model.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer hf_secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, "weights")
+	}))
+	defer server.Close()
+	defer SetDownloadOptions(DownloadOptions{})
+
+	dest := filepath.Join(t.TempDir(), "model.bin")
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL + "/"})
+	if err := downloadModelFromHuggingFace("org/model", "model.bin", dest, nil); err == nil || !strings.Contains(err.Error(), "HF_TOKEN") {
+		t.Errorf("Expected a hint to set a token, got %v", err)
+	}
+
+	SetDownloadOptions(DownloadOptions{Endpoint: server.URL, Token: "hf_secret"})
+	if err := downloadModelFromHuggingFace("org/model", "model.bin", dest, nil); err != nil {
+		t.Fatalf("downloadModelFromHuggingFace() error: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "weights" {
+		t.Errorf("Unexpected download: %q", data)
+	}
+}
+
+// TestDownloadThroughProxy tests that downloads go through the configured proxy
+func TestDownloadThroughProxy(t *testing.T) {
+	var requested string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		io.WriteString(w, "weights")
+	}))
+	defer proxy.Close()
+	defer SetDownloadOptions(DownloadOptions{})
+
+	SetDownloadOptions(DownloadOptions{Endpoint: "http://models.example", Proxy: proxy.URL})
+	if err := downloadModelFromHuggingFace("org/model", "model.bin", filepath.Join(t.TempDir(), "model.bin"), nil); err != nil {
+		t.Fatalf("downloadModelFromHuggingFace() error: %v", err)
+	}
+	if requested != "http://models.example/org/model/resolve/main/model.bin" {
+		t.Errorf("Expected the mirror's URL requested from the proxy, got %q", requested)
+	}
+}
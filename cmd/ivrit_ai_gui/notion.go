@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// notionAPI is the Notion API base URL (a variable so tests can point it at a local server)
+var notionAPI = "https://api.notion.com/v1"
+
+// Notion API limits
+const (
+	notionVersion       = "2022-06-28"
+	notionMaxBlocks     = 100  // Children per request
+	notionMaxTextLength = 2000 // Characters per rich text object
+)
+
+// notionIDPattern matches a page ID, with or without dashes, at the end of an ID or page URL
+var notionIDPattern = regexp.MustCompile(`([0-9a-fA-F]{8})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{12})(?:[?#].*)?$`)
+
+// notionPageID extracts the page ID from a Notion page URL or ID
+func notionPageID(value string) (string, error) {
+	match := notionIDPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", fmt.Errorf("no Notion page ID found in %q", value)
+	}
+	return strings.ToLower(strings.Join(match[1:], "-")), nil
+}
+
+// ExportToNotion creates a Notion page under the configured parent with a heading per
+// speaker turn and returns its URL. Long transcripts are appended in batches.
+func ExportToNotion(token *NotionToken, title string, segments []Segment) (string, error) {
+	headers := map[string]string{"Notion-Version": notionVersion}
+	blocks := notionBlocks(segments)
+	first := blocks[:min(len(blocks), notionMaxBlocks)]
+
+	var page struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	err := doJSON("POST", notionAPI+"/pages", token.Token, headers, map[string]any{
+		"parent":     map[string]string{"page_id": token.ParentPageID},
+		"properties": map[string]any{"title": map[string]any{"title": notionRichText(title)}},
+		"children":   first,
+	}, &page)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Notion page: %v", err)
+	}
+
+	for start := len(first); start < len(blocks); start += notionMaxBlocks {
+		batch := blocks[start:min(start+notionMaxBlocks, len(blocks))]
+		err := doJSON("PATCH", notionAPI+"/blocks/"+page.ID+"/children", token.Token, headers, map[string]any{"children": batch}, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to add transcript to Notion page: %v", err)
+		}
+	}
+
+	return page.URL, nil
+}
+
+// notionBlocks converts the transcript into a heading and paragraphs per speaker turn
+func notionBlocks(segments []Segment) []map[string]any {
+	blocks := []map[string]any{}
+	for _, turn := range SpeakerTurns(segments) {
+		text, translation := turn.Text()
+		blocks = append(blocks, notionBlock("heading_3", turn.Heading()), notionBlock("paragraph", text))
+		if translation != "" {
+			blocks = append(blocks, notionBlock("paragraph", translation))
+		}
+	}
+	return blocks
+}
+
+// notionBlock creates a text block of the given type
+func notionBlock(blockType, text string) map[string]any {
+	return map[string]any{
+		"object":  "block",
+		"type":    blockType,
+		blockType: map[string]any{"rich_text": notionRichText(text)},
+	}
+}
+
+// notionRichText splits text into rich text objects within Notion's length limit
+func notionRichText(text string) []map[string]any {
+	parts := []map[string]any{}
+	runes := []rune(text)
+	for start := 0; start < len(runes) || start == 0; start += notionMaxTextLength {
+		end := min(start+notionMaxTextLength, len(runes))
+		parts = append(parts, map[string]any{
+			"type": "text",
+			"text": map[string]string{"content": string(runes[start:end])},
+		})
+	}
+	return parts
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Number styles: how numbers in the Hebrew are written. Like the display mode, the
+// style is applied to what is shown and saved, and the transcript keeps the words as
+// transcribed, so switching back restores them.
+const (
+	NumbersTranscribed = "transcribed" // As transcribed (the default)
+	NumbersDigits      = "digits"      // Spelled-out numbers above ten as digits ("עשרים ושלוש" → "23")
+	NumbersWords       = "words"       // Numbers written in digits spelled out ("23" → "עשרים ושלוש")
+)
+
+var validNumberStyles = []string{NumbersTranscribed, NumbersDigits, NumbersWords}
+
+// NumberOptions selects the normalization of numbers, dates and times in the Hebrew
+type NumberOptions struct {
+	Style string `json:"style,omitempty"` // NumbersTranscribed, NumbersDigits or NumbersWords ("" = as transcribed)
+	Dates bool   `json:"dates,omitempty"` // Dates as DD/MM/YYYY and times as H:MM
+}
+
+// hebrewNumberKind is the role of a number word in a compound number
+type hebrewNumberKind int
+
+const (
+	numberUnit      hebrewNumberKind = iota // 0-10 ("עשר" after a unit makes a teen)
+	numberTen                               // 20-90
+	numberHundred                           // מאה, מאתיים
+	numberHundreds                          // מאות, after a unit (300-900)
+	numberConstruct                         // שלושת...עשרת, before אלפים
+	numberThousands                         // אלפים, after a construct form
+	numberThousand                          // אלף, אלפיים
+	numberMillion                           // מיליון
+)
+
+type hebrewNumberWord struct {
+	value int
+	kind  hebrewNumberKind
+}
+
+// numberWordValues holds the spellings of number words, in both genders and with
+// and without vowel letters. "שנים" (also "years") is left out.
+var numberWordValues = map[string]hebrewNumberWord{}
+
+func init() {
+	add := func(kind hebrewNumberKind, value int, spellings ...string) {
+		for _, s := range spellings {
+			numberWordValues[s] = hebrewNumberWord{value, kind}
+		}
+	}
+	add(numberUnit, 0, "אפס")
+	add(numberUnit, 1, "אחד", "אחת")
+	add(numberUnit, 2, "שניים", "שני", "שתיים", "שתים", "שתי")
+	add(numberUnit, 3, "שלושה", "שלשה", "שלוש", "שלש")
+	add(numberUnit, 4, "ארבעה", "ארבע")
+	add(numberUnit, 5, "חמישה", "חמשה", "חמש")
+	add(numberUnit, 6, "שישה", "ששה", "שש")
+	add(numberUnit, 7, "שבעה", "שבע")
+	add(numberUnit, 8, "שמונה")
+	add(numberUnit, 9, "תשעה", "תשע")
+	add(numberUnit, 10, "עשרה", "עשר")
+	add(numberTen, 20, "עשרים")
+	add(numberTen, 30, "שלושים", "שלשים")
+	add(numberTen, 40, "ארבעים")
+	add(numberTen, 50, "חמישים", "חמשים")
+	add(numberTen, 60, "שישים", "ששים")
+	add(numberTen, 70, "שבעים")
+	add(numberTen, 80, "שמונים")
+	add(numberTen, 90, "תשעים")
+	add(numberHundred, 100, "מאה")
+	add(numberHundred, 200, "מאתיים", "מאתים")
+	add(numberHundreds, 100, "מאות")
+	add(numberConstruct, 3, "שלושת", "שלשת")
+	add(numberConstruct, 4, "ארבעת")
+	add(numberConstruct, 5, "חמשת", "חמישת")
+	add(numberConstruct, 6, "ששת", "שישת")
+	add(numberConstruct, 7, "שבעת")
+	add(numberConstruct, 8, "שמונת")
+	add(numberConstruct, 9, "תשעת")
+	add(numberConstruct, 10, "עשרת")
+	add(numberThousands, 1000, "אלפים")
+	add(numberThousand, 1000, "אלף")
+	add(numberThousand, 2000, "אלפיים")
+	add(numberMillion, 1000000, "מיליון", "מליון")
+}
+
+// numberPrefixes are the one-letter prepositions written attached to a number;
+// before digits they take a hyphen ("בשלושים" → "ב-30"). Unlike the redaction
+// prefixes, the article ה is left out, since "המאה" is usually "the century".
+const numberPrefixes = "בלמשכו"
+
+// NormalizeNumbers returns the segments with numbers, dates and times in their Hebrew
+// text (the original of a translation) written as the options select. The segments
+// passed in are left unchanged.
+func NormalizeNumbers(segments []Segment, options NumberOptions) []Segment {
+	if options.Style != NumbersDigits && options.Style != NumbersWords && !options.Dates {
+		return segments
+	}
+	normalized := make([]Segment, len(segments))
+	for i, seg := range segments {
+		if seg.Original != "" {
+			seg.Original = normalizeNumberText(seg.Original, options)
+		} else {
+			seg.Text = normalizeNumberText(seg.Text, options)
+		}
+		normalized[i] = seg
+	}
+	return normalized
+}
+
+// normalizeNumberText normalizes the numbers of one text
+func normalizeNumberText(text string, options NumberOptions) string {
+	text = numberWordsToDigits(text, options)
+	if options.Dates {
+		text = normalizeDates(text)
+	}
+	if options.Style == NumbersWords {
+		text = digitsToNumberWords(text)
+	}
+	return text
+}
+
+// numberWordsToDigits writes spelled-out numbers in digits: with the digits style
+// those above ten (one to ten stay words, as Hebrew style guides advise and since
+// "אחד" and "שני" also mean "someone" and "second"), and with dates normalized the
+// numbers of dates and times
+func numberWordsToDigits(text string, options NumberOptions) string {
+	words := strings.Split(text, " ")
+	var out []string
+	for i := 0; i < len(words); {
+		value, n, prefix := parseHebrewNumber(words[i:])
+		next := ""
+		if i+n < len(words) {
+			next = words[i+n]
+		}
+		convert := n > 0 && options.Style == NumbersDigits && value > 10
+		if n > 0 && options.Dates && !convert && i > 0 {
+			convert = isHourWord(words[i-1]) || isMonthWord(words[i-1]) || (words[i-1] == "רבע" && prefix == "ל")
+		}
+		if n > 0 && options.Dates && isMonthWord(next) {
+			convert = true
+		}
+		if value == 1000 && n == 1 && strings.HasPrefix(next, "בית") {
+			convert = false // "אלף בית" is the alphabet
+		}
+		if !convert {
+			out = append(out, words[i])
+			i++
+			continue
+		}
+
+		number := strconv.Itoa(value)
+		if prefix != "" {
+			number = prefix + "-" + number
+		}
+		lead, _, _ := splitWordPunctuation(words[i])
+		_, _, trail := splitWordPunctuation(words[i+n-1])
+		out = append(out, lead+number+trail)
+		i += n
+	}
+	return strings.Join(out, " ")
+}
+
+// parseHebrewNumber reads the number spelled out at the start of words, returning
+// its value, the number of words it takes and any prefix letters on its first word
+// (n = 0 if the words don't start with a number)
+func parseHebrewNumber(words []string) (value, n int, prefix string) {
+	total, group := 0, 0 // Thousands and millions so far; the part below a thousand
+	var last hebrewNumberWord
+	for ; n < len(words); n++ {
+		lead, word, trail := splitWordPunctuation(words[n])
+		if n == 0 {
+			prefix, word = splitHebrewPrefix(word)
+		} else if lead != "" {
+			break
+		} else if _, ok := numberWordValues[word]; !ok {
+			word = strings.TrimPrefix(word, "ו") // "ו" (and) joins the parts of a number
+		}
+		w, ok := numberWordValues[word]
+		if word == "שנים" && len(words) > n+1 && words[n+1] == "עשר" {
+			w, ok = hebrewNumberWord{2, numberUnit}, true // "שנים עשר" (12) but not "שנים" (years)
+		}
+		if !ok {
+			break
+		}
+
+		// What a word can follow
+		switch {
+		case n > 0 && last.kind == numberUnit && group%100 < 10 && w.kind == numberUnit && w.value == 10:
+			group += 10 // A teen: "שלוש עשרה"
+			w.kind = numberTen
+		case n > 0 && last.kind == numberUnit && group >= 3 && group <= 9 && w.kind == numberHundreds:
+			group *= 100
+		case n > 0 && last.kind == numberConstruct && w.kind == numberThousands:
+			total += last.value * 1000
+		case n > 0 && group > 0 && w.kind == numberThousand && w.value == 1000:
+			total += group * 1000 // "עשרים אלף"
+			group = 0
+		case w.kind == numberMillion:
+			total += max(group, 1) * 1000000
+			group = 0
+		case w.kind == numberThousand && group == 0 && total%1000000 == 0:
+			total += w.value
+		case w.kind == numberHundred && group == 0:
+			group = w.value
+		case w.kind == numberTen && group%100 == 0:
+			group += w.value
+		case w.kind == numberUnit && group%10 == 0 && (n == 0 || (last.kind != numberUnit && w.value > 0)):
+			group += w.value
+		case w.kind == numberConstruct && n == 0:
+			// Only a number before אלפים
+		default:
+			return finishHebrewNumber(total+group, n, prefix, last)
+		}
+		last = w
+		if trail != "" || w.value == 0 {
+			n++
+			break // Punctuation ends the number, and nothing follows zero
+		}
+	}
+	return finishHebrewNumber(total+group, n, prefix, last)
+}
+
+// finishHebrewNumber returns a parsed number, without a last construct form that
+// isn't followed by אלפים
+func finishHebrewNumber(value, n int, prefix string, last hebrewNumberWord) (int, int, string) {
+	if n > 0 && last.kind == numberConstruct {
+		n--
+	}
+	if n == 0 {
+		return 0, 0, ""
+	}
+	return value, n, prefix
+}
+
+// splitWordPunctuation splits the quotes and brackets before a word and the
+// punctuation after it from the word
+func splitWordPunctuation(word string) (lead, core, trail string) {
+	core = strings.TrimLeft(word, "\"'([")
+	lead = word[:len(word)-len(core)]
+	trimmed := strings.TrimRight(core, ".,?!:;\"')]")
+	return lead, trimmed, core[len(trimmed):]
+}
+
+// splitHebrewPrefix splits up to three prefix letters from a number word ("בשלושים"
+// → "ב", "שלושים"); a number word itself has no prefix
+func splitHebrewPrefix(word string) (string, string) {
+	if _, ok := numberWordValues[word]; ok {
+		return "", word
+	}
+	for i, r := range word {
+		if i >= 3*len("ב") || !strings.ContainsRune(numberPrefixes, r) {
+			break
+		}
+		end := i + len(string(r))
+		if _, ok := numberWordValues[word[end:]]; ok {
+			return word[:end], word[end:]
+		}
+	}
+	return "", word
+}
+
+// hebrewMonths are the month names, by number
+var hebrewMonths = map[string]int{
+	"ינואר": 1, "פברואר": 2, "מרץ": 3, "מרס": 3, "אפריל": 4, "מאי": 5, "יוני": 6,
+	"יולי": 7, "אוגוסט": 8, "ספטמבר": 9, "אוקטובר": 10, "נובמבר": 11, "דצמבר": 12,
+}
+
+// isMonthWord reports whether a word is a month name with the preposition of a date ("במרץ")
+func isMonthWord(word string) bool {
+	_, core, _ := splitWordPunctuation(word)
+	for _, p := range []string{"ב", "ל"} {
+		if _, ok := hebrewMonths[strings.TrimPrefix(core, p)]; ok && strings.HasPrefix(core, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHourWord reports whether a word is "hour" ("בשעה", "עד השעה"), before a time
+func isHourWord(word string) bool {
+	return strings.HasSuffix(word, "שעה")
+}
+
+var (
+	numericDatePattern = regexp.MustCompile(`\b(\d{1,2})[./-](\d{1,2})[./-](\d{4}|\d{2})\b`)
+	spokenDatePattern  = regexp.MustCompile(`\b(\d{1,2}) [בל](ינואר|פברואר|מרץ|מרס|אפריל|מאי|יוני|יולי|אוגוסט|ספטמבר|אוקטובר|נובמבר|דצמבר)(?: (\d{4})\b)?`)
+	clockTimePattern   = regexp.MustCompile(`\b(\d{1,2}):(\d{2})\b`)
+	hourPattern        = regexp.MustCompile(`(שעה) (\d{1,2})( וחצי| ורבע)?(?:[^\d:]|$)`)
+	quarterToPattern   = regexp.MustCompile(`(שעה) רבע ל-(\d{1,2})\b`)
+)
+
+// normalizeDates writes dates as DD/MM/YYYY (DD/MM without a year) and times as
+// H:MM. Times need an hour word before them ("בשעה שלוש וחצי" → "בשעה 3:30"), so
+// other numbers with "and a half" aren't mistaken for times.
+func normalizeDates(text string) string {
+	text = numericDatePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := numericDatePattern.FindStringSubmatch(match)
+		day, _ := strconv.Atoi(parts[1])
+		month, _ := strconv.Atoi(parts[2])
+		year, _ := strconv.Atoi(parts[3])
+		if day < 1 || day > 31 || month < 1 || month > 12 {
+			return match
+		}
+		if len(parts[3]) == 2 {
+			year += 2000
+			if year >= 2070 {
+				year -= 100
+			}
+		}
+		return fmt.Sprintf("%02d/%02d/%d", day, month, year)
+	})
+	text = spokenDatePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := spokenDatePattern.FindStringSubmatch(match)
+		day, _ := strconv.Atoi(parts[1])
+		if day < 1 || day > 31 {
+			return match
+		}
+		date := fmt.Sprintf("%02d/%02d", day, hebrewMonths[parts[2]])
+		if parts[3] != "" {
+			date += "/" + parts[3]
+		}
+		return date
+	})
+
+	text = clockTimePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := clockTimePattern.FindStringSubmatch(match)
+		hour, _ := strconv.Atoi(parts[1])
+		minute, _ := strconv.Atoi(parts[2])
+		if hour > 24 || minute > 59 {
+			return match
+		}
+		return fmt.Sprintf("%d:%02d", hour, minute)
+	})
+	text = quarterToPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := quarterToPattern.FindStringSubmatch(match)
+		hour, _ := strconv.Atoi(parts[2])
+		if hour < 1 || hour > 24 {
+			return match
+		}
+		return fmt.Sprintf("%s %d:45", parts[1], hour-1)
+	})
+	return hourPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := hourPattern.FindStringSubmatch(match)
+		hour, _ := strconv.Atoi(parts[2])
+		if hour > 24 {
+			return match
+		}
+		minutes := map[string]int{" וחצי": 30, " ורבע": 15}[parts[3]]
+		rest := strings.TrimPrefix(match, parts[1]+" "+parts[2]+parts[3])
+		return fmt.Sprintf("%s %d:%02d%s", parts[1], hour, minutes, rest)
+	})
+}
+
+// digitNumberPattern matches a word that is a whole number, with prefix letters
+// (and their hyphen) before it; dates, times, decimals and phone numbers don't match
+var digitNumberPattern = regexp.MustCompile(`^(?:([` + numberPrefixes + `]{1,3})-?)?([1-9]\d{0,5}|0)$`)
+
+// digitsToNumberWords spells out the whole numbers written in digits
+func digitsToNumberWords(text string) string {
+	words := strings.Split(text, " ")
+	for i, word := range words {
+		lead, core, trail := splitWordPunctuation(word)
+		if parts := digitNumberPattern.FindStringSubmatch(core); parts != nil {
+			value, _ := strconv.Atoi(parts[2])
+			words[i] = lead + parts[1] + spellHebrewNumber(value, false) + trail
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+var (
+	feminineUnits  = []string{"אפס", "אחת", "שתיים", "שלוש", "ארבע", "חמש", "שש", "שבע", "שמונה", "תשע", "עשר"}
+	masculineUnits = []string{"אפס", "אחד", "שניים", "שלושה", "ארבעה", "חמישה", "שישה", "שבעה", "שמונה", "תשעה", "עשרה"}
+	hebrewTens     = []string{"", "", "עשרים", "שלושים", "ארבעים", "חמישים", "שישים", "שבעים", "שמונים", "תשעים"}
+	constructUnits = []string{"", "", "", "שלושת", "ארבעת", "חמשת", "ששת", "שבעת", "שמונת", "תשעת", "עשרת"}
+)
+
+// spellHebrewNumber spells out a number below a million, in the feminine counting
+// form used when no noun is counted ("עשרים ושלוש"), or the masculine form
+func spellHebrewNumber(n int, masculine bool) string {
+	if n < 0 || n >= 1000000 {
+		return strconv.Itoa(n)
+	}
+	if n == 0 {
+		return feminineUnits[0]
+	}
+
+	var parts []string
+	if thousands := n / 1000; thousands > 0 {
+		switch {
+		case thousands == 1:
+			parts = append(parts, "אלף")
+		case thousands == 2:
+			parts = append(parts, "אלפיים")
+		case thousands <= 10:
+			parts = append(parts, constructUnits[thousands]+" אלפים")
+		default:
+			parts = append(parts, spellHebrewNumber(thousands, true)+" אלף")
+		}
+	}
+	switch hundreds := n % 1000 / 100; hundreds {
+	case 0:
+	case 1:
+		parts = append(parts, "מאה")
+	case 2:
+		parts = append(parts, "מאתיים")
+	default:
+		parts = append(parts, feminineUnits[hundreds]+" מאות")
+	}
+
+	units := feminineUnits
+	teen := " עשרה"
+	if masculine {
+		units, teen = masculineUnits, " עשר"
+	}
+	switch rest := n % 100; {
+	case rest == 0:
+	case rest <= 10:
+		parts = append(parts, units[rest])
+	case rest < 20:
+		unit := units[rest-10]
+		if rest == 12 && masculine {
+			unit = "שנים"
+		} else if rest == 12 {
+			unit = "שתים"
+		}
+		parts = append(parts, unit+teen)
+	default:
+		parts = append(parts, hebrewTens[rest/10])
+		if rest%10 > 0 {
+			parts = append(parts, units[rest%10])
+		}
+	}
+
+	// "ו" (and) joins the last part
+	if len(parts) > 1 {
+		parts[len(parts)-1] = "ו" + parts[len(parts)-1]
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseHebrewNumber tests reading spelled-out Hebrew numbers
+func TestParseHebrewNumber(t *testing.T) {
+	tests := []struct {
+		words  string
+		value  int
+		n      int
+		prefix string
+	}{
+		{"שלוש", 3, 1, ""},
+		{"עשרים ושלושה ילדים", 23, 2, ""},
+		{"שתים עשרה", 12, 2, ""},
+		{"אחד עשר שחקנים", 11, 2, ""},
+		{"מאה ועשרים", 120, 2, ""},
+		{"שלוש מאות וחמישים", 350, 3, ""},
+		{"אלף תשע מאות שמונים וארבע", 1984, 5, ""},
+		{"אלפיים עשרים וארבע", 2024, 3, ""},
+		{"שלושת אלפים", 3000, 2, ""},
+		{"שלושת הילדים", 0, 0, ""},
+		{"עשרים אלף איש", 20000, 2, ""},
+		{"שני מיליון", 2000000, 2, ""},
+		{"בשלושים ושתיים", 32, 2, "ב"},
+		{"עשרים, שלושים", 20, 1, ""},
+		{"שלוש ארבע", 3, 1, ""},
+		{"ילדים", 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.words, func(t *testing.T) {
+			value, n, prefix := parseHebrewNumber(splitWords(tt.words))
+			if value != tt.value || n != tt.n || prefix != tt.prefix {
+				t.Errorf("parseHebrewNumber() = %d, %d, %q, expected %d, %d, %q", value, n, prefix, tt.value, tt.n, tt.prefix)
+			}
+		})
+	}
+}
+
+// TestSpellHebrewNumber tests spelling out numbers in both genders
+func TestSpellHebrewNumber(t *testing.T) {
+	tests := []struct {
+		n         int
+		masculine bool
+		expected  string
+	}{
+		{0, false, "אפס"},
+		{3, false, "שלוש"},
+		{3, true, "שלושה"},
+		{12, false, "שתים עשרה"},
+		{12, true, "שנים עשר"},
+		{23, false, "עשרים ושלוש"},
+		{105, false, "מאה וחמש"},
+		{120, false, "מאה ועשרים"},
+		{1984, false, "אלף תשע מאות שמונים וארבע"},
+		{2024, false, "אלפיים עשרים וארבע"},
+		{5000, false, "חמשת אלפים"},
+		{21500, false, "עשרים ואחד אלף וחמש מאות"},
+		{1000000, false, "1000000"},
+	}
+
+	for _, tt := range tests {
+		if got := spellHebrewNumber(tt.n, tt.masculine); got != tt.expected {
+			t.Errorf("spellHebrewNumber(%d, %v) = %q, expected %q", tt.n, tt.masculine, got, tt.expected)
+		}
+		if tt.n < 1000000 {
+			if value, _, _ := parseHebrewNumber(splitWords(tt.expected)); value != tt.n {
+				t.Errorf("parseHebrewNumber(%q) = %d, expected %d", tt.expected, value, tt.n)
+			}
+		}
+	}
+}
+
+// TestNormalizeNumberText tests the number styles and date and time formatting
+func TestNormalizeNumberText(t *testing.T) {
+	digits := NumberOptions{Style: NumbersDigits}
+	words := NumberOptions{Style: NumbersWords}
+	dates := NumberOptions{Dates: true}
+	tests := []struct {
+		name     string
+		options  NumberOptions
+		input    string
+		expected string
+	}{
+		{"Digits above ten", digits, "היו שם עשרים ושלושה אנשים ושלושה ילדים.", "היו שם 23 אנשים ושלושה ילדים."},
+		{"Digits with prefix", digits, "זה עלה בשלושים וחמישה שקלים", "זה עלה ב-35 שקלים"},
+		{"Digits keep punctuation", digits, "\"מאה\", אמר", "\"100\", אמר"},
+		{"Alphabet kept", digits, "לימדו אותם אלף בית", "לימדו אותם אלף בית"},
+		{"Words", words, "היו שם 23 אנשים ב-3 חדרים", "היו שם עשרים ושלוש אנשים בשלוש חדרים"},
+		{"Words skip phone numbers and decimals", words, "התקשר ל-050-1234567 או 3.5", "התקשר ל-050-1234567 או 3.5"},
+		{"Numeric date", dates, "נפגשנו ב-3.5.24 בבוקר", "נפגשנו ב-03/05/2024 בבוקר"},
+		{"Spoken date", dates, "נפגש בעשרים ושלושה במרץ אלפיים עשרים וארבע", "נפגש ב-23/03/2024"},
+		{"Time with half", dates, "בשעה שלוש וחצי נצא", "בשעה 3:30 נצא"},
+		{"Quarter to", dates, "עד השעה רבע לארבע", "עד השעה 3:45"},
+		{"Clock time", dates, "הפגישה ב-09:05.", "הפגישה ב-9:05."},
+		{"Half not a time", dates, "לפני שלוש וחצי שנים", "לפני שלוש וחצי שנים"},
+		{"Words keep dates", NumberOptions{Style: NumbersWords, Dates: true}, "ב-3.5.24, שעה 10", "ב-03/05/2024, שעה 10:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeNumberText(tt.input, tt.options); got != tt.expected {
+				t.Errorf("normalizeNumberText() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNormalizeNumbers tests that the Hebrew of translated segments is normalized and the input kept
+func TestNormalizeNumbers(t *testing.T) {
+	segments := []Segment{
+		{Text: "עשרים ושלוש"},
+		{Text: "Twenty", Original: "עשרים", Translation: "Twenty"},
+	}
+	normalized := NormalizeNumbers(segments, NumberOptions{Style: NumbersDigits})
+	if normalized[0].Text != "23" || normalized[1].Original != "20" || normalized[1].Translation != "Twenty" {
+		t.Errorf("Unexpected segments: %+v", normalized)
+	}
+	if segments[0].Text != "עשרים ושלוש" {
+		t.Errorf("Expected the input segments unchanged, got %+v", segments)
+	}
+}
+
+func splitWords(s string) []string {
+	return strings.Split(s, " ")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sampleClip is a short recording bundled with the app for the first-run test transcription
+//
+//go:embed sample.m4a
+var sampleClip []byte
+
+// Steps of the first-run setup, in order
+const (
+	onboardingModel  = iota // Choosing and downloading a model
+	onboardingFFmpeg        // Finding ffmpeg
+	onboardingOllama        // Optional: the local LLM for translation
+	onboardingSample        // A test transcription of the sample clip
+	onboardingSteps
+)
+
+// onboardingTitles are the headings of the setup steps
+var onboardingTitles = [onboardingSteps]string{
+	"Choose a model",
+	"Audio tools (ffmpeg)",
+	"Translation (optional)",
+	"Try it out",
+}
+
+// NeedsOnboarding reports whether the first-run setup should be shown: it hasn't
+// been finished or skipped, and no model is downloaded yet, so installations that
+// already work aren't walked through it
+func NeedsOnboarding(settings Settings) bool {
+	if settings.OnboardingDone {
+		return false
+	}
+	for _, modelID := range ModelIDs() {
+		if _, err := FindLocalModel(modelID); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// writeSampleClip writes the bundled sample clip to the temporary directory,
+// returning its path
+func writeSampleClip() (string, error) {
+	path := filepath.Join(os.TempDir(), "ivrit-ai-sample.m4a")
+	if err := os.WriteFile(path, sampleClip, 0644); err != nil {
+		return "", fmt.Errorf("cannot write the sample clip: %v", err)
+	}
+	return path, nil
+}
+
+// OllamaStatus describes the local ollama server translation uses
+type OllamaStatus struct {
+	Running  bool     // The server answers
+	Models   []string // Models pulled
+	HasModel bool     // The translation model is among them
+}
+
+// ollamaAPIURL returns the address of an ollama API endpoint, e.g. "/api/tags"
+func (t *MistralTranslator) ollamaAPIURL(path string) string {
+	return strings.TrimSuffix(t.ollamaURL, "/api/generate") + path
+}
+
+// Status asks ollama whether it is running and has the translation model
+func (t *MistralTranslator) Status() OllamaStatus {
+	client := httpClient(3 * time.Second)
+	resp, err := client.Get(t.ollamaAPIURL("/api/tags"))
+	if err != nil {
+		return OllamaStatus{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return OllamaStatus{}
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	status := OllamaStatus{Running: true}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return status
+	}
+	for _, m := range tags.Models {
+		status.Models = append(status.Models, m.Name)
+		if m.Name == t.model || m.Name+":latest" == t.model {
+			status.HasModel = true
+		}
+	}
+	return status
+}
+
+// PullModel has ollama download the translation model, reporting its progress
+func (t *MistralTranslator) PullModel(progressCallback func(string, int)) error {
+	jsonData, err := json.Marshal(map[string]interface{}{"name": t.model, "stream": true})
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient(0).Post(t.ollamaAPIURL("/api/pull"), "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to connect to ollama: %v (is ollama running?)", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama returned status %d", resp.StatusCode)
+	}
+
+	// One JSON object per line until "success"
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var update struct {
+			Status    string `json:"status"`
+			Error     string `json:"error"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			continue
+		}
+		if update.Error != "" {
+			return fmt.Errorf("ollama: %s", update.Error)
+		}
+		if update.Status == "success" {
+			return nil
+		}
+		if progressCallback != nil {
+			percent := -1
+			if update.Total > 0 {
+				percent = int(update.Completed * 100 / update.Total)
+			}
+			progressCallback(fmt.Sprintf("Downloading %s: %s", t.model, update.Status), percent)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("ollama stopped before the download finished")
+}
+
+// ollamaInstallHint explains how to set up ollama for translation
+func ollamaInstallHint(status OllamaStatus, model string) string {
+	switch {
+	case !status.Running:
+		return "Ollama isn't running. Install it from https://ollama.com and start it, then check again. You can skip this: transcription works without it."
+	case !status.HasModel:
+		return fmt.Sprintf("Ollama is running, but the %s model isn't downloaded yet (about 4GB).", model)
+	default:
+		return fmt.Sprintf("Ollama is ready with %s: translation, meeting minutes and spell check will work.", model)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestNeedsOnboarding tests when the first-run setup is shown
+func TestNeedsOnboarding(t *testing.T) {
+	useTempModelsConfig(t)
+	defer SetModelDir("")
+	SetModelDir(t.TempDir())
+
+	settings := defaultSettings()
+	if !NeedsOnboarding(settings) {
+		t.Error("Expected the setup on a first launch")
+	}
+	settings.OnboardingDone = true
+	if NeedsOnboarding(settings) {
+		t.Error("Expected no setup once finished or skipped")
+	}
+
+	// Installations with a model already work
+	os.WriteFile(filepath.Join(ModelDir(), "ggml-large-v3-turbo-ivrit.bin"), []byte("weights"), 0644)
+	if NeedsOnboarding(defaultSettings()) {
+		t.Error("Expected no setup with a model downloaded")
+	}
+}
+
+// TestWriteSampleClip tests writing the bundled sample clip
+func TestWriteSampleClip(t *testing.T) {
+	path, err := writeSampleClip()
+	if err != nil {
+		t.Fatalf("writeSampleClip() error: %v", err)
+	}
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || !bytes.Equal(data, sampleClip) {
+		t.Errorf("Expected the sample clip written, got %d bytes, %v", len(data), err)
+	}
+}
+
+// TestOllamaStatus tests detecting ollama and the translation model
+func TestOllamaStatus(t *testing.T) {
+	models := `{"models":[{"name":"llama3:latest"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, models)
+	}))
+	defer server.Close()
+
+	translator := NewMistralTranslator()
+	translator.ollamaURL = server.URL + "/api/generate"
+	if status := translator.Status(); !status.Running || status.HasModel || len(status.Models) != 1 {
+		t.Errorf("Expected ollama running without the model, got %+v", status)
+	}
+
+	models = `{"models":[{"name":"llama3:latest"},{"name":"mistral:latest"}]}`
+	if status := translator.Status(); !status.HasModel {
+		t.Errorf("Expected the translation model found, got %+v", status)
+	}
+
+	server.Close()
+	if status := translator.Status(); status.Running {
+		t.Error("Expected ollama reported as not running")
+	}
+}
+
+// TestPullModel tests downloading the translation model through ollama
+func TestPullModel(t *testing.T) {
+	reply := `{"status":"pulling manifest"}
+{"status":"downloading","total":200,"completed":100}
+{"status":"success"}
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, reply)
+	}))
+	defer server.Close()
+
+	translator := NewMistralTranslator()
+	translator.ollamaURL = server.URL + "/api/generate"
+	var percents []int
+	if err := translator.PullModel(func(msg string, percent int) { percents = append(percents, percent) }); err != nil {
+		t.Fatalf("PullModel() error: %v", err)
+	}
+	if len(percents) != 2 || percents[0] != -1 || percents[1] != 50 {
+		t.Errorf("Unexpected progress: %v", percents)
+	}
+
+	reply = `{"error":"pull model manifest: file does not exist"}` + "\n"
+	if err := translator.PullModel(nil); err == nil {
+		t.Error("Expected ollama's error returned")
+	}
+	reply = `{"status":"pulling manifest"}` + "\n"
+	if err := translator.PullModel(nil); err == nil {
+		t.Error("Expected an error when ollama stops before success")
+	}
+}
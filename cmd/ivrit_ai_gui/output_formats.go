@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FormatAll writes the transcript in each of allFormats at once, from the same segments
+const FormatAll = "all"
+
+// allFormats are the formats written by FormatAll: the plain ones that need nothing
+// beyond the segments (html embeds the audio and markdown links to it)
+var allFormats = []string{"text", "srt", "vtt", "json"}
+
+// outputFormats returns the formats to write for a format option
+func outputFormats(format string) []string {
+	if format == FormatAll {
+		return allFormats
+	}
+	return []string{format}
+}
+
+// formatExtension returns the file extension of an output format
+func formatExtension(format string) string {
+	switch format {
+	case "json", "srt", "vtt", "html", "ttml", "stl":
+		return format
+	case "markdown":
+		return "md"
+	case "tokens":
+		return "tokens.txt"
+	case FormatCustom:
+		return customExtension()
+	}
+	return "txt"
+}
+
+// formatFileName returns path with the extension of format, naming each file of a
+// transcript saved in several formats (e.g. talk.txt → talk.srt)
+func formatFileName(path, format string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + formatExtension(format)
+}
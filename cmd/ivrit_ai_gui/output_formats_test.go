@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestOutputFormats tests the formats written for a format option
+func TestOutputFormats(t *testing.T) {
+	if got := outputFormats("srt"); !reflect.DeepEqual(got, []string{"srt"}) {
+		t.Errorf("outputFormats(srt) = %v", got)
+	}
+	if got := outputFormats(FormatAll); !reflect.DeepEqual(got, []string{"text", "srt", "vtt", "json"}) {
+		t.Errorf("outputFormats(all) = %v", got)
+	}
+}
+
+// TestFormatFileName tests naming the files of a transcript saved in several formats
+func TestFormatFileName(t *testing.T) {
+	tests := []struct {
+		path     string
+		format   string
+		expected string
+	}{
+		{"out/talk_transcription.txt", "srt", "out/talk_transcription.srt"},
+		{"out/talk_transcription.txt", "text", "out/talk_transcription.txt"},
+		{"talk", "json", "talk.json"},
+		{"talk.v2.txt", "vtt", "talk.v2.vtt"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFileName(tt.path, tt.format); got != tt.expected {
+			t.Errorf("formatFileName(%q, %q) = %q, expected %q", tt.path, tt.format, got, tt.expected)
+		}
+	}
+}
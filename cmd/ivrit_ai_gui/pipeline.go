@@ -0,0 +1,47 @@
+package main
+
+// prefetchedInput is an input file whose audio has been decoded ahead of inference
+type prefetchedInput struct {
+	inputPath string
+	err       error
+}
+
+// RunPipeline overlaps decoding the next input's audio with processing (whisper
+// inference) of the current one. Inputs are processed sequentially and in order; at
+// most one file is decoded ahead, into memory (see prefetchAudio), and only the time
+// range when set, so no temporary WAV is written. When prefetch is false the inputs
+// are passed through as they are (e.g. split-channel mode needs the original
+// channels, and remote engines upload the file). Recordings long enough to be
+// streamed to whisper aren't decoded ahead either. It returns one error slot per
+// input (nil on success).
+func RunPipeline(inputs []string, prefetch bool, timeRange TimeRange, process func(inputPath string) error) []error {
+	prefetched := make(chan prefetchedInput) // Unbuffered: producer blocks one file ahead
+
+	// Producer: decode inputs in order
+	go func() {
+		defer close(prefetched)
+		for _, inputPath := range inputs {
+			var err error
+			if prefetch && !shouldStreamAudio(inputPath, timeRange) {
+				err = prefetchAudio(inputPath, timeRange)
+			}
+			prefetched <- prefetchedInput{inputPath: inputPath, err: err}
+		}
+	}()
+
+	// Consumer: process inputs as their audio becomes ready
+	errs := make([]error, 0, len(inputs))
+	for item := range prefetched {
+		if item.err != nil {
+			errs = append(errs, item.err)
+			continue
+		}
+
+		errs = append(errs, process(item.inputPath))
+
+		// Not taken when the transcript came from a cache or another engine
+		dropPrefetchedAudio(item.inputPath, timeRange)
+	}
+
+	return errs
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunPipelineOrder tests that inputs are processed in order with per-input errors
+func TestRunPipelineOrder(t *testing.T) {
+	inputs := []string{"a.wav", "b.wav", "c.wav"}
+	var processed []string
+
+	errs := RunPipeline(inputs, false, TimeRange{}, func(inputPath string) error {
+		processed = append(processed, inputPath)
+		if inputPath == "b.wav" {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+
+	if len(processed) != len(inputs) {
+		t.Fatalf("Expected %d processed inputs, got %d", len(inputs), len(processed))
+	}
+	for i := range inputs {
+		if processed[i] != inputs[i] {
+			t.Errorf("Input %d processed out of order: %q", i, processed[i])
+		}
+	}
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+}
+
+// TestRunPipelinePrefetch tests that the audio is decoded ahead for the engine to take,
+// and forgotten when it doesn't
+func TestRunPipelinePrefetch(t *testing.T) {
+	dir := t.TempDir()
+	inputs := []string{filepath.Join(dir, "a.wav"), filepath.Join(dir, "b.wav")}
+	for _, path := range inputs {
+		writeTestWAV(t, path, 1, 1, 16000, 16, make([]byte, 3200))
+	}
+
+	errs := RunPipeline(inputs, true, TimeRange{}, func(inputPath string) error {
+		if inputPath == inputs[0] {
+			if samples, _, ok := takePrefetchedAudio(inputPath, TimeRange{}); !ok || len(samples) != 1600 {
+				t.Errorf("Expected 1600 samples decoded ahead, got %d (%v)", len(samples), ok)
+			}
+		}
+		return nil // The second is left, like a cached transcript
+	})
+	if errs[0] != nil || errs[1] != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if _, _, ok := takePrefetchedAudio(inputs[1], TimeRange{}); ok {
+		t.Error("Expected audio not taken to be forgotten")
+	}
+}
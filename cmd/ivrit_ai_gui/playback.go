@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SegmentPlayer plays one segment's audio at a time, so a line can be checked
+// without scrubbing through the whole recording. It uses ffplay when installed
+// alongside ffmpeg, and otherwise cuts the segment to a temporary WAV file with
+// ffmpeg and plays that with the system's audio player.
+type SegmentPlayer struct {
+	mutex sync.Mutex
+	cmd   *exec.Cmd // Player process of the segment playing (nil = none)
+}
+
+// Play starts playing a segment of audioPath (with a little audio on either side),
+// stopping any segment still playing. done is called when playback ends by itself,
+// but not when it is stopped.
+func (p *SegmentPlayer) Play(audioPath string, seg Segment, done func(error)) error {
+	p.Stop()
+	window := RetranscribeWindow(seg)
+
+	var cleanup func()
+	ffplay, err := ffplayPath()
+	command := append([]string{ffplay}, ffplayArgs(audioPath, window)...)
+	if err != nil {
+		clipFile, err := CreateTempFile("segment_*.wav")
+		if err != nil {
+			return err
+		}
+		clipPath := clipFile.Name()
+		clipFile.Close()
+		cleanup = func() { RemoveTempFile(clipPath) }
+
+		if output, err := exec.Command(ffmpegPath(), clipArgs(audioPath, window, clipPath)...).CombinedOutput(); err != nil {
+			cleanup()
+			return fmt.Errorf("ffmpeg failed to cut the segment: %v %s", err, strings.TrimSpace(string(output)))
+		}
+		if command = playerCommand(runtime.GOOS, clipPath); command == nil {
+			cleanup()
+			return fmt.Errorf("no audio player found (install ffplay, which comes with ffmpeg)")
+		}
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	if err := cmd.Start(); err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return fmt.Errorf("%s failed: %v", filepath.Base(command[0]), err)
+	}
+
+	p.mutex.Lock()
+	p.cmd = cmd
+	p.mutex.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		if cleanup != nil {
+			cleanup()
+		}
+		p.mutex.Lock()
+		finished := p.cmd == cmd
+		if finished {
+			p.cmd = nil
+		}
+		p.mutex.Unlock()
+		if finished && done != nil {
+			done(err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops the segment playing, if any
+func (p *SegmentPlayer) Stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.cmd != nil {
+		p.cmd.Process.Kill()
+		p.cmd = nil
+	}
+}
+
+// Playing reports whether a segment is playing
+func (p *SegmentPlayer) Playing() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.cmd != nil
+}
+
+// ffplayPath locates ffplay: next to a configured ffmpeg, else where ffmpeg is searched for
+func ffplayPath() (string, error) {
+	ffmpegMutex.RLock()
+	override := ffmpegOverride
+	ffmpegMutex.RUnlock()
+
+	if override != "" {
+		name := "ffplay"
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		if path, err := findTool("ffplay", filepath.Join(filepath.Dir(override), name)); err == nil {
+			return path, nil
+		}
+	}
+	return findTool("ffplay", "")
+}
+
+// ffplayArgs plays a window of the input without opening a video window
+func ffplayArgs(audioPath string, window TimeRange) []string {
+	return []string{"-nodisp", "-autoexit", "-loglevel", "error",
+		"-ss", formatSeconds(window.Start), "-t", formatSeconds(window.End - window.Start), audioPath}
+}
+
+// clipArgs cuts a window of the input's audio to a WAV file
+func clipArgs(audioPath string, window TimeRange, clipPath string) []string {
+	return []string{"-y", "-loglevel", "error",
+		"-ss", formatSeconds(window.Start), "-t", formatSeconds(window.End - window.Start),
+		"-i", audioPath, "-vn", "-acodec", "pcm_s16le", clipPath}
+}
+
+// playerCommand returns the command playing a WAV file with the system's audio player
+// on goos, or nil if there is none
+func playerCommand(goos, path string) []string {
+	switch goos {
+	case "darwin":
+		return []string{"afplay", path}
+	case "windows":
+		// SoundPlayer plays WAV without opening a window; single quotes are doubled in PowerShell strings
+		script := "(New-Object Media.SoundPlayer '" + strings.ReplaceAll(path, "'", "''") + "').PlaySync()"
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		// PulseAudio/PipeWire, then ALSA
+		for _, player := range []string{"paplay", "pw-play", "aplay"} {
+			if _, err := exec.LookPath(player); err == nil {
+				return []string{player, path}
+			}
+		}
+		return nil
+	}
+}
+
+// formatSeconds formats seconds for ffmpeg's -ss and -t options
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
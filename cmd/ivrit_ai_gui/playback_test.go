@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFfplayArgs(t *testing.T) {
+	got := ffplayArgs("talk.mp3", TimeRange{Start: 9.75, End: 12.25})
+	want := []string{"-nodisp", "-autoexit", "-loglevel", "error", "-ss", "9.750", "-t", "2.500", "talk.mp3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ffplayArgs = %v, want %v", got, want)
+	}
+}
+
+func TestClipArgs(t *testing.T) {
+	got := clipArgs("talk.mp3", TimeRange{Start: 0, End: 1.5}, "clip.wav")
+	want := []string{"-y", "-loglevel", "error", "-ss", "0.000", "-t", "1.500",
+		"-i", "talk.mp3", "-vn", "-acodec", "pcm_s16le", "clip.wav"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clipArgs = %v, want %v", got, want)
+	}
+}
+
+func TestPlayerCommand(t *testing.T) {
+	if got := playerCommand("darwin", "/tmp/a.wav"); !reflect.DeepEqual(got, []string{"afplay", "/tmp/a.wav"}) {
+		t.Errorf("darwin = %v", got)
+	}
+	got := playerCommand("windows", `C:\Temp\it's.wav`)
+	if len(got) == 0 || got[0] != "powershell" {
+		t.Fatalf("windows = %v", got)
+	}
+	if script := got[len(got)-1]; script != `(New-Object Media.SoundPlayer 'C:\Temp\it''s.wav').PlaySync()` {
+		t.Errorf("windows script = %q", script)
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	for seconds, want := range map[float64]string{0: "0.000", 1.5: "1.500", 3725.1234: "3725.123"} {
+		if got := formatSeconds(seconds); got != want {
+			t.Errorf("formatSeconds(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
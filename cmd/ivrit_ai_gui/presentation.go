@@ -0,0 +1,223 @@
+//go:build !headless
+
+package main
+
+import (
+	"image/color"
+	"strings"
+	"sync"
+
+	"gioui.org/app"
+	"gioui.org/font/gofont"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+// Presentation mode caption layout
+const (
+	presentationMaxCaptions = 3 // Captions kept on screen; the newest is at the bottom
+	presentationFontSize    = unit.Sp(48)
+	presentationMinFontSize = unit.Sp(24)
+	presentationMaxFontSize = unit.Sp(120)
+	presentationFontStep    = unit.Sp(8)
+)
+
+// High-contrast caption colours
+var (
+	presentationBackground = color.NRGBA{A: 255}
+	presentationCurrent    = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	presentationPrevious   = color.NRGBA{R: 170, G: 170, B: 170, A: 255}
+	presentationHint       = color.NRGBA{R: 110, G: 110, B: 110, A: 255}
+)
+
+// PresentationWindow mirrors live captions in a separate window that can be
+// moved to a projector or second display and made full screen there
+type PresentationWindow struct {
+	window *app.Window
+	theme  *material.Theme
+
+	// Protected by mutex (written from the transcription goroutines)
+	mutex      sync.Mutex
+	captions   []string
+	fontSize   unit.Sp
+	fullscreen bool
+	closed     bool
+}
+
+// OpenPresentationWindow opens a new captions window
+func OpenPresentationWindow() *PresentationWindow {
+	th := material.NewTheme()
+	th.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
+
+	p := &PresentationWindow{
+		window:   new(app.Window),
+		theme:    th,
+		fontSize: presentationFontSize,
+	}
+	p.window.Option(app.Title("ivrit.ai - Live Captions"))
+	p.window.Option(app.Size(unit.Dp(960), unit.Dp(540)))
+
+	go p.run()
+	return p
+}
+
+// run handles the window's events until it is closed
+func (p *PresentationWindow) run() {
+	var ops op.Ops
+	for {
+		switch e := p.window.Event().(type) {
+		case app.DestroyEvent:
+			p.mutex.Lock()
+			p.closed = true
+			p.mutex.Unlock()
+			return
+		case app.ConfigEvent:
+			p.mutex.Lock()
+			p.fullscreen = e.Config.Mode == app.Fullscreen
+			p.mutex.Unlock()
+		case app.FrameEvent:
+			gtx := app.NewContext(&ops, e)
+			p.Layout(gtx)
+			e.Frame(gtx.Ops)
+		}
+	}
+}
+
+// Closed reports whether the user has closed the window
+func (p *PresentationWindow) Closed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.closed
+}
+
+// Raise brings the window to the front
+func (p *PresentationWindow) Raise() {
+	p.window.Perform(system.ActionRaise)
+}
+
+// AddCaption shows a new caption, scrolling older ones up and off the screen
+func (p *PresentationWindow) AddCaption(caption string) {
+	caption = strings.TrimSpace(caption)
+	if caption == "" {
+		return
+	}
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.captions = append(p.captions, caption)
+	if len(p.captions) > presentationMaxCaptions {
+		p.captions = p.captions[len(p.captions)-presentationMaxCaptions:]
+	}
+	p.mutex.Unlock()
+	p.window.Invalidate()
+}
+
+// Clear removes all captions (a new transcription is starting)
+func (p *PresentationWindow) Clear() {
+	p.mutex.Lock()
+	p.captions = nil
+	p.mutex.Unlock()
+	p.window.Invalidate()
+}
+
+// handleKeys toggles full screen (F11/F, Esc to leave) and changes the text size (+/-)
+func (p *PresentationWindow) handleKeys(gtx layout.Context) {
+	for {
+		ev, ok := gtx.Event(
+			key.Filter{Name: key.NameF11},
+			key.Filter{Name: "F"},
+			key.Filter{Name: key.NameEscape},
+			key.Filter{Name: "+", Optional: key.ModShift},
+			key.Filter{Name: "=", Optional: key.ModShift},
+			key.Filter{Name: "-"},
+		)
+		if !ok {
+			break
+		}
+		e, ok := ev.(key.Event)
+		if !ok || e.State != key.Press {
+			continue
+		}
+
+		p.mutex.Lock()
+		fullscreen := p.fullscreen
+		switch e.Name {
+		case "+", "=":
+			p.fontSize = min(p.fontSize+presentationFontStep, presentationMaxFontSize)
+		case "-":
+			p.fontSize = max(p.fontSize-presentationFontStep, presentationMinFontSize)
+		}
+		p.mutex.Unlock()
+
+		switch e.Name {
+		case key.NameF11, "F":
+			if fullscreen {
+				p.window.Option(app.Windowed.Option())
+			} else {
+				p.window.Option(app.Fullscreen.Option())
+			}
+		case key.NameEscape:
+			if fullscreen {
+				p.window.Option(app.Windowed.Option())
+			}
+		}
+		p.window.Invalidate()
+	}
+}
+
+// Layout draws the captions centred at the bottom of a black screen
+func (p *PresentationWindow) Layout(gtx layout.Context) layout.Dimensions {
+	p.handleKeys(gtx)
+
+	p.mutex.Lock()
+	captions := append([]string(nil), p.captions...)
+	fontSize := p.fontSize
+	fullscreen := p.fullscreen
+	p.mutex.Unlock()
+
+	paint.Fill(gtx.Ops, presentationBackground)
+
+	children := []layout.FlexChild{}
+	if !fullscreen {
+		// Only shown in a window, so the audience never sees it
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Label(p.theme, unit.Sp(14), "Move this window to the display for the audience, then press F11 for full screen. +/- change the text size, Esc leaves full screen.")
+			label.Color = presentationHint
+			label.Alignment = text.Middle
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return label.Layout(gtx)
+		}))
+	}
+	children = append(children, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+		return layout.Dimensions{Size: gtx.Constraints.Min}
+	}))
+	for i, caption := range captions {
+		captionColor := presentationPrevious
+		if i == len(captions)-1 {
+			captionColor = presentationCurrent
+		}
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(12)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				label := material.Label(p.theme, fontSize, caption)
+				label.Color = captionColor
+				label.Alignment = text.Middle
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return label.Layout(gtx)
+			})
+		}))
+	}
+
+	return layout.UniformInset(unit.Dp(48)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		gtx.Constraints.Min = gtx.Constraints.Max
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Preset is a named set of transcription options, selected with -preset or in the
+// GUI. Its options are config.json keys, applied over the configuration; a section
+// it sets (such as "decode") replaces the configured one as a whole.
+type Preset struct {
+	Name    string          `json:"name"`
+	Options json.RawMessage `json:"options"`
+}
+
+// builtinPresets are offered until a preset of the same name is saved over them
+var builtinPresets = []Preset{
+	{Name: "Quick draft", Options: json.RawMessage(`{"model": "turbo", "format": "text", "translate": false, "consensus": ""}`)},
+	{Name: "Podcast publish", Options: json.RawMessage(`{"model": "large-v3", "format": "markdown", "numbers": {"style": "digits", "dates": true}, "events": "none", "rtl": {"fixPunctuation": true}}`)},
+	{Name: "Legal verbatim", Options: json.RawMessage(`{"model": "large-v3", "format": "text", "trimSilence": false, "consensus": "models", "decode": {"beamSize": 5}, "numbers": {"style": "transcribed"}, "events": "all"}`)},
+}
+
+// presetOptions are the options a preset saved from the current settings records:
+// model, format, translation, decoding and post-processing, but not paths or servers
+type presetOptions struct {
+	Model           string                 `json:"model"`
+	Format          string                 `json:"format"`
+	Translate       bool                   `json:"translate"`
+	TargetLang      string                 `json:"targetLang"`
+	DisplayMode     string                 `json:"displayMode"`
+	ChannelMode     string                 `json:"channels"`
+	TrimSilence     bool                   `json:"trimSilence"`
+	Decode          DecodeOptions          `json:"decode"`
+	Consensus       string                 `json:"consensus"`
+	Redact          RedactOptions          `json:"redact"`
+	Transliteration TransliterationOptions `json:"transliteration"`
+	Events          string                 `json:"events"`
+	Encoding        OutputEncoding         `json:"encoding"`
+	RTL             RTLOptions             `json:"rtl"`
+	Cues            CueOptions             `json:"cues"`
+	Numbers         NumberOptions          `json:"numbers"`
+	SpeakerLabels   SpeakerLabelOptions    `json:"speakerLabels"`
+}
+
+var presetsMutex sync.Mutex // Serializes reading and writing the presets file
+
+// presetsPath returns the location of the saved presets
+func presetsPath() string {
+	return filepath.Join(filepath.Dir(settingsPath()), "presets.json")
+}
+
+// LoadPresets returns the built-in presets followed by the saved ones, a saved
+// preset taking the place of a built-in one of the same name
+func LoadPresets() ([]Preset, error) {
+	presetsMutex.Lock()
+	defer presetsMutex.Unlock()
+	saved, err := loadSavedPresets()
+	if err != nil {
+		return builtinPresets, err
+	}
+	return mergePresets(builtinPresets, saved), nil
+}
+
+// loadSavedPresets reads the presets file; the caller holds presetsMutex
+func loadSavedPresets() ([]Preset, error) {
+	data, err := os.ReadFile(presetsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("invalid presets file %s: %v", presetsPath(), err)
+	}
+	return presets, nil
+}
+
+// mergePresets lists base followed by extra, presets of extra replacing those of
+// base with the same name (ignoring case)
+func mergePresets(base, extra []Preset) []Preset {
+	merged := append([]Preset{}, base...)
+	for _, preset := range extra {
+		replaced := false
+		for i := range merged {
+			if strings.EqualFold(merged[i].Name, preset.Name) {
+				merged[i], replaced = preset, true
+			}
+		}
+		if !replaced {
+			merged = append(merged, preset)
+		}
+	}
+	return merged
+}
+
+// FindPreset returns the preset of the given name (ignoring case)
+func FindPreset(name string) (Preset, error) {
+	presets, err := LoadPresets()
+	if err != nil {
+		return Preset{}, err
+	}
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("no preset named %q. Presets: %s", name, strings.Join(PresetNames(presets), ", "))
+}
+
+// PresetNames returns the names of the presets
+func PresetNames(presets []Preset) []string {
+	names := make([]string, len(presets))
+	for i, preset := range presets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// SavePreset saves the preset, replacing a saved preset of the same name
+func SavePreset(preset Preset) error {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" {
+		return fmt.Errorf("a preset needs a name")
+	}
+	presetsMutex.Lock()
+	defer presetsMutex.Unlock()
+	saved, err := loadSavedPresets()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(mergePresets(saved, []Preset{preset}), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(presetsPath()), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(presetsPath(), data, 0644)
+}
+
+// NewPreset records the options of cfg a preset holds under the given name
+func NewPreset(name string, cfg AppConfig) (Preset, error) {
+	options, err := json.Marshal(presetOptions{
+		Model:           cfg.Model,
+		Format:          cfg.Format,
+		Translate:       cfg.Translate,
+		TargetLang:      cfg.TargetLang,
+		DisplayMode:     cfg.DisplayMode,
+		ChannelMode:     cfg.ChannelMode,
+		TrimSilence:     cfg.TrimSilence,
+		Decode:          cfg.Decode,
+		Consensus:       cfg.Consensus,
+		Redact:          cfg.Redact,
+		Transliteration: cfg.Transliteration,
+		Events:          cfg.Events,
+		Encoding:        cfg.Encoding,
+		RTL:             cfg.RTL,
+		Cues:            cfg.Cues,
+		Numbers:         cfg.Numbers,
+		SpeakerLabels:   cfg.SpeakerLabels,
+	})
+	return Preset{Name: strings.TrimSpace(name), Options: options}, err
+}
+
+// ApplyPreset sets the options of the named preset
+func (c *AppConfig) ApplyPreset(name string) error {
+	preset, err := FindPreset(name)
+	if err != nil {
+		return err
+	}
+	return c.applyPresetOptions(preset.Options)
+}
+
+// applyPresetOptions sets the config.json keys of a preset, clearing each section
+// (JSON object) it sets first so the section's omitted fields take their defaults
+func (c *AppConfig) applyPresetOptions(options json.RawMessage) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(options, &keys); err != nil {
+		return fmt.Errorf("invalid preset: %v", err)
+	}
+	config := reflect.ValueOf(c).Elem()
+	for i := 0; i < config.NumField(); i++ {
+		field := config.Type().Field(i)
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if value, ok := keys[key]; ok && field.Type.Kind() == reflect.Struct && strings.HasPrefix(strings.TrimSpace(string(value)), "{") {
+			config.Field(i).Set(reflect.Zero(field.Type))
+		}
+	}
+	if err := json.Unmarshal(options, c); err != nil {
+		return fmt.Errorf("invalid preset: %v", err)
+	}
+	return nil
+}
+
+// presetArg returns the value of the -preset flag in command-line arguments, which
+// is applied before the other flags are parsed so they override the preset
+func presetArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if !strings.HasPrefix(arg, "-") || (name != "preset" && !strings.HasPrefix(name, "preset=")) {
+			continue
+		}
+		if value, ok := strings.CutPrefix(name, "preset="); ok {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// printPresets lists the presets with their options
+func printPresets(presets []Preset) {
+	for _, preset := range presets {
+		fmt.Printf("%s\n    %s\n", preset.Name, string(preset.Options))
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestApplyPreset tests that presets set their options over the configuration
+func TestApplyPreset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := DefaultConfig()
+	cfg.FFmpegPath = "/opt/ffmpeg"
+	cfg.Decode = DecodeOptions{BeamSize: 3, InitialPrompt: "ivrit.ai"}
+
+	if err := cfg.ApplyPreset("legal verbatim"); err != nil {
+		t.Fatalf("ApplyPreset() error: %v", err)
+	}
+	if cfg.Model != "large-v3" || cfg.Consensus != ConsensusModels || cfg.TrimSilence || cfg.Events != EventsAll {
+		t.Errorf("Preset options not applied: %+v", cfg)
+	}
+	if cfg.Decode != (DecodeOptions{BeamSize: 5}) {
+		t.Errorf("Expected the preset's decode section to replace the configured one, got %+v", cfg.Decode)
+	}
+	if cfg.FFmpegPath != "/opt/ffmpeg" || cfg.Format != "text" {
+		t.Errorf("Expected options the preset doesn't set kept: %+v", cfg)
+	}
+	if err := cfg.ApplyPreset("Nonexistent"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+// TestSavePreset tests saving presets from a configuration, over built-in ones
+func TestSavePreset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := DefaultConfig()
+	cfg.Format = "srt"
+	cfg.Translate = true
+	cfg.TargetLang = "fr"
+	cfg.Cues = CueOptions{SRTAlign: "bottom-right"}
+	cfg.OutputDir = "/home/me/transcripts" // Not part of presets
+
+	for _, name := range []string{"Subtitles FR", "Quick draft"} {
+		preset, err := NewPreset(name, cfg)
+		if err != nil {
+			t.Fatalf("NewPreset() error: %v", err)
+		}
+		if err := SavePreset(preset); err != nil {
+			t.Fatalf("SavePreset() error: %v", err)
+		}
+	}
+
+	presets, err := LoadPresets()
+	if err != nil || len(presets) != len(builtinPresets)+1 {
+		t.Fatalf("Expected the built-in presets and one more, got %v (error: %v)", PresetNames(presets), err)
+	}
+	applied := DefaultConfig()
+	if err := applied.ApplyPreset("subtitles fr"); err != nil {
+		t.Fatalf("ApplyPreset() error: %v", err)
+	}
+	if applied.Format != "srt" || !applied.Translate || applied.TargetLang != "fr" || applied.Cues.SRTAlign != "bottom-right" || applied.OutputDir != "" {
+		t.Errorf("Saved preset not applied: %+v", applied)
+	}
+	applied = DefaultConfig()
+	applied.ApplyPreset("Quick draft")
+	if applied.Format != "srt" {
+		t.Errorf("Expected the saved Quick draft to replace the built-in one, got format %s", applied.Format)
+	}
+	if err := SavePreset(Preset{Name: "  "}); err == nil {
+		t.Error("Expected an error saving a preset without a name")
+	}
+}
+
+// TestPresetArg tests finding -preset among command-line arguments
+func TestPresetArg(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-input", "a.m4a", "-preset", "Quick draft"}, "Quick draft"},
+		{[]string{"--preset=Podcast publish", "-input", "a.m4a"}, "Podcast publish"},
+		{[]string{"-input", "a.m4a"}, ""},
+		{[]string{"-input", "-", "--", "-preset", "x"}, ""},
+		{[]string{"-prompt", "preset"}, ""},
+	}
+	for _, tt := range tests {
+		if got := presetArg(tt.args); got != tt.expected {
+			t.Errorf("presetArg(%q) = %q, expected %q", tt.args, got, tt.expected)
+		}
+	}
+}
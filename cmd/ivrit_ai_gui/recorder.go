@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordBlockSize is how much audio is read from ffmpeg at a time: 50ms of 16kHz
+// mono 16-bit PCM, so the level meter follows the voice
+const recordBlockSize = whisperSampleRate / 20 * 2
+
+// recordStopTimeout is how long ffmpeg gets to stop after being asked to
+const recordStopTimeout = 3 * time.Second
+
+// wavHeaderSize is the size of the header of the WAV files recorded
+const wavHeaderSize = 44
+
+// RecordingDevice is an audio input ffmpeg records from
+type RecordingDevice struct {
+	Name   string // As shown, e.g. "MacBook Pro Microphone"
+	Format string // ffmpeg input device (-f): avfoundation, dshow, pulse or alsa
+	Input  string // ffmpeg input (-i), e.g. ":0" or "audio=Microphone (USB)"
+
+	// Loopback records what the computer plays (a video call, a streamed lecture)
+	// rather than a microphone
+	Loopback bool
+}
+
+// loopbackNames are parts of the names of inputs carrying the system's output: virtual
+// audio drivers on macOS, and on Windows the loopback inputs of sound card drivers
+// (Stereo Mix), VB-Audio's virtual cable and screen-capture-recorder's WASAPI capturer
+var loopbackNames = []string{"blackhole", "soundflower", "loopback", "stereo mix", "what u hear", "wave out", "cable output", "virtual-audio-capturer"}
+
+// isLoopbackName reports whether a device's name is that of a loopback input
+func isLoopbackName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range loopbackNames {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoopbackHint tells how to make the system's output recordable where no loopback
+// input was found
+func LoopbackHint() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "To record system audio, install BlackHole (existential.audio/blackhole) and add it with your speakers to a Multi-Output Device in Audio MIDI Setup"
+	case "windows":
+		return "To record system audio, enable Stereo Mix under Sound > Recording, or install VB-CABLE or screen-capture-recorder"
+	default:
+		return "To record system audio, run PulseAudio or PipeWire, whose output monitors are listed as inputs"
+	}
+}
+
+// Patterns of ffmpeg's device lists
+var (
+	avfoundationDevice = regexp.MustCompile(`\] \[(\d+)\] (.+)$`)
+	dshowAudioDevice   = regexp.MustCompile(`\] +"([^"]+)" \(audio\)`)
+	dshowDevice        = regexp.MustCompile(`\] +"([^"]+)"\s*$`)
+)
+
+// ListRecordingDevices returns the audio inputs of the system, the default one first
+func ListRecordingDevices() ([]RecordingDevice, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		output, _ := exec.Command(ffmpegPath(), "-hide_banner", "-f", "avfoundation", "-list_devices", "true", "-i", "").CombinedOutput()
+		return append([]RecordingDevice{{Name: "Default microphone", Format: "avfoundation", Input: ":default"}}, parseAVFoundationDevices(string(output))...), nil
+	case "windows":
+		output, _ := exec.Command(ffmpegPath(), "-hide_banner", "-list_devices", "true", "-f", "dshow", "-i", "dummy").CombinedOutput()
+		devices := parseDShowDevices(string(output))
+		if len(devices) == 0 {
+			return nil, fmt.Errorf("no microphone found")
+		}
+		return devices, nil
+	default:
+		// PulseAudio/PipeWire sources, or ALSA's default input without them
+		output, err := exec.Command("pactl", "list", "short", "sources").Output()
+		if err != nil {
+			return []RecordingDevice{{Name: "Default microphone", Format: "alsa", Input: "default"}}, nil
+		}
+		return append([]RecordingDevice{
+			{Name: "Default microphone", Format: "pulse", Input: "default"},
+			{Name: "System audio", Format: "pulse", Input: "@DEFAULT_MONITOR@", Loopback: true},
+		}, parsePulseSources(string(output))...), nil
+	}
+}
+
+// parseAVFoundationDevices returns the audio devices in ffmpeg's avfoundation list,
+// which follow the video devices
+func parseAVFoundationDevices(output string) []RecordingDevice {
+	var devices []RecordingDevice
+	audio := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.Contains(line, "audio devices:") {
+			audio = true
+		} else if strings.Contains(line, "video devices:") {
+			audio = false
+		} else if m := avfoundationDevice.FindStringSubmatch(line); m != nil && audio {
+			devices = append(devices, RecordingDevice{Name: m[2], Format: "avfoundation", Input: ":" + m[1], Loopback: isLoopbackName(m[2])})
+		}
+	}
+	return devices
+}
+
+// parseDShowDevices returns the audio devices in ffmpeg's DirectShow list, marked
+// "(audio)" by recent ffmpeg and listed under "DirectShow audio devices" by older ones
+func parseDShowDevices(output string) []RecordingDevice {
+	var devices []RecordingDevice
+	audioSection := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.Contains(line, "DirectShow audio devices") {
+			audioSection = true
+			continue
+		} else if strings.Contains(line, "DirectShow video devices") {
+			audioSection = false
+			continue
+		}
+		name := ""
+		if m := dshowAudioDevice.FindStringSubmatch(line); m != nil {
+			name = m[1]
+		} else if m := dshowDevice.FindStringSubmatch(line); m != nil && audioSection {
+			name = m[1]
+		}
+		if name != "" {
+			devices = append(devices, RecordingDevice{Name: name, Format: "dshow", Input: "audio=" + name, Loopback: isLoopbackName(name)})
+		}
+	}
+	return devices
+}
+
+// parsePulseSources returns the sources in "pactl list short sources" output. The
+// monitors of outputs are listed too, for recording what the computer plays.
+func parsePulseSources(output string) []RecordingDevice {
+	var devices []RecordingDevice
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[1] == "" {
+			continue
+		}
+		monitor := strings.HasSuffix(fields[1], ".monitor")
+		devices = append(devices, RecordingDevice{Name: fields[1], Format: "pulse", Input: fields[1], Loopback: monitor})
+	}
+	return devices
+}
+
+// recordArgs returns the ffmpeg arguments recording a device as 16kHz mono 16-bit
+// PCM to its output
+func recordArgs(device RecordingDevice) []string {
+	return []string{"-hide_banner", "-loglevel", "error",
+		"-f", device.Format, "-i", device.Input,
+		"-vn", "-ar", "16000", "-ac", "1", "-f", "s16le", "pipe:1"}
+}
+
+// recordingFileName returns where a recording started at t is saved: the output
+// folder, or the Documents folder (else the home folder) when none is set
+func recordingFileName(outputDir string, t time.Time) string {
+	dir := outputDir
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, "Documents")
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			dir = home
+		}
+	}
+	return filepath.Join(dir, "recording_"+t.Format("20060102-150405")+".wav")
+}
+
+// Recorder records a device to a 16kHz mono WAV file, which whisper reads without
+// conversion. The WAV header is kept up to date as audio arrives, so the recording
+// is a valid file even if the app or the transcription fails.
+type Recorder struct {
+	Path string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+	file   *os.File
+	done   chan struct{}
+
+	mu      sync.Mutex
+	paused  bool
+	peak    float32 // Of the last block read, 0 to 1
+	written int64   // PCM bytes in the file
+	err     error
+}
+
+// StartRecording starts recording the device to a WAV file at path
+func StartRecording(device RecordingDevice, path string) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(wavHeader(0)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &Recorder{Path: path, file: file, done: make(chan struct{})}
+	r.cmd = exec.Command(ffmpegPath(), recordArgs(device)...)
+	r.cmd.Stderr = &r.stderr
+	stdout, err := r.cmd.StdoutPipe()
+	if err == nil {
+		r.stdin, err = r.cmd.StdinPipe() // ffmpeg stops cleanly on "q"
+	}
+	if err == nil {
+		err = r.cmd.Start()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("cannot start recording: %v (is ffmpeg installed?)", err)
+	}
+	go r.capture(stdout)
+	return r, nil
+}
+
+// capture writes the audio ffmpeg records to the file, except while paused
+func (r *Recorder) capture(pcm io.Reader) {
+	defer close(r.done)
+	buf := make([]byte, recordBlockSize)
+	for {
+		n, readErr := io.ReadFull(pcm, buf)
+		n -= n % 2
+		peak := pcmPeak(buf[:n])
+
+		r.mu.Lock()
+		r.peak = peak
+		if !r.paused && n > 0 {
+			if _, err := r.file.Write(buf[:n]); err != nil {
+				r.err = err
+			} else {
+				r.written += int64(n)
+				r.err = updateWAVSizes(r.file, r.written)
+			}
+		}
+		failed := r.err != nil
+		r.mu.Unlock()
+
+		if readErr != nil || failed {
+			io.Copy(io.Discard, pcm) // Let ffmpeg finish
+			return
+		}
+	}
+}
+
+// Pause stops adding audio to the recording until Resume; the level is still shown
+func (r *Recorder) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = true
+}
+
+// Resume adds audio to the recording again
+func (r *Recorder) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = false
+}
+
+// Paused reports whether the recording is paused
+func (r *Recorder) Paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.paused
+}
+
+// Level returns the input level for a meter, 0 (-60 dBFS or quieter) to 1 (full scale)
+func (r *Recorder) Level() float32 {
+	r.mu.Lock()
+	peak := r.peak
+	r.mu.Unlock()
+	return meterLevel(peak)
+}
+
+// Duration returns the length of the audio recorded, pauses left out
+func (r *Recorder) Duration() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.written/2) * time.Second / whisperSampleRate
+}
+
+// recorded returns how many bytes of PCM are in the file so far
+func (r *Recorder) recorded() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.written
+}
+
+// Done is closed when the recording ends, by Stop or because ffmpeg stopped
+func (r *Recorder) Done() <-chan struct{} {
+	return r.done
+}
+
+// Stop ends the recording and closes the file. An error is returned (and the file
+// removed) only if nothing was recorded.
+func (r *Recorder) Stop() error {
+	io.WriteString(r.stdin, "q")
+	r.stdin.Close()
+	select {
+	case <-r.done:
+	case <-time.After(recordStopTimeout):
+		r.cmd.Process.Kill()
+		<-r.done
+	}
+	waitErr := r.cmd.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	closeErr := r.file.Close()
+	if r.written == 0 {
+		os.Remove(r.Path)
+		if message := strings.TrimSpace(r.stderr.String()); message != "" {
+			return fmt.Errorf("nothing was recorded: %s", lastLine(message))
+		} else if waitErr != nil {
+			return fmt.Errorf("nothing was recorded: %v", waitErr)
+		}
+		return fmt.Errorf("nothing was recorded")
+	}
+	if r.err != nil {
+		return r.err
+	}
+	return closeErr
+}
+
+// wavHeader returns the header of a 16kHz mono 16-bit PCM WAV with dataSize bytes of audio
+func wavHeader(dataSize int64) []byte {
+	header := make([]byte, wavHeaderSize)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(36+dataSize))
+	copy(header[8:16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:20], 16)
+	binary.LittleEndian.PutUint16(header[20:22], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:24], 1) // Mono
+	binary.LittleEndian.PutUint32(header[24:28], whisperSampleRate)
+	binary.LittleEndian.PutUint32(header[28:32], whisperSampleRate*2)
+	binary.LittleEndian.PutUint16(header[32:34], 2)
+	binary.LittleEndian.PutUint16(header[34:36], 16)
+	copy(header[36:40], "data")
+	binary.LittleEndian.PutUint32(header[40:44], uint32(dataSize))
+	return header
+}
+
+// updateWAVSizes writes the RIFF and data sizes of a WAV with dataSize bytes of audio
+func updateWAVSizes(f *os.File, dataSize int64) error {
+	header := wavHeader(dataSize)
+	if _, err := f.WriteAt(header[4:8], 4); err != nil {
+		return err
+	}
+	_, err := f.WriteAt(header[40:44], 40)
+	return err
+}
+
+// pcmPeak returns the highest absolute sample of 16-bit PCM, 0 to 1
+func pcmPeak(pcm []byte) float32 {
+	var peak float32
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float32(int16(binary.LittleEndian.Uint16(pcm[i:]))) / 32768.0
+		peak = max(peak, sample, -sample)
+	}
+	return peak
+}
+
+// meterLevel maps a peak on a decibel scale from -60 dBFS (0) to full scale (1), as
+// level meters show it
+func meterLevel(peak float32) float32 {
+	if peak <= 0 {
+		return 0
+	}
+	db := 20 * math.Log10(float64(peak))
+	return float32(math.Max(0, math.Min(1, (db+60)/60)))
+}
+
+// lastLine returns the last line of a message
+func lastLine(message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseAVFoundationDevices tests reading the audio devices of ffmpeg's macOS list
+func TestParseAVFoundationDevices(t *testing.T) {
+	output := `[AVFoundation indev @ 0x7f8] AVFoundation video devices:
+[AVFoundation indev @ 0x7f8] [0] FaceTime HD Camera
+[AVFoundation indev @ 0x7f8] [1] Capture screen 0
+[AVFoundation indev @ 0x7f8] AVFoundation audio devices:
+[AVFoundation indev @ 0x7f8] [0] MacBook Pro Microphone
+[AVFoundation indev @ 0x7f8] [1] Shure MV7
+[AVFoundation indev @ 0x7f8] [2] BlackHole 2ch
+: Input/output error
+`
+	devices := parseAVFoundationDevices(output)
+	if len(devices) != 3 {
+		t.Fatalf("Expected 3 audio devices, got %+v", devices)
+	}
+	if devices[1] != (RecordingDevice{Name: "Shure MV7", Format: "avfoundation", Input: ":1"}) {
+		t.Errorf("Unexpected device: %+v", devices[1])
+	}
+	if !devices[2].Loopback {
+		t.Errorf("BlackHole not taken for system audio: %+v", devices[2])
+	}
+}
+
+// TestParseDShowDevices tests reading the audio devices of ffmpeg's Windows list, in
+// the layouts of recent and older ffmpeg
+func TestParseDShowDevices(t *testing.T) {
+	recent := "[dshow @ 0000] \"Integrated Camera\" (video)\r\n" +
+		"[dshow @ 0000]   Alternative name \"@device_pnp_\\\\?\\usb#vid\"\r\n" +
+		"[dshow @ 0000] \"Microphone (Realtek Audio)\" (audio)\r\n" +
+		"[dshow @ 0000]   Alternative name \"@device_cm_{33D9A762}\\wave_{5F2A}\"\r\n"
+	older := "[dshow @ 0000] DirectShow video devices (some may be both video and audio devices)\r\n" +
+		"[dshow @ 0000]  \"Integrated Camera\"\r\n" +
+		"[dshow @ 0000] DirectShow audio devices\r\n" +
+		"[dshow @ 0000]  \"Microphone (Realtek Audio)\"\r\n" +
+		"[dshow @ 0000]     Alternative name \"@device_cm_{33D9A762}\\wave_{5F2A}\"\r\n"
+
+	for _, output := range []string{recent, older} {
+		devices := parseDShowDevices(output)
+		if len(devices) != 1 || devices[0].Input != "audio=Microphone (Realtek Audio)" {
+			t.Errorf("Unexpected devices: %+v", devices)
+		}
+	}
+}
+
+// TestParsePulseSources tests reading the sources of pactl's short list
+func TestParsePulseSources(t *testing.T) {
+	output := "0\talsa_output.pci-0000_00_1f.3.analog-stereo.monitor\tPipeWire\ts32le 2ch 48000Hz\tSUSPENDED\n" +
+		"1\talsa_input.usb-Blue_Yeti-00.analog-stereo\tPipeWire\ts16le 2ch 48000Hz\tRUNNING\n"
+	devices := parsePulseSources(output)
+	if len(devices) != 2 || devices[1] != (RecordingDevice{Name: "alsa_input.usb-Blue_Yeti-00.analog-stereo", Format: "pulse", Input: "alsa_input.usb-Blue_Yeti-00.analog-stereo"}) {
+		t.Errorf("Unexpected devices: %+v", devices)
+	}
+	if len(devices) > 0 && !devices[0].Loopback {
+		t.Errorf("Output monitor not taken for system audio: %+v", devices[0])
+	}
+}
+
+// TestIsLoopbackName tests recognizing the inputs that carry the system's output
+func TestIsLoopbackName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Stereo Mix (Realtek(R) Audio)":         true,
+		"CABLE Output (VB-Audio Virtual Cable)": true,
+		"virtual-audio-capturer":                true,
+		"Microphone (USB Audio Device)":         false,
+		"MacBook Pro Microphone":                false,
+	} {
+		if got := isLoopbackName(name); got != want {
+			t.Errorf("isLoopbackName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestRecordingWAVHeader tests that a recording's header, updated as audio arrives,
+// makes a WAV whisper reads without conversion
+func TestRecordingWAVHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(wavHeader(0))
+	pcm := make([]byte, 3200)
+	binary.LittleEndian.PutUint16(pcm[2:], uint16(16384))
+	f.Write(pcm)
+	if err := updateWAVSizes(f, int64(len(pcm))); err != nil {
+		t.Fatalf("updateWAVSizes() error: %v", err)
+	}
+	f.Close()
+
+	if !readableWAV(path) {
+		t.Fatal("Recording not readable as a WAV")
+	}
+	samples, err := readWAVSamples(path)
+	if err != nil {
+		t.Fatalf("readWAVSamples() error: %v", err)
+	}
+	if len(samples) != 1600 || samples[1] != 0.5 {
+		t.Errorf("Unexpected samples: %d, second %v", len(samples), samples[1])
+	}
+}
+
+// TestMeterLevel tests the level meter's decibel scale
+func TestMeterLevel(t *testing.T) {
+	pcm := make([]byte, 4)
+	binary.LittleEndian.PutUint16(pcm[2:], uint16(0x8000)) // -32768, full scale
+	if peak := pcmPeak(pcm); peak != 1 {
+		t.Errorf("pcmPeak() = %v, want 1", peak)
+	}
+
+	for _, tc := range []struct {
+		peak, want float32
+	}{
+		{0, 0},
+		{0.0001, 0}, // -80 dBFS
+		{0.001, 0},  // -60 dBFS
+		{0.5, 0.9},  // About -6 dBFS
+		{1, 1},
+	} {
+		if got := meterLevel(tc.peak); got < tc.want-0.01 || got > tc.want+0.01 {
+			t.Errorf("meterLevel(%v) = %v, want %v", tc.peak, got, tc.want)
+		}
+	}
+}
+
+// TestRecordingFileName tests naming recordings by their start time
+func TestRecordingFileName(t *testing.T) {
+	start := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	got := recordingFileName("/tmp/out", start)
+	if want := filepath.Join("/tmp/out", "recording_20240305-140709.wav"); got != want {
+		t.Errorf("recordingFileName() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// RecordingPart is one of the files a recording was joined from
+type RecordingPart struct {
+	File     string  `json:"file"`     // File name
+	Start    float64 `json:"start"`    // Where the part starts in the joined recording, in seconds
+	Duration float64 `json:"duration"` // Seconds
+}
+
+// JoinRecording concatenates the files of one recording (e.g. part1.mp3 and part2.mp3,
+// or the tracks of an audio CD) into a 16kHz mono WAV, so they are transcribed as one
+// job with continuous timestamps. The joined file is named after the first part, in a
+// temporary directory the caller removes with RemoveTempFile. It returns the parts with where each starts.
+func JoinRecording(paths []string, progressCallback func(string)) (string, []RecordingPart, error) {
+	if len(paths) < 2 {
+		return "", nil, fmt.Errorf("joining needs at least two files")
+	}
+
+	parts := make([]RecordingPart, len(paths))
+	offset := 0.0
+	for i, path := range paths {
+		duration, err := getAudioDuration(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot read the length of %s: %v", filepath.Base(path), err)
+		}
+		parts[i] = RecordingPart{File: filepath.Base(path), Start: offset, Duration: duration}
+		offset += duration
+	}
+
+	dir, err := CreateTempDir("ivrit-ai-join-*")
+	if err != nil {
+		return "", nil, err
+	}
+	base := filepath.Base(paths[0])
+	joinedPath := filepath.Join(dir, strings.TrimSuffix(base, filepath.Ext(base))+".wav")
+
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Joining %d files (%s)...", len(paths), FormatTimestamp(offset, true)[:8]))
+	}
+	cmd := exec.Command(ffmpegPath(), joinArgs(paths, joinedPath)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		RemoveTempFile(dir)
+		return "", nil, fmt.Errorf("ffmpeg failed to join the files: %v\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return joinedPath, parts, nil
+}
+
+// joinArgs builds the ffmpeg arguments joining the inputs with the concat filter.
+// Unlike the concat demuxer, the filter accepts parts in different formats, sample
+// rates and channel layouts, which are converted to 16kHz mono first.
+func joinArgs(paths []string, outputPath string) []string {
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	var filter, inputs strings.Builder
+	for i, path := range paths {
+		args = append(args, "-i", path)
+		fmt.Fprintf(&filter, "[%d:a:0]aresample=16000,aformat=sample_fmts=s16:channel_layouts=mono[a%d];", i, i)
+		fmt.Fprintf(&inputs, "[a%d]", i)
+	}
+	fmt.Fprintf(&filter, "%sconcat=n=%d:v=0:a=1[out]", inputs.String(), len(paths))
+	return append(args,
+		"-filter_complex", filter.String(),
+		"-map", "[out]",
+		"-acodec", "pcm_s16le",
+		"-y",
+		outputPath,
+	)
+}
+
+// RecordingPartsInDir lists the audio and video files in a directory, e.g. the tracks
+// of an audio CD, in natural order so that "Track 10" follows "Track 9"
+func RecordingPartsInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(entry.Name()), "."))
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !containsString(mediaExtensions, ext) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Slice(paths, func(i, j int) bool { return naturalLess(filepath.Base(paths[i]), filepath.Base(paths[j])) })
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no audio files in %s", dir)
+	}
+	return paths, nil
+}
+
+// naturalLess compares file names case-insensitively, comparing runs of digits by
+// their value
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si, sj := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			x := strings.TrimLeft(string(ra[si:i]), "0")
+			y := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(x) != len(y) {
+				return len(x) < len(y)
+			}
+			if x != y {
+				return x < y
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return ra[i] < rb[j]
+		}
+		i++
+		j++
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+// partFileNames returns the file names of the parts, in order
+func partFileNames(parts []RecordingPart) []string {
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = part.File
+	}
+	return names
+}
+
+// FormatRecordingParts lists the parts of a joined recording with where each starts:
+// a Markdown table for the markdown format and plain text otherwise
+func FormatRecordingParts(parts []RecordingPart, format string) string {
+	var b strings.Builder
+	if format == "markdown" {
+		b.WriteString("## Recording Parts\n\n")
+		b.WriteString("| Part | Starts at | File |\n")
+		b.WriteString("|---|---|---|\n")
+		for i, part := range parts {
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", i+1, FormatTimestamp(part.Start, true)[:8], strings.ReplaceAll(part.File, "|", `\|`))
+		}
+		return b.String()
+	}
+
+	b.WriteString("Recording parts\n\n")
+	for i, part := range parts {
+		fmt.Fprintf(&b, "%d. [%s] %s\n", i+1, FormatTimestamp(part.Start, true)[:8], part.File)
+	}
+	return b.String()
+}
+
+// MarkRecordingParts notes the file boundaries of a joined recording in a transcript:
+// a list of the parts at the end of text and markdown, and a NOTE before the first
+// cue of each part in WebVTT. Other formats can't hold notes, so they are returned
+// unchanged with false (JSON output records the parts in its manifest).
+func MarkRecordingParts(output string, parts []RecordingPart, format string) (string, bool) {
+	switch format {
+	case "text", "markdown":
+		return strings.TrimRight(output, "\n") + "\n\n" + FormatRecordingParts(parts, format), true
+	case "vtt":
+		return markVTTParts(output, parts), true
+	}
+	return output, false
+}
+
+// markVTTParts inserts a NOTE block naming each part before its first cue
+func markVTTParts(output string, parts []RecordingPart) string {
+	blocks := strings.Split(strings.TrimRight(output, "\n"), "\n\n")
+	marked := make([]string, 0, len(blocks)+len(parts))
+	next := 0
+	for _, block := range blocks {
+		if start, ok := vttCueStart(block); ok {
+			// Part lengths are approximate, so a cue starting just before a boundary opens the next part
+			for next < len(parts) && parts[next].Start <= start+0.5 {
+				marked = append(marked, fmt.Sprintf("NOTE Part %d: %s", next+1, parts[next].File))
+				next++
+			}
+		}
+		marked = append(marked, block)
+	}
+	return strings.Join(marked, "\n\n") + "\n"
+}
+
+// vttCueStart returns the start time of a WebVTT cue block
+func vttCueStart(block string) (float64, bool) {
+	for _, line := range strings.Split(block, "\n") {
+		if timing, _, ok := strings.Cut(line, " --> "); ok {
+			seconds, err := ParseTimecode(strings.TrimSpace(timing))
+			return seconds, err == nil
+		}
+	}
+	return 0, false
+}
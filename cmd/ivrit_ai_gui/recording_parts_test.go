@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRecordingPartsInDir tests listing the tracks of a folder in order
+func TestRecordingPartsInDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Track 10.aiff", "Track 2.aiff", "track 1.aiff", "cover.jpg", ".Track 3.aiff"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("audio"), 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "Track 0.wav"), 0755)
+
+	paths, err := RecordingPartsInDir(dir)
+	if err != nil {
+		t.Fatalf("RecordingPartsInDir() error: %v", err)
+	}
+	var names []string
+	for _, path := range paths {
+		names = append(names, filepath.Base(path))
+	}
+	if strings.Join(names, ", ") != "track 1.aiff, Track 2.aiff, Track 10.aiff" {
+		t.Errorf("Unexpected parts: %v", names)
+	}
+
+	if _, err := RecordingPartsInDir(t.TempDir()); err == nil {
+		t.Error("Expected an error for a folder without audio files")
+	}
+}
+
+// TestNaturalLess tests ordering file names with numbers
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"part2.mp3", "part10.mp3", true},
+		{"part10.mp3", "part2.mp3", false},
+		{"part02.mp3", "part2.mp3", false},
+		{"Part1.mp3", "part2.mp3", true},
+		{"part.mp3", "part1.mp3", true},
+		{"a.mp3", "a.mp3", false},
+	}
+
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.expected {
+			t.Errorf("naturalLess(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+// TestJoinArgs tests the ffmpeg command joining the parts
+func TestJoinArgs(t *testing.T) {
+	args := strings.Join(joinArgs([]string{"a.mp3", "b.m4a"}, "out.wav"), " ")
+	for _, expected := range []string{"-i a.mp3 -i b.m4a", "[a0][a1]concat=n=2:v=0:a=1[out]", "-map [out]", "out.wav"} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in the ffmpeg arguments: %s", expected, args)
+		}
+	}
+}
+
+// TestMarkRecordingParts tests noting the file boundaries in transcripts
+func TestMarkRecordingParts(t *testing.T) {
+	parts := []RecordingPart{
+		{File: "part1.mp3", Start: 0, Duration: 10.2},
+		{File: "part2.mp3", Start: 10.2, Duration: 5},
+	}
+	segments := []Segment{
+		{Start: 0, End: 4, Text: "שלום"},
+		{Start: 4, End: 9.9, Text: "מה נשמע"},
+		{Start: 10, End: 12, Text: "המשך"},
+	}
+
+	text, ok := MarkRecordingParts(FormatOutput(segments, "text", "original"), parts, "text")
+	if !ok || !strings.Contains(text, "1. [00:00:00] part1.mp3\n2. [00:00:10] part2.mp3") {
+		t.Errorf("Expected the parts listed in text output, got:\n%s", text)
+	}
+
+	vtt, ok := MarkRecordingParts(FormatOutput(segments, "vtt", "original"), parts, "vtt")
+	if !ok {
+		t.Fatal("Expected the parts noted in WebVTT output")
+	}
+	first := strings.Index(vtt, "NOTE Part 1: part1.mp3\n\n00:00:00.000 -->")
+	second := strings.Index(vtt, "NOTE Part 2: part2.mp3\n\n00:00:10.000 -->")
+	if !strings.HasPrefix(vtt, "WEBVTT\n\n") || first < 0 || second < first {
+		t.Errorf("Expected a NOTE before the first cue of each part, got:\n%s", vtt)
+	}
+
+	if srt, ok := MarkRecordingParts("1\n", parts, "srt"); ok || srt != "1\n" {
+		t.Error("Expected SRT output unchanged")
+	}
+}
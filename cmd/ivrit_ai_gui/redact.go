@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RedactOptions configures the redaction pass that writes a masked copy of each transcript
+type RedactOptions struct {
+	Enabled   bool     `json:"enabled"`             // Also write <output>_redacted.<ext>
+	Words     []string `json:"words,omitempty"`     // Words and phrases to mask (names, profanity)
+	WordsFile string   `json:"wordsFile,omitempty"` // File with one word or phrase per line (# starts a comment)
+}
+
+// Masks replacing redacted text
+const (
+	maskPhone = "[PHONE]"
+	maskID    = "[ID]"
+	maskEmail = "[EMAIL]"
+	maskWord  = "[REDACTED]"
+)
+
+// hebrewPrefixes are the one-letter prefixes (ו, ה, ב, כ, ל, מ, ש) written attached to
+// Hebrew words, so "ולמשה" still matches the listed word "משה"
+const hebrewPrefixes = "[והבכלמש]{0,3}"
+
+// redactRule masks every standalone match of pattern. A match is standalone when the
+// characters on either side aren't part of it, e.g. no digit right before a phone number.
+type redactRule struct {
+	pattern *regexp.Regexp
+	mask    string
+	partOf  func(rune) bool
+}
+
+// Redactor masks phone numbers, ID numbers, email addresses and listed words in transcripts
+type Redactor struct {
+	rules []redactRule
+}
+
+// NewRedactor creates a redactor that also masks the given words and phrases
+// (case-insensitive, with or without Hebrew prefixes)
+func NewRedactor(words []string) *Redactor {
+	isDigit := func(r rune) bool { return unicode.IsDigit(r) }
+
+	r := &Redactor{rules: []redactRule{
+		{regexp.MustCompile(`[A-Za-z0-9][A-Za-z0-9._%+-]*@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`), maskEmail, isWordRune},
+		// Israeli mobile and landline numbers (050-123-4567, 02-1234567, +972 52 123 4567)
+		// and other international numbers
+		{regexp.MustCompile(`(?:\+972[-\s]?|0)(?:5\d|7\d|[23489])[-\s]?\d{3}[-\s]?\d{4}|\+\d{1,3}(?:[-\s]?\d{2,4}){2,4}`), maskPhone, isDigit},
+		// Israeli ID numbers (9 digits, sometimes with the check digit set apart)
+		{regexp.MustCompile(`\d{8}-?\d`), maskID, isDigit},
+	}}
+
+	var quoted []string
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, strings.ReplaceAll(regexp.QuoteMeta(word), " ", `\s+`))
+		}
+	}
+	if len(quoted) > 0 {
+		// Longest first, so phrases win over the words they contain
+		sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+		pattern := `(?i)(` + hebrewPrefixes + `)(?:` + strings.Join(quoted, "|") + `)`
+		r.rules = append(r.rules, redactRule{regexp.MustCompile(pattern), maskWord, isWordRune})
+	}
+	return r
+}
+
+// isWordRune reports whether r is part of a word: a letter, digit or mark (niqqud)
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// Redact masks sensitive text and returns the number of items masked
+func (r *Redactor) Redact(text string) (string, int) {
+	count := 0
+	for _, rule := range r.rules {
+		var b strings.Builder
+		last := 0
+		for _, m := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			before, _ := utf8.DecodeLastRuneInString(text[:start])
+			after, _ := utf8.DecodeRuneInString(text[end:])
+			if (start > 0 && rule.partOf(before)) || (end < len(text) && rule.partOf(after)) {
+				continue
+			}
+			// Keep a Hebrew prefix matched by the word rule
+			maskStart := start
+			if len(m) > 2 && m[2] >= 0 {
+				maskStart = m[3]
+			}
+			b.WriteString(text[last:maskStart])
+			b.WriteString(rule.mask)
+			last = end
+			count++
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text, count
+}
+
+// RedactSegments returns masked copies of segments and the number of items masked.
+// Decoder tokens are dropped, since they hold the unmasked text.
+func (r *Redactor) RedactSegments(segments []Segment) ([]Segment, int) {
+	redacted := make([]Segment, len(segments))
+	total := 0
+	for i, seg := range segments {
+		var n int
+		seg.Tokens = nil
+		seg.Text, n = r.Redact(seg.Text)
+		if seg.Original != "" {
+			// Count what was said, not its translation too
+			seg.Original, n = r.Redact(seg.Original)
+			seg.Translation, _ = r.Redact(seg.Translation)
+		}
+		if seg.Transliteration != "" {
+			// Listed words can't be found in Latin letters, so a transliteration of
+			// masked text is dropped rather than left to give the words away
+			if n > 0 {
+				seg.Transliteration = ""
+			} else {
+				seg.Transliteration, _ = r.Redact(seg.Transliteration)
+			}
+		}
+		total += n
+		redacted[i] = seg
+	}
+	return redacted, total
+}
+
+// RedactReview returns a copy of a consensus review with both readings masked
+func (r *Redactor) RedactReview(review ConsensusReview) ConsensusReview {
+	disagreements := make([]Disagreement, len(review.Disagreements))
+	for i, d := range review.Disagreements {
+		d.First, _ = r.Redact(d.First)
+		d.Second, _ = r.Redact(d.Second)
+		disagreements[i] = d
+	}
+	review.Disagreements = disagreements
+	return review
+}
+
+// WordList returns the configured words plus those in the words file
+func (o RedactOptions) WordList() ([]string, error) {
+	words := append([]string{}, o.Words...)
+	if o.WordsFile == "" {
+		return words, nil
+	}
+
+	file, err := os.Open(o.WordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read redaction word list: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// redactedFileName derives the redacted copy's path from the transcript's path
+func redactedFileName(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+	return strings.TrimSuffix(outputPath, ext) + "_redacted" + ext
+}
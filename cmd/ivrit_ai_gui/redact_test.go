@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestRedact tests masking phone numbers, ID numbers, emails and listed words
+func TestRedact(t *testing.T) {
+	redactor := NewRedactor([]string{"משה כהן", "דני", "damn"})
+
+	tests := []struct {
+		text     string
+		expected string
+		count    int
+	}{
+		{"תתקשר אליי ל-050-123-4567 מחר", "תתקשר אליי ל-[PHONE] מחר", 1},
+		{"המספר 0521234567, או 02-6543210", "המספר [PHONE], או [PHONE]", 2},
+		{"call +972 52 123 4567 now", "call [PHONE] now", 1},
+		{"תעודת זהות 123456782 בבקשה", "תעודת זהות [ID] בבקשה", 1},
+		{"מספר הזהות שלי 12345678-2.", "מספר הזהות שלי [ID].", 1},
+		{"שלחו ל-moshe.cohen@example.co.il.", "שלחו ל-[EMAIL].", 1},
+		{"דיברתי עם משה  כהן ועם דני", "דיברתי עם [REDACTED] ועם [REDACTED]", 2},
+		{"ולדני אמרתי", "ול[REDACTED] אמרתי", 1},
+		{"Damn it", "[REDACTED] it", 1},
+		{"דנית הגיעה", "דנית הגיעה", 0},                                   // Another name that starts the same
+		{"קניתי ב-1,500 שקל בשנת 2024", "קניתי ב-1,500 שקל בשנת 2024", 0}, // Ordinary numbers stay
+		{"12345678901234", "12345678901234", 0},                           // Too long for a phone or ID number
+	}
+	for _, tt := range tests {
+		result, count := redactor.Redact(tt.text)
+		if result != tt.expected || count != tt.count {
+			t.Errorf("Redact(%q) = %q, %d, expected %q, %d", tt.text, result, count, tt.expected, tt.count)
+		}
+	}
+}
+
+// TestRedactSegments tests that translated segments are masked in both languages
+func TestRedactSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Original: "הטלפון של דני 0501234567", Translation: "Dani's phone is 0501234567", Text: "Dani's phone is 0501234567",
+			Tokens: []Token{{Text: "0501234567"}}},
+	}
+
+	redacted, count := NewRedactor([]string{"דני", "Dani"}).RedactSegments(segments)
+	expected := Segment{Start: 0, End: 2, Original: "הטלפון של [REDACTED] [PHONE]", Translation: "[REDACTED]'s phone is [PHONE]", Text: "[REDACTED]'s phone is [PHONE]"}
+	if !reflect.DeepEqual(redacted[0], expected) {
+		t.Errorf("RedactSegments() = %+v, expected %+v", redacted[0], expected)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 items masked, got %d", count)
+	}
+	if segments[0].Original != "הטלפון של דני 0501234567" {
+		t.Error("Original segments should not be modified")
+	}
+}
+
+// TestRedactWordList tests combining configured words with a words file
+func TestRedactWordList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	os.WriteFile(path, []byte("# Names\nמשה כהן\n\n  דני  \n"), 0644)
+
+	words, err := RedactOptions{Words: []string{"damn"}, WordsFile: path}.WordList()
+	if err != nil {
+		t.Fatalf("WordList() error: %v", err)
+	}
+	if expected := []string{"damn", "משה כהן", "דני"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("WordList() = %q, expected %q", words, expected)
+	}
+
+	if _, err := (RedactOptions{WordsFile: filepath.Join(t.TempDir(), "missing.txt")}).WordList(); err == nil {
+		t.Error("Expected error for missing words file")
+	}
+	if name := redactedFileName(filepath.Join("out", "talk.srt")); name != filepath.Join("out", "talk_redacted.srt") {
+		t.Errorf("redactedFileName() = %q", name)
+	}
+}
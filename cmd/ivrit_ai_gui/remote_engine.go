@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transcription engines, selected with -engine or "engine" in config.json
+const (
+	EngineLocal         = "local"          // whisper.cpp linked into the app
+	EngineLocalCPU      = "local-cpu"      // The same, without GPU acceleration (Metal, CUDA, Vulkan)
+	EngineWhisperServer = "whisper-server" // whisper.cpp's HTTP server (examples/server)
+	EngineFasterWhisper = "faster-whisper" // A faster-whisper (CTranslate2) sidecar with an OpenAI-style API
+	EngineRunPod        = "runpod"         // ivrit.ai's hosted transcription or another RunPod serverless endpoint
+)
+
+var validEngines = []string{EngineLocal, EngineLocalCPU, EngineWhisperServer, EngineFasterWhisper, EngineRunPod}
+
+// Where each self-hosted engine listens by default
+var defaultEngineURLs = map[string]string{
+	EngineWhisperServer: "http://127.0.0.1:8080",
+	EngineFasterWhisper: "http://127.0.0.1:8000",
+}
+
+// isLocalEngine reports whether an engine runs whisper.cpp in the app ("" = the default, local)
+func isLocalEngine(engine string) bool {
+	return engine == "" || engine == EngineLocal || engine == EngineLocalCPU
+}
+
+// NewTranscriptionEngine creates the configured engine for a model, or with fallback
+// engines configured, a FallbackEngine trying them in order
+func NewTranscriptionEngine(cfg AppConfig, modelID string, progressCallback func(string, int)) (TranscriptionEngine, error) {
+	if len(cfg.EngineFallback) > 0 {
+		return NewFallbackEngine(cfg, modelID, progressCallback), nil
+	}
+	return newEngine(cfg, cfg.Engine, modelID, progressCallback)
+}
+
+// newEngine creates one engine for a model. Local engines download the model first;
+// remote engines transcribe with the server's copy.
+func newEngine(cfg AppConfig, kind, modelID string, progressCallback func(string, int)) (TranscriptionEngine, error) {
+	if !isLocalEngine(kind) {
+		engine, err := NewRemoteEngine(kind, cfg.EngineAddress(), cfg.EngineKey)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetMaxWait(time.Duration(cfg.EngineMaxWait) * time.Minute)
+		return engine, nil
+	}
+	modelPath, err := GetModelPath(modelID, progressCallback)
+	if err != nil {
+		return nil, err
+	}
+	var engine *WhisperCGOEngine
+	if kind == EngineLocalCPU {
+		engine, err = NewWhisperCGOEngineCPU(modelPath)
+	} else {
+		engine, err = NewWhisperCGOEngine(modelPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize whisper engine: %v", err)
+	}
+	engine.SetSilenceTrimming(cfg.TrimSilence)
+	return engine, nil
+}
+
+// engineModelPath returns the model file an engine ran, "" for remote engines
+func engineModelPath(engine TranscriptionEngine) string {
+	switch engine := engine.(type) {
+	case *WhisperCGOEngine:
+		return engine.modelPath
+	case *FallbackEngine:
+		return engineModelPath(engine.activeEngine())
+	}
+	return ""
+}
+
+// engineDescription describes the engine that ran for manifests, e.g. "local-cpu" or
+// "whisper-server http://gpu-box:8080" ("" for the default local engine)
+func engineDescription(engine TranscriptionEngine) string {
+	switch engine := engine.(type) {
+	case *WhisperCGOEngine:
+		if engine.cpuOnly {
+			return EngineLocalCPU
+		}
+	case *RemoteEngine:
+		return engine.kind + " " + engine.baseURL
+	case *FallbackEngine:
+		return engineDescription(engine.activeEngine())
+	}
+	return ""
+}
+
+// ranLocally reports whether the last transcription ran in the app, so its timing
+// describes this machine
+func ranLocally(engine TranscriptionEngine) bool {
+	return engineModelPath(engine) != ""
+}
+
+// RemoteEngine implements TranscriptionEngine by uploading the audio to a
+// transcription server. Audio is converted to 16kHz mono WAV (and cut to the time
+// range) locally first, which keeps uploads small and works with servers that
+// can't decode other formats.
+type RemoteEngine struct {
+	kind       string // EngineWhisperServer or EngineFasterWhisper
+	baseURL    string
+	apiKey     string // Sent as a bearer token when set
+	client     *http.Client
+	poll       time.Duration // How often RunPod jobs are checked
+	maxWait    time.Duration // How long a RunPod job may take before it's cancelled
+	ctx        context.Context
+	abort      context.CancelFunc // Cancels ctx, and with it every request
+	timeRange  TimeRange
+	decode     DecodeOptions
+	dumpTokens bool
+}
+
+// NewRemoteEngine creates a client for a remote engine at baseURL ("" = its default).
+// For RunPod, baseURL is the endpoint's address or ID.
+func NewRemoteEngine(kind, baseURL, apiKey string) (*RemoteEngine, error) {
+	switch {
+	case kind == EngineRunPod:
+		if baseURL = runPodEndpointURL(baseURL); baseURL == "" {
+			return nil, fmt.Errorf("this build has no hosted ivrit.ai endpoint; set the engine URL to your RunPod endpoint ID")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("the runpod engine needs an API key (IVRIT_ENGINE_KEY)")
+		}
+	case defaultEngineURLs[kind] == "":
+		return nil, fmt.Errorf("unknown remote engine %q", kind)
+	case baseURL == "":
+		baseURL = defaultEngineURLs[kind]
+	}
+	// No overall timeout: long recordings legitimately take a long time
+	ctx, abort := context.WithCancel(context.Background())
+	return &RemoteEngine{kind: kind, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, client: httpClient(0),
+		poll: runPodPollInterval, maxWait: runPodMaxWait, ctx: ctx, abort: abort}, nil
+}
+
+// SupportsModel reports whether the engine can run a model. whisper.cpp's server runs
+// the model it was started with, whatever is asked for; faster-whisper loads the
+// model named in each request.
+func (e *RemoteEngine) SupportsModel(modelID string) bool {
+	return true
+}
+
+// SetTokenDump requests per-token data, which remote engines don't provide
+func (e *RemoteEngine) SetTokenDump(enabled bool) {
+	e.dumpTokens = enabled
+}
+
+// SetTimeRange restricts transcription to part of the audio (zero value = whole file)
+func (e *RemoteEngine) SetTimeRange(timeRange TimeRange) {
+	e.timeRange = timeRange
+}
+
+// SetParallelChunks has no effect: the server decides how to use its hardware
+func (e *RemoteEngine) SetParallelChunks(n int) {}
+
+// SetMaxWait sets how long a RunPod job may take, queueing included, before it's
+// cancelled (0 = the default, an hour)
+func (e *RemoteEngine) SetMaxWait(maxWait time.Duration) {
+	if maxWait <= 0 {
+		maxWait = runPodMaxWait
+	}
+	e.maxWait = maxWait
+}
+
+// SetDecodeOptions sets the decoding parameters sent with each request
+func (e *RemoteEngine) SetDecodeOptions(decode DecodeOptions) {
+	e.decode = decode
+}
+
+// LastResultCached is always false: remote results aren't cached
+func (e *RemoteEngine) LastResultCached() bool {
+	return false
+}
+
+// Abort cancels the running request, and any later one. A RunPod job is cancelled
+// on the endpoint too.
+func (e *RemoteEngine) Abort() {
+	e.abort()
+}
+
+// Close releases nothing; the server keeps its model loaded
+func (e *RemoteEngine) Close() {}
+
+// Transcribe uploads the audio and returns the server's segments
+func (e *RemoteEngine) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	if e.dumpTokens {
+		return nil, fmt.Errorf("the tokens format needs the local engine; %s doesn't return per-token data", e.kind)
+	}
+	if e.ctx.Err() != nil {
+		return nil, ErrInterrupted
+	}
+
+	wavPath, trimmed, err := prepareAudioFile(audioPath, e.timeRange, progressCallback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare audio: %v", err)
+	}
+	if wavPath != audioPath {
+		defer RemoveTempFile(wavPath)
+	}
+
+	segments, err := e.transcribeFile(wavPath, modelID, progressCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cut recording's timestamps start at zero; an uncut WAV is filtered instead
+	if trimmed {
+		for i := range segments {
+			segments[i].Start += e.timeRange.Start
+			segments[i].End += e.timeRange.Start
+		}
+	} else if e.timeRange.IsSet() {
+		segments = segmentsInRange(segments, e.timeRange)
+	}
+
+	if segmentCallback != nil {
+		for _, segment := range segments {
+			segmentCallback(segment)
+		}
+	}
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Transcription complete (%d segments)", len(segments)))
+	}
+	return segments, nil
+}
+
+// transcribeFile sends prepared audio to the engine and returns its segments
+func (e *RemoteEngine) transcribeFile(wavPath, modelID string, progressCallback func(string)) ([]Segment, error) {
+	if e.kind == EngineRunPod {
+		// Cloud uploads are compressed: the audio travels inline in the job request
+		if progressCallback != nil {
+			progressCallback("Compressing audio for upload...")
+		}
+		audio, err := encodeUploadAudio(wavPath)
+		if err != nil {
+			return nil, err
+		}
+		return e.transcribeRunPod(audio, modelID, progressCallback)
+	}
+
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Transcribing on %s...", e.baseURL))
+	}
+	body, err := e.post(wavPath, modelID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := parseVerboseJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected response from %s: %v", e.kind, err)
+	}
+	return segments, nil
+}
+
+// post sends the audio with the engine's request fields and returns the response body.
+// The form is streamed, so long recordings aren't held in memory.
+func (e *RemoteEngine) post(wavPath, modelID string) ([]byte, error) {
+	audio, err := os.Open(wavPath)
+	if err != nil {
+		return nil, err
+	}
+	defer audio.Close()
+
+	fields, endpoint := e.requestFields(modelID)
+	pipeReader, pipeWriter := io.Pipe()
+	form := multipart.NewWriter(pipeWriter)
+	go func() {
+		pipeWriter.CloseWithError(writeForm(form, audio, fields))
+	}()
+
+	req, err := http.NewRequestWithContext(e.ctx, "POST", e.baseURL+endpoint, pipeReader)
+	if err != nil {
+		pipeReader.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if e.ctx.Err() != nil {
+		return nil, ErrInterrupted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the %s engine at %s: %v", e.kind, e.baseURL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s engine returned %s: %s", e.kind, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// writeForm writes the audio file and fields of a request as multipart form data
+func writeForm(form *multipart.Writer, audio *os.File, fields [][2]string) error {
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", filepath.Base(audio.Name()))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return err
+	}
+	return form.Close()
+}
+
+// requestFields returns the form fields and endpoint of a transcription request
+func (e *RemoteEngine) requestFields(modelID string) ([][2]string, string) {
+	fields := [][2]string{
+		{"language", "he"},
+		{"response_format", "verbose_json"},
+		{"temperature", strconv.FormatFloat(e.decode.Temperature, 'f', -1, 64)},
+	}
+	if e.decode.InitialPrompt != "" {
+		fields = append(fields, [2]string{"prompt", e.decode.InitialPrompt})
+	}
+
+	if e.kind == EngineWhisperServer {
+		if e.decode.BeamSize > 1 {
+			fields = append(fields, [2]string{"beam_size", strconv.Itoa(e.decode.BeamSize)})
+		}
+		if e.decode.MaxSegmentChars > 0 {
+			fields = append(fields, [2]string{"max_len", strconv.Itoa(whisperMaxLen(e.decode.MaxSegmentChars))}, [2]string{"split_on_word", "true"})
+		}
+		return fields, "/inference"
+	}
+	// OpenAI-style servers name the model in each request
+	return append(fields, [2]string{"model", fasterWhisperModel(modelID)}), "/v1/audio/transcriptions"
+}
+
+// fasterWhisperModel returns the CTranslate2 model a faster-whisper server loads for
+// a model, from its configuration, or the model ID itself
+func fasterWhisperModel(modelID string) string {
+	if info, ok := loadModelsConfig()[modelID]; ok && info.FasterWhisperID != "" {
+		return info.FasterWhisperID
+	}
+	return modelID
+}
+
+// parseVerboseJSON reads the segments of a verbose_json response, the format both
+// whisper.cpp's server and OpenAI-style servers return (times in seconds)
+func parseVerboseJSON(body []byte) ([]Segment, error) {
+	var response struct {
+		Text     string `json:"text"`
+		Segments []struct {
+			Start        float64 `json:"start"`
+			End          float64 `json:"end"`
+			Text         string  `json:"text"`
+			NoSpeechProb float64 `json:"no_speech_prob"` // OpenAI-style servers only
+		} `json:"segments"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	segments := []Segment{}
+	for _, s := range response.Segments {
+		segments = append(segments, LabelEvent(Segment{Start: s.Start, End: s.End, Text: s.Text}, s.NoSpeechProb))
+	}
+	// Servers without verbose_json support answer with the text alone
+	if len(segments) == 0 && strings.TrimSpace(response.Text) != "" {
+		return nil, fmt.Errorf("no segment timestamps in the response (does the server support verbose_json?)")
+	}
+	return segments, nil
+}
+
+// segmentsInRange keeps the segments overlapping a time range
+func segmentsInRange(segments []Segment, timeRange TimeRange) []Segment {
+	var kept []Segment
+	for _, segment := range segments {
+		if segment.End <= timeRange.Start || (timeRange.End > 0 && segment.Start >= timeRange.End) {
+			continue
+		}
+		kept = append(kept, segment)
+	}
+	return kept
+}
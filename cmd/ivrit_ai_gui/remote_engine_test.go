@@ -0,0 +1,195 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testVerboseJSON = `{"text": "שלום עולם", "segments": [
+	{"start": 0.0, "end": 2.5, "text": "שלום"},
+	{"start": 2.5, "end": 4.0, "text": "עולם"}
+]}`
+
+// remoteTestServer answers transcription requests with testVerboseJSON, recording
+// the path and form fields of the last request
+func remoteTestServer(t *testing.T, path *string, fields map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.Path
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Invalid form: %v", err)
+			return
+		}
+		for key := range r.MultipartForm.Value {
+			fields[key] = r.FormValue(key)
+		}
+		if file, _, err := r.FormFile("file"); err != nil {
+			t.Errorf("No audio uploaded: %v", err)
+		} else {
+			data, _ := io.ReadAll(file)
+			fields["file"] = string(data[:4])
+		}
+		fields["auth"] = r.Header.Get("Authorization")
+		io.WriteString(w, testVerboseJSON)
+	}))
+}
+
+// testCompliantWAV writes a short 16kHz mono WAV, which is uploaded without conversion
+func testCompliantWAV(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	writeTestWAV(t, path, 1, 1, 16000, 16, make([]byte, 3200))
+	return path
+}
+
+// TestRemoteEngineWhisperServer tests a request to whisper.cpp's server
+func TestRemoteEngineWhisperServer(t *testing.T) {
+	var path string
+	fields := map[string]string{}
+	server := remoteTestServer(t, &path, fields)
+	defer server.Close()
+
+	engine, err := NewRemoteEngine(EngineWhisperServer, server.URL+"/", "")
+	if err != nil {
+		t.Fatalf("NewRemoteEngine() error: %v", err)
+	}
+	engine.SetDecodeOptions(DecodeOptions{BeamSize: 5, InitialPrompt: "ישיבת צוות", MaxSegmentChars: 40})
+
+	var streamed []Segment
+	segments, err := engine.Transcribe(testCompliantWAV(t), "turbo", 4, nil, func(s Segment) { streamed = append(streamed, s) })
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if path != "/inference" {
+		t.Errorf("Expected /inference, got %s", path)
+	}
+	if fields["response_format"] != "verbose_json" || fields["language"] != "he" || fields["beam_size"] != "5" || fields["prompt"] != "ישיבת צוות" {
+		t.Errorf("Unexpected request fields: %v", fields)
+	}
+	if fields["max_len"] != "72" || fields["split_on_word"] != "true" {
+		t.Errorf("Expected the segment length in bytes, split at words, got %v", fields)
+	}
+	if _, ok := fields["model"]; ok {
+		t.Errorf("whisper-server requests shouldn't name a model, got %q", fields["model"])
+	}
+	if fields["file"] != "RIFF" || fields["auth"] != "" {
+		t.Errorf("Expected the WAV uploaded without credentials, got %v", fields)
+	}
+	if len(segments) != 2 || segments[1].Text != "עולם" || segments[1].End != 4.0 {
+		t.Errorf("Unexpected segments: %+v", segments)
+	}
+	if len(streamed) != 2 {
+		t.Errorf("Expected 2 segments streamed, got %d", len(streamed))
+	}
+}
+
+// TestRemoteEngineFasterWhisper tests a request to an OpenAI-style faster-whisper server
+func TestRemoteEngineFasterWhisper(t *testing.T) {
+	var path string
+	fields := map[string]string{}
+	server := remoteTestServer(t, &path, fields)
+	defer server.Close()
+
+	engine, err := NewRemoteEngine(EngineFasterWhisper, server.URL, "secret-key")
+	if err != nil {
+		t.Fatalf("NewRemoteEngine() error: %v", err)
+	}
+	if _, err := engine.Transcribe(testCompliantWAV(t), "base", 4, nil, nil); err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if path != "/v1/audio/transcriptions" {
+		t.Errorf("Expected /v1/audio/transcriptions, got %s", path)
+	}
+	if fields["model"] != fasterWhisperModel("base") || fields["model"] == "" {
+		t.Errorf("Expected model %q, got %q", fasterWhisperModel("base"), fields["model"])
+	}
+	if fields["auth"] != "Bearer secret-key" {
+		t.Errorf("Expected a bearer token, got %q", fields["auth"])
+	}
+}
+
+// TestRemoteEngineErrors tests that server failures and unsupported options are reported
+func TestRemoteEngineErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	engine, _ := NewRemoteEngine(EngineWhisperServer, server.URL, "")
+	if _, err := engine.Transcribe(testCompliantWAV(t), "turbo", 4, nil, nil); err == nil || !strings.Contains(err.Error(), "model not loaded") {
+		t.Errorf("Expected the server's error, got %v", err)
+	}
+
+	engine.SetTokenDump(true)
+	if _, err := engine.Transcribe(testCompliantWAV(t), "turbo", 4, nil, nil); err == nil {
+		t.Error("Expected an error for the token dump")
+	}
+
+	if _, err := NewRemoteEngine("local", "", ""); err == nil {
+		t.Error("Expected an error for a non-remote engine")
+	}
+}
+
+// TestRemoteEngineTimeRange tests that an uncut recording's segments are filtered to the range
+func TestRemoteEngineTimeRange(t *testing.T) {
+	var path string
+	server := remoteTestServer(t, &path, map[string]string{})
+	defer server.Close()
+
+	engine, _ := NewRemoteEngine(EngineWhisperServer, server.URL, "")
+	engine.SetTimeRange(TimeRange{Start: 3})
+	segments, err := engine.Transcribe(testCompliantWAV(t), "turbo", 4, nil, nil)
+	if err != nil {
+		t.Fatalf("Transcribe() error: %v", err)
+	}
+	if len(segments) != 1 || segments[0].Text != "עולם" {
+		t.Errorf("Expected only the segment after 3s, got %+v", segments)
+	}
+}
+
+// TestParseVerboseJSON tests reading segments from verbose_json responses
+func TestParseVerboseJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		segments int
+		valid    bool
+	}{
+		{"Segments", testVerboseJSON, 2, true},
+		{"Silence", `{"text": "", "segments": []}`, 0, true},
+		{"Text only", `{"text": "שלום"}`, 0, false},
+		{"Error", `{"error": "failed to read audio"}`, 0, false},
+		{"Not JSON", `שלום`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := parseVerboseJSON([]byte(tt.body))
+			if (err == nil) != tt.valid {
+				t.Fatalf("parseVerboseJSON() error = %v, expected valid = %v", err, tt.valid)
+			}
+			if len(segments) != tt.segments {
+				t.Errorf("Expected %d segments, got %d", tt.segments, len(segments))
+			}
+		})
+	}
+}
+
+// TestNewTranscriptionEngineRemote tests that a remote engine is created from the configuration
+func TestNewTranscriptionEngineRemote(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Engine = EngineFasterWhisper
+	engine, err := NewTranscriptionEngine(cfg, "turbo", nil)
+	if err != nil {
+		t.Fatalf("NewTranscriptionEngine() error: %v", err)
+	}
+	remote, ok := engine.(*RemoteEngine)
+	if !ok || remote.baseURL != defaultEngineURLs[EngineFasterWhisper] {
+		t.Errorf("Expected a faster-whisper engine at its default address, got %+v", engine)
+	}
+	if engineModelPath(engine) != "" || engineDescription(engine) != "faster-whisper http://127.0.0.1:8000" {
+		t.Errorf("Unexpected manifest details %q, %q", engineModelPath(engine), engineDescription(engine))
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import "math"
+
+// resampleZeroCrossings is how many zero crossings of the low-pass filter's sinc are
+// kept on each side of a sample: enough to keep speech free of aliasing
+const resampleZeroCrossings = 12
+
+// resampler converts a stream of samples from one sample rate to another with a
+// polyphase windowed-sinc filter, which also removes what lies above the new
+// Nyquist frequency so downsampling (e.g. 44.1kHz to 16kHz) doesn't alias. Samples
+// are pushed a block at a time, so a long recording isn't held at its original rate.
+type resampler struct {
+	up, down int         // Output samples per input sample, as the reduced fraction up/down
+	half     int         // Input samples on each side of an output sample
+	taps     [][]float32 // Filter coefficients for each phase, 2*half of them
+	input    []float32   // Input not yet passed by the filter
+	offset   int64       // Index of input[0] in the stream
+	next     int64       // Index of the next output sample
+	output   []float32
+}
+
+// newResampler returns a resampler from one sample rate to another
+func newResampler(from, to int) *resampler {
+	g := gcd(from, to)
+	r := &resampler{up: to / g, down: from / g}
+	cutoff := math.Min(1, float64(to)/float64(from)) // Of the input's Nyquist frequency
+	r.half = int(math.Ceil(resampleZeroCrossings / cutoff))
+	r.taps = make([][]float32, r.up)
+	for phase := range r.taps {
+		taps := make([]float32, 2*r.half)
+		for k := range taps {
+			// Distance from the output sample to input sample k of its window
+			d := float64(k-r.half+1) - float64(phase)/float64(r.up)
+			window := 0.5 * (1 + math.Cos(math.Pi*d/float64(r.half))) // Hann
+			taps[k] = float32(cutoff * sinc(cutoff*d) * window)
+		}
+		r.taps[phase] = taps
+	}
+	return r
+}
+
+// push adds input samples and filters every output sample they complete
+func (r *resampler) push(samples []float32) {
+	r.input = append(r.input, samples...)
+	r.filter(r.offset + int64(len(r.input)))
+}
+
+// finish filters the last output samples, taking the input to be silent past its
+// end, and returns the output
+func (r *resampler) finish() []float32 {
+	total := r.offset + int64(len(r.input))
+	end := (total*int64(r.up) + int64(r.down) - 1) / int64(r.down)
+	r.filter(total + int64(r.half))
+	if int64(len(r.output)) > end {
+		r.output = r.output[:end]
+	}
+	return r.output
+}
+
+// filter computes the output samples whose window ends before input index available,
+// then drops the input no later output needs
+func (r *resampler) filter(available int64) {
+	for {
+		position := r.next * int64(r.down)
+		n, phase := position/int64(r.up), int(position%int64(r.up))
+		if n+int64(r.half) > available {
+			break
+		}
+		var sum float32
+		for k, tap := range r.taps[phase] {
+			j := n - int64(r.half) + 1 + int64(k) - r.offset
+			if j >= 0 && j < int64(len(r.input)) {
+				sum += r.input[j] * tap
+			}
+		}
+		r.output = append(r.output, sum)
+		r.next++
+	}
+
+	keep := r.next*int64(r.down)/int64(r.up) - int64(r.half) + 1 - r.offset
+	if keep > 0 && keep <= int64(len(r.input)) {
+		r.input = append(r.input[:0], r.input[keep:]...)
+		r.offset += keep
+	}
+}
+
+// sinc is the normalized sinc function, sin(πx)/(πx)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
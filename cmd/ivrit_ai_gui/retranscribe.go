@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// retranscribePadding widens the audio window around a segment being re-transcribed,
+// so words cut off at its edges are heard whole
+const retranscribePadding = 0.25
+
+// RetranscribeWindow returns the audio to re-run whisper on for a segment
+func RetranscribeWindow(seg Segment) TimeRange {
+	return TimeRange{Start: math.Max(0, seg.Start-retranscribePadding), End: seg.End + retranscribePadding}
+}
+
+// RetranscribedText joins the text whisper produced for a segment's window
+func RetranscribedText(result []Segment) string {
+	var texts []string
+	for _, seg := range result {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// ReplaceSegmentText returns seg with its Hebrew text replaced, keeping its timing and
+// speaker. Translated segments also take the new translation, in the same layout:
+// with the original kept, or the translation alone.
+func ReplaceSegmentText(seg Segment, hebrew, translation string) Segment {
+	seg.Tokens = nil
+	switch {
+	case seg.Translation == "":
+		seg.Text = hebrew
+	case seg.Original != "":
+		seg.Original, seg.Text, seg.Translation = hebrew, translation, translation
+	default:
+		seg.Text, seg.Translation = translation, translation
+	}
+	return seg
+}
+
+// ReplaceSegmentTranslation returns seg with its translation replaced by an edit,
+// keeping its Hebrew original, timing and speaker
+func ReplaceSegmentTranslation(seg Segment, translation string) Segment {
+	seg.Text, seg.Translation = translation, translation
+	return seg
+}
+
+// IsTranslated reports whether any segment of a transcript has a translation
+func IsTranslated(segments []Segment) bool {
+	for _, seg := range segments {
+		if seg.Translation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// SegmentAt returns the index of the segment displayed at a rune offset of the output
+// text (such as the editor's caret), or -1 before the first segment. Segment texts
+// appear in order in every output format, so each is searched for after the previous
+// one; the last one starting at or before the offset is the one the offset is in.
+func SegmentAt(display string, segments []Segment, offset int) int {
+	byteOffset := len(display)
+	for i := range display {
+		if offset == 0 {
+			byteOffset = i
+			break
+		}
+		offset--
+	}
+
+	found := -1
+	pos := 0
+	for i, seg := range segments {
+		text := seg.Text
+		if seg.Original != "" {
+			text = seg.Original
+		}
+		needle := strings.TrimSpace(text)
+		if needle == "" {
+			continue
+		}
+		idx := strings.Index(display[pos:], needle)
+		if idx < 0 {
+			continue
+		}
+		start := pos + idx
+		if start > byteOffset {
+			break
+		}
+		found = i
+		pos = start + len(needle)
+	}
+	return found
+}
+
+// segmentPreview shortens a segment's text for a one-line label
+func segmentPreview(seg Segment, maxRunes int) string {
+	text := strings.TrimSpace(seg.Text)
+	if seg.Original != "" {
+		text = strings.TrimSpace(seg.Original)
+	}
+	if utf8.RuneCountInString(text) > maxRunes {
+		text = string([]rune(text)[:maxRunes]) + "..."
+	}
+	return text
+}
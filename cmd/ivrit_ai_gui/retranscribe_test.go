@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSegmentAt tests finding the segment under the cursor in different output formats
+func TestSegmentAt(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום", Speaker: 0},
+		{Start: 2, End: 4, Text: "מה שלומך?", Speaker: 0},
+		{Start: 4, End: 6, Text: "שלום", Speaker: 1},
+	}
+
+	for _, format := range []string{"text", "srt", "vtt", "json", "markdown"} {
+		display := FormatOutput(segments, format, DisplayBilingual)
+		for i, seg := range segments {
+			// Place the cursor inside the i-th occurrence of the segment's text
+			offset := nthIndex(display, seg.Text, countBefore(segments[:i], seg.Text))
+			if got := SegmentAt(display, segments, len([]rune(display[:offset]))+1); got != i {
+				t.Errorf("%s: SegmentAt(segment %d) = %d", format, i, got)
+			}
+		}
+	}
+
+	if got := SegmentAt("Speaker 1: שלום", segments, 0); got != -1 {
+		t.Errorf("Expected -1 before the first segment, got %d", got)
+	}
+}
+
+// nthIndex returns the byte index of the n-th (0-based) occurrence of substr in s
+func nthIndex(s, substr string, n int) int {
+	pos := 0
+	for {
+		idx := strings.Index(s[pos:], substr)
+		if n == 0 {
+			return pos + idx
+		}
+		pos += idx + len(substr)
+		n--
+	}
+}
+
+// countBefore counts the segments with the given text
+func countBefore(segments []Segment, text string) int {
+	n := 0
+	for _, seg := range segments {
+		if seg.Text == text {
+			n++
+		}
+	}
+	return n
+}
+
+// TestReplaceSegmentText tests replacing text while keeping timing, speaker and layout
+func TestReplaceSegmentText(t *testing.T) {
+	hebrew := RetranscribedText([]Segment{{Text: " שלום "}, {Text: ""}, {Text: "לכולם"}})
+	if hebrew != "שלום לכולם" {
+		t.Fatalf("RetranscribedText() = %q", hebrew)
+	}
+
+	plain := ReplaceSegmentText(Segment{Start: 1, End: 2, Text: "שלם", Speaker: 1, Tokens: []Token{{Text: "שלם"}}}, hebrew, "")
+	if plain.Text != hebrew || plain.Start != 1 || plain.End != 2 || plain.Speaker != 1 || plain.Tokens != nil {
+		t.Errorf("Unexpected plain segment: %+v", plain)
+	}
+
+	kept := ReplaceSegmentText(Segment{Original: "שלם", Translation: "Whole", Text: "Whole"}, hebrew, "Hello everyone")
+	if kept.Original != hebrew || kept.Translation != "Hello everyone" || kept.Text != "Hello everyone" {
+		t.Errorf("Unexpected segment with original: %+v", kept)
+	}
+
+	translationOnly := ReplaceSegmentText(Segment{Translation: "Whole", Text: "Whole"}, hebrew, "Hello everyone")
+	if translationOnly.Original != "" || translationOnly.Text != "Hello everyone" {
+		t.Errorf("Unexpected translation-only segment: %+v", translationOnly)
+	}
+
+	edited := ReplaceSegmentTranslation(Segment{Start: 1, Speaker: 2, Original: "שלום", Translation: "Hi", Text: "Hi"}, "Hello")
+	if edited.Original != "שלום" || edited.Translation != "Hello" || edited.Text != "Hello" || edited.Speaker != 2 {
+		t.Errorf("Unexpected edited translation: %+v", edited)
+	}
+	if output := FormatOutput([]Segment{edited}, "srt", DisplayBilingual); !strings.Contains(output, "שלום\nHello") {
+		t.Errorf("Expected the edited translation under the Hebrew, got %q", output)
+	}
+	if IsTranslated([]Segment{plain}) || !IsTranslated([]Segment{plain, edited}) {
+		t.Error("IsTranslated() should find translated segments")
+	}
+
+	if window := RetranscribeWindow(Segment{Start: 0.1, End: 3}); window.Start != 0 || window.End != 3.25 {
+		t.Errorf("RetranscribeWindow() = %+v", window)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RevealFile opens the folder containing path in the system file manager with the
+// file selected (Finder, Explorer, or a file manager supporting FileManager1 on Linux)
+func RevealFile(path string) error {
+	return runFileCommand(revealCommands(runtime.GOOS, path))
+}
+
+// OpenFile opens path with its default application
+func OpenFile(path string) error {
+	return runFileCommand(openCommands(runtime.GOOS, path))
+}
+
+// revealLabel is the platform's name for revealing a file
+func revealLabel(goos string) string {
+	switch goos {
+	case "darwin":
+		return "Reveal in Finder"
+	case "windows":
+		return "Show in Explorer"
+	default:
+		return "Show in Folder"
+	}
+}
+
+// revealCommands returns the commands that reveal path on goos, in order of preference
+func revealCommands(goos, path string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"open", "-R", path}}
+	case "windows":
+		return [][]string{{"explorer", "/select," + path}}
+	default:
+		// Most Linux file managers (Nautilus, Dolphin, Nemo, Caja) implement the
+		// FileManager1 D-Bus interface; otherwise just open the folder. dbus-send
+		// splits array items at commas, which URLs leave unescaped in paths.
+		fileURL := strings.ReplaceAll((&url.URL{Scheme: "file", Path: path}).String(), ",", "%2C")
+		return [][]string{
+			{"dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.FileManager1", "--type=method_call",
+				"/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+				"array:string:" + fileURL, "string:"},
+			{"xdg-open", filepath.Dir(path)},
+		}
+	}
+}
+
+// openCommands returns the commands that open path with its default application on goos
+func openCommands(goos, path string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"open", path}}
+	case "windows":
+		// Unlike "cmd /c start", rundll32 takes the path as is, spaces and all
+		return [][]string{{"rundll32", "url.dll,FileProtocolHandler", path}}
+	default:
+		return [][]string{{"xdg-open", path}}
+	}
+}
+
+// runFileCommand runs the first of commands that succeeds, with env added to the
+// environment
+func runFileCommand(commands [][]string, env ...string) error {
+	var err error
+	for _, command := range commands {
+		cmd := exec.Command(command[0], command[1:]...)
+		if len(env) > 0 {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		if runtime.GOOS == "windows" {
+			// Explorer's exit status is 1 even when it succeeds
+			if err = cmd.Start(); err == nil {
+				go cmd.Wait()
+				return nil
+			}
+			continue
+		}
+		if err = cmd.Run(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s failed: %v", commands[len(commands)-1][0], err)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRevealCommands tests the per-platform commands that reveal a saved file
+func TestRevealCommands(t *testing.T) {
+	tests := []struct {
+		goos     string
+		expected [][]string
+	}{
+		{"darwin", [][]string{{"open", "-R", "/tmp/my talk.srt"}}},
+		{"windows", [][]string{{"explorer", "/select,/tmp/my talk.srt"}}},
+		{"linux", [][]string{
+			{"dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.FileManager1", "--type=method_call",
+				"/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+				"array:string:file:///tmp/my%20talk.srt", "string:"},
+			{"xdg-open", "/tmp"},
+		}},
+	}
+	for _, tt := range tests {
+		if commands := revealCommands(tt.goos, "/tmp/my talk.srt"); !reflect.DeepEqual(commands, tt.expected) {
+			t.Errorf("revealCommands(%q) = %q, expected %q", tt.goos, commands, tt.expected)
+		}
+	}
+	// dbus-send would take a comma for the next array item
+	if commands := revealCommands("linux", "/tmp/Dana, Yossi.srt"); commands[0][7] != "array:string:file:///tmp/Dana%2C%20Yossi.srt" {
+		t.Errorf("Unexpected D-Bus argument %q", commands[0][7])
+	}
+}
+
+// TestOpenCommands tests the per-platform commands that open a file with its default app
+func TestOpenCommands(t *testing.T) {
+	tests := []struct {
+		goos     string
+		expected []string
+	}{
+		{"darwin", []string{"open", "/tmp/my talk.srt"}},
+		{"windows", []string{"rundll32", "url.dll,FileProtocolHandler", "/tmp/my talk.srt"}},
+		{"linux", []string{"xdg-open", "/tmp/my talk.srt"}},
+	}
+	for _, tt := range tests {
+		if commands := openCommands(tt.goos, "/tmp/my talk.srt"); len(commands) != 1 || !reflect.DeepEqual(commands[0], tt.expected) {
+			t.Errorf("openCommands(%q) = %q, expected %q", tt.goos, commands, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reviewHelp explains the answers to the -review prompt
+const reviewHelp = "Enter accepts, e edits the text, s skips (leaves the segment out), q accepts the rest"
+
+// ReviewResult is the outcome of reviewing a transcript in the terminal
+type ReviewResult struct {
+	Segments []Segment // The segments kept, with their edits
+	Edited   int
+	Skipped  int
+}
+
+// ReviewSegments goes through the segments one at a time in the terminal, showing
+// each with its time and speaker for the reviewer to accept, edit or skip. Skipped
+// segments are left out of the output. The rest is accepted on q or at the end of
+// the input, so a review can't hang when there is no terminal.
+func ReviewSegments(segments []Segment, in *bufio.Reader, out io.Writer) ReviewResult {
+	result := ReviewResult{Segments: make([]Segment, 0, len(segments))}
+	for i, seg := range segments {
+		fmt.Fprintf(out, "\n[%d/%d] %s  %s\n  %s\n", i+1, len(segments), FormatTimestamp(seg.Start, true)[:8], seg.SpeakerLabel(), strings.TrimSpace(seg.Text))
+
+		for {
+			fmt.Fprint(out, "[Enter/e/s/q] ")
+			line, err := in.ReadString('\n')
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if err != nil && answer == "" {
+				answer = "q"
+			}
+
+			switch answer {
+			case "", "a":
+				result.Segments = append(result.Segments, seg)
+			case "e":
+				fmt.Fprint(out, "New text (empty keeps it): ")
+				text, _ := in.ReadString('\n')
+				if text = strings.TrimSpace(text); text != "" && text != strings.TrimSpace(seg.Text) {
+					seg = ReplaceSegmentText(seg, text, "")
+					result.Edited++
+				}
+				result.Segments = append(result.Segments, seg)
+			case "s":
+				result.Skipped++
+			case "q":
+				result.Segments = append(result.Segments, segments[i:]...)
+				return result
+			default:
+				fmt.Fprintln(out, reviewHelp)
+				continue
+			}
+			break
+		}
+	}
+	return result
+}
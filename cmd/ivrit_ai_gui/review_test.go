@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestReviewSegments tests accepting, editing and skipping segments in the terminal
+func TestReviewSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום"},
+		{Start: 2, End: 4, Text: "מה שלומח", Tokens: []Token{{Text: "מה"}}},
+		{Start: 4, End: 6, Text: "תודה רבה"},
+		{Start: 6, End: 8, Text: "להתראות"},
+	}
+
+	// Accept, edit, an unknown answer then skip, accept at the end of the input
+	input := bufio.NewReader(strings.NewReader("\ne\nמה שלומך\nx\ns\n"))
+	var out bytes.Buffer
+	result := ReviewSegments(segments, input, &out)
+	if result.Edited != 1 || result.Skipped != 1 || len(result.Segments) != 3 {
+		t.Fatalf("Unexpected review: %+v", result)
+	}
+	if seg := result.Segments[1]; seg.Text != "מה שלומך" || seg.Start != 2 || seg.Tokens != nil {
+		t.Errorf("Expected the edited text with its timing, got %+v", seg)
+	}
+	if result.Segments[2].Text != "להתראות" {
+		t.Errorf("Expected the rest accepted, got %+v", result.Segments[2])
+	}
+	if !strings.Contains(out.String(), "[2/4] 00:00:02  Speaker 1") || !strings.Contains(out.String(), reviewHelp) {
+		t.Errorf("Unexpected prompt:\n%s", out.String())
+	}
+
+	// q accepts the rest
+	result = ReviewSegments(segments, bufio.NewReader(strings.NewReader("s\nq\n")), &out)
+	if result.Skipped != 1 || len(result.Segments) != 3 || result.Segments[0].Text != "מה שלומח" {
+		t.Errorf("Expected all but the first segment kept, got %+v", result)
+	}
+}
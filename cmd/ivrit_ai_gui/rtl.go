@@ -0,0 +1,113 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Unicode direction marks: invisible characters that take a side in bidirectional
+// layout, so punctuation next to them follows the text's direction
+const (
+	rightToLeftMark = "\u200F" // RLM
+	leftToRightMark = "\u200E" // LRM
+)
+
+// RTLOptions fixes how right-to-left text is displayed by players and editors that
+// lay every line out left-to-right
+type RTLOptions struct {
+	FixPunctuation bool `json:"fixPunctuation,omitempty"` // Move stray leading punctuation to the end and add RLM/LRM marks
+	StripEmbedding bool `json:"stripEmbedding,omitempty"` // Plain text without the embedding marks (U+202B/U+202C) around Hebrew
+}
+
+// rtlLinePrefix matches the speaker labels, voice tags, indentation and embedding
+// marks that start a line of a transcript in the format, which are kept in place
+func rtlLinePrefix(format string) *regexp.Regexp {
+	prefixes := `<[^>]*>|\x{202B}`
+	if label := currentSpeakerLabels().linePattern(format); label != "" {
+		prefixes += "|" + label
+	}
+	return regexp.MustCompile(`^\s*(?:` + prefixes + `)*`)
+}
+
+// Apply returns a text, SRT or VTT transcript with the options applied; other
+// formats set the direction themselves and are returned unchanged
+func (o RTLOptions) Apply(text, format string) string {
+	if !encodesFormat(format) {
+		return text
+	}
+	if o.StripEmbedding && format == "text" {
+		text = strings.NewReplacer("\u202B", "", "\u202C", "").Replace(text)
+	}
+	if !o.FixPunctuation || !containsRTL(text) {
+		return text
+	}
+
+	prefix := rtlLinePrefix(format)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = fixRTLLine(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixRTLLine fixes the punctuation of one line of a transcript with right-to-left
+// text. Lines in an embedding already display right-to-left and get no marks.
+func fixRTLLine(line string, linePrefix *regexp.Regexp) string {
+	prefix := linePrefix.FindString(line)
+	body := strings.Trim(line[len(prefix):], rightToLeftMark+leftToRightMark)
+	suffix := ""
+	if strings.HasSuffix(body, "\u202C") {
+		body, suffix = strings.TrimSuffix(body, "\u202C"), "\u202C"
+	}
+	embedded := strings.Contains(prefix, "\u202B")
+
+	switch {
+	case containsRTL(body):
+		body = moveLeadingPunctuation(body)
+		if !embedded {
+			body = addDirectionMarks(body, rightToLeftMark, isRTLRune)
+		}
+	case strings.IndexFunc(body, unicode.IsLetter) >= 0 && !embedded:
+		// A translation or transliteration line among the Hebrew
+		body = addDirectionMarks(body, leftToRightMark, func(r rune) bool {
+			return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isRTLRune(r)
+		})
+	}
+	return prefix + body + suffix
+}
+
+// moveLeadingPunctuation moves sentence punctuation from the start of right-to-left
+// text to its end, where text copied in visual order (".שלום") had it. Ellipses
+// starting a continued sentence are kept.
+func moveLeadingPunctuation(body string) string {
+	rest := strings.TrimLeft(body, ".?!,;:")
+	lead := body[:len(body)-len(rest)]
+	rest = strings.TrimLeft(rest, " ")
+	if lead == "" || strings.Contains(lead, "..") || rest == "" || !isRTLRune([]rune(rest)[0]) {
+		return body
+	}
+	return rest + lead
+}
+
+// addDirectionMarks puts the mark at the start and end of text that starts or ends
+// with something other than a strong character of its direction (punctuation,
+// digits, brackets), so those stay on the correct side
+func addDirectionMarks(body, mark string, strong func(rune) bool) string {
+	runes := []rune(body)
+	if len(runes) == 0 {
+		return body
+	}
+	if !strong(runes[0]) {
+		body = mark + body
+	}
+	if !strong(runes[len(runes)-1]) {
+		body += mark
+	}
+	return body
+}
+
+// isRTLRune reports whether r is a right-to-left (Hebrew or Arabic) character
+func isRTLRune(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRTLOptionsApply tests the punctuation fixes and direction marks of exported transcripts
+func TestRTLOptionsApply(t *testing.T) {
+	const rlm, lrm = rightToLeftMark, leftToRightMark
+	fix := RTLOptions{FixPunctuation: true}
+	tests := []struct {
+		name     string
+		options  RTLOptions
+		format   string
+		input    string
+		expected string
+	}{
+		{"Off", RTLOptions{}, "srt", "שלום.\n", "שלום.\n"},
+		{"Trailing period", fix, "srt", "1\n00:00:00,000 --> 00:00:01,000\nשלום.\n", "1\n00:00:00,000 --> 00:00:01,000\nשלום." + rlm + "\n"},
+		{"Leading punctuation moved", fix, "srt", "?מה שלומך\n", "מה שלומך?" + rlm + "\n"},
+		{"Leading ellipsis kept", fix, "srt", "...ואז הלכנו\n", rlm + "...ואז הלכנו\n"},
+		{"Speaker label kept first", fix, "srt", "[Speaker 1] !תודה\n", "[Speaker 1] תודה!" + rlm + "\n"},
+		{"Voice tag kept first", fix, "vtt", "<v Speaker 2>\"כן\"\n", "<v Speaker 2>" + rlm + "\"כן\"" + rlm + "\n"},
+		{"Translation line", fix, "srt", "שלום\nHello.\n", "שלום\n" + "Hello." + lrm + "\n"},
+		{"Already marked", fix, "srt", "שלום." + rlm + "\n", "שלום." + rlm + "\n"},
+		{"No Hebrew", fix, "srt", "Hello.\n", "Hello.\n"},
+		{"Embedded text line", fix, "text", "Speaker 1: \u202B.שלום\u202C\n", "Speaker 1: \u202Bשלום.\u202C\n"},
+		{"Embedding stripped", RTLOptions{StripEmbedding: true}, "text", "Speaker 1: \u202Bשלום.\u202C\n", "Speaker 1: שלום.\n"},
+		{"Stripped and fixed", RTLOptions{true, true}, "text", "\u202Bשלום.\u202C\n", "שלום." + rlm + "\n"},
+		{"JSON unchanged", fix, "json", `{"text": ".שלום"}`, `{"text": ".שלום"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Apply(tt.input, tt.format); got != tt.expected {
+				t.Errorf("Apply() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseTranscriptWithDirectionMarks tests that fixed transcripts are read back without the marks
+func TestParseTranscriptWithDirectionMarks(t *testing.T) {
+	srt := FormatOutput([]Segment{{Start: 0, End: 1, Text: "שלום."}}, "srt", DisplayBilingual)
+	data := RTLOptions{FixPunctuation: true}.Apply(srt, "srt")
+	if !strings.Contains(data, rightToLeftMark) {
+		t.Fatalf("Expected a direction mark in %q", data)
+	}
+	segments, _, err := ParseTranscript([]byte(data), "srt")
+	if err != nil || len(segments) != 1 || segments[0].Text != "שלום." {
+		t.Errorf("ParseTranscript() = %+v, %v", segments, err)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// RunPod serverless endpoints run ivrit.ai's faster-whisper worker on cloud GPUs;
+// ivrit.ai's hosted transcription is one. A job is submitted with the audio inline
+// and polled until it finishes.
+const (
+	runPodAPI          = "https://api.runpod.ai/v2/"
+	runPodPayloadLimit = 10 << 20 // RunPod rejects larger /run requests
+	runPodAudioBitrate = "32k"    // Speech stays accurate; about half an hour fits in one request
+	runPodPollInterval = 2 * time.Second
+	runPodMaxWait      = time.Hour // Default for how long a job may take, queueing included
+)
+
+// ivritCloudEndpoint is the RunPod endpoint ID of ivrit.ai's hosted transcription.
+// Release builds that include it set it at link time:
+//
+//	go build -ldflags "-X main.ivritCloudEndpoint=<endpoint-id>"
+var ivritCloudEndpoint = ""
+
+// runPodEndpointURL returns the API address of a RunPod endpoint given its ID or
+// URL ("" = ivrit.ai's hosted endpoint, when the build has one)
+func runPodEndpointURL(endpoint string) string {
+	if endpoint == "" {
+		endpoint = ivritCloudEndpoint
+	}
+	if endpoint == "" || strings.Contains(endpoint, "://") {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return runPodAPI + endpoint
+}
+
+// CloudUploadNotice is the privacy warning shown while recordings are sent to a
+// third-party cloud service ("" when they stay on this computer or your own servers)
+func CloudUploadNotice(cfg AppConfig) string {
+	if cfg.Engine != EngineRunPod {
+		return ""
+	}
+	where := "RunPod endpoint " + cfg.EngineURL
+	if cfg.EngineURL == "" {
+		where = "ivrit.ai's hosted service (on RunPod)"
+	}
+	return fmt.Sprintf("recordings are uploaded to %s for transcription; don't use it for audio you may not share", where)
+}
+
+// isRunPodEndpointID reports whether s looks like a RunPod endpoint ID rather than a URL
+func isRunPodEndpointID(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// runPodJob is the input of ivrit.ai's RunPod worker
+type runPodJob struct {
+	Type           string         `json:"type"` // "blob": the audio is in Data
+	Data           string         `json:"data"` // Base64 audio
+	Model          string         `json:"model"`
+	Engine         string         `json:"engine"`
+	Streaming      bool           `json:"streaming"`
+	TranscribeArgs map[string]any `json:"transcribe_args"`
+}
+
+// runPodStatus is RunPod's answer when a job is submitted or polled
+type runPodStatus struct {
+	ID     string          `json:"id"`
+	Status string          `json:"status"` // IN_QUEUE, IN_PROGRESS, COMPLETED, FAILED, CANCELLED or TIMED_OUT
+	Output json.RawMessage `json:"output"`
+	Error  string          `json:"error"`
+}
+
+// encodeUploadAudio compresses audio for uploading to a cloud endpoint
+func encodeUploadAudio(audioPath string) ([]byte, error) {
+	tempFile, err := CreateTempFile("upload_audio_*.m4a")
+	if err != nil {
+		return nil, err
+	}
+	tempPath := tempFile.Name()
+	tempFile.Close()
+	defer RemoveTempFile(tempPath)
+
+	cmd := exec.Command(ffmpegPath(),
+		"-i", audioPath,
+		"-vn",      // No video
+		"-ac", "1", // Mono
+		"-ar", "16000",
+		"-c:a", "aac", // Built into every ffmpeg
+		"-b:a", runPodAudioBitrate,
+		"-y", // Overwrite output file
+		tempPath,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg audio encoding failed: %v", err)
+	}
+	return os.ReadFile(tempPath)
+}
+
+// transcribeRunPod runs a transcription job on a RunPod endpoint and waits for its segments
+func (e *RemoteEngine) transcribeRunPod(audio []byte, modelID string, progressCallback func(string)) ([]Segment, error) {
+	args := map[string]any{"language": "he", "temperature": e.decode.Temperature}
+	if e.decode.BeamSize > 1 {
+		args["beam_size"] = e.decode.BeamSize
+	}
+	if e.decode.InitialPrompt != "" {
+		args["initial_prompt"] = e.decode.InitialPrompt
+	}
+	payload, err := json.Marshal(map[string]runPodJob{"input": {
+		Type:           "blob",
+		Data:           base64.StdEncoding.EncodeToString(audio),
+		Model:          fasterWhisperModel(modelID),
+		Engine:         EngineFasterWhisper,
+		TranscribeArgs: args,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > runPodPayloadLimit {
+		return nil, fmt.Errorf("the recording is too long to upload in one request (%.1fMB, the limit is %dMB); transcribe it in parts with a time range",
+			float64(len(payload))/(1024*1024), runPodPayloadLimit>>20)
+	}
+
+	var job runPodStatus
+	upload := &uploadProgressReader{reader: bytes.NewReader(payload), total: int64(len(payload)), report: progressCallback}
+	if err := e.runPodRequest(e.ctx, "POST", "/run", upload, &job); err != nil {
+		return nil, err
+	}
+	if job.ID == "" {
+		return nil, fmt.Errorf("RunPod did not return a job ID")
+	}
+
+	deadline := time.Now().Add(e.maxWait)
+	for {
+		switch job.Status {
+		case "COMPLETED":
+			return parseRunPodOutput(job.Output)
+		case "FAILED", "CANCELLED", "TIMED_OUT":
+			err := fmt.Errorf("cloud transcription %s", strings.ToLower(strings.ReplaceAll(job.Status, "_", " ")))
+			if job.Error != "" {
+				err = fmt.Errorf("%v: %s", err, job.Error)
+			}
+			return nil, err
+		case "IN_QUEUE":
+			if progressCallback != nil {
+				progressCallback("Waiting for a cloud GPU...")
+			}
+		default:
+			if progressCallback != nil {
+				progressCallback("Transcribing in the cloud...")
+			}
+		}
+		select {
+		case <-time.After(e.poll):
+		case <-e.ctx.Done():
+		}
+		// Stop the job rather than leave it running, and billed, for nobody
+		switch {
+		case ShuttingDown() || e.ctx.Err() != nil:
+			e.cancelRunPodJob(job.ID)
+			return nil, ErrInterrupted
+		case time.Now().After(deadline):
+			e.cancelRunPodJob(job.ID)
+			return nil, fmt.Errorf("cloud transcription took longer than %v and was cancelled; allow more with -engine-max-wait", e.maxWait)
+		}
+		if err := e.runPodRequest(e.ctx, "GET", "/status/"+job.ID, nil, &job); err != nil {
+			if err == ErrInterrupted {
+				e.cancelRunPodJob(job.ID)
+			}
+			return nil, err
+		}
+	}
+}
+
+// cancelRunPodJob asks the endpoint to stop a job, even after Abort. It's best
+// effort: a job that can't be cancelled times out on RunPod's side eventually.
+func (e *RemoteEngine) cancelRunPodJob(id string) {
+	var job runPodStatus
+	e.runPodRequest(context.Background(), "POST", "/cancel/"+id, nil, &job)
+}
+
+// runPodRequest calls the endpoint's API and decodes the job status it returns.
+// It returns ErrInterrupted when ctx is cancelled.
+func (e *RemoteEngine) runPodRequest(ctx context.Context, method, path string, body io.Reader, status *runPodStatus) error {
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	if upload, ok := body.(*uploadProgressReader); ok {
+		req.ContentLength = upload.total
+	}
+
+	resp, err := e.client.Do(req)
+	if ctx.Err() != nil {
+		return ErrInterrupted
+	}
+	if err != nil {
+		return fmt.Errorf("cannot reach the cloud endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("the cloud endpoint rejected the API key")
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("cloud endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, status)
+}
+
+// parseRunPodOutput reads the segments of a finished job. ivrit.ai's worker returns
+// a list of results, each with a "result" list of segments (times in seconds).
+func parseRunPodOutput(output json.RawMessage) ([]Segment, error) {
+	type segment struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	}
+	var results []struct {
+		Result []segment `json:"result"`
+		Error  string    `json:"error"`
+	}
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, fmt.Errorf("unexpected output from the cloud endpoint: %v", err)
+	}
+
+	segments := []Segment{}
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("cloud transcription failed: %s", result.Error)
+		}
+		for _, s := range result.Result {
+			segments = append(segments, LabelEvent(Segment{Start: s.Start, End: s.End, Text: s.Text}, 0))
+		}
+	}
+	return segments, nil
+}
+
+// uploadProgressReader reports how much of a request body has been sent
+type uploadProgressReader struct {
+	reader      io.Reader
+	total       int64
+	sent        int64
+	lastPercent int
+	report      func(string)
+}
+
+func (r *uploadProgressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+	if r.report != nil && r.total > 0 {
+		// Every 5% is enough, and keeps the GUI's progress channel from filling up
+		if percent := int(r.sent * 100 / r.total); percent >= r.lastPercent+5 || (percent == 100 && r.lastPercent != 100) {
+			r.lastPercent = percent
+			r.report(fmt.Sprintf("Uploading: %.1fMB / %.1fMB (%d%%)",
+				float64(r.sent)/(1024*1024), float64(r.total)/(1024*1024), percent))
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTranscribeRunPod tests submitting a job and polling it until it completes
+func TestTranscribeRunPod(t *testing.T) {
+	var job struct {
+		Input runPodJob `json:"input"`
+	}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer rp-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/run":
+			if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+				t.Errorf("Invalid job: %v", err)
+			}
+			io.WriteString(w, `{"id": "job-1", "status": "IN_QUEUE"}`)
+		case "/status/job-1":
+			polls++
+			if polls < 2 {
+				io.WriteString(w, `{"id": "job-1", "status": "IN_PROGRESS"}`)
+				return
+			}
+			io.WriteString(w, `{"id": "job-1", "status": "COMPLETED", "output": [{"result": [
+				{"start": 0, "end": 1.5, "text": "שלום"}, {"start": 1.5, "end": 3, "text": "עולם"}]}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	engine, err := NewRemoteEngine(EngineRunPod, server.URL, "rp-key")
+	if err != nil {
+		t.Fatalf("NewRemoteEngine() error: %v", err)
+	}
+	engine.poll = 0
+	engine.SetDecodeOptions(DecodeOptions{BeamSize: 5})
+
+	var messages []string
+	segments, err := engine.transcribeRunPod([]byte("audio"), "turbo", func(msg string) { messages = append(messages, msg) })
+	if err != nil {
+		t.Fatalf("transcribeRunPod() error: %v", err)
+	}
+	if len(segments) != 2 || segments[1].Text != "עולם" || segments[1].End != 3 {
+		t.Errorf("Unexpected segments: %+v", segments)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(job.Input.Data)
+	if job.Input.Type != "blob" || string(data) != "audio" || job.Input.Engine != EngineFasterWhisper {
+		t.Errorf("Unexpected job input: %+v", job.Input)
+	}
+	if job.Input.Model != fasterWhisperModel("turbo") || job.Input.TranscribeArgs["language"] != "he" || job.Input.TranscribeArgs["beam_size"] != 5.0 {
+		t.Errorf("Unexpected model or arguments: %s, %v", job.Input.Model, job.Input.TranscribeArgs)
+	}
+	if len(messages) == 0 || !strings.Contains(messages[0], "(100%)") {
+		t.Errorf("Expected upload progress first, got %q", messages)
+	}
+
+	engine.apiKey = "wrong"
+	if _, err := engine.transcribeRunPod([]byte("audio"), "turbo", nil); err == nil || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("Expected a rejected key, got %v", err)
+	}
+}
+
+// TestTranscribeRunPodFailed tests that a failed job reports the worker's error
+func TestTranscribeRunPodFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id": "job-2", "status": "FAILED", "error": "out of memory"}`)
+	}))
+	defer server.Close()
+
+	engine, _ := NewRemoteEngine(EngineRunPod, server.URL, "rp-key")
+	_, err := engine.transcribeRunPod([]byte("audio"), "turbo", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed: out of memory") {
+		t.Errorf("Expected the job's error, got %v", err)
+	}
+
+	if _, err := engine.transcribeRunPod(make([]byte, runPodPayloadLimit), "turbo", nil); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Errorf("Expected an oversized upload to be refused, got %v", err)
+	}
+}
+
+// TestTranscribeRunPodTimeout tests that a job still queued when the wait runs out
+// is cancelled on the endpoint
+func TestTranscribeRunPodTimeout(t *testing.T) {
+	cancelled := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cancel/job-3":
+			cancelled = true
+			io.WriteString(w, `{"id": "job-3", "status": "CANCELLED"}`)
+		default:
+			io.WriteString(w, `{"id": "job-3", "status": "IN_QUEUE"}`)
+		}
+	}))
+	defer server.Close()
+
+	engine, _ := NewRemoteEngine(EngineRunPod, server.URL, "rp-key")
+	engine.poll = time.Millisecond
+	engine.SetMaxWait(20 * time.Millisecond)
+	_, err := engine.transcribeRunPod([]byte("audio"), "turbo", nil)
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("Expected the wait to run out, got %v", err)
+	}
+	if !cancelled {
+		t.Error("Expected the job to be cancelled on the endpoint")
+	}
+}
+
+// TestTranscribeRunPodAbort tests that aborting, e.g. when a gRPC client goes away,
+// stops polling and cancels the job on the endpoint
+func TestTranscribeRunPodAbort(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cancel/job-4" {
+			cancelled <- true
+		}
+		io.WriteString(w, `{"id": "job-4", "status": "IN_PROGRESS"}`)
+	}))
+	defer server.Close()
+
+	engine, _ := NewRemoteEngine(EngineRunPod, server.URL, "rp-key")
+	engine.poll = time.Hour
+	time.AfterFunc(20*time.Millisecond, engine.Abort)
+	if _, err := engine.transcribeRunPod([]byte("audio"), "turbo", nil); err != ErrInterrupted {
+		t.Errorf("Expected ErrInterrupted, got %v", err)
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Error("Expected the job to be cancelled on the endpoint")
+	}
+}
+
+// TestParseRunPodOutput tests reading segments from a finished job's output
+func TestParseRunPodOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		segments int
+		valid    bool
+	}{
+		{"Results", `[{"result": [{"start": 0, "end": 1, "text": "א"}]}, {"result": [{"start": 1, "end": 2, "text": "ב"}]}]`, 2, true},
+		{"Silence", `[]`, 0, true},
+		{"Worker error", `[{"error": "invalid audio"}]`, 0, false},
+		{"Not a list", `{"text": "שלום"}`, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments, err := parseRunPodOutput(json.RawMessage(tt.output))
+			if (err == nil) != tt.valid {
+				t.Fatalf("parseRunPodOutput() error = %v, expected valid = %v", err, tt.valid)
+			}
+			if len(segments) != tt.segments {
+				t.Errorf("Expected %d segments, got %d", tt.segments, len(segments))
+			}
+		})
+	}
+}
+
+// TestRunPodEndpointURL tests resolving endpoint IDs and the hosted endpoint
+func TestRunPodEndpointURL(t *testing.T) {
+	if got := runPodEndpointURL("abc123xyz"); got != "https://api.runpod.ai/v2/abc123xyz" {
+		t.Errorf("Unexpected URL for an endpoint ID: %s", got)
+	}
+	if got := runPodEndpointURL("https://proxy.example.com/v2/abc/"); got != "https://proxy.example.com/v2/abc" {
+		t.Errorf("Unexpected URL for a full address: %s", got)
+	}
+
+	saved := ivritCloudEndpoint
+	defer func() { ivritCloudEndpoint = saved }()
+	ivritCloudEndpoint = ""
+	if _, err := NewRemoteEngine(EngineRunPod, "", "rp-key"); err == nil {
+		t.Error("Expected an error without a hosted endpoint")
+	}
+	ivritCloudEndpoint = "hosted1"
+	if got := runPodEndpointURL(""); got != "https://api.runpod.ai/v2/hosted1" {
+		t.Errorf("Expected the hosted endpoint, got %s", got)
+	}
+	if _, err := NewRemoteEngine(EngineRunPod, "", ""); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+}
+
+// TestCloudUploadNotice tests that only the cloud engine shows the privacy warning
+func TestCloudUploadNotice(t *testing.T) {
+	cfg := DefaultConfig()
+	if CloudUploadNotice(cfg) != "" {
+		t.Error("Expected no notice for the local engine")
+	}
+	cfg.Engine = EngineWhisperServer
+	if CloudUploadNotice(cfg) != "" {
+		t.Error("Expected no notice for a self-hosted server")
+	}
+	cfg.Engine = EngineRunPod
+	if notice := CloudUploadNotice(cfg); !strings.Contains(notice, "ivrit.ai") {
+		t.Errorf("Expected the hosted service named, got %q", notice)
+	}
+	cfg.EngineURL = "abc123xyz"
+	if notice := CloudUploadNotice(cfg); !strings.Contains(notice, "abc123xyz") {
+		t.Errorf("Expected the endpoint named, got %q", notice)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// FormatCustom is the format written by the configured export script
+const FormatCustom = "custom"
+
+// exportScriptTimeout stops an export script that doesn't finish, e.g. an endless loop
+const exportScriptTimeout = 30 * time.Second
+
+// ExportScriptOptions sets the Lua script behind the custom format. The script gets
+// the transcript as the global table segments and returns its output as a string, or
+// writes it with write() and print().
+type ExportScriptOptions struct {
+	Path      string `json:"path,omitempty"`      // Lua script file ("" = no custom format)
+	Extension string `json:"extension,omitempty"` // File extension of its output ("" = txt)
+}
+
+// The export script from the config, compiled once
+var (
+	exportScript      ExportScriptOptions
+	exportScriptProto *lua.FunctionProto
+	exportScriptMutex sync.RWMutex
+)
+
+// SetExportScript loads and compiles the script the custom format runs ("" = none)
+func SetExportScript(options ExportScriptOptions) error {
+	var proto *lua.FunctionProto
+	var err error
+	if options.Path != "" {
+		if proto, err = compileExportScript(options.Path); err != nil {
+			options = ExportScriptOptions{}
+		}
+	}
+	exportScriptMutex.Lock()
+	defer exportScriptMutex.Unlock()
+	exportScript, exportScriptProto = options, proto
+	return err
+}
+
+// currentExportScript returns the export script in effect (nil when none is set)
+func currentExportScript() (ExportScriptOptions, *lua.FunctionProto) {
+	exportScriptMutex.RLock()
+	defer exportScriptMutex.RUnlock()
+	return exportScript, exportScriptProto
+}
+
+// HasExportScript reports whether the custom format can be written
+func HasExportScript() bool {
+	_, proto := currentExportScript()
+	return proto != nil
+}
+
+// customExtension returns the file extension of the custom format's output
+func customExtension() string {
+	options, _ := currentExportScript()
+	if ext := strings.TrimPrefix(options.Extension, "."); ext != "" {
+		return ext
+	}
+	return "txt"
+}
+
+// compileExportScript reads and compiles a Lua script
+func compileExportScript(path string) (*lua.FunctionProto, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading export script: %v", err)
+	}
+	chunk, err := parse.Parse(strings.NewReader(string(source)), path)
+	if err != nil {
+		return nil, fmt.Errorf("error in export script: %v", err)
+	}
+	proto, err := lua.Compile(chunk, path)
+	if err != nil {
+		return nil, fmt.Errorf("error in export script: %v", err)
+	}
+	return proto, nil
+}
+
+// FormatScript runs the export script on the segments and returns its output
+func FormatScript(segments []Segment) (string, error) {
+	_, proto := currentExportScript()
+	if proto == nil {
+		return "", fmt.Errorf("the custom format needs an export script (-script)")
+	}
+	return runExportScript(proto, segments)
+}
+
+// runExportScript runs a compiled script in a sandbox: only the base, string, table
+// and math libraries, without file access, for at most exportScriptTimeout
+func runExportScript(proto *lua.FunctionProto, segments []Segment) (string, error) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportScriptTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+
+	var output strings.Builder
+	write := func(L *lua.LState, separator, end string) int {
+		for i := 1; i <= L.GetTop(); i++ {
+			if i > 1 {
+				output.WriteString(separator)
+			}
+			output.WriteString(L.ToStringMeta(L.Get(i)).String())
+		}
+		output.WriteString(end)
+		return 0
+	}
+	L.SetGlobal("write", L.NewFunction(func(L *lua.LState) int { return write(L, "", "") }))
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int { return write(L, "\t", "\n") }))
+	L.SetGlobal("timestamp", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(FormatTimestamp(float64(L.CheckNumber(1)), L.OptBool(2, false))))
+		return 1
+	}))
+	L.SetGlobal("segments", exportSegments(L, segments))
+
+	L.Push(L.NewFunctionFromProto(proto))
+	if err := L.PCall(0, 1, nil); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("export script didn't finish within %v", exportScriptTimeout)
+		}
+		return "", fmt.Errorf("export script failed: %v", err)
+	}
+	if result, ok := L.Get(-1).(lua.LString); ok {
+		output.WriteString(string(result))
+	}
+	return output.String(), nil
+}
+
+// exportSegments returns the segments as a Lua array of tables, with speakers
+// numbered from 1 like the labels
+func exportSegments(L *lua.LState, segments []Segment) *lua.LTable {
+	table := L.CreateTable(len(segments), 0)
+	for _, seg := range segments {
+		entry := L.CreateTable(0, 10)
+		entry.RawSetString("start", lua.LNumber(seg.Start))
+		entry.RawSetString("end", lua.LNumber(seg.End))
+		entry.RawSetString("text", lua.LString(seg.Text))
+		entry.RawSetString("original", lua.LString(seg.Original))
+		entry.RawSetString("translation", lua.LString(seg.Translation))
+		entry.RawSetString("transliteration", lua.LString(seg.Transliteration))
+		entry.RawSetString("event", lua.LString(seg.Event))
+		if seg.Event == "" {
+			entry.RawSetString("speaker", lua.LNumber(seg.Speaker+1))
+			entry.RawSetString("label", lua.LString(seg.SpeakerLabel()))
+		}
+		table.Append(entry)
+	}
+	return table
+}
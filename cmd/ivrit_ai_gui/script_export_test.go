@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setTestExportScript sets an export script with the given source for one test
+func setTestExportScript(t *testing.T, source, extension string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.lua")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetExportScript(ExportScriptOptions{Path: path, Extension: extension}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetExportScript(ExportScriptOptions{}) })
+}
+
+// TestFormatScript tests custom output from a Lua script
+func TestFormatScript(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 1.5, Text: "שלום"},
+		{Start: 1.5, End: 3, Text: "[music]", Event: EventMusic},
+		{Start: 3, End: 4, Text: "מה נשמע", Speaker: 1, SpeakerName: "דנה"},
+	}
+
+	// A returned string
+	setTestExportScript(t, `
+local rows = {"start,speaker,text"}
+for i, seg in ipairs(segments) do
+  if seg.event == "" then
+    rows[#rows + 1] = string.format("%s,%d,%s", timestamp(seg.start), seg.speaker, seg.text)
+  end
+end
+return table.concat(rows, "\n") .. "\n"`, ".csv")
+	output, err := FormatScript(segments)
+	want := "start,speaker,text\n00:00:00,000,1,שלום\n00:00:03,000,2,מה נשמע\n"
+	if err != nil || output != want {
+		t.Errorf("Expected %q, got %q (error: %v)", want, output, err)
+	}
+	if ext := formatExtension(FormatCustom); ext != "csv" {
+		t.Errorf("Expected the csv extension, got %s", ext)
+	}
+	if FormatOutput(segments, FormatCustom, DisplayBilingual) != want {
+		t.Error("Expected FormatOutput to run the script")
+	}
+
+	// Written output, with labels and VTT timestamps
+	setTestExportScript(t, `
+for _, seg in ipairs(segments) do
+  if seg.label then print(seg.label, timestamp(seg["end"], true)) end
+end
+write("done")`, "")
+	output, err = FormatScript(segments)
+	want = "Speaker 1\t00:00:01.500\nדנה\t00:00:04.000\ndone"
+	if err != nil || output != want {
+		t.Errorf("Expected %q, got %q (error: %v)", want, output, err)
+	}
+	if ext := formatExtension(FormatCustom); ext != "txt" {
+		t.Errorf("Expected the txt extension by default, got %s", ext)
+	}
+}
+
+// TestFormatScriptErrors tests failing and sandboxed scripts
+func TestFormatScriptErrors(t *testing.T) {
+	if _, err := FormatScript(nil); err == nil {
+		t.Error("Expected an error without an export script")
+	}
+	path := filepath.Join(t.TempDir(), "broken.lua")
+	os.WriteFile(path, []byte("return ("), 0644)
+	if err := SetExportScript(ExportScriptOptions{Path: path}); err == nil || HasExportScript() {
+		t.Error("Expected a syntax error, leaving no export script")
+	}
+
+	for name, source := range map[string]string{
+		"runtime error": `error("no segments")`,
+		"file access":   `return io.open("/etc/passwd"):read("*a")`,
+		"os access":     `os.execute("true")`,
+		"dofile":        `dofile("/etc/passwd")`,
+	} {
+		setTestExportScript(t, source, "")
+		if _, err := FormatScript(nil); err == nil {
+			t.Errorf("%s: expected the script to fail", name)
+		}
+	}
+	if FormatOutput(nil, FormatCustom, DisplayBilingual) != "" {
+		t.Error("Expected no output from a failing script")
+	}
+}
+
+// TestValidateCustomFormat tests that the custom format needs a script
+func TestValidateCustomFormat(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Format = FormatCustom
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "-script") {
+		t.Errorf("Expected an error asking for a script, got %v", err)
+	}
+	cfg.Script.Path = "export.lua"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the custom format to be valid with a script: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hebrew punctuation that needs special care when looking for sentence ends
+const (
+	hebrewGeresh    = '׳' // ׳ abbreviation mark (also written as ')
+	hebrewGershayim = '״' // ״ acronym mark (also written as ")
+	hebrewSofPasuq  = '׃' // ׃ verse end
+)
+
+// paragraphChars is how long a paragraph grows before the next sentence starts a new one
+const paragraphChars = 600
+
+// Letters that attach to the front of Hebrew words (and, in, to, from, the, that, as)
+const hebrewPrefixLetters = "ובלמהשכ"
+
+// Abbreviations that end with a period but don't end a sentence (compared without the period)
+var sentenceAbbreviations = map[string]bool{
+	// Hebrew
+	"פרופ": true, "עמ": true, "רח": true, "וכו": true, "וגו": true, "סע": true,
+	"ד\"ר": true, "ד״ר": true, "עו\"ד": true, "עו״ד": true, "רו\"ח": true, "רו״ח": true,
+	"ת\"ד": true, "ת״ד": true,
+	// English (common in mixed Hebrew/English speech)
+	"dr": true, "mr": true, "mrs": true, "ms": true, "prof": true, "st": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "inc": true, "ltd": true,
+}
+
+// SplitSentences splits text into sentences. It is aware of Hebrew conventions:
+// gershayim in acronyms (צה"ל, ד"ר) are not quotes, dotted abbreviations
+// (פרופ., ד"ר.) don't end sentences, closing quotes and brackets
+// stay with their sentence, and decimals (3.5) and initials (א.ב.) aren't split.
+// Line breaks are treated as spaces. Sentences are returned trimmed.
+func SplitSentences(text string) []string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	sentences := []string{}
+
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !isSentenceTerminator(runes[i]) {
+			continue
+		}
+
+		// Consume runs like "?!" and "..."
+		end := i + 1
+		for end < len(runes) && isSentenceTerminator(runes[end]) {
+			end++
+		}
+		// Closing quotes and brackets belong to the sentence they close
+		for end < len(runes) && isClosingPunctuation(runes, end) {
+			end++
+		}
+
+		// A sentence only ends before whitespace or the end of the text
+		if end < len(runes) && runes[end] != ' ' {
+			i = end - 1
+			continue
+		}
+
+		if runes[i] == '.' && end-i == 1 && !endsSentenceAtPeriod(runes, start, i) {
+			i = end - 1
+			continue
+		}
+
+		if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+		i = end - 1
+	}
+
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+
+	return sentences
+}
+
+// ChunkSentences groups consecutive sentences into chunks of at most maxChars
+// characters (a single longer sentence becomes its own chunk), so long text can
+// be processed in pieces without cutting a sentence in half.
+func ChunkSentences(text string, maxChars int) []string {
+	chunks := []string{}
+	current := ""
+
+	for _, sentence := range SplitSentences(text) {
+		if current != "" && len([]rune(current))+1+len([]rune(sentence)) > maxChars {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		if current != "" {
+			current += " "
+		}
+		current += sentence
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// Paragraphs breaks long text, e.g. a lecture's turn, into paragraphs of whole
+// sentences, each of about paragraphChars characters
+func Paragraphs(text string) []string {
+	return ChunkSentences(text, paragraphChars)
+}
+
+// isSentenceTerminator reports whether r can end a sentence
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '.', '?', '!', '…', hebrewSofPasuq:
+		return true
+	}
+	return false
+}
+
+// isClosingPunctuation reports whether the rune at i closes a quote or bracket.
+// An ASCII " or ' directly between two letters is gershayim/geresh, not a quote.
+func isClosingPunctuation(runes []rune, i int) bool {
+	switch runes[i] {
+	case ')', ']', '}', '»', '”', '’':
+		return true
+	case '"', '\'', hebrewGershayim, hebrewGeresh:
+		return !(i+1 < len(runes) && unicode.IsLetter(runes[i+1]))
+	}
+	return false
+}
+
+// endsSentenceAtPeriod decides whether the single period at runes[dot] ends the sentence
+func endsSentenceAtPeriod(runes []rune, start, dot int) bool {
+	// The word before the period (back to the previous space)
+	wordStart := dot
+	for wordStart > start && runes[wordStart-1] != ' ' {
+		wordStart--
+	}
+	word := string(runes[wordStart:dot])
+	word = strings.TrimLeft(word, "\"'(«“‘")
+	if word == "" {
+		return true
+	}
+
+	// Known abbreviations, also with an attached Hebrew prefix letter (ופרופ. בעמ.)
+	if sentenceAbbreviations[strings.ToLower(word)] {
+		return false
+	}
+	if prefix, size := utf8.DecodeRuneInString(word); strings.ContainsRune(hebrewPrefixLetters, prefix) && sentenceAbbreviations[word[size:]] {
+		return false
+	}
+
+	// Single letters and initials (א. ב. / א.ב.) are not sentence ends.
+	// A period after a geresh abbreviation (וכו'.) is a real sentence end, since
+	// the geresh already marks the abbreviation.
+	wordRunes := []rune(word)
+	if len(wordRunes) == 1 && unicode.IsLetter(wordRunes[0]) {
+		return false
+	}
+	if strings.Contains(word, ".") {
+		return false
+	}
+
+	return true
+}
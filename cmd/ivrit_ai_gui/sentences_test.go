@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSplitSentences tests Hebrew-aware sentence splitting
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Simple", "שלום לכולם. מה שלומכם? טוב מאוד!", []string{"שלום לכולם.", "מה שלומכם?", "טוב מאוד!"}},
+		{"No terminator", "שלום לכולם", []string{"שלום לכולם"}},
+		{"Empty", "  ", []string{}},
+		{"ASCII gershayim", `ד"ר כהן הגיע לצה"ל. הוא שמח.`, []string{`ד"ר כהן הגיע לצה"ל.`, "הוא שמח."}},
+		{"Hebrew gershayim", "ארה״ב גדולה. ישראל קטנה.", []string{"ארה״ב גדולה.", "ישראל קטנה."}},
+		{"Dotted abbreviation", "פרופ. לוי ופרופ. כהן נפגשו. זה היה מעניין.", []string{"פרופ. לוי ופרופ. כהן נפגשו.", "זה היה מעניין."}},
+		{"Abbreviation with gershayim and period", `פגשתי את ד"ר. לוי אתמול. זה היה טוב.`, []string{`פגשתי את ד"ר. לוי אתמול.`, "זה היה טוב."}},
+		{"Geresh then period", "קנינו פירות, ירקות וכו'. אחר כך הלכנו.", []string{"קנינו פירות, ירקות וכו'.", "אחר כך הלכנו."}},
+		{"Decimal", "המחיר עלה ב-3.5 אחוזים. זה הרבה.", []string{"המחיר עלה ב-3.5 אחוזים.", "זה הרבה."}},
+		{"Initials", "א.ב. יהושע כתב ספר. הוא מפורסם.", []string{"א.ב. יהושע כתב ספר.", "הוא מפורסם."}},
+		{"Closing quote", `הוא אמר "שלום." ואז הלך.`, []string{`הוא אמר "שלום."`, "ואז הלך."}},
+		{"Closing bracket", "זה נכון (לפחות לדעתי.) נמשיך.", []string{"זה נכון (לפחות לדעתי.)", "נמשיך."}},
+		{"Mixed terminators", "באמת?! לא ייתכן... אבל כן.", []string{"באמת?!", "לא ייתכן...", "אבל כן."}},
+		{"Sof pasuq", "בראשית ברא אלוהים׃ והארץ הייתה תוהו׃", []string{"בראשית ברא אלוהים׃", "והארץ הייתה תוהו׃"}},
+		{"Line breaks", "שורה ראשונה\nממשיכה. שורה\nשנייה.", []string{"שורה ראשונה ממשיכה.", "שורה שנייה."}},
+		{"English abbreviation", "We met Dr. Smith. It went well.", []string{"We met Dr. Smith.", "It went well."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SplitSentences(tt.text)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("SplitSentences(%q)\n got:  %q\n want: %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestChunkSentences tests grouping sentences into size-limited chunks
+func TestChunkSentences(t *testing.T) {
+	text := "משפט ראשון. משפט שני. משפט שלישי ארוך יותר מהאחרים."
+
+	chunks := ChunkSentences(text, 25)
+	expected := []string{"משפט ראשון. משפט שני.", "משפט שלישי ארוך יותר מהאחרים."}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("ChunkSentences()\n got:  %q\n want: %q", chunks, expected)
+	}
+
+	// Everything fits in one chunk
+	if chunks := ChunkSentences(text, 1000); len(chunks) != 1 || chunks[0] != text {
+		t.Errorf("Expected a single chunk, got %q", chunks)
+	}
+
+	// No sentence is lost or cut
+	if joined := strings.Join(ChunkSentences(text, 5), " "); joined != text {
+		t.Errorf("Chunks should rejoin to the original text, got %q", joined)
+	}
+}
+
+// TestParagraphs tests that long text is broken into paragraphs at sentence ends
+func TestParagraphs(t *testing.T) {
+	sentence := strings.Repeat("מילה ", 30) + "אחרונה."
+	text := strings.TrimSpace(strings.Repeat(sentence+" ", 8))
+
+	paragraphs := Paragraphs(text)
+	if len(paragraphs) < 2 {
+		t.Fatalf("Expected several paragraphs, got %d", len(paragraphs))
+	}
+	for _, paragraph := range paragraphs {
+		if !strings.HasSuffix(paragraph, "אחרונה.") || len([]rune(paragraph)) > paragraphChars {
+			t.Errorf("Paragraph not of whole sentences within the limit: %q", paragraph)
+		}
+	}
+	if got := Paragraphs("שלום. מה שלומך?"); len(got) != 1 {
+		t.Errorf("Expected short text in one paragraph, got %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// minAPIKeyLength rejects keys short enough to guess
+const minAPIKeyLength = 16
+
+// apiKeyAuth rejects server requests that don't carry one of the configured API keys,
+// sent as "authorization: Bearer <key>" or "x-api-key: <key>" metadata
+type apiKeyAuth struct {
+	keys []string
+}
+
+// authenticate checks the API key in the request metadata
+func (a apiKeyAuth) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	key := ""
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		key = values[0]
+	} else if values := md.Get("authorization"); len(values) > 0 {
+		key, _ = strings.CutPrefix(values[0], "Bearer ")
+	}
+	if key == "" {
+		return status.Error(codes.Unauthenticated, "missing API key")
+	}
+
+	// Compare every key in constant time so timing doesn't reveal how close a guess was
+	valid := false
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	if !valid {
+		return status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return nil
+}
+
+// unary is the interceptor for single-response RPCs
+func (a apiKeyAuth) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.authenticate(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream is the interceptor for streaming RPCs
+func (a apiKeyAuth) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authenticate(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Settings holds user preferences persisted between runs
+type Settings struct {
+	DefaultModel   string `json:"defaultModel,omitempty"` // Last used model, selected at launch (empty = configured model)
+	PreloadModel   bool   `json:"preloadModel"`           // Load the default model in the background at launch
+	WatchClipboard bool   `json:"watchClipboard"`         // Offer to transcribe media files and URLs copied to the clipboard
+	CoreMLEncoder  bool   `json:"coreMLEncoder"`          // Run the encoder with Core ML on Apple Silicon when an encoder is available
+	ModelDir       string `json:"modelDir,omitempty"`     // Folder models are downloaded to (empty = ~/.cache/whisper; modelDir in config.json wins)
+	OnboardingDone bool   `json:"onboardingDone"`         // The first-run setup was finished or skipped
+
+	// Weekly check of the downloaded models for updated versions published by ivrit.ai
+	CheckModelUpdates    bool      `json:"checkModelUpdates"`
+	LastModelUpdateCheck time.Time `json:"lastModelUpdateCheck,omitempty"`
+
+	// Opt-in daily check of GitHub for a newer release of the app
+	CheckAppUpdates    bool      `json:"checkAppUpdates"`
+	LastAppUpdateCheck time.Time `json:"lastAppUpdateCheck,omitempty"`
+
+	// Window layout
+	UIDensity    string  `json:"uiDensity,omitempty"`   // UIDensityComfortable or UIDensityCompact
+	WindowWidth  float32 `json:"windowWidth,omitempty"` // Last window size in Dp (0 = default)
+	WindowHeight float32 `json:"windowHeight,omitempty"`
+
+	// Transcript display
+	TranscriptFontSize  float32 `json:"transcriptFontSize,omitempty"` // In Sp (0 = default)
+	LineSpacing         float32 `json:"lineSpacing,omitempty"`        // Multiple of the normal line height (0 = default)
+	MonospaceTranscript bool    `json:"monospaceTranscript"`
+	ShowTimestamps      bool    `json:"showTimestamps"`   // Prefix live transcript lines with their start time
+	ShowSpeakerStats    bool    `json:"showSpeakerStats"` // Show per-speaker statistics after the transcript and add them to saved text/markdown
+	HideEvents          bool    `json:"hideEvents"`       // Leave non-speech events such as [music] out of the transcript shown and saved
+	ShowTextStats       bool    `json:"showTextStats"`    // Show word, character and reading-time counts of the transcript
+
+	// Longest segment in characters at inference (0 = whisper's own segments; maxSegmentChars in config.json wins)
+	MaxSegmentChars int `json:"maxSegmentChars,omitempty"`
+
+	// Back-translate translations to flag segments that may be mistranslated
+	CheckTranslation bool `json:"checkTranslation"`
+
+	// Input recorded from last, by its name (empty = the system's default microphone)
+	RecordingDevice string `json:"recordingDevice,omitempty"`
+
+	// Processing seconds per second of audio, per model, measured on this machine (for ETAs)
+	RealtimeFactors map[string]float64 `json:"realtimeFactors,omitempty"`
+
+	// Fastest thread count per model, measured on this machine the first time the model is used
+	TunedThreads map[string]int `json:"tunedThreads,omitempty"`
+}
+
+// UI density options
+const (
+	UIDensityComfortable = "comfortable"
+	UIDensityCompact     = "compact"
+)
+
+// Window size bounds in Dp: the default size, and the smallest size the layout fits in
+const (
+	defaultWindowWidth  = 900
+	defaultWindowHeight = 700
+	minWindowWidth      = 640
+	minWindowHeight     = 480
+)
+
+// modelUpdateCheckInterval is how often the downloaded models are checked for updates
+const modelUpdateCheckInterval = 7 * 24 * time.Hour
+
+// appUpdateCheckInterval is how often GitHub is checked for a newer release of the app
+const appUpdateCheckInterval = 24 * time.Hour
+
+// compactSpacingScale shrinks margins and gaps in the compact layout
+const compactSpacingScale = 0.5
+
+// Transcript font size bounds in Sp
+const (
+	defaultTranscriptFontSize = 16
+	minTranscriptFontSize     = 10
+	maxTranscriptFontSize     = 40
+	transcriptFontSizeStep    = 2
+)
+
+// lineSpacingOptions are the line spacings offered in the GUI
+var lineSpacingOptions = []float32{1.0, 1.2, 1.5, 2.0}
+
+// defaultLineSpacing is Gio's normal line height
+const defaultLineSpacing = 1.2
+
+// defaultSettings returns the settings used when no settings file exists
+func defaultSettings() Settings {
+	return Settings{
+		DefaultModel:      "",
+		PreloadModel:      false,
+		WatchClipboard:    false,
+		CoreMLEncoder:     true,
+		CheckModelUpdates: true,
+		UIDensity:         UIDensityComfortable,
+		RealtimeFactors:   map[string]float64{},
+		TunedThreads:      map[string]int{},
+	}
+}
+
+// WindowSize returns the window size to open with in Dp: the last used size,
+// clamped so the window is never too small to use, or the default
+func (s Settings) WindowSize() (width, height float32) {
+	width, height = s.WindowWidth, s.WindowHeight
+	if width <= 0 || height <= 0 {
+		return defaultWindowWidth, defaultWindowHeight
+	}
+	if width < minWindowWidth {
+		width = minWindowWidth
+	}
+	if height < minWindowHeight {
+		height = minWindowHeight
+	}
+	return width, height
+}
+
+// TranscriptTextSize returns the transcript font size in Sp, within the supported bounds
+func (s Settings) TranscriptTextSize() float32 {
+	size := s.TranscriptFontSize
+	if size <= 0 {
+		return defaultTranscriptFontSize
+	}
+	return min(max(size, minTranscriptFontSize), maxTranscriptFontSize)
+}
+
+// TranscriptLineSpacing returns the transcript line spacing, or the default if
+// the saved one isn't one of the offered options
+func (s Settings) TranscriptLineSpacing() float32 {
+	for _, spacing := range lineSpacingOptions {
+		if s.LineSpacing == spacing {
+			return spacing
+		}
+	}
+	return defaultLineSpacing
+}
+
+// ModelUpdateCheckDue reports whether the downloaded models are due for their periodic update check
+func (s Settings) ModelUpdateCheckDue(now time.Time) bool {
+	return s.CheckModelUpdates && now.Sub(s.LastModelUpdateCheck) >= modelUpdateCheckInterval
+}
+
+// AppUpdateCheckDue reports whether the opted-in check for a newer release of the app is due
+func (s Settings) AppUpdateCheckDue(now time.Time) bool {
+	return s.CheckAppUpdates && now.Sub(s.LastAppUpdateCheck) >= appUpdateCheckInterval
+}
+
+// settingsPath returns the location of the user settings file
+func settingsPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "ivrit-ai", "settings.json")
+}
+
+// LoadSettings loads user settings, falling back to defaults for a missing or invalid file
+func LoadSettings() Settings {
+	settings := defaultSettings()
+
+	data, err := os.ReadFile(settingsPath())
+	if err != nil {
+		return settings
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return defaultSettings()
+	}
+	if settings.RealtimeFactors == nil {
+		settings.RealtimeFactors = map[string]float64{}
+	}
+	if settings.TunedThreads == nil {
+		settings.TunedThreads = map[string]int{}
+	}
+
+	return settings
+}
+
+// SaveSettings writes user settings to the settings file
+func SaveSettings(settings Settings) error {
+	path := settingsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
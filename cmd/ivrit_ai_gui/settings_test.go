@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSettingsRoundTrip tests saving and loading user settings
+func TestSettingsRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-settings-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("HOME", tmpDir)
+
+	// Missing file should give defaults
+	settings := LoadSettings()
+	if settings.DefaultModel != "" || settings.PreloadModel {
+		t.Errorf("Expected default settings, got %+v", settings)
+	}
+
+	settings.DefaultModel = "large-v3"
+	settings.PreloadModel = true
+	if err := SaveSettings(settings); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".config", "ivrit-ai", "settings.json")); err != nil {
+		t.Errorf("Settings file should exist: %v", err)
+	}
+
+	loaded := LoadSettings()
+	if loaded.DefaultModel != "large-v3" || !loaded.PreloadModel {
+		t.Errorf("Loaded settings = %+v, expected %+v", loaded, settings)
+	}
+}
+
+// TestLoadSettingsInvalidFile tests that a corrupt settings file falls back to defaults
+func TestLoadSettingsInvalidFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ivrit-settings-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("HOME", tmpDir)
+
+	path := settingsPath()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	settings := LoadSettings()
+	defaults := defaultSettings()
+	if settings.DefaultModel != defaults.DefaultModel || settings.PreloadModel != defaults.PreloadModel || len(settings.RealtimeFactors) != 0 {
+		t.Errorf("Expected default settings for invalid file, got %+v", settings)
+	}
+}
+
+// TestWindowSize tests restoring the window size with defaults and minimum bounds
+func TestWindowSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  float32
+		expectedWidth  float32
+		expectedHeight float32
+	}{
+		{"Never saved", 0, 0, defaultWindowWidth, defaultWindowHeight},
+		{"Saved size", 1400, 1000, 1400, 1000},
+		{"Too small", 300, 200, minWindowWidth, minWindowHeight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := Settings{WindowWidth: tt.width, WindowHeight: tt.height}
+			width, height := settings.WindowSize()
+			if width != tt.expectedWidth || height != tt.expectedHeight {
+				t.Errorf("WindowSize() = %vx%v, expected %vx%v", width, height, tt.expectedWidth, tt.expectedHeight)
+			}
+		})
+	}
+}
+
+// TestTranscriptDisplaySettings tests font size bounds and line spacing defaults
+func TestTranscriptDisplaySettings(t *testing.T) {
+	sizes := map[float32]float32{0: defaultTranscriptFontSize, 22: 22, 4: minTranscriptFontSize, 90: maxTranscriptFontSize}
+	for saved, expected := range sizes {
+		if size := (Settings{TranscriptFontSize: saved}).TranscriptTextSize(); size != expected {
+			t.Errorf("TranscriptTextSize() with %v saved = %v, expected %v", saved, size, expected)
+		}
+	}
+
+	spacings := map[float32]float32{0: defaultLineSpacing, 1.5: 1.5, 2.0: 2.0, 1.7: defaultLineSpacing}
+	for saved, expected := range spacings {
+		if spacing := (Settings{LineSpacing: saved}).TranscriptLineSpacing(); spacing != expected {
+			t.Errorf("TranscriptLineSpacing() with %v saved = %v, expected %v", saved, spacing, expected)
+		}
+	}
+}
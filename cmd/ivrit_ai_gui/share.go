@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// shareBodyLimit caps the transcript text put in a mailto: link when the file can't
+// be attached, as mail apps and Windows cut links at about 2000 characters
+const shareBodyLimit = 1500
+
+// macShareScript hands files to the Email service of the macOS share sheet, which
+// opens a message in the default mail app with them attached (osascript passes the
+// subject and the file paths as argv)
+const macShareScript = `function run(argv) {
+	ObjC.import("AppKit");
+	var service = $.NSSharingService.sharingServiceNamed($.NSSharingServiceNameComposeEmail);
+	service.subject = argv[0];
+	var items = $.NSMutableArray.array;
+	for (var i = 1; i < argv.length; i++) items.addObject($.NSURL.fileURLWithPath(argv[i]));
+	if (!service.canPerformWithItems(items)) throw new Error("no mail app can send the file");
+	service.performWithItems(items);
+}`
+
+// windowsShareScript opens a new Outlook message with the file attached, or a mailto:
+// link in the default mail app without Outlook. The subject, path and link come in
+// environment variables (shareEnv) rather than in the script, as PowerShell ends a
+// quoted string at typographic quotes too, e.g. a geresh typed as ’ in a file name.
+const windowsShareScript = `try {
+	$mail = (New-Object -ComObject Outlook.Application).CreateItem(0)
+	$mail.Subject = $env:IVRIT_SHARE_SUBJECT
+	$mail.Attachments.Add($env:IVRIT_SHARE_PATH) | Out-Null
+	$mail.Display()
+} catch {
+	Start-Process $env:IVRIT_SHARE_LINK
+}`
+
+// ShareFile sends a saved transcript by email: a new message in the default mail
+// app with the file attached, or with its text where attaching isn't possible
+func ShareFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	subject := shareSubject(path)
+	mailto := shareMailto(subject, decodeTranscriptText(data))
+	return runFileCommand(shareCommands(runtime.GOOS, path, subject, mailto), shareEnv(path, subject, mailto)...)
+}
+
+// shareEnv returns the environment windowsShareScript reads the email's fields from
+func shareEnv(path, subject, mailto string) []string {
+	return []string{"IVRIT_SHARE_SUBJECT=" + subject, "IVRIT_SHARE_PATH=" + path, "IVRIT_SHARE_LINK=" + mailto}
+}
+
+// shareSubject is the subject of the email sharing a transcript
+func shareSubject(path string) string {
+	return "Transcript: " + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// shareMailto returns a mailto: link to a new message with the subject and the start
+// of the transcript as its body
+func shareMailto(subject, body string) string {
+	if runes := []rune(body); len(runes) > shareBodyLimit {
+		body = string(runes[:shareBodyLimit]) + "…"
+	}
+	escape := func(s string) string { return strings.ReplaceAll(url.QueryEscape(s), "+", "%20") }
+	return "mailto:?subject=" + escape(subject) + "&body=" + escape(body)
+}
+
+// shareCommands returns the commands that share path by email on goos, in order of
+// preference; mailto is the fallback link without the attachment
+func shareCommands(goos, path, subject, mailto string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{
+			{"osascript", "-l", "JavaScript", "-e", macShareScript, subject, path},
+			{"open", mailto},
+		}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-NonInteractive", "-Command", windowsShareScript}}
+	default:
+		// xdg-email attaches the file in Thunderbird, Evolution and KMail
+		return [][]string{
+			{"xdg-email", "--subject", subject, "--attach", path},
+			{"xdg-open", mailto},
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestShareMailto tests the fallback link carrying the start of the transcript
+func TestShareMailto(t *testing.T) {
+	link := shareMailto(shareSubject("/tmp/my talk_transcription.txt"), "שלום & ברוכים הבאים")
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Scheme != "mailto" {
+		t.Fatalf("Invalid link %q: %v", link, err)
+	}
+	query, _ := url.ParseQuery(parsed.RawQuery)
+	if query.Get("subject") != "Transcript: my talk_transcription" || query.Get("body") != "שלום & ברוכים הבאים" {
+		t.Errorf("Unexpected link fields: %v", query)
+	}
+	if strings.Contains(link, "+") {
+		t.Errorf("Spaces escaped as + in %q, which mail apps keep", link)
+	}
+
+	long := shareMailto("Transcript", strings.Repeat("א", 3*shareBodyLimit))
+	parsed, _ = url.Parse(long)
+	query, _ = url.ParseQuery(parsed.RawQuery)
+	if body := []rune(query.Get("body")); len(body) != shareBodyLimit+1 {
+		t.Errorf("Body of %d characters, want %d and an ellipsis", len(body), shareBodyLimit)
+	}
+}
+
+// TestShareCommands tests the per-platform commands that email a transcript
+func TestShareCommands(t *testing.T) {
+	path, mailto := "/tmp/Dana's talk.srt", "mailto:?subject=Transcript"
+	darwin := shareCommands("darwin", path, "Transcript", mailto)
+	if len(darwin) != 2 || darwin[0][0] != "osascript" || darwin[0][len(darwin[0])-1] != path || darwin[1][1] != mailto {
+		t.Errorf("Unexpected macOS commands: %q", darwin)
+	}
+	linux := shareCommands("linux", path, "Transcript", mailto)
+	if len(linux) != 2 || strings.Join(linux[0], " ") != "xdg-email --subject Transcript --attach "+path || linux[1][1] != mailto {
+		t.Errorf("Unexpected Linux commands: %q", linux)
+	}
+	// Nothing from the file name goes into the script, e.g. a quote ending its string
+	path = `C:\Talks\a’; Start-Process calc; ’.txt`
+	windows := shareCommands("windows", path, "Transcript: a’; Start-Process calc; ’", mailto)
+	if script := windows[0][len(windows[0])-1]; script != windowsShareScript {
+		t.Errorf("Unexpected Windows script: %s", script)
+	}
+	env := shareEnv(path, "Transcript", mailto)
+	if env[1] != "IVRIT_SHARE_PATH="+path {
+		t.Errorf("Unexpected Windows environment: %q", env)
+	}
+}
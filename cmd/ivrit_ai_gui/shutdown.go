@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Graceful shutdown: Ctrl+C, SIGTERM or closing the GUI stops whisper at its next
+// abort check, saves what was transcribed so far to a recovery file, removes the
+// temporary files and frees the loaded models before the process exits
+
+// shutdownGrace is how long a shutdown waits for running transcriptions to stop
+const shutdownGrace = 10 * time.Second
+
+// ErrInterrupted is returned by transcriptions stopped by a shutdown
+var ErrInterrupted = errors.New("transcription interrupted")
+
+var (
+	shuttingDown         atomic.Bool
+	shutdownMutex        sync.Mutex     // Orders starting transcriptions against a shutdown
+	activeTranscriptions sync.WaitGroup // Transcriptions a shutdown waits for
+)
+
+// ShuttingDown reports whether the app is shutting down
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// beginTranscription registers a running transcription, which must call
+// endTranscription when it returns. It returns false once a shutdown started.
+func beginTranscription() bool {
+	shutdownMutex.Lock()
+	defer shutdownMutex.Unlock()
+	if shuttingDown.Load() {
+		return false
+	}
+	activeTranscriptions.Add(1)
+	return true
+}
+
+// endTranscription unregisters a transcription started by beginTranscription
+func endTranscription() {
+	activeTranscriptions.Done()
+}
+
+// Shutdown stops running transcriptions, waits up to shutdownGrace for them to
+// save their partial results, then removes temporary files and frees the models.
+// Models still in use by a transcription that didn't stop are left to the exit.
+func Shutdown() {
+	shutdownMutex.Lock()
+	shuttingDown.Store(true)
+	shutdownMutex.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		activeTranscriptions.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		RemoveTempFiles()
+		ClearModelCache()
+	case <-time.After(shutdownGrace):
+		RemoveTempFiles()
+	}
+}
+
+// ShutdownOnSignal shuts down gracefully and exits when the process is
+// interrupted (Ctrl+C) or terminated. A second signal exits at once.
+func ShutdownOnSignal() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Fprintln(os.Stderr, "\nStopping... (press Ctrl+C again to quit at once)")
+		go func() {
+			<-signals
+			os.Exit(130)
+		}()
+		Shutdown()
+		os.Exit(130)
+	}()
+}
+
+// AwaitShutdown blocks the calling goroutine while a shutdown finishes and exits
+// the process, so work interrupted by it isn't reported as failed
+func AwaitShutdown() {
+	if ShuttingDown() {
+		select {}
+	}
+}
+
+// recoveryDir returns where the partial results of interrupted transcriptions are saved
+func recoveryDir() string {
+	return filepath.Join(filepath.Dir(settingsPath()), "recovery")
+}
+
+// SaveRecovery writes the segments an interrupted transcription of audioPath had
+// produced as a JSON transcript in the recovery directory, and returns its path
+func SaveRecovery(audioPath string, segments []Segment) (string, error) {
+	if err := os.MkdirAll(recoveryDir(), 0755); err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(filepath.Base(audioPath), filepath.Ext(audioPath))
+	path := filepath.Join(recoveryDir(), fmt.Sprintf("%s_%s.json", base, time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(path, []byte(FormatOutput(segments, "json", "")), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// saveInterrupted saves the partial results of a transcription stopped by a
+// shutdown, reporting where they went, and returns ErrInterrupted
+func saveInterrupted(audioPath string, segments []Segment) error {
+	if len(segments) == 0 {
+		return ErrInterrupted
+	}
+	path, err := SaveRecovery(audioPath, segments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Cannot save the partial transcript: %v\n", err)
+		return ErrInterrupted
+	}
+	fmt.Fprintf(os.Stderr, "\nInterrupted: the %d segments transcribed so far are saved in %s\n", len(segments), path)
+	return ErrInterrupted
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestShutdownWaitsForTranscriptions tests that a shutdown waits for running
+// transcriptions and refuses new ones
+func TestShutdownWaitsForTranscriptions(t *testing.T) {
+	defer shuttingDown.Store(false)
+
+	if !beginTranscription() {
+		t.Fatal("Expected a transcription to start before shutdown")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		Shutdown()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Shutdown returned while a transcription was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !ShuttingDown() {
+		t.Error("Expected ShuttingDown to report the shutdown")
+	}
+	if beginTranscription() {
+		t.Error("Expected new transcriptions to be refused during shutdown")
+	}
+
+	endTranscription()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown didn't return after the transcription stopped")
+	}
+}
+
+// TestSaveInterrupted tests that partial results are saved as a readable JSON transcript
+func TestSaveInterrupted(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	segments := []Segment{{Start: 0, End: 2.5, Text: "שלום"}, {Start: 2.5, End: 4, Text: "עולם", Speaker: 1}}
+
+	if err := saveInterrupted("/recordings/meeting.m4a", segments); err != ErrInterrupted {
+		t.Fatalf("Expected ErrInterrupted, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(recoveryDir(), "*.json"))
+	if len(files) != 1 || !strings.HasPrefix(filepath.Base(files[0]), "meeting_") {
+		t.Fatalf("Expected one meeting_*.json recovery file, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	recovered, _, err := ParseTranscript(data, "json")
+	if err != nil || len(recovered) != 2 || recovered[1].Text != "עולם" {
+		t.Errorf("Recovery file doesn't hold the segments: %+v (error: %v)", recovered, err)
+	}
+
+	// Nothing transcribed yet: nothing to save
+	os.RemoveAll(recoveryDir())
+	saveInterrupted("/recordings/meeting.m4a", nil)
+	if _, err := os.Stat(recoveryDir()); !os.IsNotExist(err) {
+		t.Error("Expected no recovery file without segments")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import "math"
+
+// Silence detection tuning for splitting long audio into chunks
+const (
+	silenceFrameSamples  = 480  // 30ms frames at 16kHz
+	silenceRMSThreshold  = 0.01 // Frames quieter than this (about -40 dBFS) count as silence
+	silenceMinFrames     = 10   // Only pauses of at least 300ms are split points
+	minParallelChunkSecs = 60   // Don't bother splitting into chunks shorter than a minute
+	whisperSampleRate    = 16000
+)
+
+// Trimming of silence at the start and end of the audio
+const (
+	trimMinSilenceSecs = 2.0 // Shorter leading or trailing silence is left to whisper
+	trimPaddingSecs    = 0.5 // Silence kept before the first and after the last sound
+)
+
+// AudioChunk is a half-open sample range [Start, End) of the input audio
+type AudioChunk struct {
+	Start int
+	End   int
+}
+
+// findSilences returns the sample index at the middle of every pause of at
+// least silenceMinFrames quiet frames
+func findSilences(samples []float32) []int {
+	silences := []int{}
+	runStart := -1
+
+	nFrames := len(samples) / silenceFrameSamples
+	for f := 0; f <= nFrames; f++ {
+		quiet := false
+		if f < nFrames {
+			quiet = isQuietFrame(samples[f*silenceFrameSamples : (f+1)*silenceFrameSamples])
+		}
+
+		if quiet && runStart < 0 {
+			runStart = f
+		} else if !quiet && runStart >= 0 {
+			if f-runStart >= silenceMinFrames {
+				silences = append(silences, (runStart+f)*silenceFrameSamples/2)
+			}
+			runStart = -1
+		}
+	}
+
+	return silences
+}
+
+// isQuietFrame reports whether a frame's RMS level is below the silence threshold
+func isQuietFrame(frame []float32) bool {
+	sum := 0.0
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum/float64(len(frame))) < silenceRMSThreshold
+}
+
+// TrimSilence returns the sample range [start, end) of audio left after cutting
+// long silence from its start and end, keeping a little padding so whisper hears
+// the first and last words begin and end. Silence shorter than
+// trimMinSilenceSecs is kept, and so is audio that is silent throughout.
+func TrimSilence(samples []float32) (start, end int) {
+	nFrames := len(samples) / silenceFrameSamples
+	first, last := -1, -1
+	for f := 0; f < nFrames; f++ {
+		if !isQuietFrame(samples[f*silenceFrameSamples : (f+1)*silenceFrameSamples]) {
+			if first < 0 {
+				first = f
+			}
+			last = f
+		}
+	}
+	if first < 0 {
+		return 0, len(samples)
+	}
+
+	minSilence := int(trimMinSilenceSecs * whisperSampleRate)
+	padding := int(trimPaddingSecs * whisperSampleRate)
+	start, end = 0, len(samples)
+	if lead := first * silenceFrameSamples; lead >= minSilence {
+		start = lead - padding
+	}
+	if tail := len(samples) - (last+1)*silenceFrameSamples; tail >= minSilence {
+		end = (last+1)*silenceFrameSamples + padding
+	}
+	return start, end
+}
+
+// SplitAtSilences splits audio into up to n chunks of roughly equal length,
+// cutting at the pause nearest to each ideal boundary so that no word is cut
+// in half. Audio without usable pauses, or too short to be worth splitting,
+// is returned as a single chunk.
+func SplitAtSilences(samples []float32, n int) []AudioChunk {
+	whole := []AudioChunk{{Start: 0, End: len(samples)}}
+	if n <= 1 {
+		return whole
+	}
+
+	// Keep chunks long enough that whisper has context and setup cost is amortized
+	maxChunks := len(samples) / (minParallelChunkSecs * whisperSampleRate)
+	if n > maxChunks {
+		n = maxChunks
+	}
+	if n <= 1 {
+		return whole
+	}
+
+	silences := findSilences(samples)
+	if len(silences) == 0 {
+		return whole
+	}
+
+	chunks := []AudioChunk{}
+	start := 0
+	target := len(samples) / n
+	for i := 1; i < n; i++ {
+		ideal := i * target
+
+		// Closest pause to the ideal boundary that is after the current chunk start
+		best := -1
+		for _, s := range silences {
+			if s <= start {
+				continue
+			}
+			if best < 0 || abs(s-ideal) < abs(best-ideal) {
+				best = s
+			}
+		}
+		// Give up on this boundary if the nearest pause is more than half a chunk away
+		if best < 0 || abs(best-ideal) > target/2 {
+			continue
+		}
+
+		chunks = append(chunks, AudioChunk{Start: start, End: best})
+		start = best
+	}
+	chunks = append(chunks, AudioChunk{Start: start, End: len(samples)})
+
+	return chunks
+}
+
+// abs returns the absolute value of an int
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// sliceTimeRange cuts a time range out of 16kHz samples and returns the
+// slice together with the time (in seconds) at which it starts
+func sliceTimeRange(samples []float32, timeRange TimeRange) ([]float32, float64) {
+	start := int(timeRange.Start * whisperSampleRate)
+	if start > len(samples) {
+		start = len(samples)
+	}
+	end := len(samples)
+	if timeRange.End > 0 && int(timeRange.End*whisperSampleRate) < end {
+		end = int(timeRange.End * whisperSampleRate)
+	}
+	if end < start {
+		end = start
+	}
+	return samples[start:end], float64(start) / whisperSampleRate
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// toneWithPauses builds 16kHz audio of a 440Hz tone with a 1s pause every pauseEvery seconds
+func toneWithPauses(totalSecs, pauseEvery int) []float32 {
+	samples := make([]float32, totalSecs*whisperSampleRate)
+	for i := range samples {
+		sec := i / whisperSampleRate
+		if pauseEvery > 0 && sec%pauseEvery == pauseEvery-1 {
+			continue // silence
+		}
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/whisperSampleRate))
+	}
+	return samples
+}
+
+// TestSplitAtSilences tests that long audio is cut at pauses into contiguous chunks
+func TestSplitAtSilences(t *testing.T) {
+	samples := toneWithPauses(240, 20)
+
+	chunks := SplitAtSilences(samples, 4)
+	if len(chunks) != 4 {
+		t.Fatalf("Expected 4 chunks, got %d", len(chunks))
+	}
+
+	if chunks[0].Start != 0 || chunks[len(chunks)-1].End != len(samples) {
+		t.Errorf("Chunks should cover the whole input, got %+v", chunks)
+	}
+	for i := 1; i < len(chunks); i++ {
+		if chunks[i].Start != chunks[i-1].End {
+			t.Errorf("Chunk %d does not start where chunk %d ends: %+v", i, i-1, chunks)
+		}
+		// Every boundary must fall inside a pause
+		if samples[chunks[i].Start] != 0 {
+			t.Errorf("Chunk boundary %d at sample %d is not silent", i, chunks[i].Start)
+		}
+	}
+}
+
+// TestSplitAtSilencesSingleChunk tests the cases where audio is not split
+func TestSplitAtSilencesSingleChunk(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float32
+		n       int
+	}{
+		{"Parallel off", toneWithPauses(240, 20), 1},
+		{"Too short to split", toneWithPauses(90, 20), 4},
+		{"No pauses", toneWithPauses(240, 0), 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := SplitAtSilences(tt.samples, tt.n)
+			if len(chunks) != 1 || chunks[0].Start != 0 || chunks[0].End != len(tt.samples) {
+				t.Errorf("Expected a single chunk covering the input, got %+v", chunks)
+			}
+		})
+	}
+}
+
+// TestSliceTimeRange tests cutting a time range out of the sample buffer
+func TestSliceTimeRange(t *testing.T) {
+	samples := make([]float32, 10*whisperSampleRate)
+
+	tests := []struct {
+		name      string
+		timeRange TimeRange
+		wantLen   int
+		wantStart float64
+	}{
+		{"Start only", TimeRange{Start: 4}, 6 * whisperSampleRate, 4},
+		{"Start and end", TimeRange{Start: 2, End: 5}, 3 * whisperSampleRate, 2},
+		{"End past input", TimeRange{Start: 8, End: 20}, 2 * whisperSampleRate, 8},
+		{"Start past input", TimeRange{Start: 12}, 0, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice, start := sliceTimeRange(samples, tt.timeRange)
+			if len(slice) != tt.wantLen {
+				t.Errorf("Expected %d samples, got %d", tt.wantLen, len(slice))
+			}
+			if start != tt.wantStart {
+				t.Errorf("Expected start %v, got %v", tt.wantStart, start)
+			}
+		})
+	}
+}
+
+// TestTrimSilence tests cutting long silence from the start and end of audio
+func TestTrimSilence(t *testing.T) {
+	// 10s of silence, 5s of tone, 20s of silence, as in a voicemail recording
+	samples := make([]float32, 35*whisperSampleRate)
+	tone := toneWithPauses(5, 0)
+	copy(samples[10*whisperSampleRate:], tone)
+
+	// Silence is detected a 30ms frame at a time
+	start, end := TrimSilence(samples)
+	if expected := int((10 - trimPaddingSecs) * whisperSampleRate); abs(start-expected) > silenceFrameSamples {
+		t.Errorf("Expected the audio to start near sample %d, got %d", expected, start)
+	}
+	if expected := int((15 + trimPaddingSecs) * whisperSampleRate); abs(end-expected) > silenceFrameSamples {
+		t.Errorf("Expected the audio to end near sample %d, got %d", expected, end)
+	}
+
+	// Short silence is left to whisper
+	samples = make([]float32, 7*whisperSampleRate)
+	copy(samples[whisperSampleRate:], tone)
+	if start, end := TrimSilence(samples); start != 0 || end != len(samples) {
+		t.Errorf("Expected short silence kept, got [%d, %d)", start, end)
+	}
+
+	// So is audio that is silent throughout
+	samples = make([]float32, 30*whisperSampleRate)
+	if start, end := TrimSilence(samples); start != 0 || end != len(samples) {
+		t.Errorf("Expected silent audio kept whole, got [%d, %d)", start, end)
+	}
+}
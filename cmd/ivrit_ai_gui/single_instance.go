@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// instanceTimeout bounds how long a new launch waits on the running app before
+// starting on its own
+const instanceTimeout = 3 * time.Second
+
+// instanceRequest is what a new launch sends the running app: the files it was
+// asked to open (none to just bring the app to the front)
+type instanceRequest struct {
+	Files []string `json:"files"`
+}
+
+// InstanceLock is held by the running GUI: a local socket later launches hand their
+// files to instead of opening a second app
+type InstanceLock struct {
+	listener net.Listener
+	path     string
+}
+
+// instanceSocketPath returns the location of the socket, next to the user settings
+func instanceSocketPath() string {
+	return filepath.Join(filepath.Dir(settingsPath()), "instance.sock")
+}
+
+// AcquireInstanceLock makes this launch the running app, calling open with the files
+// of each later launch. When another app already holds the lock, files are handed to
+// it instead and forwarded is true: this launch should then exit.
+func AcquireInstanceLock(path string, files []string, open func(files []string)) (lock *InstanceLock, forwarded bool, err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, false, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		if forwardToInstance(path, files) == nil {
+			return nil, true, nil
+		}
+		// Left behind by an app that crashed: nothing answers on it
+		os.Remove(path)
+		if listener, err = net.Listen("unix", path); err != nil {
+			return nil, false, err
+		}
+	}
+	lock = &InstanceLock{listener: listener, path: path}
+	go lock.serve(open)
+	return lock, false, nil
+}
+
+// serve answers later launches until the lock is released
+func (l *InstanceLock) serve(open func(files []string)) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return // Released
+		}
+		go func() {
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(instanceTimeout))
+			var request instanceRequest
+			if err := json.NewDecoder(conn).Decode(&request); err != nil {
+				return
+			}
+			conn.Write([]byte("ok\n"))
+			open(request.Files)
+		}()
+	}
+}
+
+// Release stops answering later launches, which then start an app of their own
+func (l *InstanceLock) Release() {
+	l.listener.Close()
+	os.Remove(l.path)
+}
+
+// forwardToInstance hands files to the app holding the lock at path, with absolute
+// paths as it runs in another folder
+func forwardToInstance(path string, files []string) error {
+	request := instanceRequest{Files: []string{}}
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		request.Files = append(request.Files, file)
+	}
+	conn, err := net.DialTimeout("unix", path, instanceTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(instanceTimeout))
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return err
+	}
+	// Wait for the answer, so a socket nothing reads from counts as stale
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	return err
+}
+
+// launchedSession is a session window that files from later launches open in
+type launchedSession interface {
+	raise()
+	openLaunchedFile(path string) bool // Reports false, leaving the session alone, while it's busy
+}
+
+// launched tracks the open session windows, to hand the files of later launches to
+var launched struct {
+	sync.Mutex
+	sessions  []launchedSession // In the order they opened
+	pending   []string          // Files for the next window to open, nil = none
+	newWindow func()            // Opens a session window
+}
+
+// setLaunchWindowOpener sets how a session window is opened for files handed over
+// while the others are busy
+func setLaunchWindowOpener(open func()) {
+	launched.Lock()
+	defer launched.Unlock()
+	launched.newWindow = open
+}
+
+// openLaunchedFiles brings the session window opened last to the front and opens the
+// first of files in it. While it is busy, e.g. transcribing, the files are queued for
+// a new session window instead; before any window is up, for the first one.
+func openLaunchedFiles(files []string) {
+	launched.Lock()
+	if len(launched.sessions) == 0 {
+		launched.pending = append(launched.pending, files...)
+		launched.Unlock()
+		return
+	}
+	session := launched.sessions[len(launched.sessions)-1]
+	launched.Unlock()
+
+	session.raise()
+	if len(files) == 0 || session.openLaunchedFile(files[0]) {
+		return
+	}
+	launched.Lock()
+	launched.pending = files
+	newWindow := launched.newWindow
+	launched.Unlock()
+	if newWindow != nil {
+		newWindow()
+	}
+}
+
+// addLaunchedSession makes a session window the one later launches open files in,
+// handing it the files queued
+func addLaunchedSession(session launchedSession) {
+	launched.Lock()
+	defer launched.Unlock()
+	launched.sessions = append(launched.sessions, session)
+	if len(launched.pending) > 0 {
+		go session.openLaunchedFile(launched.pending[0])
+	}
+	launched.pending = nil
+}
+
+// removeLaunchedSession forgets a closed session window
+func removeLaunchedSession(session launchedSession) {
+	launched.Lock()
+	defer launched.Unlock()
+	for i, s := range launched.sessions {
+		if s == session {
+			launched.sessions = append(launched.sessions[:i], launched.sessions[i+1:]...)
+			return
+		}
+	}
+}
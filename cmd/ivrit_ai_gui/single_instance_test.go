@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestAcquireInstanceLock tests that a second launch hands its files to the running
+// app instead of starting
+func TestAcquireInstanceLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance.sock")
+	opened := make(chan []string, 1)
+	lock, forwarded, err := AcquireInstanceLock(path, nil, func(files []string) { opened <- files })
+	if err != nil || forwarded {
+		t.Fatalf("AcquireInstanceLock() = %v, %v for the first launch", forwarded, err)
+	}
+
+	if _, forwarded, err := AcquireInstanceLock(path, []string{"recording.m4a"}, nil); err != nil || !forwarded {
+		t.Fatalf("AcquireInstanceLock() = %v, %v for a second launch, expected it forwarded", forwarded, err)
+	}
+	want, _ := filepath.Abs("recording.m4a")
+	select {
+	case files := <-opened:
+		if !reflect.DeepEqual(files, []string{want}) {
+			t.Errorf("The running app got %v, want %v", files, []string{want})
+		}
+	case <-time.After(instanceTimeout):
+		t.Fatal("The running app got no files")
+	}
+
+	// Once the app quits, the next launch starts
+	lock.Release()
+	lock, forwarded, err = AcquireInstanceLock(path, nil, func([]string) {})
+	if err != nil || forwarded {
+		t.Fatalf("AcquireInstanceLock() = %v, %v after the app quit", forwarded, err)
+	}
+	lock.Release()
+}
+
+// TestAcquireInstanceLockStale tests starting over a socket left by an app that crashed
+func TestAcquireInstanceLockStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instance.sock")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	lock, forwarded, err := AcquireInstanceLock(path, nil, func([]string) {})
+	if err != nil || forwarded {
+		t.Fatalf("AcquireInstanceLock() = %v, %v over a stale socket", forwarded, err)
+	}
+	lock.Release()
+}
+
+// fakeSession is a session window that is busy or not
+type fakeSession struct {
+	sync.Mutex
+	busy   bool
+	raised bool
+	opened chan string
+}
+
+func (s *fakeSession) raise() {
+	s.Lock()
+	defer s.Unlock()
+	s.raised = true
+}
+
+func (s *fakeSession) openLaunchedFile(path string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.busy {
+		return false
+	}
+	s.opened <- path
+	return true
+}
+
+// TestOpenLaunchedFiles tests that a file handed over while the session is busy is
+// queued for a new window rather than replacing the session's input
+func TestOpenLaunchedFiles(t *testing.T) {
+	newWindows := 0
+	setLaunchWindowOpener(func() { newWindows++ })
+	defer setLaunchWindowOpener(nil)
+
+	first := &fakeSession{opened: make(chan string, 1)}
+	addLaunchedSession(first)
+	defer removeLaunchedSession(first)
+	openLaunchedFiles([]string{"/tmp/a.m4a"})
+	if path := <-first.opened; path != "/tmp/a.m4a" || !first.raised {
+		t.Errorf("The idle session opened %q, raised %v", path, first.raised)
+	}
+
+	first.busy = true // Transcribing
+	openLaunchedFiles([]string{"/tmp/b.m4a"})
+	if len(first.opened) != 0 || newWindows != 1 {
+		t.Fatalf("Expected b.m4a queued for a new window, got %d new windows", newWindows)
+	}
+	second := &fakeSession{opened: make(chan string, 1)}
+	addLaunchedSession(second)
+	defer removeLaunchedSession(second)
+	select {
+	case path := <-second.opened:
+		if path != "/tmp/b.m4a" {
+			t.Errorf("The new window opened %q", path)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The new window opened nothing")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// speakerLabelsNone leaves speaker labels out: as the name, of every format; as a
+// format's template, of that format
+const speakerLabelsNone = "none"
+
+// defaultSpeakerName labels speakers without a name
+const defaultSpeakerName = "Speaker {n}"
+
+// defaultSpeakerFormats is how each format writes a speaker label, where the speaker changes
+var defaultSpeakerFormats = map[string]string{
+	"text":     "{speaker}: ",
+	"srt":      "[{speaker}] ",
+	"vtt":      "<v {speaker}>",
+	"markdown": "{speaker}", // Turn headings, followed by their start time
+	"html":     "{speaker}",
+	"ttml":     "[{speaker}] ",
+	"stl":      "[{speaker}] ",
+}
+
+// SpeakerLabelOptions sets how speakers are labeled in transcripts, e.g. in Hebrew
+// ("דובר {n}"), as short tags ("SPK{n}" in "[{speaker}] ") or not at all
+type SpeakerLabelOptions struct {
+	Name    string            `json:"name,omitempty"`    // Label of speakers without a name, {n} standing for their number ("" = "Speaker {n}"; "none" hides labels)
+	Formats map[string]string `json:"formats,omitempty"` // Per format (text, srt, vtt, markdown, html, ttml, stl), the label as written, {speaker} standing for it; "none" hides labels
+}
+
+// Speaker labels from the config
+var (
+	speakerLabels      SpeakerLabelOptions
+	speakerLabelsMutex sync.RWMutex
+)
+
+// SetSpeakerLabels sets how FormatOutput and the other transcript writers label speakers
+func SetSpeakerLabels(options SpeakerLabelOptions) {
+	speakerLabelsMutex.Lock()
+	defer speakerLabelsMutex.Unlock()
+	speakerLabels = options
+}
+
+// currentSpeakerLabels returns the speaker label options in effect
+func currentSpeakerLabels() SpeakerLabelOptions {
+	speakerLabelsMutex.RLock()
+	defer speakerLabelsMutex.RUnlock()
+	return speakerLabels
+}
+
+// Validate checks the templates and the formats they are given for
+func (o SpeakerLabelOptions) Validate() error {
+	if o.Name != "" && o.Name != speakerLabelsNone && !strings.Contains(o.Name, "{n}") {
+		return fmt.Errorf("speaker label %q needs {n} for the speaker number, e.g. \"דובר {n}\"", o.Name)
+	}
+	for format, template := range o.Formats {
+		if _, ok := defaultSpeakerFormats[format]; !ok {
+			return fmt.Errorf("speaker labels can't be set for the %s format. Valid options: %s", format, strings.Join(speakerLabelFormats(), ", "))
+		}
+		if template != speakerLabelsNone && !strings.Contains(template, "{speaker}") {
+			return fmt.Errorf("the %s speaker label %q needs {speaker} where the label goes", format, template)
+		}
+	}
+	return nil
+}
+
+// speakerLabelFormats returns the formats whose speaker labels can be set
+func speakerLabelFormats() []string {
+	formats := make([]string, 0, len(defaultSpeakerFormats))
+	for format := range defaultSpeakerFormats {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// name returns the label of a speaker without a name (0-based, like Segment.Speaker)
+func (o SpeakerLabelOptions) name(speaker int) string {
+	template := o.Name
+	if template == "" || template == speakerLabelsNone {
+		template = defaultSpeakerName
+	}
+	return strings.ReplaceAll(template, "{n}", strconv.Itoa(speaker+1))
+}
+
+// template returns how the format writes a speaker label ("" = not at all)
+func (o SpeakerLabelOptions) template(format string) string {
+	if o.Name == speakerLabelsNone {
+		return ""
+	}
+	template := o.Formats[format]
+	if template == "" {
+		template = defaultSpeakerFormats[format]
+	}
+	if template == speakerLabelsNone {
+		return ""
+	}
+	return template
+}
+
+// Write returns the speaker label as the format writes it ("" when hidden)
+func (o SpeakerLabelOptions) Write(label, format string) string {
+	return strings.ReplaceAll(o.template(format), "{speaker}", label)
+}
+
+// linePattern returns a regular expression matching the label of a speaker without
+// a name as the format writes it, capturing the number ("" when hidden)
+func (o SpeakerLabelOptions) linePattern(format string) string {
+	template := o.template(format)
+	if template == "" {
+		return ""
+	}
+	name := o.Name
+	if name == "" {
+		name = defaultSpeakerName
+	}
+	label := strings.ReplaceAll(template, "{speaker}", name)
+	return strings.ReplaceAll(regexp.QuoteMeta(label), regexp.QuoteMeta("{n}"), `(\d+)`)
+}
+
+// speakerLinePrefix returns the segment's speaker label as the format writes it
+// where the speaker changes
+func speakerLinePrefix(seg Segment, format string) string {
+	return currentSpeakerLabels().Write(seg.SpeakerLabel(), format)
+}
+
+// speakerLabelPattern matches the speaker label starting a line of a transcript read
+// back in the format, as configured or as written by default
+func speakerLabelPattern(format string) *regexp.Regexp {
+	patterns := []string{SpeakerLabelOptions{}.linePattern(format)}
+	if configured := currentSpeakerLabels().linePattern(format); configured != "" && configured != patterns[0] {
+		patterns = append(patterns, configured)
+	}
+	return regexp.MustCompile("^(?:" + strings.Join(patterns, "|") + ")")
+}
+
+// speakerLabelNumber returns the speaker number captured by a match of speakerLabelPattern
+func speakerLabelNumber(match []string) string {
+	for _, number := range match[1:] {
+		if number != "" {
+			return number
+		}
+	}
+	return ""
+}
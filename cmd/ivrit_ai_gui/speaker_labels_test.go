@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSpeakerLabels tests custom, per-format and hidden speaker labels in transcripts
+func TestSpeakerLabels(t *testing.T) {
+	defer SetSpeakerLabels(SpeakerLabelOptions{})
+	segments := []Segment{
+		{Start: 0, End: 1, Text: "שלום", Speaker: 0},
+		{Start: 1, End: 2, Text: "מה נשמע", Speaker: 1, SpeakerName: "דנה"},
+	}
+
+	SetSpeakerLabels(SpeakerLabelOptions{Name: "דובר {n}", Formats: map[string]string{"srt": "[{speaker}] ", "text": "{speaker} - "}})
+	if got := FormatOutput(segments, "text", DisplayBilingual); !strings.HasPrefix(got, "דובר 1 - ") || !strings.Contains(got, "דנה - ") {
+		t.Errorf("Unexpected text labels:\n%s", got)
+	}
+	if got := FormatOutput(segments, "vtt", DisplayBilingual); !strings.Contains(got, "<v דובר 1>") {
+		t.Errorf("Expected the default VTT voice tag with the custom name:\n%s", got)
+	}
+	if got := FormatMarkdown(segments, ""); !strings.HasPrefix(got, "## דובר 1 (00:00:00)") {
+		t.Errorf("Unexpected markdown heading:\n%s", got)
+	}
+
+	SetSpeakerLabels(SpeakerLabelOptions{Name: "SPK{n}", Formats: map[string]string{"text": "none"}})
+	if got := FormatOutput(segments, "srt", DisplayBilingual); !strings.Contains(got, "[SPK1] שלום") {
+		t.Errorf("Expected [SPK1] in SRT:\n%s", got)
+	}
+	if got := FormatOutput(segments, "text", DisplayBilingual); strings.Contains(got, "SPK1") || strings.Contains(got, "דנה") {
+		t.Errorf("Expected no labels in text:\n%s", got)
+	}
+
+	SetSpeakerLabels(SpeakerLabelOptions{Name: "none"})
+	if got := FormatMarkdown(segments, ""); !strings.HasPrefix(got, "## 00:00:00\n") {
+		t.Errorf("Expected headings with only the time:\n%s", got)
+	}
+	if got := FormatOutput(segments, "srt", DisplayBilingual); strings.Contains(got, "[") {
+		t.Errorf("Expected no labels in SRT:\n%s", got)
+	}
+}
+
+// TestSpeakerLabelsReadBack tests that transcripts with custom or default labels are read back
+func TestSpeakerLabelsReadBack(t *testing.T) {
+	defer SetSpeakerLabels(SpeakerLabelOptions{})
+	defaultSRT := FormatOutput([]Segment{{Start: 0, End: 1, Text: "שלום", Speaker: 1}}, "srt", DisplayBilingual)
+
+	SetSpeakerLabels(SpeakerLabelOptions{Name: "דובר {n}"})
+	custom := FormatOutput([]Segment{{Start: 0, End: 1, Text: "שלום.", Speaker: 2}}, "text", DisplayBilingual)
+	segments, _, err := ParseTranscript([]byte(custom), "text")
+	if err != nil || len(segments) != 1 || segments[0].Speaker != 2 || segments[0].Text != "שלום." {
+		t.Errorf("Custom labels not read back: %+v (error: %v)", segments, err)
+	}
+	segments, _, err = ParseTranscript([]byte(defaultSRT), "srt")
+	if err != nil || len(segments) != 1 || segments[0].Speaker != 1 || segments[0].Text != "שלום" {
+		t.Errorf("Default labels not read back: %+v (error: %v)", segments, err)
+	}
+
+	// The label stays first when fixing punctuation
+	fixed := RTLOptions{FixPunctuation: true}.Apply("דובר 3: .שלום\n", "text")
+	if !strings.HasPrefix(fixed, "דובר 3: שלום.") {
+		t.Errorf("Expected the label kept first, got %q", fixed)
+	}
+}
+
+// TestSpeakerLabelOptionsValidate tests that templates need their placeholders
+func TestSpeakerLabelOptionsValidate(t *testing.T) {
+	valid := []SpeakerLabelOptions{
+		{},
+		{Name: "none"},
+		{Name: "SPK{n}", Formats: map[string]string{"srt": "[{speaker}]", "markdown": "none"}},
+	}
+	for _, options := range valid {
+		if err := options.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error: %v", options, err)
+		}
+	}
+	invalid := []SpeakerLabelOptions{
+		{Name: "דובר"},
+		{Formats: map[string]string{"json": "{speaker}"}},
+		{Formats: map[string]string{"text": "Speaker: "}},
+	}
+	for _, options := range invalid {
+		if options.Validate() == nil {
+			t.Errorf("Expected Validate(%+v) to fail", options)
+		}
+	}
+}
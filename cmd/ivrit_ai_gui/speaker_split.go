@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SpeakerTranscript is what one speaker said in a transcript
+type SpeakerTranscript struct {
+	Speaker  int // 0-based, like Segment.Speaker
+	Segments []Segment
+}
+
+// SplitBySpeaker groups the segments by speaker, ordered by speaker, keeping their
+// timestamps in the full recording
+func SplitBySpeaker(segments []Segment) []SpeakerTranscript {
+	bySpeaker := map[int][]Segment{}
+	for _, seg := range segments {
+		bySpeaker[seg.Speaker] = append(bySpeaker[seg.Speaker], seg)
+	}
+	transcripts := make([]SpeakerTranscript, 0, len(bySpeaker))
+	for speaker, segs := range bySpeaker {
+		transcripts = append(transcripts, SpeakerTranscript{Speaker: speaker, Segments: segs})
+	}
+	sort.Slice(transcripts, func(i, j int) bool { return transcripts[i].Speaker < transcripts[j].Speaker })
+	return transcripts
+}
+
+// Label names the speaker, like Segment.SpeakerLabel
+func (t SpeakerTranscript) Label() string {
+	if len(t.Segments) == 0 {
+		return Segment{Speaker: t.Speaker}.SpeakerLabel()
+	}
+	return t.Segments[0].SpeakerLabel()
+}
+
+// speakerFileName names a speaker's file after the transcript's (e.g. talk.txt →
+// talk_speaker2.txt)
+func speakerFileName(path string, speaker int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s_speaker%d%s", strings.TrimSuffix(path, ext), speaker+1, ext)
+}
+
+// FormatSpeakerTranscript formats one speaker's segments with the texts displayMode
+// selects. Plain text transcripts have no timestamps, so here each line starts with
+// its time; the other formats are those of the full transcript. Markdown timestamps
+// link to mediaURL.
+func FormatSpeakerTranscript(t SpeakerTranscript, format, displayMode, mediaURL string) string {
+	if format == "markdown" {
+		return FormatMarkdown(ApplyDisplayMode(t.Segments, displayMode), mediaURL)
+	}
+	if format != "text" {
+		return FormatOutput(t.Segments, format, displayMode)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", t.Label())
+	for _, seg := range ApplyDisplayMode(t.Segments, displayMode) {
+		stamp := "[" + FormatTimestamp(seg.Start, true)[:8] + "] "
+		indent := strings.Repeat(" ", len(stamp))
+		if seg.Original != "" && seg.Translation != "" {
+			b.WriteString(stamp + embedRTL(seg.Original) + "\n")
+			if seg.Transliteration != "" {
+				b.WriteString(indent + seg.Transliteration + "\n")
+			}
+			b.WriteString(indent + embedRTL(seg.Translation) + "\n")
+			continue
+		}
+		b.WriteString(stamp + embedRTL(seg.Text) + "\n")
+		if seg.Transliteration != "" {
+			b.WriteString(indent + seg.Transliteration + "\n")
+		}
+	}
+	return b.String()
+}
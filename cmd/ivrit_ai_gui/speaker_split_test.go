@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSplitBySpeaker tests grouping a transcript by speaker
+func TestSplitBySpeaker(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "שלום", Speaker: 1},
+		{Start: 2, End: 4, Text: "שלום לך", Speaker: 0},
+		{Start: 4, End: 6, Text: "מה שלומך?", Speaker: 1},
+	}
+
+	speakers := SplitBySpeaker(segments)
+	if len(speakers) != 2 || speakers[0].Speaker != 0 || speakers[1].Speaker != 1 {
+		t.Fatalf("Expected speakers 1 and 2 in order, got %+v", speakers)
+	}
+	if len(speakers[1].Segments) != 2 || speakers[1].Segments[1].Start != 4 {
+		t.Errorf("Expected the second speaker's segments with their timestamps, got %+v", speakers[1].Segments)
+	}
+
+	if name := speakerFileName("out/talk_transcription.srt", 1); name != "out/talk_transcription_speaker2.srt" {
+		t.Errorf("Unexpected speaker file name: %s", name)
+	}
+}
+
+// TestFormatSpeakerTranscript tests the per-speaker files
+func TestFormatSpeakerTranscript(t *testing.T) {
+	speaker := SpeakerTranscript{Speaker: 1, Segments: []Segment{
+		{Start: 0, End: 2, Text: "שלום", Speaker: 1},
+		{Start: 83, End: 85, Original: "מה שלומך?", Translation: "How are you?", Speaker: 1},
+	}}
+
+	text := FormatSpeakerTranscript(speaker, "text", DisplayBilingual, "")
+	for _, expected := range []string{"Speaker 2\n", "[00:00:00] \u202Bשלום\u202C\n", "[00:01:23] \u202Bמה שלומך?\u202C\n           How are you?\n"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, text)
+		}
+	}
+	if text := FormatSpeakerTranscript(speaker, "text", DisplayTranslation, ""); !strings.Contains(text, "[00:01:23] How are you?") {
+		t.Errorf("Expected only the translation shown, got:\n%s", text)
+	}
+
+	srt := FormatSpeakerTranscript(speaker, "srt", DisplayBilingual, "")
+	if !strings.HasPrefix(srt, "1\n00:00:00,000 --> ") || !strings.Contains(srt, "2\n00:01:23,000 --> ") {
+		t.Errorf("Expected the cues numbered from 1 with their timestamps, got:\n%s", srt)
+	}
+}
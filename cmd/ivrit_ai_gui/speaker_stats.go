@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// SpeakerStats summarizes how much one speaker talked
+type SpeakerStats struct {
+	Speaker          int     // 0-based, like Segment.Speaker
+	Name             string  // Speaker's name when known, like Segment.SpeakerName
+	TalkTime         float64 // Seconds
+	Share            float64 // Percentage of the total talk time
+	Words            int
+	Turns            int
+	LongestMonologue float64 // Duration of the longest turn in seconds
+	LongestStart     float64 // Where that turn starts
+}
+
+// ComputeSpeakerStats returns per-speaker statistics ordered by speaker. Words are
+// counted in the original Hebrew of translated segments.
+func ComputeSpeakerStats(segments []Segment) []SpeakerStats {
+	bySpeaker := map[int]*SpeakerStats{}
+	speaker := func(id int) *SpeakerStats {
+		if bySpeaker[id] == nil {
+			bySpeaker[id] = &SpeakerStats{Speaker: id}
+		}
+		return bySpeaker[id]
+	}
+
+	var total float64
+	segments = WithoutEvents(segments) // Music or laughter isn't anyone's talk time
+	for _, seg := range segments {
+		s := speaker(seg.Speaker)
+		if seg.SpeakerName != "" {
+			s.Name = seg.SpeakerName
+		}
+		text := seg.Text
+		if seg.Original != "" && seg.Translation != "" {
+			text = seg.Original
+		}
+		s.TalkTime += seg.End - seg.Start
+		s.Words += len(strings.Fields(text))
+		total += seg.End - seg.Start
+	}
+	for _, turn := range SpeakerTurns(segments) {
+		s := speaker(turn.Speaker)
+		s.Turns++
+		if duration := turn.End - turn.Start; duration > s.LongestMonologue {
+			s.LongestMonologue, s.LongestStart = duration, turn.Start
+		}
+	}
+
+	stats := make([]SpeakerStats, 0, len(bySpeaker))
+	for _, s := range bySpeaker {
+		if total > 0 {
+			s.Share = s.TalkTime * 100 / total
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Speaker < stats[j].Speaker })
+	return stats
+}
+
+// FormatSpeakerStats renders the statistics as a table: a Markdown table for the
+// markdown format and aligned plain text otherwise
+func FormatSpeakerStats(stats []SpeakerStats, format string) string {
+	header := []string{"Speaker", "Talk time", "Share", "Words", "Turns", "Longest monologue"}
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		rows[i] = []string{
+			Segment{Speaker: s.Speaker, SpeakerName: s.Name}.SpeakerLabel(),
+			FormatTimestamp(s.TalkTime, true)[:8],
+			fmt.Sprintf("%.0f%%", s.Share),
+			fmt.Sprintf("%d", s.Words),
+			fmt.Sprintf("%d", s.Turns),
+			fmt.Sprintf("%s (at %s)", FormatTimestamp(s.LongestMonologue, true)[:8], FormatTimestamp(s.LongestStart, true)[:8]),
+		}
+	}
+
+	var b strings.Builder
+	if format == "markdown" {
+		b.WriteString("## Speaker Statistics\n\n")
+		b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat("---|", len(header)) + "\n")
+		for _, row := range rows {
+			b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+		return b.String()
+	}
+
+	b.WriteString("Speaker statistics\n\n")
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+	return b.String()
+}
+
+// AppendSpeakerStats adds the statistics report to the end of a text or markdown
+// transcript. Other formats can't hold it, so they are returned unchanged with false.
+func AppendSpeakerStats(output string, segments []Segment, format string) (string, bool) {
+	if format != "text" && format != "markdown" {
+		return output, false
+	}
+	return strings.TrimRight(output, "\n") + "\n\n" + FormatSpeakerStats(ComputeSpeakerStats(segments), format), true
+}
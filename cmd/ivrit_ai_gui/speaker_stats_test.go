@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestComputeSpeakerStats tests talk time, share, words, turns and longest monologue
+func TestComputeSpeakerStats(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 10, Text: "שלום לכולם", Speaker: 0},
+		{Start: 10, End: 40, Text: "ברוכים הבאים לפודקאסט", Speaker: 0},
+		{Start: 40, End: 50, Original: "תודה רבה", Translation: "Thank you very much", Text: "Thank you very much", Speaker: 1},
+		{Start: 50, End: 60, Text: "בבקשה", Speaker: 0},
+		{Start: 60, End: 80, Text: "אז נתחיל", Speaker: 1},
+	}
+
+	stats := ComputeSpeakerStats(segments)
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 speakers, got %d", len(stats))
+	}
+
+	first := stats[0]
+	if first.Speaker != 0 || first.TalkTime != 50 || first.Share != 62.5 || first.Words != 6 || first.Turns != 2 {
+		t.Errorf("Unexpected first speaker stats: %+v", first)
+	}
+	if first.LongestMonologue != 40 || first.LongestStart != 0 {
+		t.Errorf("Expected a 40s monologue at 0s, got %vs at %vs", first.LongestMonologue, first.LongestStart)
+	}
+
+	second := stats[1]
+	if second.Words != 4 {
+		t.Errorf("Expected words counted in the original Hebrew, got %d", second.Words)
+	}
+	if second.LongestMonologue != 20 || second.LongestStart != 60 {
+		t.Errorf("Expected a 20s monologue at 60s, got %vs at %vs", second.LongestMonologue, second.LongestStart)
+	}
+}
+
+// TestFormatSpeakerStats tests the markdown table and appending to transcripts
+func TestFormatSpeakerStats(t *testing.T) {
+	segments := []Segment{{Start: 0, End: 90, Text: "שלום", Speaker: 0}}
+
+	expected := "## Speaker Statistics\n\n" +
+		"| Speaker | Talk time | Share | Words | Turns | Longest monologue |\n" +
+		"|---|---|---|---|---|---|\n" +
+		"| Speaker 1 | 00:01:30 | 100% | 1 | 1 | 00:01:30 (at 00:00:00) |\n"
+	if output := FormatSpeakerStats(ComputeSpeakerStats(segments), "markdown"); output != expected {
+		t.Errorf("FormatSpeakerStats() =\n%s\nexpected\n%s", output, expected)
+	}
+
+	output, ok := AppendSpeakerStats("Speaker 1: שלום\n", segments, "text")
+	if !ok || !strings.HasPrefix(output, "Speaker 1: שלום\n\nSpeaker statistics\n") {
+		t.Errorf("Expected statistics after the transcript, got:\n%s", output)
+	}
+	if _, ok := AppendSpeakerStats("1\n00:00:00,000 --> 00:01:30,000\nשלום\n", segments, "srt"); ok {
+		t.Error("Statistics should not be appended to subtitles")
+	}
+}
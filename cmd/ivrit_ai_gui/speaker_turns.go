@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpeakerTurn is a run of consecutive segments by the same speaker
+type SpeakerTurn struct {
+	Speaker  int    // 0-based, like Segment.Speaker
+	Name     string // Speaker's name when known, like Segment.SpeakerName
+	Start    float64
+	End      float64
+	Segments []Segment
+}
+
+// SpeakerTurns groups segments into turns, starting a new turn whenever the speaker changes
+func SpeakerTurns(segments []Segment) []SpeakerTurn {
+	turns := []SpeakerTurn{}
+	for _, seg := range segments {
+		if n := len(turns); n > 0 && turns[n-1].Speaker == seg.Speaker {
+			turns[n-1].End = seg.End
+			turns[n-1].Segments = append(turns[n-1].Segments, seg)
+			continue
+		}
+		turns = append(turns, SpeakerTurn{Speaker: seg.Speaker, Name: seg.SpeakerName, Start: seg.Start, End: seg.End, Segments: []Segment{seg}})
+	}
+	return turns
+}
+
+// Text joins the turn's text. For translated segments it returns the original
+// Hebrew and the translation separately; otherwise translation is empty.
+func (t SpeakerTurn) Text() (text, translation string) {
+	var texts, translations []string
+	for _, seg := range t.Segments {
+		if seg.Original != "" && seg.Translation != "" {
+			texts = append(texts, strings.TrimSpace(seg.Original))
+			translations = append(translations, strings.TrimSpace(seg.Translation))
+		} else {
+			texts = append(texts, strings.TrimSpace(seg.Text))
+		}
+	}
+	return strings.Join(texts, " "), strings.Join(translations, " ")
+}
+
+// Label names the turn's speaker, like Segment.SpeakerLabel
+func (t SpeakerTurn) Label() string {
+	return Segment{Speaker: t.Speaker, SpeakerName: t.Name}.SpeakerLabel()
+}
+
+// Heading labels the turn with its speaker and start time, e.g. "Speaker 2 (00:01:23)"
+func (t SpeakerTurn) Heading() string {
+	return fmt.Sprintf("%s (%s)", t.Label(), FormatTimestamp(t.Start, true)[:8])
+}
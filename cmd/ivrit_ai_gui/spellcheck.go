@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCorrectionChangeRatio is the largest share of a segment's words a correction may
+// change (at least one word); the model is told not to paraphrase, and replies changing
+// more are dropped
+const maxCorrectionChangeRatio = 0.4
+
+// SegmentCorrection is a fix the language model proposes for one segment's Hebrew text
+type SegmentCorrection struct {
+	Index  int // Segment index in the transcript
+	Before string
+	After  string
+}
+
+// CorrectSegments asks the model to fix obvious recognition errors in each segment's
+// Hebrew text and returns the proposed corrections, for the user to approve. Segments
+// the model leaves unchanged, or rewrites beyond a correction, yield none.
+func (t *MistralTranslator) CorrectSegments(segments []Segment, progressCallback func(string)) ([]SegmentCorrection, error) {
+	var corrections []SegmentCorrection
+	for i, seg := range segments {
+		text, ok := segmentHebrew(seg)
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Checking segment %d/%d...", i+1, len(segments)))
+		}
+
+		var previous, next string
+		if i > 0 {
+			previous, _ = segmentHebrew(segments[i-1])
+		}
+		if i < len(segments)-1 {
+			next, _ = segmentHebrew(segments[i+1])
+		}
+		reply, err := t.generate(correctionPrompt(text, previous, next), false)
+		if err != nil {
+			return corrections, err
+		}
+		if fixed := cleanCorrection(reply); isCorrection(text, fixed) {
+			corrections = append(corrections, SegmentCorrection{Index: i, Before: text, After: fixed})
+		}
+	}
+	return corrections, nil
+}
+
+// correctionPrompt builds the prompt for correcting one segment, with its neighbours as context
+func correctionPrompt(text, previous, next string) string {
+	return fmt.Sprintf(`You are proofreading a Hebrew speech recognition transcript. Fix only obvious recognition errors in the line below: misheard words, wrong homophones and misspellings. Do not paraphrase, reorder, translate, summarize, or add or remove content. If nothing needs fixing, output the line unchanged. Output only the corrected line, nothing else.
+
+Previous line (context only): %s
+Next line (context only): %s
+
+Line: %s
+
+Corrected line:`, previous, next, text)
+}
+
+// cleanCorrection strips labels and quotes the model sometimes wraps its reply in
+func cleanCorrection(reply string) string {
+	reply = strings.TrimSpace(strings.SplitN(reply, "\n", 2)[0])
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "Corrected line:"))
+	return strings.Trim(reply, `"“”`)
+}
+
+// isCorrection reports whether fixed changes text, but only in a few words
+func isCorrection(text, fixed string) bool {
+	if fixed == "" || readingKey(fixed) == readingKey(text) {
+		return false
+	}
+	deleted, inserted := 0, 0
+	for _, op := range WordDiff(text, fixed) {
+		switch op.Kind {
+		case DiffDelete:
+			deleted += len(strings.Fields(op.Text))
+		case DiffInsert:
+			inserted += len(strings.Fields(op.Text))
+		}
+	}
+	allowed := max(1, int(maxCorrectionChangeRatio*float64(len(strings.Fields(text)))))
+	return max(deleted, inserted) <= allowed
+}
+
+// segmentHebrew returns a segment's Hebrew text; translation-only segments have none
+func segmentHebrew(seg Segment) (string, bool) {
+	if seg.Original != "" {
+		return seg.Original, true
+	}
+	return seg.Text, seg.Translation == ""
+}
+
+// Word diff operation kinds
+const (
+	DiffEqual = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffOp is a run of words kept, deleted or inserted going from one text to another
+type DiffOp struct {
+	Kind int
+	Text string
+}
+
+// WordDiff compares two texts word by word (longest common subsequence). Where words
+// were replaced, the deletion comes before the insertion.
+func WordDiff(before, after string) []DiffOp {
+	a, b := strings.Fields(before), strings.Fields(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []DiffOp
+	add := func(kind int, word string) {
+		if n := len(ops); n > 0 && ops[n-1].Kind == kind {
+			ops[n-1].Text += " " + word
+			return
+		}
+		ops = append(ops, DiffOp{Kind: kind, Text: word})
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			add(DiffEqual, a[i])
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			add(DiffDelete, a[i])
+			i++
+		default:
+			add(DiffInsert, b[j])
+			j++
+		}
+	}
+	return ops
+}
+
+// DescribeChanges lists a correction's changes for review, one per changed run of
+// words: "old → new", "-removed" or "+added"
+func DescribeChanges(before, after string) []string {
+	var changes []string
+	ops := WordDiff(before, after)
+	for i := 0; i < len(ops); i++ {
+		op := ops[i]
+		switch {
+		case op.Kind == DiffDelete && i+1 < len(ops) && ops[i+1].Kind == DiffInsert:
+			changes = append(changes, op.Text+" → "+ops[i+1].Text)
+			i++
+		case op.Kind == DiffDelete:
+			changes = append(changes, "-"+op.Text)
+		case op.Kind == DiffInsert:
+			changes = append(changes, "+"+op.Text)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWordDiff tests the word diff and the change list shown for review
+func TestWordDiff(t *testing.T) {
+	ops := WordDiff("היום נדבר על החוזה של דני", "היום נדבר על החוזים של דני")
+	expected := []DiffOp{{DiffEqual, "היום נדבר על"}, {DiffDelete, "החוזה"}, {DiffInsert, "החוזים"}, {DiffEqual, "של דני"}}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("WordDiff() = %+v, expected %+v", ops, expected)
+	}
+
+	changes := DescribeChanges("אני הולך הבייתה עכשיו מיד", "אני הולך הביתה עכשיו")
+	if expected := []string{"הבייתה → הביתה", "-מיד"}; !reflect.DeepEqual(changes, expected) {
+		t.Errorf("DescribeChanges() = %q, expected %q", changes, expected)
+	}
+	if changes := DescribeChanges("שלום", "שלום לך"); !reflect.DeepEqual(changes, []string{"+לך"}) {
+		t.Errorf("DescribeChanges() insertion = %q", changes)
+	}
+}
+
+// TestIsCorrection tests telling a correction from no change or a paraphrase
+func TestIsCorrection(t *testing.T) {
+	tests := []struct {
+		text, fixed string
+		expected    bool
+	}{
+		{"אני הולך הבייתה עכשיו", "אני הולך הביתה עכשיו", true},
+		{"שלוםם", "שלום", true}, // A one-word segment may still be fixed
+		{"אני הולך הביתה עכשיו", "אני הולך הביתה, עכשיו.", false},   // Punctuation only
+		{"אני הולך הביתה עכשיו", "", false},                         // No reply
+		{"אני הולך הביתה עכשיו", "עכשיו אני בדרך חזרה לבית", false}, // Paraphrase
+	}
+	for _, tt := range tests {
+		if got := isCorrection(tt.text, tt.fixed); got != tt.expected {
+			t.Errorf("isCorrection(%q, %q) = %v, expected %v", tt.text, tt.fixed, got, tt.expected)
+		}
+	}
+
+	if fixed := cleanCorrection("Corrected line: \"אני הולך הביתה\"\nI fixed one word."); fixed != "אני הולך הביתה" {
+		t.Errorf("cleanCorrection() = %q", fixed)
+	}
+}
+
+// TestSegmentHebrew tests which segments have Hebrew text to correct
+func TestSegmentHebrew(t *testing.T) {
+	if text, ok := segmentHebrew(Segment{Text: "שלום"}); !ok || text != "שלום" {
+		t.Errorf("Plain segment: %q, %v", text, ok)
+	}
+	if text, ok := segmentHebrew(Segment{Original: "שלום", Translation: "Hello", Text: "Hello"}); !ok || text != "שלום" {
+		t.Errorf("Segment with original: %q, %v", text, ok)
+	}
+	if _, ok := segmentHebrew(Segment{Translation: "Hello", Text: "Hello"}); ok {
+		t.Error("Translation-only segments have no Hebrew text")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Subcommand is a verb of the CLI, e.g. "ivrit_ai transcribe talk.m4a". Each one
+// takes only the flags that apply to it and maps its arguments onto the flat
+// flags the CLI has always taken, which keep working without a subcommand.
+type Subcommand struct {
+	Name    string
+	Args    string   // Arguments after the options, for the usage line
+	Summary string   // One line for the list of commands
+	Flags   []string // CLI flags it takes
+	Shared  []string // Options shared with the GUI it takes (nil = all of them)
+
+	// apply sets the flat flags for the arguments after the options, returning
+	// the input files left over
+	apply func(fs *flag.FlagSet, args []string) ([]string, error)
+}
+
+// transcribeFlags are the CLI's own flags for transcribing files
+var transcribeFlags = []string{"input", "output", "from", "to", "export", "media-url", "minutes", "review", "split-speakers", "split-every", "speaker-stats", "check-translation", "manifest", "participants", "reuse", "start-time", "wall-clock", "coreml", "tune-threads", "join", "demo"}
+
+// subcommands lists the CLI's verbs in the order help shows them
+var subcommands = []Subcommand{
+	{
+		Name:    "transcribe",
+		Args:    "<audio-file>...",
+		Summary: "Transcribe audio or video files (-join for one recording in several files)",
+		Flags:   transcribeFlags,
+		apply:   inputArgs,
+	},
+	{
+		Name:    "batch",
+		Args:    "<files or folders>...",
+		Summary: "Transcribe many files, or every audio/video file in folders, into -output",
+		Flags:   withoutFlags(transcribeFlags, "join", "demo"),
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			var files []string
+			for _, arg := range args {
+				// A Zoom folder's video has the same audio as its audio-only file
+				if rec, ok := DetectMeetingRecording(arg); ok && rec.App == MeetingAppZoom && rec.Mixed != "" && rec.Dir == arg {
+					files = append(files, rec.Mixed)
+					continue
+				}
+				if info, err := os.Stat(arg); err == nil && info.IsDir() {
+					inDir, err := RecordingPartsInDir(arg)
+					if err != nil {
+						return nil, err
+					}
+					files = append(files, inDir...)
+					continue
+				}
+				files = append(files, arg)
+			}
+			if len(files) == 0 && fs.Lookup("input").Value.String() == "" {
+				return nil, errors.New("batch needs files or folders to transcribe")
+			}
+			return inputArgs(fs, files)
+		},
+	},
+	{
+		Name:    "translate",
+		Args:    "<folder>",
+		Summary: "Translate the transcripts saved in a folder to -lang",
+		Flags:   []string{"output"},
+		Shared:  []string{"lang", "glossary", "display", "numbers", "normalize-dates", "encoding", "line-endings", "fix-rtl", "strip-rtl-marks"},
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			if len(args) != 1 {
+				return nil, errors.New("translate needs the folder of transcripts to translate")
+			}
+			return nil, fs.Set("translate-dir", args[0])
+		},
+	},
+	{
+		Name:    "chat",
+		Args:    "<folder>",
+		Summary: "Transcribe the voice notes of an exported WhatsApp or Telegram chat into one transcript",
+		Flags:   []string{"output"},
+		Shared:  []string{"model", "model-dir", "threads", "engine", "engine-url", "beam-size", "temperature", "prompt", "ffmpeg", "ffprobe", "hf-endpoint", "download-rate-limit", "proxy", "ca-certs", "connect-timeout"},
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			if len(args) != 1 {
+				return nil, errors.New("chat needs the folder the chat was exported to")
+			}
+			return nil, fs.Set("chat", args[0])
+		},
+	},
+	{
+		Name:    "models",
+		Args:    "[list | check | update | redownload [model] | move <folder>]",
+		Summary: "List, update, download again or move the downloaded models",
+		Shared:  []string{"model", "model-dir", "hf-endpoint", "download-rate-limit", "proxy", "ca-certs", "connect-timeout"},
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			action := "list"
+			if len(args) > 0 {
+				action, args = args[0], args[1:]
+			}
+			switch {
+			case action == "list" && len(args) == 0:
+				return nil, fs.Set("list-models", "true")
+			case action == "check" && len(args) == 0:
+				return nil, fs.Set("check-model-updates", "true")
+			case action == "update" && len(args) == 0:
+				return nil, fs.Set("update-models", "true")
+			case action == "redownload" && len(args) <= 1:
+				if len(args) == 1 {
+					if err := fs.Set("model", args[0]); err != nil {
+						return nil, err
+					}
+				}
+				return nil, fs.Set("redownload-model", "true")
+			case action == "move" && len(args) == 1:
+				return nil, fs.Set("move-models", args[0])
+			}
+			return nil, fmt.Errorf("unknown models command %q", strings.Join(append([]string{action}, args...), " "))
+		},
+	},
+	{
+		Name:    "voice-memos",
+		Args:    "[<n>...]",
+		Summary: "List the recent Voice Memos recordings (macOS), or transcribe those numbered",
+		Flags:   withoutFlags(transcribeFlags, "input", "join", "demo"),
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			if len(args) == 0 {
+				return nil, fs.Set("list-voice-memos", "true")
+			}
+			memos, err := ListVoiceMemos(0)
+			if err != nil {
+				return nil, err
+			}
+			paths, err := voiceMemoPaths(memos, args)
+			if err != nil {
+				return nil, err
+			}
+			return inputArgs(fs, paths)
+		},
+	},
+	{
+		Name:    "serve",
+		Args:    "[address]",
+		Summary: "Serve the gRPC API (default address :50051); the options are the defaults for requests",
+		Flags:   []string{"coreml"},
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			addr := ":50051"
+			if len(args) > 1 {
+				return nil, errors.New("serve takes one address")
+			} else if len(args) == 1 {
+				addr = args[0]
+			}
+			return nil, fs.Set("grpc", addr)
+		},
+	},
+	{
+		Name:    "doctor",
+		Summary: "Check ffmpeg, the models, whisper.cpp and ollama, and say what to fix",
+		Shared:  []string{"model", "model-dir", "engine", "engine-url", "ffmpeg", "ffprobe", "hf-endpoint", "download-rate-limit", "proxy", "ca-certs", "connect-timeout"},
+		apply: func(fs *flag.FlagSet, args []string) ([]string, error) {
+			if len(args) > 0 {
+				return nil, errors.New("doctor takes no arguments")
+			}
+			return nil, fs.Set("doctor", "true")
+		},
+	},
+}
+
+// FindSubcommand returns the subcommand named name, or nil
+func FindSubcommand(name string) *Subcommand {
+	for i := range subcommands {
+		if subcommands[i].Name == name {
+			return &subcommands[i]
+		}
+	}
+	return nil
+}
+
+// IsSubcommand reports whether a first argument names a subcommand (or help), so
+// the CLI runs instead of the GUI
+func IsSubcommand(arg string) bool {
+	return arg == "help" || FindSubcommand(arg) != nil
+}
+
+// inputArgs makes the first argument -input, unless it was given, and returns the
+// rest as further inputs
+func inputArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	if len(args) == 0 || fs.Lookup("input").Value.String() != "" {
+		return args, nil
+	}
+	return args[1:], fs.Set("input", args[0])
+}
+
+// subcommandNames lists the subcommands' names
+func subcommandNames() []string {
+	names := make([]string, len(subcommands))
+	for i, c := range subcommands {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// withoutFlags returns flags without the names given
+func withoutFlags(flags []string, names ...string) []string {
+	var kept []string
+	for _, name := range flags {
+		if !containsString(names, name) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// sharedFlagNames lists the options shared with the GUI, as AppConfig registers them
+func sharedFlagNames() []string {
+	fs := flag.NewFlagSet("shared", flag.ContinueOnError)
+	cfg := DefaultConfig()
+	cfg.RegisterFlags(fs)
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	return names
+}
+
+// FlagSet returns the subcommand's flags, sharing their values with the flat flags
+// of all, so parsing either sets the same options
+func (c *Subcommand) FlagSet(program string, all *flag.FlagSet) *flag.FlagSet {
+	fs := flag.NewFlagSet(program+" "+c.Name, flag.ContinueOnError)
+	shared := c.Shared
+	if shared == nil {
+		shared = sharedFlagNames()
+	}
+	for _, name := range append(append([]string{}, c.Flags...), shared...) {
+		if f := all.Lookup(name); f != nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	fs.Usage = func() { c.PrintUsage(fs.Output(), program, fs) }
+	return fs
+}
+
+// PrintUsage describes the subcommand and its flags
+func (c *Subcommand) PrintUsage(w io.Writer, program string, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "%s\n\nUsage:\n  %s\n", c.Summary, strings.TrimSpace(fmt.Sprintf("%s %s [options] %s", program, c.Name, c.Args)))
+	fmt.Fprintln(w, "\nOptions:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// Parse parses the subcommand's arguments, setting the flat flags of all, and
+// returns the input files after them
+func (c *Subcommand) Parse(program string, all *flag.FlagSet, args []string) ([]string, error) {
+	fs := c.FlagSet(program, all)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	rest, err := c.apply(all, fs.Args())
+	if err != nil {
+		return nil, fmt.Errorf("%v (see %s %s -help)", err, program, c.Name)
+	}
+	return rest, nil
+}
+
+// printSubcommands lists the subcommands for the CLI's help
+func printSubcommands(w io.Writer, program string) {
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range subcommands {
+		fmt.Fprintf(w, "  %-11s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintf(w, "\nRun %s <command> -help for a command's options. Without a command, all options below apply.\n\n", program)
+}
+
+// subcommandHelp handles "help [command]", returning whether it printed a command's help
+func subcommandHelp(program string, all *flag.FlagSet, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	c := FindSubcommand(args[0])
+	if c == nil {
+		return false
+	}
+	c.PrintUsage(os.Stdout, program, c.FlagSet(program, all))
+	return true
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCLIFlags registers the flat flags the subcommands map onto
+func testCLIFlags() (*flag.FlagSet, *AppConfig) {
+	fs := flag.NewFlagSet("ivrit_ai", flag.ContinueOnError)
+	cfg := DefaultConfig()
+	cfg.RegisterFlags(fs)
+	for _, name := range []string{"input", "output", "from", "to", "export", "media-url", "split-every", "translate-dir", "grpc", "move-models"} {
+		fs.String(name, "", name)
+	}
+	for _, name := range []string{"minutes", "review", "split-speakers", "speaker-stats", "coreml", "tune-threads", "join", "demo", "list-models", "check-model-updates", "update-models", "redownload-model", "doctor"} {
+		fs.Bool(name, false, name)
+	}
+	return fs, &cfg
+}
+
+// TestSubcommandParse tests mapping the subcommands onto the flat flags
+func TestSubcommandParse(t *testing.T) {
+	tests := []struct {
+		args     []string
+		inputs   []string
+		expected map[string]string
+	}{
+		{[]string{"transcribe", "-format", "srt", "a.m4a", "b.m4a"}, []string{"b.m4a"}, map[string]string{"input": "a.m4a", "format": "srt"}},
+		{[]string{"transcribe", "-join", "-input", "a.mp3", "b.mp3"}, []string{"b.mp3"}, map[string]string{"input": "a.mp3", "join": "true"}},
+		{[]string{"translate", "-lang", "fr", "transcripts"}, nil, map[string]string{"translate-dir": "transcripts", "lang": "fr"}},
+		{[]string{"models"}, nil, map[string]string{"list-models": "true"}},
+		{[]string{"models", "check"}, nil, map[string]string{"check-model-updates": "true"}},
+		{[]string{"models", "redownload", "turbo"}, nil, map[string]string{"redownload-model": "true", "model": "turbo"}},
+		{[]string{"models", "-model-dir", "/Volumes/models", "move", "old"}, nil, map[string]string{"move-models": "old", "model-dir": "/Volumes/models"}},
+		{[]string{"serve"}, nil, map[string]string{"grpc": ":50051"}},
+		{[]string{"serve", "-model", "turbo", "127.0.0.1:9000"}, nil, map[string]string{"grpc": "127.0.0.1:9000", "model": "turbo"}},
+		{[]string{"doctor", "-ffmpeg", "/opt/ffmpeg"}, nil, map[string]string{"doctor": "true", "ffmpeg": "/opt/ffmpeg"}},
+	}
+	for _, tt := range tests {
+		all, _ := testCLIFlags()
+		inputs, err := FindSubcommand(tt.args[0]).Parse("ivrit_ai", all, tt.args[1:])
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(inputs, tt.inputs) {
+			t.Errorf("%v: inputs %v, expected %v", tt.args, inputs, tt.inputs)
+		}
+		for name, value := range tt.expected {
+			if got := all.Lookup(name).Value.String(); got != value {
+				t.Errorf("%v: -%s = %q, expected %q", tt.args, name, got, value)
+			}
+		}
+	}
+}
+
+// TestSubcommandErrors tests flags and arguments a subcommand doesn't take
+func TestSubcommandErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"translate", "-model", "turbo", "transcripts"},
+		{"translate"},
+		{"models", "delete"},
+		{"serve", ":1", ":2"},
+		{"doctor", "extra"},
+		{"batch"},
+	} {
+		all, _ := testCLIFlags()
+		c := FindSubcommand(args[0])
+		var out bytes.Buffer
+		fs := c.FlagSet("ivrit_ai", all)
+		fs.SetOutput(&out)
+		if err := fs.Parse(args[1:]); err == nil {
+			_, err = c.apply(all, fs.Args())
+			if err == nil {
+				t.Errorf("Expected an error for %v", args)
+			}
+		}
+	}
+}
+
+// TestBatchFolders tests that batch expands folders to their media files
+func TestBatchFolders(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"track10.mp3", "track2.mp3", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	all, _ := testCLIFlags()
+	inputs, err := FindSubcommand("batch").Parse("ivrit_ai", all, []string{"-output", "out", dir, "extra.wav"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input := all.Lookup("input").Value.String(); input != filepath.Join(dir, "track2.mp3") {
+		t.Errorf("Unexpected first input: %s", input)
+	}
+	if !reflect.DeepEqual(inputs, []string{filepath.Join(dir, "track10.mp3"), "extra.wav"}) {
+		t.Errorf("Unexpected inputs: %v", inputs)
+	}
+}
+
+// TestSubcommandUsage tests the help of a subcommand, which lists only its flags
+func TestSubcommandUsage(t *testing.T) {
+	all, _ := testCLIFlags()
+	c := FindSubcommand("translate")
+	var out bytes.Buffer
+	c.PrintUsage(&out, "ivrit_ai", c.FlagSet("ivrit_ai", all))
+	usage := out.String()
+	if !strings.Contains(usage, "ivrit_ai translate [options] <folder>") || !strings.Contains(usage, "-lang") {
+		t.Errorf("Unexpected usage:\n%s", usage)
+	}
+	if strings.Contains(usage, "-model ") || strings.Contains(usage, "-input") {
+		t.Errorf("Expected only translate's flags:\n%s", usage)
+	}
+
+	if !IsSubcommand("doctor") || !IsSubcommand("help") || IsSubcommand("recording.m4a") {
+		t.Error("Unexpected subcommand detection")
+	}
+}
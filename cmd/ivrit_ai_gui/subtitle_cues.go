@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Valid cue option values
+var (
+	validVTTAligns = []string{"start", "center", "end", "left", "right"}
+	vttLinePattern = regexp.MustCompile(`^(?:-?\d+|\d{1,3}(?:\.\d+)?%)(?:,(?:start|center|end))?$`)
+	vttPosPattern  = regexp.MustCompile(`^\d{1,3}(?:\.\d+)?%(?:,(?:line-left|center|line-right))?$`)
+)
+
+// maxCueChars is the longest cue, two lines of 42 characters, kept whole; longer
+// segments of several sentences are split at sentence ends
+const maxCueChars = 84
+
+// srtAlignTags maps SRT positions to the {\anN} tags (numpad layout) that players
+// such as VLC, mpv and MPC-HC honor in SRT files
+var srtAlignTags = map[string]int{
+	"bottom-left": 1, "bottom-center": 2, "bottom-right": 3,
+	"middle-left": 4, "middle-center": 5, "middle-right": 6,
+	"top-left": 7, "top-center": 8, "top-right": 9,
+}
+
+// srtAlignTag matches an {\anN} tag starting a cue's text
+var srtAlignTag = regexp.MustCompile(`^\{\\an[1-9]\}`)
+
+// CueOptions places subtitle cues on screen, e.g. bottom-right so Hebrew lines start
+// at the right edge in players that would otherwise center or left-align them
+type CueOptions struct {
+	Line     string `json:"line,omitempty"`     // VTT line: a percentage from the top ("90%") or a line number ("-1" = bottom line)
+	Align    string `json:"align,omitempty"`    // VTT text alignment: start, center, end, left or right
+	Position string `json:"position,omitempty"` // VTT position of the cue box across the video, a percentage ("90%")
+	SRTAlign string `json:"srtAlign,omitempty"` // SRT position: bottom-right, bottom-center, top-left... (written as an {\an} tag)
+}
+
+// Validate checks the cue settings
+func (o CueOptions) Validate() error {
+	if o.Line != "" && !vttLinePattern.MatchString(o.Line) {
+		return fmt.Errorf("invalid VTT line %q: use a percentage such as 90%% or a line number such as -1", o.Line)
+	}
+	if o.Align != "" && !containsString(validVTTAligns, o.Align) {
+		return fmt.Errorf("Invalid VTT alignment '%s'. Valid options: %s", o.Align, strings.Join(validVTTAligns, ", "))
+	}
+	if o.Position != "" && !vttPosPattern.MatchString(o.Position) {
+		return fmt.Errorf("invalid VTT position %q: use a percentage such as 90%%", o.Position)
+	}
+	if o.SRTAlign != "" && srtAlignTags[o.SRTAlign] == 0 {
+		return fmt.Errorf("Invalid SRT alignment '%s'. Valid options: bottom-left, bottom-center, bottom-right, middle-left, middle-center, middle-right, top-left, top-center, top-right", o.SRTAlign)
+	}
+	return nil
+}
+
+// VTTSettings returns the cue settings written after each VTT timing, e.g.
+// "line:90% position:95% align:right" ("" = none)
+func (o CueOptions) VTTSettings() string {
+	var settings []string
+	if o.Line != "" {
+		settings = append(settings, "line:"+o.Line)
+	}
+	if o.Position != "" {
+		settings = append(settings, "position:"+o.Position)
+	}
+	if o.Align != "" {
+		settings = append(settings, "align:"+o.Align)
+	}
+	return strings.Join(settings, " ")
+}
+
+// Apply returns an SRT or VTT transcript with the cue settings added; other formats
+// are returned unchanged
+func (o CueOptions) Apply(text, format string) string {
+	var addTiming, addText string
+	switch format {
+	case "vtt":
+		if settings := o.VTTSettings(); settings != "" {
+			addTiming = " " + settings
+		}
+	case "srt":
+		if o.SRTAlign != "" {
+			addText = fmt.Sprintf(`{\an%d}`, srtAlignTags[o.SRTAlign])
+		}
+	}
+	if addTiming == "" && addText == "" {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+		lines[i] = line + addTiming
+		if addText != "" && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			lines[i+1] = addText + lines[i+1]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SplitLongCues splits segments too long for one subtitle cue into a cue per group
+// of sentences, timed in proportion to their length. Translated, transliterated and
+// event segments, and single long sentences, are kept whole.
+func SplitLongCues(segments []Segment) []Segment {
+	cues := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		if seg.Translation != "" || seg.Transliteration != "" || seg.Event != "" || len([]rune(seg.Text)) <= maxCueChars {
+			cues = append(cues, seg)
+			continue
+		}
+		parts := ChunkSentences(seg.Text, maxCueChars)
+		if len(parts) < 2 {
+			cues = append(cues, seg)
+			continue
+		}
+
+		total := 0
+		for _, part := range parts {
+			total += len([]rune(part))
+		}
+		start, done := seg.Start, 0
+		for i, part := range parts {
+			cue := seg
+			cue.Text = part
+			cue.Tokens = nil
+			cue.Start = start
+			done += len([]rune(part))
+			cue.End = seg.Start + (seg.End-seg.Start)*float64(done)/float64(total)
+			if i == len(parts)-1 {
+				cue.End = seg.End
+			}
+			start = cue.End
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCueOptionsApply tests VTT cue settings and SRT position tags
+func TestCueOptionsApply(t *testing.T) {
+	segments := []Segment{{Start: 0, End: 1, Text: "שלום"}, {Start: 1, End: 2, Text: "עולם"}}
+	cues := CueOptions{Line: "90%", Align: "right", Position: "95%", SRTAlign: "bottom-right"}
+
+	vtt := cues.Apply(FormatOutput(segments, "vtt", DisplayBilingual), "vtt")
+	if !strings.Contains(vtt, "00:00:00.000 --> 00:00:01.000 line:90% position:95% align:right\n<v Speaker 1>שלום") {
+		t.Errorf("Expected cue settings after the VTT timing:\n%s", vtt)
+	}
+	srt := cues.Apply(FormatOutput(segments, "srt", DisplayBilingual), "srt")
+	if strings.Count(srt, "{\\an3}") != 2 || !strings.Contains(srt, "00:00:01,000\n{\\an3}[Speaker 1] שלום") {
+		t.Errorf("Expected an {\\an3} tag starting each SRT cue:\n%s", srt)
+	}
+	if text := FormatOutput(segments, "text", DisplayBilingual); cues.Apply(text, "text") != text {
+		t.Error("Expected text transcripts unchanged")
+	}
+	if plain := FormatOutput(segments, "vtt", DisplayBilingual); (CueOptions{}).Apply(plain, "vtt") != plain {
+		t.Error("Expected no change without cue settings")
+	}
+
+	// Read back without the settings and tags
+	for format, data := range map[string]string{"vtt": vtt, "srt": srt} {
+		read, _, err := ParseTranscript([]byte(data), format)
+		if err != nil || len(read) != 2 || read[0].Text != "שלום" || read[1].End != 2 {
+			t.Errorf("%s not read back: %+v (error: %v)", format, read, err)
+		}
+	}
+}
+
+// TestCueOptionsValidate tests the accepted cue settings
+func TestCueOptionsValidate(t *testing.T) {
+	valid := []CueOptions{{}, {Line: "-1", Align: "end"}, {Line: "85.5%,end", Position: "100%,line-right", SRTAlign: "top-right"}}
+	for _, cues := range valid {
+		if err := cues.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error: %v", cues, err)
+		}
+	}
+	invalid := []CueOptions{{Line: "bottom"}, {Align: "justify"}, {Position: "90"}, {SRTAlign: "right"}}
+	for _, cues := range invalid {
+		if cues.Validate() == nil {
+			t.Errorf("Expected Validate(%+v) to fail", cues)
+		}
+	}
+}
+
+// TestSplitLongCues tests that a long segment becomes a cue per sentence, timed by
+// length, and that short, translated and single-sentence segments are kept whole
+func TestSplitLongCues(t *testing.T) {
+	first := strings.Repeat("א", 60) + "."
+	second := "ד\"ר כהן הגיע " + strings.Repeat("ב", 45) + "."
+	long := strings.Repeat("ג", 100)
+	segments := []Segment{
+		{Start: 0, End: 1, Text: "שלום. מה שלומך?"},
+		{Start: 10, End: 20, Text: first + " " + second, Speaker: 1},
+		{Start: 20, End: 25, Text: long + ". " + long, Original: long, Translation: "Long"},
+		{Start: 30, End: 35, Text: long + " " + long},
+	}
+
+	cues := SplitLongCues(segments)
+	if len(cues) != 5 {
+		t.Fatalf("Expected 5 cues, got %d: %+v", len(cues), cues)
+	}
+	if cues[1].Text != first || cues[2].Text != second || cues[1].Speaker != 1 || cues[2].Speaker != 1 {
+		t.Errorf("Not split at the sentence end: %q, %q", cues[1].Text, cues[2].Text)
+	}
+	if cues[1].Start != 10 || cues[1].End != cues[2].Start || cues[2].End != 20 || cues[1].End < 14 || cues[1].End > 16 {
+		t.Errorf("Unexpected timing: %v-%v, %v-%v", cues[1].Start, cues[1].End, cues[2].Start, cues[2].End)
+	}
+
+	if srt := FormatOutput(segments[1:2], "srt", DisplayBilingual); !strings.Contains(srt, "\n2\n") {
+		t.Errorf("Expected two SRT cues:\n%s", srt)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// hlsSubtitleGroup is the GROUP-ID of the subtitle renditions in the HLS manifest; the
+// video's variant streams refer to it with SUBTITLES="subs"
+const hlsSubtitleGroup = "subs"
+
+// SubtitleTrack is one language of a translated transcript, written as its own VTT
+// file for players that offer a choice of subtitles
+type SubtitleTrack struct {
+	Language    string // Language code, e.g. "he"
+	DisplayMode string // The display mode showing the language's text
+}
+
+// TrackFile is a file of the subtitle tracks: a VTT track or an HLS playlist
+type TrackFile struct {
+	Path   string
+	Format string // "vtt" or "m3u8"
+	Text   string
+}
+
+// SubtitleTracks returns the Hebrew and target language tracks of a translated
+// transcript (nil when no segment is translated)
+func SubtitleTracks(segments []Segment, targetLang string) []SubtitleTrack {
+	for _, seg := range segments {
+		if seg.Original != "" && seg.Translation != "" {
+			return []SubtitleTrack{
+				{Language: "he", DisplayMode: DisplayOriginal},
+				{Language: targetLang, DisplayMode: DisplayTranslation},
+			}
+		}
+	}
+	return nil
+}
+
+// trackFileName returns path with the language before its extension, e.g. talk.he.vtt
+func trackFileName(path, language, ext string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + language + "." + ext
+}
+
+// SubtitleTrackFiles returns the files of a transcript's subtitle tracks named after
+// path (e.g. talk.vtt): a VTT file and an HLS media playlist per language
+// (talk.he.vtt, talk.he.m3u8...), and an HLS manifest listing the languages as
+// selectable subtitles (talk.m3u8)
+func SubtitleTrackFiles(segments []Segment, targetLang, path string) []TrackFile {
+	tracks := SubtitleTracks(segments, targetLang)
+	if tracks == nil {
+		return nil
+	}
+	duration := 0.0
+	for _, seg := range segments {
+		duration = math.Max(duration, seg.End)
+	}
+
+	var files []TrackFile
+	manifest := "#EXTM3U\n"
+	for i, track := range tracks {
+		vttPath := trackFileName(path, track.Language, "vtt")
+		playlistPath := trackFileName(path, track.Language, "m3u8")
+		files = append(files,
+			TrackFile{Path: vttPath, Format: "vtt", Text: FormatOutput(segments, "vtt", track.DisplayMode)},
+			TrackFile{Path: playlistPath, Format: "m3u8", Text: FormatTrackPlaylist(filepath.Base(vttPath), duration)},
+		)
+		isDefault := "NO"
+		if i == 0 {
+			isDefault = "YES"
+		}
+		manifest += fmt.Sprintf("#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID=%q,NAME=%q,LANGUAGE=%q,DEFAULT=%s,AUTOSELECT=YES,URI=%q\n",
+			hlsSubtitleGroup, languageName(track.Language), track.Language, isDefault, filepath.Base(playlistPath))
+	}
+	manifestPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".m3u8"
+	return append(files, TrackFile{Path: manifestPath, Format: "m3u8", Text: manifest})
+}
+
+// FormatTrackPlaylist returns the HLS media playlist of a VTT track as a single
+// segment lasting the transcript's duration
+func FormatTrackPlaylist(vttName string, duration float64) string {
+	return fmt.Sprintf("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:0\n#EXTINF:%.3f,\n%s\n#EXT-X-ENDLIST\n",
+		int(math.Ceil(duration)), duration, vttName)
+}
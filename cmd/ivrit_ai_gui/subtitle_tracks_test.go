@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSubtitleTrackFiles tests per-language VTT tracks and their HLS playlists
+func TestSubtitleTrackFiles(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2, Text: "Hello", Original: "שלום", Translation: "Hello"},
+		{Start: 2, End: 4.5, Text: "World", Original: "עולם", Translation: "World"},
+	}
+	files := SubtitleTrackFiles(segments, "en", "/out/talk.vtt")
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	want := "/out/talk.he.vtt /out/talk.he.m3u8 /out/talk.en.vtt /out/talk.en.m3u8 /out/talk.m3u8"
+	if strings.Join(paths, " ") != want {
+		t.Fatalf("Expected %s, got %v", want, paths)
+	}
+
+	if he := files[0].Text; !strings.Contains(he, "שלום") || strings.Contains(he, "Hello") {
+		t.Errorf("Expected only Hebrew in the Hebrew track:\n%s", he)
+	}
+	if en := files[2].Text; !strings.HasPrefix(en, "WEBVTT") || !strings.Contains(en, "Hello") || strings.Contains(en, "שלום") {
+		t.Errorf("Expected only English in the English track:\n%s", en)
+	}
+	if playlist := files[1].Text; !strings.Contains(playlist, "#EXT-X-TARGETDURATION:5\n") || !strings.Contains(playlist, "#EXTINF:4.500,\ntalk.he.vtt\n#EXT-X-ENDLIST") {
+		t.Errorf("Unexpected track playlist:\n%s", playlist)
+	}
+	manifest := files[4].Text
+	for _, line := range []string{
+		`#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Hebrew",LANGUAGE="he",DEFAULT=YES,AUTOSELECT=YES,URI="talk.he.m3u8"`,
+		`#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",LANGUAGE="en",DEFAULT=NO,AUTOSELECT=YES,URI="talk.en.m3u8"`,
+	} {
+		if !strings.Contains(manifest, line+"\n") {
+			t.Errorf("Expected %s in the manifest:\n%s", line, manifest)
+		}
+	}
+
+	if SubtitleTrackFiles([]Segment{{Start: 0, End: 1, Text: "שלום"}}, "en", "talk.vtt") != nil {
+		t.Error("Expected no tracks for an untranslated transcript")
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// telemetryInterval is how often the system load is sampled during transcription
+const telemetryInterval = 3 * time.Second
+
+// SystemLoad is a sample of the machine's load. Readings the platform doesn't
+// provide are -1 (percentages) or 0 (memory, temperature).
+type SystemLoad struct {
+	CPUPercent  float64
+	MemoryUsed  uint64 // Bytes
+	MemoryTotal uint64
+	GPUPercent  float64
+	Temperature float64 // °C, of the CPU (or else the GPU)
+}
+
+// FormatSystemLoad renders a sample for the status bar, e.g.
+// "CPU 85% | RAM 9.2/16.0 GB | GPU 40% | 72°C", leaving out what isn't known
+func FormatSystemLoad(load SystemLoad) string {
+	var parts []string
+	if load.CPUPercent >= 0 {
+		parts = append(parts, fmt.Sprintf("CPU %.0f%%", load.CPUPercent))
+	}
+	if load.MemoryTotal > 0 {
+		const gb = 1 << 30
+		parts = append(parts, fmt.Sprintf("RAM %.1f/%.1f GB", float64(load.MemoryUsed)/gb, float64(load.MemoryTotal)/gb))
+	}
+	if load.GPUPercent >= 0 {
+		parts = append(parts, fmt.Sprintf("GPU %.0f%%", load.GPUPercent))
+	}
+	if load.Temperature > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f°C", load.Temperature))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// MonitorSystemLoad samples the system load every interval and reports each sample
+// until the returned stop function is called. No sample is reported once stop returns.
+func MonitorSystemLoad(interval time.Duration, report func(SystemLoad)) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		sampler := &loadSampler{}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			load := sampler.sample()
+			select {
+			case <-done:
+				return
+			default:
+				report(load)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// loadSampler takes system load samples. Linux reports CPU time counters, so
+// usage is measured between consecutive samples.
+type loadSampler struct {
+	prevIdle  uint64
+	prevTotal uint64
+}
+
+// sample reads the current system load
+func (s *loadSampler) sample() SystemLoad {
+	load := SystemLoad{CPUPercent: -1, GPUPercent: -1}
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/proc/stat"); err == nil {
+			if idle, total, ok := parseProcStat(string(data)); ok {
+				if s.prevTotal > 0 && total > s.prevTotal {
+					load.CPUPercent = cpuPercent(idle-s.prevIdle, total-s.prevTotal)
+				}
+				s.prevIdle, s.prevTotal = idle, total
+			}
+		}
+		if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+			load.MemoryUsed, load.MemoryTotal = parseMeminfo(string(data))
+		}
+		load.Temperature = linuxCPUTemperature()
+	case "darwin":
+		// The first sample of top covers the time since boot; the second is current
+		if output, err := exec.Command("top", "-l", "2", "-n", "0", "-s", "1").Output(); err == nil {
+			load.CPUPercent, load.MemoryUsed, load.MemoryTotal = parseTopOutput(string(output))
+		}
+		// Apple GPUs report their utilization through the IOAccelerator statistics
+		if output, err := exec.Command("ioreg", "-r", "-d", "1", "-c", "IOAccelerator").Output(); err == nil {
+			load.GPUPercent = parseIORegGPU(string(output))
+		}
+	case "windows":
+		script := `$c=(Get-CimInstance Win32_Processor|Measure-Object LoadPercentage -Average).Average;` +
+			`$o=Get-CimInstance Win32_OperatingSystem;"$c $($o.TotalVisibleMemorySize) $($o.FreePhysicalMemory)"`
+		if output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Output(); err == nil {
+			load.CPUPercent, load.MemoryUsed, load.MemoryTotal = parseWindowsLoad(string(output))
+		}
+	}
+
+	// NVIDIA GPUs (Linux and Windows)
+	if nvidiaSmi, err := findTool("nvidia-smi", ""); err == nil {
+		output, err := exec.Command(nvidiaSmi, "--query-gpu=utilization.gpu,temperature.gpu", "--format=csv,noheader,nounits").Output()
+		if err == nil {
+			if gpu, temperature, ok := parseNvidiaSmi(string(output)); ok {
+				load.GPUPercent = gpu
+				if load.Temperature == 0 {
+					load.Temperature = temperature
+				}
+			}
+		}
+	}
+	return load
+}
+
+// cpuPercent is the share of CPU time that wasn't idle
+func cpuPercent(idle, total uint64) float64 {
+	if total == 0 || idle > total {
+		return -1
+	}
+	return 100 * float64(total-idle) / float64(total)
+}
+
+// parseProcStat reads the idle and total CPU time counters from /proc/stat's "cpu" line
+func parseProcStat(data string) (idle, total uint64, ok bool) {
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		for i, field := range fields[1:] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, false
+			}
+			total += value
+			if i == 3 || i == 4 { // idle and iowait
+				idle += value
+			}
+		}
+		return idle, total, true
+	}
+	return 0, 0, false
+}
+
+// parseMeminfo reads the used and total memory from /proc/meminfo, in bytes
+func parseMeminfo(data string) (used, total uint64) {
+	var available uint64
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = value * 1024
+		case "MemAvailable:":
+			available = value * 1024
+		}
+	}
+	if total == 0 || available > total {
+		return 0, 0
+	}
+	return total - available, total
+}
+
+// Patterns for macOS top's summary lines
+var (
+	topCPUPattern = regexp.MustCompile(`CPU usage: .*?([\d.]+)% idle`)
+	topMemPattern = regexp.MustCompile(`PhysMem: (\d+[KMGT]?) used .*?(\d+[KMGT]?) unused`)
+)
+
+// parseTopOutput reads the CPU usage and memory from macOS top's last sample
+func parseTopOutput(output string) (cpu float64, used, total uint64) {
+	cpu = -1
+	if m := topCPUPattern.FindAllStringSubmatch(output, -1); m != nil {
+		if idle, err := strconv.ParseFloat(m[len(m)-1][1], 64); err == nil {
+			cpu = max(0, 100-idle)
+		}
+	}
+	if m := topMemPattern.FindAllStringSubmatch(output, -1); m != nil {
+		last := m[len(m)-1]
+		used = parseMemorySize(last[1])
+		if unused := parseMemorySize(last[2]); used > 0 {
+			total = used + unused
+		}
+	}
+	return cpu, used, total
+}
+
+// parseMemorySize parses a size such as "15G" or "512M" in bytes
+func parseMemorySize(size string) uint64 {
+	multiplier := uint64(1)
+	if n := len(size); n > 0 {
+		switch size[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			size = size[:n-1]
+		}
+	}
+	value, err := strconv.ParseUint(size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value * multiplier
+}
+
+// ioregGPUPattern matches the GPU utilization in ioreg's IOAccelerator statistics
+var ioregGPUPattern = regexp.MustCompile(`"Device Utilization %"=(\d+)`)
+
+// parseIORegGPU reads the GPU utilization from ioreg, or -1 if it isn't reported
+func parseIORegGPU(output string) float64 {
+	if m := ioregGPUPattern.FindStringSubmatch(output); m != nil {
+		if value, err := strconv.ParseFloat(m[1], 64); err == nil {
+			return value
+		}
+	}
+	return -1
+}
+
+// parseWindowsLoad reads the "<cpu%> <total KB> <free KB>" line the PowerShell query prints
+func parseWindowsLoad(output string) (cpu float64, used, total uint64) {
+	cpu = -1
+	fields := strings.Fields(output)
+	if len(fields) != 3 {
+		return cpu, 0, 0
+	}
+	if value, err := strconv.ParseFloat(fields[0], 64); err == nil {
+		cpu = value
+	}
+	totalKB, err1 := strconv.ParseUint(fields[1], 10, 64)
+	freeKB, err2 := strconv.ParseUint(fields[2], 10, 64)
+	if err1 != nil || err2 != nil || freeKB > totalKB {
+		return cpu, 0, 0
+	}
+	return cpu, (totalKB - freeKB) * 1024, totalKB * 1024
+}
+
+// parseNvidiaSmi reads the utilization and temperature of the busiest GPU from
+// nvidia-smi's "utilization, temperature" CSV lines
+func parseNvidiaSmi(output string) (gpu, temperature float64, ok bool) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			continue
+		}
+		utilization, err1 := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		temp, err2 := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if !ok || utilization > gpu {
+			gpu, temperature, ok = utilization, temp, true
+		}
+	}
+	return gpu, temperature, ok
+}
+
+// linuxCPUTemperature reads the CPU package temperature from the thermal zones,
+// or 0 if none is exposed
+func linuxCPUTemperature() float64 {
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	var readings []thermalReading
+	for _, zone := range zones {
+		zoneType, err := os.ReadFile(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		temp, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		if milli, err := strconv.ParseFloat(strings.TrimSpace(string(temp)), 64); err == nil {
+			readings = append(readings, thermalReading{Type: strings.TrimSpace(string(zoneType)), Celsius: milli / 1000})
+		}
+	}
+	return cpuTemperature(readings)
+}
+
+// thermalReading is one Linux thermal zone's temperature
+type thermalReading struct {
+	Type    string
+	Celsius float64
+}
+
+// cpuTemperature picks the hottest CPU zone (x86_pkg_temp, cpu-thermal, soc...),
+// or the hottest zone when none is named for the CPU
+func cpuTemperature(readings []thermalReading) float64 {
+	hottest, hottestCPU := 0.0, 0.0
+	for _, r := range readings {
+		hottest = max(hottest, r.Celsius)
+		zoneType := strings.ToLower(r.Type)
+		if strings.Contains(zoneType, "cpu") || strings.Contains(zoneType, "pkg") || strings.Contains(zoneType, "soc") {
+			hottestCPU = max(hottestCPU, r.Celsius)
+		}
+	}
+	if hottestCPU > 0 {
+		return hottestCPU
+	}
+	return hottest
+}
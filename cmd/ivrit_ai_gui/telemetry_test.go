@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatSystemLoad(t *testing.T) {
+	load := SystemLoad{CPUPercent: 85.4, MemoryUsed: 9 << 30, MemoryTotal: 16 << 30, GPUPercent: 40, Temperature: 72.3}
+	if got, want := FormatSystemLoad(load), "CPU 85% | RAM 9.0/16.0 GB | GPU 40% | 72°C"; got != want {
+		t.Errorf("FormatSystemLoad = %q, want %q", got, want)
+	}
+	if got := FormatSystemLoad(SystemLoad{CPUPercent: -1, GPUPercent: -1}); got != "" {
+		t.Errorf("unknown load = %q, want empty", got)
+	}
+	if got, want := FormatSystemLoad(SystemLoad{CPUPercent: 12, GPUPercent: -1}), "CPU 12%"; got != want {
+		t.Errorf("CPU only = %q, want %q", got, want)
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	data := "cpu  100 0 50 800 50 0 0 0 0 0\ncpu0 50 0 25 400 25 0 0 0 0 0\nintr 1234\n"
+	idle, total, ok := parseProcStat(data)
+	if !ok || idle != 850 || total != 1000 {
+		t.Errorf("parseProcStat = %d, %d, %v, want 850, 1000, true", idle, total, ok)
+	}
+	if got := cpuPercent(850, 1000); got != 15 {
+		t.Errorf("cpuPercent = %v, want 15", got)
+	}
+	if _, _, ok := parseProcStat("intr 1234\n"); ok {
+		t.Error("parseProcStat without a cpu line should fail")
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	data := "MemTotal:       16384000 kB\nMemFree:         1000000 kB\nMemAvailable:    4096000 kB\n"
+	used, total := parseMeminfo(data)
+	if total != 16384000*1024 || used != (16384000-4096000)*1024 {
+		t.Errorf("parseMeminfo = %d, %d", used, total)
+	}
+}
+
+func TestParseTopOutput(t *testing.T) {
+	output := `Processes: 500 total
+CPU usage: 3.1% user, 2.0% sys, 94.9% idle
+PhysMem: 10G used (2G wired, 1G compressor), 6G unused.
+
+Processes: 501 total
+CPU usage: 60.5% user, 9.5% sys, 30.0% idle
+PhysMem: 12G used (2G wired, 1G compressor), 4096M unused.
+`
+	cpu, used, total := parseTopOutput(output)
+	if cpu != 70 {
+		t.Errorf("cpu = %v, want 70 (from the last sample)", cpu)
+	}
+	if used != 12<<30 || total != 16<<30 {
+		t.Errorf("memory = %d/%d, want 12G/16G", used, total)
+	}
+}
+
+func TestParseIORegGPU(t *testing.T) {
+	output := `"PerformanceStatistics" = {"In use system memory"=123,"Device Utilization %"=37,"Renderer Utilization %"=30}`
+	if got := parseIORegGPU(output); got != 37 {
+		t.Errorf("parseIORegGPU = %v, want 37", got)
+	}
+	if got := parseIORegGPU("nothing"); got != -1 {
+		t.Errorf("parseIORegGPU without statistics = %v, want -1", got)
+	}
+}
+
+func TestParseWindowsLoad(t *testing.T) {
+	cpu, used, total := parseWindowsLoad("37 16000000 4000000\r\n")
+	if cpu != 37 || total != 16000000*1024 || used != 12000000*1024 {
+		t.Errorf("parseWindowsLoad = %v, %d, %d", cpu, used, total)
+	}
+	if cpu, _, total := parseWindowsLoad("garbage"); cpu != -1 || total != 0 {
+		t.Errorf("parseWindowsLoad(garbage) = %v, %d", cpu, total)
+	}
+}
+
+func TestParseNvidiaSmi(t *testing.T) {
+	gpu, temperature, ok := parseNvidiaSmi("12, 45\n87, 71\n")
+	if !ok || gpu != 87 || temperature != 71 {
+		t.Errorf("parseNvidiaSmi = %v, %v, %v, want the busiest GPU", gpu, temperature, ok)
+	}
+	if _, _, ok := parseNvidiaSmi("[N/A], 40\n"); ok {
+		t.Error("parseNvidiaSmi should skip unparsable lines")
+	}
+}
+
+func TestCPUTemperature(t *testing.T) {
+	readings := []thermalReading{{"acpitz", 80}, {"x86_pkg_temp", 65}, {"iwlwifi_1", 40}}
+	if got := cpuTemperature(readings); got != 65 {
+		t.Errorf("cpuTemperature = %v, want the CPU package zone", got)
+	}
+	if got := cpuTemperature([]thermalReading{{"acpitz", 50}, {"acpitz", 55}}); got != 55 {
+		t.Errorf("cpuTemperature without a CPU zone = %v, want the hottest", got)
+	}
+}
+
+func TestMonitorSystemLoad(t *testing.T) {
+	samples := make(chan SystemLoad, 10)
+	stop := MonitorSystemLoad(time.Millisecond, func(load SystemLoad) { samples <- load })
+	<-samples
+	stop()
+	for len(samples) > 0 {
+		<-samples
+	}
+	time.Sleep(10 * time.Millisecond)
+	if len(samples) != 0 {
+		t.Error("samples reported after stop")
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Temporary files (converted audio, extracted channels, uploads...) are created
+// through CreateTempFile so they are removed on shutdown (see Shutdown), and the ones a
+// crash left behind are collected at the next start
+
+// orphanTempAge is how long a temporary file must have been left untouched before
+// it counts as left behind by a crash (a running transcription may still read a
+// WAV converted hours ago)
+const orphanTempAge = 24 * time.Hour
+
+// tempFilePatterns are the name patterns, in the system temp directory, of the
+// temporary files the app creates
+var tempFilePatterns = []string{
+	"whisper_audio_*.wav",
+	"extracted_audio_*.wav",
+	"channel_audio_*.wav",
+	"segment_*.wav",
+	"live_window_*.wav",
+	"player_audio_*.m4a",
+	"upload_audio_*.m4a",
+	"grpc_upload_*",
+	"ivrit-ai-join-*", // Directory of a joined recording, see CreateTempDir
+}
+
+// tempFiles tracks the temporary files this process has created and not yet removed
+var tempFiles = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+// CreateTempFile creates a temporary file like os.CreateTemp in the system temp
+// directory and tracks it until RemoveTempFile. pattern must be one of
+// tempFilePatterns (with any extension after the *), so leftovers are collected.
+func CreateTempFile(pattern string) (*os.File, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %v", err)
+	}
+	tempFiles.Lock()
+	tempFiles.paths[f.Name()] = true
+	tempFiles.Unlock()
+	return f, nil
+}
+
+// CreateTempDir creates a temporary directory like os.MkdirTemp in the system temp
+// directory, for a file that must keep a given name, and tracks it like CreateTempFile
+func CreateTempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	tempFiles.Lock()
+	tempFiles.paths[dir] = true
+	tempFiles.Unlock()
+	return dir, nil
+}
+
+// RemoveTempFile removes a file created by CreateTempFile, or a directory created by
+// CreateTempDir with its contents, and stops tracking it
+func RemoveTempFile(path string) {
+	os.RemoveAll(path)
+	tempFiles.Lock()
+	delete(tempFiles.paths, path)
+	tempFiles.Unlock()
+}
+
+// RemoveTempFiles removes every temporary file this process still tracks
+func RemoveTempFiles() {
+	tempFiles.Lock()
+	defer tempFiles.Unlock()
+	for path := range tempFiles.paths {
+		os.RemoveAll(path)
+		delete(tempFiles.paths, path)
+	}
+}
+
+// CleanOrphanedTempFiles removes temporary files a crashed run left in the system
+// temp directory, and returns how many it removed
+func CleanOrphanedTempFiles() int {
+	return cleanOrphanedTempFiles(os.TempDir(), time.Now().Add(-orphanTempAge))
+}
+
+// cleanOrphanedTempFiles removes the files and directories in dir matching
+// tempFilePatterns that were last modified before cutoff
+func cleanOrphanedTempFiles(dir string, cutoff time.Time) int {
+	removed := 0
+	for _, pattern := range tempFilePatterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil || !(info.Mode().IsRegular() || info.IsDir()) || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if os.RemoveAll(path) == nil {
+				removed++
+			}
+		}
+	}
+	return removed
+}
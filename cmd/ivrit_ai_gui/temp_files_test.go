@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestCreateTempFileTracking tests that temporary files are tracked until removed
+func TestCreateTempFileTracking(t *testing.T) {
+	f, err := CreateTempFile("whisper_audio_*.wav")
+	if err != nil {
+		t.Fatalf("CreateTempFile() error: %v", err)
+	}
+	f.Close()
+	kept, err := CreateTempFile("channel_audio_*.wav")
+	if err != nil {
+		t.Fatalf("CreateTempFile() error: %v", err)
+	}
+	kept.Close()
+	keptDir, err := CreateTempDir("ivrit-ai-join-*")
+	if err != nil {
+		t.Fatalf("CreateTempDir() error: %v", err)
+	}
+	os.WriteFile(filepath.Join(keptDir, "part1.wav"), []byte("RIFF"), 0644)
+
+	RemoveTempFile(f.Name())
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed", f.Name())
+	}
+
+	RemoveTempFiles()
+	if _, err := os.Stat(kept.Name()); !os.IsNotExist(err) {
+		t.Errorf("Expected RemoveTempFiles to remove %s", kept.Name())
+	}
+	if _, err := os.Stat(keptDir); !os.IsNotExist(err) {
+		t.Errorf("Expected RemoveTempFiles to remove %s with its contents", keptDir)
+	}
+	tempFiles.Lock()
+	defer tempFiles.Unlock()
+	if len(tempFiles.paths) != 0 {
+		t.Errorf("Expected no tracked files, got %v", tempFiles.paths)
+	}
+}
+
+// TestCleanOrphanedTempFiles tests that only old files of the app's patterns are removed
+func TestCleanOrphanedTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	files := map[string]bool{ // name: expected to be removed
+		"whisper_audio_123.wav":   true,
+		"extracted_audio_456.wav": true,
+		"grpc_upload_789.m4a":     true,
+		"whisper_audio_new.wav":   false, // Recent: may belong to a running transcription
+		"notes.wav":               false, // Not the app's
+	}
+	for name := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte("RIFF"), 0644)
+		if name != "whisper_audio_new.wav" {
+			os.Chtimes(path, old, old)
+		}
+	}
+
+	joinDir := filepath.Join(dir, "ivrit-ai-join-abc") // A joined recording's directory
+	os.Mkdir(joinDir, 0755)
+	os.WriteFile(filepath.Join(joinDir, "part1.wav"), []byte("RIFF"), 0644)
+	os.Chtimes(joinDir, old, old)
+	files["ivrit-ai-join-abc"] = true
+
+	if removed := cleanOrphanedTempFiles(dir, time.Now().Add(-orphanTempAge)); removed != 4 {
+		t.Errorf("Expected 4 files removed, got %d", removed)
+	}
+	for name, gone := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if gone != os.IsNotExist(err) {
+			t.Errorf("%s: expected removed=%v", name, gone)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// readingWordsPerMinute is the silent reading speed used to estimate reading time
+const readingWordsPerMinute = 200
+
+// TextStats counts what a transcript holds, e.g. for quoting a per-word rate
+type TextStats struct {
+	Segments           int
+	Words              int
+	HebrewWords        int // Words with at least one Hebrew letter
+	Characters         int
+	CharactersNoSpaces int
+}
+
+// ComputeTextStats counts the words and characters of a transcript. Non-speech
+// events aren't counted, translated segments are counted in their Hebrew original,
+// and punctuation standing alone (such as a dash) isn't a word.
+func ComputeTextStats(segments []Segment) TextStats {
+	var stats TextStats
+	for _, seg := range WithoutEvents(segments) {
+		text := strings.TrimSpace(seg.Text)
+		if seg.Original != "" && seg.Translation != "" {
+			text = strings.TrimSpace(seg.Original)
+		}
+		if text == "" {
+			continue
+		}
+		stats.Segments++
+		stats.Characters += utf8.RuneCountInString(text)
+		for _, r := range text {
+			if !unicode.IsSpace(r) {
+				stats.CharactersNoSpaces++
+			}
+		}
+		for _, word := range strings.Fields(text) {
+			if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+				continue
+			}
+			stats.Words++
+			if strings.ContainsFunc(word, isHebrewLetter) {
+				stats.HebrewWords++
+			}
+		}
+	}
+	return stats
+}
+
+// OtherWords returns the number of words without Hebrew letters (English, digits...)
+func (s TextStats) OtherWords() int {
+	return s.Words - s.HebrewWords
+}
+
+// HebrewShare returns the percentage of words that are Hebrew
+func (s TextStats) HebrewShare() float64 {
+	if s.Words == 0 {
+		return 0
+	}
+	return float64(s.HebrewWords) * 100 / float64(s.Words)
+}
+
+// ReadingTime estimates how long reading the transcript takes
+func (s TextStats) ReadingTime() time.Duration {
+	return time.Duration(float64(s.Words) / readingWordsPerMinute * float64(time.Minute))
+}
+
+// Summary describes the statistics on one line, e.g. "1,204 words (92% Hebrew,
+// 96 other) · 6,310 characters (5,107 without spaces) · 6 min read · 85 segments"
+func (s TextStats) Summary() string {
+	minutes := int(s.ReadingTime().Round(time.Minute) / time.Minute)
+	reading := fmt.Sprintf("%d min read", minutes)
+	if minutes == 0 && s.Words > 0 {
+		reading = "under 1 min read"
+	}
+	return fmt.Sprintf("%s words (%.0f%% Hebrew, %s other) · %s characters (%s without spaces) · %s · %s segments",
+		groupThousands(s.Words), s.HebrewShare(), groupThousands(s.OtherWords()),
+		groupThousands(s.Characters), groupThousands(s.CharactersNoSpaces), reading, groupThousands(s.Segments))
+}
+
+// groupThousands formats a count with comma thousands separators, e.g. 12,345
+func groupThousands(n int) string {
+	if n < 0 {
+		return "-" + groupThousands(-n)
+	}
+	digits := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestComputeTextStats tests word, Hebrew word and character counts
+func TestComputeTextStats(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 5, Text: "שלום לכולם, welcome!"},
+		{Start: 5, End: 8, Text: "[music]", Event: "music"},
+		{Start: 8, End: 12, Original: "תודה רבה", Translation: "Thank you very much", Text: "Thank you very much"},
+		{Start: 12, End: 15, Text: "יש 3 אנשים - בחדר"},
+	}
+
+	stats := ComputeTextStats(segments)
+	if stats.Segments != 3 {
+		t.Errorf("Expected 3 segments without the event, got %d", stats.Segments)
+	}
+	if stats.Words != 9 || stats.HebrewWords != 7 || stats.OtherWords() != 2 {
+		t.Errorf("Expected 9 words, 7 Hebrew, got %+v", stats)
+	}
+	if stats.Characters != 45 || stats.CharactersNoSpaces != 38 {
+		t.Errorf("Expected 45 characters (38 without spaces), got %d (%d)", stats.Characters, stats.CharactersNoSpaces)
+	}
+}
+
+// TestTextStatsSummary tests reading time and the one-line summary
+func TestTextStatsSummary(t *testing.T) {
+	stats := TextStats{Segments: 85, Words: 1204, HebrewWords: 1108, Characters: 6310, CharactersNoSpaces: 5107}
+	if got := stats.ReadingTime(); got != 361200*time.Millisecond {
+		t.Errorf("Unexpected reading time %v", got)
+	}
+	want := "1,204 words (92% Hebrew, 96 other) · 6,310 characters (5,107 without spaces) · 6 min read · 85 segments"
+	if got := stats.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got := (TextStats{Segments: 1, Words: 3, Characters: 12}).Summary(); got != "3 words (0% Hebrew, 3 other) · 12 characters (0 without spaces) · under 1 min read · 1 segments" {
+		t.Errorf("Unexpected short summary %q", got)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// benchmarkSeconds is the length of the synthetic audio decoded when tuning threads.
+// whisper pads every window to 30 seconds for the encoder, which dominates the cost,
+// so a short clip times the same work as real speech.
+const benchmarkSeconds = 5
+
+// CPUTopology describes the processor cores threads can run on
+type CPUTopology struct {
+	Logical     int // Hardware threads
+	Physical    int // Cores (0 = unknown)
+	Performance int // Performance cores of a hybrid CPU, e.g. Apple Silicon (0 = unknown or not hybrid)
+}
+
+var (
+	topology     CPUTopology
+	topologyOnce sync.Once
+)
+
+// cpuTopology returns this machine's core layout, detected on first use
+func cpuTopology() CPUTopology {
+	topologyOnce.Do(func() { topology = detectCPUTopology() })
+	return topology
+}
+
+// detectCPUTopology counts the cores: sysctl on macOS, /proc/cpuinfo and the hybrid
+// core list on Linux, and the processor's core count on Windows
+func detectCPUTopology() CPUTopology {
+	topo := CPUTopology{Logical: runtime.NumCPU()}
+	switch runtime.GOOS {
+	case "darwin":
+		topo.Physical = sysctlInt("hw.physicalcpu")
+		// Apple Silicon lists performance cores as level 0; Intel Macs have no levels
+		if levels := sysctlInt("hw.nperflevels"); levels > 1 {
+			topo.Performance = sysctlInt("hw.perflevel0.physicalcpu")
+		}
+	case "linux":
+		if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+			topo.Physical = parseCPUInfoCores(string(data))
+		}
+		// Intel hybrid CPUs list their performance core threads separately
+		if data, err := os.ReadFile("/sys/devices/cpu_core/cpus"); err == nil {
+			if threads := countCPUList(string(data)); threads > 0 {
+				topo.Performance = threads
+				if topo.Physical > 0 && topo.Logical > topo.Physical {
+					topo.Performance = (threads + 1) / 2 // Performance cores are the hyperthreaded ones
+				}
+			}
+		}
+	case "windows":
+		script := "(Get-CimInstance Win32_Processor | Measure-Object NumberOfCores -Sum).Sum"
+		if output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Output(); err == nil {
+			topo.Physical, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+		}
+	}
+	if topo.Physical > topo.Logical {
+		topo.Physical = 0
+	}
+	return topo
+}
+
+// sysctlInt reads a numeric sysctl value, or 0 if it doesn't exist
+func sysctlInt(name string) int {
+	output, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return 0
+	}
+	value, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return value
+}
+
+// parseCPUInfoCores counts the distinct physical cores in /proc/cpuinfo, or 0 if
+// it doesn't list them (as on many ARM boards)
+func parseCPUInfoCores(data string) int {
+	cores := map[string]bool{}
+	physicalID := ""
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "physical id":
+			physicalID = strings.TrimSpace(value)
+		case "core id":
+			cores[physicalID+"/"+strings.TrimSpace(value)] = true
+		}
+	}
+	return len(cores)
+}
+
+// countCPUList counts the CPUs in a Linux CPU list such as "0-7,16,18-19"
+func countCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return 0
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return 0
+			}
+		}
+		count += end - start + 1
+	}
+	return count
+}
+
+// modelThreadCap is the thread count beyond which a model stops getting faster:
+// small models are memory bound early, while large-v3's bigger matrices keep scaling
+func modelThreadCap(modelID string) int {
+	switch {
+	case strings.Contains(modelID, "tiny"), strings.Contains(modelID, "base"), strings.Contains(modelID, "small"):
+		return 4
+	case modelID == "large-v3":
+		return 12
+	default:
+		return 8 // turbo and custom models
+	}
+}
+
+// HeuristicThreads picks a thread count for a model without measuring: one thread per
+// performance core (efficiency cores and hyperthreads slow whisper's lockstep threads
+// down rather than help), up to what the model can use
+func HeuristicThreads(modelID string, topo CPUTopology) int {
+	cores := topo.Logical
+	if topo.Performance > 0 {
+		cores = topo.Performance
+	} else if topo.Physical > 0 {
+		cores = topo.Physical
+	}
+	return max(1, min(cores, modelThreadCap(modelID)))
+}
+
+// GetOptimalCPUThreads returns the thread count for a model on this machine, before
+// any benchmark has been run
+func GetOptimalCPUThreads(modelID string) int {
+	return HeuristicThreads(modelID, cpuTopology())
+}
+
+// threadCandidates returns the thread counts benchmarked around the heuristic pick:
+// fewer threads, the pick itself, and every core and hardware thread
+func threadCandidates(heuristic int, topo CPUTopology) []int {
+	seen := map[int]bool{}
+	var candidates []int
+	for _, n := range []int{heuristic / 2, heuristic * 3 / 4, heuristic, topo.Performance, topo.Physical, topo.Logical} {
+		if n >= 1 && n <= topo.Logical && !seen[n] {
+			seen[n] = true
+			candidates = append(candidates, n)
+		}
+	}
+	sort.Ints(candidates)
+	return candidates
+}
+
+// threadBenchmarker times a decode with a given thread count (WhisperCGOEngine)
+type threadBenchmarker interface {
+	BenchmarkThreads(threads int) (time.Duration, error)
+}
+
+// TuneThreads benchmarks a model at each candidate thread count and returns the
+// fastest. A warm-up run first loads the model's weights into memory, so the first
+// candidate isn't penalized; ties go to fewer threads.
+func TuneThreads(engine threadBenchmarker, modelID string, topo CPUTopology, progressCallback func(string)) (int, error) {
+	heuristic := HeuristicThreads(modelID, topo)
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Measuring the fastest thread count for %s (first use only)...", modelID))
+	}
+	if _, err := engine.BenchmarkThreads(heuristic); err != nil {
+		return heuristic, err
+	}
+
+	best, bestTime := heuristic, time.Duration(0)
+	candidates := threadCandidates(heuristic, topo)
+	for i, threads := range candidates {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Measuring the fastest thread count for %s (%d/%d)...", modelID, i+1, len(candidates)))
+		}
+		elapsed, err := engine.BenchmarkThreads(threads)
+		if err != nil {
+			return heuristic, err
+		}
+		if bestTime == 0 || elapsed < bestTime {
+			best, bestTime = threads, elapsed
+		}
+	}
+	return best, nil
+}
+
+// benchmarkSamples returns the synthetic audio decoded when tuning threads: quiet,
+// deterministic noise, which keeps the decoder from producing much text
+func benchmarkSamples() []float32 {
+	samples := make([]float32, benchmarkSeconds*16000)
+	state := uint32(1)
+	for i := range samples {
+		state = state*1664525 + 1013904223
+		samples[i] = (float32(state>>16)/65536 - 0.5) * 0.01
+	}
+	return samples
+}
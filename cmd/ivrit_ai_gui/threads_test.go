@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseCPUInfoCores(t *testing.T) {
+	// Two sockets of two hyperthreaded cores
+	var data string
+	for _, ids := range [][2]string{{"0", "0"}, {"0", "1"}, {"0", "0"}, {"0", "1"}, {"1", "0"}, {"1", "1"}, {"1", "0"}, {"1", "1"}} {
+		data += "processor\t: x\nphysical id\t: " + ids[0] + "\ncore id\t\t: " + ids[1] + "\n\n"
+	}
+	if got := parseCPUInfoCores(data); got != 4 {
+		t.Errorf("parseCPUInfoCores = %d, want 4", got)
+	}
+	if got := parseCPUInfoCores("processor\t: 0\nBogoMIPS\t: 48.00\n"); got != 0 {
+		t.Errorf("parseCPUInfoCores without core ids = %d, want 0", got)
+	}
+}
+
+func TestCountCPUList(t *testing.T) {
+	tests := map[string]int{"0-7\n": 8, "0-7,16,18-19": 11, "3": 1, "": 0, "5-2": 0}
+	for list, want := range tests {
+		if got := countCPUList(list); got != want {
+			t.Errorf("countCPUList(%q) = %d, want %d", list, got, want)
+		}
+	}
+}
+
+func TestHeuristicThreads(t *testing.T) {
+	tests := []struct {
+		model string
+		topo  CPUTopology
+		want  int
+	}{
+		{"turbo", CPUTopology{Logical: 10, Physical: 10, Performance: 8}, 8}, // M1 Pro: performance cores only
+		{"turbo", CPUTopology{Logical: 8, Physical: 8, Performance: 4}, 4},   // M1: 4 performance cores
+		{"large-v3", CPUTopology{Logical: 32, Physical: 16}, 12},             // Hyperthreads ignored, large cap
+		{"turbo", CPUTopology{Logical: 32, Physical: 16}, 8},                 // Turbo cap
+		{"base", CPUTopology{Logical: 16, Physical: 8}, 4},                   // Small model cap
+		{"large-v3", CPUTopology{Logical: 4}, 4},                             // Unknown cores: hardware threads
+		{"turbo", CPUTopology{Logical: 1}, 1},
+	}
+	for _, tt := range tests {
+		if got := HeuristicThreads(tt.model, tt.topo); got != tt.want {
+			t.Errorf("HeuristicThreads(%q, %+v) = %d, want %d", tt.model, tt.topo, got, tt.want)
+		}
+	}
+}
+
+func TestThreadCandidates(t *testing.T) {
+	got := threadCandidates(8, CPUTopology{Logical: 20, Physical: 14, Performance: 6})
+	want := []int{4, 6, 8, 14, 20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("threadCandidates = %v, want %v", got, want)
+	}
+	if got := threadCandidates(1, CPUTopology{Logical: 1}); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("threadCandidates on one core = %v, want [1]", got)
+	}
+}
+
+// fakeBenchmarker times decodes from a table of thread counts
+type fakeBenchmarker struct {
+	times map[int]time.Duration
+	runs  []int
+	err   error
+}
+
+func (f *fakeBenchmarker) BenchmarkThreads(threads int) (time.Duration, error) {
+	f.runs = append(f.runs, threads)
+	return f.times[threads], f.err
+}
+
+func TestTuneThreads(t *testing.T) {
+	topo := CPUTopology{Logical: 16, Physical: 8}
+	bench := &fakeBenchmarker{times: map[int]time.Duration{4: 9 * time.Second, 6: 5 * time.Second, 8: 5 * time.Second, 16: 7 * time.Second}}
+	best, err := TuneThreads(bench, "turbo", topo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best != 6 {
+		t.Errorf("TuneThreads = %d, want 6 (fastest, fewest threads on a tie)", best)
+	}
+	if want := []int{8, 4, 6, 8, 16}; !reflect.DeepEqual(bench.runs, want) {
+		t.Errorf("benchmark runs = %v, want a warm-up then %v", bench.runs, want[1:])
+	}
+
+	failing := &fakeBenchmarker{err: errors.New("out of memory")}
+	if best, err := TuneThreads(failing, "turbo", topo, nil); err == nil || best != 8 {
+		t.Errorf("failed benchmark = %d, %v, want the heuristic and an error", best, err)
+	}
+}
+
+func TestCPUThreads(t *testing.T) {
+	tuned := map[string]int{"turbo": 6}
+	if got := (AppConfig{Threads: 3}).CPUThreads("turbo", tuned); got != 3 {
+		t.Errorf("configured threads = %d, want 3", got)
+	}
+	if got := (AppConfig{}).CPUThreads("turbo", tuned); got != 6 {
+		t.Errorf("tuned threads = %d, want 6", got)
+	}
+	if got := (AppConfig{}).CPUThreads("large-v3", tuned); got != GetOptimalCPUThreads("large-v3") {
+		t.Errorf("untuned threads = %d, want the heuristic", got)
+	}
+	if (AppConfig{}).NeedsThreadTuning("turbo", tuned) || !(AppConfig{}).NeedsThreadTuning("large-v3", tuned) {
+		t.Error("only untuned models need tuning")
+	}
+	if (AppConfig{Threads: 4}).NeedsThreadTuning("large-v3", tuned) {
+		t.Error("configured threads are never tuned")
+	}
+}
+
+func TestBenchmarkSamples(t *testing.T) {
+	samples := benchmarkSamples()
+	if len(samples) != benchmarkSeconds*16000 {
+		t.Fatalf("len = %d, want %d", len(samples), benchmarkSeconds*16000)
+	}
+	for _, s := range samples {
+		if s < -0.01 || s > 0.01 {
+			t.Fatalf("sample %v is not quiet", s)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxTranscriptRecords bounds the index of saved transcripts; the oldest are dropped
+const maxTranscriptRecords = 1000
+
+// TranscriptRecord is a transcript saved for a recording, found again by the
+// recording's content when a copy of it is selected under another name
+type TranscriptRecord struct {
+	Fingerprint string    `json:"fingerprint"` // AudioFingerprint of the input
+	InputPath   string    `json:"inputPath"`
+	OutputPath  string    `json:"outputPath"`
+	Model       string    `json:"model,omitempty"`
+	SavedAt     time.Time `json:"savedAt"`
+}
+
+// Audio fingerprints are memoized by path, size and modification time, since
+// hashing a long video takes a while and the same file is looked up repeatedly
+type fingerprintKey struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+var (
+	fingerprints      = make(map[fingerprintKey]string)
+	fingerprintsMutex sync.Mutex
+	transcriptIndexMu sync.Mutex // Serializes reading and writing the index file
+)
+
+// AudioFingerprint identifies a recording by its content rather than its name: the
+// SHA-256 of the file, as in the manifest's inputSha256, so a renamed or copied
+// file has the same fingerprint. It hashes the file as stored, not the decoded
+// audio: the same recording re-encoded, remuxed or with edited tags is another file.
+func AudioFingerprint(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	key := fingerprintKey{path: absolutePath(path), size: info.Size(), modTime: info.ModTime()}
+	fingerprintsMutex.Lock()
+	fingerprint, ok := fingerprints[key]
+	fingerprintsMutex.Unlock()
+	if ok {
+		return fingerprint, nil
+	}
+
+	fingerprint, err = fileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+	fingerprintsMutex.Lock()
+	fingerprints[key] = fingerprint
+	fingerprintsMutex.Unlock()
+	return fingerprint, nil
+}
+
+// audioCacheKey keys the transcription cache by the audio's fingerprint, falling
+// back to the path when the file can't be read
+func audioCacheKey(path string) string {
+	if fingerprint, err := AudioFingerprint(path); err == nil {
+		return fingerprint
+	}
+	return path
+}
+
+// transcriptIndexPath returns the location of the index of saved transcripts
+func transcriptIndexPath() string {
+	return filepath.Join(filepath.Dir(settingsPath()), "transcripts.json")
+}
+
+// loadTranscriptIndex reads the index of saved transcripts; the caller holds
+// transcriptIndexMu. A missing file is an empty index, but one that can't be read or
+// parsed is an error, so it isn't overwritten with the new record alone.
+func loadTranscriptIndex() ([]TranscriptRecord, error) {
+	var records []TranscriptRecord
+	data, err := os.ReadFile(transcriptIndexPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("the transcript index in %s is damaged: %v", transcriptIndexPath(), err)
+	}
+	return records, nil
+}
+
+// RecordTranscript adds a saved transcript of inputPath to the index. Only
+// transcripts that can be read back (text, SRT, VTT and JSON) are recorded.
+func RecordTranscript(inputPath, outputPath, modelID string) error {
+	if transcriptFileFormat(outputPath) == "" {
+		return nil
+	}
+	fingerprint, err := AudioFingerprint(inputPath)
+	if err != nil {
+		return err
+	}
+	record := TranscriptRecord{
+		Fingerprint: fingerprint,
+		InputPath:   absolutePath(inputPath),
+		OutputPath:  absolutePath(outputPath),
+		Model:       modelID,
+		SavedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	transcriptIndexMu.Lock()
+	defer transcriptIndexMu.Unlock()
+	existing, err := loadTranscriptIndex()
+	if err != nil {
+		return err
+	}
+	records := []TranscriptRecord{record}
+	for _, r := range existing {
+		if r.OutputPath != record.OutputPath { // A file saved again replaces its record
+			records = append(records, r)
+		}
+	}
+	if len(records) > maxTranscriptRecords {
+		records = records[:maxTranscriptRecords]
+	}
+
+	path := transcriptIndexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// FindTranscribedDuplicate returns the latest transcript saved for a recording with
+// the same audio as inputPath under another name, if its file is still there.
+// Transcripts with timestamps (JSON, SRT, VTT) are preferred over plain text.
+func FindTranscribedDuplicate(inputPath string) (TranscriptRecord, bool) {
+	transcriptIndexMu.Lock()
+	records, err := loadTranscriptIndex() // Newest first
+	transcriptIndexMu.Unlock()
+	if err != nil || len(records) == 0 {
+		return TranscriptRecord{}, false
+	}
+	fingerprint, err := AudioFingerprint(inputPath)
+	if err != nil {
+		return TranscriptRecord{}, false
+	}
+
+	inputPath = absolutePath(inputPath)
+	var untimed *TranscriptRecord
+	for i, r := range records {
+		if r.Fingerprint != fingerprint || r.InputPath == inputPath {
+			continue
+		}
+		if _, err := os.Stat(r.OutputPath); err != nil {
+			continue
+		}
+		if transcriptFileFormat(r.OutputPath) != "text" {
+			return r, true
+		}
+		if untimed == nil {
+			untimed = &records[i]
+		}
+	}
+	if untimed != nil {
+		return *untimed, true
+	}
+	return TranscriptRecord{}, false
+}
+
+// Describe says where the earlier transcript came from, e.g.
+// "same audio as interview.m4a, transcribed to interview.srt"
+func (r TranscriptRecord) Describe() string {
+	return fmt.Sprintf("same audio as %s, transcribed to %s", filepath.Base(r.InputPath), r.OutputPath)
+}
+
+// LoadTranscript reads the recorded transcript back, with its manifest if it was
+// saved as JSON
+func (r TranscriptRecord) LoadTranscript() ([]Segment, *Manifest, error) {
+	data, err := os.ReadFile(r.OutputPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseTranscript(data, transcriptFileFormat(r.OutputPath))
+}
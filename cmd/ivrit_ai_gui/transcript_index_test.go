@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindTranscribedDuplicate tests finding the transcript of a renamed copy of a recording
+func TestFindTranscribedDuplicate(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	original := filepath.Join(tmpDir, "interview.m4a")
+	copied := filepath.Join(tmpDir, "Copy of interview.m4a")
+	other := filepath.Join(tmpDir, "lecture.m4a")
+	for path, content := range map[string]string{original: "same audio", copied: "same audio", other: "other audio"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := FindTranscribedDuplicate(copied); ok {
+		t.Fatal("Expected no duplicate before anything was transcribed")
+	}
+
+	segments := []Segment{{Start: 0, End: 2.5, Text: "שלום"}, {Start: 2.5, End: 4, Text: "מה שלומך", Speaker: 1}}
+	srtPath := filepath.Join(tmpDir, "interview.srt")
+	textPath := filepath.Join(tmpDir, "interview.txt")
+	os.WriteFile(srtPath, []byte(FormatOutput(segments, "srt", DisplayBilingual)), 0644)
+	os.WriteFile(textPath, []byte(FormatOutput(segments, "text", DisplayBilingual)), 0644)
+	for _, path := range []string{srtPath, textPath, filepath.Join(tmpDir, "interview.md")} {
+		if err := RecordTranscript(original, path, "turbo"); err != nil {
+			t.Fatalf("RecordTranscript(%s) failed: %v", path, err)
+		}
+	}
+
+	// The timed transcript is preferred over the newer plain text one
+	earlier, ok := FindTranscribedDuplicate(copied)
+	if !ok || earlier.OutputPath != srtPath || earlier.Model != "turbo" {
+		t.Fatalf("Expected the SRT transcript of the original, got %+v (found: %v)", earlier, ok)
+	}
+	loaded, _, err := earlier.LoadTranscript()
+	if err != nil || len(loaded) != 2 || loaded[1].Text != "מה שלומך" || loaded[1].Start != 2.5 || loaded[1].Speaker != 1 {
+		t.Errorf("Unexpected reused transcript: %+v (error: %v)", loaded, err)
+	}
+
+	// The recording itself and different audio aren't duplicates
+	if _, ok := FindTranscribedDuplicate(original); ok {
+		t.Error("Expected the transcribed file not to be its own duplicate")
+	}
+	if _, ok := FindTranscribedDuplicate(other); ok {
+		t.Error("Expected different audio not to match")
+	}
+
+	// Deleted transcripts aren't offered
+	os.Remove(srtPath)
+	if earlier, ok := FindTranscribedDuplicate(copied); !ok || earlier.OutputPath != textPath {
+		t.Errorf("Expected the text transcript once the SRT is gone, got %+v", earlier)
+	}
+	os.Remove(textPath)
+	if _, ok := FindTranscribedDuplicate(copied); ok {
+		t.Error("Expected no duplicate once its transcripts are gone")
+	}
+}
+
+// TestRecordTranscriptDamaged tests that a damaged index is left as it was rather than
+// replaced by the new record alone
+func TestRecordTranscriptDamaged(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	input := filepath.Join(tmpDir, "interview.m4a")
+	os.WriteFile(input, []byte("audio"), 0644)
+	os.MkdirAll(filepath.Dir(transcriptIndexPath()), 0755)
+	damaged := []byte(`[{"fingerprint": "ab`)
+	if err := os.WriteFile(transcriptIndexPath(), damaged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RecordTranscript(input, filepath.Join(tmpDir, "interview.srt"), "turbo"); err == nil {
+		t.Error("Expected RecordTranscript() to fail on a damaged index")
+	}
+	if data, _ := os.ReadFile(transcriptIndexPath()); string(data) != string(damaged) {
+		t.Errorf("Expected the damaged index left as it was, got %s", data)
+	}
+	if _, ok := FindTranscribedDuplicate(input); ok {
+		t.Error("Expected no duplicate from a damaged index")
+	}
+}
+
+// TestAudioCacheKey tests that the transcription cache is keyed by content, not name
+func TestAudioCacheKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "a.wav")
+	second := filepath.Join(tmpDir, "b.wav")
+	os.WriteFile(first, []byte("RIFF samples"), 0644)
+	os.WriteFile(second, []byte("RIFF samples"), 0644)
+
+	if audioCacheKey(first) != audioCacheKey(second) {
+		t.Error("Expected copies of the same audio to share a cache key")
+	}
+	if hash, _ := fileSHA256(first); audioCacheKey(first) != hash {
+		t.Errorf("Expected the key to be the file's SHA-256, got %s", audioCacheKey(first))
+	}
+	missing := filepath.Join(tmpDir, "missing.wav")
+	if audioCacheKey(missing) != missing {
+		t.Error("Expected an unreadable file to be keyed by its path")
+	}
+}
@@ -5,24 +5,128 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
+	"strconv"
+	"strings"
 	"unicode"
 )
 
 // Segment represents a transcribed segment with timing information
 type Segment struct {
+	Start           float64 `json:"start"`
+	End             float64 `json:"end"`
+	Text            string  `json:"text"`
+	Original        string  `json:"original,omitempty"`        // Original Hebrew text (if translated)
+	Translation     string  `json:"translation,omitempty"`     // English translation (if requested)
+	Transliteration string  `json:"transliteration,omitempty"` // Latin-script Hebrew (if requested)
+	Speaker         int     `json:"speaker,omitempty"`         // Speaker ID (0, 1, 2, etc.) from tinydiarize
+	SpeakerName     string  `json:"speakerName,omitempty"`     // Speaker's name when known, e.g. from a participant's track
+	Event           string  `json:"event,omitempty"`           // Non-speech event the segment is, e.g. EventMusic (its Text is then the label)
+	Tokens          []Token `json:"tokens,omitempty"`          // Raw decoder tokens (only collected for the debug token dump)
+}
+
+// SpeakerLabel names the segment's speaker in transcripts: their name when known,
+// otherwise "Speaker N" or the label set with SetSpeakerLabels
+func (s Segment) SpeakerLabel() string {
+	if s.SpeakerName != "" {
+		return s.SpeakerName
+	}
+	return currentSpeakerLabels().name(s.Speaker)
+}
+
+// Token represents a single whisper decoder token with timing and confidence
+type Token struct {
+	ID          int     `json:"id"`
+	Text        string  `json:"text"`
 	Start       float64 `json:"start"`
 	End         float64 `json:"end"`
-	Text        string  `json:"text"`
-	Original    string  `json:"original,omitempty"`    // Original Hebrew text (if translated)
-	Translation string  `json:"translation,omitempty"` // English translation (if requested)
-	Speaker     int     `json:"speaker,omitempty"`     // Speaker ID (0, 1, 2, etc.) from tinydiarize
+	Probability float64 `json:"p"`
+	LogProb     float64 `json:"plog"`
+}
+
+// TimeRange selects a portion of the input audio in seconds (End 0 = until the end of the file)
+type TimeRange struct {
+	Start float64
+	End   float64
 }
 
-// TranscriptionEngine interface for different transcription backends
+// IsSet reports whether the range selects less than the whole file
+func (r TimeRange) IsSet() bool {
+	return r.Start > 0 || r.End > 0
+}
+
+// Validate checks that the range is well-formed
+func (r TimeRange) Validate() error {
+	if r.Start < 0 || r.End < 0 {
+		return fmt.Errorf("time range cannot be negative")
+	}
+	if r.End > 0 && r.End <= r.Start {
+		return fmt.Errorf("end time (%s) must be after start time (%s)", FormatTimestamp(r.End, true), FormatTimestamp(r.Start, true))
+	}
+	return nil
+}
+
+// ParseTimecode parses "HH:MM:SS", "MM:SS" or plain seconds (fractions allowed) into seconds
+func ParseTimecode(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timecode %q (expected HH:MM:SS)", value)
+	}
+
+	seconds := 0.0
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timecode %q (expected HH:MM:SS)", value)
+		}
+		// Only the last component may have a fraction; minutes/seconds must be < 60
+		if i < len(parts)-1 && n != float64(int(n)) {
+			return 0, fmt.Errorf("invalid timecode %q (expected HH:MM:SS)", value)
+		}
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("invalid timecode %q (minutes and seconds must be below 60)", value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	return seconds, nil
+}
+
+// ParseTimeRange parses start/end timecodes (either may be empty) into a validated TimeRange
+func ParseTimeRange(from, to string) (TimeRange, error) {
+	start, err := ParseTimecode(from)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	end, err := ParseTimecode(to)
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	timeRange := TimeRange{Start: start, End: end}
+	if err := timeRange.Validate(); err != nil {
+		return TimeRange{}, err
+	}
+	return timeRange, nil
+}
+
+// TranscriptionEngine interface for different transcription backends: whisper.cpp
+// linked into the app (WhisperCGOEngine), a transcription server (RemoteEngine), or
+// several of them tried in order (FallbackEngine)
 type TranscriptionEngine interface {
 	Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error)
 	SupportsModel(modelID string) bool
+	SetTokenDump(enabled bool)             // Collect per-token data (tokens format)
+	SetTimeRange(timeRange TimeRange)      // Transcribe only part of the audio
+	SetParallelChunks(n int)               // Split long audio into chunks run in parallel
+	SetDecodeOptions(decode DecodeOptions) // Advanced decoding parameters
+	LastResultCached() bool                // Whether the last result came from a cache
+	Abort()                                // Stop the running and any later transcription, which return ErrInterrupted
+	Close()
 }
 
 // ProgressCallback is called for status updates
@@ -48,15 +152,15 @@ func ExtractAudioFromVideo(videoPath string, progressCallback ProgressCallback)
 	}
 
 	// Create temporary file for audio
-	tempFile, err := os.CreateTemp("", "extracted_audio_*.wav")
+	tempFile, err := CreateTempFile("extracted_audio_*.wav")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %v", err)
+		return "", err
 	}
 	tempPath := tempFile.Name()
 	tempFile.Close()
 
 	// Use ffmpeg to extract audio
-	cmd := exec.Command("ffmpeg",
+	cmd := exec.Command(ffmpegPath(),
 		"-i", videoPath,
 		"-vn",              // No video
 		"-acodec", "pcm_s16le", // PCM 16-bit
@@ -70,7 +174,7 @@ func ExtractAudioFromVideo(videoPath string, progressCallback ProgressCallback)
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		os.Remove(tempPath)
+		RemoveTempFile(tempPath)
 		return "", fmt.Errorf("ffmpeg failed: %v (is ffmpeg installed?)", err)
 	}
 
@@ -90,17 +194,23 @@ func FormatTimestamp(seconds float64, vtt bool) string {
 	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, secs, millis)
 }
 
-// FormatOutput formats segments into different output formats
-func FormatOutput(segments []Segment, formatType string, includeOriginal bool) string {
+// FormatOutput formats segments into different output formats, showing the texts of
+// translated segments that displayMode selects
+func FormatOutput(segments []Segment, formatType string, displayMode string) string {
+	segments = ApplyDisplayMode(segments, displayMode)
+	// Subtitles show one segment at a time, so long ones are split at sentence ends
+	if formatType == "srt" || formatType == "vtt" || formatType == "ttml" || formatType == "stl" {
+		segments = SplitLongCues(segments)
+	}
 	switch formatType {
 	case "text":
 		output := ""
 		lastSpeaker := -1
 		for _, seg := range segments {
-			// Add speaker label if speaker changed
+			// Add speaker label if speaker changed (events aren't anyone's)
 			speakerPrefix := ""
-			if seg.Speaker != lastSpeaker {
-				speakerPrefix = fmt.Sprintf("Speaker %d: ", seg.Speaker+1)
+			if seg.Speaker != lastSpeaker && seg.Event == "" {
+				speakerPrefix = speakerLinePrefix(seg, "text")
 				lastSpeaker = seg.Speaker
 			}
 
@@ -109,17 +219,26 @@ func FormatOutput(segments []Segment, formatType string, includeOriginal bool) s
 				// Add RTL markers for Hebrew text
 				original := "\u202B" + seg.Original + "\u202C"
 				output += speakerPrefix + original + "\n"
+				if seg.Transliteration != "" {
+					if speakerPrefix != "" {
+						output += "           "
+					}
+					output += seg.Transliteration + "\n"
+				}
 				if speakerPrefix != "" {
 					output += "           " // Indent translation to align
 				}
-				output += seg.Translation + "\n\n"
+				output += embedRTL(seg.Translation) + "\n\n"
+			} else if seg.Transliteration != "" {
+				// Hebrew with its transliteration under it
+				output += speakerPrefix + embedRTL(seg.Text) + "\n"
+				if speakerPrefix != "" {
+					output += "           "
+				}
+				output += seg.Transliteration + "\n\n"
 			} else {
 				// Just show text (either Hebrew or translated)
-				text := seg.Text
-				if containsHebrew(text) {
-					text = "\u202B" + text + "\u202C"
-				}
-				output += speakerPrefix + text + "\n"
+				output += speakerPrefix + embedRTL(seg.Text) + "\n"
 			}
 		}
 		return output
@@ -133,6 +252,9 @@ func FormatOutput(segments []Segment, formatType string, includeOriginal bool) s
 			}
 			output += "  {"
 			output += fmt.Sprintf(`"start": %.2f, "end": %.2f, "speaker": %d`, seg.Start, seg.End, seg.Speaker+1)
+			if seg.SpeakerName != "" {
+				output += fmt.Sprintf(`, "speakerName": "%s"`, seg.SpeakerName)
+			}
 			if seg.Translation != "" && seg.Original != "" {
 				// Both original and translation present
 				output += fmt.Sprintf(`, "original": "%s", "translation": "%s"`, seg.Original, seg.Translation)
@@ -140,6 +262,12 @@ func FormatOutput(segments []Segment, formatType string, includeOriginal bool) s
 				// Just text (either Hebrew or English)
 				output += fmt.Sprintf(`, "text": "%s"`, seg.Text)
 			}
+			if seg.Transliteration != "" {
+				output += fmt.Sprintf(`, "transliteration": "%s"`, seg.Transliteration)
+			}
+			if seg.Event != "" {
+				output += fmt.Sprintf(`, "event": "%s"`, seg.Event)
+			}
 			output += "}"
 		}
 		output += "\n]"
@@ -152,18 +280,18 @@ func FormatOutput(segments []Segment, formatType string, includeOriginal bool) s
 			start := FormatTimestamp(seg.Start, false)
 			end := FormatTimestamp(seg.End, false)
 
-			// Add speaker label if speaker changed
+			// Add speaker label if speaker changed (events aren't anyone's)
 			speakerLabel := ""
-			if seg.Speaker != lastSpeaker {
-				speakerLabel = fmt.Sprintf("[Speaker %d] ", seg.Speaker+1)
+			if seg.Speaker != lastSpeaker && seg.Event == "" {
+				speakerLabel = speakerLinePrefix(seg, "srt")
 				lastSpeaker = seg.Speaker
 			}
 
 			// If both original and translation exist, show both on separate lines
 			if seg.Original != "" && seg.Translation != "" {
-				output += fmt.Sprintf("%d\n%s --> %s\n%s%s\n%s\n\n", i+1, start, end, speakerLabel, seg.Original, seg.Translation)
+				output += fmt.Sprintf("%d\n%s --> %s\n%s%s\n%s%s\n\n", i+1, start, end, speakerLabel, seg.Original, transliterationLine(seg), seg.Translation)
 			} else {
-				output += fmt.Sprintf("%d\n%s --> %s\n%s%s\n\n", i+1, start, end, speakerLabel, seg.Text)
+				output += fmt.Sprintf("%d\n%s --> %s\n%s%s\n%s\n", i+1, start, end, speakerLabel, seg.Text, transliterationLine(seg))
 			}
 		}
 		return output
@@ -175,35 +303,76 @@ func FormatOutput(segments []Segment, formatType string, includeOriginal bool) s
 			start := FormatTimestamp(seg.Start, true)
 			end := FormatTimestamp(seg.End, true)
 
-			// Add speaker label if speaker changed
+			// Add speaker label if speaker changed (events aren't anyone's)
 			speakerLabel := ""
-			if seg.Speaker != lastSpeaker {
-				speakerLabel = fmt.Sprintf("<v Speaker %d>", seg.Speaker+1)
+			if seg.Speaker != lastSpeaker && seg.Event == "" {
+				speakerLabel = speakerLinePrefix(seg, "vtt")
 				lastSpeaker = seg.Speaker
 			}
 
 			// If both original and translation exist, show both on separate lines
 			if seg.Original != "" && seg.Translation != "" {
-				output += fmt.Sprintf("%s --> %s\n%s%s\n%s\n\n", start, end, speakerLabel, seg.Original, seg.Translation)
+				output += fmt.Sprintf("%s --> %s\n%s%s\n%s%s\n\n", start, end, speakerLabel, seg.Original, transliterationLine(seg), seg.Translation)
 			} else {
-				output += fmt.Sprintf("%s --> %s\n%s%s\n\n", start, end, speakerLabel, seg.Text)
+				output += fmt.Sprintf("%s --> %s\n%s%s\n%s\n", start, end, speakerLabel, seg.Text, transliterationLine(seg))
 			}
 		}
 		return output
 
+	case "markdown":
+		return FormatMarkdown(segments, "")
+
+	case "html":
+		// Transcript only; callers with the input file embed its audio with FormatHTML
+		output, err := FormatHTML("Transcript", segments, nil, "")
+		if err != nil {
+			return ""
+		}
+		return output
+
+	case "ttml":
+		return FormatTTML(segments)
+
+	case "stl":
+		// Binary, returned as a string of its bytes
+		return FormatSTL(segments)
+
+	case FormatCustom:
+		// Callers that report script errors run FormatScript themselves
+		output, err := FormatScript(segments)
+		if err != nil {
+			return ""
+		}
+		return output
+
+	case "tokens":
+		// Debug dump: each segment followed by its raw tokens, one per line.
+		// Token text is quoted so whitespace and broken UTF-8 bytes stay visible.
+		output := ""
+		for i, seg := range segments {
+			start := FormatTimestamp(seg.Start, true)
+			end := FormatTimestamp(seg.End, true)
+			output += fmt.Sprintf("#%d [%s --> %s] speaker=%d %s\n", i+1, start, end, seg.Speaker+1, seg.Text)
+			for _, tok := range seg.Tokens {
+				output += fmt.Sprintf("    %s  %s  id=%-6d p=%.4f plog=%.4f  %q\n",
+					FormatTimestamp(tok.Start, true), FormatTimestamp(tok.End, true),
+					tok.ID, tok.Probability, tok.LogProb, tok.Text)
+			}
+			output += "\n"
+		}
+		return output
+
 	default:
 		return ""
 	}
 }
 
-// GetOptimalCPUThreads returns optimal number of CPU threads
-func GetOptimalCPUThreads() int {
-	cpuCount := runtime.NumCPU()
-	// Default to 8 threads (optimal for turbo model), but cap at available cores
-	if cpuCount < 8 {
-		return cpuCount
+// transliterationLine returns a segment's transliteration as a subtitle line ("" if none)
+func transliterationLine(seg Segment) string {
+	if seg.Transliteration == "" {
+		return ""
 	}
-	return 8
+	return seg.Transliteration + "\n"
 }
 
 // containsHebrew checks if a string contains Hebrew characters
@@ -216,3 +385,22 @@ func containsHebrew(s string) bool {
 	return false
 }
 
+// containsRTL checks if a string contains right-to-left (Hebrew or Arabic) characters
+func containsRTL(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hebrew, unicode.Arabic) {
+			return true
+		}
+	}
+	return false
+}
+
+// embedRTL wraps right-to-left text in Unicode embedding marks, so plain text
+// viewers show it right-to-left whatever the surrounding text is
+func embedRTL(s string) string {
+	if !containsRTL(s) {
+		return s
+	}
+	return "\u202B" + s + "\u202C"
+}
+
@@ -31,6 +31,25 @@ func TestContainsHebrew(t *testing.T) {
 	}
 }
 
+// TestContainsRTL tests detecting right-to-left text in Hebrew, Yiddish and Arabic
+func TestContainsRTL(t *testing.T) {
+	for _, text := range []string{"שלום", "אַ גוטן טאָג", "مرحبا بالعالم"} {
+		if !containsRTL(text) || embedRTL(text) != "\u202B"+text+"\u202C" {
+			t.Errorf("Expected %q to be embedded right-to-left, got %q", text, embedRTL(text))
+		}
+	}
+	for _, text := range []string{"Hello", "Привет", "ሰላም", ""} {
+		if containsRTL(text) || embedRTL(text) != text {
+			t.Errorf("Expected %q to be left-to-right, got %q", text, embedRTL(text))
+		}
+	}
+
+	segments := []Segment{{Start: 0, End: 1, Text: "مرحبا", Original: "שלום", Translation: "مرحبا"}}
+	if output := FormatOutput(segments, "text", DisplayBilingual); !strings.Contains(output, "\u202Bمرحبا\u202C") {
+		t.Errorf("Expected the Arabic translation embedded right-to-left, got %q", output)
+	}
+}
+
 // Test IsVideoFile function
 func TestIsVideoFile(t *testing.T) {
 	tests := []struct {
@@ -91,6 +110,55 @@ func TestFormatTimestamp(t *testing.T) {
 	}
 }
 
+// Test ParseTimecode function
+func TestParseTimecode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"90", 90, false},
+		{"1.5", 1.5, false},
+		{"10:00", 600, false},
+		{"00:10:00", 600, false},
+		{"01:02:03.5", 3723.5, false},
+		{"00:61:00", 0, true},
+		{"1.5:00", 0, true},
+		{"abc", 0, true},
+		{"-5", 0, true},
+		{"1:2:3:4", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseTimecode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimecode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && result != tt.expected {
+				t.Errorf("ParseTimecode(%q) = %v, expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// Test ParseTimeRange validation
+func TestParseTimeRange(t *testing.T) {
+	r, err := ParseTimeRange("00:10:00", "00:25:00")
+	if err != nil || r.Start != 600 || r.End != 1500 || !r.IsSet() {
+		t.Errorf("ParseTimeRange() = %+v, %v", r, err)
+	}
+
+	if r, err := ParseTimeRange("", ""); err != nil || r.IsSet() {
+		t.Errorf("Empty range should be unset, got %+v, %v", r, err)
+	}
+
+	if _, err := ParseTimeRange("00:25:00", "00:10:00"); err == nil {
+		t.Error("End before start should be rejected")
+	}
+}
+
 // Test FormatOutput function with text format
 func TestFormatOutputText(t *testing.T) {
 	segments := []Segment{
@@ -99,7 +167,7 @@ func TestFormatOutputText(t *testing.T) {
 		{Start: 5.0, End: 7.5, Text: "בסדר גמור", Speaker: 0},
 	}
 
-	output := FormatOutput(segments, "text", false)
+	output := FormatOutput(segments, "text", DisplayBilingual)
 
 	// Check that output contains speaker labels
 	if !strings.Contains(output, "Speaker 1:") {
@@ -128,7 +196,7 @@ func TestFormatOutputTextWithTranslation(t *testing.T) {
 		},
 	}
 
-	output := FormatOutput(segments, "text", true)
+	output := FormatOutput(segments, "text", DisplayBilingual)
 
 	// Should contain both original and translation
 	if !strings.Contains(output, "שלום עולם") {
@@ -146,7 +214,7 @@ func TestFormatOutputJSON(t *testing.T) {
 		{Start: 2.5, End: 5.0, Text: "עולם", Speaker: 1},
 	}
 
-	output := FormatOutput(segments, "json", false)
+	output := FormatOutput(segments, "json", DisplayBilingual)
 
 	// Check JSON structure
 	if !strings.HasPrefix(output, "[") || !strings.HasSuffix(output, "]") {
@@ -175,7 +243,7 @@ func TestFormatOutputSRT(t *testing.T) {
 		{Start: 2.5, End: 5.0, Text: "מה שלומך?", Speaker: 0},
 	}
 
-	output := FormatOutput(segments, "srt", false)
+	output := FormatOutput(segments, "srt", DisplayBilingual)
 
 	// Check for SRT structure
 	if !strings.Contains(output, "1\n") {
@@ -199,7 +267,7 @@ func TestFormatOutputVTT(t *testing.T) {
 		{Start: 0.0, End: 2.5, Text: "שלום עולם", Speaker: 0},
 	}
 
-	output := FormatOutput(segments, "vtt", false)
+	output := FormatOutput(segments, "vtt", DisplayBilingual)
 
 	// Check for VTT header
 	if !strings.HasPrefix(output, "WEBVTT\n\n") {
@@ -212,18 +280,42 @@ func TestFormatOutputVTT(t *testing.T) {
 	}
 }
 
-// Test GetOptimalCPUThreads
-func TestGetOptimalCPUThreads(t *testing.T) {
-	threads := GetOptimalCPUThreads()
+// Test FormatOutput with the debug token dump format
+func TestFormatOutputTokens(t *testing.T) {
+	segments := []Segment{
+		{Start: 0.0, End: 2.5, Text: "שלום", Speaker: 0, Tokens: []Token{
+			{ID: 50364, Text: "[_BEG_]", Start: 0.0, End: 0.0, Probability: 0.99},
+			{ID: 7659, Text: " שלום", Start: 0.1, End: 0.8, Probability: 0.42, LogProb: -0.87},
+		}},
+	}
 
-	// Should return a positive number
-	if threads <= 0 {
-		t.Errorf("GetOptimalCPUThreads() returned %d, expected positive number", threads)
+	output := FormatOutput(segments, "tokens", DisplayBilingual)
+
+	if !strings.Contains(output, "#1 [00:00:00.000 --> 00:00:02.500] speaker=1 שלום") {
+		t.Errorf("Token dump should contain segment header, got: %s", output)
+	}
+	if !strings.Contains(output, `id=7659   p=0.4200 plog=-0.8700  " שלום"`) {
+		t.Errorf("Token dump should contain quoted token with probability, got: %s", output)
 	}
+	if !strings.Contains(output, "00:00:00.100  00:00:00.800") {
+		t.Error("Token dump should contain token timestamps")
+	}
+}
 
-	// Should not exceed 8 (as per the implementation)
-	if threads > 8 {
-		t.Errorf("GetOptimalCPUThreads() returned %d, expected <= 8", threads)
+// Test GetOptimalCPUThreads
+func TestGetOptimalCPUThreads(t *testing.T) {
+	for _, modelID := range []string{"base", "turbo", "large-v3"} {
+		threads := GetOptimalCPUThreads(modelID)
+
+		// Should return a positive number
+		if threads <= 0 {
+			t.Errorf("GetOptimalCPUThreads(%q) returned %d, expected positive number", modelID, threads)
+		}
+
+		// Should not exceed the model's cap
+		if threads > modelThreadCap(modelID) {
+			t.Errorf("GetOptimalCPUThreads(%q) returned %d, expected <= %d", modelID, threads, modelThreadCap(modelID))
+		}
 	}
 }
 
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// segmentTranslator translates transcript segments (MistralTranslator)
+type segmentTranslator interface {
+	TranslateSegments(segments []Segment, targetLang string, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error)
+}
+
+// DirTranslation is the outcome of translating one saved transcript
+type DirTranslation struct {
+	Input   string
+	Output  string
+	Skipped bool // The translation already exists
+	Err     error
+}
+
+// transcriptFileFormat returns the output format a saved transcript was written in,
+// or "" for files that aren't transcripts
+func transcriptFileFormat(path string) string {
+	if strings.HasSuffix(path, ".tokens.txt") {
+		return ""
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return "text"
+	case ".srt":
+		return "srt"
+	case ".vtt":
+		return "vtt"
+	case ".json":
+		return "json"
+	}
+	return ""
+}
+
+// ParseTranscript reads a transcript saved in the text, srt, vtt or json format back
+// into segments, with its manifest if it has one. Translated transcripts yield their
+// Hebrew original where it was kept. Plain text has no timing, so its segments
+// (one per line) have none.
+func ParseTranscript(data []byte, format string) ([]Segment, *Manifest, error) {
+	text := strings.NewReplacer("\r\n", "\n", "\u202B", "", "\u202C", "", "\u200E", "", "\u200F", "", "\uFEFF", "").Replace(decodeTranscriptText(data))
+	switch format {
+	case "text":
+		return parseTextTranscript(text), nil, nil
+	case "srt", "vtt":
+		segments, err := parseSubtitles(text, format)
+		return segments, nil, err
+	case "json":
+		return parseJSONTranscript([]byte(text))
+	}
+	return nil, nil, fmt.Errorf("cannot read %s transcripts", format)
+}
+
+// parseTextTranscript reads the text format: "Speaker N: " starts a speaker's turn.
+// Translated transcripts with the original kept have blank-line separated pairs of
+// original and translation, of which the original is read.
+func parseTextTranscript(text string) []Segment {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if strings.Contains(strings.TrimSpace(text), "\n\n") {
+		lines = nil
+		for _, block := range strings.Split(strings.TrimSpace(text), "\n\n") {
+			lines = append(lines, strings.SplitN(strings.TrimSpace(block), "\n", 2)[0])
+		}
+	}
+
+	var segments []Segment
+	speaker := 0
+	speakerPattern := speakerLabelPattern("text")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if m := speakerPattern.FindStringSubmatch(line); m != nil {
+			speaker = speakerIndex(speakerLabelNumber(m))
+			line = strings.TrimSpace(line[len(m[0]):])
+		}
+		if line != "" {
+			segments = append(segments, Segment{Text: line, Speaker: speaker})
+		}
+	}
+	return segments
+}
+
+// parseSubtitles reads SRT and WebVTT cues. A cue's first line is its text; a second
+// line is the translation of a transcript translated with the original kept.
+func parseSubtitles(text, format string) ([]Segment, error) {
+	speakerPattern := speakerLabelPattern(format)
+
+	var segments []Segment
+	speaker := 0
+	for _, block := range strings.Split(text, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		timing := -1
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 || timing == len(lines)-1 {
+			continue // WEBVTT header, NOTE or empty cue
+		}
+
+		times := strings.SplitN(lines[timing], "-->", 2)
+		start, err := parseSubtitleTime(times[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseSubtitleTime(times[1])
+		if err != nil {
+			return nil, err
+		}
+
+		line := strings.TrimSpace(srtAlignTag.ReplaceAllString(strings.TrimSpace(lines[timing+1]), ""))
+		if m := speakerPattern.FindStringSubmatch(line); m != nil {
+			speaker = speakerIndex(speakerLabelNumber(m))
+			line = strings.TrimSpace(line[len(m[0]):])
+		}
+		segments = append(segments, Segment{Start: start, End: end, Text: line, Speaker: speaker})
+	}
+	return segments, nil
+}
+
+// parseSubtitleTime parses an SRT (00:01:02,500) or WebVTT (00:01:02.500) timestamp,
+// ignoring any cue settings after it
+func parseSubtitleTime(value string) (float64, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("missing subtitle timestamp")
+	}
+	return ParseTimecode(strings.Replace(fields[0], ",", ".", 1))
+}
+
+// parseJSONTranscript reads the json format, with or without the manifest wrapper
+// (version 2 and version 1 of the layout, see jsonTranscriptVersion)
+func parseJSONTranscript(data []byte) ([]Segment, *Manifest, error) {
+	type jsonSegment struct {
+		Start       float64 `json:"start"`
+		End         float64 `json:"end"`
+		Speaker     int     `json:"speaker"` // 1-based in the output
+		Text        string  `json:"text"`
+		Original    string  `json:"original"`
+		Translation string  `json:"translation"`
+	}
+	var wrapped struct {
+		Version  int           `json:"version"`
+		Manifest *Manifest     `json:"manifest"`
+		Segments []jsonSegment `json:"segments"`
+	}
+	var raw []jsonSegment
+	if err := json.Unmarshal(data, &raw); err != nil {
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON transcript: %v", err)
+		}
+		if wrapped.Version > jsonTranscriptVersion {
+			return nil, nil, fmt.Errorf("the JSON transcript is version %d, written by a newer release: update the app to read it", wrapped.Version)
+		}
+		raw = wrapped.Segments
+	}
+
+	segments := make([]Segment, len(raw))
+	for i, s := range raw {
+		text := s.Text
+		if s.Original != "" {
+			text = s.Original
+		}
+		segments[i] = Segment{Start: s.Start, End: s.End, Text: text, Speaker: max(0, s.Speaker-1)}
+	}
+	return segments, wrapped.Manifest, nil
+}
+
+// speakerIndex converts a 1-based speaker label number to a speaker ID
+func speakerIndex(label string) int {
+	n, _ := strconv.Atoi(label)
+	return max(0, n-1)
+}
+
+// translatedFileName derives a translation's file name, e.g. talk_transcription_en.srt
+func translatedFileName(path, lang string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + lang + ext
+}
+
+// isTranslatedFileName reports whether a file looks like the output of a previous
+// translation run, which isn't translated again
+func isTranslatedFileName(path string) bool {
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, lang := range validTargetLangs {
+		if strings.HasSuffix(stem, "_"+lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// TranslateDir translates every saved transcript (txt, srt, vtt, json) under dir to
+// cfg's target language and writes each translation in the same format (with cfg's
+// display mode and export options) as <name>_<lang>.<ext>, next to it or at the same
+// relative path under outputDir. Existing translations are skipped, so an
+// interrupted run can be resumed. Per-file failures are reported in the results.
+func TranslateDir(dir, outputDir string, cfg AppConfig, translator segmentTranslator, progressCallback func(string)) ([]DirTranslation, error) {
+	var inputs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && transcriptFileFormat(path) != "" && !isTranslatedFileName(path) {
+			inputs = append(inputs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DirTranslation, 0, len(inputs))
+	for _, input := range inputs {
+		output := translatedFileName(input, cfg.TargetLang)
+		if outputDir != "" {
+			rel, err := filepath.Rel(dir, output)
+			if err != nil {
+				return results, err
+			}
+			output = filepath.Join(outputDir, rel)
+		}
+		result := DirTranslation{Input: input, Output: output}
+		if _, err := os.Stat(output); err == nil {
+			result.Skipped = true
+		} else {
+			result.Err = translateTranscriptFile(input, output, cfg, translator, progressCallback)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// translateTranscriptFile translates one saved transcript into output
+func translateTranscriptFile(input, output string, cfg AppConfig, translator segmentTranslator, progressCallback func(string)) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	format := transcriptFileFormat(input)
+	segments, manifest, err := ParseTranscript(data, format)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("no segments found")
+	}
+
+	translated, err := translator.TranslateSegments(segments, cfg.TargetLang, progressCallback, nil)
+	if err != nil {
+		return err
+	}
+	outputText := FormatOutput(NormalizeNumbers(translated, cfg.Numbers), format, cfg.DisplayMode)
+	if format == "json" && manifest != nil {
+		manifest.Parameters.TranslateTo = cfg.TargetLang
+		if t, ok := translator.(*MistralTranslator); ok {
+			manifest.Parameters.TranslationModel = t.model
+		}
+		outputText = AttachManifest(outputText, *manifest)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(output, cfg.OutputData(outputText, format), 0644)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSegmentTranslator "translates" by upper-casing a prefix, recording the texts it got
+type fakeSegmentTranslator struct {
+	texts []string
+}
+
+func (f *fakeSegmentTranslator) TranslateSegments(segments []Segment, targetLang string, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	translated := make([]Segment, len(segments))
+	for i, seg := range segments {
+		f.texts = append(f.texts, seg.Text)
+		translation := strings.ToUpper(targetLang) + ": " + seg.Text
+		translated[i] = Segment{Start: seg.Start, End: seg.End, Speaker: seg.Speaker, Text: translation, Original: seg.Text, Translation: translation}
+	}
+	return translated, nil
+}
+
+// TestParseTranscript tests reading each saved format back into segments
+func TestParseTranscript(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 2.5, Text: "שלום לכולם", Speaker: 0},
+		{Start: 2.5, End: 4, Text: "מה שלומכם?", Speaker: 0},
+		{Start: 4, End: 6, Text: "בסדר גמור", Speaker: 1},
+	}
+	translated := []Segment{
+		{Start: 0, End: 2.5, Original: "שלום לכולם", Translation: "Hello all", Text: "Hello all", Speaker: 0},
+		{Start: 2.5, End: 4, Original: "מה שלומכם?", Translation: "How are you?", Text: "How are you?", Speaker: 1},
+	}
+
+	for _, format := range []string{"text", "srt", "vtt", "json"} {
+		parsed, _, err := ParseTranscript([]byte(FormatOutput(segments, format, DisplayBilingual)), format)
+		if err != nil {
+			t.Fatalf("%s: ParseTranscript() error: %v", format, err)
+		}
+		expected := segments
+		if format == "text" {
+			expected = []Segment{{Text: "שלום לכולם"}, {Text: "מה שלומכם?"}, {Text: "בסדר גמור", Speaker: 1}}
+		}
+		if !reflect.DeepEqual(parsed, expected) {
+			t.Errorf("%s: ParseTranscript() = %+v", format, parsed)
+		}
+
+		// Translated with the original kept: the original is read
+		parsed, _, err = ParseTranscript([]byte(FormatOutput(translated, format, DisplayBilingual)), format)
+		if err != nil || len(parsed) != 2 || parsed[0].Text != "שלום לכולם" || parsed[1].Text != "מה שלומכם?" || parsed[1].Speaker != 1 {
+			t.Errorf("%s: ParseTranscript(translated) = %+v, %v", format, parsed, err)
+		}
+	}
+
+	manifest := Manifest{Model: "turbo", Input: "talk.m4a"}
+	parsed, parsedManifest, err := ParseTranscript([]byte(AttachManifest(FormatOutput(segments, "json", DisplayBilingual), manifest)), "json")
+	if err != nil || len(parsed) != 3 || parsedManifest == nil || parsedManifest.Model != "turbo" {
+		t.Errorf("ParseTranscript(json with manifest) = %+v, %+v, %v", parsed, parsedManifest, err)
+	}
+	newer := `{"version": 99, "segments": [{"start": 0, "end": 1, "text": "שלום"}]}`
+	if _, _, err := ParseTranscript([]byte(newer), "json"); err == nil {
+		t.Error("Expected an error for a JSON transcript of a newer version")
+	}
+}
+
+// TestTranslateDir tests translating a directory tree into parallel outputs
+func TestTranslateDir(t *testing.T) {
+	dir := t.TempDir()
+	segments := []Segment{{Start: 0, End: 2, Text: "שלום"}, {Start: 2, End: 3, Text: "תודה", Speaker: 1}}
+	os.MkdirAll(filepath.Join(dir, "day2"), 0755)
+	os.WriteFile(filepath.Join(dir, "talk_transcription.srt"), []byte(FormatOutput(segments, "srt", DisplayBilingual)), 0644)
+	os.WriteFile(filepath.Join(dir, "day2", "call_transcription.txt"), []byte(FormatOutput(segments, "text", DisplayBilingual)), 0644)
+	os.WriteFile(filepath.Join(dir, "day2", "call_transcription.tokens.txt"), []byte("#1 debug"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.md"), []byte("# Notes"), 0644)
+	os.WriteFile(filepath.Join(dir, "old_transcription_fr.srt"), []byte("1\n00:00:00,000 --> 00:00:01,000\nBonjour\n"), 0644)
+
+	out := filepath.Join(t.TempDir(), "out")
+	translator := &fakeSegmentTranslator{}
+	cfg := DefaultConfig()
+	cfg.DisplayMode = DisplayTranslation
+	results, err := TranslateDir(dir, out, cfg, translator, nil)
+	if err != nil {
+		t.Fatalf("TranslateDir() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 transcripts, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.Skipped {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	}
+
+	srt, err := os.ReadFile(filepath.Join(out, "talk_transcription_en.srt"))
+	if err != nil || !strings.Contains(string(srt), "00:00:02,000 --> 00:00:03,000\n[Speaker 2] EN: תודה") {
+		t.Errorf("Unexpected SRT translation (%v):\n%s", err, srt)
+	}
+	if _, err := os.Stat(filepath.Join(out, "day2", "call_transcription_en.txt")); err != nil {
+		t.Errorf("Expected the text translation in the same subdirectory: %v", err)
+	}
+
+	// Translations that already exist are skipped
+	results, _ = TranslateDir(dir, out, cfg, translator, nil)
+	if len(results) != 2 || !results[0].Skipped || !results[1].Skipped {
+		t.Errorf("Expected existing translations skipped, got %+v", results)
+	}
+	if len(translator.texts) != 4 {
+		t.Errorf("Expected 4 segments translated, got %q", translator.texts)
+	}
+}
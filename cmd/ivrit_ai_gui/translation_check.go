@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// minBackTranslationSimilarity is the similarity (0-1) between a segment's Hebrew and
+// the back-translation of its translation below which the translation is flagged.
+// Back-translations paraphrase, so a faithful translation scores well below 1.
+const minBackTranslationSimilarity = 0.4
+
+// minCheckedWords is the fewest words a segment needs to be checked: the back-translation
+// of a word or two says too little to compare
+const minCheckedWords = 3
+
+// TranslationFlag is a translated segment whose back-translation differs from the
+// Hebrew enough that the translation may be wrong
+type TranslationFlag struct {
+	Index           int // Segment index in the transcript
+	Start           float64
+	Speaker         int
+	Original        string
+	Translation     string
+	BackTranslation string
+	Similarity      float64
+}
+
+// TranslationReview lists the translations flagged by the back-translation check
+type TranslationReview struct {
+	Language string // Language translated to
+	Checked  int    // Segments checked
+	Flags    []TranslationFlag
+}
+
+// CheckTranslation translates each translated segment back to Hebrew and flags those
+// whose back-translation is not similar to the original. It is an estimate: a flag
+// means the translation is worth a look, not that it is wrong.
+func (t *MistralTranslator) CheckTranslation(segments []Segment, targetLang string, progressCallback func(string)) (TranslationReview, error) {
+	review := TranslationReview{Language: targetLang}
+	for i, seg := range segments {
+		if seg.Original == "" || seg.Translation == "" || len(strings.Fields(seg.Original)) < minCheckedWords {
+			continue
+		}
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Checking translation %d/%d...", i+1, len(segments)))
+		}
+
+		back, err := t.generate(backTranslationPrompt(seg.Translation, languageName(targetLang)), false)
+		if err != nil {
+			return review, fmt.Errorf("failed to back-translate segment %d: %v", i+1, err)
+		}
+		review.Checked++
+		if similarity := TextSimilarity(seg.Original, back); similarity < minBackTranslationSimilarity {
+			review.Flags = append(review.Flags, TranslationFlag{
+				Index:           i,
+				Start:           seg.Start,
+				Speaker:         seg.Speaker,
+				Original:        seg.Original,
+				Translation:     seg.Translation,
+				BackTranslation: back,
+				Similarity:      similarity,
+			})
+		}
+	}
+	return review, nil
+}
+
+// backTranslationPrompt builds the prompt translating a translation back to Hebrew
+func backTranslationPrompt(text, langName string) string {
+	return fmt.Sprintf(`Translate the following %s text to Hebrew. Translate literally, without improving or explaining it. Only output the translation, nothing else.
+
+%s text: %s
+
+Hebrew translation:`, langName, langName, text)
+}
+
+// TextSimilarity compares two texts by the pairs of adjacent letters in their words
+// (the Dice coefficient), from 0 (nothing in common) to 1 (the same letters). Unlike
+// comparing whole words, it still matches a Hebrew word written with another prefix
+// or suffix, as back-translations often do. Punctuation and niqqud are ignored.
+func TextSimilarity(a, b string) float64 {
+	x, y := letterPairs(a), letterPairs(b)
+	if len(x)+len(y) == 0 {
+		return 1
+	}
+	counts := make(map[string]int, len(x))
+	for _, pair := range x {
+		counts[pair]++
+	}
+	common := 0
+	for _, pair := range y {
+		if counts[pair] > 0 {
+			counts[pair]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(x)+len(y))
+}
+
+// letterPairs lists the pairs of adjacent letters of each word, with the word's start
+// and end as a space, so one-letter words count too
+func letterPairs(text string) []string {
+	var pairs []string
+	for _, word := range strings.Fields(readingKey(text)) {
+		runes := []rune(" " + strings.Map(func(r rune) rune {
+			if unicode.IsMark(r) {
+				return -1
+			}
+			return unicode.ToLower(r)
+		}, word) + " ")
+		for i := 0; i+1 < len(runes); i++ {
+			pairs = append(pairs, string(runes[i:i+2]))
+		}
+	}
+	return pairs
+}
+
+// FormatTranslationReview renders the flagged translations: a Markdown table for the
+// markdown format and a plain list otherwise
+func FormatTranslationReview(review TranslationReview, format string) string {
+	summary := fmt.Sprintf("Back-translated %d segments from %s: %d may be mistranslated.", review.Checked, languageName(review.Language), len(review.Flags))
+
+	var b strings.Builder
+	if format == "markdown" {
+		b.WriteString("## Translations to Review\n\n")
+		b.WriteString(summary + "\n\n")
+		if len(review.Flags) == 0 {
+			return b.String()
+		}
+		b.WriteString("| Time | Speaker | Hebrew | Translation | Back-translation | Match |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		cell := func(text string) string { return strings.ReplaceAll(text, "|", `\|`) }
+		for _, f := range review.Flags {
+			fmt.Fprintf(&b, "| %s | Speaker %d | %s | %s | %s | %.0f%% |\n", FormatTimestamp(f.Start, true)[:8], f.Speaker+1,
+				cell(f.Original), cell(f.Translation), cell(f.BackTranslation), f.Similarity*100)
+		}
+		return b.String()
+	}
+
+	b.WriteString("Translations to review\n\n")
+	b.WriteString(summary + "\n")
+	for _, f := range review.Flags {
+		fmt.Fprintf(&b, "\n[%s] Speaker %d (%.0f%% match)\n", FormatTimestamp(f.Start, true)[:8], f.Speaker+1, f.Similarity*100)
+		fmt.Fprintf(&b, "  Hebrew: %s\n", f.Original)
+		fmt.Fprintf(&b, "  Translation: %s\n", f.Translation)
+		fmt.Fprintf(&b, "  Back-translation: %s\n", f.BackTranslation)
+	}
+	return b.String()
+}
+
+// AppendTranslationReview adds the flagged translations to the end of a text or
+// markdown transcript. Other formats can't hold them, so they are returned unchanged
+// with false.
+func AppendTranslationReview(output string, review TranslationReview, format string) (string, bool) {
+	if format != "text" && format != "markdown" {
+		return output, false
+	}
+	return strings.TrimRight(output, "\n") + "\n\n" + FormatTranslationReview(review, format), true
+}
+
+// translationReviewFileName derives the file name of the flagged translations for an
+// input file, written for output formats they can't be appended to
+func translationReviewFileName(inputPath string) string {
+	base := filepath.Base(inputPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "_translation_review.md"
+}
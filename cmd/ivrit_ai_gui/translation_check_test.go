@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestTextSimilarity tests comparing a back-translation with the original Hebrew
+func TestTextSimilarity(t *testing.T) {
+	original := "אנחנו נפגשים מחר בבוקר במשרד"
+	if got := TextSimilarity(original, original); got != 1 {
+		t.Errorf("Identical texts: %.2f, expected 1", got)
+	}
+	if got := TextSimilarity(original, "אָנַחְנוּ נפגשים מחר, בבוקר, במשרד!"); got != 1 {
+		t.Errorf("Expected punctuation and niqqud to be ignored, got %.2f", got)
+	}
+	faithful := TextSimilarity(original, "נפגש מחר בבוקר במשרד")
+	wrong := TextSimilarity(original, "הכלב אכל את העוגה של סבתא")
+	if faithful < minBackTranslationSimilarity || wrong >= minBackTranslationSimilarity {
+		t.Errorf("Expected a paraphrase to pass and a different sentence to be flagged: %.2f, %.2f", faithful, wrong)
+	}
+}
+
+// TestCheckTranslation tests back-translating segments and flagging the suspect ones
+func TestCheckTranslation(t *testing.T) {
+	backTranslations := map[string]string{
+		"We meet tomorrow morning at the office": "אנחנו נפגשים מחר בבוקר במשרד",
+		"The dog ate grandma's cake":             "הכלב אכל את העוגה של סבתא",
+	}
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OllamaRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		prompts = append(prompts, request.Prompt)
+		for translation, back := range backTranslations {
+			if strings.Contains(request.Prompt, translation) {
+				json.NewEncoder(w).Encode(OllamaResponse{Response: back, Done: true})
+				return
+			}
+		}
+		http.Error(w, "unexpected prompt", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	translator := NewMistralTranslator()
+	translator.ollamaURL = server.URL
+
+	segments := []Segment{
+		{Start: 0, End: 3, Original: "אנחנו נפגשים מחר בבוקר במשרד", Translation: "We meet tomorrow morning at the office"},
+		{Start: 3, End: 4, Original: "תודה", Translation: "Thanks"}, // Too short to check
+		{Start: 4, End: 8, Original: "נשלח את החוזה ביום ראשון", Translation: "The dog ate grandma's cake", Speaker: 1},
+		{Start: 8, End: 9, Text: "לא תורגם"},
+	}
+	review, err := translator.CheckTranslation(segments, "en", nil)
+	if err != nil {
+		t.Fatalf("CheckTranslation() error: %v", err)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "Translate the following English text to Hebrew") {
+		t.Errorf("Unexpected prompts: %q", prompts)
+	}
+	if review.Checked != 2 || len(review.Flags) != 1 {
+		t.Fatalf("Expected 1 of 2 segments flagged, got %+v", review)
+	}
+	if f := review.Flags[0]; f.Index != 2 || f.Speaker != 1 || f.BackTranslation != "הכלב אכל את העוגה של סבתא" {
+		t.Errorf("Unexpected flag: %+v", f)
+	}
+
+	markdown, ok := AppendTranslationReview("## Speaker 1 (00:00:00)\n\ntext\n", review, "markdown")
+	if !ok || !strings.Contains(markdown, "## Translations to Review") || !strings.Contains(markdown, "| 00:00:04 | Speaker 2 | נשלח את החוזה ביום ראשון | The dog ate grandma's cake |") {
+		t.Errorf("Unexpected markdown review:\n%s", markdown)
+	}
+	if _, ok := AppendTranslationReview("1\n00:00:00,000 --> 00:00:03,000\ntext\n", review, "srt"); ok {
+		t.Error("Expected SRT output not to hold the review")
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transliteration methods: Latin-script renderings of the Hebrew for readers who
+// don't read Hebrew letters, such as language learners
+const (
+	TransliterateRules = "rules" // Built-in letter rules: instant, offline and approximate
+	TransliterateLLM   = "llm"   // The local LLM (via Ollama): slower, with vowels as pronounced
+)
+
+var validTransliterations = []string{TransliterateRules, TransliterateLLM}
+
+// TransliterationOptions selects the transliteration added to transcripts
+type TransliterationOptions struct {
+	Method string `json:"method,omitempty"` // TransliterateRules or TransliterateLLM ("" = off)
+	Only   bool   `json:"only,omitempty"`   // Output the transliteration in place of the Hebrew
+}
+
+// Hebrew points and the maqaf (hyphen) used by the rules; the geresh and gershayim
+// are with the sentence punctuation
+const (
+	hebrewShva   = '\u05B0'
+	hebrewHolam  = '\u05B9'
+	hebrewDagesh = '\u05BC' // Also shuruk (in vav) and mappiq (in final heh)
+	hebrewSinDot = '\u05C2'
+	hebrewMaqaf  = '\u05BE'
+)
+
+// hebrewVowels maps vowel points to their Modern Hebrew sounds
+var hebrewVowels = map[rune]string{
+	'\u05B1': "e", // Hataf segol
+	'\u05B2': "a", // Hataf patah
+	'\u05B3': "o", // Hataf kamatz
+	'\u05B4': "i", // Hiriq
+	'\u05B5': "e", // Tsere
+	'\u05B6': "e", // Segol
+	'\u05B7': "a", // Patah
+	'\u05B8': "a", // Kamatz
+	'\u05B9': "o", // Holam
+	'\u05BA': "o", // Holam haser for vav
+	'\u05BB': "u", // Kubutz
+	'\u05C7': "o", // Kamatz katan
+}
+
+// hebrewLetter is a letter with the points and geresh written on it
+type hebrewLetter struct {
+	r      rune
+	marks  []rune
+	geresh bool
+}
+
+func (l hebrewLetter) has(mark rune) bool {
+	for _, m := range l.marks {
+		if m == mark {
+			return true
+		}
+	}
+	return false
+}
+
+// vowel returns the sound of the letter's vowel point ("" for none or a silent shva)
+func (l hebrewLetter) vowel(first bool) string {
+	for _, m := range l.marks {
+		if v, ok := hebrewVowels[m]; ok {
+			return v
+		}
+		if m == hebrewShva && first {
+			return "e"
+		}
+	}
+	return ""
+}
+
+func isHebrewLetter(r rune) bool {
+	return r >= 'א' && r <= 'ת'
+}
+
+func isHebrewPoint(r rune) bool {
+	return r >= '\u05B0' && r <= '\u05C7' && r != hebrewMaqaf
+}
+
+// TransliterateHebrew renders Hebrew in Latin letters with letter rules. Pointed
+// text (with niqqud) is read from its vowel points; ordinary unpointed text has its
+// vowels guessed from vav, yod, heh and alef, with "a" between other consonants,
+// so names and common words come out recognizable rather than exact. Other scripts,
+// digits and punctuation are kept.
+func TransliterateHebrew(text string) string {
+	var out strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !isHebrewLetter(runes[i]) {
+			switch runes[i] {
+			case hebrewMaqaf:
+				out.WriteByte('-')
+			case hebrewGershayim:
+				out.WriteByte('"')
+			case hebrewGeresh:
+				out.WriteByte('\'')
+			default:
+				if !isHebrewPoint(runes[i]) {
+					out.WriteRune(runes[i])
+				}
+			}
+			i++
+			continue
+		}
+
+		var word []hebrewLetter
+		for i < len(runes) && isHebrewLetter(runes[i]) {
+			letter := hebrewLetter{r: runes[i]}
+			i++
+			for i < len(runes) {
+				if isHebrewPoint(runes[i]) {
+					letter.marks = append(letter.marks, runes[i])
+				} else if (runes[i] == hebrewGeresh || runes[i] == '\'') && strings.ContainsRune("גזצץת", letter.r) {
+					letter.geresh = true
+				} else {
+					break
+				}
+				i++
+			}
+			word = append(word, letter)
+		}
+		out.WriteString(transliterateWord(word))
+	}
+	return out.String()
+}
+
+// transliterateWord transliterates the letters of one word
+func transliterateWord(word []hebrewLetter) string {
+	pointed := false
+	for _, l := range word {
+		if len(l.marks) > 0 {
+			pointed = true
+		}
+	}
+
+	var b strings.Builder
+	afterConsonant := false // The last sound written was a consonant with no vowel yet
+	lastVowel := ""
+	consonant := func(sound, vowel string) {
+		if !pointed && afterConsonant {
+			b.WriteString("a")
+		}
+		b.WriteString(sound)
+		b.WriteString(vowel)
+		afterConsonant = vowel == ""
+		lastVowel = vowel
+	}
+	vowelLetter := func(vowel string) {
+		b.WriteString(vowel)
+		afterConsonant = vowel == "" && afterConsonant
+		if vowel != "" {
+			lastVowel = vowel
+		}
+	}
+
+	for k := 0; k < len(word); k++ {
+		l := word[k]
+		first, last := k == 0, k == len(word)-1
+		doubled := !last && word[k+1].r == l.r
+		vowel := l.vowel(first)
+
+		switch l.r {
+		case 'ו':
+			switch {
+			case pointed && l.has(hebrewHolam) && len(l.marks) == 1:
+				vowelLetter("o") // Holam male
+			case pointed && l.has(hebrewDagesh) && vowel == "" && len(l.marks) == 1:
+				vowelLetter("u") // Shuruk
+			case pointed || first:
+				consonant("v", vowel)
+			case doubled:
+				consonant("v", "") // Double vav spells a consonant
+				k++
+			default:
+				vowelLetter("o")
+			}
+		case 'י':
+			switch {
+			case pointed && vowel == "" && !first && (lastVowel == "i" || lastVowel == "e") && !afterConsonant:
+				vowelLetter("") // Spells the vowel before it
+			case pointed || first:
+				consonant("y", vowel)
+			case doubled:
+				consonant("y", "")
+				k++
+			default:
+				vowelLetter("i")
+			}
+		case 'ה':
+			if last && !l.has(hebrewDagesh) && vowel == "" {
+				if !pointed {
+					vowelLetter("a") // Final heh usually ends in "a"
+				}
+				continue
+			}
+			consonant("h", vowel)
+		case 'א', 'ע':
+			// Silent in Modern Hebrew; they carry a vowel
+			switch {
+			case pointed:
+				vowelLetter(vowel)
+			case last:
+				vowelLetter("")
+			default:
+				vowelLetter("a")
+			}
+		default:
+			consonant(consonantSound(l, first, pointed), vowel)
+		}
+	}
+	return b.String()
+}
+
+// consonantSound returns the Latin spelling of a consonant. Bet, kaf and peh are hard
+// with a dagesh, or in unpointed text at the start of a word, where they usually are.
+func consonantSound(l hebrewLetter, first, pointed bool) string {
+	hard := l.has(hebrewDagesh) || (!pointed && first)
+	switch l.r {
+	case 'ב':
+		if hard {
+			return "b"
+		}
+		return "v"
+	case 'ג':
+		if l.geresh {
+			return "j"
+		}
+		return "g"
+	case 'ד':
+		return "d"
+	case 'ז':
+		if l.geresh {
+			return "zh"
+		}
+		return "z"
+	case 'ח':
+		return "ch"
+	case 'ט', 'ת':
+		return "t"
+	case 'כ':
+		if hard {
+			return "k"
+		}
+		return "kh"
+	case 'ך':
+		return "kh"
+	case 'ל':
+		return "l"
+	case 'מ', 'ם':
+		return "m"
+	case 'נ', 'ן':
+		return "n"
+	case 'ס':
+		return "s"
+	case 'פ':
+		if hard {
+			return "p"
+		}
+		return "f"
+	case 'ף':
+		return "f"
+	case 'צ', 'ץ':
+		if l.geresh {
+			return "ch"
+		}
+		return "ts"
+	case 'ק':
+		return "k"
+	case 'ר':
+		return "r"
+	case 'ש':
+		if l.has(hebrewSinDot) {
+			return "s"
+		}
+		return "sh"
+	}
+	return ""
+}
+
+// Transliterate renders Hebrew text in Latin letters as it is pronounced in Modern Hebrew
+func (t *MistralTranslator) Transliterate(text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+	prompt := fmt.Sprintf(`Transliterate the following Hebrew text into Latin letters, as it is pronounced in Modern Hebrew (for example "שלום, מה שלומך?" becomes "shalom, ma shlomkha?"). Keep punctuation and numbers. Only output the transliteration, nothing else.
+
+Hebrew text: %s
+
+Transliteration:`, text)
+	return t.generate(prompt, false)
+}
+
+// transliterator returns the function transliterating text with a method
+func transliterator(method string) func(string) (string, error) {
+	if method == TransliterateLLM {
+		return NewMistralTranslator().Transliterate
+	}
+	return func(text string) (string, error) {
+		return TransliterateHebrew(text), nil
+	}
+}
+
+// TransliterateSegments returns the segments with their Hebrew (the original, when
+// translated) transliterated: in the Transliteration field, or with only set, in
+// place of the Hebrew.
+func TransliterateSegments(segments []Segment, transliterate func(string) (string, error), only bool, progressCallback func(string)) ([]Segment, error) {
+	transliterated := make([]Segment, len(segments))
+	for i, seg := range segments {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Transliterating segment %d/%d...", i+1, len(segments)))
+		}
+
+		hebrew := &seg.Text
+		if seg.Original != "" {
+			hebrew = &seg.Original
+		}
+		latin, err := transliterate(strings.TrimSpace(*hebrew))
+		if err != nil {
+			return nil, fmt.Errorf("failed to transliterate segment %d: %v", i+1, err)
+		}
+		if only {
+			*hebrew = latin
+		} else {
+			seg.Transliteration = latin
+		}
+		transliterated[i] = seg
+	}
+	return transliterated, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestTransliterateHebrew tests the rule-based transliteration of pointed and unpointed Hebrew
+func TestTransliterateHebrew(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Unpointed", "שלום", "shalom"},
+		{"Final heh", "תודה", "toda"},
+		{"Soft bet and vav", "דבר טוב", "davar tov"},
+		{"Geresh", "ג'ירפה", "jirafa"},
+		{"Pointed", "שָׁלוֹם", "shalom"},
+		{"Pointed shuruk and sin", "שׂוּק", "suk"},
+		{"Pointed dagesh", "סֵפֶר", "sefer"},
+		{"Punctuation and digits", "שלום, 3 פעמים!", "shalom, 3 pamim!"},
+		{"Maqaf", "בית־ספר", "bit-safar"},
+		{"Other scripts", "Hello", "Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TransliterateHebrew(tt.input); got != tt.expected {
+				t.Errorf("TransliterateHebrew(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestTransliterateSegments tests adding transliterations and replacing the Hebrew with them
+func TestTransliterateSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 1, Text: "שלום"},
+		{Start: 1, End: 2, Text: "Thanks", Original: "תודה", Translation: "Thanks"},
+	}
+	rules := transliterator(TransliterateRules)
+
+	added, err := TransliterateSegments(segments, rules, false, nil)
+	if err != nil {
+		t.Fatalf("TransliterateSegments() error: %v", err)
+	}
+	if added[0].Text != "שלום" || added[0].Transliteration != "shalom" || added[1].Transliteration != "toda" {
+		t.Errorf("Expected transliterations added, got %+v", added)
+	}
+	if output := FormatOutput(added, "srt", DisplayBilingual); !strings.Contains(output, "שלום\nshalom\n\n") || !strings.Contains(output, "תודה\ntoda\nThanks\n\n") {
+		t.Errorf("Expected transliteration lines in the subtitles, got %q", output)
+	}
+	if output := FormatOutput(added, "json", DisplayBilingual); !strings.Contains(output, `"transliteration": "toda"`) {
+		t.Errorf("Expected a transliteration field in JSON, got %q", output)
+	}
+
+	only, err := TransliterateSegments(segments, rules, true, nil)
+	if err != nil {
+		t.Fatalf("TransliterateSegments() error: %v", err)
+	}
+	if only[0].Text != "shalom" || only[1].Original != "toda" || only[1].Translation != "Thanks" || only[0].Transliteration != "" {
+		t.Errorf("Expected the Hebrew replaced, got %+v", only)
+	}
+
+	failing := func(string) (string, error) { return "", fmt.Errorf("ollama is not running") }
+	if _, err := TransliterateSegments(segments, failing, false, nil); err == nil || !strings.Contains(err.Error(), "segment 1") {
+		t.Errorf("Expected the failing segment reported, got %v", err)
+	}
+}
+
+// TestRedactTransliteration tests that redacted copies don't leak masked words in Latin letters
+func TestRedactTransliteration(t *testing.T) {
+	redactor := NewRedactor([]string{"דני"})
+	segments := []Segment{
+		{Text: "שלום דני", Transliteration: "shalom dani"},
+		{Text: "הטלפון", Transliteration: "hatelefon 050-1234567"},
+	}
+	redacted, _ := redactor.RedactSegments(segments)
+	if redacted[0].Transliteration != "" {
+		t.Errorf("Expected the transliteration of masked text dropped, got %q", redacted[0].Transliteration)
+	}
+	if strings.Contains(redacted[1].Transliteration, "1234567") {
+		t.Errorf("Expected the phone number masked, got %q", redacted[1].Transliteration)
+	}
+}
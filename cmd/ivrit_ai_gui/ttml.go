@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ttmlHeader opens an IMSC1 text profile document with its language and writing
+// mode: white on black cues centred at the bottom of the picture
+const ttmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:tts="http://www.w3.org/ns/ttml#styling" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text" ttp:timeBase="media" xml:lang="%s">
+  <head>
+    <styling>
+      <style xml:id="cue" tts:textAlign="center" tts:color="white" tts:backgroundColor="black" tts:fontFamily="proportionalSansSerif"/>
+    </styling>
+    <layout>
+      <region xml:id="bottom" tts:origin="10%% 75%%" tts:extent="80%% 20%%" tts:displayAlign="after" tts:writingMode="%s"/>
+    </layout>
+  </head>
+  <body style="cue" region="bottom">
+    <div>
+`
+
+// FormatTTML writes segments as a TTML (IMSC1) document, the timed text format of
+// broadcasters and streaming platforms that don't take SRT. Hebrew lines are
+// right-to-left, and a translation under them is marked left-to-right.
+func FormatTTML(segments []Segment) string {
+	language, writingMode := "he", "rltb"
+	if !segmentsHaveHebrew(segments) {
+		language, writingMode = "", "lrtb" // A translation, in a language the segments don't name
+	}
+	output := fmt.Sprintf(ttmlHeader, language, writingMode)
+
+	lastSpeaker := -1
+	for _, seg := range segments {
+		speakerLabel := ""
+		if seg.Speaker != lastSpeaker && seg.Event == "" {
+			speakerLabel = speakerLinePrefix(seg, "ttml")
+			lastSpeaker = seg.Speaker
+		}
+		text := seg.Text
+		if seg.Original != "" && seg.Translation != "" {
+			text = seg.Original
+		}
+		lines := []string{ttmlLine(speakerLabel + text)}
+		if seg.Transliteration != "" {
+			lines = append(lines, ttmlLine(seg.Transliteration))
+		}
+		if seg.Original != "" && seg.Translation != "" {
+			lines = append(lines, ttmlLine(seg.Translation))
+		}
+		output += fmt.Sprintf("      <p begin=\"%s\" end=\"%s\">%s</p>\n",
+			FormatTimestamp(seg.Start, true), FormatTimestamp(seg.End, true), strings.Join(lines, "<br/>"))
+	}
+	return output + "    </div>\n  </body>\n</tt>\n"
+}
+
+// ttmlLine returns a line of a cue as escaped XML, in a span of its direction
+func ttmlLine(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(strings.TrimSpace(text)))
+	direction := "ltr"
+	if strings.ContainsFunc(text, isRTLRune) {
+		direction = "rtl"
+	}
+	return fmt.Sprintf(`<span tts:direction="%s" tts:unicodeBidi="embed">%s</span>`, direction, escaped.String())
+}
+
+// segmentsHaveHebrew reports whether the text shown of any segment is in Hebrew
+func segmentsHaveHebrew(segments []Segment) bool {
+	for _, seg := range segments {
+		if seg.Event == "" && (strings.ContainsFunc(seg.Text, isHebrewLetter) || strings.ContainsFunc(seg.Original, isHebrewLetter)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// TestFormatTTML tests IMSC1 documents with Hebrew and translated cues
+func TestFormatTTML(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 1.5, Text: "שלום & ברוכים הבאים"},
+		{Start: 1.5, End: 3, Text: "Hello", Original: "שלום", Translation: "Hello", Speaker: 1},
+	}
+	output := FormatOutput(segments, "ttml", DisplayBilingual)
+	if err := xml.Unmarshal([]byte(output), new(struct{})); err != nil {
+		t.Fatalf("Expected well-formed XML: %v\n%s", err, output)
+	}
+	for _, want := range []string{
+		`ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text"`,
+		`xml:lang="he"`,
+		`tts:writingMode="rltb"`,
+		`<p begin="00:00:00.000" end="00:00:01.500"><span tts:direction="rtl" tts:unicodeBidi="embed">[Speaker 1] שלום &amp; ברוכים הבאים</span></p>`,
+		`<span tts:direction="rtl" tts:unicodeBidi="embed">[Speaker 2] שלום</span><br/><span tts:direction="ltr" tts:unicodeBidi="embed">Hello</span>`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %s in:\n%s", want, output)
+		}
+	}
+
+	// A translation only is written left-to-right
+	translated := FormatOutput(segments[1:], "ttml", DisplayTranslation)
+	if !strings.Contains(translated, `tts:writingMode="lrtb"`) || strings.Contains(translated, "שלום") {
+		t.Errorf("Expected a left-to-right document with the translation only:\n%s", translated)
+	}
+}
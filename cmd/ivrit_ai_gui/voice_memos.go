@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// voiceMemosDirs are where the Voice Memos app keeps its recordings, under the home
+// folder: the shared group container of macOS 14 and later, then the app's folder
+// on earlier versions
+var voiceMemosDirs = []string{
+	"Library/Group Containers/group.com.apple.VoiceMemos.shared/Recordings",
+	"Library/Application Support/com.apple.voicememos/Recordings",
+}
+
+// voiceMemoExtensions are the file types of recordings: AAC or ALAC in .m4a, and
+// QuickTime audio (.qta) on macOS 14 and later
+var voiceMemoExtensions = []string{".m4a", ".qta"}
+
+// recentVoiceMemos is how many memos the GUI offers
+const recentVoiceMemos = 10
+
+// VoiceMemo is a recording of the Voice Memos app
+type VoiceMemo struct {
+	Path     string
+	Title    string // As named in the app (the file name when unknown)
+	Date     time.Time
+	Duration float64 // Seconds (0 = unknown)
+}
+
+// Describe returns a line naming the memo, e.g. "Lecture (2024-03-05 14:07, 52:10)"
+func (m VoiceMemo) Describe() string {
+	details := m.Date.Format("2006-01-02 15:04")
+	if m.Duration > 0 {
+		details += ", " + formatClockDuration(m.Duration)
+	}
+	return fmt.Sprintf("%s (%s)", m.Title, details)
+}
+
+// ListVoiceMemos returns the most recent recordings of the Voice Memos app, newest
+// first (all of them when limit is 0)
+func ListVoiceMemos(limit int) ([]VoiceMemo, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, errors.New("Voice Memos is a macOS app")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range voiceMemosDirs {
+		dir = filepath.Join(home, dir)
+		if _, err := os.Stat(dir); err == nil {
+			return readVoiceMemos(dir, voiceMemoTitles(dir), limit)
+		}
+	}
+	return nil, errors.New("no Voice Memos recordings found (are they synced to this Mac?)")
+}
+
+// readVoiceMemos returns the recordings in a Voice Memos folder, newest first, with
+// their titles and durations by file name
+func readVoiceMemos(dir string, titles map[string]VoiceMemo, limit int) ([]VoiceMemo, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, errors.New("cannot read the Voice Memos recordings: allow this app (or the terminal running it) Full Disk Access in System Settings > Privacy & Security")
+	} else if err != nil {
+		return nil, err
+	}
+
+	var memos []VoiceMemo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !containsString(voiceMemoExtensions, strings.ToLower(filepath.Ext(name))) {
+			continue
+		}
+		memo := VoiceMemo{Path: filepath.Join(dir, name), Title: strings.TrimSuffix(name, filepath.Ext(name))}
+		memo.Date = voiceMemoDate(name)
+		if memo.Date.IsZero() {
+			if info, err := entry.Info(); err == nil {
+				memo.Date = info.ModTime()
+			}
+		}
+		if known, ok := titles[name]; ok {
+			if known.Title != "" {
+				memo.Title = known.Title
+			}
+			memo.Duration = known.Duration
+		}
+		memos = append(memos, memo)
+	}
+	sort.SliceStable(memos, func(i, j int) bool { return memos[i].Date.After(memos[j].Date) })
+	if limit > 0 && len(memos) > limit {
+		memos = memos[:limit]
+	}
+	return memos, nil
+}
+
+// voiceMemoDate returns when a memo was recorded from its file name, which the app
+// starts with the local date and time, e.g. "20240305 140709-1A2B3C4D.m4a"
+func voiceMemoDate(name string) time.Time {
+	if len(name) < 15 {
+		return time.Time{}
+	}
+	date, err := time.ParseInLocation("20060102 150405", name[:15], time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// voiceMemoTitles reads the titles and durations of the recordings from the app's
+// database with the sqlite3 tool macOS ships, by file name. Without them, memos are
+// listed by file name.
+func voiceMemoTitles(dir string) map[string]VoiceMemo {
+	db := filepath.Join(dir, "CloudRecordings.db")
+	if _, err := os.Stat(db); err != nil {
+		return nil
+	}
+	// The title column is named ZENCRYPTEDTITLE since macOS 10.15, ZCUSTOMLABEL before
+	for _, column := range []string{"ZENCRYPTEDTITLE", "ZCUSTOMLABEL"} {
+		query := fmt.Sprintf("SELECT ZPATH, %s, ZDURATION FROM ZCLOUDRECORDING;", column)
+		output, err := exec.Command("sqlite3", "-readonly", "-separator", "\x1f", db, query).Output()
+		if err == nil {
+			return parseVoiceMemoTitles(string(output))
+		}
+	}
+	return nil
+}
+
+// parseVoiceMemoTitles parses the rows of the Voice Memos database query: path,
+// title and duration separated by the unit separator
+func parseVoiceMemoTitles(output string) map[string]VoiceMemo {
+	titles := make(map[string]VoiceMemo)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 3 || fields[0] == "" {
+			continue
+		}
+		duration, _ := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		titles[filepath.Base(fields[0])] = VoiceMemo{Title: strings.TrimSpace(fields[1]), Duration: duration}
+	}
+	return titles
+}
+
+// printVoiceMemos lists memos numbered from 1, newest first, for "voice-memos <n>"
+func printVoiceMemos(w io.Writer, memos []VoiceMemo) {
+	if len(memos) == 0 {
+		fmt.Fprintln(w, "No Voice Memos recordings")
+	}
+	for i, memo := range memos {
+		fmt.Fprintf(w, "%3d. %s\n     %s\n", i+1, memo.Describe(), memo.Path)
+	}
+}
+
+// voiceMemoPaths returns the files of the memos numbered as printVoiceMemos lists them
+func voiceMemoPaths(memos []VoiceMemo, numbers []string) ([]string, error) {
+	var paths []string
+	for _, arg := range numbers {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(memos) {
+			return nil, fmt.Errorf("no voice memo numbered %q (see the list of voice-memos)", arg)
+		}
+		paths = append(paths, memos[n-1].Path)
+	}
+	return paths, nil
+}
+
+// copiedFinderFile returns the media file copied in the Finder or shared from an app
+// such as Voice Memos, which macOS puts on the clipboard as a file reference with
+// only the file's name as text
+func copiedFinderFile() (string, bool) {
+	if runtime.GOOS != "darwin" {
+		return "", false
+	}
+	output, err := exec.Command("osascript", "-e", "POSIX path of (the clipboard as «class furl»)").Output()
+	if err != nil {
+		return "", false
+	}
+	return ClipboardMedia(strings.TrimSpace(string(output)))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestReadVoiceMemos tests listing recordings newest first, with their titles and
+// durations from the app's database
+func TestReadVoiceMemos(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"20240301 090000-AAAA.m4a",
+		"20240305 140709-BBBB.qta",
+		"20240303 120000-CCCC.m4a",
+		"CloudRecordings.db",
+		"20240306 080000-DDDD.waveform",
+	} {
+		os.WriteFile(filepath.Join(dir, name), []byte("audio"), 0644)
+	}
+	titles := parseVoiceMemoTitles("/Users/me/Recordings/20240305 140709-BBBB.qta\x1fLecture\x1f3130.2\n" +
+		"20240303 120000-CCCC.m4a\x1f\x1f42\n")
+
+	memos, err := readVoiceMemos(dir, titles, 0)
+	if err != nil {
+		t.Fatalf("readVoiceMemos() error: %v", err)
+	}
+	if len(memos) != 3 {
+		t.Fatalf("Expected 3 memos, got %+v", memos)
+	}
+	if memos[0].Title != "Lecture" || memos[0].Duration != 3130.2 || memos[0].Path != filepath.Join(dir, "20240305 140709-BBBB.qta") {
+		t.Errorf("Unexpected newest memo: %+v", memos[0])
+	}
+	if memos[1].Title != "20240303 120000-CCCC" || memos[1].Duration != 42 {
+		t.Errorf("Memo without a title not named by its file: %+v", memos[1])
+	}
+	if want := time.Date(2024, 3, 5, 14, 7, 9, 0, time.Local); !memos[0].Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", memos[0].Date, want)
+	}
+	if got := memos[0].Describe(); got != "Lecture (2024-03-05 14:07, 52:10)" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	if memos, _ := readVoiceMemos(dir, nil, 2); len(memos) != 2 || memos[1].Title != "20240303 120000-CCCC" {
+		t.Errorf("Expected the 2 newest memos, got %+v", memos)
+	}
+}
+
+// TestVoiceMemoPaths tests choosing memos by their number in the list
+func TestVoiceMemoPaths(t *testing.T) {
+	memos := []VoiceMemo{{Path: "/r/new.m4a"}, {Path: "/r/old.m4a"}}
+	paths, err := voiceMemoPaths(memos, []string{"2", "1"})
+	if err != nil || len(paths) != 2 || paths[0] != "/r/old.m4a" || paths[1] != "/r/new.m4a" {
+		t.Errorf("voiceMemoPaths() = %v, %v", paths, err)
+	}
+	for _, arg := range []string{"0", "3", "new"} {
+		if _, err := voiceMemoPaths(memos, []string{arg}); err == nil {
+			t.Errorf("Expected an error for %q", arg)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordingStartLayouts are the ways a recording's start can be written in -start-time
+// or a .start file, read in the local time zone unless they include one
+var recordingStartLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+var (
+	// Dated file names: Zoom's "2024-05-03 10.00.00 <topic>", OBS's "2024-05-03 10-00-00"
+	// and Google Meet's "<title> (2024-05-03 10:00 GMT+3)", with an optional zone
+	datedNamePattern = regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})[ _T](\d{2})[.:\-](\d{2})(?:[.:\-](\d{2}))?(?: ?(?:GMT|UTC)([+\-]\d{1,2})(?::?(\d{2}))?)?`)
+	// Phone recorders' compact "20240503_100000" and "20240503-100000"
+	compactNamePattern = regexp.MustCompile(`(?:^|\D)(\d{4})(\d{2})(\d{2})[_\-T](\d{2})(\d{2})(\d{2})(?:\D|$)`)
+	// Timing lines of SRT and WebVTT cues
+	cueTimingPattern = regexp.MustCompile(`^(\d{2,}:\d{2}:\d{2}[,.]\d{3}) --> (\d{2,}:\d{2}:\d{2}[,.]\d{3})(.*)$`)
+	// The timestamp of a markdown heading, linked to the recording or not
+	headingTimePattern = regexp.MustCompile(`\((\[?)(\d{2,}:\d{2}:\d{2})(\]\([^)]*\))?\)$`)
+	// The timestamp starting a line of text, as in per-speaker files: [00:01:05]
+	lineTimePattern = regexp.MustCompile(`^\[(\d{2,}:\d{2}:\d{2})\] `)
+)
+
+// Where a recording's start time was found
+const (
+	StartFromFlag     = "set"
+	StartFromSidecar  = "start file"
+	StartFromFileName = "file name"
+)
+
+// ParseRecordingStart reads the time a recording started, e.g. "2024-05-03 10:00"
+func ParseRecordingStart(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range recordingStartLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid start time %q (expected e.g. 2024-05-03 10:00 or 2024-05-03 10:00:05)", value)
+}
+
+// RecordingStartFromName finds the date and time a recording started in its file
+// name, or in its folder's name for the files of a Zoom recording
+func RecordingStartFromName(path string) (time.Time, bool) {
+	for _, name := range []string{filepath.Base(path), filepath.Base(filepath.Dir(path))} {
+		if m := datedNamePattern.FindStringSubmatch(name); m != nil {
+			if t, ok := namedTime(m[1:7], m[7], m[8]); ok {
+				return t, true
+			}
+		}
+		if m := compactNamePattern.FindStringSubmatch(name); m != nil {
+			if t, ok := namedTime(m[1:7], "", ""); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// namedTime builds the time from a file name's year, month, day, hour, minute and
+// (possibly empty) second, in the zone given as a GMT offset or else the local one.
+// Numbers out of range, like a month of 13, aren't a time.
+func namedTime(fields []string, zoneHours, zoneMinutes string) (time.Time, bool) {
+	n := make([]int, len(fields))
+	for i, field := range fields {
+		if field != "" {
+			n[i], _ = strconv.Atoi(field)
+		}
+	}
+	location := time.Local
+	if zoneHours != "" {
+		hours, _ := strconv.Atoi(zoneHours)
+		minutes, _ := strconv.Atoi(zoneMinutes)
+		if hours < 0 {
+			minutes = -minutes
+		}
+		location = time.FixedZone("GMT"+zoneHours, hours*3600+minutes*60)
+	}
+	t := time.Date(n[0], time.Month(n[1]), n[2], n[3], n[4], n[5], 0, location)
+	if t.Month() != time.Month(n[1]) || t.Day() != n[2] || t.Hour() != n[3] || t.Minute() != n[4] || t.Second() != n[5] {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// recordingStartFile is the text file next to a recording that can give its start
+// time: meeting.m4a's is meeting.start
+func recordingStartFile(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".start"
+}
+
+// ResolveRecordingStart finds when the recording at inputPath started: the value
+// given (-start-time or the GUI's field), else the recording's .start file, else
+// the date and time in its name. ok is false when none gives one.
+func ResolveRecordingStart(value, inputPath string) (start time.Time, source string, ok bool, err error) {
+	if strings.TrimSpace(value) != "" {
+		start, err = ParseRecordingStart(value)
+		return start, StartFromFlag, err == nil, err
+	}
+	if data, readErr := os.ReadFile(recordingStartFile(inputPath)); readErr == nil {
+		start, err = ParseRecordingStart(string(data))
+		if err != nil {
+			return time.Time{}, "", false, fmt.Errorf("%s: %v", recordingStartFile(inputPath), err)
+		}
+		return start, StartFromSidecar, true, nil
+	}
+	if start, ok := RecordingStartFromName(inputPath); ok {
+		return start, StartFromFileName, true, nil
+	}
+	return time.Time{}, "", false, nil
+}
+
+// FormatRecordingStart writes a start time as the field and manifest show it
+func FormatRecordingStart(start time.Time) string {
+	if start.Second() == 0 {
+		return start.Format("2006-01-02 15:04")
+	}
+	return start.Format("2006-01-02 15:04:05")
+}
+
+// ApplyWallClock shows the times of a transcript as the time of day, for a recording
+// that started at start: the cue timings of SRT and WebVTT, the timestamps of
+// markdown headings, whose links still point into the recording, and those starting
+// lines of text. Text and markdown also note when the recording started. Times after midnight go on counting (24:05:00),
+// so the cues stay in order. JSON keeps its offsets, with the start in its manifest,
+// and other formats are returned unchanged with false.
+func ApplyWallClock(output string, start time.Time, format string) (string, bool) {
+	offset := wallClockOffset(start)
+	switch format {
+	case "srt", "vtt":
+		lines := strings.Split(output, "\n")
+		for i, line := range lines {
+			if m := cueTimingPattern.FindStringSubmatch(line); m != nil {
+				lines[i] = shiftTimecode(m[1], offset) + " --> " + shiftTimecode(m[2], offset) + m[3]
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	case "markdown":
+		lines := strings.Split(output, "\n")
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "#") {
+				continue
+			}
+			if m := headingTimePattern.FindStringSubmatchIndex(line); m != nil {
+				lines[i] = line[:m[4]] + shiftClock(line[m[4]:m[5]], offset) + line[m[5]:]
+			}
+		}
+		return fmt.Sprintf("*Recording started %s*\n\n", FormatRecordingStart(start)) + strings.Join(lines, "\n"), true
+	case "text":
+		lines := strings.Split(output, "\n")
+		for i, line := range lines {
+			if m := lineTimePattern.FindStringSubmatchIndex(line); m != nil {
+				lines[i] = line[:m[2]] + shiftClock(line[m[2]:m[3]], offset) + line[m[3]:]
+			}
+		}
+		return fmt.Sprintf("Recording started %s\n\n", FormatRecordingStart(start)) + strings.Join(lines, "\n"), true
+	}
+	return output, false
+}
+
+// shiftClock adds offset milliseconds to an HH:MM:SS timestamp
+func shiftClock(clock string, offset int64) string {
+	shifted := shiftTimecode(clock+".000", offset)
+	return shifted[:len(shifted)-4]
+}
+
+// wallClockOffset is the time of day the recording started, in milliseconds
+func wallClockOffset(start time.Time) int64 {
+	return int64(start.Hour()*3600+start.Minute()*60+start.Second())*1000 + int64(start.Nanosecond()/int(time.Millisecond))
+}
+
+// shiftTimecode adds offset milliseconds to an HH:MM:SS,mmm (or .mmm) timecode,
+// keeping its separator. Whole milliseconds are added, so no rounding creeps in.
+func shiftTimecode(timecode string, offset int64) string {
+	separator := timecode[len(timecode)-4]
+	clock, fraction := timecode[:len(timecode)-4], timecode[len(timecode)-3:]
+	parts := strings.Split(clock, ":")
+	var millis int64
+	for _, part := range parts {
+		n, _ := strconv.ParseInt(part, 10, 64)
+		millis = millis*60 + n
+	}
+	ms, _ := strconv.ParseInt(fraction, 10, 64)
+	return formatMillis(millis*1000+ms+offset, separator)
+}
+
+// formatMillis writes milliseconds as HH:MM:SS followed by separator and the milliseconds
+func formatMillis(millis int64, separator byte) string {
+	seconds := millis / 1000
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", seconds/3600, seconds%3600/60, seconds%60, separator, millis%1000)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRecordingStartFromName tests reading the start of a recording from its name
+func TestRecordingStartFromName(t *testing.T) {
+	local := func(year, month, day, hour, minute, second int) time.Time {
+		return time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Local)
+	}
+	tests := []struct {
+		path string
+		want time.Time
+		ok   bool
+	}{
+		{"/rec/2024-05-03 10.00.00 Weekly Sync 81234567890/audio_only.m4a", local(2024, 5, 3, 10, 0, 0), true},
+		{"Weekly Sync (2024-05-03 10:00 GMT+3).mp4", time.Date(2024, 5, 3, 10, 0, 0, 0, time.FixedZone("", 3*3600)), true},
+		{"2024-05-03 14-30-15.mkv", local(2024, 5, 3, 14, 30, 15), true},
+		{"20240503_093000.m4a", local(2024, 5, 3, 9, 30, 0), true},
+		{"Recording_20240503-093000.wav", local(2024, 5, 3, 9, 30, 0), true},
+		{"2024-13-03 10.00.00.m4a", time.Time{}, false},
+		{"interview.m4a", time.Time{}, false},
+		{"track_1234567890123.m4a", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := RecordingStartFromName(tt.path)
+		if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+			t.Errorf("RecordingStartFromName(%q) = %v, %v; want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestResolveRecordingStart tests that a given start wins over a .start file, and that over the name
+func TestResolveRecordingStart(t *testing.T) {
+	tmpDir := t.TempDir()
+	input := filepath.Join(tmpDir, "20240503_093000.m4a")
+
+	start, source, ok, err := ResolveRecordingStart("", input)
+	if err != nil || !ok || source != StartFromFileName || start.Hour() != 9 {
+		t.Errorf("Expected the start from the file name, got %v from %q (ok: %v, error: %v)", start, source, ok, err)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "20240503_093000.start"), []byte("2024-05-03 09:31:20\n"), 0644)
+	if start, source, _, _ = ResolveRecordingStart("", input); source != StartFromSidecar || start.Minute() != 31 {
+		t.Errorf("Expected the start from the .start file, got %v from %q", start, source)
+	}
+	if start, source, _, _ = ResolveRecordingStart("2024-05-03 11:00", input); source != StartFromFlag || start.Hour() != 11 {
+		t.Errorf("Expected the start given, got %v from %q", start, source)
+	}
+	if _, _, ok, err = ResolveRecordingStart("tomorrow", input); ok || err == nil {
+		t.Error("Expected an invalid start time to be an error")
+	}
+
+	if _, _, ok, err = ResolveRecordingStart("", filepath.Join(tmpDir, "interview.m4a")); ok || err != nil {
+		t.Errorf("Expected no start for an undated recording (ok: %v, error: %v)", ok, err)
+	}
+}
+
+// TestApplyWallClock tests showing a transcript's times as times of day
+func TestApplyWallClock(t *testing.T) {
+	start := time.Date(2024, 5, 3, 23, 59, 30, 0, time.Local)
+	segments := []Segment{
+		{Start: 0.1, End: 2.5, Text: "שלום"},
+		{Start: 30.5, End: 31.25, Text: "מה שלומך", Speaker: 1},
+	}
+
+	srt, ok := ApplyWallClock(FormatOutput(segments, "srt", DisplayBilingual), start, "srt")
+	if !ok || !strings.Contains(srt, "23:59:30,100 --> 23:59:32,500") || !strings.Contains(srt, "24:00:00,500 --> 24:00:01,250") {
+		t.Errorf("Unexpected SRT timings:\n%s", srt)
+	}
+	vtt, _ := ApplyWallClock(FormatOutput(segments, "vtt", DisplayBilingual), start, "vtt")
+	if !strings.HasPrefix(vtt, "WEBVTT\n") || !strings.Contains(vtt, "23:59:30.100 --> 23:59:32.500") {
+		t.Errorf("Unexpected VTT timings:\n%s", vtt)
+	}
+
+	markdown, _ := ApplyWallClock(FormatMarkdown(segments, "talk.m4a"), start, "markdown")
+	if !strings.HasPrefix(markdown, "*Recording started 2024-05-03 23:59:30*") ||
+		!strings.Contains(markdown, "([24:00:00](talk.m4a#t=30))") {
+		t.Errorf("Unexpected markdown:\n%s", markdown)
+	}
+
+	text, _ := ApplyWallClock("[00:00:05] Speaker 1: שלום\n", time.Date(2024, 5, 3, 10, 0, 0, 0, time.Local), "text")
+	if text != "Recording started 2024-05-03 10:00\n\n[10:00:05] Speaker 1: שלום\n" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	json := FormatOutput(segments, "json", DisplayBilingual)
+	if got, ok := ApplyWallClock(json, start, "json"); ok || got != json {
+		t.Error("Expected JSON to keep its offsets")
+	}
+}
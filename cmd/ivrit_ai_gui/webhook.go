@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Webhook events
+const (
+	WebhookJobCompleted = "job.completed"
+	WebhookJobFailed    = "job.failed"
+)
+
+// Webhook delivery: transient failures (network errors, 5xx) are retried with a growing delay
+var (
+	webhookAttempts   = 3
+	webhookRetryDelay = 2 * time.Second
+	webhookTimeout    = 30 * time.Second
+)
+
+// WebhookPayload is the JSON body POSTed to the webhook URL when a job completes or fails
+type WebhookPayload struct {
+	Event      string    `json:"event"` // WebhookJobCompleted or WebhookJobFailed
+	JobID      string    `json:"jobId"`
+	Input      string    `json:"input"`
+	Model      string    `json:"model"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Error      string    `json:"error,omitempty"`
+	OutputPath string    `json:"outputPath,omitempty"` // Where the transcript was saved (CLI jobs)
+	Segments   []Segment `json:"segments,omitempty"`   // The transcript itself (completed jobs)
+}
+
+// NewWebhookPayload starts describing a job that begins now
+func NewWebhookPayload(input, model string) WebhookPayload {
+	return WebhookPayload{
+		JobID:     newJobID(),
+		Input:     input,
+		Model:     model,
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+// Finish records the job's outcome
+func (p *WebhookPayload) Finish(segments []Segment, err error) {
+	p.FinishedAt = time.Now().UTC()
+	if err != nil {
+		p.Event = WebhookJobFailed
+		p.Error = err.Error()
+		p.Segments = nil
+		return
+	}
+	p.Event = WebhookJobCompleted
+	p.Segments = segments
+}
+
+// SendWebhook POSTs the payload to url, retrying transient failures
+func SendWebhook(url string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %v", err)
+	}
+
+	client := httpClient(webhookTimeout)
+	delay := webhookRetryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(client, url, payload.Event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= webhookAttempts {
+			return fmt.Errorf("webhook %s failed: %v", url, err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// notifyWebhook delivers a job notification, logging rather than returning failures
+// so a broken webhook never fails the job itself
+func notifyWebhook(url string, payload WebhookPayload) {
+	if err := SendWebhook(url, payload); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// postWebhook makes one delivery attempt and reports whether a failure is worth retrying
+func postWebhook(client *http.Client, url, event string, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ivrit-ai/"+appVersion)
+	req.Header.Set("X-Ivrit-Event", event)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// newJobID returns a random identifier for a job
+func newJobID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
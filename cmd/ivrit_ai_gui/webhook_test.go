@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestSendWebhook tests that a completed job is delivered with its transcript
+func TestSendWebhook(t *testing.T) {
+	var received WebhookPayload
+	var event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get("X-Ivrit-Event")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	job := NewWebhookPayload("meeting.m4a", "turbo")
+	job.Finish([]Segment{{Start: 0, End: 2, Text: "שלום"}}, nil)
+
+	if err := SendWebhook(server.URL, job); err != nil {
+		t.Fatalf("SendWebhook() error: %v", err)
+	}
+	if event != WebhookJobCompleted || received.Event != WebhookJobCompleted {
+		t.Errorf("Expected %s event, got header %q and payload %q", WebhookJobCompleted, event, received.Event)
+	}
+	if received.JobID == "" || received.Input != "meeting.m4a" || received.Model != "turbo" {
+		t.Errorf("Job metadata not delivered, got %+v", received)
+	}
+	if len(received.Segments) != 1 || received.Segments[0].Text != "שלום" {
+		t.Errorf("Transcript not delivered, got %+v", received.Segments)
+	}
+}
+
+// TestWebhookFailedJob tests that a failed job reports its error without a transcript
+func TestWebhookFailedJob(t *testing.T) {
+	job := NewWebhookPayload("meeting.m4a", "turbo")
+	job.Finish([]Segment{{Text: "partial"}}, errors.New("ffmpeg failed"))
+
+	if job.Event != WebhookJobFailed || job.Error != "ffmpeg failed" || job.Segments != nil {
+		t.Errorf("Unexpected failed job payload: %+v", job)
+	}
+	if job.FinishedAt.Before(job.StartedAt) {
+		t.Errorf("FinishedAt %v is before StartedAt %v", job.FinishedAt, job.StartedAt)
+	}
+}
+
+// TestSendWebhookRetries tests that server errors are retried and client errors are not
+func TestSendWebhookRetries(t *testing.T) {
+	defer func(delay time.Duration) { webhookRetryDelay = delay }(webhookRetryDelay)
+	webhookRetryDelay = time.Millisecond
+
+	var calls atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+
+	if err := SendWebhook(flaky.URL, NewWebhookPayload("a.wav", "base")); err != nil {
+		t.Errorf("Expected delivery after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls.Load())
+	}
+
+	calls.Store(0)
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	if err := SendWebhook(rejecting.URL, NewWebhookPayload("a.wav", "base")); err == nil {
+		t.Error("Expected error for rejected webhook")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Client errors should not be retried, got %d attempts", calls.Load())
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WhisperCapabilities lists what the whisper.cpp library the app runs with supports.
+// A shared library can be older or newer than the header the app was compiled
+// against; features it lacks are turned off instead of failing inside cgo.
+type WhisperCapabilities struct {
+	Version         string   // Library version, e.g. "1.7.4" ("" = unknown)
+	Backends        []string // Accelerators it was built with, e.g. "Metal", "CUDA"
+	TinyDiarize     bool     // Speaker turn detection (whisper_full_get_segment_speaker_turn_next)
+	TokenTimestamps bool     // Per-token data for the token dump (whisper_full_get_token_data)
+	AbortCallback   bool     // Stopping a decode midway (whisper_full_params.abort_callback)
+	NoSpeechProb    bool     // Each segment's probability of having no speech (whisper_full_get_segment_no_speech_prob)
+}
+
+// whisperFeature is an optional whisper.cpp feature: the release that introduced
+// it, and the function it needs when there is one to look for
+type whisperFeature struct {
+	name       string
+	minVersion string
+	symbol     string
+}
+
+var (
+	featureTinyDiarize     = whisperFeature{"tinydiarize speaker turns", "1.4.0", "whisper_full_get_segment_speaker_turn_next"}
+	featureTokenTimestamps = whisperFeature{"token timestamps", "1.1.0", "whisper_full_get_token_data"}
+	featureAbortCallback   = whisperFeature{"abort callback", "1.5.0", ""}
+	featureNoSpeechProb    = whisperFeature{"no-speech probability", "1.7.2", "whisper_full_get_segment_no_speech_prob"}
+)
+
+// Backends as whisper_print_system_info names them, with their display names
+var whisperBackends = []struct{ key, name string }{
+	{"METAL", "Metal"},
+	{"COREML", "Core ML"},
+	{"CUDA", "CUDA"},
+	{"VULKAN", "Vulkan"},
+	{"BLAS", "BLAS"},
+	{"OPENVINO", "OpenVINO"},
+}
+
+var (
+	whisperCaps     WhisperCapabilities
+	whisperCapsOnce sync.Once
+)
+
+// WhisperCaps returns the linked library's capabilities, detected on first use
+func WhisperCaps() WhisperCapabilities {
+	whisperCapsOnce.Do(func() {
+		version := whisperLibraryVersion()
+		if version == "" && whisperVersion != "unknown" {
+			version = whisperVersion // Static builds stamp the vendored release
+		}
+		whisperCaps = detectWhisperCapabilities(version, WhisperSystemInfo(), whisperHasSymbol)
+	})
+	return whisperCaps
+}
+
+// warnMissingWhisperFeatures tells the user which features are off because the
+// linked library lacks them
+func warnMissingWhisperFeatures() {
+	caps := WhisperCaps()
+	if missing := caps.Missing(); len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s doesn't support %s; these features are turned off\n", caps, strings.Join(missing, ", "))
+	}
+}
+
+// detectWhisperCapabilities works the features out from the library's version and
+// the functions it exports. hasSymbol reports ok=false when symbols can't be looked
+// up (static builds); then, like with an unknown version, a feature is assumed present.
+func detectWhisperCapabilities(version, systemInfo string, hasSymbol func(name string) (found, ok bool)) WhisperCapabilities {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	supports := func(feature whisperFeature) bool {
+		if version != "" && !versionAtLeast(version, feature.minVersion) {
+			return false
+		}
+		if feature.symbol != "" {
+			if found, ok := hasSymbol(feature.symbol); ok && !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	caps := WhisperCapabilities{
+		Version:         version,
+		TinyDiarize:     supports(featureTinyDiarize),
+		TokenTimestamps: supports(featureTokenTimestamps),
+		AbortCallback:   supports(featureAbortCallback),
+		NoSpeechProb:    supports(featureNoSpeechProb),
+	}
+	for _, backend := range whisperBackends {
+		if strings.Contains(systemInfo, backend.key+" = 1") {
+			caps.Backends = append(caps.Backends, backend.name)
+		}
+	}
+	return caps
+}
+
+// Missing lists the features the library lacks, for warnings
+func (c WhisperCapabilities) Missing() []string {
+	var missing []string
+	for _, f := range []struct {
+		feature   whisperFeature
+		supported bool
+	}{
+		{featureTinyDiarize, c.TinyDiarize},
+		{featureTokenTimestamps, c.TokenTimestamps},
+		{featureAbortCallback, c.AbortCallback},
+		{featureNoSpeechProb, c.NoSpeechProb},
+	} {
+		if !f.supported {
+			missing = append(missing, f.feature.name)
+		}
+	}
+	return missing
+}
+
+// String describes the library, e.g. "whisper.cpp 1.7.4 (Metal, BLAS)"
+func (c WhisperCapabilities) String() string {
+	description := "whisper.cpp"
+	if c.Version != "" {
+		description += " " + c.Version
+	} else {
+		description += ", version unknown"
+	}
+	if len(c.Backends) > 0 {
+		description += fmt.Sprintf(" (%s)", strings.Join(c.Backends, ", "))
+	}
+	return description
+}
+
+// versionAtLeast compares dotted versions such as "1.7.4"; trailing parts such as
+// "-14-gabc123" from git describe are ignored. Unparseable versions count as recent.
+func versionAtLeast(version, minimum string) bool {
+	have, ok := parseVersion(version)
+	if !ok {
+		return true
+	}
+	want, _ := parseVersion(minimum)
+	for i := range have {
+		if have[i] != want[i] {
+			return have[i] > want[i]
+		}
+	}
+	return true
+}
+
+// parseVersion reads major.minor.patch from the start of a version string
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(version, "v")
+	if end := strings.IndexFunc(version, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); end >= 0 {
+		version = version[:end]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) < 2 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
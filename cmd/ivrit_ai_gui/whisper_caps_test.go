@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectWhisperCapabilities(t *testing.T) {
+	allSymbols := func(string) (bool, bool) { return true, true }
+	noLookup := func(string) (bool, bool) { return false, false }
+
+	caps := detectWhisperCapabilities("1.7.4", "WHISPER : COREML = 1 | OPENVINO = 0 | Metal : EMBED_LIBRARY = 1 | METAL = 1 | BLAS = 1 |", allSymbols)
+	if !caps.TinyDiarize || !caps.TokenTimestamps || !caps.AbortCallback || !caps.NoSpeechProb {
+		t.Errorf("1.7.4 should support everything: %+v", caps)
+	}
+	if want := []string{"Metal", "Core ML", "BLAS"}; !reflect.DeepEqual(caps.Backends, want) {
+		t.Errorf("Backends = %v, want %v", caps.Backends, want)
+	}
+	if got := caps.String(); got != "whisper.cpp 1.7.4 (Metal, Core ML, BLAS)" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// An old library: features newer than it are off
+	caps = detectWhisperCapabilities("v1.4.2", "", allSymbols)
+	if !caps.TinyDiarize || caps.AbortCallback {
+		t.Errorf("1.4.2: %+v", caps)
+	}
+	if got := caps.Missing(); !reflect.DeepEqual(got, []string{"abort callback", "no-speech probability"}) {
+		t.Errorf("Missing() = %v", got)
+	}
+
+	// A library without a function the feature calls
+	caps = detectWhisperCapabilities("", "", func(name string) (bool, bool) {
+		return name != "whisper_full_get_segment_speaker_turn_next", true
+	})
+	if caps.TinyDiarize || !caps.TokenTimestamps {
+		t.Errorf("missing speaker turn function: %+v", caps)
+	}
+	if got := caps.String(); got != "whisper.cpp, version unknown" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Nothing known (static build stamped with a commit hash): assume everything works
+	caps = detectWhisperCapabilities("abc1234", "", noLookup)
+	if len(caps.Missing()) != 0 {
+		t.Errorf("unknown library reported missing %v", caps.Missing())
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version, minimum string
+		want             bool
+	}{
+		{"1.7.4", "1.5.0", true},
+		{"1.5.0", "1.5.0", true},
+		{"1.4.9", "1.5.0", false},
+		{"1.10", "1.5.0", true},
+		{"0.9.0", "1.1.0", false},
+		{"v1.7.4-14-gabc123", "1.7.4", true},
+		{"abc1234", "1.5.0", true},
+	}
+	for _, tt := range tests {
+		if got := versionAtLeast(tt.version, tt.minimum); got != tt.want {
+			t.Errorf("versionAtLeast(%q, %q) = %v, want %v", tt.version, tt.minimum, got, tt.want)
+		}
+	}
+}
@@ -5,35 +5,65 @@ package main
 // Supports multiple installation methods and platforms
 
 // Note: whisper.cpp does not provide pkg-config files
-// Use platform-specific paths below instead
+// Use platform-specific paths below instead, or build with the static tag to
+// compile against the vendored whisper.cpp (whisper_static.go)
 
 // macOS with Homebrew (both Intel and Apple Silicon)
-#cgo darwin CFLAGS: -I/opt/homebrew/include -I/usr/local/include
-#cgo darwin LDFLAGS: -L/opt/homebrew/lib -L/usr/local/lib -lwhisper -lggml -lggml-base -lm -lpthread
+#cgo darwin,!static CFLAGS: -I/opt/homebrew/include -I/usr/local/include
+#cgo darwin,!static LDFLAGS: -L/opt/homebrew/lib -L/usr/local/lib -lwhisper -lggml -lggml-base -lm -lpthread
 
 // Linux (standard paths)
-#cgo linux CFLAGS: -I/usr/include -I/usr/local/include
-#cgo linux LDFLAGS: -L/usr/lib -L/usr/local/lib -lwhisper -lggml -lggml-base -lm -lpthread -ldl
+#cgo linux,!static CFLAGS: -I/usr/include -I/usr/local/include
+#cgo linux,!static LDFLAGS: -L/usr/lib -L/usr/local/lib -lwhisper -lggml -lggml-base -lm -lpthread -ldl
 
-// Windows (assuming whisper-cpp installed in standard location)
-#cgo windows CFLAGS: -IC:/msys64/mingw64/include
-#cgo windows LDFLAGS: -LC:/msys64/mingw64/lib -lwhisper -lggml -lggml-base
+// Windows: include and library folders come from CGO_CFLAGS/CGO_LDFLAGS (set by
+// scripts/build-windows.ps1). With the whisper_dll tag nothing is linked; whisper.dll
+// is loaded at startup instead (whisper_dll_windows.c).
+#cgo windows,!whisper_dll,!static LDFLAGS: -lwhisper -lggml -lggml-base
 
 #include <stdlib.h>
 #include <string.h>
 #include "whisper.h"
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <dlfcn.h>
+#endif
+
+// whisper_lookup finds a function exported by the loaded whisper.cpp library, or
+// NULL when the library lacks it or can't be searched (statically linked)
+static void * whisper_lookup(const char * name) {
+#ifdef _WIN32
+	HMODULE lib = GetModuleHandleA("libwhisper.dll");
+	if (lib == NULL) {
+		lib = GetModuleHandleA("whisper.dll");
+	}
+	return lib == NULL ? NULL : (void *)GetProcAddress(lib, name);
+#else
+	return dlsym(dlopen(NULL, RTLD_LAZY), name);
+#endif
+}
+
+// whisper_call_version calls whisper_version, which libraries before 1.7.5 lack
+static const char * whisper_call_version(void * fn) {
+	return ((const char * (*)(void))fn)();
+}
 
 // Forward declare callback wrappers
 extern void whisper_new_segment_callback_go(struct whisper_context * ctx, struct whisper_state * state, int n_new, void * user_data);
 extern void whisper_progress_callback_go(struct whisper_context * ctx, struct whisper_state * state, int progress, void * user_data);
+extern bool whisper_abort_callback_go(void * user_data);
 */
 import "C"
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/cgo"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -45,6 +75,8 @@ type transcriptionCallbacks struct {
 	segmentCallback  func(Segment)
 	ctx              *C.struct_whisper_context
 	currentSpeaker   int    // Track current speaker for real-time callbacks
+	tinyDiarize      bool   // Whether the library reports speaker turns
+	noSpeechProb     bool   // Whether the library reports each segment's no-speech probability
 	progressPercent  *int32 // Atomic progress percentage (0-100)
 }
 
@@ -64,7 +96,7 @@ func whisper_new_segment_callback_go(ctx *C.struct_whisper_context, state *C.str
 
 	// Check for speaker turn (if this isn't the first segment)
 	speakerTurn := false
-	if lastIdx > 0 {
+	if lastIdx > 0 && callbacks.tinyDiarize {
 		speakerTurn = bool(C.whisper_full_get_segment_speaker_turn_next(ctx, C.int(lastIdx-1)))
 	}
 
@@ -72,6 +104,10 @@ func whisper_new_segment_callback_go(ctx *C.struct_whisper_context, state *C.str
 	t1 := C.whisper_full_get_segment_t1(ctx, C.int(lastIdx))
 	textPtr := C.whisper_full_get_segment_text(ctx, C.int(lastIdx))
 	text := C.GoString(textPtr)
+	noSpeech := 0.0
+	if callbacks.noSpeechProb {
+		noSpeech = float64(C.whisper_full_get_segment_no_speech_prob(ctx, C.int(lastIdx)))
+	}
 
 	if callbacks.segmentCallback == nil {
 		return
@@ -81,12 +117,12 @@ func whisper_new_segment_callback_go(ctx *C.struct_whisper_context, state *C.str
 		callbacks.currentSpeaker++
 	}
 
-	segment := Segment{
+	segment := LabelEvent(Segment{
 		Start:   float64(t0) / 100.0,
 		End:     float64(t1) / 100.0,
 		Text:    text,
 		Speaker: callbacks.currentSpeaker,
-	}
+	}, noSpeech)
 
 	// Call callback
 	callbacks.segmentCallback(segment)
@@ -106,6 +142,14 @@ func whisper_progress_callback_go(ctx *C.struct_whisper_context, state *C.struct
 	}
 }
 
+//export whisper_abort_callback_go
+func whisper_abort_callback_go(userData unsafe.Pointer) C.bool {
+	// Atomic reads only, like the progress callback: whisper stops when the app shuts
+	// down or the engine's transcription is aborted
+	aborted := cgo.Handle(uintptr(userData)).Value().(*atomic.Bool)
+	return C.bool(ShuttingDown() || aborted.Load())
+}
+
 // cachedModel wraps a whisper context with a mutex to ensure thread-safe access
 type cachedModel struct {
 	ctx   *C.struct_whisper_context
@@ -116,12 +160,17 @@ type cachedModel struct {
 var (
 	modelCache      = make(map[string]*cachedModel)
 	modelCacheMutex sync.RWMutex
+	modelLoadMutex  sync.Mutex // Serializes model loading so a preload and a transcription don't load the same model twice
 )
 
 // Transcription result cache to avoid re-transcribing the same files
 type transcriptionCacheKey struct {
-	audioPath string
-	modelID   string
+	audio      string        // AudioFingerprint of the file, so a renamed copy is served from the cache too
+	modelID    string
+	withTokens bool          // Token dumps need per-token data that plain results don't carry
+	timeRange  TimeRange     // Partial transcriptions are cached separately from full ones
+	decode     DecodeOptions // Different decoding settings give different results
+	trimSilence bool         // Trimmed audio can decode slightly differently
 }
 
 var (
@@ -131,13 +180,31 @@ var (
 
 // WhisperCGOEngine implements TranscriptionEngine using direct cgo bindings
 type WhisperCGOEngine struct {
-	model     *cachedModel // Reference to cached model (includes mutex)
-	modelPath string
-	fromCache bool // Whether this engine is using a cached model
+	model      *cachedModel // Reference to cached model (includes mutex)
+	modelPath  string
+	cpuOnly    bool // Loaded without GPU acceleration
+	fromCache  bool // Whether this engine is using a cached model
+	dumpTokens bool          // Collect per-token text, timestamps and probabilities (debug output)
+	timeRange  TimeRange     // Portion of the audio to transcribe (zero value = whole file)
+	lastCached bool          // Whether the last Transcribe call was served from the transcription cache
+	parallel   int           // Split long audio at silences into this many chunks run in parallel (<= 1 = off)
+	decode     DecodeOptions // Advanced decoding parameters (zero value = whisper defaults)
+	trimSilence bool         // Skip long silence at the start and end of the audio
+	aborted     atomic.Bool  // Set by Abort; read by whisper's abort callback
 }
 
 // NewWhisperCGOEngine creates a new whisper engine using direct cgo with model caching
 func NewWhisperCGOEngine(modelPath string) (*WhisperCGOEngine, error) {
+	return newWhisperCGOEngine(modelPath, false)
+}
+
+// NewWhisperCGOEngineCPU creates a whisper engine that runs on the CPU only, for when
+// the GPU backend fails or is out of memory
+func NewWhisperCGOEngineCPU(modelPath string) (*WhisperCGOEngine, error) {
+	return newWhisperCGOEngine(modelPath, true)
+}
+
+func newWhisperCGOEngine(modelPath string, cpuOnly bool) (*WhisperCGOEngine, error) {
 	if modelPath == "" {
 		return nil, fmt.Errorf("model path required")
 	}
@@ -146,10 +213,19 @@ func NewWhisperCGOEngine(modelPath string) (*WhisperCGOEngine, error) {
 	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("model file not found: %s", modelPath)
 	}
+	if err := loadWhisperLibrary(); err != nil {
+		return nil, err
+	}
+
+	// The CPU-only context is cached separately from the GPU one
+	cacheKey := modelPath
+	if cpuOnly {
+		cacheKey += "#cpu"
+	}
 
 	// Check cache first
 	modelCacheMutex.RLock()
-	cachedMdl, exists := modelCache[modelPath]
+	cachedMdl, exists := modelCache[cacheKey]
 	modelCacheMutex.RUnlock()
 
 	if exists && cachedMdl != nil {
@@ -157,6 +233,24 @@ func NewWhisperCGOEngine(modelPath string) (*WhisperCGOEngine, error) {
 		return &WhisperCGOEngine{
 			model:     cachedMdl,
 			modelPath: modelPath,
+			cpuOnly:   cpuOnly,
+			fromCache: true,
+		}, nil
+	}
+
+	modelLoadMutex.Lock()
+	defer modelLoadMutex.Unlock()
+
+	// Check cache again - another goroutine (e.g. a background preload) may have loaded it meanwhile
+	modelCacheMutex.RLock()
+	cachedMdl, exists = modelCache[cacheKey]
+	modelCacheMutex.RUnlock()
+
+	if exists && cachedMdl != nil {
+		return &WhisperCGOEngine{
+			model:     cachedMdl,
+			modelPath: modelPath,
+			cpuOnly:   cpuOnly,
 			fromCache: true,
 		}, nil
 	}
@@ -168,6 +262,7 @@ func NewWhisperCGOEngine(modelPath string) (*WhisperCGOEngine, error) {
 	// Load model with default params
 	params := C.whisper_context_default_params()
 	// Note: GPU support is automatically used if available in whisper.cpp build
+	params.use_gpu = C.bool(!cpuOnly)
 
 	ctx := C.whisper_init_from_file_with_params(cModelPath, params)
 	if ctx == nil {
@@ -181,21 +276,165 @@ func NewWhisperCGOEngine(modelPath string) (*WhisperCGOEngine, error) {
 
 	// Store in cache
 	modelCacheMutex.Lock()
-	modelCache[modelPath] = cachedMdl
+	modelCache[cacheKey] = cachedMdl
 	modelCacheMutex.Unlock()
 
+	// The model is owned by the cache from now on, so Close must not free it
+	// (otherwise a preloaded model would be freed right after loading)
 	return &WhisperCGOEngine{
 		model:     cachedMdl,
 		modelPath: modelPath,
-		fromCache: false,
+		cpuOnly:   cpuOnly,
+		fromCache: true,
 	}, nil
 }
 
+// PreloadModel loads an already-downloaded model into the model cache so the
+// first transcription doesn't pay the load time. It never downloads.
+func PreloadModel(modelID string) error {
+	modelPath, err := FindLocalModel(modelID)
+	if err != nil {
+		return err
+	}
+	if err := checkModelFile(modelID, modelPath); err != nil {
+		return err
+	}
+
+	applyCoreMLSetting(modelPath)
+	engine, err := NewWhisperCGOEngine(modelPath)
+	if err != nil {
+		return err
+	}
+	engine.Close()
+
+	return nil
+}
+
+// WhisperSystemInfo returns whisper.cpp's build features, e.g. "... METAL = 1 | COREML = 1 ..."
+func WhisperSystemInfo() string {
+	if loadWhisperLibrary() != nil {
+		return ""
+	}
+	return C.GoString(C.whisper_print_system_info())
+}
+
+// whisperLibraryVersion asks the library for its version, or "" if it predates whisper_version
+func whisperLibraryVersion() string {
+	if loadWhisperLibrary() != nil {
+		return ""
+	}
+	name := C.CString("whisper_version")
+	defer C.free(unsafe.Pointer(name))
+	fn := C.whisper_lookup(name)
+	if fn == nil {
+		return ""
+	}
+	return C.GoString(C.whisper_call_version(fn))
+}
+
+// whisperHasSymbol reports whether the library exports a function. ok is false when
+// its exports can't be searched, as when whisper.cpp is linked statically.
+func whisperHasSymbol(name string) (found, ok bool) {
+	if loadWhisperLibrary() != nil {
+		return false, false
+	}
+	lookup := func(name string) bool {
+		cName := C.CString(name)
+		defer C.free(unsafe.Pointer(cName))
+		return C.whisper_lookup(cName) != nil
+	}
+	// Every library has this one: not finding it means there is nothing to search
+	if !lookup("whisper_init_from_file_with_params") {
+		return false, false
+	}
+	return lookup(name), true
+}
+
 // SupportsModel checks if this engine supports the given model
 func (e *WhisperCGOEngine) SupportsModel(modelID string) bool {
 	return modelID == "large-v3" || modelID == "turbo" || modelID == "base"
 }
 
+// SetTokenDump enables collection of per-token data on each segment
+func (e *WhisperCGOEngine) SetTokenDump(enabled bool) {
+	e.dumpTokens = enabled
+}
+
+// SetTimeRange limits transcription to a portion of the audio
+func (e *WhisperCGOEngine) SetTimeRange(timeRange TimeRange) {
+	e.timeRange = timeRange
+}
+
+// SetParallelChunks enables split-by-silence parallel inference: long audio is cut
+// at pauses into up to n chunks that are transcribed concurrently and stitched in order
+func (e *WhisperCGOEngine) SetParallelChunks(n int) {
+	e.parallel = n
+}
+
+// SetSilenceTrimming skips long silence at the start and end of the audio, shifting
+// timestamps back so they match the original
+func (e *WhisperCGOEngine) SetSilenceTrimming(enabled bool) {
+	e.trimSilence = enabled
+}
+
+// SetDecodeOptions sets advanced decoding parameters (beam size, temperature, initial prompt)
+func (e *WhisperCGOEngine) SetDecodeOptions(decode DecodeOptions) {
+	e.decode = decode
+}
+
+// LastResultCached reports whether the last transcription came from the result cache
+// (so its duration says nothing about inference speed)
+func (e *WhisperCGOEngine) LastResultCached() bool {
+	return e.lastCached
+}
+
+// Abort stops the running transcription at whisper's next abort check, and any later
+// one before it starts. Unlike a shutdown, no partial transcript is saved.
+func (e *WhisperCGOEngine) Abort() {
+	e.aborted.Store(true)
+}
+
+// stopped reports whether whisper was stopped by a shutdown or Abort
+func (e *WhisperCGOEngine) stopped() bool {
+	return ShuttingDown() || e.aborted.Load()
+}
+
+// interrupted returns ErrInterrupted for a stopped transcription, saving the segments
+// so far when the app is shutting down
+func (e *WhisperCGOEngine) interrupted(audioPath string, segments []Segment) error {
+	if !ShuttingDown() {
+		return ErrInterrupted // Aborted: nobody is waiting for the partial transcript
+	}
+	return saveInterrupted(audioPath, segments)
+}
+
+// BenchmarkThreads times one decode of synthetic audio with the given thread count,
+// bypassing the transcription cache (for tuning the thread count)
+func (e *WhisperCGOEngine) BenchmarkThreads(threads int) (time.Duration, error) {
+	if e.model == nil || e.model.ctx == nil {
+		return 0, fmt.Errorf("whisper context not initialized")
+	}
+	samples := benchmarkSamples()
+
+	e.model.mutex.Lock()
+	defer e.model.mutex.Unlock()
+
+	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	params.language = C.CString("he")
+	defer C.free(unsafe.Pointer(params.language))
+	params.n_threads = C.int(threads)
+	params.print_progress = C.bool(false)
+	params.print_special = C.bool(false)
+	params.print_realtime = C.bool(false)
+	params.print_timestamps = C.bool(false)
+
+	start := time.Now()
+	if result := C.whisper_full(e.model.ctx, params, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples))); result != 0 {
+		return 0, fmt.Errorf("whisper_full failed with code %d", result)
+	}
+	return time.Since(start), nil
+}
+
 // Transcribe transcribes audio using native whisper.cpp
 func (e *WhisperCGOEngine) Transcribe(audioPath string, modelID string, cpuThreads int, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
 	return e.TranscribeWithTranslation(audioPath, modelID, cpuThreads, "", progressCallback, segmentCallback)
@@ -207,15 +446,24 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 	if e.model == nil || e.model.ctx == nil {
 		return nil, fmt.Errorf("whisper context not initialized")
 	}
+	e.lastCached = false
+	caps := WhisperCaps()
+	if e.dumpTokens && !caps.TokenTimestamps {
+		return nil, fmt.Errorf("the token dump needs per-token data, which %s doesn't provide; upgrade whisper.cpp", caps)
+	}
+	if e.decode.MaxSegmentChars > 0 && !caps.TokenTimestamps {
+		return nil, fmt.Errorf("splitting segments to a maximum length needs per-token timestamps, which %s doesn't provide; upgrade whisper.cpp", caps)
+	}
 
 	// Check transcription cache first (only for non-translated transcriptions)
 	if translateTo == "" {
-		cacheKey := transcriptionCacheKey{audioPath: audioPath, modelID: modelID}
+		cacheKey := transcriptionCacheKey{audio: audioCacheKey(audioPath), modelID: modelID, withTokens: e.dumpTokens, timeRange: e.timeRange, decode: e.decode, trimSilence: e.trimSilence}
 		transcriptionCacheMutex.RLock()
 		cachedSegments, exists := transcriptionCache[cacheKey]
 		transcriptionCacheMutex.RUnlock()
 
 		if exists {
+			e.lastCached = true
 			if progressCallback != nil {
 				progressCallback("Using cached transcription...")
 			}
@@ -232,6 +480,12 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		}
 	}
 
+	// A shutdown waits for this transcription to stop and save what it has
+	if e.aborted.Load() || !beginTranscription() {
+		return nil, ErrInterrupted
+	}
+	defer endTranscription()
+
 	// Lock model for exclusive access during transcription (whisper_full is not thread-safe)
 	e.model.mutex.Lock()
 	defer e.model.mutex.Unlock()
@@ -240,26 +494,31 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		progressCallback(fmt.Sprintf("Transcribing with native whisper.cpp (model: %s)...", modelID))
 	}
 
-	// Load audio file (we need to convert to 16kHz mono PCM)
-	// For now, use ffmpeg to convert if needed
-	tempWav, err := prepareAudioFile(audioPath, progressCallback)
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare audio: %v", err)
-	}
-	defer os.Remove(tempWav)
-
-	// Read WAV file
-	audioData, sampleRate, err := readWAVFile(tempWav)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read audio: %v", err)
-	}
-
-	if sampleRate != 16000 {
-		return nil, fmt.Errorf("sample rate must be 16000 Hz, got %d", sampleRate)
+	// Recordings of several hours are streamed to whisper in windows instead of being
+	// decoded whole. Parallel chunks are split from the whole recording, so they aren't.
+	stream := e.parallel <= 1 && shouldStreamAudio(audioPath, e.timeRange)
+
+	// Decode the audio to 16kHz mono samples, piped from ffmpeg unless it already is,
+	// or take them from the batch pipeline, which decoded them during the last file
+	var samples []float32
+	trimmed := false
+	if !stream {
+		var prefetchedOK bool
+		if samples, trimmed, prefetchedOK = takePrefetchedAudio(audioPath, e.timeRange); !prefetchedOK {
+			var err error
+			samples, trimmed, err = DecodeAudio(audioPath, e.timeRange, progressCallback)
+			if err != nil {
+				return nil, fmt.Errorf("failed to prepare audio: %v", err)
+			}
+		}
 	}
 
 	// Set up whisper parameters
-	params := C.whisper_full_default_params(C.WHISPER_SAMPLING_GREEDY)
+	strategy := C.enum_whisper_sampling_strategy(C.WHISPER_SAMPLING_GREEDY)
+	if e.decode.BeamSize > 1 {
+		strategy = C.WHISPER_SAMPLING_BEAM_SEARCH
+	}
+	params := C.whisper_full_default_params(strategy)
 	params.language = C.CString("he")
 	defer C.free(unsafe.Pointer(params.language))
 	params.n_threads = C.int(cpuThreads)
@@ -270,7 +529,68 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 	params.print_realtime = C.bool(false)
 	params.print_timestamps = C.bool(true)
 	// Enable tinydiarize for speaker detection
-	params.tdrz_enable = C.bool(true)
+	params.tdrz_enable = C.bool(caps.TinyDiarize)
+	// Per-token timestamps are needed for the debug token dump, and for whisper to
+	// split segments at a maximum length
+	params.token_timestamps = C.bool(e.dumpTokens || e.decode.MaxSegmentChars > 0)
+	// Advanced decoding options
+	if e.decode.BeamSize > 1 {
+		params.beam_search.beam_size = C.int(e.decode.BeamSize)
+	}
+	if e.decode.Temperature > 0 {
+		params.temperature = C.float(e.decode.Temperature)
+	}
+	if e.decode.InitialPrompt != "" {
+		params.initial_prompt = C.CString(e.decode.InitialPrompt)
+		defer C.free(unsafe.Pointer(params.initial_prompt))
+	}
+	// Segment length limits, splitting at word boundaries rather than mid-word
+	if e.decode.MaxSegmentChars > 0 {
+		params.max_len = C.int(whisperMaxLen(e.decode.MaxSegmentChars))
+		params.split_on_word = C.bool(true)
+	}
+	if e.decode.MaxSegmentTokens > 0 {
+		params.max_tokens = C.int(e.decode.MaxSegmentTokens)
+	}
+	// A shutdown or Abort stops whisper at its next check instead of after the whole recording
+	if caps.AbortCallback {
+		abortHandle := cgo.NewHandle(&e.aborted)
+		defer abortHandle.Delete()
+		params.abort_callback = C.ggml_abort_callback(C.whisper_abort_callback_go)
+		h := uintptr(abortHandle)
+		params.abort_callback_user_data = *(*unsafe.Pointer)(unsafe.Pointer(&h))
+	}
+
+	// Time range: ffmpeg already cut converted audio, so its timestamps start at zero
+	// and must be shifted back. Unconverted WAVs are windowed by whisper itself.
+	timeShift := 0.0
+	if trimmed {
+		timeShift = e.timeRange.Start
+	} else if e.timeRange.IsSet() {
+		// Whisper returns nothing for a window past the end, where ffmpeg's cut is empty
+		if inRange, _ := sliceTimeRange(samples, e.timeRange); !stream && len(inRange) == 0 {
+			return nil, fmt.Errorf("no audio to transcribe (is the time range past the end of the file?)")
+		}
+		params.offset_ms = C.int(e.timeRange.Start * 1000)
+		if e.timeRange.End > 0 {
+			params.duration_ms = C.int((e.timeRange.End - e.timeRange.Start) * 1000)
+		}
+	}
+
+	if stream {
+		segments, err := e.transcribeStreamed(params, audioPath, progressCallback, segmentCallback)
+		if err == ErrInterrupted {
+			return nil, e.interrupted(audioPath, segments)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Transcription complete (%d segments)", len(segments)))
+		}
+		e.cacheResult(audioPath, modelID, translateTo, segments)
+		return segments, nil
+	}
 
 	// Set up safe progress tracking using atomic variables
 	// C callback writes to atomic (no allocations), Go goroutine reads and updates UI
@@ -281,6 +601,8 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		callbacks := &transcriptionCallbacks{
 			ctx:             e.model.ctx,
 			progressPercent: &progressPercent,
+			tinyDiarize:     caps.TinyDiarize,
+			noSpeechProb:    caps.NoSpeechProb,
 		}
 		handle = cgo.NewHandle(callbacks)
 
@@ -300,12 +622,57 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		}
 	}()
 
-	// Convert audio to float32 samples
-	samples := make([]float32, len(audioData)/2)
-	for i := 0; i < len(samples); i++ {
-		// Convert int16 to float32 (-1.0 to 1.0)
-		sample := int16(audioData[i*2]) | int16(audioData[i*2+1])<<8
-		samples[i] = float32(sample) / 32768.0
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no audio to transcribe (is the time range past the end of the file?)")
+	}
+
+	// Split-by-silence parallel inference and silence trimming work on the sample
+	// buffer directly, so a time range is applied by slicing instead of offset_ms/duration_ms
+	if (e.parallel > 1 || e.trimSilence) && !trimmed && e.timeRange.IsSet() {
+		samples, timeShift = sliceTimeRange(samples, e.timeRange)
+		params.offset_ms = 0
+		params.duration_ms = 0
+		if len(samples) == 0 {
+			return nil, fmt.Errorf("no audio to transcribe (is the time range past the end of the file?)")
+		}
+	}
+
+	// Skip long silence at the start and end, e.g. of voicemail recordings
+	if e.trimSilence {
+		start, end := TrimSilence(samples)
+		if start > 0 || end < len(samples) {
+			if progressCallback != nil {
+				progressCallback(fmt.Sprintf("Skipping silence: %.0fs at the start, %.0fs at the end",
+					float64(start)/whisperSampleRate, float64(len(samples)-end)/whisperSampleRate))
+			}
+			samples = samples[start:end]
+			timeShift += float64(start) / whisperSampleRate
+		}
+	}
+
+	if e.parallel > 1 {
+		if chunks := SplitAtSilences(samples, e.parallel); len(chunks) > 1 {
+			if progressCallback != nil {
+				progressCallback(fmt.Sprintf("Transcribing %d chunks in parallel...", len(chunks)))
+			}
+			segments, err := e.transcribeChunks(params, samples, chunks, cpuThreads, timeShift, progressCallback)
+			if err == ErrInterrupted {
+				return nil, e.interrupted(audioPath, segments)
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, segment := range segments {
+				if segmentCallback != nil {
+					segmentCallback(segment)
+				}
+			}
+			if progressCallback != nil {
+				progressCallback(fmt.Sprintf("Transcription complete (%d segments)", len(segments)))
+			}
+			e.cacheResult(audioPath, modelID, translateTo, segments)
+			return segments, nil
+		}
 	}
 
 	// Run inference
@@ -350,7 +717,9 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		time.Sleep(100 * time.Millisecond) // Give goroutine time to exit
 	}
 
-	if result != 0 {
+	// An aborted run still holds the segments decoded before the shutdown
+	interrupted := result != 0 && e.stopped()
+	if result != 0 && !interrupted {
 		return nil, fmt.Errorf("whisper_full failed with code %d", result)
 	}
 
@@ -366,7 +735,7 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 	currentSpeaker := 0
 	for i := 0; i < nSegments; i++ {
 		// Check if this segment has a speaker turn (next segment switches speaker)
-		if i > 0 {
+		if i > 0 && caps.TinyDiarize {
 			speakerTurn := C.whisper_full_get_segment_speaker_turn_next(e.model.ctx, C.int(i-1))
 			if speakerTurn {
 				currentSpeaker++
@@ -385,11 +754,23 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		runes := []rune(text)
 		text = string(runes)
 
-		segment := Segment{
-			Start:   float64(t0) / 100.0, // Convert from centiseconds to seconds
-			End:     float64(t1) / 100.0,
+		// Sounds whisper annotates, and silence it filled with words, become events
+		noSpeech := 0.0
+		if caps.NoSpeechProb {
+			noSpeech = float64(C.whisper_full_get_segment_no_speech_prob(e.model.ctx, C.int(i)))
+		}
+		segment := LabelEvent(Segment{
+			Start:   float64(t0)/100.0 + timeShift, // Convert from centiseconds to seconds
+			End:     float64(t1)/100.0 + timeShift,
 			Text:    text,          // Store as UTF-8 string
 			Speaker: currentSpeaker, // Speaker ID from tinydiarize
+		}, noSpeech)
+		if e.dumpTokens {
+			segment.Tokens = extractTokens(e.model.ctx, i)
+			for j := range segment.Tokens {
+				segment.Tokens[j].Start += timeShift
+				segment.Tokens[j].End += timeShift
+			}
 		}
 		segments = append(segments, segment)
 
@@ -399,21 +780,293 @@ func (e *WhisperCGOEngine) TranscribeWithTranslation(audioPath string, modelID s
 		}
 	}
 
+	if interrupted {
+		return nil, e.interrupted(audioPath, segments)
+	}
+
 	if progressCallback != nil {
 		progressCallback(fmt.Sprintf("Transcription complete (%d segments)", len(segments)))
 	}
 
-	// Cache the transcription result (only for non-translated transcriptions)
-	if translateTo == "" {
-		cacheKey := transcriptionCacheKey{audioPath: audioPath, modelID: modelID}
-		transcriptionCacheMutex.Lock()
-		transcriptionCache[cacheKey] = segments
-		transcriptionCacheMutex.Unlock()
+	e.cacheResult(audioPath, modelID, translateTo, segments)
+
+	return segments, nil
+}
+
+// cacheResult caches a transcription result (only for non-translated transcriptions)
+func (e *WhisperCGOEngine) cacheResult(audioPath string, modelID string, translateTo string, segments []Segment) {
+	if translateTo != "" {
+		return
+	}
+	cacheKey := transcriptionCacheKey{audio: audioCacheKey(audioPath), modelID: modelID, withTokens: e.dumpTokens, timeRange: e.timeRange, decode: e.decode, trimSilence: e.trimSilence}
+	transcriptionCacheMutex.Lock()
+	transcriptionCache[cacheKey] = segments
+	transcriptionCacheMutex.Unlock()
+}
+
+// transcribeChunks runs each chunk through its own whisper state concurrently and
+// stitches the results back in order. The model weights are shared between states,
+// but each state holds its own decoder buffers, so memory grows with the chunk count.
+// After a shutdown it returns the segments up to the first stopped chunk with
+// ErrInterrupted. The caller must hold the model mutex.
+func (e *WhisperCGOEngine) transcribeChunks(params C.struct_whisper_full_params, samples []float32, chunks []AudioChunk, cpuThreads int, timeShift float64, progressCallback func(string)) ([]Segment, error) {
+	// Share the CPU threads between the chunks
+	threadsPerChunk := cpuThreads / len(chunks)
+	if threadsPerChunk < 1 {
+		threadsPerChunk = 1
+	}
+	params.n_threads = C.int(threadsPerChunk)
+
+	progress := make([]int32, len(chunks)) // Per-chunk percentage, written atomically by C callbacks
+	results := make([][]Segment, len(chunks))
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		chunkParams := params
+		if progressCallback != nil {
+			handle := cgo.NewHandle(&transcriptionCallbacks{
+				ctx:             e.model.ctx,
+				progressPercent: &progress[i],
+			})
+			defer handle.Delete()
+
+			chunkParams.progress_callback = C.whisper_progress_callback(C.whisper_progress_callback_go)
+			h := uintptr(handle)
+			chunkParams.progress_callback_user_data = *(*unsafe.Pointer)(unsafe.Pointer(&h))
+		}
+
+		wg.Add(1)
+		go func(i int, chunk AudioChunk, chunkParams C.struct_whisper_full_params) {
+			defer wg.Done()
+
+			state := C.whisper_init_state(e.model.ctx)
+			if state == nil {
+				errs[i] = fmt.Errorf("failed to allocate whisper state for chunk %d", i+1)
+				return
+			}
+			defer C.whisper_free_state(state)
+
+			chunkSamples := samples[chunk.Start:chunk.End]
+			result := C.whisper_full_with_state(e.model.ctx, state, chunkParams, (*C.float)(unsafe.Pointer(&chunkSamples[0])), C.int(len(chunkSamples)))
+			if result != 0 && !e.stopped() {
+				errs[i] = fmt.Errorf("whisper_full failed on chunk %d with code %d", i+1, result)
+				return
+			}
+			if result != 0 {
+				errs[i] = ErrInterrupted // Keeps the segments decoded before the shutdown
+			}
+
+			offset := timeShift + float64(chunk.Start)/whisperSampleRate
+			results[i] = e.segmentsFromState(state, offset)
+		}(i, chunk, chunkParams)
+	}
+
+	// Report the average progress over all chunks (only reads atomics, no C calls)
+	done := make(chan bool)
+	if progressCallback != nil {
+		go func() {
+			ticker := time.NewTicker(200 * time.Millisecond)
+			defer ticker.Stop()
+			lastProgress := int32(-1)
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					var total int32
+					for i := range progress {
+						total += atomic.LoadInt32(&progress[i])
+					}
+					currentProgress := total / int32(len(progress))
+					if currentProgress != lastProgress && currentProgress > 0 && currentProgress <= 100 {
+						lastProgress = currentProgress
+						progressCallback(fmt.Sprintf("Transcribing... %d%%", currentProgress))
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+
+	// Stitch chunks in order; speaker numbering continues across chunk boundaries.
+	// After a shutdown only the segments up to the first interrupted chunk are kept.
+	segments := []Segment{}
+	speakerOffset := 0
+	for i := range results {
+		if errs[i] != nil && errs[i] != ErrInterrupted {
+			return nil, errs[i]
+		}
+		for _, segment := range results[i] {
+			segment.Speaker += speakerOffset
+			segments = append(segments, segment)
+		}
+		if n := len(segments); n > 0 {
+			speakerOffset = segments[n-1].Speaker
+		}
+		if errs[i] == ErrInterrupted {
+			return segments, ErrInterrupted
+		}
 	}
 
 	return segments, nil
 }
 
+// transcribeStreamed transcribes a very long recording one window at a time, piping
+// it from ffmpeg into a single reused buffer so peak memory stays the same however
+// long the recording is. Windows end at pauses, and silence trimming is not applied.
+// After a shutdown it returns the segments so far with ErrInterrupted.
+// The caller must hold the model mutex.
+func (e *WhisperCGOEngine) transcribeStreamed(params C.struct_whisper_full_params, audioPath string, progressCallback func(string), segmentCallback func(Segment)) ([]Segment, error) {
+	total := expectedSamples(audioPath, e.timeRange)
+	if progressCallback != nil {
+		progressCallback(fmt.Sprintf("Long recording: streaming it to whisper in %d-minute windows", streamWindowSecs/60))
+	}
+
+	// ffmpeg applies the time range, so whisper always sees the window from its start
+	params.offset_ms = 0
+	params.duration_ms = 0
+
+	cmd, pcm, err := startPCMDecode(audioPath, e.timeRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare audio: %v", err)
+	}
+	defer func() {
+		// Drain ffmpeg's output so it exits when transcription stops early
+		io.Copy(io.Discard, pcm)
+		cmd.Wait()
+	}()
+
+	state := C.whisper_init_state(e.model.ctx)
+	if state == nil {
+		return nil, fmt.Errorf("failed to allocate whisper state")
+	}
+	defer C.whisper_free_state(state)
+
+	segments := []Segment{}
+	speakerOffset := 0
+	decoded := false
+	windows := newSampleWindows(pcm, streamWindowSecs*whisperSampleRate)
+	for {
+		// Stop between windows on a shutdown or Abort, even when whisper can't be aborted
+		if e.stopped() {
+			return segments, ErrInterrupted
+		}
+		samples, start, err := windows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decoded audio: %v", err)
+		}
+		decoded = true
+
+		if progressCallback != nil && total > 0 {
+			percent := start * 100 / total
+			if percent > 99 {
+				percent = 99
+			}
+			progressCallback(fmt.Sprintf("Transcribing... %d%%", percent))
+		}
+
+		result := C.whisper_full_with_state(e.model.ctx, state, params, (*C.float)(unsafe.Pointer(&samples[0])), C.int(len(samples)))
+		if result != 0 && !e.stopped() {
+			return nil, fmt.Errorf("whisper_full failed on the window at %.0fs with code %d", float64(start)/whisperSampleRate, result)
+		}
+
+		// Speaker numbering continues across window boundaries
+		offset := e.timeRange.Start + float64(start)/whisperSampleRate
+		for _, segment := range e.segmentsFromState(state, offset) {
+			segment.Speaker += speakerOffset
+			segments = append(segments, segment)
+			if segmentCallback != nil {
+				segmentCallback(segment)
+			}
+		}
+		if n := len(segments); n > 0 {
+			speakerOffset = segments[n-1].Speaker
+		}
+	}
+
+	if !decoded {
+		return nil, fmt.Errorf("no audio to transcribe (is the time range past the end of the file?)")
+	}
+	return segments, nil
+}
+
+// segmentsFromState extracts the segments of a parallel chunk from its whisper state,
+// shifting timestamps by the chunk's position in the audio
+func (e *WhisperCGOEngine) segmentsFromState(state *C.struct_whisper_state, offset float64) []Segment {
+	segments := []Segment{}
+	nSegments := int(C.whisper_full_n_segments_from_state(state))
+	caps := WhisperCaps()
+
+	currentSpeaker := 0
+	for i := 0; i < nSegments; i++ {
+		if i > 0 && caps.TinyDiarize && bool(C.whisper_full_get_segment_speaker_turn_next_from_state(state, C.int(i-1))) {
+			currentSpeaker++
+		}
+
+		t0 := C.whisper_full_get_segment_t0_from_state(state, C.int(i))
+		t1 := C.whisper_full_get_segment_t1_from_state(state, C.int(i))
+		text := string([]rune(C.GoString(C.whisper_full_get_segment_text_from_state(state, C.int(i)))))
+		noSpeech := 0.0
+		if caps.NoSpeechProb {
+			noSpeech = float64(C.whisper_full_get_segment_no_speech_prob_from_state(state, C.int(i)))
+		}
+
+		segment := LabelEvent(Segment{
+			Start:   float64(t0)/100.0 + offset,
+			End:     float64(t1)/100.0 + offset,
+			Text:    text,
+			Speaker: currentSpeaker,
+		}, noSpeech)
+
+		if e.dumpTokens {
+			nTokens := int(C.whisper_full_n_tokens_from_state(state, C.int(i)))
+			for j := 0; j < nTokens; j++ {
+				data := C.whisper_full_get_token_data_from_state(state, C.int(i), C.int(j))
+				segment.Tokens = append(segment.Tokens, Token{
+					ID:          int(data.id),
+					Text:        C.GoString(C.whisper_full_get_token_text_from_state(e.model.ctx, state, C.int(i), C.int(j))),
+					Start:       float64(data.t0)/100.0 + offset,
+					End:         float64(data.t1)/100.0 + offset,
+					Probability: float64(data.p),
+					LogProb:     float64(data.plog),
+				})
+			}
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// extractTokens reads the raw decoder tokens of a segment for debug output.
+// Token text is kept as-is (it may be a partial UTF-8 sequence) so that
+// byte-level mangling is visible in the dump.
+func extractTokens(ctx *C.struct_whisper_context, segmentIdx int) []Token {
+	nTokens := int(C.whisper_full_n_tokens(ctx, C.int(segmentIdx)))
+	tokens := make([]Token, 0, nTokens)
+	for j := 0; j < nTokens; j++ {
+		data := C.whisper_full_get_token_data(ctx, C.int(segmentIdx), C.int(j))
+		textPtr := C.whisper_full_get_token_text(ctx, C.int(segmentIdx), C.int(j))
+		tokens = append(tokens, Token{
+			ID:          int(data.id),
+			Text:        C.GoString(textPtr),
+			Start:       float64(data.t0) / 100.0,
+			End:         float64(data.t1) / 100.0,
+			Probability: float64(data.p),
+			LogProb:     float64(data.plog),
+		})
+	}
+	return tokens
+}
+
 // Close releases resources (but keeps cached models)
 func (e *WhisperCGOEngine) Close() {
 	// Don't free cached models, they'll be reused
@@ -441,22 +1094,48 @@ func ClearModelCache() {
 	}
 }
 
-// prepareAudioFile converts audio to 16kHz mono WAV using ffmpeg
-func prepareAudioFile(audioPath string, progressCallback func(string)) (string, error) {
+// prepareAudioFile converts audio to 16kHz mono WAV using ffmpeg.
+// Files that are already 16kHz mono 16-bit PCM WAV are returned as-is.
+// When a time range is set only that portion is converted, and trimmed
+// reports that the returned audio starts at timeRange.Start.
+func prepareAudioFile(audioPath string, timeRange TimeRange, progressCallback func(string)) (string, bool, error) {
+	if isCompliantWAV(audioPath) {
+		if progressCallback != nil {
+			progressCallback("Audio is already 16kHz mono WAV, skipping conversion")
+		}
+		return audioPath, false, nil
+	}
+
 	if progressCallback != nil {
 		progressCallback("Preparing audio file...")
 	}
 
+	// A long video's WAV takes gigabytes: make sure it fits before converting
+	if duration, err := getAudioDuration(audioPath); err == nil {
+		if err := CheckDiskSpace(os.TempDir(), expectedWAVSize(duration, timeRange), "the converted audio of "+filepath.Base(audioPath), tempSpaceHint); err != nil {
+			return "", false, err
+		}
+	}
+
 	// Create temporary WAV file
-	tempFile, err := os.CreateTemp("", "whisper_audio_*.wav")
+	tempFile, err := CreateTempFile("whisper_audio_*.wav")
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	tempPath := tempFile.Name()
 	tempFile.Close()
 
+	// Seek before the input for fast seeking; -t is then the duration to keep
+	args := []string{}
+	if timeRange.IsSet() {
+		args = append(args, "-ss", strconv.FormatFloat(timeRange.Start, 'f', 3, 64))
+		if timeRange.End > 0 {
+			args = append(args, "-t", strconv.FormatFloat(timeRange.End-timeRange.Start, 'f', 3, 64))
+		}
+	}
+
 	// Use ffmpeg to convert
-	cmd := exec.Command("ffmpeg",
+	args = append(args,
 		"-i", audioPath,
 		"-ar", "16000",    // 16kHz sample rate
 		"-ac", "1",        // Mono
@@ -464,55 +1143,16 @@ func prepareAudioFile(audioPath string, progressCallback func(string)) (string,
 		"-y",              // Overwrite
 		tempPath,
 	)
+	cmd := exec.Command(ffmpegPath(), args...)
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stderr
 
 	if err := cmd.Run(); err != nil {
-		os.Remove(tempPath)
-		return "", fmt.Errorf("ffmpeg conversion failed: %v", err)
-	}
-
-	return tempPath, nil
-}
-
-// readWAVFile reads a WAV file and returns PCM data and sample rate
-func readWAVFile(wavPath string) ([]byte, int, error) {
-	data, err := os.ReadFile(wavPath)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// Simple WAV parser (assumes standard 44-byte header)
-	if len(data) < 44 {
-		return nil, 0, fmt.Errorf("WAV file too short")
-	}
-
-	// Check RIFF header
-	if string(data[0:4]) != "RIFF" {
-		return nil, 0, fmt.Errorf("not a valid WAV file")
+		RemoveTempFile(tempPath)
+		return "", false, fmt.Errorf("ffmpeg conversion failed: %v", err)
 	}
 
-	// Check WAVE format
-	if string(data[8:12]) != "WAVE" {
-		return nil, 0, fmt.Errorf("not a valid WAV file")
-	}
-
-	// Get sample rate (bytes 24-27, little-endian)
-	sampleRate := int(data[24]) | int(data[25])<<8 | int(data[26])<<16 | int(data[27])<<24
-
-	// Find data chunk
-	dataOffset := 44
-	for i := 12; i < len(data)-8; i++ {
-		if string(data[i:i+4]) == "data" {
-			dataOffset = i + 8
-			break
-		}
-	}
-
-	// Extract PCM data
-	pcmData := data[dataOffset:]
-
-	return pcmData, sampleRate, nil
+	return tempPath, timeRange.IsSet(), nil
 }
 
 // GetModelPath is now in model_download.go with auto-download support
@@ -0,0 +1,172 @@
+//go:build whisper_dll && !static
+
+// Runtime loading of whisper.dll for builds tagged whisper_dll. Each whisper.cpp
+// function the engine calls is defined here as a trampoline through a pointer
+// resolved by whisper_dll_load, so the binary links without whisper's import
+// libraries and starts (with a clear error) even when the DLL is missing.
+
+#include <windows.h>
+#include "whisper.h"
+
+#define WHISPER_FUNCTIONS(X) \
+    X(whisper_context_default_params) \
+    X(whisper_init_from_file_with_params) \
+    X(whisper_init_state) \
+    X(whisper_free) \
+    X(whisper_free_state) \
+    X(whisper_full_default_params) \
+    X(whisper_full) \
+    X(whisper_full_with_state) \
+    X(whisper_full_n_segments) \
+    X(whisper_full_n_segments_from_state) \
+    X(whisper_full_get_segment_t0) \
+    X(whisper_full_get_segment_t0_from_state) \
+    X(whisper_full_get_segment_t1) \
+    X(whisper_full_get_segment_t1_from_state) \
+    X(whisper_full_get_segment_text) \
+    X(whisper_full_get_segment_text_from_state) \
+    X(whisper_full_get_segment_speaker_turn_next) \
+    X(whisper_full_get_segment_speaker_turn_next_from_state) \
+    X(whisper_full_get_segment_no_speech_prob) \
+    X(whisper_full_get_segment_no_speech_prob_from_state) \
+    X(whisper_full_n_tokens) \
+    X(whisper_full_n_tokens_from_state) \
+    X(whisper_full_get_token_text) \
+    X(whisper_full_get_token_text_from_state) \
+    X(whisper_full_get_token_data) \
+    X(whisper_full_get_token_data_from_state) \
+    X(whisper_print_system_info)
+
+#define DECLARE_POINTER(name) static __typeof__(&name) p_##name;
+WHISPER_FUNCTIONS(DECLARE_POINTER)
+
+// whisper_dll_load loads the library at path and resolves every function.
+// Returns 0 on success, 1 if the library can't be loaded, or 2 with *missing set
+// to the first function the library doesn't export.
+int whisper_dll_load(const char * path, const char ** missing) {
+    // Look for whisper's own dependencies (ggml.dll, ...) next to it first
+    HMODULE lib = LoadLibraryExA(path, NULL, LOAD_LIBRARY_SEARCH_DLL_LOAD_DIR | LOAD_LIBRARY_SEARCH_DEFAULT_DIRS);
+    if (lib == NULL) {
+        return 1;
+    }
+
+#define RESOLVE(name) \
+    p_##name = (__typeof__(p_##name))(void *)GetProcAddress(lib, #name); \
+    if (p_##name == NULL) { \
+        *missing = #name; \
+        FreeLibrary(lib); \
+        return 2; \
+    }
+    WHISPER_FUNCTIONS(RESOLVE)
+#undef RESOLVE
+
+    return 0;
+}
+
+struct whisper_context_params whisper_context_default_params(void) {
+    return p_whisper_context_default_params();
+}
+
+struct whisper_context * whisper_init_from_file_with_params(const char * path_model, struct whisper_context_params params) {
+    return p_whisper_init_from_file_with_params(path_model, params);
+}
+
+struct whisper_state * whisper_init_state(struct whisper_context * ctx) {
+    return p_whisper_init_state(ctx);
+}
+
+void whisper_free(struct whisper_context * ctx) {
+    p_whisper_free(ctx);
+}
+
+void whisper_free_state(struct whisper_state * state) {
+    p_whisper_free_state(state);
+}
+
+struct whisper_full_params whisper_full_default_params(enum whisper_sampling_strategy strategy) {
+    return p_whisper_full_default_params(strategy);
+}
+
+int whisper_full(struct whisper_context * ctx, struct whisper_full_params params, const float * samples, int n_samples) {
+    return p_whisper_full(ctx, params, samples, n_samples);
+}
+
+int whisper_full_with_state(struct whisper_context * ctx, struct whisper_state * state, struct whisper_full_params params, const float * samples, int n_samples) {
+    return p_whisper_full_with_state(ctx, state, params, samples, n_samples);
+}
+
+int whisper_full_n_segments(struct whisper_context * ctx) {
+    return p_whisper_full_n_segments(ctx);
+}
+
+int whisper_full_n_segments_from_state(struct whisper_state * state) {
+    return p_whisper_full_n_segments_from_state(state);
+}
+
+int64_t whisper_full_get_segment_t0(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_get_segment_t0(ctx, i_segment);
+}
+
+int64_t whisper_full_get_segment_t0_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_get_segment_t0_from_state(state, i_segment);
+}
+
+int64_t whisper_full_get_segment_t1(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_get_segment_t1(ctx, i_segment);
+}
+
+int64_t whisper_full_get_segment_t1_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_get_segment_t1_from_state(state, i_segment);
+}
+
+const char * whisper_full_get_segment_text(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_get_segment_text(ctx, i_segment);
+}
+
+const char * whisper_full_get_segment_text_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_get_segment_text_from_state(state, i_segment);
+}
+
+bool whisper_full_get_segment_speaker_turn_next(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_get_segment_speaker_turn_next(ctx, i_segment);
+}
+
+bool whisper_full_get_segment_speaker_turn_next_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_get_segment_speaker_turn_next_from_state(state, i_segment);
+}
+
+float whisper_full_get_segment_no_speech_prob(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_get_segment_no_speech_prob(ctx, i_segment);
+}
+
+float whisper_full_get_segment_no_speech_prob_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_get_segment_no_speech_prob_from_state(state, i_segment);
+}
+
+int whisper_full_n_tokens(struct whisper_context * ctx, int i_segment) {
+    return p_whisper_full_n_tokens(ctx, i_segment);
+}
+
+int whisper_full_n_tokens_from_state(struct whisper_state * state, int i_segment) {
+    return p_whisper_full_n_tokens_from_state(state, i_segment);
+}
+
+const char * whisper_full_get_token_text(struct whisper_context * ctx, int i_segment, int i_token) {
+    return p_whisper_full_get_token_text(ctx, i_segment, i_token);
+}
+
+const char * whisper_full_get_token_text_from_state(struct whisper_context * ctx, struct whisper_state * state, int i_segment, int i_token) {
+    return p_whisper_full_get_token_text_from_state(ctx, state, i_segment, i_token);
+}
+
+whisper_token_data whisper_full_get_token_data(struct whisper_context * ctx, int i_segment, int i_token) {
+    return p_whisper_full_get_token_data(ctx, i_segment, i_token);
+}
+
+whisper_token_data whisper_full_get_token_data_from_state(struct whisper_state * state, int i_segment, int i_token) {
+    return p_whisper_full_get_token_data_from_state(state, i_segment, i_token);
+}
+
+const char * whisper_print_system_info(void) {
+    return p_whisper_print_system_info();
+}
@@ -0,0 +1,55 @@
+//go:build whisper_dll && !static
+
+package main
+
+/*
+#include <stdlib.h>
+
+int whisper_dll_load(const char * path, const char ** missing);
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+var (
+	whisperLibraryOnce sync.Once
+	whisperLibraryErr  error
+)
+
+// loadWhisperLibrary loads whisper.dll and resolves the functions the engine calls.
+// It runs once; every later call returns the first outcome.
+func loadWhisperLibrary() error {
+	whisperLibraryOnce.Do(func() {
+		exeDir := ""
+		if exe, err := os.Executable(); err == nil {
+			exeDir = filepath.Dir(exe)
+		}
+
+		var tried []string
+		for _, path := range whisperDLLCandidates(exeDir, os.Getenv("IVRIT_WHISPER_DLL")) {
+			cPath := C.CString(path)
+			var missing *C.char
+			status := C.whisper_dll_load(cPath, &missing)
+			C.free(unsafe.Pointer(cPath))
+			switch status {
+			case 0:
+				return
+			case 1: // Not found, or a dependency such as ggml.dll is missing
+				tried = append(tried, path)
+			default:
+				whisperLibraryErr = fmt.Errorf("%s has no %s; it is from an incompatible whisper.cpp version", path, C.GoString(missing))
+				return
+			}
+		}
+		whisperLibraryErr = fmt.Errorf("could not load whisper.cpp (tried %s); keep whisper.dll and the ggml DLLs next to the program or set IVRIT_WHISPER_DLL",
+			strings.Join(tried, ", "))
+	})
+	return whisperLibraryErr
+}
@@ -0,0 +1,17 @@
+package main
+
+import "path/filepath"
+
+// whisperDLLCandidates lists where a whisper_dll build looks for whisper.cpp's
+// library: the IVRIT_WHISPER_DLL override, the program's own folder (how releases
+// ship it), then the system's DLL search path
+func whisperDLLCandidates(exeDir, override string) []string {
+	if override != "" {
+		return []string{override}
+	}
+	var candidates []string
+	if exeDir != "" {
+		candidates = append(candidates, filepath.Join(exeDir, "whisper.dll"), filepath.Join(exeDir, "libwhisper.dll"))
+	}
+	return append(candidates, "whisper.dll", "libwhisper.dll")
+}
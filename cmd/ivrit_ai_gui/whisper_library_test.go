@@ -0,0 +1,30 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWhisperDLLCandidates(t *testing.T) {
+	exeDir := filepath.Join("C:", "Program Files", "ivrit.ai")
+	want := []string{
+		filepath.Join(exeDir, "whisper.dll"),
+		filepath.Join(exeDir, "libwhisper.dll"),
+		"whisper.dll",
+		"libwhisper.dll",
+	}
+	if got := whisperDLLCandidates(exeDir, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("whisperDLLCandidates = %v, want %v", got, want)
+	}
+
+	// An override is the only place looked at
+	if got := whisperDLLCandidates(exeDir, `D:\whisper\whisper.dll`); !reflect.DeepEqual(got, []string{`D:\whisper\whisper.dll`}) {
+		t.Errorf("whisperDLLCandidates with override = %v", got)
+	}
+
+	// Without the program's folder, only the search path is used
+	if got := whisperDLLCandidates("", ""); !reflect.DeepEqual(got, []string{"whisper.dll", "libwhisper.dll"}) {
+		t.Errorf("whisperDLLCandidates without exe dir = %v", got)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows || !whisper_dll || static
+
+package main
+
+// loadWhisperLibrary is a no-op when whisper.cpp is linked into the binary
+func loadWhisperLibrary() error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build static
+
+package main
+
+// Builds tagged static compile against the whisper.cpp release vendored in
+// third_party/whisper.cpp and link its static libraries into the binary, so no
+// Homebrew/apt install of libwhisper is needed and the header always matches the
+// library. The vendored tree is fetched and built by scripts/build-static.sh:
+//
+//	go generate -tags static ./cmd/ivrit_ai_gui
+//	go build -tags static ./cmd/ivrit_ai_gui
+
+//go:generate bash ../../scripts/build-static.sh -libs-only
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../third_party/whisper.cpp/static/include
+#cgo LDFLAGS: -L${SRCDIR}/../../third_party/whisper.cpp/static/lib -lwhisper -lggml -lggml-cpu
+#cgo darwin LDFLAGS: -lggml-metal -lggml-blas -lggml-base -framework Accelerate -framework Foundation -framework Metal -framework MetalKit -lc++
+#cgo linux LDFLAGS: -lggml-base -lstdc++ -lgomp -lm -lpthread -ldl
+#cgo windows LDFLAGS: -lggml-base -lstdc++ -lgomp
+*/
+import "C"
@@ -5,6 +5,11 @@ go 1.23.8
 require (
 	gioui.org v0.9.0
 	github.com/sqweek/dialog v0.0.0-20240226140203-065105509627
+	github.com/yuin/gopher-lua v1.1.2
+	golang.org/x/net v0.34.0
+	golang.org/x/text v0.24.0
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.4
 )
 
 require (
@@ -14,5 +19,5 @@ require (
 	golang.org/x/exp/shiny v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
 	golang.org/x/image v0.26.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
-	golang.org/x/text v0.24.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
 )
@@ -0,0 +1,98 @@
+#!/bin/bash
+# Build the application with whisper.cpp compiled from the vendored source tree
+# and linked statically (the static build tag), without a Homebrew/apt install
+#
+#   ./scripts/build-static.sh              # build whisper.cpp if needed, then the app
+#   ./scripts/build-static.sh -libs-only   # only whisper.cpp (run by go generate -tags static)
+#
+# WHISPER_REF    whisper.cpp release fetched when third_party/whisper.cpp is empty (default v1.7.4)
+# WHISPER_COREML set to 1 to build the Core ML encoder support (macOS)
+# HEADLESS       set to 1 for the CLI/server-only build
+
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(cd "$SCRIPT_DIR/.." && pwd)"
+
+WHISPER_REF="${WHISPER_REF:-v1.7.4}"
+WHISPER_SRC="$PROJECT_ROOT/third_party/whisper.cpp"
+WHISPER_BUILD="$WHISPER_SRC/build-static"
+WHISPER_PREFIX="$WHISPER_SRC/static"
+
+LIBS_ONLY=0
+if [ "$1" = "-libs-only" ]; then
+    LIBS_ONLY=1
+fi
+
+for tool in git cmake; do
+    if ! command -v $tool &> /dev/null; then
+        echo "Error: $tool is not installed."
+        echo "Install with: brew install $tool (macOS) or sudo apt-get install $tool (Linux)"
+        exit 1
+    fi
+done
+
+# Fetch the pinned release unless a source tree is already vendored
+if [ ! -f "$WHISPER_SRC/CMakeLists.txt" ]; then
+    echo "Fetching whisper.cpp $WHISPER_REF into third_party/whisper.cpp..."
+    git clone --depth 1 --branch "$WHISPER_REF" https://github.com/ggerganov/whisper.cpp.git "$WHISPER_SRC"
+fi
+
+if [ "$(uname)" = "Darwin" ]; then
+    JOBS=$(sysctl -n hw.ncpu)
+    # Embed the Metal shaders, so the binary doesn't need ggml-metal.metal next to it
+    PLATFORM_FLAGS="-DGGML_METAL_EMBED_LIBRARY=ON"
+    if [ "$WHISPER_COREML" = "1" ]; then
+        PLATFORM_FLAGS="$PLATFORM_FLAGS -DWHISPER_COREML=ON"
+    fi
+else
+    JOBS=$(nproc)
+    PLATFORM_FLAGS=""
+fi
+
+# Rebuilt only when the libraries are missing; delete third_party/whisper.cpp/static to force it
+if [ ! -f "$WHISPER_PREFIX/lib/libwhisper.a" ]; then
+    echo "Building whisper.cpp (static libraries)..."
+    cmake -S "$WHISPER_SRC" -B "$WHISPER_BUILD" \
+        -DCMAKE_BUILD_TYPE=Release \
+        -DBUILD_SHARED_LIBS=OFF \
+        -DCMAKE_POSITION_INDEPENDENT_CODE=ON \
+        -DWHISPER_BUILD_TESTS=OFF \
+        -DWHISPER_BUILD_EXAMPLES=OFF \
+        $PLATFORM_FLAGS
+    cmake --build "$WHISPER_BUILD" --config Release -j "$JOBS"
+    cmake --install "$WHISPER_BUILD" --prefix "$WHISPER_PREFIX"
+    # The Core ML encoder library isn't part of whisper.cpp's install
+    if [ -f "$WHISPER_BUILD/src/libwhisper.coreml.a" ]; then
+        cp "$WHISPER_BUILD/src/libwhisper.coreml.a" "$WHISPER_PREFIX/lib/"
+    fi
+    echo "✅ whisper.cpp built: third_party/whisper.cpp/static"
+fi
+
+if [ $LIBS_ONLY -eq 1 ]; then
+    exit 0
+fi
+
+cd "$PROJECT_ROOT"
+
+# Flags from another whisper.cpp install would mix its headers or libraries in
+unset CGO_CFLAGS CGO_LDFLAGS
+export CGO_ENABLED=1
+if [ -f "$WHISPER_PREFIX/lib/libwhisper.coreml.a" ]; then
+    export CGO_LDFLAGS="-lwhisper.coreml -framework CoreML"
+fi
+
+TAGS="static"
+if [ "$HEADLESS" = "1" ]; then
+    TAGS="static,headless"
+fi
+
+WHISPER_VERSION=$(git -C "$WHISPER_SRC" describe --tags --always 2>/dev/null || echo "$WHISPER_REF")
+APP_VERSION="${APP_VERSION:-dev}"
+
+echo "Building ivrit_ai (tags: $TAGS)..."
+go build -tags "$TAGS" \
+    -ldflags "-X main.appVersion=$APP_VERSION -X main.whisperVersion=$WHISPER_VERSION" \
+    -o ivrit_ai ./cmd/ivrit_ai_gui
+
+echo "✅ Built ivrit_ai with whisper.cpp $WHISPER_VERSION linked in"
@@ -0,0 +1,137 @@
+# Build the Windows application without MSYS2
+#
+# Builds whisper.cpp with MinGW (gcc and cmake on PATH, e.g. from WinLibs or the
+# GitHub runner) and packages a self-contained folder and zip: the program, the
+# whisper/ggml DLLs and the MinGW runtime DLLs.
+#
+#   .\scripts\build-windows.ps1 -WhisperDir C:\src\whisper.cpp
+#
+# -Mode dll   (default) builds with the whisper_dll tag: whisper.dll is loaded at
+#             startup, so the build needs only whisper.h, not import libraries
+# -Mode link  links against whisper.cpp's import libraries at build time
+# -Mode static compiles the vendored third_party\whisper.cpp into the program
+#             (the static build tag); no whisper DLLs are shipped
+
+param(
+    [string]$WhisperDir = $env:WHISPER_DIR,
+    [ValidateSet("dll", "link", "static")]
+    [string]$Mode = "dll",
+    [switch]$Headless,
+    [string]$Version = "dev"
+)
+
+$ErrorActionPreference = "Stop"
+$ProjectRoot = Split-Path -Parent $PSScriptRoot
+Set-Location $ProjectRoot
+
+Write-Host "Building Windows application ($Mode mode)..."
+
+foreach ($tool in "go", "gcc", "cmake") {
+    if (-not (Get-Command $tool -ErrorAction SilentlyContinue)) {
+        Write-Error "$tool not found on PATH. Install Go, and MinGW-w64 gcc with cmake (e.g. https://winlibs.com)."
+    }
+}
+
+# Fetch whisper.cpp if no source tree was given
+if ($Mode -eq "static") {
+    $WhisperDir = Join-Path $ProjectRoot "third_party\whisper.cpp"
+} elseif (-not $WhisperDir) {
+    $WhisperDir = Join-Path $ProjectRoot "build\whisper.cpp"
+}
+if (-not (Test-Path (Join-Path $WhisperDir "CMakeLists.txt"))) {
+    Write-Host "Cloning whisper.cpp into $WhisperDir..."
+    if ($Mode -eq "static") {
+        # The vendored tree is pinned, like scripts/build-static.sh
+        $ref = if ($env:WHISPER_REF) { $env:WHISPER_REF } else { "v1.7.4" }
+        git clone --depth 1 --branch $ref https://github.com/ggerganov/whisper.cpp.git $WhisperDir
+    } else {
+        git clone --depth 1 https://github.com/ggerganov/whisper.cpp.git $WhisperDir
+    }
+    if ($LASTEXITCODE -ne 0) { Write-Error "git clone failed" }
+}
+$WhisperDir = (Resolve-Path $WhisperDir).Path
+$WhisperBuild = Join-Path $WhisperDir "build"
+
+if ($Mode -eq "static") {
+    # Static libraries installed where whisper_static.go looks for them
+    $WhisperBuild = Join-Path $WhisperDir "build-static"
+    $prefix = Join-Path $WhisperDir "static"
+    if (-not (Test-Path (Join-Path $prefix "lib\libwhisper.a"))) {
+        Write-Host "Building whisper.cpp (static libraries)..."
+        cmake -S $WhisperDir -B $WhisperBuild -G "MinGW Makefiles" -DCMAKE_BUILD_TYPE=Release -DBUILD_SHARED_LIBS=OFF `
+            -DWHISPER_BUILD_TESTS=OFF -DWHISPER_BUILD_EXAMPLES=OFF
+        if ($LASTEXITCODE -ne 0) { Write-Error "cmake configure failed" }
+        cmake --build $WhisperBuild --config Release -j $env:NUMBER_OF_PROCESSORS
+        if ($LASTEXITCODE -ne 0) { Write-Error "whisper.cpp build failed" }
+        cmake --install $WhisperBuild --prefix $prefix
+        if ($LASTEXITCODE -ne 0) { Write-Error "whisper.cpp install failed" }
+    }
+} elseif (-not (Test-Path (Join-Path $WhisperBuild "bin\*whisper.dll"))) {
+    # Build whisper.cpp as DLLs (skipped when already built)
+    Write-Host "Building whisper.cpp..."
+    cmake -S $WhisperDir -B $WhisperBuild -G "MinGW Makefiles" -DCMAKE_BUILD_TYPE=Release -DBUILD_SHARED_LIBS=ON `
+        -DWHISPER_BUILD_TESTS=OFF -DWHISPER_BUILD_EXAMPLES=OFF
+    if ($LASTEXITCODE -ne 0) { Write-Error "cmake configure failed" }
+    cmake --build $WhisperBuild --config Release -j $env:NUMBER_OF_PROCESSORS
+    if ($LASTEXITCODE -ne 0) { Write-Error "whisper.cpp build failed" }
+}
+
+# gcc takes forward slashes; CGO_CFLAGS is split on spaces, so keep the tree in a path without them
+$include = "$WhisperDir/include" -replace '\\', '/'
+$ggmlInclude = "$WhisperDir/ggml/include" -replace '\\', '/'
+$env:CGO_ENABLED = "1"
+$env:CGO_CFLAGS = "-I$include -I$ggmlInclude"
+
+$tags = @()
+if ($Mode -eq "static") {
+    $tags += "static"
+    $env:CGO_CFLAGS = ""
+    $env:CGO_LDFLAGS = ""
+} elseif ($Mode -eq "dll") {
+    $tags += "whisper_dll"
+    $env:CGO_LDFLAGS = ""
+} else {
+    $libDirs = "src", "ggml/src" | ForEach-Object { "-L" + ("$WhisperBuild/$_" -replace '\\', '/') }
+    $env:CGO_LDFLAGS = $libDirs -join " "
+}
+if ($Headless) {
+    $tags += "headless"
+}
+
+$whisperVersion = (git -C $WhisperDir rev-parse --short HEAD 2>$null)
+if (-not $whisperVersion) { $whisperVersion = "unknown" }
+
+$dist = Join-Path $ProjectRoot "dist\ivrit_ai-windows-amd64"
+if (Test-Path $dist) { Remove-Item -Recurse -Force $dist }
+New-Item -ItemType Directory -Force -Path $dist | Out-Null
+
+$ldflags = "-X main.appVersion=$Version -X main.whisperVersion=$whisperVersion"
+$buildArgs = @("build", "-ldflags", $ldflags, "-o", (Join-Path $dist "ivrit_ai-windows-amd64.exe"))
+if ($tags.Count -gt 0) {
+    $buildArgs += @("-tags", ($tags -join ","))
+}
+$buildArgs += "./cmd/ivrit_ai_gui"
+
+Write-Host "go $($buildArgs -join ' ')"
+& go @buildArgs
+if ($LASTEXITCODE -ne 0) { Write-Error "go build failed" }
+
+# Ship whisper.cpp's DLLs and the MinGW runtime they (and the program) depend on
+if ($Mode -ne "static") {
+    Get-ChildItem (Join-Path $WhisperBuild "bin") -Filter *.dll | Copy-Item -Destination $dist
+}
+$mingwBin = Split-Path -Parent (Get-Command gcc).Source
+foreach ($dll in "libstdc++-6.dll", "libgcc_s_seh-1.dll", "libwinpthread-1.dll", "libgomp-1.dll") {
+    $path = Join-Path $mingwBin $dll
+    if (Test-Path $path) {
+        Copy-Item $path -Destination $dist
+    }
+}
+Copy-Item README.md, LICENSE -Destination $dist
+
+$zip = Join-Path $ProjectRoot "dist\ivrit_ai-windows-amd64.zip"
+if (Test-Path $zip) { Remove-Item $zip }
+Compress-Archive -Path "$dist\*" -DestinationPath $zip
+
+Write-Host "✅ Built $dist"
+Write-Host "   Packaged $zip"
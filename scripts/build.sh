@@ -94,10 +94,23 @@ fi
 echo "Downloading dependencies..."
 go mod tidy
 
+# Version information recorded in transcript manifests
+APP_VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo "dev")
+if [ -z "$WHISPER_VERSION" ] && command -v pkg-config &> /dev/null && pkg-config --exists whisper 2>/dev/null; then
+    WHISPER_VERSION=$(pkg-config --modversion whisper)
+fi
+LDFLAGS="-X main.appVersion=$APP_VERSION -X main.whisperVersion=${WHISPER_VERSION:-unknown}"
+
 # Build
 echo "Building executable..."
-# Build Gio-based GUI (pure Go with RTL support)
-go build -o ivrit_ai ./cmd/ivrit_ai_gui
+if [ "$HEADLESS" = "1" ]; then
+    # CLI-only binary without Gio/dialog (no X11/Wayland libraries needed)
+    echo "Headless build (CLI only)"
+    go build -tags headless -ldflags "$LDFLAGS" -o ivrit_ai ./cmd/ivrit_ai_gui
+else
+    # Build Gio-based GUI (pure Go with RTL support)
+    go build -ldflags "$LDFLAGS" -o ivrit_ai ./cmd/ivrit_ai_gui
+fi
 
 echo ""
 echo "✅ Build complete!"
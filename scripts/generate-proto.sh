@@ -0,0 +1,28 @@
+#!/bin/bash
+# Regenerate the Go code for the gRPC API from api/transcriptionpb/transcription.proto
+# Requires protoc (brew install protobuf / apt install protobuf-compiler)
+
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(cd "$SCRIPT_DIR/.." && pwd)"
+cd "$PROJECT_ROOT"
+
+echo "Generating gRPC code..."
+
+# Install the Go plugins if not present
+if ! command -v protoc-gen-go &> /dev/null; then
+    echo "Installing protoc-gen-go..."
+    go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.4
+fi
+if ! command -v protoc-gen-go-grpc &> /dev/null; then
+    echo "Installing protoc-gen-go-grpc..."
+    go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+fi
+
+cd api/transcriptionpb
+protoc --go_out=. --go_opt=paths=source_relative \
+    --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+    transcription.proto
+
+echo "✅ Generated api/transcriptionpb/transcription.pb.go and transcription_grpc.pb.go"